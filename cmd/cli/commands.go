@@ -0,0 +1,628 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/idml"
+	"github.com/dimelords/idmllib/v2/pkg/idms"
+)
+
+// Exit codes returned by the subcommands.
+const (
+	exitOK      = 0 // success
+	exitError   = 1 // the operation failed (unreadable file, write error, ...)
+	exitUsage   = 2 // invalid arguments
+	exitInvalid = 3 // validate found problems
+)
+
+// errUsage marks errors caused by invalid arguments.
+var errUsage = errors.New("usage error")
+
+// command is a non-interactive subcommand.
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(c *commandContext, args []string) error
+}
+
+// commandContext carries the output streams and the --json flag of a run.
+type commandContext struct {
+	stdout io.Writer
+	stderr io.Writer
+	flags  *flag.FlagSet
+	json   bool
+
+	// invalid is set by commands that completed but found problems
+	invalid bool
+}
+
+var commands = []command{
+	{"info", "<file.idml>", "Show document information", cmdInfo},
+	{"text", "[--story ID] <file.idml>", "Print the text of all stories (or one story)", cmdText},
+	{"export-idms", "--frame ID [-o out.idms] <file.idml>", "Export a text frame as an IDMS snippet", cmdExportIDMS},
+	{"validate", "<file.idml>", "Check for missing or malformed resource references", cmdValidate},
+	{"cleanup", "(-o out.idml | --dry-run) <file.idml>", "Remove unused fonts and styles", cmdCleanup},
+	{"roundtrip", "<in.idml> <out.idml>", "Read and write a document unchanged", cmdRoundtrip},
+	{"new", "[--preset a4] [--landscape] [--columns N] [--gutter PT] <out.idml>", "Create a new document", cmdNew},
+}
+
+// runCommand runs the subcommand named by args[0] and returns the exit code.
+func runCommand(args []string, stdout, stderr io.Writer) int {
+	name := args[0]
+	if name == "help" || name == "-h" || name == "--help" {
+		printUsage(stdout)
+		return exitOK
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+
+		c := &commandContext{stdout: stdout, stderr: stderr}
+		c.flags = flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+		c.flags.SetOutput(io.Discard)
+		c.flags.BoolVar(&c.json, "json", false, "write JSON output")
+
+		err := cmd.run(c, args[1:])
+		switch {
+		case errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp):
+			c.fail(err)
+			fmt.Fprintf(stderr, "usage: idml %s %s [--json]\n", cmd.name, cmd.args)
+			return exitUsage
+		case err != nil:
+			c.fail(err)
+			return exitError
+		case c.invalid:
+			return exitInvalid
+		}
+		return exitOK
+	}
+
+	fmt.Fprintf(stderr, "idml: unknown command %q\n\n", name)
+	printUsage(stderr)
+	return exitUsage
+}
+
+// printUsage lists the subcommands and exit codes.
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: idml <command> [options]")
+	fmt.Fprintln(w, "       idml              (interactive menu)")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-12s %s\n", cmd.name, cmd.summary)
+		fmt.Fprintf(w, "  %-12s   idml %s %s\n", "", cmd.name, cmd.args)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "All commands accept --json for machine-readable output.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Exit codes: 0 success, 1 failure, 2 invalid arguments, 3 validation problems found")
+}
+
+// parse parses the flags and checks the number of positional arguments.
+// Flags may appear before, between or after the positional arguments, as in
+// "idml info file.idml --json"; arguments after "--" are all positional.
+func (c *commandContext) parse(args []string, positional int) ([]string, error) {
+	var rest []string
+	for {
+		if err := c.flags.Parse(args); err != nil {
+			return nil, fmt.Errorf("%w: %v", errUsage, err)
+		}
+		remaining := c.flags.Args()
+		if consumed := len(args) - len(remaining); consumed > 0 && args[consumed-1] == "--" {
+			rest = append(rest, remaining...)
+			break
+		}
+		if len(remaining) == 0 {
+			break
+		}
+		rest = append(rest, remaining[0])
+		args = remaining[1:]
+	}
+	if len(rest) != positional {
+		return nil, fmt.Errorf("%w: expected %d argument(s), got %d", errUsage, positional, len(rest))
+	}
+	return rest, nil
+}
+
+// output writes v as JSON in --json mode, otherwise calls text.
+func (c *commandContext) output(v any, text func(w io.Writer)) error {
+	if !c.json {
+		text(c.stdout)
+		return nil
+	}
+	enc := json.NewEncoder(c.stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// fail reports an error as JSON on stdout or as text on stderr.
+func (c *commandContext) fail(err error) {
+	if c.json {
+		_ = c.output(map[string]string{"error": err.Error()}, nil)
+		return
+	}
+	fmt.Fprintf(c.stderr, "Error: %v\n", err)
+}
+
+// ─── info ───────────────────────────────────────────────────────────────────
+
+type infoResult struct {
+	File          string   `json:"file"`
+	DOMVersion    string   `json:"domVersion"`
+	Files         int      `json:"files"`
+	Spreads       int      `json:"spreads"`
+	MasterSpreads []string `json:"masterSpreads"`
+	Pages         int      `json:"pages"`
+	Stories       int      `json:"stories"`
+	PageItems     int      `json:"pageItems"`
+	TextFrames    int      `json:"textFrames"`
+	Rectangles    int      `json:"rectangles"`
+}
+
+func cmdInfo(c *commandContext, args []string) error {
+	rest, err := c.parse(args, 1)
+	if err != nil {
+		return err
+	}
+
+	pkg, err := idml.Read(rest[0])
+	if err != nil {
+		return err
+	}
+
+	doc, err := pkg.Document()
+	if err != nil {
+		return err
+	}
+	spreads, err := pkg.Spreads()
+	if err != nil {
+		return err
+	}
+	masters, err := pkg.MasterSpreads()
+	if err != nil {
+		return err
+	}
+
+	result := infoResult{
+		File:          rest[0],
+		DOMVersion:    doc.DOMVersion,
+		Files:         pkg.FileCount(),
+		Spreads:       len(spreads),
+		MasterSpreads: []string{},
+		Stories:       len(doc.Stories),
+	}
+	for _, sp := range spreads {
+		inner := &sp.InnerSpread
+		result.Pages += len(inner.Pages)
+		result.TextFrames += len(inner.TextFrames)
+		result.Rectangles += len(inner.Rectangles)
+		result.PageItems += len(inner.TextFrames) + len(inner.Rectangles) + len(inner.Ovals) +
+			len(inner.Polygons) + len(inner.GraphicLines) + len(inner.Groups)
+	}
+	for _, ms := range masters {
+		result.MasterSpreads = append(result.MasterSpreads, ms.InnerMasterSpread.Name)
+	}
+	sort.Strings(result.MasterSpreads)
+
+	return c.output(result, func(w io.Writer) {
+		fmt.Fprintf(w, "File:           %s\n", result.File)
+		fmt.Fprintf(w, "DOM version:    %s\n", result.DOMVersion)
+		fmt.Fprintf(w, "Files:          %d\n", result.Files)
+		fmt.Fprintf(w, "Spreads:        %d\n", result.Spreads)
+		fmt.Fprintf(w, "Pages:          %d\n", result.Pages)
+		fmt.Fprintf(w, "Master spreads: %s\n", strings.Join(result.MasterSpreads, ", "))
+		fmt.Fprintf(w, "Stories:        %d\n", result.Stories)
+		fmt.Fprintf(w, "Page items:     %d (%d text frames, %d rectangles)\n", result.PageItems, result.TextFrames, result.Rectangles)
+	})
+}
+
+// ─── text ───────────────────────────────────────────────────────────────────
+
+type storyText struct {
+	Story string `json:"story"`
+	Text  string `json:"text"`
+}
+
+func cmdText(c *commandContext, args []string) error {
+	storyID := c.flags.String("story", "", "only print the story with this ID")
+	rest, err := c.parse(args, 1)
+	if err != nil {
+		return err
+	}
+
+	pkg, err := idml.Read(rest[0])
+	if err != nil {
+		return err
+	}
+
+	filenames, err := storyFilenames(pkg)
+	if err != nil {
+		return err
+	}
+	if *storyID != "" {
+		filename := idml.StoryPath(*storyID)
+		if !contains(filenames, filename) {
+			return common.WrapErrorWithPath("cli", "text", filename, common.ErrNotFound)
+		}
+		filenames = []string{filename}
+	}
+
+	texts := []storyText{}
+	for _, filename := range filenames {
+		st, err := pkg.Story(filename)
+		if err != nil {
+			return err
+		}
+		texts = append(texts, storyText{Story: st.StoryElement.Self, Text: st.ExtractText()})
+	}
+
+	return c.output(texts, func(w io.Writer) {
+		for i, t := range texts {
+			if len(texts) > 1 {
+				if i > 0 {
+					fmt.Fprintln(w)
+				}
+				fmt.Fprintf(w, "── %s ──\n", t.Story)
+			}
+			fmt.Fprintln(w, strings.ReplaceAll(t.Text, "\r", "\n"))
+		}
+	})
+}
+
+// storyFilenames returns the story files in designmap order, followed by any
+// story files the designmap does not reference.
+func storyFilenames(pkg *idml.Package) ([]string, error) {
+	stories, err := pkg.Stories()
+	if err != nil {
+		return nil, err
+	}
+	doc, err := pkg.Document()
+	if err != nil {
+		return nil, err
+	}
+
+	var filenames []string
+	seen := make(map[string]bool)
+	for _, ref := range doc.Stories {
+		if _, ok := stories[ref.Src]; ok && !seen[ref.Src] {
+			filenames = append(filenames, ref.Src)
+			seen[ref.Src] = true
+		}
+	}
+
+	var rest []string
+	for filename := range stories {
+		if !seen[filename] {
+			rest = append(rest, filename)
+		}
+	}
+	sort.Strings(rest)
+	return append(filenames, rest...), nil
+}
+
+// ─── export-idms ────────────────────────────────────────────────────────────
+
+type exportResult struct {
+	Frame  string `json:"frame"`
+	Output string `json:"output"`
+}
+
+func cmdExportIDMS(c *commandContext, args []string) error {
+	frameID := c.flags.String("frame", "", "ID of the text frame to export")
+	output := c.flags.String("o", "", "output file (default <frame>.idms)")
+	rest, err := c.parse(args, 1)
+	if err != nil {
+		return err
+	}
+	if *frameID == "" {
+		return fmt.Errorf("%w: --frame is required", errUsage)
+	}
+	if *output == "" {
+		*output = *frameID + ".idms"
+	}
+
+	pkg, err := idml.Read(rest[0])
+	if err != nil {
+		return err
+	}
+
+	frame, err := pkg.SelectTextFrameByID(*frameID)
+	if err != nil {
+		return err
+	}
+	selection := idml.NewSelection()
+	selection.AddTextFrame(frame)
+
+	snippet, err := idms.NewExporter(pkg).ExportSelection(selection)
+	if err != nil {
+		return err
+	}
+	if err := idms.Write(snippet, *output); err != nil {
+		return err
+	}
+
+	result := exportResult{Frame: *frameID, Output: absPath(*output)}
+	return c.output(result, func(w io.Writer) {
+		fmt.Fprintf(w, "Exported text frame %s to %s\n", result.Frame, result.Output)
+	})
+}
+
+// ─── validate ───────────────────────────────────────────────────────────────
+
+type validationIssue struct {
+	Type     string   `json:"type"`
+	Resource string   `json:"resource"`
+	UsedBy   []string `json:"usedBy,omitempty"`
+	Message  string   `json:"message"`
+}
+
+type validateResult struct {
+	File   string            `json:"file"`
+	Valid  bool              `json:"valid"`
+	Issues []validationIssue `json:"issues"`
+}
+
+func cmdValidate(c *commandContext, args []string) error {
+	rest, err := c.parse(args, 1)
+	if err != nil {
+		return err
+	}
+
+	pkg, err := idml.Read(rest[0])
+	if err != nil {
+		return err
+	}
+
+	rm := idml.NewResourceManager(pkg)
+	missing, err := rm.ValidateReferences()
+	if err != nil {
+		return err
+	}
+	malformed, err := rm.ValidateResourceReferences()
+	if err != nil {
+		return err
+	}
+
+	result := validateResult{File: rest[0], Issues: []validationIssue{}}
+	for _, ve := range append(missing, malformed...) {
+		result.Issues = append(result.Issues, validationIssue{
+			Type:     ve.ResourceType,
+			Resource: ve.ResourceID,
+			UsedBy:   ve.UsedBy,
+			Message:  ve.Message,
+		})
+	}
+	sort.Slice(result.Issues, func(i, j int) bool {
+		a, b := result.Issues[i], result.Issues[j]
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Resource < b.Resource
+	})
+	result.Valid = len(result.Issues) == 0
+	c.invalid = !result.Valid
+
+	return c.output(result, func(w io.Writer) {
+		if result.Valid {
+			fmt.Fprintf(w, "%s: no problems found\n", result.File)
+			return
+		}
+		fmt.Fprintf(w, "%s: %d problem(s) found\n", result.File, len(result.Issues))
+		for _, issue := range result.Issues {
+			fmt.Fprintf(w, "  %s %s: %s\n", issue.Type, issue.Resource, issue.Message)
+		}
+	})
+}
+
+// ─── cleanup ────────────────────────────────────────────────────────────────
+
+type cleanupResult struct {
+	File                   string   `json:"file"`
+	Output                 string   `json:"output,omitempty"`
+	DryRun                 bool     `json:"dryRun"`
+	Removed                int      `json:"removed"`
+	RemovedFonts           []string `json:"removedFonts"`
+	RemovedParagraphStyles []string `json:"removedParagraphStyles"`
+	RemovedCharacterStyles []string `json:"removedCharacterStyles"`
+}
+
+func cmdCleanup(c *commandContext, args []string) error {
+	output := c.flags.String("o", "", "output file")
+	dryRun := c.flags.Bool("dry-run", false, "only report what would be removed")
+	rest, err := c.parse(args, 1)
+	if err != nil {
+		return err
+	}
+	if *output == "" && !*dryRun {
+		return fmt.Errorf("%w: -o or --dry-run is required", errUsage)
+	}
+
+	pkg, err := idml.Read(rest[0])
+	if err != nil {
+		return err
+	}
+
+	opts := idml.DefaultCleanupOptions()
+	opts.DryRun = *dryRun
+	removed, err := idml.NewResourceManager(pkg).CleanupOrphans(opts)
+	if err != nil {
+		return err
+	}
+
+	result := cleanupResult{
+		File:                   rest[0],
+		DryRun:                 *dryRun,
+		Removed:                removed.Count(),
+		RemovedFonts:           nonNil(removed.RemovedFonts),
+		RemovedParagraphStyles: nonNil(removed.RemovedParagraphStyles),
+		RemovedCharacterStyles: nonNil(removed.RemovedCharacterStyles),
+	}
+	if !*dryRun {
+		if err := idml.Write(pkg, *output); err != nil {
+			return err
+		}
+		result.Output = absPath(*output)
+	}
+
+	return c.output(result, func(w io.Writer) {
+		verb := "Removed"
+		if result.DryRun {
+			verb = "Would remove"
+		}
+		fmt.Fprintf(w, "%s %d unused resource(s)\n", verb, result.Removed)
+		printList(w, "Fonts", result.RemovedFonts)
+		printList(w, "Paragraph styles", result.RemovedParagraphStyles)
+		printList(w, "Character styles", result.RemovedCharacterStyles)
+		if result.Output != "" {
+			fmt.Fprintf(w, "Saved to %s\n", result.Output)
+		}
+	})
+}
+
+// ─── roundtrip ──────────────────────────────────────────────────────────────
+
+type roundtripResult struct {
+	Input      string `json:"input"`
+	Output     string `json:"output"`
+	DOMVersion string `json:"domVersion"`
+	Files      int    `json:"files"`
+	Stories    int    `json:"stories"`
+}
+
+func cmdRoundtrip(c *commandContext, args []string) error {
+	rest, err := c.parse(args, 2)
+	if err != nil {
+		return err
+	}
+
+	pkg, err := idml.Read(rest[0])
+	if err != nil {
+		return err
+	}
+
+	result := roundtripResult{Input: rest[0], Files: pkg.FileCount()}
+	if doc, err := pkg.Document(); err == nil {
+		result.DOMVersion = doc.DOMVersion
+		result.Stories = len(doc.Stories)
+	}
+
+	if err := idml.Write(pkg, rest[1]); err != nil {
+		return err
+	}
+	result.Output = absPath(rest[1])
+
+	return c.output(result, func(w io.Writer) {
+		fmt.Fprintf(w, "Read %s (DOM %s, %d files, %d stories)\n", result.Input, result.DOMVersion, result.Files, result.Stories)
+		fmt.Fprintf(w, "Wrote %s\n", result.Output)
+	})
+}
+
+// ─── new ────────────────────────────────────────────────────────────────────
+
+type newResult struct {
+	Output      string  `json:"output"`
+	Preset      string  `json:"preset"`
+	Orientation string  `json:"orientation"`
+	Width       float64 `json:"width"`
+	Height      float64 `json:"height"`
+	Columns     int     `json:"columns"`
+	Gutter      float64 `json:"gutter"`
+}
+
+func cmdNew(c *commandContext, args []string) error {
+	defaults := idml.DefaultTemplateOptions()
+	preset := c.flags.String("preset", string(defaults.Preset), "page size: letter-us, a4, tabloid or legal")
+	landscape := c.flags.Bool("landscape", false, "use landscape orientation")
+	columns := c.flags.Int("columns", defaults.ColumnCount, "number of text columns")
+	gutter := c.flags.Float64("gutter", defaults.ColumnGutter, "column gutter in points")
+	rest, err := c.parse(args, 1)
+	if err != nil {
+		return err
+	}
+
+	opts := idml.DefaultTemplateOptions()
+	opts.Preset = idml.DocumentPreset(strings.ToLower(*preset))
+	if _, ok := idml.StandardPresets[opts.Preset]; !ok {
+		return fmt.Errorf("%w: unknown preset %q", errUsage, *preset)
+	}
+	if *landscape {
+		opts.Orientation = "Landscape"
+	}
+	if *columns < 1 {
+		return fmt.Errorf("%w: --columns must be at least 1", errUsage)
+	}
+	opts.ColumnCount = *columns
+	opts.ColumnGutter = *gutter
+
+	filename := rest[0]
+	if !strings.HasSuffix(strings.ToLower(filename), ".idml") {
+		filename += ".idml"
+	}
+
+	pkg, err := idml.NewFromTemplate(opts)
+	if err != nil {
+		return err
+	}
+	if err := idml.Write(pkg, filename); err != nil {
+		return err
+	}
+
+	dims := opts.GetDimensions()
+	result := newResult{
+		Output:      absPath(filename),
+		Preset:      string(opts.Preset),
+		Orientation: opts.Orientation,
+		Width:       dims.Width,
+		Height:      dims.Height,
+		Columns:     opts.ColumnCount,
+		Gutter:      opts.ColumnGutter,
+	}
+	return c.output(result, func(w io.Writer) {
+		fmt.Fprintf(w, "Created %s (%s %s, %.1f × %.1f points, %d column(s))\n",
+			result.Output, result.Preset, result.Orientation, result.Width, result.Height, result.Columns)
+	})
+}
+
+// ─── helpers ────────────────────────────────────────────────────────────────
+
+// absPath returns the absolute form of path for display, or path itself on error.
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// nonNil makes empty lists encode as [] instead of null.
+func nonNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
+
+func printList(w io.Writer, label string, list []string) {
+	if len(list) > 0 {
+		fmt.Fprintf(w, "  %s: %s\n", label, strings.Join(list, ", "))
+	}
+}
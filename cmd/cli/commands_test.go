@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dimelords/idmllib/v2/pkg/idml"
+	"github.com/dimelords/idmllib/v2/pkg/idms"
+)
+
+const exampleIDML = "../../testdata/example.idml"
+
+// TestRunCommand_FlagsAfterFile tests that flags are accepted after the
+// positional arguments, as the usage text shows them.
+func TestRunCommand_FlagsAfterFile(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"before", []string{"info", "--json", exampleIDML}},
+		{"after", []string{"info", exampleIDML, "--json"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := runCommand(tt.args, &stdout, &stderr); code != exitOK {
+				t.Fatalf("exit code = %d, stderr = %s", code, stderr.String())
+			}
+			var result infoResult
+			if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+				t.Fatalf("output is not JSON: %v\n%s", err, stdout.String())
+			}
+			if result.File != exampleIDML || result.Stories == 0 {
+				t.Errorf("result = %+v", result)
+			}
+		})
+	}
+
+	// Flags with values after the file
+	var stdout, stderr bytes.Buffer
+	if code := runCommand([]string{"text", exampleIDML, "--story", "u222", "--json"}, &stdout, &stderr); code != exitOK {
+		t.Fatalf("text exit code = %d, stderr = %s", code, stderr.String())
+	}
+	var texts []storyText
+	if err := json.Unmarshal(stdout.Bytes(), &texts); err != nil {
+		t.Fatalf("text output is not JSON: %v\n%s", err, stdout.String())
+	}
+	if len(texts) != 1 || texts[0].Story != "u222" {
+		t.Errorf("texts = %+v", texts)
+	}
+
+	// Extra positional arguments are still rejected
+	stdout.Reset()
+	stderr.Reset()
+	if code := runCommand([]string{"info", exampleIDML, "--json", "extra"}, &stdout, &stderr); code != exitUsage {
+		t.Errorf("extra argument exit code = %d, want %d", code, exitUsage)
+	}
+}
+
+// run runs a subcommand and returns its exit code and output.
+func run(t *testing.T, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := runCommand(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// decodeJSON decodes the JSON output of a subcommand into v.
+func decodeJSON(t *testing.T, output string, v any) {
+	t.Helper()
+	if err := json.Unmarshal([]byte(output), v); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, output)
+	}
+}
+
+// TestRunCommand_UsageErrors tests that invalid arguments exit with exitUsage.
+func TestRunCommand_UsageErrors(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out.idml")
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"unknown command", []string{"frobnicate"}},
+		{"missing file", []string{"info"}},
+		{"unknown flag", []string{"info", "--verbose", exampleIDML}},
+		{"export-idms without frame", []string{"export-idms", exampleIDML}},
+		{"cleanup without output", []string{"cleanup", exampleIDML}},
+		{"roundtrip without output", []string{"roundtrip", exampleIDML}},
+		{"new with unknown preset", []string{"new", "--preset", "a0", out}},
+		{"new without columns", []string{"new", "--columns", "0", out}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, stderr := run(t, tt.args...)
+			if code != exitUsage {
+				t.Errorf("exit code = %d, want %d", code, exitUsage)
+			}
+			if !strings.Contains(stderr, "idml") {
+				t.Errorf("stderr = %q, want a usage line", stderr)
+			}
+		})
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Errorf("rejected new command created %s", out)
+	}
+
+	// A missing input file is a failure, not a usage error
+	if code, _, _ := run(t, "info", filepath.Join(t.TempDir(), "missing.idml")); code != exitError {
+		t.Errorf("missing input exit code = %d, want %d", code, exitError)
+	}
+}
+
+// TestRunCommand_Validate tests the exit codes and JSON output of validate.
+func TestRunCommand_Validate(t *testing.T) {
+	// The example document refers to styles and colors it does not define
+	code, stdout, stderr := run(t, "validate", "--json", exampleIDML)
+	if code != exitInvalid {
+		t.Fatalf("exit code = %d, want %d; stderr = %s", code, exitInvalid, stderr)
+	}
+	var result validateResult
+	decodeJSON(t, stdout, &result)
+	if result.Valid || len(result.Issues) == 0 {
+		t.Errorf("result = %+v, want issues", result)
+	}
+	for _, issue := range result.Issues {
+		if issue.Type == "" || issue.Resource == "" || issue.Message == "" {
+			t.Errorf("incomplete issue %+v", issue)
+		}
+	}
+
+	// A new document has no problems
+	created := filepath.Join(t.TempDir(), "valid.idml")
+	if code, _, stderr := run(t, "new", created); code != exitOK {
+		t.Fatalf("new exit code = %d, stderr = %s", code, stderr)
+	}
+	code, stdout, _ = run(t, "validate", created)
+	if code != exitOK || !strings.Contains(stdout, "no problems found") {
+		t.Errorf("validate new document: exit code = %d, output = %q", code, stdout)
+	}
+}
+
+// TestRunCommand_ExportIDMS tests exporting a text frame as a snippet.
+func TestRunCommand_ExportIDMS(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "frame.idms")
+	code, stdout, stderr := run(t, "export-idms", exampleIDML, "--frame", "u234", "-o", out, "--json")
+	if code != exitOK {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr)
+	}
+	var result exportResult
+	decodeJSON(t, stdout, &result)
+	if result.Frame != "u234" || result.Output != out {
+		t.Errorf("result = %+v", result)
+	}
+
+	snippet, err := idms.Read(out)
+	if err != nil {
+		t.Fatalf("idms.Read failed: %v", err)
+	}
+	if len(snippet.Stories()) == 0 {
+		t.Error("snippet has no stories")
+	}
+
+	// Unknown frames fail
+	if code, _, _ := run(t, "export-idms", exampleIDML, "--frame", "u999", "-o", out); code != exitError {
+		t.Errorf("unknown frame exit code = %d, want %d", code, exitError)
+	}
+}
+
+// TestRunCommand_CleanupDryRun tests the JSON output of a dry run, which
+// lists what would be removed without writing a file.
+func TestRunCommand_CleanupDryRun(t *testing.T) {
+	code, stdout, stderr := run(t, "cleanup", "--dry-run", "--json", exampleIDML)
+	if code != exitOK {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr)
+	}
+
+	var raw map[string]any
+	decodeJSON(t, stdout, &raw)
+	for _, key := range []string{"removedFonts", "removedParagraphStyles", "removedCharacterStyles"} {
+		if _, ok := raw[key].([]any); !ok {
+			t.Errorf("%s = %v, want a list", key, raw[key])
+		}
+	}
+	if _, ok := raw["output"]; ok {
+		t.Errorf("dry run reports an output file: %v", raw["output"])
+	}
+
+	var result cleanupResult
+	decodeJSON(t, stdout, &result)
+	listed := len(result.RemovedFonts) + len(result.RemovedParagraphStyles) + len(result.RemovedCharacterStyles)
+	if result.File != exampleIDML || !result.DryRun || result.Removed == 0 || listed > result.Removed {
+		t.Errorf("result = %+v", result)
+	}
+}
+
+// TestRunCommand_New tests creating a document from a preset.
+func TestRunCommand_New(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "landscape")
+	code, stdout, stderr := run(t, "new", "--preset", "A4", "--landscape", "--columns", "3", "--gutter", "10", "--json", out)
+	if code != exitOK {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr)
+	}
+	var result newResult
+	decodeJSON(t, stdout, &result)
+	if result.Output != out+".idml" || result.Preset != "a4" || result.Orientation != "Landscape" ||
+		result.Columns != 3 || result.Gutter != 10 || result.Width <= result.Height {
+		t.Errorf("result = %+v", result)
+	}
+
+	pkg, err := idml.Read(result.Output)
+	if err != nil {
+		t.Fatalf("idml.Read failed: %v", err)
+	}
+	masters, err := pkg.MasterSpreads()
+	if err != nil {
+		t.Fatalf("MasterSpreads failed: %v", err)
+	}
+	for _, ms := range masters {
+		for _, page := range ms.InnerMasterSpread.Pages {
+			if page.MarginPreference == nil || page.MarginPreference.ColumnCount != "3" || page.MarginPreference.ColumnGutter != "10" {
+				t.Errorf("page %s margins = %+v, want 3 columns with a 10 pt gutter", page.Self, page.MarginPreference)
+			}
+		}
+	}
+	if len(masters) == 0 {
+		t.Error("new document has no master spreads")
+	}
+}
+
+// TestRunCommand_Roundtrip tests that roundtrip writes a readable copy.
+func TestRunCommand_Roundtrip(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "copy.idml")
+	code, stdout, stderr := run(t, "roundtrip", "--json", exampleIDML, out)
+	if code != exitOK {
+		t.Fatalf("exit code = %d, stderr = %s", code, stderr)
+	}
+	var result roundtripResult
+	decodeJSON(t, stdout, &result)
+	if result.Input != exampleIDML || result.Output != out || result.Stories == 0 || result.DOMVersion == "" {
+		t.Errorf("result = %+v", result)
+	}
+
+	pkg, err := idml.Read(out)
+	if err != nil {
+		t.Fatalf("idml.Read failed: %v", err)
+	}
+	if pkg.FileCount() != result.Files {
+		t.Errorf("copy has %d files, want %d", pkg.FileCount(), result.Files)
+	}
+}
@@ -1,4 +1,8 @@
 // IDML Builder - Main CLI Tool with Bubbletea TUI
+//
+// Without arguments the interactive menu is shown. With a subcommand
+// (info, text, export-idms, validate, cleanup, roundtrip, new) the tool runs
+// non-interactively, for use in scripts and CI. Run "idml help" for details.
 package main
 
 import (
@@ -11,6 +15,14 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(os.Args[1:], os.Stdout, os.Stderr))
+	}
+	runTUI()
+}
+
+// runTUI shows the interactive main menu until the user exits.
+func runTUI() {
 	for {
 		// Show main menu
 		menu := tui.NewMainMenu()
@@ -0,0 +1,159 @@
+// Package snippetfile holds the reading and writing shared by the single-file
+// XML formats IDMS and ICML: an XML declaration and <?aid ...?> processing
+// instructions followed by a root Document element.
+package snippetfile
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/document"
+)
+
+// Header is the part of a snippet file before the root Document element.
+type Header struct {
+	// XMLDeclaration is the XML declaration (e.g., <?xml version="1.0" encoding="UTF-8"?>)
+	XMLDeclaration string
+
+	// AIDProcessingInstructions contains the <?aid ...?> directives
+	AIDProcessingInstructions []document.ProcessingInstruction
+}
+
+// ReadFile reads the snippet file at path. Errors are reported for the
+// package pkgName, e.g. "idms".
+func ReadFile(pkgName, path string) ([]byte, error) {
+	// #nosec G304 - This is a library function; file path is intentionally provided by caller
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, common.WrapErrorWithPath(pkgName, "read", path, err)
+	}
+	return data, nil
+}
+
+// ReadHeader reads the XML declaration and AID processing instructions from
+// d up to the root element, which it returns. The root is nil if the data
+// ends without an element.
+func ReadHeader(d *xml.Decoder) (Header, *xml.StartElement, error) {
+	var h Header
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			return h, nil, nil
+		}
+		if err != nil {
+			return h, nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.ProcInst:
+			if t.Target == "xml" {
+				h.XMLDeclaration = fmt.Sprintf("<?xml %s?>", string(t.Inst))
+			} else if t.Target == "aid" {
+				// Trim trailing whitespace (original file may have varying spacing before ?>)
+				inst := strings.TrimRight(string(t.Inst), " \t")
+				h.AIDProcessingInstructions = append(h.AIDProcessingInstructions, document.ProcessingInstruction{
+					Target: t.Target,
+					Inst:   inst,
+				})
+			}
+
+		case xml.StartElement:
+			root := t.Copy()
+			return h, &root, nil
+		}
+	}
+}
+
+// Marshal serializes the header and doc to XML bytes. If trailer is not
+// empty it is written on its own line before the closing </Document> tag;
+// IDMS keeps its XMP packet there.
+func Marshal(h Header, doc *document.Document, trailer string) ([]byte, error) {
+	var buf bytes.Buffer
+
+	// 1. XML Declaration
+	buf.WriteString(h.XMLDeclaration)
+	buf.WriteString("\n")
+
+	// 2. AID Processing Instructions
+	for _, pi := range h.AIDProcessingInstructions {
+		buf.WriteString("<?")
+		buf.WriteString(pi.Target)
+		buf.WriteString(" ")
+		buf.WriteString(pi.Inst)
+		buf.WriteString(" ?>") // Space before ?> to match InDesign format
+		buf.WriteString("\n")
+	}
+
+	// 3. Document Element
+	docXML, err := document.MarshalDocument(doc)
+	if err != nil {
+		return nil, fmt.Errorf("marshal document: %w", err)
+	}
+
+	// Remove the XML declaration from docXML (it has its own)
+	if bytes.HasPrefix(docXML, []byte("<?")) {
+		if idx := bytes.Index(docXML, []byte("?>")); idx != -1 {
+			docXML = bytes.TrimLeft(docXML[idx+2:], " \t\n\r")
+		}
+	}
+
+	if trailer == "" {
+		buf.Write(docXML)
+		return buf.Bytes(), nil
+	}
+
+	// 4. Insert the trailer BEFORE the closing </Document> tag
+	if idx := bytes.LastIndex(docXML, []byte("</Document>")); idx != -1 {
+		buf.Write(docXML[:idx])
+		buf.WriteString("\n")
+		buf.WriteString(trailer)
+		buf.WriteString("\n")
+		buf.Write(docXML[idx:])
+	} else {
+		// Fallback: if </Document> not found, append the trailer
+		buf.Write(docXML)
+		buf.WriteString("\n")
+		buf.WriteString(trailer)
+	}
+	return buf.Bytes(), nil
+}
+
+// Write writes the output of marshal to path. Errors are reported for the
+// package pkgName.
+func Write(pkgName, path string, marshal func() ([]byte, error)) error {
+	data, err := marshal()
+	if err != nil {
+		return common.WrapErrorWithPath(pkgName, "marshal", path, err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return common.WrapErrorWithPath(pkgName, "write", path, err)
+	}
+
+	return nil
+}
+
+// WriteTo writes the output of marshal to w and returns the number of bytes
+// written. Errors are reported for the package pkgName.
+func WriteTo(pkgName string, w io.Writer, marshal func() ([]byte, error)) (int64, error) {
+	if w == nil {
+		return 0, common.Errorf(pkgName, "write to writer", "<stream>", "writer is nil")
+	}
+
+	data, err := marshal()
+	if err != nil {
+		return 0, common.WrapErrorWithPath(pkgName, "marshal", "<stream>", err)
+	}
+
+	n, err := w.Write(data)
+	if err != nil {
+		return int64(n), common.WrapErrorWithPath(pkgName, "write to writer", "<stream>", err)
+	}
+
+	return int64(n), nil
+}
@@ -0,0 +1,70 @@
+package snippetfile
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/dimelords/idmllib/v2/pkg/document"
+	"github.com/dimelords/idmllib/v2/pkg/story"
+)
+
+// TestReadHeader tests that the declaration and AID processing instructions
+// before the root element are read and the root element is returned.
+func TestReadHeader(t *testing.T) {
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<?aid style="50" type="snippet"  ?>
+<?aid SnippetType="InCopyInterchange"?>
+<Document DOMVersion="20.5"><?ACE 18?></Document>`
+
+	h, root, err := ReadHeader(xml.NewDecoder(strings.NewReader(data)))
+	if err != nil {
+		t.Fatalf("ReadHeader failed: %v", err)
+	}
+	if h.XMLDeclaration != `<?xml version="1.0" encoding="UTF-8"?>` {
+		t.Errorf("XMLDeclaration = %q", h.XMLDeclaration)
+	}
+	want := []document.ProcessingInstruction{
+		{Target: "aid", Inst: `style="50" type="snippet"`},
+		{Target: "aid", Inst: `SnippetType="InCopyInterchange"`},
+	}
+	if len(h.AIDProcessingInstructions) != len(want) {
+		t.Fatalf("AIDProcessingInstructions = %v, want %v", h.AIDProcessingInstructions, want)
+	}
+	for i := range want {
+		if h.AIDProcessingInstructions[i] != want[i] {
+			t.Errorf("AIDProcessingInstructions[%d] = %v, want %v", i, h.AIDProcessingInstructions[i], want[i])
+		}
+	}
+	if root == nil || root.Name.Local != "Document" {
+		t.Errorf("root = %v, want Document", root)
+	}
+
+	if _, root, err := ReadHeader(xml.NewDecoder(strings.NewReader(`<?xml version="1.0"?>`))); err != nil || root != nil {
+		t.Errorf("ReadHeader without root = %v, %v, want nil, nil", root, err)
+	}
+}
+
+// TestMarshal_Trailer tests that the trailer is written before the closing
+// Document tag.
+func TestMarshal_Trailer(t *testing.T) {
+	h := Header{
+		XMLDeclaration:            `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`,
+		AIDProcessingInstructions: []document.ProcessingInstruction{{Target: "aid", Inst: `SnippetType="PageItem"`}},
+	}
+	doc := &document.Document{DOMVersion: "20.5", InlineStories: []story.StoryElement{{Self: "u1"}}}
+	data, err := Marshal(h, doc, "<?xpacket end?>")
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte(h.XMLDeclaration+"\n<?aid SnippetType=\"PageItem\" ?>\n<Document")) {
+		t.Errorf("unexpected header:\n%s", data)
+	}
+	if !bytes.Contains(data, []byte("<?xpacket end?>\n</Document>")) {
+		t.Errorf("trailer not before </Document>:\n%s", data)
+	}
+	if bytes.Count(data, []byte("<?xml")) != 1 {
+		t.Errorf("document XML declaration not removed:\n%s", data)
+	}
+}
@@ -46,6 +46,8 @@
 //   - ParagraphStyles: Referenced paragraph style IDs
 //   - CharacterStyles: Referenced character style IDs
 //   - ObjectStyles: Referenced object style IDs
+//   - TableStyles: Referenced table style IDs
+//   - CellStyles: Referenced cell style IDs, including region cell styles of table styles
 //   - Colors: Referenced color IDs
 //   - Swatches: Referenced swatch IDs
 //   - Fonts: Referenced font families
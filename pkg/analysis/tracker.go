@@ -28,6 +28,15 @@ type DependencySet struct {
 	// Key: object style ID (e.g., "ObjectStyle/$ID/[Normal]")
 	ObjectStyles map[string]bool
 
+	// TableStyles tracks referenced table style IDs
+	// Key: table style ID (e.g., "TableStyle/$ID/[Basic Table]")
+	TableStyles map[string]bool
+
+	// CellStyles tracks referenced cell style IDs, including the region cell
+	// styles of referenced table styles
+	// Key: cell style ID (e.g., "CellStyle/$ID/[None]")
+	CellStyles map[string]bool
+
 	// Colors tracks referenced color IDs
 	// Key: color ID (e.g., "Color/Black")
 	Colors map[string]bool
@@ -60,6 +69,8 @@ func NewDependencySet() *DependencySet {
 		ParagraphStyles: make(map[string]bool),
 		CharacterStyles: make(map[string]bool),
 		ObjectStyles:    make(map[string]bool),
+		TableStyles:     make(map[string]bool),
+		CellStyles:      make(map[string]bool),
 		Colors:          make(map[string]bool),
 		Swatches:        make(map[string]bool),
 		Fonts:           make(map[string]bool),
@@ -131,11 +142,41 @@ func (dt *DependencyTracker) AnalyzeTextFrame(tf *spread.SpreadTextFrame) error
 // This includes:
 // - Paragraph styles used in the story
 // - Character styles used in the story
+// - Paragraph and character styles used in footnotes
+// - Table and cell styles of tables in the story, and the styles of their cell text
+// - Page items anchored in the story (see AnalyzeAnchoredObject)
 // - Fonts referenced by the styles (future enhancement)
 // - Colors used in the styles (future enhancement)
 func (dt *DependencyTracker) AnalyzeStory(story *story.Story) error {
+	dt.analyzeRanges(story.StoryElement.ParagraphStyleRanges)
+
+	// Analyze tables
+	dt.analyzeTables(story.Tables())
+
+	// Analyze footnote text
+	for _, footnote := range story.Footnotes() {
+		dt.analyzeRanges(footnote.ParagraphStyleRanges)
+	}
+
+	// Analyze note text
+	for _, note := range story.Notes() {
+		dt.analyzeRanges(note.ParagraphStyleRanges)
+	}
+
+	// Analyze anchored page items
+	for _, obj := range story.AnchoredObjects() {
+		if err := dt.AnalyzeAnchoredObject(obj); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// analyzeRanges tracks the paragraph and character styles of the given ranges.
+func (dt *DependencyTracker) analyzeRanges(ranges []story.ParagraphStyleRange) {
 	// Analyze each paragraph style range
-	for _, psr := range story.StoryElement.ParagraphStyleRanges {
+	for _, psr := range ranges {
 		// Track the paragraph style
 		if psr.AppliedParagraphStyle != "" {
 			dt.deps.ParagraphStyles[psr.AppliedParagraphStyle] = true
@@ -149,6 +190,65 @@ func (dt *DependencyTracker) AnalyzeStory(story *story.Story) error {
 			}
 		}
 	}
+}
+
+// analyzeTables tracks the table and cell styles of the given tables and the
+// styles of the text in their cells, descending into nested tables.
+func (dt *DependencyTracker) analyzeTables(tables []*story.Table) {
+	for _, table := range tables {
+		if table.AppliedTableStyle != "" {
+			dt.deps.TableStyles[table.AppliedTableStyle] = true
+		}
+		for i := range table.Cells {
+			cell := &table.Cells[i]
+			if cell.AppliedCellStyle != "" {
+				dt.deps.CellStyles[cell.AppliedCellStyle] = true
+			}
+			dt.analyzeRanges(cell.ParagraphStyleRanges)
+			dt.analyzeTables(cell.Tables())
+		}
+	}
+}
+
+// AnalyzeAnchoredObject analyzes a page item anchored in a story and tracks all its dependencies.
+// This includes:
+// - Object style applied to the item
+// - Fill and stroke colors set on the item
+// - Image and its dependencies if the item contains an image
+// - The story of an anchored text frame, which is analyzed in turn
+func (dt *DependencyTracker) AnalyzeAnchoredObject(obj *story.AnchoredObject) error {
+	// Track the applied object style
+	if style := obj.AppliedObjectStyle(); style != "" {
+		dt.deps.ObjectStyles[style] = true
+	}
+
+	// Track fill and stroke colors
+	for _, color := range obj.Colors() {
+		dt.deps.Colors[color] = true
+	}
+
+	// Analyze the image if present
+	if img := obj.Image(); img != nil {
+		if err := dt.AnalyzeImage(img); err != nil {
+			return err
+		}
+	}
+
+	// Track the story of an anchored text frame
+	if parentStory := obj.ParentStory(); parentStory != "" {
+		storyFilename := "Stories/Story_" + parentStory + ".xml"
+		if dt.deps.Stories[storyFilename] {
+			// Already analyzed
+			return nil
+		}
+		dt.deps.Stories[storyFilename] = true
+
+		story, err := dt.pkg.Story(storyFilename)
+		if err == nil {
+			return dt.AnalyzeStory(story)
+		}
+		// The story might not exist in the package - skip it
+	}
 
 	return nil
 }
@@ -386,6 +486,8 @@ func (dt *DependencyTracker) AnalyzeSelection(sel *idml.Selection) error {
 // - Paragraph style inheritance (BasedOn relationships)
 // - Character style inheritance (BasedOn relationships)
 // - Object style inheritance (BasedOn relationships)
+// - Table and cell style inheritance (BasedOn relationships)
+// - Region cell styles of table styles and paragraph styles of cell styles
 // - Circular reference detection (to prevent infinite loops)
 // - Multi-level inheritance (grandparent styles, etc.)
 func (dt *DependencyTracker) ResolveStyleHierarchies() error {
@@ -410,6 +512,23 @@ func (dt *DependencyTracker) ResolveStyleHierarchies() error {
 		}
 	}
 
+	// Resolve table style hierarchies first: table styles apply cell styles
+	// to their regions, and cell styles apply paragraph styles
+	tableStylesToResolve := make([]string, 0, len(dt.deps.TableStyles))
+	for styleID := range dt.deps.TableStyles {
+		tableStylesToResolve = append(tableStylesToResolve, styleID)
+	}
+	for _, styleID := range tableStylesToResolve {
+		if err := dt.resolveStyleChain(styleID, styleParents, dt.deps.TableStyles); err != nil {
+			return err
+		}
+	}
+	if len(dt.deps.TableStyles) > 0 || len(dt.deps.CellStyles) > 0 {
+		if err := dt.resolveTableStyleReferences(styleParents); err != nil {
+			return err
+		}
+	}
+
 	// Resolve paragraph style hierarchies
 	paragraphStylesToResolve := make([]string, 0, len(dt.deps.ParagraphStyles))
 	for styleID := range dt.deps.ParagraphStyles {
@@ -446,6 +565,41 @@ func (dt *DependencyTracker) ResolveStyleHierarchies() error {
 	return nil
 }
 
+// resolveTableStyleReferences adds the region cell styles of the tracked
+// table styles and the paragraph styles of the tracked cell styles, with the
+// BasedOn chains of the cell styles.
+func (dt *DependencyTracker) resolveTableStyleReferences(styleParents map[string]string) error {
+	styles, err := dt.pkg.Styles()
+	if err != nil {
+		return err
+	}
+
+	for styleID := range dt.deps.TableStyles {
+		if style := styles.FindTableStyle(styleID); style != nil {
+			for _, cellStyle := range style.RegionCellStyles() {
+				dt.deps.CellStyles[cellStyle] = true
+			}
+		}
+	}
+
+	cellStylesToResolve := make([]string, 0, len(dt.deps.CellStyles))
+	for styleID := range dt.deps.CellStyles {
+		cellStylesToResolve = append(cellStylesToResolve, styleID)
+	}
+	for _, styleID := range cellStylesToResolve {
+		if err := dt.resolveStyleChain(styleID, styleParents, dt.deps.CellStyles); err != nil {
+			return err
+		}
+	}
+
+	for styleID := range dt.deps.CellStyles {
+		if style := styles.FindCellStyle(styleID); style != nil && style.AppliedParagraphStyle != "" {
+			dt.deps.ParagraphStyles[style.AppliedParagraphStyle] = true
+		}
+	}
+	return nil
+}
+
 // resolveStyleChain recursively walks up the style hierarchy and adds all parent styles.
 // It handles circular references by tracking visited styles.
 func (dt *DependencyTracker) resolveStyleChain(styleID string, styleParents map[string]string, targetMap map[string]bool) error {
@@ -492,6 +646,8 @@ func (dt *DependencyTracker) Summary() DependencySummary {
 		ParagraphStylesCount: len(dt.deps.ParagraphStyles),
 		CharacterStylesCount: len(dt.deps.CharacterStyles),
 		ObjectStylesCount:    len(dt.deps.ObjectStyles),
+		TableStylesCount:     len(dt.deps.TableStyles),
+		CellStylesCount:      len(dt.deps.CellStyles),
 		ColorsCount:          len(dt.deps.Colors),
 		SwatchesCount:        len(dt.deps.Swatches),
 		FontsCount:           len(dt.deps.Fonts),
@@ -507,6 +663,8 @@ type DependencySummary struct {
 	ParagraphStylesCount int
 	CharacterStylesCount int
 	ObjectStylesCount    int
+	TableStylesCount     int
+	CellStylesCount      int
 	ColorsCount          int
 	SwatchesCount        int
 	FontsCount           int
@@ -1,6 +1,7 @@
 package analysis
 
 import (
+	"sort"
 	"testing"
 
 	"github.com/dimelords/idmllib/v2/pkg/idml"
@@ -182,6 +183,57 @@ func TestAnalyzeStory(t *testing.T) {
 	}
 }
 
+// TestAnalyzeStory_Tables tests that table and cell styles and the styles of
+// text in cells, including nested tables, are tracked.
+func TestAnalyzeStory_Tables(t *testing.T) {
+	st, err := story.ParseStory([]byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<idPkg:Story xmlns:idPkg="http://ns.adobe.com/AdobeInDesign/idml/1.0/packaging" DOMVersion="20.4">
+	<Story Self="u100">
+		<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/$ID/NormalParagraphStyle">
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+				<Table Self="u100i1" BodyRowCount="1" ColumnCount="1" AppliedTableStyle="TableStyle/Data">
+					<Cell Self="u100i1i0i0" Name="0:0" AppliedCellStyle="CellStyle/Body">
+						<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/Cell">
+							<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/Strong">
+								<Table Self="u100i2" BodyRowCount="1" ColumnCount="1" AppliedTableStyle="TableStyle/Nested">
+									<Cell Self="u100i2i0i0" Name="0:0" AppliedCellStyle="CellStyle/Inner" />
+								</Table>
+							</CharacterStyleRange>
+						</ParagraphStyleRange>
+					</Cell>
+				</Table>
+			</CharacterStyleRange>
+		</ParagraphStyleRange>
+	</Story>
+</idPkg:Story>`))
+	if err != nil {
+		t.Fatalf("ParseStory() error: %v", err)
+	}
+
+	tracker := &DependencyTracker{deps: NewDependencySet()}
+	if err := tracker.AnalyzeStory(st); err != nil {
+		t.Fatalf("AnalyzeStory() error: %v", err)
+	}
+	deps := tracker.Dependencies()
+
+	for _, id := range []string{"TableStyle/Data", "TableStyle/Nested"} {
+		if !deps.TableStyles[id] {
+			t.Errorf("table style %s not tracked", id)
+		}
+	}
+	for _, id := range []string{"CellStyle/Body", "CellStyle/Inner"} {
+		if !deps.CellStyles[id] {
+			t.Errorf("cell style %s not tracked", id)
+		}
+	}
+	if !deps.ParagraphStyles["ParagraphStyle/Cell"] || !deps.CharacterStyles["CharacterStyle/Strong"] {
+		t.Error("styles of text in cells not tracked")
+	}
+	if summary := tracker.Summary(); summary.TableStylesCount != 2 || summary.CellStylesCount != 2 {
+		t.Errorf("Summary() = %+v, want 2 table and 2 cell styles", summary)
+	}
+}
+
 // TestAnalyzeRectangle tests analyzing a rectangle
 func TestAnalyzeRectangle(t *testing.T) {
 	// Load test IDML with graphics
@@ -901,3 +953,110 @@ func TestAnalyzeSelection_WithOvalsPolygonsLines(t *testing.T) {
 
 	t.Log("✅ Selection with ovals, polygons, lines, and groups correctly analyzed")
 }
+
+// TestAnalyzeStory_AnchoredObjects tests that page items anchored in a story are followed
+func TestAnalyzeStory_AnchoredObjects(t *testing.T) {
+	pkg, err := idml.Read("../../testdata/example.idml")
+	if err != nil {
+		t.Fatalf("Failed to read IDML: %v", err)
+	}
+
+	stories, err := pkg.Stories()
+	if err != nil {
+		t.Fatalf("Failed to get stories: %v", err)
+	}
+	filenames := make([]string, 0, len(stories))
+	for filename := range stories {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	var host, anchored *story.Story
+	for _, filename := range filenames {
+		st := stories[filename]
+		if len(st.StoryElement.ParagraphStyleRanges) == 0 || len(st.StoryElement.ParagraphStyleRanges[0].CharacterStyleRanges) == 0 {
+			continue
+		}
+		if host == nil {
+			host = st
+		} else if anchored == nil {
+			anchored = st
+		}
+	}
+	if anchored == nil {
+		t.Skip("Need at least two stories with text")
+	}
+
+	// Anchor an image rectangle and a text frame showing the other story
+	rect := &story.AnchoredRectangle{}
+	rect.Self = "uanc1"
+	rect.AppliedObjectStyle = "ObjectStyle/Inline Image"
+	rect.Image = &spread.Image{Link: &spread.Link{Self: "uanc2", LinkResourceURI: "file:/images/logo.png"}}
+	frame := &story.AnchoredTextFrame{}
+	frame.Self = "uanc3"
+	frame.ParentStory = anchored.StoryElement.Self
+	frame.FillColor = "Color/Paper"
+
+	csr := &host.StoryElement.ParagraphStyleRanges[0].CharacterStyleRanges[0]
+	csr.Children = append(csr.Children,
+		story.CharacterChild{Anchored: &story.AnchoredObject{Rectangle: rect}},
+		story.CharacterChild{Anchored: &story.AnchoredObject{TextFrame: frame}},
+	)
+
+	tracker := NewDependencyTracker(pkg)
+	if err := tracker.AnalyzeStory(host); err != nil {
+		t.Fatalf("AnalyzeStory() error: %v", err)
+	}
+	deps := tracker.Dependencies()
+
+	if !deps.ObjectStyles["ObjectStyle/Inline Image"] {
+		t.Error("Expected anchored rectangle object style to be tracked")
+	}
+	if !deps.Links["file:/images/logo.png"] {
+		t.Error("Expected anchored image link to be tracked")
+	}
+	if !deps.Colors["Color/Paper"] {
+		t.Error("Expected anchored text frame fill color to be tracked")
+	}
+	storyFilename := "Stories/Story_" + anchored.StoryElement.Self + ".xml"
+	if !deps.Stories[storyFilename] {
+		t.Errorf("Expected anchored text frame story %s to be tracked", storyFilename)
+	}
+	for _, psr := range anchored.StoryElement.ParagraphStyleRanges {
+		if psr.AppliedParagraphStyle != "" && !deps.ParagraphStyles[psr.AppliedParagraphStyle] {
+			t.Errorf("Expected paragraph style %s of the anchored story to be tracked", psr.AppliedParagraphStyle)
+		}
+	}
+}
+
+// TestAnalyzeStory_Footnotes tests that styles used in footnote text are tracked
+func TestAnalyzeStory_Footnotes(t *testing.T) {
+	pkg, err := idml.Read("../../testdata/example.idml")
+	if err != nil {
+		t.Fatalf("Failed to read IDML: %v", err)
+	}
+
+	st, err := pkg.Story("Stories/Story_u2ee.xml")
+	if err != nil {
+		t.Fatalf("Failed to get story: %v", err)
+	}
+	fn, err := st.AddFootnote(0, "Source.", "ParagraphStyle/Footnote")
+	if err != nil {
+		t.Fatalf("AddFootnote failed: %v", err)
+	}
+	fn.ParagraphStyleRanges[0].CharacterStyleRanges[0].AppliedCharacterStyle = "CharacterStyle/Footnote Reference"
+
+	tracker := NewDependencyTracker(pkg)
+	if err := tracker.AnalyzeStory(st); err != nil {
+		t.Fatalf("AnalyzeStory failed: %v", err)
+	}
+
+	deps := tracker.Dependencies()
+
+	if !deps.ParagraphStyles["ParagraphStyle/Footnote"] {
+		t.Error("Footnote paragraph style not tracked")
+	}
+	if !deps.CharacterStyles["CharacterStyle/Footnote Reference"] {
+		t.Error("Footnote character style not tracked")
+	}
+}
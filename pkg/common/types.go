@@ -11,6 +11,7 @@ package common
 
 import (
 	"encoding/xml"
+	"strings"
 )
 
 // RawXMLElement represents an arbitrary XML element that hasn't been explicitly modeled yet.
@@ -162,6 +163,27 @@ func (p *Properties) GetBasedOn() string {
 	return ""
 }
 
+// GetLeading extracts the Leading value from Properties.OtherElements.
+// Returns the leading in points, "Auto", or empty string if not found.
+//
+// Leading is stored in the Properties element as:
+//
+//	<Leading type="unit">11.4</Leading>
+//	<Leading type="enumeration">Auto</Leading>
+func (p *Properties) GetLeading() string {
+	if p == nil {
+		return ""
+	}
+
+	for _, elem := range p.OtherElements {
+		if elem.XMLName.Local == "Leading" {
+			return strings.TrimSpace(string(elem.Content))
+		}
+	}
+
+	return ""
+}
+
 // GridDataInformation contains the detailed configuration for a grid.
 // This defines character/line spacing, alignment, and typographic settings.
 type GridDataInformation struct {
@@ -129,6 +129,61 @@ func TestProperties_GetBasedOn(t *testing.T) {
 	}
 }
 
+// TestProperties_GetLeading tests extracting Leading values from Properties.
+func TestProperties_GetLeading(t *testing.T) {
+	tests := []struct {
+		name     string
+		xml      string
+		expected string
+	}{
+		{
+			name: "Unit leading",
+			xml: `<Properties>
+				<BasedOn type="string">$ID/[No paragraph style]</BasedOn>
+				<Leading type="unit">11.4</Leading>
+			</Properties>`,
+			expected: "11.4",
+		},
+		{
+			name: "Auto leading",
+			xml: `<Properties>
+				<Leading type="enumeration">Auto</Leading>
+			</Properties>`,
+			expected: "Auto",
+		},
+		{
+			name: "No Leading",
+			xml: `<Properties>
+				<AppliedFont type="string">Polaris Condensed</AppliedFont>
+			</Properties>`,
+			expected: "",
+		},
+		{
+			name:     "Nil properties",
+			xml:      "",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var props *Properties
+
+			if tt.xml != "" {
+				props = &Properties{}
+				if err := xml.Unmarshal([]byte(tt.xml), props); err != nil {
+					t.Fatalf("Failed to unmarshal XML: %v", err)
+				}
+			}
+
+			got := props.GetLeading()
+			if got != tt.expected {
+				t.Errorf("GetLeading() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
 // TestProperties_GetAppliedFont_RealWorld tests with actual IDML XML structure.
 func TestProperties_GetAppliedFont_RealWorld(t *testing.T) {
 	// Real XML from IDML file (typical CharacterStyle Properties)
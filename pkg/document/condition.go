@@ -0,0 +1,203 @@
+package document
+
+import (
+	"encoding/xml"
+	"strconv"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+)
+
+// Condition is a conditional text condition. Character ranges refer to it by
+// Self in their AppliedConditions attribute; text is shown when at least one
+// of its conditions is visible.
+type Condition struct {
+	XMLName xml.Name `xml:"Condition"`
+
+	// Identification
+	Self string `xml:"Self,attr"` // Unique identifier (e.g., "Condition/Norway")
+	Name string `xml:"Name,attr"` // Display name (e.g., "Norway")
+
+	// Appearance
+	IndicatorMethod              string `xml:"IndicatorMethod,attr,omitempty"`              // e.g., "UseHighlight", "UseUnderline"
+	UnderlineIndicatorAppearance string `xml:"UnderlineIndicatorAppearance,attr,omitempty"` // e.g., "Wavy", "Solid"
+	Visible                      string `xml:"Visible,attr,omitempty"`                      // "true"/"false"
+
+	// Catch-all for other attributes
+	OtherAttrs []xml.Attr `xml:",any,attr"`
+
+	// Properties hold the indicator color
+	Properties *ConditionProperties `xml:"Properties,omitempty"`
+}
+
+// ConditionProperties is the Properties element of a Condition.
+type ConditionProperties struct {
+	XMLName xml.Name `xml:"Properties"`
+
+	IndicatorColor *common.RawXMLElement `xml:"IndicatorColor,omitempty"` // An enumeration or a list of RGB values
+
+	// Catch-all for other Properties children
+	OtherElements []common.RawXMLElement `xml:",any"`
+}
+
+// ConditionSet is a named snapshot of condition visibility, such as
+// "Norwegian edition".
+type ConditionSet struct {
+	XMLName xml.Name `xml:"ConditionSet"`
+
+	// Identification
+	Self string `xml:"Self,attr"` // Unique identifier
+	Name string `xml:"Name,attr"` // Display name
+
+	// Catch-all for other attributes
+	OtherAttrs []xml.Attr `xml:",any,attr"`
+
+	// Properties hold the visibility of each condition
+	Properties *ConditionSetProperties `xml:"Properties,omitempty"`
+}
+
+// ConditionSetProperties is the Properties element of a ConditionSet.
+type ConditionSetProperties struct {
+	XMLName xml.Name `xml:"Properties"`
+
+	// SetConditions is a list of (condition, visible) pairs
+	SetConditions *ListProperty `xml:"SetConditions,omitempty"`
+
+	// Catch-all for other Properties children
+	OtherElements []common.RawXMLElement `xml:",any"`
+}
+
+// ListProperty is a list-valued property such as
+// <SetConditions type="list"><ListItem type="list">...</ListItem></SetConditions>.
+type ListProperty struct {
+	Type  string     `xml:"type,attr,omitempty"`
+	Items []ListItem `xml:"ListItem"`
+}
+
+// ListItem is an item of a ListProperty. Items of type "list" hold nested
+// items; other items hold a value.
+type ListItem struct {
+	Type  string     `xml:"type,attr,omitempty"` // e.g., "list", "object", "boolean"
+	Value string     `xml:",chardata"`
+	Items []ListItem `xml:"ListItem,omitempty"`
+}
+
+// UnmarshalXML drops the indentation between the nested items of list items,
+// so that it is not written back next to new indentation.
+func (li *ListItem) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type plain ListItem
+	if err := d.DecodeElement((*plain)(li), &start); err != nil {
+		return err
+	}
+	if len(li.Items) > 0 {
+		li.Value = ""
+	}
+	return nil
+}
+
+// ConditionalTextPreference holds the document's conditional text settings.
+type ConditionalTextPreference struct {
+	XMLName xml.Name `xml:"ConditionalTextPreference"`
+
+	ShowConditionIndicators string `xml:"ShowConditionIndicators,attr,omitempty"` // e.g., "ShowIndicators"
+	ActiveConditionSet      string `xml:"ActiveConditionSet,attr,omitempty"`      // Self of the active ConditionSet, or "n"
+
+	// Catch-all for other attributes and children
+	OtherAttrs    []xml.Attr             `xml:",any,attr"`
+	OtherElements []common.RawXMLElement `xml:",any"`
+}
+
+// Conditions returns the visibility of each condition in the set, keyed by
+// condition Self.
+func (cs *ConditionSet) Conditions() map[string]bool {
+	conditions := make(map[string]bool)
+	if cs.Properties == nil || cs.Properties.SetConditions == nil {
+		return conditions
+	}
+	for _, pair := range cs.Properties.SetConditions.Items {
+		if len(pair.Items) == 2 {
+			conditions[pair.Items[0].Value] = pair.Items[1].Value == "true"
+		}
+	}
+	return conditions
+}
+
+// SetCondition sets the visibility of a condition in the set, adding it if needed.
+func (cs *ConditionSet) SetCondition(conditionID string, visible bool) {
+	if cs.Properties == nil {
+		cs.Properties = &ConditionSetProperties{XMLName: xml.Name{Local: "Properties"}}
+	}
+	if cs.Properties.SetConditions == nil {
+		cs.Properties.SetConditions = &ListProperty{Type: "list"}
+	}
+
+	value := strconv.FormatBool(visible)
+	items := cs.Properties.SetConditions.Items
+	for i := range items {
+		if len(items[i].Items) == 2 && items[i].Items[0].Value == conditionID {
+			items[i].Items[1].Value = value
+			return
+		}
+	}
+	cs.Properties.SetConditions.Items = append(items, ListItem{
+		Type: "list",
+		Items: []ListItem{
+			{Type: "object", Value: conditionID},
+			{Type: "boolean", Value: value},
+		},
+	})
+}
+
+// FindCondition returns the condition with the given Self or name.
+// Returns common.ErrNotFound if there is none.
+func (d *Document) FindCondition(idOrName string) (*Condition, error) {
+	for i := range d.Conditions {
+		if d.Conditions[i].Self == idOrName || d.Conditions[i].Name == idOrName {
+			return &d.Conditions[i], nil
+		}
+	}
+	return nil, common.WrapErrorWithPath("document", "find condition", idOrName, common.ErrNotFound)
+}
+
+// FindConditionSet returns the condition set with the given Self or name.
+// Returns common.ErrNotFound if there is none.
+func (d *Document) FindConditionSet(idOrName string) (*ConditionSet, error) {
+	for i := range d.ConditionSets {
+		if d.ConditionSets[i].Self == idOrName || d.ConditionSets[i].Name == idOrName {
+			return &d.ConditionSets[i], nil
+		}
+	}
+	return nil, common.WrapErrorWithPath("document", "find condition set", idOrName, common.ErrNotFound)
+}
+
+// VisibleConditions returns the visibility of each condition as currently set
+// in the document, keyed by condition Self. The result can be passed to
+// story.Story.ExtractTextWithConditions.
+func (d *Document) VisibleConditions() map[string]bool {
+	visible := make(map[string]bool, len(d.Conditions))
+	for _, c := range d.Conditions {
+		visible[c.Self] = c.Visible != "false"
+	}
+	return visible
+}
+
+// ApplyConditionSet makes the condition set with the given Self or name
+// active: the Visible attribute of each condition in the set is updated and
+// the set is recorded in ConditionalTextPreference.
+// Returns common.ErrNotFound if there is no such set.
+func (d *Document) ApplyConditionSet(idOrName string) error {
+	cs, err := d.FindConditionSet(idOrName)
+	if err != nil {
+		return err
+	}
+	conditions := cs.Conditions()
+	for i := range d.Conditions {
+		if visible, ok := conditions[d.Conditions[i].Self]; ok {
+			d.Conditions[i].Visible = strconv.FormatBool(visible)
+		}
+	}
+	if d.ConditionalTextPreference == nil {
+		d.ConditionalTextPreference = &ConditionalTextPreference{XMLName: xml.Name{Local: "ConditionalTextPreference"}}
+	}
+	d.ConditionalTextPreference.ActiveConditionSet = cs.Self
+	return nil
+}
@@ -98,6 +98,22 @@ type Document struct {
 	// Step 9: Text Variables
 	TextVariables []TextVariable `xml:"TextVariable,omitempty"`
 
+	// Step 9b: Conditional Text
+	// Character ranges in stories refer to conditions in AppliedConditions.
+	ConditionalTextPreference *ConditionalTextPreference `xml:"ConditionalTextPreference,omitempty"`
+	Conditions                []Condition                `xml:"Condition,omitempty"`
+	ConditionSets             []ConditionSet             `xml:"ConditionSet,omitempty"`
+
+	// Step 10: Hyperlinks and Cross-References
+	// Hyperlink and cross-reference text sources and text destinations live in
+	// stories. These elements are written after the IDMS inline content, so
+	// that hyperlinks follow the stories holding their sources.
+	CrossReferenceFormats     []CrossReferenceFormat     `xml:"CrossReferenceFormat,omitempty"`
+	HyperlinkURLDestinations  []HyperlinkURLDestination  `xml:"HyperlinkURLDestination,omitempty"`
+	HyperlinkPageDestinations []HyperlinkPageDestination `xml:"HyperlinkPageDestination,omitempty"`
+	HyperlinkPageItemSources  []HyperlinkPageItemSource  `xml:"HyperlinkPageItemSource,omitempty"`
+	Hyperlinks                []Hyperlink                `xml:"Hyperlink,omitempty"`
+
 	// ========================================================================
 	// Phase 4.3: IDMS Inline Resources
 	// ========================================================================
@@ -118,6 +134,8 @@ type Document struct {
 	// Note: Style groups use resources types (Phase 5c complete)
 	RootCharacterStyleGroup *resources.CharacterStyleGroup `xml:"RootCharacterStyleGroup,omitempty"`
 	RootParagraphStyleGroup *resources.ParagraphStyleGroup `xml:"RootParagraphStyleGroup,omitempty"`
+	RootCellStyleGroup      *resources.CellStyleGroup      `xml:"RootCellStyleGroup,omitempty"`
+	RootTableStyleGroup     *resources.TableStyleGroup     `xml:"RootTableStyleGroup,omitempty"`
 	RootObjectStyleGroup    *resources.ObjectStyleGroup    `xml:"RootObjectStyleGroup,omitempty"`
 
 	// Inline Content (instead of Spreads/Stories ResourceRefs)
@@ -131,9 +149,9 @@ type Document struct {
 	TransparencyDefaultContainerObject *TransparencyDefaultContainerObject `xml:"TransparencyDefaultContainerObject,omitempty"`
 
 	// Catch-all for all other child elements not yet explicitly modeled.
-	// This includes: KinsokuTable, MojikumiTable, CrossReferenceFormat,
-	// ConditionalTextPreference, EndnoteOption, WatermarkPreference, IndexingSortOption,
-	// LinkedStoryOption, LinkedPageItemOption, and many more.
+	// This includes: KinsokuTable, MojikumiTable, EndnoteOption,
+	// WatermarkPreference, IndexingSortOption, LinkedStoryOption,
+	// LinkedPageItemOption, and many more.
 	// As we add explicit support for more elements, they move from OtherElements
 	// to dedicated fields above.
 	OtherElements []common.RawXMLElement `xml:",any"`
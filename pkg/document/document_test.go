@@ -3,9 +3,11 @@ package document_test
 import (
 	"bytes"
 	"encoding/xml"
+	"errors"
 	"testing"
 
 	"github.com/dimelords/idmllib/v2/internal/testutil"
+	"github.com/dimelords/idmllib/v2/pkg/common"
 	"github.com/dimelords/idmllib/v2/pkg/document"
 	"github.com/dimelords/idmllib/v2/pkg/idml"
 	"github.com/google/go-cmp/cmp"
@@ -860,3 +862,175 @@ func TestDocumentTextVariablesRoundtrip(t *testing.T) {
 		}
 	}
 }
+
+// conditionsDesignmap is a designmap with two conditions and a condition set.
+const conditionsDesignmap = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Document xmlns:idPkg="http://ns.adobe.com/AdobeInDesign/idml/1.0/packaging" DOMVersion="20.4" Self="d">
+	<ConditionalTextPreference ShowConditionIndicators="ShowIndicators" ActiveConditionSet="n" />
+	<Condition Self="Condition/Norway" Name="Norway" IndicatorMethod="UseHighlight" UnderlineIndicatorAppearance="Wavy" Visible="true">
+		<Properties>
+			<IndicatorColor type="enumeration">LightBlue</IndicatorColor>
+		</Properties>
+	</Condition>
+	<Condition Self="Condition/Sweden" Name="Sweden" IndicatorMethod="UseHighlight" Visible="true" />
+	<ConditionSet Self="u1a0" Name="Swedish edition">
+		<Properties>
+			<SetConditions type="list">
+				<ListItem type="list">
+					<ListItem type="object">Condition/Norway</ListItem>
+					<ListItem type="boolean">false</ListItem>
+				</ListItem>
+				<ListItem type="list">
+					<ListItem type="object">Condition/Sweden</ListItem>
+					<ListItem type="boolean">true</ListItem>
+				</ListItem>
+			</SetConditions>
+		</Properties>
+	</ConditionSet>
+</Document>`
+
+// TestDocumentConditions tests parsing conditions and condition sets and
+// applying a condition set.
+func TestDocumentConditions(t *testing.T) {
+	doc, err := document.ParseDocument([]byte(conditionsDesignmap))
+	if err != nil {
+		t.Fatalf("document.ParseDocument() error = %v", err)
+	}
+
+	if len(doc.Conditions) != 2 || len(doc.ConditionSets) != 1 || doc.ConditionalTextPreference == nil {
+		t.Fatalf("Conditions = %+v, ConditionSets = %+v", doc.Conditions, doc.ConditionSets)
+	}
+	norway, err := doc.FindCondition("Norway")
+	if err != nil {
+		t.Fatalf("FindCondition() error = %v", err)
+	}
+	if norway.Self != "Condition/Norway" || norway.Properties == nil || norway.Properties.IndicatorColor == nil {
+		t.Errorf("Norway = %+v", norway)
+	}
+	if _, err := doc.FindCondition("Denmark"); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("FindCondition(Denmark) error = %v, want ErrNotFound", err)
+	}
+
+	set, err := doc.FindConditionSet("Swedish edition")
+	if err != nil {
+		t.Fatalf("FindConditionSet() error = %v", err)
+	}
+	want := map[string]bool{"Condition/Norway": false, "Condition/Sweden": true}
+	if diff := cmp.Diff(want, set.Conditions()); diff != "" {
+		t.Errorf("Conditions() mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := doc.ApplyConditionSet("u1a0"); err != nil {
+		t.Fatalf("ApplyConditionSet() error = %v", err)
+	}
+	if diff := cmp.Diff(want, doc.VisibleConditions()); diff != "" {
+		t.Errorf("VisibleConditions() mismatch (-want +got):\n%s", diff)
+	}
+	if doc.ConditionalTextPreference.ActiveConditionSet != "u1a0" {
+		t.Errorf("ActiveConditionSet = %q, want u1a0", doc.ConditionalTextPreference.ActiveConditionSet)
+	}
+
+	set.SetCondition("Condition/Denmark", true)
+	if !set.Conditions()["Condition/Denmark"] {
+		t.Error("SetCondition did not add Condition/Denmark")
+	}
+}
+
+// TestDocumentConditionsRoundtrip tests that conditions survive marshaling.
+func TestDocumentConditionsRoundtrip(t *testing.T) {
+	doc, err := document.ParseDocument([]byte(conditionsDesignmap))
+	if err != nil {
+		t.Fatalf("document.ParseDocument() error = %v", err)
+	}
+	data, err := document.MarshalDocument(doc)
+	if err != nil {
+		t.Fatalf("document.MarshalDocument() error = %v", err)
+	}
+	reparsed, err := document.ParseDocument(data)
+	if err != nil {
+		t.Fatalf("document.ParseDocument() (second) error = %v", err)
+	}
+	if diff := cmp.Diff(doc.Conditions, reparsed.Conditions); diff != "" {
+		t.Errorf("Conditions mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(doc.ConditionSets, reparsed.ConditionSets); diff != "" {
+		t.Errorf("ConditionSets mismatch (-want +got):\n%s", diff)
+	}
+	if len(reparsed.OtherElements) != 0 {
+		t.Errorf("OtherElements = %+v, want conditions to be typed", reparsed.OtherElements)
+	}
+}
+
+// hyperlinksSnippet is a snippet-style document with an inline story holding
+// a text source and a page item hyperlink source, written by InDesign with
+// the hyperlinks after the sources.
+const hyperlinksSnippet = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Document DOMVersion="20.4" Self="d">
+	<Story Self="u300">
+		<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/$ID/NormalParagraphStyle">
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+				<HyperlinkTextSource Self="u310" Name="Text link" Hidden="false">
+					<Content>Example</Content>
+				</HyperlinkTextSource>
+			</CharacterStyleRange>
+		</ParagraphStyleRange>
+	</Story>
+	<HyperlinkURLDestination Self="u320" Name="https://example.com" DestinationURL="https://example.com" DestinationUniqueKey="1" />
+	<HyperlinkPageItemSource Self="u330" Name="Frame link" SourcePageItem="u340" Hidden="false" />
+	<Hyperlink Self="u350" Name="Text link" Source="u310" Visible="false" DestinationUniqueKey="1">
+		<Properties>
+			<Destination type="object">u320</Destination>
+		</Properties>
+	</Hyperlink>
+	<Hyperlink Self="u360" Name="Frame link" Source="u330" Visible="false" DestinationUniqueKey="1">
+		<Properties>
+			<Destination type="object">u320</Destination>
+		</Properties>
+	</Hyperlink>
+</Document>`
+
+// TestDocumentHyperlinksRoundtrip tests that page item sources are typed and
+// that hyperlinks are written after the stories and page item sources they
+// refer to.
+func TestDocumentHyperlinksRoundtrip(t *testing.T) {
+	doc, err := document.ParseDocument([]byte(hyperlinksSnippet))
+	if err != nil {
+		t.Fatalf("document.ParseDocument() error = %v", err)
+	}
+	if len(doc.HyperlinkPageItemSources) != 1 || doc.HyperlinkPageItemSources[0].SourcePageItem != "u340" {
+		t.Fatalf("HyperlinkPageItemSources = %+v", doc.HyperlinkPageItemSources)
+	}
+	if len(doc.OtherElements) != 0 {
+		t.Errorf("OtherElements = %+v, want hyperlink elements to be typed", doc.OtherElements)
+	}
+
+	data, err := document.MarshalDocument(doc)
+	if err != nil {
+		t.Fatalf("document.MarshalDocument() error = %v", err)
+	}
+	at := 0
+	for _, want := range []string{
+		`<Story Self="u300"`,
+		`<HyperlinkURLDestination Self="u320"`,
+		`<HyperlinkPageItemSource Self="u330"`,
+		`<Hyperlink Self="u350"`,
+		`<Hyperlink Self="u360"`,
+	} {
+		i := bytes.Index(data[at:], []byte(want))
+		if i < 0 {
+			t.Fatalf("output is missing %s after offset %d:\n%s", want, at, data)
+		}
+		at += i + len(want)
+	}
+
+	reparsed, err := document.ParseDocument(data)
+	if err != nil {
+		t.Fatalf("document.ParseDocument() (second) error = %v", err)
+	}
+	if diff := cmp.Diff(doc.HyperlinkPageItemSources, reparsed.HyperlinkPageItemSources); diff != "" {
+		t.Errorf("HyperlinkPageItemSources mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(doc.Hyperlinks, reparsed.Hyperlinks); diff != "" {
+		t.Errorf("Hyperlinks mismatch (-want +got):\n%s", diff)
+	}
+}
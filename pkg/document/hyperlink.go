@@ -0,0 +1,159 @@
+package document
+
+import (
+	"encoding/xml"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+)
+
+// Hyperlink connects a hyperlink or cross-reference source with a destination.
+// Sources live in stories (HyperlinkTextSource, CrossReferenceSource) or in
+// designmap.xml (HyperlinkPageItemSource); the destination is referenced by
+// ID in Properties/Destination.
+type Hyperlink struct {
+	XMLName xml.Name `xml:"Hyperlink"`
+
+	// Identification
+	Self   string `xml:"Self,attr"`           // Unique identifier (e.g., "u1b4")
+	Name   string `xml:"Name,attr,omitempty"` // Display name (e.g., "Hyperlink 1")
+	Source string `xml:"Source,attr"`         // Self of the hyperlink or cross-reference source
+
+	// Appearance
+	Visible     string `xml:"Visible,attr,omitempty"`     // Show border ("true"/"false")
+	Highlight   string `xml:"Highlight,attr,omitempty"`   // Highlight mode (e.g., "None", "Invert")
+	Width       string `xml:"Width,attr,omitempty"`       // Border width (e.g., "Thin")
+	BorderStyle string `xml:"BorderStyle,attr,omitempty"` // Border style (e.g., "Solid")
+	Hidden      string `xml:"Hidden,attr,omitempty"`      // Hidden in the UI ("true"/"false")
+
+	// Key of the destination at the time of export
+	DestinationUniqueKey string `xml:"DestinationUniqueKey,attr,omitempty"`
+
+	// Catch-all for other attributes
+	OtherAttrs []xml.Attr `xml:",any,attr"`
+
+	// Properties hold the border color and the destination reference
+	Properties *HyperlinkProperties `xml:"Properties,omitempty"`
+}
+
+// HyperlinkProperties is the Properties element of a Hyperlink.
+type HyperlinkProperties struct {
+	XMLName xml.Name `xml:"Properties"`
+
+	BorderColor *common.RawXMLElement `xml:"BorderColor,omitempty"` // Border color (an enumeration or a list of RGB values)
+	Destination *PropertyValue        `xml:"Destination,omitempty"` // Destination ID (type="object")
+
+	// Catch-all for other Properties children
+	OtherElements []common.RawXMLElement `xml:",any"`
+}
+
+// PropertyValue is a typed value inside a Properties element, such as
+// <Destination type="object">u1b2</Destination>.
+type PropertyValue struct {
+	Type  string `xml:"type,attr,omitempty"` // Value type (e.g., "object", "enumeration", "string")
+	Value string `xml:",chardata"`
+}
+
+// DestinationID returns the ID of the hyperlink's destination, or "" if none is set.
+func (h *Hyperlink) DestinationID() string {
+	if h.Properties == nil || h.Properties.Destination == nil {
+		return ""
+	}
+	return h.Properties.Destination.Value
+}
+
+// SetDestinationID points the hyperlink at the destination with the given ID.
+func (h *Hyperlink) SetDestinationID(id string) {
+	if h.Properties == nil {
+		h.Properties = &HyperlinkProperties{XMLName: xml.Name{Local: "Properties"}}
+	}
+	h.Properties.Destination = &PropertyValue{Type: "object", Value: id}
+}
+
+// HyperlinkURLDestination is a URL that hyperlinks can point to.
+type HyperlinkURLDestination struct {
+	XMLName xml.Name `xml:"HyperlinkURLDestination"`
+
+	// Identification
+	Self                 string `xml:"Self,attr"`                           // Unique identifier
+	Name                 string `xml:"Name,attr,omitempty"`                 // Display name (usually the URL)
+	DestinationUniqueKey string `xml:"DestinationUniqueKey,attr,omitempty"` // Numeric key unique per document
+
+	// Target
+	DestinationURL string `xml:"DestinationURL,attr"`   // URL (e.g., "https://example.com")
+	Hidden         string `xml:"Hidden,attr,omitempty"` // Hidden in the UI ("true"/"false")
+
+	// Catch-all for other attributes and children
+	OtherAttrs    []xml.Attr             `xml:",any,attr"`
+	OtherElements []common.RawXMLElement `xml:",any"`
+}
+
+// HyperlinkPageDestination is a page that hyperlinks can point to.
+type HyperlinkPageDestination struct {
+	XMLName xml.Name `xml:"HyperlinkPageDestination"`
+
+	// Identification
+	Self                 string `xml:"Self,attr"`                           // Unique identifier
+	Name                 string `xml:"Name,attr,omitempty"`                 // Display name
+	DestinationUniqueKey string `xml:"DestinationUniqueKey,attr,omitempty"` // Numeric key unique per document
+
+	// Target
+	DestinationPage string `xml:"DestinationPage,attr,omitempty"` // Self of the target page
+	ViewSetting     string `xml:"ViewSetting,attr,omitempty"`     // Zoom setting (e.g., "FitView")
+	Hidden          string `xml:"Hidden,attr,omitempty"`          // Hidden in the UI ("true"/"false")
+
+	// Catch-all for other attributes and children
+	OtherAttrs    []xml.Attr             `xml:",any,attr"`
+	OtherElements []common.RawXMLElement `xml:",any"`
+}
+
+// HyperlinkPageItemSource marks a page item, such as a graphic frame, as the
+// source of a hyperlink.
+type HyperlinkPageItemSource struct {
+	XMLName xml.Name `xml:"HyperlinkPageItemSource"`
+
+	// Identification
+	Self string `xml:"Self,attr"`           // Unique identifier
+	Name string `xml:"Name,attr,omitempty"` // Display name
+
+	// Source
+	SourcePageItem string `xml:"SourcePageItem,attr"`   // Self of the page item
+	Hidden         string `xml:"Hidden,attr,omitempty"` // Hidden in the UI ("true"/"false")
+
+	// Catch-all for other attributes and children
+	OtherAttrs    []xml.Attr             `xml:",any,attr"`
+	OtherElements []common.RawXMLElement `xml:",any"`
+}
+
+// CrossReferenceFormat defines how the text of a cross-reference is built.
+type CrossReferenceFormat struct {
+	XMLName xml.Name `xml:"CrossReferenceFormat"`
+
+	// Identification
+	Self string `xml:"Self,attr"` // Unique identifier (e.g., "u374")
+	Name string `xml:"Name,attr"` // Display name (e.g., "Full Paragraph & Page Number")
+
+	// Character style applied to the whole cross-reference
+	AppliedCharacterStyle string `xml:"AppliedCharacterStyle,attr,omitempty"`
+
+	// Building blocks in order
+	BuildingBlocks []BuildingBlock `xml:"BuildingBlock,omitempty"`
+
+	// Catch-all for other CrossReferenceFormat children
+	OtherElements []common.RawXMLElement `xml:",any"`
+}
+
+// BuildingBlock is one part of a cross-reference format, such as custom text
+// or the page number of the destination.
+type BuildingBlock struct {
+	XMLName xml.Name `xml:"BuildingBlock"`
+
+	Self                  string `xml:"Self,attr"`                            // Unique identifier
+	BlockType             string `xml:"BlockType,attr"`                       // e.g., "CustomStringBuildingBlock", "PageNumberBuildingBlock"
+	AppliedCharacterStyle string `xml:"AppliedCharacterStyle,attr,omitempty"` // Character style of this block
+	CustomText            string `xml:"CustomText,attr,omitempty"`            // Text for custom string blocks
+	AppliedDelimiter      string `xml:"AppliedDelimiter,attr,omitempty"`      // Delimiter for partial paragraph blocks
+	IncludeDelimiter      string `xml:"IncludeDelimiter,attr,omitempty"`      // Include the delimiter ("true"/"false")
+
+	// Catch-all for other attributes
+	OtherAttrs []xml.Attr `xml:",any,attr"`
+}
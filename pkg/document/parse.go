@@ -211,6 +211,62 @@ func (d *Document) unmarshalChildElement(decoder *xml.Decoder, start xml.StartEl
 		}
 		d.TextVariables = append(d.TextVariables, tv)
 
+	case "ConditionalTextPreference":
+		var pref ConditionalTextPreference
+		if err := decoder.DecodeElement(&pref, &start); err != nil {
+			return common.WrapError("document", "parse document", err)
+		}
+		d.ConditionalTextPreference = &pref
+
+	case "Condition":
+		var condition Condition
+		if err := decoder.DecodeElement(&condition, &start); err != nil {
+			return common.WrapError("document", "parse document", err)
+		}
+		d.Conditions = append(d.Conditions, condition)
+
+	case "ConditionSet":
+		var set ConditionSet
+		if err := decoder.DecodeElement(&set, &start); err != nil {
+			return common.WrapError("document", "parse document", err)
+		}
+		d.ConditionSets = append(d.ConditionSets, set)
+
+	case "CrossReferenceFormat":
+		var format CrossReferenceFormat
+		if err := decoder.DecodeElement(&format, &start); err != nil {
+			return common.WrapError("document", "parse document", err)
+		}
+		d.CrossReferenceFormats = append(d.CrossReferenceFormats, format)
+
+	case "HyperlinkURLDestination":
+		var dest HyperlinkURLDestination
+		if err := decoder.DecodeElement(&dest, &start); err != nil {
+			return common.WrapError("document", "parse document", err)
+		}
+		d.HyperlinkURLDestinations = append(d.HyperlinkURLDestinations, dest)
+
+	case "HyperlinkPageDestination":
+		var dest HyperlinkPageDestination
+		if err := decoder.DecodeElement(&dest, &start); err != nil {
+			return common.WrapError("document", "parse document", err)
+		}
+		d.HyperlinkPageDestinations = append(d.HyperlinkPageDestinations, dest)
+
+	case "HyperlinkPageItemSource":
+		var src HyperlinkPageItemSource
+		if err := decoder.DecodeElement(&src, &start); err != nil {
+			return common.WrapError("document", "parse document", err)
+		}
+		d.HyperlinkPageItemSources = append(d.HyperlinkPageItemSources, src)
+
+	case "Hyperlink":
+		var link Hyperlink
+		if err := decoder.DecodeElement(&link, &start); err != nil {
+			return common.WrapError("document", "parse document", err)
+		}
+		d.Hyperlinks = append(d.Hyperlinks, link)
+
 	// IDMS inline resources (used in snippets instead of separate files)
 	case "Color":
 		var color resources.Color
@@ -247,6 +303,20 @@ func (d *Document) unmarshalChildElement(decoder *xml.Decoder, start xml.StartEl
 		}
 		d.RootParagraphStyleGroup = &group
 
+	case "RootCellStyleGroup":
+		var group resources.CellStyleGroup
+		if err := decoder.DecodeElement(&group, &start); err != nil {
+			return common.WrapError("document", "parse document", err)
+		}
+		d.RootCellStyleGroup = &group
+
+	case "RootTableStyleGroup":
+		var group resources.TableStyleGroup
+		if err := decoder.DecodeElement(&group, &start); err != nil {
+			return common.WrapError("document", "parse document", err)
+		}
+		d.RootTableStyleGroup = &group
+
 	case "RootObjectStyleGroup":
 		var group resources.ObjectStyleGroup
 		if err := decoder.DecodeElement(&group, &start); err != nil {
@@ -520,7 +590,24 @@ func (d Document) marshalChildren(encoder *xml.Encoder) error {
 		}
 	}
 
-	// 13. IDMS inline content (colors, swatches, styles, spreads, stories)
+	// 13. Conditional text preference, conditions and condition sets
+	if d.ConditionalTextPreference != nil {
+		if err := encoder.Encode(d.ConditionalTextPreference); err != nil {
+			return common.WrapError("document", "marshal document", err)
+		}
+	}
+	for _, condition := range d.Conditions {
+		if err := encoder.Encode(condition); err != nil {
+			return common.WrapError("document", "marshal document", err)
+		}
+	}
+	for _, set := range d.ConditionSets {
+		if err := encoder.Encode(set); err != nil {
+			return common.WrapError("document", "marshal document", err)
+		}
+	}
+
+	// 14. IDMS inline content (colors, swatches, styles, spreads, stories)
 	// These are used in IDMS (snippet) files instead of resource references
 	for _, color := range d.Colors {
 		if err := encoder.Encode(color); err != nil {
@@ -549,6 +636,16 @@ func (d Document) marshalChildren(encoder *xml.Encoder) error {
 			return common.WrapError("document", "marshal document", err)
 		}
 	}
+	if d.RootCellStyleGroup != nil {
+		if err := encoder.Encode(d.RootCellStyleGroup); err != nil {
+			return common.WrapError("document", "marshal document", err)
+		}
+	}
+	if d.RootTableStyleGroup != nil {
+		if err := encoder.Encode(d.RootTableStyleGroup); err != nil {
+			return common.WrapError("document", "marshal document", err)
+		}
+	}
 	if d.RootObjectStyleGroup != nil {
 		if err := encoder.Encode(d.RootObjectStyleGroup); err != nil {
 			return common.WrapError("document", "marshal document", err)
@@ -577,7 +674,36 @@ func (d Document) marshalChildren(encoder *xml.Encoder) error {
 		}
 	}
 
-	// 14. Other unknown elements
+	// 15. Cross-reference formats, hyperlink destinations, page item sources
+	// and hyperlinks, after the inline stories and spreads holding the other
+	// sources and destinations
+	for _, format := range d.CrossReferenceFormats {
+		if err := encoder.Encode(format); err != nil {
+			return common.WrapError("document", "marshal document", err)
+		}
+	}
+	for _, dest := range d.HyperlinkURLDestinations {
+		if err := encoder.Encode(dest); err != nil {
+			return common.WrapError("document", "marshal document", err)
+		}
+	}
+	for _, dest := range d.HyperlinkPageDestinations {
+		if err := encoder.Encode(dest); err != nil {
+			return common.WrapError("document", "marshal document", err)
+		}
+	}
+	for _, src := range d.HyperlinkPageItemSources {
+		if err := encoder.Encode(src); err != nil {
+			return common.WrapError("document", "marshal document", err)
+		}
+	}
+	for _, link := range d.Hyperlinks {
+		if err := encoder.Encode(link); err != nil {
+			return common.WrapError("document", "marshal document", err)
+		}
+	}
+
+	// 16. Other unknown elements
 	for _, elem := range d.OtherElements {
 		if err := encoder.Encode(elem); err != nil {
 			return common.WrapError("document", "marshal document", err)
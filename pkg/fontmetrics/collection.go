@@ -0,0 +1,134 @@
+package fontmetrics
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+)
+
+// Collection is a set of fonts that can be looked up by family and style,
+// typically loaded from a font directory with LoadDir.
+type Collection struct {
+	fonts []*Font
+
+	// byName maps lowercased "family\x00style" and PostScript names to fonts.
+	byName map[string]*Font
+
+	// byFamily maps a lowercased family name to its first loaded font.
+	byFamily map[string]*Font
+
+	// Skipped lists font files that could not be parsed.
+	Skipped []string
+}
+
+// NewCollection creates an empty collection.
+func NewCollection() *Collection {
+	return &Collection{
+		byName:   make(map[string]*Font),
+		byFamily: make(map[string]*Font),
+	}
+}
+
+// LoadDir loads all .ttf, .otf, .ttc and .otc files below dir.
+// Files that fail to parse are recorded in Skipped instead of failing the load,
+// since font directories often contain unsupported or damaged files.
+func LoadDir(dir string) (*Collection, error) {
+	c := NewCollection()
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !isFontFile(path) {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		fonts, err := ParseCollection(data)
+		if err != nil {
+			c.Skipped = append(c.Skipped, path)
+			return nil
+		}
+		for _, f := range fonts {
+			c.Add(f)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, common.WrapErrorWithPath("fontmetrics", "load dir", dir, err)
+	}
+
+	return c, nil
+}
+
+// Add adds a font to the collection. Fonts added earlier win when two fonts
+// share the same family and style.
+func (c *Collection) Add(f *Font) {
+	c.fonts = append(c.fonts, f)
+
+	if f.Family != "" {
+		if key := styleKey(f.Family, f.Style); c.byName[key] == nil {
+			c.byName[key] = f
+		}
+		if family := strings.ToLower(f.Family); c.byFamily[family] == nil {
+			c.byFamily[family] = f
+		}
+	}
+	if ps := strings.ToLower(f.PostScriptName); ps != "" && c.byName[ps] == nil {
+		c.byName[ps] = f
+	}
+}
+
+// Len returns the number of fonts in the collection.
+func (c *Collection) Len() int {
+	return len(c.fonts)
+}
+
+// Fonts returns all fonts in load order.
+func (c *Collection) Fonts() []*Font {
+	return c.fonts
+}
+
+// Lookup finds a font by family and style (case-insensitive).
+//
+// If the exact style is missing, "Regular" and "Roman" are tried, then any
+// font of the family. family may also be a PostScript name. Returns nil if
+// the family is not loaded.
+func (c *Collection) Lookup(family, style string) *Font {
+	if c == nil {
+		return nil
+	}
+
+	if f := c.byName[styleKey(family, style)]; f != nil {
+		return f
+	}
+	if f := c.byName[strings.ToLower(family)]; f != nil {
+		return f
+	}
+	for _, fallback := range []string{"Regular", "Roman"} {
+		if f := c.byName[styleKey(family, fallback)]; f != nil {
+			return f
+		}
+	}
+	return c.byFamily[strings.ToLower(family)]
+}
+
+// styleKey builds the lookup key for a family and style.
+func styleKey(family, style string) string {
+	return strings.ToLower(family) + "\x00" + strings.ToLower(style)
+}
+
+// isFontFile reports whether path has a supported font extension.
+func isFontFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ttf", ".otf", ".ttc", ".otc":
+		return true
+	}
+	return false
+}
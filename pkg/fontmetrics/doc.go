@@ -0,0 +1,31 @@
+// Package fontmetrics reads horizontal metrics from TrueType and OpenType fonts.
+//
+// It is a small, dependency-free SFNT reader that extracts just enough data for
+// text fitting estimates: family and style names, vertical metrics and glyph
+// advance widths. It does not shape text, apply kerning or rasterize glyphs.
+//
+// # Key Types
+//
+//   - Font: Metrics for a single font face
+//   - Collection: Fonts loaded from a directory, looked up by family and style
+//
+// # Usage
+//
+// Load all fonts from a directory and measure a string:
+//
+//	fonts, err := fontmetrics.LoadDir("/Library/Fonts")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	if f := fonts.Lookup("Minion Pro", "Regular"); f != nil {
+//	    width := f.TextWidth("Hello world", 12) // in points
+//	    fmt.Printf("%.2fpt\n", width)
+//	}
+//
+// # Supported Formats
+//
+// TrueType (.ttf), OpenType (.otf) and font collections (.ttc, .otc) are
+// supported. Glyph lookup uses cmap subtable formats 4 and 12; characters
+// without a glyph are measured with the font's average advance width.
+package fontmetrics
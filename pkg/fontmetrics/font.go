@@ -0,0 +1,443 @@
+package fontmetrics
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"unicode/utf16"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+)
+
+// SFNT version tags.
+const (
+	tagTrueType   = 0x00010000
+	tagOpenType   = 0x4F54544F // "OTTO"
+	tagAppleTrue  = 0x74727565 // "true"
+	tagCollection = 0x74746366 // "ttcf"
+)
+
+// Name table IDs used for font identification.
+const (
+	nameFamily           = 1
+	nameSubfamily        = 2
+	namePostScript       = 6
+	nameTypographicFam   = 16
+	nameTypographicSubfm = 17
+)
+
+// Font holds the metrics of a single font face.
+// All metric values are in font units; divide by UnitsPerEm and multiply by
+// the point size to convert to points.
+type Font struct {
+	// Family is the font family name (e.g., "Minion Pro").
+	// The typographic family name is preferred over the legacy one.
+	Family string
+
+	// Style is the style name within the family (e.g., "Bold Italic").
+	Style string
+
+	// PostScriptName is the PostScript name (e.g., "MinionPro-BoldIt").
+	PostScriptName string
+
+	// UnitsPerEm is the number of font units per em square.
+	UnitsPerEm int
+
+	// Ascender, Descender and LineGap come from the hhea table.
+	// Descender is usually negative.
+	Ascender  int
+	Descender int
+	LineGap   int
+
+	// AverageAdvance is the average glyph advance width, used for characters
+	// the font has no glyph for.
+	AverageAdvance int
+
+	// advances holds the advance width for each glyph ID.
+	advances []uint16
+
+	// cmap is the raw character-to-glyph subtable and its format (4 or 12).
+	cmap       []byte
+	cmapFormat int
+}
+
+// Load reads the first font face from a font file.
+func Load(path string) (*Font, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, common.WrapErrorWithPath("fontmetrics", "load", path, err)
+	}
+
+	font, err := Parse(data)
+	if err != nil {
+		return nil, common.WrapErrorWithPath("fontmetrics", "load", path, err)
+	}
+	return font, nil
+}
+
+// Parse reads the first font face from TrueType, OpenType or collection data.
+func Parse(data []byte) (*Font, error) {
+	fonts, err := ParseCollection(data)
+	if err != nil {
+		return nil, err
+	}
+	return fonts[0], nil
+}
+
+// ParseCollection reads every font face from the data.
+// Single-font files return a slice with one element.
+func ParseCollection(data []byte) ([]*Font, error) {
+	if len(data) < 12 {
+		return nil, invalidFont("file too short")
+	}
+
+	if binary.BigEndian.Uint32(data) != tagCollection {
+		font, err := parseFace(data, 0)
+		if err != nil {
+			return nil, err
+		}
+		return []*Font{font}, nil
+	}
+
+	count := int(binary.BigEndian.Uint32(data[8:]))
+	if count == 0 || len(data) < 12+4*count {
+		return nil, invalidFont("invalid collection header")
+	}
+
+	fonts := make([]*Font, 0, count)
+	for i := 0; i < count; i++ {
+		offset := int(binary.BigEndian.Uint32(data[12+4*i:]))
+		font, err := parseFace(data, offset)
+		if err != nil {
+			return nil, err
+		}
+		fonts = append(fonts, font)
+	}
+	return fonts, nil
+}
+
+// GlyphAdvance returns the advance width of the glyph for r in font units.
+// The second return value is false if the font has no glyph for r.
+func (f *Font) GlyphAdvance(r rune) (int, bool) {
+	gid := f.glyphIndex(r)
+	if gid == 0 || len(f.advances) == 0 {
+		return f.AverageAdvance, false
+	}
+	if gid >= len(f.advances) {
+		// Glyphs past numberOfHMetrics share the last advance width
+		return int(f.advances[len(f.advances)-1]), true
+	}
+	return int(f.advances[gid]), true
+}
+
+// TextWidth returns the width of text set at pointSize, in points.
+// Kerning and ligatures are not applied.
+func (f *Font) TextWidth(text string, pointSize float64) float64 {
+	if f.UnitsPerEm == 0 {
+		return 0
+	}
+
+	units := 0
+	for _, r := range text {
+		advance, _ := f.GlyphAdvance(r)
+		units += advance
+	}
+	return float64(units) * pointSize / float64(f.UnitsPerEm)
+}
+
+// ============================================================================
+// Table parsing
+// ============================================================================
+
+// parseFace parses the font whose table directory starts at offset.
+func parseFace(data []byte, offset int) (*Font, error) {
+	if offset < 0 || offset+12 > len(data) {
+		return nil, invalidFont("table directory out of range")
+	}
+
+	switch binary.BigEndian.Uint32(data[offset:]) {
+	case tagTrueType, tagOpenType, tagAppleTrue:
+	default:
+		return nil, invalidFont("unknown sfnt version")
+	}
+
+	numTables := int(binary.BigEndian.Uint16(data[offset+4:]))
+	tables := make(map[string][]byte, numTables)
+	for i := 0; i < numTables; i++ {
+		rec := offset + 12 + 16*i
+		if rec+16 > len(data) {
+			return nil, invalidFont("table record out of range")
+		}
+		tag := string(data[rec : rec+4])
+		start := int(binary.BigEndian.Uint32(data[rec+8:]))
+		length := int(binary.BigEndian.Uint32(data[rec+12:]))
+		if start < 0 || length < 0 || start+length > len(data) {
+			return nil, invalidFont(fmt.Sprintf("table %q out of range", tag))
+		}
+		tables[tag] = data[start : start+length]
+	}
+
+	font := &Font{}
+	if err := font.parseHead(tables["head"]); err != nil {
+		return nil, err
+	}
+	numHMetrics, err := font.parseHhea(tables["hhea"])
+	if err != nil {
+		return nil, err
+	}
+	if err := font.parseHmtx(tables["hmtx"], numHMetrics); err != nil {
+		return nil, err
+	}
+	font.parseCmap(tables["cmap"])
+	font.parseName(tables["name"])
+	font.parseOS2(tables["OS/2"])
+
+	return font, nil
+}
+
+// parseHead reads unitsPerEm from the head table.
+func (f *Font) parseHead(table []byte) error {
+	if len(table) < 54 {
+		return invalidFont("missing or short head table")
+	}
+	f.UnitsPerEm = int(binary.BigEndian.Uint16(table[18:]))
+	if f.UnitsPerEm == 0 {
+		return invalidFont("unitsPerEm is zero")
+	}
+	return nil
+}
+
+// parseHhea reads vertical metrics and returns numberOfHMetrics.
+func (f *Font) parseHhea(table []byte) (int, error) {
+	if len(table) < 36 {
+		return 0, invalidFont("missing or short hhea table")
+	}
+	f.Ascender = int(int16(binary.BigEndian.Uint16(table[4:])))
+	f.Descender = int(int16(binary.BigEndian.Uint16(table[6:])))
+	f.LineGap = int(int16(binary.BigEndian.Uint16(table[8:])))
+	return int(binary.BigEndian.Uint16(table[34:])), nil
+}
+
+// parseHmtx reads advance widths and derives the average advance.
+func (f *Font) parseHmtx(table []byte, numHMetrics int) error {
+	if len(table) < 4*numHMetrics {
+		return invalidFont("short hmtx table")
+	}
+
+	f.advances = make([]uint16, numHMetrics)
+	total, counted := 0, 0
+	for i := range f.advances {
+		f.advances[i] = binary.BigEndian.Uint16(table[4*i:])
+		if f.advances[i] > 0 {
+			total += int(f.advances[i])
+			counted++
+		}
+	}
+	if counted > 0 {
+		f.AverageAdvance = total / counted
+	}
+	return nil
+}
+
+// parseOS2 prefers xAvgCharWidth over the hmtx average when present.
+func (f *Font) parseOS2(table []byte) {
+	if len(table) < 4 {
+		return
+	}
+	if avg := int(int16(binary.BigEndian.Uint16(table[2:]))); avg > 0 {
+		f.AverageAdvance = avg
+	}
+}
+
+// parseCmap selects the best Unicode subtable. Fonts without a usable
+// subtable are still accepted; every character then uses AverageAdvance.
+func (f *Font) parseCmap(table []byte) {
+	if len(table) < 4 {
+		return
+	}
+
+	numTables := int(binary.BigEndian.Uint16(table[2:]))
+	bestScore := 0
+	for i := 0; i < numTables; i++ {
+		rec := 4 + 8*i
+		if rec+8 > len(table) {
+			return
+		}
+		platform := binary.BigEndian.Uint16(table[rec:])
+		encoding := binary.BigEndian.Uint16(table[rec+2:])
+		offset := int(binary.BigEndian.Uint32(table[rec+4:]))
+		if offset+4 > len(table) {
+			continue
+		}
+
+		format := int(binary.BigEndian.Uint16(table[offset:]))
+		score := 0
+		switch {
+		case format == 12 && (platform == 3 && encoding == 10 || platform == 0):
+			score = 3
+		case format == 4 && platform == 3 && encoding == 1:
+			score = 2
+		case format == 4 && platform == 0:
+			score = 1
+		}
+		if score > bestScore {
+			bestScore = score
+			f.cmap = table[offset:]
+			f.cmapFormat = format
+		}
+	}
+}
+
+// glyphIndex maps a rune to its glyph ID, returning 0 if it is not mapped.
+func (f *Font) glyphIndex(r rune) int {
+	switch f.cmapFormat {
+	case 4:
+		return cmapFormat4Lookup(f.cmap, r)
+	case 12:
+		return cmapFormat12Lookup(f.cmap, r)
+	}
+	return 0
+}
+
+// cmapFormat4Lookup looks up r in a segment mapping (format 4) subtable.
+func cmapFormat4Lookup(sub []byte, r rune) int {
+	if r > 0xFFFF || len(sub) < 14 {
+		return 0
+	}
+	segCount := int(binary.BigEndian.Uint16(sub[6:])) / 2
+	endCodes := 14
+	startCodes := endCodes + 2*segCount + 2
+	idDeltas := startCodes + 2*segCount
+	idRangeOffsets := idDeltas + 2*segCount
+	if idRangeOffsets+2*segCount > len(sub) {
+		return 0
+	}
+
+	c := uint16(r)
+	for i := 0; i < segCount; i++ {
+		if c > binary.BigEndian.Uint16(sub[endCodes+2*i:]) {
+			continue
+		}
+		start := binary.BigEndian.Uint16(sub[startCodes+2*i:])
+		if c < start {
+			return 0
+		}
+		delta := binary.BigEndian.Uint16(sub[idDeltas+2*i:])
+		rangeOffset := int(binary.BigEndian.Uint16(sub[idRangeOffsets+2*i:]))
+		if rangeOffset == 0 {
+			return int(c + delta)
+		}
+		pos := idRangeOffsets + 2*i + rangeOffset + 2*int(c-start)
+		if pos+2 > len(sub) {
+			return 0
+		}
+		gid := binary.BigEndian.Uint16(sub[pos:])
+		if gid == 0 {
+			return 0
+		}
+		return int(gid + delta)
+	}
+	return 0
+}
+
+// cmapFormat12Lookup looks up r in a segmented coverage (format 12) subtable.
+func cmapFormat12Lookup(sub []byte, r rune) int {
+	if len(sub) < 16 {
+		return 0
+	}
+	numGroups := int(binary.BigEndian.Uint32(sub[12:]))
+	if 16+12*numGroups > len(sub) {
+		return 0
+	}
+
+	c := uint32(r)
+	lo, hi := 0, numGroups
+	for lo < hi {
+		mid := (lo + hi) / 2
+		group := 16 + 12*mid
+		start := binary.BigEndian.Uint32(sub[group:])
+		end := binary.BigEndian.Uint32(sub[group+4:])
+		switch {
+		case c < start:
+			hi = mid
+		case c > end:
+			lo = mid + 1
+		default:
+			return int(binary.BigEndian.Uint32(sub[group+8:]) + c - start)
+		}
+	}
+	return 0
+}
+
+// parseName reads family, style and PostScript names.
+// Windows Unicode records are preferred over Macintosh Roman ones.
+func (f *Font) parseName(table []byte) {
+	if len(table) < 6 {
+		return
+	}
+
+	count := int(binary.BigEndian.Uint16(table[2:]))
+	storage := int(binary.BigEndian.Uint16(table[4:]))
+	names := make(map[int]string)
+	for i := 0; i < count; i++ {
+		rec := 6 + 12*i
+		if rec+12 > len(table) {
+			break
+		}
+		platform := binary.BigEndian.Uint16(table[rec:])
+		id := int(binary.BigEndian.Uint16(table[rec+6:]))
+		length := int(binary.BigEndian.Uint16(table[rec+8:]))
+		offset := storage + int(binary.BigEndian.Uint16(table[rec+10:]))
+		if offset+length > len(table) {
+			continue
+		}
+		raw := table[offset : offset+length]
+
+		switch platform {
+		case 0, 3:
+			names[id] = decodeUTF16BE(raw)
+		case 1:
+			if _, ok := names[id]; !ok {
+				names[id] = decodeLatin1(raw)
+			}
+		}
+	}
+
+	f.Family = firstNonEmpty(names[nameTypographicFam], names[nameFamily])
+	f.Style = firstNonEmpty(names[nameTypographicSubfm], names[nameSubfamily])
+	f.PostScriptName = names[namePostScript]
+}
+
+// decodeUTF16BE decodes big-endian UTF-16 name data.
+func decodeUTF16BE(b []byte) string {
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		units[i] = binary.BigEndian.Uint16(b[2*i:])
+	}
+	return string(utf16.Decode(units))
+}
+
+// decodeLatin1 decodes single-byte name data. Mac Roman differs from Latin-1
+// only above 0x7F, which is rare in font names.
+func decodeLatin1(b []byte) string {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+// firstNonEmpty returns the first non-empty string.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// invalidFont wraps common.ErrInvalidFormat with a description.
+func invalidFont(msg string) error {
+	return common.WrapError("fontmetrics", "parse", fmt.Errorf("%s: %w", msg, common.ErrInvalidFormat))
+}
@@ -0,0 +1,267 @@
+package fontmetrics
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+)
+
+// buildTestFont assembles a minimal TrueType font with head, hhea, hmtx,
+// cmap (format 4) and name tables. Glyph 0 is .notdef with a 500 unit advance.
+func buildTestFont(family, style string, advances map[rune]uint16) []byte {
+	runes := make([]rune, 0, len(advances))
+	for r := range advances {
+		runes = append(runes, r)
+	}
+	sort.Slice(runes, func(i, j int) bool { return runes[i] < runes[j] })
+
+	be := binary.BigEndian
+
+	head := make([]byte, 54)
+	be.PutUint16(head[18:], 1000)
+
+	hhea := make([]byte, 36)
+	be.PutUint16(hhea[4:], 800)
+	be.PutUint16(hhea[6:], uint16(0xFFFF-200+1)) // -200
+	be.PutUint16(hhea[34:], uint16(len(runes)+1))
+
+	var hmtx []byte
+	hmtx = be.AppendUint16(hmtx, 500)
+	hmtx = be.AppendUint16(hmtx, 0)
+	for _, r := range runes {
+		hmtx = be.AppendUint16(hmtx, advances[r])
+		hmtx = be.AppendUint16(hmtx, 0)
+	}
+
+	// One segment per character plus the mandatory 0xFFFF terminator
+	segCount := len(runes) + 1
+	var ends, starts, deltas, offsets []byte
+	for i, r := range runes {
+		ends = be.AppendUint16(ends, uint16(r))
+		starts = be.AppendUint16(starts, uint16(r))
+		deltas = be.AppendUint16(deltas, uint16(i+1)-uint16(r))
+		offsets = be.AppendUint16(offsets, 0)
+	}
+	ends = be.AppendUint16(ends, 0xFFFF)
+	starts = be.AppendUint16(starts, 0xFFFF)
+	deltas = be.AppendUint16(deltas, 1)
+	offsets = be.AppendUint16(offsets, 0)
+
+	var sub []byte
+	sub = be.AppendUint16(sub, 4)
+	sub = be.AppendUint16(sub, uint16(16+8*segCount))
+	sub = be.AppendUint16(sub, 0)
+	sub = be.AppendUint16(sub, uint16(2*segCount))
+	sub = append(sub, 0, 0, 0, 0, 0, 0) // searchRange, entrySelector, rangeShift
+	sub = append(sub, ends...)
+	sub = append(sub, 0, 0)
+	sub = append(sub, starts...)
+	sub = append(sub, deltas...)
+	sub = append(sub, offsets...)
+
+	var cmap []byte
+	cmap = be.AppendUint16(cmap, 0)
+	cmap = be.AppendUint16(cmap, 1)
+	cmap = be.AppendUint16(cmap, 3)
+	cmap = be.AppendUint16(cmap, 1)
+	cmap = be.AppendUint32(cmap, 12)
+	cmap = append(cmap, sub...)
+
+	nameTable := buildNameTable(map[int]string{
+		nameFamily:     family,
+		nameSubfamily:  style,
+		namePostScript: family + "-" + style,
+	})
+
+	return assembleSFNT(map[string][]byte{
+		"head": head,
+		"hhea": hhea,
+		"hmtx": hmtx,
+		"cmap": cmap,
+		"name": nameTable,
+	})
+}
+
+// buildNameTable encodes Windows Unicode name records.
+func buildNameTable(names map[int]string) []byte {
+	be := binary.BigEndian
+	ids := make([]int, 0, len(names))
+	for id := range names {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	var records, storage []byte
+	for _, id := range ids {
+		var encoded []byte
+		for _, u := range utf16.Encode([]rune(names[id])) {
+			encoded = be.AppendUint16(encoded, u)
+		}
+		records = be.AppendUint16(records, 3)
+		records = be.AppendUint16(records, 1)
+		records = be.AppendUint16(records, 0x409)
+		records = be.AppendUint16(records, uint16(id))
+		records = be.AppendUint16(records, uint16(len(encoded)))
+		records = be.AppendUint16(records, uint16(len(storage)))
+		storage = append(storage, encoded...)
+	}
+
+	var table []byte
+	table = be.AppendUint16(table, 0)
+	table = be.AppendUint16(table, uint16(len(ids)))
+	table = be.AppendUint16(table, uint16(6+len(records)))
+	table = append(table, records...)
+	return append(table, storage...)
+}
+
+// assembleSFNT writes a table directory followed by the tables.
+func assembleSFNT(tables map[string][]byte) []byte {
+	be := binary.BigEndian
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	var buf bytes.Buffer
+	header := make([]byte, 12)
+	be.PutUint32(header, tagTrueType)
+	be.PutUint16(header[4:], uint16(len(tags)))
+	buf.Write(header)
+
+	offset := 12 + 16*len(tags)
+	for _, tag := range tags {
+		rec := make([]byte, 16)
+		copy(rec, tag)
+		be.PutUint32(rec[8:], uint32(offset))
+		be.PutUint32(rec[12:], uint32(len(tables[tag])))
+		buf.Write(rec)
+		offset += len(tables[tag])
+	}
+	for _, tag := range tags {
+		buf.Write(tables[tag])
+	}
+	return buf.Bytes()
+}
+
+// TestParse tests reading names, metrics and advances from a synthetic font.
+func TestParse(t *testing.T) {
+	data := buildTestFont("Test Sans", "Bold", map[rune]uint16{'a': 400, 'b': 600, ' ': 250})
+
+	f, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if f.Family != "Test Sans" || f.Style != "Bold" || f.PostScriptName != "Test Sans-Bold" {
+		t.Errorf("names = %q/%q/%q", f.Family, f.Style, f.PostScriptName)
+	}
+	if f.UnitsPerEm != 1000 || f.Ascender != 800 || f.Descender != -200 {
+		t.Errorf("metrics = upem %d, asc %d, desc %d", f.UnitsPerEm, f.Ascender, f.Descender)
+	}
+
+	if adv, ok := f.GlyphAdvance('b'); !ok || adv != 600 {
+		t.Errorf("GlyphAdvance('b') = %d, %v", adv, ok)
+	}
+	if adv, ok := f.GlyphAdvance('z'); ok || adv != f.AverageAdvance {
+		t.Errorf("GlyphAdvance('z') = %d, %v, want average %d", adv, ok, f.AverageAdvance)
+	}
+
+	// (400 + 250 + 600) units at 10pt with 1000 upem
+	if got := f.TextWidth("a b", 10); math.Abs(got-12.5) > 1e-9 {
+		t.Errorf("TextWidth = %v, want 12.5", got)
+	}
+}
+
+// TestParse_Invalid tests that malformed data is rejected.
+func TestParse_Invalid(t *testing.T) {
+	for _, data := range [][]byte{nil, []byte("not a font at all"), make([]byte, 12)} {
+		if _, err := Parse(data); !errors.Is(err, common.ErrInvalidFormat) {
+			t.Errorf("Parse(%q) error = %v, want ErrInvalidFormat", data, err)
+		}
+	}
+}
+
+// TestCollection_Lookup tests family/style matching and fallbacks.
+func TestCollection_Lookup(t *testing.T) {
+	c := NewCollection()
+	for _, style := range []string{"Regular", "Bold"} {
+		f, err := Parse(buildTestFont("Test Serif", style, map[rune]uint16{'a': 500}))
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		c.Add(f)
+	}
+
+	tests := []struct {
+		family, style string
+		want          string
+	}{
+		{"Test Serif", "Bold", "Bold"},
+		{"test serif", "bold", "Bold"},
+		{"Test Serif", "Black", "Regular"},
+		{"Test Serif-Bold", "", "Bold"},
+		{"Unknown", "Regular", ""},
+	}
+	for _, tt := range tests {
+		f := c.Lookup(tt.family, tt.style)
+		got := ""
+		if f != nil {
+			got = f.Style
+		}
+		if got != tt.want {
+			t.Errorf("Lookup(%q, %q) = %q, want %q", tt.family, tt.style, got, tt.want)
+		}
+	}
+
+	var nilCollection *Collection
+	if nilCollection.Lookup("Test Serif", "Bold") != nil {
+		t.Error("Lookup on nil collection should return nil")
+	}
+}
+
+// TestLoadDir tests loading fonts from a directory and skipping bad files.
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"good.ttf":        buildTestFont("Dir Sans", "Regular", map[rune]uint16{'a': 500}),
+		"nested/good.otf": buildTestFont("Dir Sans", "Italic", map[rune]uint16{'a': 450}),
+		"broken.ttf":      []byte("garbage"),
+		"readme.txt":      []byte("ignored"),
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c, err := LoadDir(dir)
+	if err != nil {
+		t.Fatalf("LoadDir failed: %v", err)
+	}
+	if c.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", c.Len())
+	}
+	if len(c.Skipped) != 1 || filepath.Base(c.Skipped[0]) != "broken.ttf" {
+		t.Errorf("Skipped = %v", c.Skipped)
+	}
+	if f := c.Lookup("Dir Sans", "Italic"); f == nil {
+		t.Error("Italic font from nested directory not found")
+	}
+
+	if _, err := LoadDir(filepath.Join(dir, "missing")); err == nil {
+		t.Error("LoadDir on missing directory should fail")
+	}
+}
@@ -0,0 +1,51 @@
+// Package icml provides support for reading and writing InCopy Markup Language
+// (ICML) files.
+//
+// ICML is the single-story sibling of IDMS: one XML file holding a Document
+// with the styles, colors and swatches of a story and the story itself.
+// Copy editors work on ICML files in InCopy while the layout stays in the
+// InDesign document.
+//
+// # Key Types
+//
+//   - Package: Represents an ICML file with its processing instructions and
+//     inline Document
+//
+// # Usage
+//
+// Edit an ICML file:
+//
+//	pkg, err := icml.Read("story.icml")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	elem, err := pkg.Story()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	st := &story.Story{StoryElement: *elem}
+//	st.ReplaceText("colour", "color")
+//	pkg.SetStory(st.StoryElement)
+//
+//	err = icml.Write(pkg, "story.icml")
+//
+// Round trip a story of an IDML package through InCopy:
+//
+//	ic, err := doc.ExportStoryAsICML("Stories/Story_u1d8.xml")
+//	err = icml.Write(ic, "story.icml")
+//
+//	// ... edited in InCopy ...
+//
+//	edited, err := icml.Read("story.icml")
+//	err = doc.ReplaceStoryFromICML("Stories/Story_u1d8.xml", edited)
+//
+// # ICML vs IDMS Structure
+//
+// Both formats reuse document.Document with inline resources. An ICML file
+// is marked with <?aid SnippetType="InCopyInterchange"?>, has exactly one
+// inline Story and no spreads or XMP metadata.
+//
+// Processing instructions inside the story, such as the <?ACE 18?> auto
+// page number, are part of the story text and are kept by pkg/story.
+package icml
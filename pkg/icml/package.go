@@ -0,0 +1,108 @@
+package icml
+
+import (
+	"fmt"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/document"
+	"github.com/dimelords/idmllib/v2/pkg/story"
+)
+
+// SnippetType is the snippet type InCopy writes for ICML files.
+const SnippetType = "InCopyInterchange"
+
+// Package represents an InCopy Markup Language (ICML) file.
+//
+// An ICML file contains:
+//   - XML processing instructions identifying it as an InCopy snippet
+//   - A Document element (reusing document.Document) with the inline
+//     styles, colors and swatches the story needs
+//   - A single inline Story element
+type Package struct {
+	// XMLDeclaration is the XML declaration (e.g., <?xml version="1.0" encoding="UTF-8"?>)
+	XMLDeclaration string
+
+	// AIDProcessingInstructions contains the <?aid ...?> directives
+	// Example: <?aid SnippetType="InCopyInterchange"?>
+	AIDProcessingInstructions []document.ProcessingInstruction
+
+	// Document is the root ICML document (reuses document.Document)
+	Document *document.Document
+}
+
+// New creates a new empty ICML Package with the default AID processing
+// instructions.
+func New() *Package {
+	p := &Package{
+		XMLDeclaration: `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`,
+		Document:       &document.Document{},
+	}
+	p.SetDefaultAIDProcessingInstructions()
+	return p
+}
+
+// SetDefaultAIDProcessingInstructions sets up the standard AID processing
+// instructions for an InCopy file.
+//
+// Default values match InDesign 2025 (version 20.5):
+//   - style="50"
+//   - type="snippet"
+//   - readerVersion="6.0"
+//   - featureSet="513"
+//   - product="20.5(66)" (InDesign 2025)
+//   - SnippetType="InCopyInterchange"
+func (p *Package) SetDefaultAIDProcessingInstructions() {
+	p.AIDProcessingInstructions = []document.ProcessingInstruction{
+		{
+			Target: "aid",
+			Inst:   `style="50" type="snippet" readerVersion="6.0" featureSet="513" product="20.5(66)"`,
+		},
+		{
+			Target: "aid",
+			Inst:   fmt.Sprintf(`SnippetType="%s"`, SnippetType),
+		},
+	}
+}
+
+// Validate checks if the Package is valid.
+func (p *Package) Validate() error {
+	if p.Document == nil {
+		return fmt.Errorf("document is nil")
+	}
+	if len(p.AIDProcessingInstructions) == 0 {
+		return fmt.Errorf("missing AID processing instructions")
+	}
+	if len(p.Document.InlineStories) != 1 {
+		return fmt.Errorf("ICML must contain exactly one story, found %d", len(p.Document.InlineStories))
+	}
+	return nil
+}
+
+// Story returns the story of the ICML file.
+//
+// Returns common.ErrNotFound if the document has no story.
+func (p *Package) Story() (*story.StoryElement, error) {
+	if p.Document == nil || len(p.Document.InlineStories) == 0 {
+		return nil, common.WrapError("icml", "story", common.ErrNotFound)
+	}
+	return &p.Document.InlineStories[0], nil
+}
+
+// SetStory makes elem the story of the ICML file, replacing any story it had.
+func (p *Package) SetStory(elem story.StoryElement) {
+	if p.Document == nil {
+		p.Document = &document.Document{}
+	}
+	p.Document.InlineStories = []story.StoryElement{elem}
+}
+
+// Text returns the text of the story, like story.Story.ExtractText, or ""
+// if the file has no story.
+func (p *Package) Text() string {
+	elem, err := p.Story()
+	if err != nil {
+		return ""
+	}
+	st := &story.Story{StoryElement: *elem}
+	return st.ExtractText()
+}
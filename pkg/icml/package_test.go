@@ -0,0 +1,186 @@
+package icml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/story"
+)
+
+const testICML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<?aid style="50" type="snippet" readerVersion="6.0" featureSet="513" product="20.5(66)" ?>
+<?aid SnippetType="InCopyInterchange"?>
+<Document DOMVersion="20.5" Self="d">
+	<Color Self="Color/Black" Model="Process" Space="CMYK" ColorValue="0 0 0 100" Name="Black"/>
+	<RootCharacterStyleGroup Self="u79">
+		<CharacterStyle Self="CharacterStyle/$ID/[No character style]" Name="$ID/[No character style]"/>
+	</RootCharacterStyleGroup>
+	<RootParagraphStyleGroup Self="u78">
+		<ParagraphStyle Self="ParagraphStyle/$ID/NormalParagraphStyle" Name="$ID/NormalParagraphStyle"/>
+	</RootParagraphStyleGroup>
+	<Story Self="u1d8" AppliedTOCStyle="n" TrackChanges="false" StoryTitle="$ID/" AppliedNamedGrid="n">
+		<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/$ID/NormalParagraphStyle">
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+				<Content>Page <?ACE 18?> of the report</Content>
+				<Br/>
+			</CharacterStyleRange>
+		</ParagraphStyleRange>
+	</Story>
+</Document>`
+
+// TestParse tests reading the processing instructions, resources and story
+// of an ICML file.
+func TestParse(t *testing.T) {
+	pkg, err := Parse([]byte(testICML))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if want := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`; pkg.XMLDeclaration != want {
+		t.Errorf("XMLDeclaration = %q, want %q", pkg.XMLDeclaration, want)
+	}
+	// The <?ACE 18?> in the story is story text, not an AID instruction
+	if len(pkg.AIDProcessingInstructions) != 2 {
+		t.Fatalf("got %d AID processing instructions, want 2", len(pkg.AIDProcessingInstructions))
+	}
+	if got := pkg.AIDProcessingInstructions[1].Inst; got != `SnippetType="InCopyInterchange"` {
+		t.Errorf("second AID instruction = %q", got)
+	}
+
+	if len(pkg.Document.Colors) != 1 || pkg.Document.RootParagraphStyleGroup == nil {
+		t.Error("expected inline colors and paragraph styles")
+	}
+	elem, err := pkg.Story()
+	if err != nil {
+		t.Fatalf("Story failed: %v", err)
+	}
+	if elem.Self != "u1d8" {
+		t.Errorf("Story().Self = %q, want u1d8", elem.Self)
+	}
+	if got := pkg.Text(); got != "Page  of the report\n" {
+		t.Errorf("Text() = %q", got)
+	}
+	st := &story.Story{StoryElement: *elem}
+	if refs := st.SpecialCharacters(); len(refs) != 1 || refs[0].Character != story.SpecialAutoPageNumber {
+		t.Errorf("SpecialCharacters() = %+v, want the auto page number", refs)
+	}
+}
+
+// TestParseInvalid tests that files without a Document root are rejected.
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"no root", `<?xml version="1.0"?>`},
+		{"wrong root", `<?xml version="1.0"?><Story Self="u1"/>`},
+		{"malformed", `<Document><Story>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse([]byte(tt.data)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+
+	if _, err := Parse([]byte(`<Root/>`)); !errors.Is(err, common.ErrInvalidFormat) {
+		t.Errorf("Parse(<Root/>) error = %v, want ErrInvalidFormat", err)
+	}
+}
+
+// TestMarshalRoundtrip tests that an edited story survives writing and
+// reading the ICML file again.
+func TestMarshalRoundtrip(t *testing.T) {
+	pkg, err := Parse([]byte(testICML))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	elem, _ := pkg.Story()
+	st := &story.Story{StoryElement: *elem}
+	if n := st.ReplaceText("report", "budget"); n != 1 {
+		t.Fatalf("ReplaceText replaced %d, want 1", n)
+	}
+	pkg.SetStory(st.StoryElement)
+
+	path := filepath.Join(t.TempDir(), "story.icml")
+	if err := Write(pkg, path); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if !bytes.Contains(data, []byte(`<?aid SnippetType="InCopyInterchange" ?>`)) {
+		t.Error("written ICML lacks the SnippetType instruction")
+	}
+	if !bytes.Contains(data, []byte(`<?ACE 18?>`)) {
+		t.Error("written ICML lost the auto page number")
+	}
+
+	reread, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if got := reread.Text(); got != "Page  of the budget\n" {
+		t.Errorf("Text() after roundtrip = %q", got)
+	}
+	if len(reread.AIDProcessingInstructions) != 2 {
+		t.Errorf("got %d AID processing instructions after roundtrip, want 2", len(reread.AIDProcessingInstructions))
+	}
+
+	var buf bytes.Buffer
+	n, err := reread.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	if n != int64(len(data)) || !bytes.Equal(buf.Bytes(), data) {
+		t.Error("WriteTo output differs from the written file")
+	}
+}
+
+// TestNewAndValidate tests building an ICML file from scratch.
+func TestNewAndValidate(t *testing.T) {
+	pkg := New()
+	if err := pkg.Validate(); err == nil || !strings.Contains(err.Error(), "exactly one story") {
+		t.Errorf("Validate() without a story = %v", err)
+	}
+	if _, err := pkg.Story(); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("Story() error = %v, want ErrNotFound", err)
+	}
+	if _, err := Marshal(pkg); err == nil {
+		t.Error("Marshal without a story should fail")
+	}
+
+	csr := story.NewCharacterStyleRange("CharacterStyle/$ID/[No character style]", []story.Content{{Text: "Hello"}})
+	pkg.SetStory(story.StoryElement{
+		Self: "u100",
+		ParagraphStyleRanges: []story.ParagraphStyleRange{{
+			XMLName:               xml.Name{Local: "ParagraphStyleRange"},
+			AppliedParagraphStyle: "ParagraphStyle/$ID/NormalParagraphStyle",
+			CharacterStyleRanges:  []story.CharacterStyleRange{csr},
+		}},
+	})
+	data, err := Marshal(pkg)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	reread, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if got := reread.Text(); !strings.HasPrefix(got, "Hello") {
+		t.Errorf("Text() = %q, want Hello", got)
+	}
+
+	if _, err := pkg.WriteTo(nil); err == nil {
+		t.Error("WriteTo(nil) should return error")
+	}
+}
@@ -0,0 +1,51 @@
+package icml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+
+	"github.com/dimelords/idmllib/v2/internal/snippetfile"
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/document"
+)
+
+// Read reads an ICML file from the given path.
+func Read(path string) (*Package, error) {
+	data, err := snippetfile.ReadFile("icml", path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse parses ICML XML data into a Package.
+//
+// Only the processing instructions before the Document element are kept as
+// AID processing instructions; those inside the story, such as <?ACE 18?>,
+// belong to the story text.
+func Parse(data []byte) (*Package, error) {
+	// Step 1: Read the prolog up to the root element
+	header, root, err := snippetfile.ReadHeader(xml.NewDecoder(bytes.NewReader(data)))
+	if err != nil {
+		return nil, common.WrapError("icml", "parse", err)
+	}
+	if root == nil {
+		return nil, common.WrapError("icml", "parse", fmt.Errorf("no Document element: %w", common.ErrInvalidFormat))
+	}
+	if root.Name.Local != "Document" {
+		return nil, common.WrapError("icml", "parse", fmt.Errorf("root element %q: %w", root.Name.Local, common.ErrInvalidFormat))
+	}
+
+	// Step 2: Parse the Document using document.ParseDocument
+	doc, err := document.ParseDocument(data)
+	if err != nil {
+		return nil, common.WrapError("icml", "parse", fmt.Errorf("parse ICML Document: %w", err))
+	}
+
+	return &Package{
+		XMLDeclaration:            header.XMLDeclaration,
+		AIDProcessingInstructions: header.AIDProcessingInstructions,
+		Document:                  doc,
+	}, nil
+}
@@ -0,0 +1,37 @@
+package icml
+
+import (
+	"io"
+
+	"github.com/dimelords/idmllib/v2/internal/snippetfile"
+	"github.com/dimelords/idmllib/v2/pkg/common"
+)
+
+// Write writes an ICML Package to the given path.
+func Write(pkg *Package, path string) error {
+	return snippetfile.Write("icml", path, func() ([]byte, error) { return Marshal(pkg) })
+}
+
+// WriteTo writes the ICML package to w.
+// It implements io.WriterTo and returns the number of bytes written.
+// The output is identical to Write.
+func (p *Package) WriteTo(w io.Writer) (int64, error) {
+	return snippetfile.WriteTo("icml", w, func() ([]byte, error) { return Marshal(p) })
+}
+
+// Marshal serializes an ICML Package to XML bytes.
+func Marshal(pkg *Package) ([]byte, error) {
+	if err := pkg.Validate(); err != nil {
+		return nil, common.WrapError("icml", "validate", err)
+	}
+
+	header := snippetfile.Header{
+		XMLDeclaration:            pkg.XMLDeclaration,
+		AIDProcessingInstructions: pkg.AIDProcessingInstructions,
+	}
+	data, err := snippetfile.Marshal(header, pkg.Document, "")
+	if err != nil {
+		return nil, common.WrapError("icml", "marshal", err)
+	}
+	return data, nil
+}
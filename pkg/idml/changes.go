@@ -0,0 +1,186 @@
+package idml
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/document"
+	"github.com/dimelords/idmllib/v2/pkg/story"
+)
+
+// ChangeInfo describes a tracked change in a story of the package.
+type ChangeInfo struct {
+	StoryFile string
+	story.TrackedChange
+
+	// Author is the DocumentUser who made the change, or nil if designmap.xml
+	// has no user with the change's UserName.
+	Author *document.DocumentUser
+}
+
+// NoteInfo describes an InCopy note in a story of the package.
+type NoteInfo struct {
+	StoryFile string
+	Note      *story.Note
+	Text      string
+
+	// Author is the DocumentUser who wrote the note, or nil if designmap.xml
+	// has no such user.
+	Author *document.DocumentUser
+}
+
+// Changes returns the tracked changes of all stories, ordered by story file
+// name and then by position in the story.
+//
+// Example:
+//
+//	changes, err := pkg.Changes()
+//	for _, c := range changes {
+//	    fmt.Printf("%s %s %q\n", c.Change.UserName, c.Change.ChangeType, c.Text)
+//	}
+func (p *Package) Changes() ([]ChangeInfo, error) {
+	const op = "list changes"
+
+	doc, err := p.Document()
+	if err != nil {
+		return nil, common.WrapError("idml", op, err)
+	}
+	stories, err := p.Stories()
+	if err != nil {
+		return nil, common.WrapError("idml", op, err)
+	}
+
+	var changes []ChangeInfo
+	for _, filename := range sortedStoryFiles(stories) {
+		for _, c := range stories[filename].Changes() {
+			changes = append(changes, ChangeInfo{
+				StoryFile:     filename,
+				TrackedChange: c,
+				Author:        findDocumentUser(doc, "", c.Change.UserName),
+			})
+		}
+	}
+	return changes, nil
+}
+
+// AcceptChange accepts the tracked change with the given Self ID in whichever
+// story holds it. See story.Story.AcceptChange.
+//
+// Returns common.ErrNotFound if no story has such a change.
+func (p *Package) AcceptChange(changeID string) error {
+	return p.resolveChange("accept change", changeID, (*story.Story).AcceptChange)
+}
+
+// RejectChange rejects the tracked change with the given Self ID in whichever
+// story holds it. See story.Story.RejectChange.
+//
+// Returns common.ErrNotFound if no story has such a change.
+func (p *Package) RejectChange(changeID string) error {
+	return p.resolveChange("reject change", changeID, (*story.Story).RejectChange)
+}
+
+// AcceptAllChanges accepts the tracked changes of all stories and returns how
+// many there were.
+func (p *Package) AcceptAllChanges() (int, error) {
+	return p.forEachStory("accept all changes", (*story.Story).AcceptAllChanges)
+}
+
+// RejectAllChanges rejects the tracked changes of all stories and returns how
+// many there were.
+func (p *Package) RejectAllChanges() (int, error) {
+	return p.forEachStory("reject all changes", (*story.Story).RejectAllChanges)
+}
+
+// Notes returns the notes of all stories, ordered by story file name and then
+// by position in the story.
+func (p *Package) Notes() ([]NoteInfo, error) {
+	const op = "list notes"
+
+	doc, err := p.Document()
+	if err != nil {
+		return nil, common.WrapError("idml", op, err)
+	}
+	stories, err := p.Stories()
+	if err != nil {
+		return nil, common.WrapError("idml", op, err)
+	}
+
+	var notes []NoteInfo
+	for _, filename := range sortedStoryFiles(stories) {
+		for _, n := range stories[filename].Notes() {
+			notes = append(notes, NoteInfo{
+				StoryFile: filename,
+				Note:      n,
+				Text:      n.Text(),
+				Author:    findDocumentUser(doc, n.AppliedDocumentUser, n.UserName),
+			})
+		}
+	}
+	return notes, nil
+}
+
+// RemoveAllNotes removes the notes of all stories, as done before publishing,
+// and returns how many there were.
+func (p *Package) RemoveAllNotes() (int, error) {
+	return p.forEachStory("remove all notes", (*story.Story).RemoveAllNotes)
+}
+
+// resolveChange calls resolve on the story holding the change.
+func (p *Package) resolveChange(op, changeID string, resolve func(*story.Story, string) error) error {
+	stories, err := p.Stories()
+	if err != nil {
+		return common.WrapError("idml", op, err)
+	}
+	for _, filename := range sortedStoryFiles(stories) {
+		st := stories[filename]
+		for _, c := range st.Changes() {
+			if c.Change.Self == changeID {
+				if err := resolve(st, changeID); err != nil {
+					return common.WrapErrorWithPath("idml", op, filename, err)
+				}
+				return nil
+			}
+		}
+	}
+	return common.WrapError("idml", op, fmt.Errorf("change %q: %w", changeID, common.ErrNotFound))
+}
+
+// forEachStory calls fn on every story and returns the sum of the results.
+func (p *Package) forEachStory(op string, fn func(*story.Story) int) (int, error) {
+	stories, err := p.Stories()
+	if err != nil {
+		return 0, common.WrapError("idml", op, err)
+	}
+	total := 0
+	for _, st := range stories {
+		total += fn(st)
+	}
+	return total, nil
+}
+
+// sortedStoryFiles returns the file names of stories in sorted order.
+func sortedStoryFiles(stories map[string]*story.Story) []string {
+	files := make([]string, 0, len(stories))
+	for filename := range stories {
+		files = append(files, filename)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// findDocumentUser returns the DocumentUser with the given Self or, if self
+// is empty or unknown, with the given user name.
+func findDocumentUser(doc *document.Document, self, userName string) *document.DocumentUser {
+	for i := range doc.DocumentUsers {
+		if self != "" && doc.DocumentUsers[i].Self == self {
+			return &doc.DocumentUsers[i]
+		}
+	}
+	for i := range doc.DocumentUsers {
+		if userName != "" && doc.DocumentUsers[i].UserName == userName {
+			return &doc.DocumentUsers[i]
+		}
+	}
+	return nil
+}
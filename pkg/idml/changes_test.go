@@ -0,0 +1,106 @@
+package idml
+
+import (
+	"encoding/xml"
+	"errors"
+	"testing"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/story"
+)
+
+// addTrackedChanges adds an insertion and a note by known document users to
+// the hyperlink test story.
+func addTrackedChanges(t *testing.T, pkg *Package) {
+	t.Helper()
+
+	st, err := pkg.Story(hyperlinkStory)
+	if err != nil {
+		t.Fatalf("Story failed: %v", err)
+	}
+	note := story.NewCharacterStyleRange("", nil)
+	note.AddContent("Verify the quote.")
+	csr := &st.StoryElement.ParagraphStyleRanges[0].CharacterStyleRanges[0]
+	csr.Children = append(csr.Children,
+		story.CharacterChild{Change: &story.Change{
+			Self:       "u900",
+			ChangeType: story.ChangeInserted,
+			UserName:   "Naviga Support",
+			Date:       "2024-05-12T10:00:00",
+			Children:   []story.CharacterChild{{Content: &story.Content{Text: " Added."}}},
+		}},
+		story.CharacterChild{Note: &story.Note{
+			XMLName:             xml.Name{Local: "Note"},
+			Self:                "u901",
+			AppliedDocumentUser: "dDocumentUserb",
+			ParagraphStyleRanges: []story.ParagraphStyleRange{{
+				XMLName:               xml.Name{Local: "ParagraphStyleRange"},
+				AppliedParagraphStyle: "ParagraphStyle/$ID/NormalParagraphStyle",
+				CharacterStyleRanges:  []story.CharacterStyleRange{note},
+			}},
+		}},
+	)
+}
+
+// TestChangesAndNotes tests listing tracked changes and notes with their
+// authors, accepting the changes and removing the notes.
+func TestChangesAndNotes(t *testing.T) {
+	pkg := loadExampleIDML(t)
+	addTrackedChanges(t, pkg)
+
+	reread, err := Read(writeTestIDML(t, pkg, "tracked.idml"))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+
+	changes, err := reread.Changes()
+	if err != nil {
+		t.Fatalf("Changes failed: %v", err)
+	}
+	if len(changes) != 1 {
+		t.Fatalf("Changes() returned %d changes, want 1", len(changes))
+	}
+	c := changes[0]
+	if c.StoryFile != hyperlinkStory || c.Text != " Added." || c.Author == nil || c.Author.Self != "dDocumentUsera" {
+		t.Errorf("change = %+v, author = %+v", c, c.Author)
+	}
+
+	notes, err := reread.Notes()
+	if err != nil {
+		t.Fatalf("Notes failed: %v", err)
+	}
+	if len(notes) != 1 || notes[0].Text != "Verify the quote." || notes[0].Author == nil || notes[0].Author.UserName != "Infomaker Scandinavia" {
+		t.Errorf("Notes() = %+v", notes)
+	}
+
+	if err := reread.AcceptChange("u999"); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("AcceptChange(u999) error = %v, want ErrNotFound", err)
+	}
+	if n, err := reread.AcceptAllChanges(); err != nil || n != 1 {
+		t.Errorf("AcceptAllChanges() = %d, %v, want 1", n, err)
+	}
+	if n, err := reread.RemoveAllNotes(); err != nil || n != 1 {
+		t.Errorf("RemoveAllNotes() = %d, %v, want 1", n, err)
+	}
+	st, _ := reread.Story(hyperlinkStory)
+	if got := st.ExtractText(); got != "ANNA ipsum dolor sit amet, consectetur adipiscing elit. Added." {
+		t.Errorf("story text = %q", got)
+	}
+}
+
+// TestRejectChange tests rejecting a single change by Self ID.
+func TestRejectChange(t *testing.T) {
+	pkg := loadExampleIDML(t)
+	addTrackedChanges(t, pkg)
+
+	if err := pkg.RejectChange("u900"); err != nil {
+		t.Fatalf("RejectChange failed: %v", err)
+	}
+	st, _ := pkg.Story(hyperlinkStory)
+	if got := st.ExtractText(); got != "ANNA ipsum dolor sit amet, consectetur adipiscing elit." {
+		t.Errorf("story text = %q", got)
+	}
+	if changes, _ := pkg.Changes(); len(changes) != 0 {
+		t.Errorf("Changes() = %+v, want none", changes)
+	}
+}
@@ -0,0 +1,93 @@
+package idml
+
+import (
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/document"
+)
+
+// FlattenConditions produces one variant of a document with conditional text:
+// text whose conditions are all hidden is removed from every story, the
+// remaining text becomes unconditional, and the conditions and condition sets
+// are removed from designmap.xml. Keys of visible are condition Selfs;
+// conditions missing from the map are hidden.
+//
+// Hyperlinks and cross-references whose source text was removed are removed
+// as well. Hyperlinks pointing at removed text destinations are kept; use
+// ValidateHyperlinks to find them.
+//
+// Example:
+//
+//	// Produce the Norwegian edition
+//	err := pkg.FlattenConditions(map[string]bool{"Condition/Norway": true})
+func (p *Package) FlattenConditions(visible map[string]bool) error {
+	const op = "flatten conditions"
+
+	doc, err := p.Document()
+	if err != nil {
+		return common.WrapError("idml", op, err)
+	}
+	stories, err := p.Stories()
+	if err != nil {
+		return common.WrapError("idml", op, err)
+	}
+
+	removedSources := make(map[string]bool)
+	for _, st := range stories {
+		for _, src := range st.LinkSources() {
+			removedSources[src.Self] = true
+		}
+		st.FlattenConditions(visible)
+		for _, src := range st.LinkSources() {
+			delete(removedSources, src.Self)
+		}
+	}
+
+	links := doc.Hyperlinks[:0]
+	for _, link := range doc.Hyperlinks {
+		if !removedSources[link.Source] {
+			links = append(links, link)
+		}
+	}
+	doc.Hyperlinks = links
+
+	doc.Conditions = nil
+	doc.ConditionSets = nil
+	if doc.ConditionalTextPreference != nil {
+		doc.ConditionalTextPreference.ActiveConditionSet = "n"
+	}
+	return nil
+}
+
+// FlattenConditionSet flattens the document's conditional text like
+// FlattenConditions, using the visibility recorded in the condition set with
+// the given Self or name. Conditions the set does not mention keep their
+// current visibility.
+//
+// Returns common.ErrNotFound if there is no such set.
+func (p *Package) FlattenConditionSet(idOrName string) error {
+	const op = "flatten condition set"
+
+	doc, err := p.Document()
+	if err != nil {
+		return common.WrapError("idml", op, err)
+	}
+	visible, err := conditionSetVisibility(doc, idOrName)
+	if err != nil {
+		return common.WrapError("idml", op, err)
+	}
+	return p.FlattenConditions(visible)
+}
+
+// conditionSetVisibility returns the condition visibility of the document
+// with the condition set applied.
+func conditionSetVisibility(doc *document.Document, idOrName string) (map[string]bool, error) {
+	cs, err := doc.FindConditionSet(idOrName)
+	if err != nil {
+		return nil, err
+	}
+	visible := doc.VisibleConditions()
+	for id, v := range cs.Conditions() {
+		visible[id] = v
+	}
+	return visible, nil
+}
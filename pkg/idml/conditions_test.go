@@ -0,0 +1,88 @@
+package idml
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/document"
+)
+
+// TestFlattenConditionSet tests producing a variant from a condition set:
+// hidden text, the hyperlinks in it and the conditions are removed.
+func TestFlattenConditionSet(t *testing.T) {
+	pkg := loadExampleIDML(t)
+
+	st, err := pkg.Story(hyperlinkStory)
+	if err != nil {
+		t.Fatalf("Story failed: %v", err)
+	}
+	r := st.FindText("dolor sit amet")[0]
+	link, err := pkg.AddURLHyperlink(hyperlinkStory, r.Start, r.End, "https://example.no")
+	if err != nil {
+		t.Fatalf("AddURLHyperlink failed: %v", err)
+	}
+	st.StoryElement.ParagraphStyleRanges[0].CharacterStyleRanges[0].SetAppliedConditions([]string{"Condition/Norway"})
+
+	doc, err := pkg.Document()
+	if err != nil {
+		t.Fatalf("Document failed: %v", err)
+	}
+	doc.Conditions = append(doc.Conditions, document.Condition{Self: "Condition/Norway", Name: "Norway", Visible: "true"})
+	set := document.ConditionSet{Self: "u1a0", Name: "Swedish edition"}
+	set.SetCondition("Condition/Norway", false)
+	doc.ConditionSets = append(doc.ConditionSets, set)
+
+	if err := pkg.FlattenConditionSet("Danish edition"); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("FlattenConditionSet(Danish edition) error = %v, want ErrNotFound", err)
+	}
+	if err := pkg.FlattenConditionSet("Swedish edition"); err != nil {
+		t.Fatalf("FlattenConditionSet failed: %v", err)
+	}
+
+	reread, err := Read(writeTestIDML(t, pkg, "flattened.idml"))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	st, _ = reread.Story(hyperlinkStory)
+	if got := st.ExtractText(); got != "" {
+		t.Errorf("story text = %q, want the Norwegian text removed", got)
+	}
+	if len(st.StoryElement.ParagraphStyleRanges) != 1 {
+		t.Errorf("got %d paragraph ranges, want the first paragraph kept", len(st.StoryElement.ParagraphStyleRanges))
+	}
+	doc, _ = reread.Document()
+	if len(doc.Conditions) != 0 || len(doc.ConditionSets) != 0 {
+		t.Errorf("Conditions = %+v, ConditionSets = %+v, want none", doc.Conditions, doc.ConditionSets)
+	}
+	if doc.ConditionalTextPreference.ActiveConditionSet != "n" {
+		t.Errorf("ActiveConditionSet = %q, want n", doc.ConditionalTextPreference.ActiveConditionSet)
+	}
+	for _, h := range doc.Hyperlinks {
+		if h.Self == link.Self {
+			t.Errorf("hyperlink %s on removed text was kept", link.Self)
+		}
+	}
+}
+
+// TestFlattenConditions_Visible tests that visible conditional text is kept.
+func TestFlattenConditions_Visible(t *testing.T) {
+	pkg := loadExampleIDML(t)
+
+	st, err := pkg.Story(hyperlinkStory)
+	if err != nil {
+		t.Fatalf("Story failed: %v", err)
+	}
+	text := st.ExtractText()
+	st.StoryElement.ParagraphStyleRanges[0].CharacterStyleRanges[0].SetAppliedConditions([]string{"Condition/Norway"})
+
+	if err := pkg.FlattenConditions(map[string]bool{"Condition/Norway": true}); err != nil {
+		t.Fatalf("FlattenConditions failed: %v", err)
+	}
+	if got := st.ExtractText(); got != text {
+		t.Errorf("story text = %q, want %q", got, text)
+	}
+	if got := st.ConditionalRanges(); len(got) != 0 {
+		t.Errorf("ConditionalRanges() = %+v, want the text made unconditional", got)
+	}
+}
@@ -0,0 +1,83 @@
+package idml
+
+import (
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/spread"
+)
+
+// groupedPageItems are the element names of the page items a group holds.
+var groupedPageItems = map[string]bool{
+	"TextFrame":   true,
+	"Rectangle":   true,
+	"Oval":        true,
+	"Polygon":     true,
+	"GraphicLine": true,
+	"Group":       true,
+}
+
+// visitGroupedItems calls visit with the raw element of each page item
+// inside the given groups, in document order, descending into nested
+// groups. Groups keep their items as raw XML, so visit decodes the element
+// itself; after changing it, it calls save to write the element back into
+// the groups it is nested in. Visiting stops when visit returns true.
+func visitGroupedItems(groups []spread.Group, visit func(elem *common.RawXMLElement, save func() error) (bool, error)) error {
+	for i := range groups {
+		done, err := visitGroupElements(groups[i].OtherElements, func() error { return nil }, visit)
+		if err != nil || done {
+			return err
+		}
+	}
+	return nil
+}
+
+// visitGroupElements visits the page items among the raw child elements of
+// a group. saveGroup writes the group back into its parent.
+func visitGroupElements(elems []common.RawXMLElement, saveGroup func() error, visit func(elem *common.RawXMLElement, save func() error) (bool, error)) (bool, error) {
+	for i := range elems {
+		elem := &elems[i]
+		if !groupedPageItems[elem.XMLName.Local] {
+			continue
+		}
+		if done, err := visit(elem, saveGroup); err != nil || done {
+			return done, err
+		}
+		if elem.XMLName.Local != "Group" {
+			continue
+		}
+
+		nested := &spread.Group{}
+		if err := copyElement("Group", elem, nested); err != nil {
+			return false, err
+		}
+		saveNested := func() error {
+			var raw common.RawXMLElement
+			if err := copyElement("Group", nested, &raw); err != nil {
+				return err
+			}
+			*elem = raw
+			return saveGroup()
+		}
+		if done, err := visitGroupElements(nested.OtherElements, saveNested, visit); err != nil || done {
+			return done, err
+		}
+	}
+	return false, nil
+}
+
+// decodeGroupedItem decodes the raw element of a grouped page item into
+// item and returns a function that writes item back into the element and
+// its groups.
+func decodeGroupedItem(elem *common.RawXMLElement, save func() error, item interface{}) (func() error, error) {
+	name := elem.XMLName.Local
+	if err := copyElement(name, elem, item); err != nil {
+		return nil, err
+	}
+	return func() error {
+		var raw common.RawXMLElement
+		if err := copyElement(name, item, &raw); err != nil {
+			return err
+		}
+		*elem = raw
+		return save()
+	}, nil
+}
@@ -0,0 +1,466 @@
+package idml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/document"
+	"github.com/dimelords/idmllib/v2/pkg/story"
+)
+
+// HyperlinkInfo describes a hyperlink or cross-reference with its resolved
+// source and destination.
+type HyperlinkInfo struct {
+	// ID and Name of the Hyperlink element in designmap.xml
+	ID   string
+	Name string
+
+	// Source of the link. StoryFile, SourceRange and SourceText are set for
+	// text sources; CrossReference is true for cross-reference sources.
+	SourceID       string
+	CrossReference bool
+	StoryFile      string
+	SourceRange    story.TextRange
+	SourceText     string
+
+	// Destination of the link. DestinationType is the element name of the
+	// destination (e.g. "HyperlinkURLDestination", "HyperlinkTextDestination")
+	// and is empty if the destination does not exist. URL is set for URL
+	// destinations.
+	DestinationID   string
+	DestinationType string
+	URL             string
+}
+
+// linkSourceRef locates a hyperlink or cross-reference source in a story.
+type linkSourceRef struct {
+	storyFile string
+	source    story.LinkSource
+}
+
+// linkTargets indexes the link sources and destinations of a package.
+type linkTargets struct {
+	sources      map[string]linkSourceRef // text sources by Self
+	otherSources map[string]bool          // page item and other sources in designmap.xml
+	destinations map[string]string        // destination element name by Self
+	urls         map[string]string        // URL by destination Self
+	keys         map[string]string        // DestinationUniqueKey by destination Self
+	formats      map[string]bool          // cross-reference formats by Self
+}
+
+// linkTargets collects the link sources and destinations of all stories and
+// of designmap.xml.
+func (p *Package) linkTargets(doc *document.Document) (*linkTargets, error) {
+	stories, err := p.Stories()
+	if err != nil {
+		return nil, err
+	}
+
+	t := &linkTargets{
+		sources:      make(map[string]linkSourceRef),
+		otherSources: make(map[string]bool),
+		destinations: make(map[string]string),
+		urls:         make(map[string]string),
+		keys:         make(map[string]string),
+		formats:      make(map[string]bool),
+	}
+	for filename, st := range stories {
+		for _, src := range st.LinkSources() {
+			t.sources[src.Self] = linkSourceRef{storyFile: filename, source: src}
+		}
+		for _, dest := range st.TextDestinations() {
+			t.destinations[dest.Self] = dest.XMLName.Local
+			t.keys[dest.Self] = dest.DestinationUniqueKey
+		}
+	}
+
+	for _, dest := range doc.HyperlinkURLDestinations {
+		t.destinations[dest.Self] = "HyperlinkURLDestination"
+		t.urls[dest.Self] = dest.DestinationURL
+		t.keys[dest.Self] = dest.DestinationUniqueKey
+	}
+	for _, dest := range doc.HyperlinkPageDestinations {
+		t.destinations[dest.Self] = "HyperlinkPageDestination"
+		t.keys[dest.Self] = dest.DestinationUniqueKey
+	}
+	for _, src := range doc.HyperlinkPageItemSources {
+		t.otherSources[src.Self] = true
+	}
+	for _, format := range doc.CrossReferenceFormats {
+		t.formats[format.Self] = true
+	}
+
+	// Other sources and external destinations are kept as raw elements
+	for _, elem := range doc.OtherElements {
+		name := elem.XMLName.Local
+		if !strings.HasPrefix(name, "Hyperlink") {
+			continue
+		}
+		var self, key string
+		for _, attr := range elem.Attrs {
+			switch attr.Name.Local {
+			case "Self":
+				self = attr.Value
+			case "DestinationUniqueKey":
+				key = attr.Value
+			}
+		}
+		switch {
+		case strings.HasSuffix(name, "Source"):
+			t.otherSources[self] = true
+		case strings.HasSuffix(name, "Destination"):
+			t.destinations[self] = name
+			t.keys[self] = key
+		}
+	}
+	return t, nil
+}
+
+// Hyperlinks returns all hyperlinks and cross-references in the document with
+// their source text and destination, in designmap.xml order.
+//
+// Example:
+//
+//	links, err := pkg.Hyperlinks()
+//	for _, link := range links {
+//	    fmt.Printf("%q -> %s\n", link.SourceText, link.URL)
+//	}
+func (p *Package) Hyperlinks() ([]HyperlinkInfo, error) {
+	doc, err := p.Document()
+	if err != nil {
+		return nil, common.WrapError("idml", "list hyperlinks", err)
+	}
+	targets, err := p.linkTargets(doc)
+	if err != nil {
+		return nil, common.WrapError("idml", "list hyperlinks", err)
+	}
+
+	links := make([]HyperlinkInfo, 0, len(doc.Hyperlinks))
+	for _, h := range doc.Hyperlinks {
+		info := HyperlinkInfo{
+			ID:              h.Self,
+			Name:            h.Name,
+			SourceID:        h.Source,
+			DestinationID:   h.DestinationID(),
+			DestinationType: targets.destinations[h.DestinationID()],
+			URL:             targets.urls[h.DestinationID()],
+		}
+		if ref, ok := targets.sources[h.Source]; ok {
+			info.CrossReference = ref.source.CrossReference
+			info.StoryFile = ref.storyFile
+			info.SourceRange = ref.source.Range
+			info.SourceText = ref.source.Text
+		}
+		links = append(links, info)
+	}
+	return links, nil
+}
+
+// AddURLHyperlink links the text in [start, end) of a story to url and
+// returns the new hyperlink.
+//
+// The text is wrapped in a new HyperlinkTextSource and an existing
+// HyperlinkURLDestination for url is reused if there is one. The range must
+// lie within a single character style range and is given as byte offsets into
+// the story's ExtractText output.
+//
+// Example:
+//
+//	r := st.FindText("our website")[0]
+//	link, err := pkg.AddURLHyperlink("Stories/Story_u1d8.xml", r.Start, r.End, "https://example.com")
+func (p *Package) AddURLHyperlink(storyFile string, start, end int, url string) (*document.Hyperlink, error) {
+	const op = "add hyperlink"
+	if url == "" {
+		return nil, common.Errorf("idml", op, storyFile, "URL is empty")
+	}
+
+	st, err := p.Story(storyFile)
+	if err != nil {
+		return nil, common.WrapErrorWithPath("idml", op, storyFile, err)
+	}
+	doc, err := p.Document()
+	if err != nil {
+		return nil, common.WrapError("idml", op, err)
+	}
+	used, err := p.usedSelfIDs()
+	if err != nil {
+		return nil, common.WrapError("idml", op, err)
+	}
+	ids := newIDAllocator(used)
+	targets, err := p.linkTargets(doc)
+	if err != nil {
+		return nil, common.WrapError("idml", op, err)
+	}
+
+	name := uniqueHyperlinkName(doc, url)
+	sourceID := ids.next()
+	if _, err := st.AddHyperlinkSource(start, end, sourceID, name); err != nil {
+		return nil, common.WrapErrorWithPath("idml", op, storyFile, err)
+	}
+
+	dest := urlDestination(doc, url, ids, targets)
+	doc.Hyperlinks = append(doc.Hyperlinks, document.Hyperlink{
+		XMLName:              xml.Name{Local: "Hyperlink"},
+		Self:                 ids.next(),
+		Name:                 name,
+		Source:               sourceID,
+		Visible:              "false",
+		Highlight:            "None",
+		Width:                "Thin",
+		BorderStyle:          "Solid",
+		Hidden:               "false",
+		DestinationUniqueKey: dest.DestinationUniqueKey,
+		Properties: &document.HyperlinkProperties{
+			XMLName: xml.Name{Local: "Properties"},
+			BorderColor: &common.RawXMLElement{
+				XMLName: xml.Name{Local: "BorderColor"},
+				Attrs:   []xml.Attr{{Name: xml.Name{Local: "type"}, Value: "enumeration"}},
+				Content: []byte("Black"),
+			},
+			Destination: &document.PropertyValue{Type: "object", Value: dest.Self},
+		},
+	})
+	return &doc.Hyperlinks[len(doc.Hyperlinks)-1], nil
+}
+
+// RetargetHyperlink points a hyperlink or cross-reference at another existing
+// destination, such as a HyperlinkURLDestination, a HyperlinkPageDestination
+// or a text destination in a story.
+//
+// Returns common.ErrNotFound if the hyperlink or the destination does not exist.
+func (p *Package) RetargetHyperlink(hyperlinkID, destinationID string) error {
+	const op = "retarget hyperlink"
+
+	doc, err := p.Document()
+	if err != nil {
+		return common.WrapError("idml", op, err)
+	}
+	h := findHyperlink(doc, hyperlinkID)
+	if h == nil {
+		return common.WrapError("idml", op, fmt.Errorf("hyperlink %q: %w", hyperlinkID, common.ErrNotFound))
+	}
+	targets, err := p.linkTargets(doc)
+	if err != nil {
+		return common.WrapError("idml", op, err)
+	}
+	if _, ok := targets.destinations[destinationID]; !ok {
+		return common.WrapError("idml", op, fmt.Errorf("destination %q: %w", destinationID, common.ErrNotFound))
+	}
+
+	h.SetDestinationID(destinationID)
+	h.DestinationUniqueKey = targets.keys[destinationID]
+	return nil
+}
+
+// SetHyperlinkURL points a hyperlink at url, reusing or creating a
+// HyperlinkURLDestination for it.
+//
+// Returns common.ErrNotFound if the hyperlink does not exist.
+func (p *Package) SetHyperlinkURL(hyperlinkID, url string) error {
+	const op = "set hyperlink URL"
+	if url == "" {
+		return common.Errorf("idml", op, "", "URL is empty")
+	}
+
+	doc, err := p.Document()
+	if err != nil {
+		return common.WrapError("idml", op, err)
+	}
+	h := findHyperlink(doc, hyperlinkID)
+	if h == nil {
+		return common.WrapError("idml", op, fmt.Errorf("hyperlink %q: %w", hyperlinkID, common.ErrNotFound))
+	}
+	used, err := p.usedSelfIDs()
+	if err != nil {
+		return common.WrapError("idml", op, err)
+	}
+	targets, err := p.linkTargets(doc)
+	if err != nil {
+		return common.WrapError("idml", op, err)
+	}
+
+	dest := urlDestination(doc, url, newIDAllocator(used), targets)
+	h.SetDestinationID(dest.Self)
+	h.DestinationUniqueKey = dest.DestinationUniqueKey
+	return nil
+}
+
+// RemoveHyperlink removes a hyperlink or cross-reference.
+//
+// The hyperlink text stays in the story but is no longer wrapped in a source;
+// the generated text of a cross-reference is removed. URL destinations that
+// no other hyperlink uses are removed as well.
+//
+// Returns common.ErrNotFound if the hyperlink does not exist.
+func (p *Package) RemoveHyperlink(hyperlinkID string) error {
+	const op = "remove hyperlink"
+
+	doc, err := p.Document()
+	if err != nil {
+		return common.WrapError("idml", op, err)
+	}
+	h := findHyperlink(doc, hyperlinkID)
+	if h == nil {
+		return common.WrapError("idml", op, fmt.Errorf("hyperlink %q: %w", hyperlinkID, common.ErrNotFound))
+	}
+	targets, err := p.linkTargets(doc)
+	if err != nil {
+		return common.WrapError("idml", op, err)
+	}
+
+	if ref, ok := targets.sources[h.Source]; ok {
+		st, err := p.Story(ref.storyFile)
+		if err != nil {
+			return common.WrapErrorWithPath("idml", op, ref.storyFile, err)
+		}
+		if err := st.RemoveLinkSource(h.Source); err != nil {
+			return common.WrapErrorWithPath("idml", op, ref.storyFile, err)
+		}
+	}
+
+	destinationID := h.DestinationID()
+	links := doc.Hyperlinks[:0]
+	for _, link := range doc.Hyperlinks {
+		if link.Self != hyperlinkID {
+			links = append(links, link)
+		}
+	}
+	doc.Hyperlinks = links
+
+	for _, link := range doc.Hyperlinks {
+		if link.DestinationID() == destinationID {
+			return nil
+		}
+	}
+	dests := doc.HyperlinkURLDestinations[:0]
+	for _, dest := range doc.HyperlinkURLDestinations {
+		if dest.Self != destinationID {
+			dests = append(dests, dest)
+		}
+	}
+	doc.HyperlinkURLDestinations = dests
+	return nil
+}
+
+// ValidateHyperlinks checks that every hyperlink has an existing source and
+// destination, that no source is used by more than one hyperlink and that
+// every cross-reference source uses an existing CrossReferenceFormat.
+//
+// Returns a slice of ValidationError for each problem found. An empty slice
+// means all hyperlinks resolve.
+func (p *Package) ValidateHyperlinks() ([]ValidationError, error) {
+	doc, err := p.Document()
+	if err != nil {
+		return nil, common.WrapError("idml", "validate hyperlinks", err)
+	}
+	targets, err := p.linkTargets(doc)
+	if err != nil {
+		return nil, common.WrapError("idml", "validate hyperlinks", err)
+	}
+
+	errs := []ValidationError{}
+	linked := make(map[string][]string)
+	for _, h := range doc.Hyperlinks {
+		linked[h.Source] = append(linked[h.Source], h.Self)
+
+		if _, ok := targets.sources[h.Source]; !ok && !targets.otherSources[h.Source] {
+			errs = append(errs, ValidationError{
+				ResourceType: "HyperlinkSource",
+				ResourceID:   h.Source,
+				UsedBy:       []string{h.Self},
+				Message:      fmt.Sprintf("Hyperlink '%s' refers to source '%s', which does not exist", h.Self, h.Source),
+			})
+		}
+
+		dest := h.DestinationID()
+		if _, ok := targets.destinations[dest]; !ok {
+			errs = append(errs, ValidationError{
+				ResourceType: "HyperlinkDestination",
+				ResourceID:   dest,
+				UsedBy:       []string{h.Self},
+				Message:      fmt.Sprintf("Hyperlink '%s' refers to destination '%s', which does not exist", h.Self, dest),
+			})
+		}
+	}
+
+	sources := make([]string, 0, len(targets.sources))
+	for id := range targets.sources {
+		sources = append(sources, id)
+	}
+	sort.Strings(sources)
+	for _, id := range sources {
+		ref := targets.sources[id]
+		if users := linked[id]; len(users) > 1 {
+			errs = append(errs, ValidationError{
+				ResourceType: "HyperlinkSource",
+				ResourceID:   id,
+				UsedBy:       users,
+				Message:      fmt.Sprintf("Source '%s' in %s is used by %d hyperlinks", id, ref.storyFile, len(users)),
+			})
+		}
+		if format := ref.source.AppliedFormat; ref.source.CrossReference && format != "" && !targets.formats[format] {
+			errs = append(errs, ValidationError{
+				ResourceType: "CrossReferenceFormat",
+				ResourceID:   format,
+				UsedBy:       []string{id},
+				Message:      fmt.Sprintf("Cross-reference format '%s' is referenced but not defined in designmap.xml", format),
+			})
+		}
+	}
+	return errs, nil
+}
+
+// findHyperlink returns the hyperlink with the given Self, or nil.
+func findHyperlink(doc *document.Document, id string) *document.Hyperlink {
+	for i := range doc.Hyperlinks {
+		if doc.Hyperlinks[i].Self == id {
+			return &doc.Hyperlinks[i]
+		}
+	}
+	return nil
+}
+
+// urlDestination returns the URL destination for url, adding one if the
+// document has none. New destinations get a DestinationUniqueKey above all
+// keys in targets.
+func urlDestination(doc *document.Document, url string, ids *idAllocator, targets *linkTargets) *document.HyperlinkURLDestination {
+	for i := range doc.HyperlinkURLDestinations {
+		if doc.HyperlinkURLDestinations[i].DestinationURL == url {
+			return &doc.HyperlinkURLDestinations[i]
+		}
+	}
+
+	highest := 0
+	for _, key := range targets.keys {
+		if n, err := strconv.Atoi(key); err == nil && n > highest {
+			highest = n
+		}
+	}
+	doc.HyperlinkURLDestinations = append(doc.HyperlinkURLDestinations, document.HyperlinkURLDestination{
+		XMLName:              xml.Name{Local: "HyperlinkURLDestination"},
+		Self:                 ids.next(),
+		Name:                 url,
+		DestinationUniqueKey: strconv.Itoa(highest + 1),
+		DestinationURL:       url,
+		Hidden:               "false",
+	})
+	return &doc.HyperlinkURLDestinations[len(doc.HyperlinkURLDestinations)-1]
+}
+
+// uniqueHyperlinkName returns name, or name with a number appended if a
+// hyperlink with that name already exists.
+func uniqueHyperlinkName(doc *document.Document, name string) string {
+	taken := make(map[string]bool, len(doc.Hyperlinks))
+	for _, h := range doc.Hyperlinks {
+		taken[h.Name] = true
+	}
+	candidate := name
+	for n := 2; taken[candidate]; n++ {
+		candidate = fmt.Sprintf("%s %d", name, n)
+	}
+	return candidate
+}
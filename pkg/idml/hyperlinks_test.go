@@ -0,0 +1,165 @@
+package idml
+
+import (
+	"encoding/xml"
+	"errors"
+	"testing"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/document"
+)
+
+const hyperlinkStory = "Stories/Story_u2ee.xml"
+
+// TestAddURLHyperlink tests linking story text to a URL and reading it back.
+func TestAddURLHyperlink(t *testing.T) {
+	pkg := loadExampleIDML(t)
+
+	st, err := pkg.Story(hyperlinkStory)
+	if err != nil {
+		t.Fatalf("Story failed: %v", err)
+	}
+	r := st.FindText("dolor sit amet")[0]
+
+	link, err := pkg.AddURLHyperlink(hyperlinkStory, r.Start, r.End, "https://example.com")
+	if err != nil {
+		t.Fatalf("AddURLHyperlink failed: %v", err)
+	}
+	if link.Name != "https://example.com" || link.Source == "" || link.DestinationID() == "" {
+		t.Errorf("new hyperlink = %+v", link)
+	}
+
+	// A second link to the same URL shares the destination and gets a unique name
+	r2 := st.FindText("consectetur")[0]
+	link2, err := pkg.AddURLHyperlink(hyperlinkStory, r2.Start, r2.End, "https://example.com")
+	if err != nil {
+		t.Fatalf("AddURLHyperlink (second) failed: %v", err)
+	}
+	if link2.DestinationID() != link.DestinationID() || link2.Name != "https://example.com 2" {
+		t.Errorf("second hyperlink = %+v", link2)
+	}
+
+	reread, err := Read(writeTestIDML(t, pkg, "hyperlinks.idml"))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	links, err := reread.Hyperlinks()
+	if err != nil {
+		t.Fatalf("Hyperlinks failed: %v", err)
+	}
+	if len(links) != 2 {
+		t.Fatalf("Hyperlinks() returned %d links, want 2", len(links))
+	}
+	got := links[0]
+	if got.SourceText != "dolor sit amet" || got.StoryFile != hyperlinkStory || got.SourceRange != r ||
+		got.URL != "https://example.com" || got.DestinationType != "HyperlinkURLDestination" || got.CrossReference {
+		t.Errorf("first link = %+v", got)
+	}
+
+	doc, err := reread.Document()
+	if err != nil {
+		t.Fatalf("Document failed: %v", err)
+	}
+	if len(doc.HyperlinkURLDestinations) != 1 || len(doc.CrossReferenceFormats) != 9 {
+		t.Errorf("got %d URL destinations and %d cross-reference formats, want 1 and 9",
+			len(doc.HyperlinkURLDestinations), len(doc.CrossReferenceFormats))
+	}
+
+	errs, err := reread.ValidateHyperlinks()
+	if err != nil {
+		t.Fatalf("ValidateHyperlinks failed: %v", err)
+	}
+	if len(errs) != 0 {
+		t.Errorf("ValidateHyperlinks() = %v, want no errors", errs)
+	}
+}
+
+// TestRetargetAndRemoveHyperlink tests changing and removing hyperlinks.
+func TestRetargetAndRemoveHyperlink(t *testing.T) {
+	pkg := loadExampleIDML(t)
+
+	st, err := pkg.Story(hyperlinkStory)
+	if err != nil {
+		t.Fatalf("Story failed: %v", err)
+	}
+	text := st.ExtractText()
+	r := st.FindText("ipsum")[0]
+	link, err := pkg.AddURLHyperlink(hyperlinkStory, r.Start, r.End, "https://example.com/old")
+	if err != nil {
+		t.Fatalf("AddURLHyperlink failed: %v", err)
+	}
+	id, oldDest := link.Self, link.DestinationID()
+
+	if err := pkg.SetHyperlinkURL(id, "https://example.com/new"); err != nil {
+		t.Fatalf("SetHyperlinkURL failed: %v", err)
+	}
+	links, err := pkg.Hyperlinks()
+	if err != nil {
+		t.Fatalf("Hyperlinks failed: %v", err)
+	}
+	if links[0].URL != "https://example.com/new" {
+		t.Errorf("URL = %q after SetHyperlinkURL", links[0].URL)
+	}
+
+	if err := pkg.RetargetHyperlink(id, oldDest); err != nil {
+		t.Fatalf("RetargetHyperlink failed: %v", err)
+	}
+	if links, _ := pkg.Hyperlinks(); links[0].URL != "https://example.com/old" {
+		t.Errorf("URL = %q after RetargetHyperlink", links[0].URL)
+	}
+	if err := pkg.RetargetHyperlink(id, "u-missing"); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("RetargetHyperlink to missing destination error = %v, want ErrNotFound", err)
+	}
+
+	if err := pkg.RemoveHyperlink(id); err != nil {
+		t.Fatalf("RemoveHyperlink failed: %v", err)
+	}
+	doc, err := pkg.Document()
+	if err != nil {
+		t.Fatalf("Document failed: %v", err)
+	}
+	if len(doc.Hyperlinks) != 0 {
+		t.Errorf("%d hyperlinks left after removal", len(doc.Hyperlinks))
+	}
+	// The new URL destination is still there; the old one was only used by the removed link
+	if len(doc.HyperlinkURLDestinations) != 1 || doc.HyperlinkURLDestinations[0].DestinationURL != "https://example.com/new" {
+		t.Errorf("URL destinations after removal = %+v", doc.HyperlinkURLDestinations)
+	}
+	if len(st.LinkSources()) != 0 || st.ExtractText() != text {
+		t.Errorf("story after removal has sources %v and text %q", st.LinkSources(), st.ExtractText())
+	}
+
+	if err := pkg.RemoveHyperlink(id); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("RemoveHyperlink twice error = %v, want ErrNotFound", err)
+	}
+}
+
+// TestValidateHyperlinks tests detection of dangling sources and destinations.
+func TestValidateHyperlinks(t *testing.T) {
+	pkg := loadExampleIDML(t)
+
+	doc, err := pkg.Document()
+	if err != nil {
+		t.Fatalf("Document failed: %v", err)
+	}
+	broken := document.Hyperlink{
+		XMLName: xml.Name{Local: "Hyperlink"},
+		Self:    "uf01",
+		Name:    "Broken",
+		Source:  "uf02",
+	}
+	broken.SetDestinationID("uf03")
+	doc.Hyperlinks = append(doc.Hyperlinks, broken)
+
+	errs, err := pkg.ValidateHyperlinks()
+	if err != nil {
+		t.Fatalf("ValidateHyperlinks failed: %v", err)
+	}
+	found := make(map[string]string)
+	for _, e := range errs {
+		found[e.ResourceType] = e.ResourceID
+	}
+	if found["HyperlinkSource"] != "uf02" || found["HyperlinkDestination"] != "uf03" || len(errs) != 2 {
+		t.Errorf("ValidateHyperlinks() = %v, want missing source uf02 and destination uf03", errs)
+	}
+}
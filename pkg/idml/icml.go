@@ -0,0 +1,170 @@
+package idml
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/document"
+	"github.com/dimelords/idmllib/v2/pkg/icml"
+	"github.com/dimelords/idmllib/v2/pkg/story"
+)
+
+// ExportStoryAsICML exports a story as an InCopy (ICML) file.
+//
+// Like InDesign's own export, the ICML carries the package's paragraph,
+// character and object styles, colors, swatches, stroke styles and
+// conditions, so the story can be edited in InCopy on its own. The returned
+// package is a copy; changing it does not change the IDML package.
+//
+// Example:
+//
+//	ic, err := pkg.ExportStoryAsICML("Stories/Story_u1d8.xml")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	err = icml.Write(ic, "story.icml")
+func (p *Package) ExportStoryAsICML(storyFile string) (*icml.Package, error) {
+	const op = "export story as ICML"
+
+	st, err := p.Story(storyFile)
+	if err != nil {
+		return nil, common.WrapErrorWithPath("idml", op, storyFile, err)
+	}
+	doc, err := p.Document()
+	if err != nil {
+		return nil, common.WrapErrorWithPath("idml", op, storyFile, err)
+	}
+	styles, err := p.Styles()
+	if err != nil {
+		return nil, common.WrapErrorWithPath("idml", op, PathStyles, err)
+	}
+	graphics, err := p.Graphics()
+	if err != nil {
+		return nil, common.WrapErrorWithPath("idml", op, PathGraphic, err)
+	}
+
+	ic := icml.New()
+	ic.Document = &document.Document{
+		Self:                    "d",
+		DOMVersion:              doc.DOMVersion,
+		Colors:                  graphics.Colors,
+		Swatches:                graphics.Swatches,
+		StrokeStyles:            graphics.StrokeStyles,
+		RootCharacterStyleGroup: styles.RootCharacterStyleGroup,
+		RootParagraphStyleGroup: styles.RootParagraphStyleGroup,
+		RootObjectStyleGroup:    styles.RootObjectStyleGroup,
+		Conditions:              doc.Conditions,
+		InlineStories:           []story.StoryElement{st.StoryElement},
+	}
+
+	// Parsing the marshaled ICML gives a copy that shares nothing with the package
+	data, err := icml.Marshal(ic)
+	if err != nil {
+		return nil, common.WrapErrorWithPath("idml", op, storyFile, err)
+	}
+	ic, err = icml.Parse(data)
+	if err != nil {
+		return nil, common.WrapErrorWithPath("idml", op, storyFile, err)
+	}
+	return ic, nil
+}
+
+// ReplaceStoryFromICML replaces the content of a story with the story of an
+// ICML file, typically one exported with ExportStoryAsICML and edited in
+// InCopy. The story keeps its Self ID, so its text frames show the new
+// content.
+//
+// Styles, colors, swatches and conditions of the ICML that are missing from
+// the package are added; existing ones keep their definition. IDs inside
+// the ICML story that are used elsewhere in the package are renumbered.
+//
+// Returns common.ErrNotFound if the package has no such story or the ICML
+// has no story.
+func (p *Package) ReplaceStoryFromICML(storyFile string, ic *icml.Package) error {
+	const op = "replace story from ICML"
+
+	if ic == nil {
+		return common.Errorf("idml", op, storyFile, "ICML package is nil")
+	}
+	src, err := ic.Story()
+	if err != nil {
+		return common.WrapErrorWithPath("idml", op, storyFile, err)
+	}
+	old, err := p.Story(storyFile)
+	if err != nil {
+		return common.WrapErrorWithPath("idml", op, storyFile, err)
+	}
+	doc, err := p.Document()
+	if err != nil {
+		return common.WrapErrorWithPath("idml", op, storyFile, err)
+	}
+
+	// Step 1: Merge styles, colors, swatches and stroke styles
+	if ic.Document.RootParagraphStyleGroup != nil || ic.Document.RootCharacterStyleGroup != nil || ic.Document.RootObjectStyleGroup != nil {
+		styles, err := p.Styles()
+		if err != nil {
+			return common.WrapErrorWithPath("idml", op, PathStyles, err)
+		}
+		mergeSnippetStyles(styles, ic.Document)
+		p.SetStyles(styles)
+	}
+	if len(ic.Document.Colors) > 0 || len(ic.Document.Swatches) > 0 || len(ic.Document.StrokeStyles) > 0 {
+		graphics, err := p.Graphics()
+		if err != nil {
+			return common.WrapErrorWithPath("idml", op, PathGraphic, err)
+		}
+		mergeSnippetGraphics(graphics, ic.Document)
+		p.SetGraphics(graphics)
+	}
+
+	// Step 2: Merge conditions
+	for _, c := range ic.Document.Conditions {
+		if _, err := doc.FindCondition(c.Self); err != nil {
+			doc.Conditions = append(doc.Conditions, c)
+		}
+	}
+
+	// Step 3: Renumber IDs used outside the story being replaced
+	used, err := p.usedSelfIDs()
+	if err != nil {
+		return common.WrapErrorWithPath("idml", op, storyFile, err)
+	}
+	oldData, err := story.MarshalStory(old)
+	if err != nil {
+		return common.WrapErrorWithPath("idml", op, storyFile, err)
+	}
+	oldIDs := make(map[string]bool)
+	collectSelfIDs(oldData, oldIDs)
+	for id := range oldIDs {
+		delete(used, id)
+	}
+
+	elem := *src
+	elem.Self = old.StoryElement.Self
+	data, err := xml.Marshal(snippetContent{Stories: []story.StoryElement{elem}})
+	if err != nil {
+		return common.WrapErrorWithPath("idml", op, storyFile, err)
+	}
+	icmlIDs := make(map[string]bool)
+	collectSelfIDs(data, icmlIDs)
+	ids := newIDAllocator(used, oldIDs, icmlIDs)
+	renames := make(map[string]string)
+	for _, m := range selfAttrPattern.FindAllSubmatch(data, -1) {
+		if id := string(m[1]); id != elem.Self && used[id] && renames[id] == "" {
+			renames[id] = ids.next()
+		}
+	}
+	// Decoding the renamed XML also gives a copy, leaving the ICML unchanged
+	var content snippetContent
+	if err := xml.Unmarshal(renameIDReferences(data, renames), &content); err != nil {
+		return common.WrapErrorWithPath("idml", op, storyFile, err)
+	}
+	if len(content.Stories) != 1 {
+		return common.WrapErrorWithPath("idml", op, storyFile, fmt.Errorf("decode ICML story: %w", common.ErrInvalidFormat))
+	}
+
+	// Step 4: Replace the story
+	st := &story.Story{DOMVersion: old.DOMVersion, StoryElement: content.Stories[0]}
+	return p.marshalAndUpdateStory(storyFile, st)
+}
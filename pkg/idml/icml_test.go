@@ -0,0 +1,164 @@
+package idml
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/icml"
+	"github.com/dimelords/idmllib/v2/pkg/resources"
+	"github.com/dimelords/idmllib/v2/pkg/story"
+)
+
+// TestICMLRoundtrip tests exporting a story as ICML, editing it and flowing
+// it back into the package.
+func TestICMLRoundtrip(t *testing.T) {
+	pkg := loadExampleIDML(t)
+
+	ic, err := pkg.ExportStoryAsICML(hyperlinkStory)
+	if err != nil {
+		t.Fatalf("ExportStoryAsICML failed: %v", err)
+	}
+	elem, err := ic.Story()
+	if err != nil {
+		t.Fatalf("Story failed: %v", err)
+	}
+	if elem.Self != "u2ee" {
+		t.Errorf("ICML story Self = %q, want u2ee", elem.Self)
+	}
+	if ic.Document.RootParagraphStyleGroup == nil || len(ic.Document.Swatches) == 0 {
+		t.Error("expected the ICML to carry styles and swatches")
+	}
+
+	// Editing the ICML must not change the package until it is flowed back
+	edited := &story.Story{StoryElement: *elem}
+	if n := edited.ReplaceText("ANNA", "ANNE"); n == 0 {
+		t.Fatal("ReplaceText found nothing to replace")
+	}
+	ic.SetStory(edited.StoryElement)
+	original, _ := pkg.Story(hyperlinkStory)
+	if strings.Contains(original.ExtractText(), "ANNE") {
+		t.Fatal("editing the ICML changed the package story")
+	}
+
+	// Go through the file format, as the copy editor would
+	data, err := icml.Marshal(ic)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	ic, err = icml.Parse(data)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	// A new style used by the editor is added to the package
+	newStyle := resources.ParagraphStyle{Self: "ParagraphStyle/Copy Desk", Name: "Copy Desk"}
+	ic.Document.RootParagraphStyleGroup.ParagraphStyles = append(ic.Document.RootParagraphStyleGroup.ParagraphStyles, newStyle)
+
+	if err := pkg.ReplaceStoryFromICML(hyperlinkStory, ic); err != nil {
+		t.Fatalf("ReplaceStoryFromICML failed: %v", err)
+	}
+
+	reread, err := Read(writeTestIDML(t, pkg, "icml.idml"))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	st, err := reread.Story(hyperlinkStory)
+	if err != nil {
+		t.Fatalf("Story failed: %v", err)
+	}
+	if st.StoryElement.Self != "u2ee" {
+		t.Errorf("story Self = %q, want u2ee", st.StoryElement.Self)
+	}
+	if text := st.ExtractText(); !strings.Contains(text, "ANNE") || strings.Contains(text, "ANNA") {
+		t.Errorf("story text was not replaced: %q", text)
+	}
+	styles, err := reread.Styles()
+	if err != nil {
+		t.Fatalf("Styles failed: %v", err)
+	}
+	if styles.FindParagraphStyle("ParagraphStyle/Copy Desk") == nil {
+		t.Error("style from the ICML was not added")
+	}
+}
+
+// TestReplaceStoryFromICMLRenumbers tests that IDs in a foreign ICML story
+// that collide with the package are renumbered.
+func TestReplaceStoryFromICMLRenumbers(t *testing.T) {
+	pkg := loadExampleIDML(t)
+	addTrackedChanges(t, pkg)
+
+	// Flow the hyperlink story, with its change and note, into another story:
+	// all its IDs are in use outside the story being replaced
+	stories, err := pkg.Stories()
+	if err != nil {
+		t.Fatalf("Stories failed: %v", err)
+	}
+	var target string
+	for _, filename := range sortedStoryFiles(stories) {
+		if filename != hyperlinkStory {
+			target = filename
+			break
+		}
+	}
+	targetSelf := stories[target].StoryElement.Self
+
+	ic, err := pkg.ExportStoryAsICML(hyperlinkStory)
+	if err != nil {
+		t.Fatalf("ExportStoryAsICML failed: %v", err)
+	}
+	src, _ := ic.Story()
+	want := (&story.Story{StoryElement: *src}).ExtractText()
+
+	if err := pkg.ReplaceStoryFromICML(target, ic); err != nil {
+		t.Fatalf("ReplaceStoryFromICML failed: %v", err)
+	}
+	st, err := pkg.Story(target)
+	if err != nil {
+		t.Fatalf("Story failed: %v", err)
+	}
+	if st.StoryElement.Self != targetSelf {
+		t.Errorf("story Self = %q, want %q", st.StoryElement.Self, targetSelf)
+	}
+	if got := st.ExtractText(); got != want {
+		t.Errorf("story text = %q, want %q", got, want)
+	}
+	if src, _ := ic.Story(); src.Self != "u2ee" {
+		t.Error("ReplaceStoryFromICML changed the ICML")
+	}
+
+	changes := st.Changes()
+	if len(changes) != 1 || changes[0].Change.Self == "u900" {
+		t.Errorf("expected the change to be renumbered, got %+v", changes)
+	}
+	if notes := st.Notes(); len(notes) != 1 || notes[0].Self == "u901" {
+		t.Error("expected the note to be renumbered")
+	}
+	if changes, _ := pkg.Changes(); len(changes) != 2 {
+		t.Errorf("Changes() found %d changes, want 2", len(changes))
+	}
+}
+
+// TestICMLErrors tests the errors of the ICML functions.
+func TestICMLErrors(t *testing.T) {
+	pkg := loadExampleIDML(t)
+
+	if _, err := pkg.ExportStoryAsICML("Stories/Story_u999.xml"); err == nil {
+		t.Error("expected error exporting a missing story")
+	}
+
+	ic, err := pkg.ExportStoryAsICML(hyperlinkStory)
+	if err != nil {
+		t.Fatalf("ExportStoryAsICML failed: %v", err)
+	}
+	if err := pkg.ReplaceStoryFromICML("Stories/Story_u999.xml", ic); err == nil {
+		t.Error("expected error replacing a missing story")
+	}
+	if err := pkg.ReplaceStoryFromICML(hyperlinkStory, nil); err == nil {
+		t.Error("expected error for a nil ICML package")
+	}
+	if err := pkg.ReplaceStoryFromICML(hyperlinkStory, icml.New()); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("ReplaceStoryFromICML without a story error = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,61 @@
+package idml
+
+import (
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/story"
+)
+
+// ExportStoryMarkdown returns a story as Markdown, like
+// story.Story.ExportMarkdown, with hyperlinks resolved: text inside a
+// hyperlink source pointing at a URL destination becomes a Markdown link.
+// URLs already in opts.Links take precedence.
+//
+// Example:
+//
+//	md, err := pkg.ExportStoryMarkdown("Stories/Story_u1d8.xml", story.MarkupOptions{
+//	    ParagraphTags: map[string]string{"Headline": "h1", "Subhead": "h2"},
+//	    CharacterTags: map[string]string{"Bold": "strong", "Italic": "em"},
+//	})
+func (p *Package) ExportStoryMarkdown(storyFile string, opts story.MarkupOptions) (string, error) {
+	st, opts, err := p.markupStory("export story markdown", storyFile, opts)
+	if err != nil {
+		return "", err
+	}
+	return st.ExportMarkdown(opts), nil
+}
+
+// ExportStoryHTML returns a story as semantic HTML, like
+// story.Story.ExportHTML, with hyperlinks resolved as in
+// ExportStoryMarkdown.
+func (p *Package) ExportStoryHTML(storyFile string, opts story.MarkupOptions) (string, error) {
+	st, opts, err := p.markupStory("export story HTML", storyFile, opts)
+	if err != nil {
+		return "", err
+	}
+	return st.ExportHTML(opts), nil
+}
+
+// markupStory returns the story and opts with the URLs of its hyperlinks
+// added to a copy of opts.Links.
+func (p *Package) markupStory(op, storyFile string, opts story.MarkupOptions) (*story.Story, story.MarkupOptions, error) {
+	st, err := p.Story(storyFile)
+	if err != nil {
+		return nil, opts, common.WrapErrorWithPath("idml", op, storyFile, err)
+	}
+	links, err := p.Hyperlinks()
+	if err != nil {
+		return nil, opts, common.WrapErrorWithPath("idml", op, storyFile, err)
+	}
+
+	urls := make(map[string]string, len(opts.Links))
+	for _, link := range links {
+		if link.StoryFile == storyFile && link.URL != "" {
+			urls[link.SourceID] = link.URL
+		}
+	}
+	for source, url := range opts.Links {
+		urls[source] = url
+	}
+	opts.Links = urls
+	return st, opts, nil
+}
@@ -0,0 +1,54 @@
+package idml
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dimelords/idmllib/v2/pkg/story"
+)
+
+// TestExportStoryMarkup tests exporting a story as Markdown and HTML with
+// its hyperlinks resolved from designmap.xml.
+func TestExportStoryMarkup(t *testing.T) {
+	pkg := loadExampleIDML(t)
+
+	st, err := pkg.Story(hyperlinkStory)
+	if err != nil {
+		t.Fatalf("Story failed: %v", err)
+	}
+	r := st.FindText("dolor sit amet")[0]
+	if _, err := pkg.AddURLHyperlink(hyperlinkStory, r.Start, r.End, "https://example.com/a?b=1&c=2"); err != nil {
+		t.Fatalf("AddURLHyperlink failed: %v", err)
+	}
+
+	reread, err := Read(writeTestIDML(t, pkg, "markup.idml"))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	opts := story.MarkupOptions{
+		ParagraphTags: map[string]string{"Naviga:Standard:preamble-TEK ingress": "h2"},
+	}
+
+	md, err := reread.ExportStoryMarkdown(hyperlinkStory, opts)
+	if err != nil {
+		t.Fatalf("ExportStoryMarkdown failed: %v", err)
+	}
+	if want := "## ANNA ipsum [dolor sit amet](https://example.com/a?b=1&c=2), consectetur"; !strings.HasPrefix(md, want) {
+		t.Errorf("ExportStoryMarkdown() = %q, want prefix %q", md, want)
+	}
+
+	html, err := reread.ExportStoryHTML(hyperlinkStory, opts)
+	if err != nil {
+		t.Fatalf("ExportStoryHTML failed: %v", err)
+	}
+	if want := `<h2>ANNA ipsum <a href="https://example.com/a?b=1&amp;c=2">dolor sit amet</a>, consectetur`; !strings.HasPrefix(html, want) {
+		t.Errorf("ExportStoryHTML() = %q, want prefix %q", html, want)
+	}
+	if opts.Links != nil {
+		t.Error("ExportStoryMarkdown changed the caller's options")
+	}
+
+	if _, err := reread.ExportStoryHTML("Stories/Story_u999.xml", opts); err == nil {
+		t.Error("expected error for a missing story")
+	}
+}
@@ -0,0 +1,148 @@
+package idml
+
+import (
+	"strings"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/resources"
+	"github.com/dimelords/idmllib/v2/pkg/story"
+)
+
+// ImportStoryMarkdown replaces the text of a story with Markdown, built by
+// story.NewStoryFromMarkdown, and stores it with UpdateStory. The story keeps
+// its Self and settings; only its paragraph style ranges are replaced.
+//
+// Style values in opts may be style references or style names, which are
+// looked up in the package's styles: "Headline" and "Body:Intro" resolve to
+// the paragraph style with that name or Self ("ParagraphStyle/Body%3aIntro").
+// Styles that do not exist are referenced by the Self they would have and
+// handled by vopts: FailOnMissing returns an error counting the missing
+// resources and leaves the story unchanged, AutoAddMissing adds default
+// styles.
+//
+// Hyperlinks whose sources were in the replaced text are left without a
+// source; see ValidateHyperlinks and RemoveHyperlink.
+//
+// Example:
+//
+//	err := pkg.ImportStoryMarkdown("Stories/Story_u1d8.xml", md, story.MarkupImportOptions{
+//	    ParagraphStyles: map[string]string{"h1": "Headline", "p": "Body"},
+//	    CharacterStyles: map[string]string{"strong": "Bold", "em": "Italic"},
+//	}, idml.FullValidation)
+func (p *Package) ImportStoryMarkdown(storyFile, src string, opts story.MarkupImportOptions, vopts ValidationOptions) error {
+	opts, err := p.markupImportOptions("import story markdown", storyFile, opts)
+	if err != nil {
+		return err
+	}
+	return p.importStory(storyFile, story.NewStoryFromMarkdown(src, opts), vopts)
+}
+
+// ImportStoryHTML replaces the text of a story with HTML, built by
+// story.NewStoryFromHTML, as in ImportStoryMarkdown.
+func (p *Package) ImportStoryHTML(storyFile, src string, opts story.MarkupImportOptions, vopts ValidationOptions) error {
+	opts, err := p.markupImportOptions("import story HTML", storyFile, opts)
+	if err != nil {
+		return err
+	}
+	imported, err := story.NewStoryFromHTML(src, opts)
+	if err != nil {
+		return common.WrapErrorWithPath("idml", "import story HTML", storyFile, err)
+	}
+	return p.importStory(storyFile, imported, vopts)
+}
+
+// importStory replaces the paragraph style ranges of a story with those of
+// an imported story.
+func (p *Package) importStory(storyFile string, imported *story.Story, vopts ValidationOptions) error {
+	existing, err := p.Story(storyFile)
+	if err != nil {
+		return common.WrapErrorWithPath("idml", "import story", storyFile, err)
+	}
+	updated := *existing
+	updated.StoryElement.ParagraphStyleRanges = imported.StoryElement.ParagraphStyleRanges
+	return p.UpdateStory(storyFile, &updated, vopts)
+}
+
+// markupImportOptions returns a copy of opts with style names resolved to
+// the style references of the package.
+func (p *Package) markupImportOptions(op, storyFile string, opts story.MarkupImportOptions) (story.MarkupImportOptions, error) {
+	styles, err := p.Styles()
+	if err != nil {
+		return opts, common.WrapErrorWithPath("idml", op, storyFile, err)
+	}
+
+	paragraphStyles := make(map[string]string, len(opts.ParagraphStyles))
+	for tag, name := range opts.ParagraphStyles {
+		paragraphStyles[tag] = resolveStyleRef("ParagraphStyle/", name, func(ref string) bool {
+			return styles.FindParagraphStyle(ref) != nil
+		}, func() string {
+			return findParagraphStyleByName(styles.RootParagraphStyleGroup, name)
+		})
+	}
+	characterStyles := make(map[string]string, len(opts.CharacterStyles))
+	for tag, name := range opts.CharacterStyles {
+		characterStyles[tag] = resolveStyleRef("CharacterStyle/", name, func(ref string) bool {
+			return styles.FindCharacterStyle(ref) != nil
+		}, func() string {
+			return findCharacterStyleByName(styles.RootCharacterStyleGroup, name)
+		})
+	}
+	opts.ParagraphStyles = paragraphStyles
+	opts.CharacterStyles = characterStyles
+	return opts, nil
+}
+
+// resolveStyleRef returns the reference for a style value: references are
+// kept, names resolve to the style with that Self or, failing that, the
+// style found by byName. Unknown names get the Self they would have.
+func resolveStyleRef(prefix, value string, exists func(ref string) bool, byName func() string) string {
+	if value == "" || strings.HasPrefix(value, prefix) {
+		return value
+	}
+	ref := prefix + strings.ReplaceAll(value, ":", "%3a")
+	if exists(ref) {
+		return ref
+	}
+	if self := byName(); self != "" {
+		return self
+	}
+	return ref
+}
+
+// findParagraphStyleByName returns the Self of the first paragraph style in
+// group or its nested groups with the given name, or "" if there is none.
+func findParagraphStyleByName(group *resources.ParagraphStyleGroup, name string) string {
+	if group == nil {
+		return ""
+	}
+	for _, style := range group.ParagraphStyles {
+		if style.Name == name {
+			return style.Self
+		}
+	}
+	for i := range group.NestedGroups {
+		if self := findParagraphStyleByName(&group.NestedGroups[i], name); self != "" {
+			return self
+		}
+	}
+	return ""
+}
+
+// findCharacterStyleByName returns the Self of the first character style in
+// group or its nested groups with the given name, or "" if there is none.
+func findCharacterStyleByName(group *resources.CharacterStyleGroup, name string) string {
+	if group == nil {
+		return ""
+	}
+	for _, style := range group.CharacterStyles {
+		if style.Name == name {
+			return style.Self
+		}
+	}
+	for i := range group.NestedGroups {
+		if self := findCharacterStyleByName(&group.NestedGroups[i], name); self != "" {
+			return self
+		}
+	}
+	return ""
+}
@@ -0,0 +1,118 @@
+package idml
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dimelords/idmllib/v2/pkg/story"
+)
+
+// TestImportStoryMarkdown tests replacing a story with Markdown, with style
+// names resolved and missing styles handled by the validation options.
+func TestImportStoryMarkdown(t *testing.T) {
+	pkg := loadExampleIDML(t)
+	before, err := pkg.Story(hyperlinkStory)
+	if err != nil {
+		t.Fatalf("Story failed: %v", err)
+	}
+	self, text := before.StoryElement.Self, before.ExtractText()
+
+	md := "# Budget\n\nThe **council** met on *Monday*.[^1]\n\n[^1]: Minutes, page 4.\n"
+	opts := story.MarkupImportOptions{
+		ParagraphStyles: map[string]string{
+			"h1":       "NOT tittel",
+			"p":        "Naviga:Standard:headline-TIT B",
+			"footnote": "ParagraphStyle/Infotekst",
+		},
+		CharacterStyles: map[string]string{
+			"strong": "Semibold i autotekst",
+			"em":     "Copy Desk Italic",
+		},
+	}
+
+	// The italic style does not exist
+	if err := pkg.ImportStoryMarkdown(hyperlinkStory, md, opts, FullValidation); err == nil || !strings.Contains(err.Error(), "1 character styles") {
+		t.Fatalf("ImportStoryMarkdown with a missing style error = %v", err)
+	}
+	if st, _ := pkg.Story(hyperlinkStory); st.ExtractText() != text {
+		t.Fatal("failed import changed the story")
+	}
+
+	if err := pkg.ImportStoryMarkdown(hyperlinkStory, md, opts, AutoResolve); err != nil {
+		t.Fatalf("ImportStoryMarkdown failed: %v", err)
+	}
+
+	reread, err := Read(writeTestIDML(t, pkg, "markdown.idml"))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	st, err := reread.Story(hyperlinkStory)
+	if err != nil {
+		t.Fatalf("Story failed: %v", err)
+	}
+	if st.StoryElement.Self != self {
+		t.Errorf("story Self = %q, want %q", st.StoryElement.Self, self)
+	}
+	if got, want := st.ExtractText(), "Budget\nThe council met on Monday."; got != want {
+		t.Errorf("ExtractText() = %q, want %q", got, want)
+	}
+
+	ranges := st.StoryElement.ParagraphStyleRanges
+	if len(ranges) != 2 || ranges[0].AppliedParagraphStyle != "ParagraphStyle/NOT tittel" ||
+		ranges[1].AppliedParagraphStyle != "ParagraphStyle/Naviga%3aStandard%3aheadline-TIT B" {
+		t.Fatalf("paragraph styles were not resolved: %+v", ranges)
+	}
+	var characterStyles []string
+	for _, csr := range ranges[1].CharacterStyleRanges {
+		characterStyles = append(characterStyles, csr.AppliedCharacterStyle)
+	}
+	if want := "CharacterStyle/Semibold i autotekst"; len(characterStyles) < 2 || characterStyles[1] != want {
+		t.Errorf("character styles = %v, want %q second", characterStyles, want)
+	}
+	if notes := st.Footnotes(); len(notes) != 1 || notes[0].Text() != "Minutes, page 4." {
+		t.Errorf("Footnotes() = %+v, want one footnote", notes)
+	}
+
+	styles, err := reread.Styles()
+	if err != nil {
+		t.Fatalf("Styles failed: %v", err)
+	}
+	if styles.FindCharacterStyle("CharacterStyle/Copy Desk Italic") == nil {
+		t.Error("missing character style was not added")
+	}
+}
+
+// TestImportStoryHTML tests replacing a story with HTML and the errors of
+// the import functions.
+func TestImportStoryHTML(t *testing.T) {
+	pkg := loadExampleIDML(t)
+	opts := story.MarkupImportOptions{
+		ParagraphStyles: map[string]string{"h2": "NOT tittel", "p": "Infotekst"},
+		CharacterStyles: map[string]string{"strong": "CharacterStyle/Semibold i autotekst"},
+	}
+
+	src := "<h2>Results</h2>\n<p>Home <strong>2</strong>, away 1.</p>"
+	if err := pkg.ImportStoryHTML(hyperlinkStory, src, opts, FullValidation); err != nil {
+		t.Fatalf("ImportStoryHTML failed: %v", err)
+	}
+	html, err := pkg.ExportStoryHTML(hyperlinkStory, story.MarkupOptions{
+		ParagraphTags: map[string]string{"NOT tittel": "h2"},
+		CharacterTags: map[string]string{"Semibold i autotekst": "strong"},
+	})
+	if err != nil {
+		t.Fatalf("ExportStoryHTML failed: %v", err)
+	}
+	if want := src + "\n"; html != want {
+		t.Errorf("ExportStoryHTML() = %q, want %q", html, want)
+	}
+
+	if err := pkg.ImportStoryHTML("Stories/Story_u999.xml", src, opts, NoValidation); err == nil {
+		t.Error("expected error importing into a missing story")
+	}
+	if err := pkg.ImportStoryMarkdown("Stories/Story_u999.xml", "text", opts, NoValidation); err == nil {
+		t.Error("expected error importing into a missing story")
+	}
+	if err := pkg.ImportStoryHTML(hyperlinkStory, "<p><b>x</i></p>", opts, NoValidation); err == nil {
+		t.Error("expected error for invalid HTML")
+	}
+}
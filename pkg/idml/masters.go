@@ -0,0 +1,294 @@
+package idml
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/spread"
+)
+
+// EffectivePageItem is a page item that appears on a page, either placed on
+// the page itself or inherited from its applied master.
+type EffectivePageItem struct {
+	// Item points at the cached page item, so changes are visible to later reads.
+	Item PageItem
+
+	// SourceFilename is the spread or master spread file holding the item.
+	SourceFilename string
+
+	// MasterID is the Self ID of the master spread the item is inherited from.
+	// It is empty for items placed on the page itself.
+	MasterID string
+}
+
+// Inherited reports whether the item comes from a master spread.
+func (e EffectivePageItem) Inherited() bool {
+	return e.MasterID != ""
+}
+
+// EffectiveItemsForPage returns the page items that appear on a page:
+// the items inherited from its master spread followed by its own items.
+//
+// This operation:
+//  1. Finds the page in the document spreads (or in the master spreads)
+//  2. Collects the items of the applied master page, recursing into masters
+//     that are themselves based on another master
+//  3. Drops master items listed in the page's OverrideList, since the page holds
+//     its own overridden copy of them
+//  4. Appends the items placed on the page itself
+//
+// Master items are skipped entirely when the spread containing the page has
+// ShowMasterItems="false". On a master page the same attribute of the master
+// spread controls the items of its parent master.
+//
+// An item belongs to a page when the center of its bounds (in spread
+// coordinates) lies within the page; items on the pasteboard belong to no page.
+// When a master has a different page count than the spread, left pages use the
+// first master page and right pages the last one.
+//
+// Returns common.ErrNotFound if the page or its applied master does not exist.
+//
+// Example:
+//
+//	items, err := pkg.EffectiveItemsForPage("u217")
+//	for _, e := range items {
+//	    fmt.Println(e.Item.GetSelf(), e.Inherited())
+//	}
+func (p *Package) EffectiveItemsForPage(pageID string) ([]EffectivePageItem, error) {
+	const op = "effective items for page"
+
+	containers, masters, err := p.pageContainers()
+	if err != nil {
+		return nil, common.WrapError("idml", op, err)
+	}
+
+	for _, c := range containers {
+		for i := range c.pages {
+			if c.pages[i].Self != pageID {
+				continue
+			}
+
+			var items []EffectivePageItem
+			if showsMasterItems(c.showMasterItems) {
+				overrides := make(map[string]bool)
+				visited := make(map[string]bool)
+				if c.masterID != "" {
+					visited[c.masterID] = true
+				}
+				items, err = masterItemsForPage(masters, c, i, overrides, visited)
+				if err != nil {
+					return nil, common.WrapErrorWithPath("idml", op, c.filename, err)
+				}
+			}
+
+			own, err := c.itemsOnPage(i)
+			if err != nil {
+				return nil, common.WrapErrorWithPath("idml", op, c.filename, err)
+			}
+			for _, item := range own {
+				items = append(items, EffectivePageItem{Item: item, SourceFilename: c.filename})
+			}
+			return items, nil
+		}
+	}
+
+	return nil, common.WrapError("idml", op, fmt.Errorf("page %q: %w", pageID, common.ErrNotFound))
+}
+
+// pageContainer is a spread or master spread seen as pages plus page items.
+type pageContainer struct {
+	filename        string
+	masterID        string // Self of the master spread; empty for document spreads
+	showMasterItems string
+	pages           []spread.Page
+	items           []PageItem
+}
+
+// pageContainers returns the document spreads in designmap order followed by
+// the master spreads, plus the master spreads keyed by their Self ID.
+func (p *Package) pageContainers() ([]*pageContainer, map[string]*pageContainer, error) {
+	var containers []*pageContainer
+
+	spreadFiles, err := p.orderedSpreadFilenames()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, filename := range spreadFiles {
+		sp, err := p.Spread(filename)
+		if err != nil {
+			return nil, nil, err
+		}
+		s := &sp.InnerSpread
+		containers = append(containers, &pageContainer{
+			filename:        filename,
+			showMasterItems: s.ShowMasterItems,
+			pages:           s.Pages,
+			items:           collectPageItems(s.TextFrames, s.Rectangles, s.Ovals, s.Polygons, s.GraphicLines, s.Groups),
+		})
+	}
+
+	masterSpreads, err := p.MasterSpreads()
+	if err != nil {
+		return nil, nil, err
+	}
+	masterFiles := make([]string, 0, len(masterSpreads))
+	for filename := range masterSpreads {
+		masterFiles = append(masterFiles, filename)
+	}
+	sort.Strings(masterFiles)
+
+	masters := make(map[string]*pageContainer, len(masterFiles))
+	for _, filename := range masterFiles {
+		m := &masterSpreads[filename].InnerMasterSpread
+		c := &pageContainer{
+			filename:        filename,
+			masterID:        m.Self,
+			showMasterItems: m.ShowMasterItems,
+			pages:           m.Pages,
+			items:           collectPageItems(m.TextFrames, m.Rectangles, m.Ovals, m.Polygons, m.GraphicLines, m.Groups),
+		}
+		containers = append(containers, c)
+		masters[m.Self] = c
+	}
+
+	return containers, masters, nil
+}
+
+// masterItemsForPage returns the items page i of c inherits from its applied
+// master, excluding overridden items. overrides and visited are shared across
+// the recursion into parent masters.
+func masterItemsForPage(masters map[string]*pageContainer, c *pageContainer, i int, overrides, visited map[string]bool) ([]EffectivePageItem, error) {
+	page := &c.pages[i]
+	for _, id := range strings.Fields(page.OverrideList) {
+		overrides[id] = true
+	}
+
+	// "n" means no master is applied
+	if page.AppliedMaster == "" || page.AppliedMaster == "n" {
+		return nil, nil
+	}
+	master, ok := masters[page.AppliedMaster]
+	if !ok {
+		return nil, fmt.Errorf("master spread %q applied to page %q: %w", page.AppliedMaster, page.Self, common.ErrNotFound)
+	}
+	if visited[master.masterID] {
+		return nil, fmt.Errorf("master spread %q is applied to itself through page %q", master.masterID, page.Self)
+	}
+	visited[master.masterID] = true
+
+	mi := masterPageIndex(c, i, master)
+	if mi < 0 {
+		return nil, nil
+	}
+
+	var items []EffectivePageItem
+	if showsMasterItems(master.showMasterItems) {
+		parent, err := masterItemsForPage(masters, master, mi, overrides, visited)
+		if err != nil {
+			return nil, err
+		}
+		items = parent
+	}
+
+	own, err := master.itemsOnPage(mi)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range own {
+		if overrides[item.GetSelf()] {
+			continue
+		}
+		items = append(items, EffectivePageItem{Item: item, SourceFilename: master.filename, MasterID: master.masterID})
+	}
+	return items, nil
+}
+
+// masterPageIndex picks the master page applied to page i of c.
+// Returns -1 if the master has no pages.
+func masterPageIndex(c *pageContainer, i int, master *pageContainer) int {
+	n := len(master.pages)
+	switch {
+	case n == 0:
+		return -1
+	case n == 1:
+		return 0
+	case len(c.pages) == n:
+		return i
+	}
+
+	// Different page counts: match by side of the spread
+	rect, err := c.pages[i].SpreadRect()
+	if err != nil {
+		return min(i, n-1)
+	}
+	if rect.Center().X < 0 {
+		return 0
+	}
+	return n - 1
+}
+
+// itemsOnPage returns the items of the container whose center lies on page i.
+func (c *pageContainer) itemsOnPage(i int) ([]PageItem, error) {
+	rect, err := c.pages[i].SpreadRect()
+	if err != nil {
+		return nil, fmt.Errorf("page %q: %w", c.pages[i].Self, err)
+	}
+
+	var items []PageItem
+	for _, item := range c.items {
+		if center, ok := pageItemCenter(item); ok && rect.Contains(center) {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// collectPageItems returns pointers to the given items in type order.
+func collectPageItems(textFrames []spread.SpreadTextFrame, rectangles []spread.Rectangle, ovals []spread.Oval,
+	polygons []spread.Polygon, lines []spread.GraphicLine, groups []spread.Group) []PageItem {
+	var items []PageItem
+	for i := range textFrames {
+		items = append(items, &textFrames[i])
+	}
+	for i := range rectangles {
+		items = append(items, &rectangles[i])
+	}
+	for i := range ovals {
+		items = append(items, &ovals[i])
+	}
+	for i := range polygons {
+		items = append(items, &polygons[i])
+	}
+	for i := range lines {
+		items = append(items, &lines[i])
+	}
+	for i := range groups {
+		items = append(items, &groups[i])
+	}
+	return items
+}
+
+// pageItemCenter returns the center of an item in spread coordinates.
+// Groups do not carry their own bounds, so their transform origin is used.
+func pageItemCenter(item PageItem) (spread.Position, bool) {
+	switch v := item.(type) {
+	case interface{ SpreadRect() (spread.Rect, error) }:
+		rect, err := v.SpreadRect()
+		if err != nil {
+			return spread.Position{}, false
+		}
+		return rect.Center(), true
+	case *spread.Group:
+		pos, err := v.Position()
+		return pos, err == nil
+	}
+	return spread.Position{}, false
+}
+
+// showsMasterItems reports whether a ShowMasterItems attribute enables master items.
+// InDesign omits the attribute when it has its default value of true.
+func showsMasterItems(value string) bool {
+	return !strings.EqualFold(value, "false")
+}
@@ -0,0 +1,145 @@
+package idml
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/spread"
+)
+
+// applyMaster sets AppliedMaster on both pages of the example spread.
+func applyMaster(t *testing.T, pkg *Package, masterID string) *spread.Spread {
+	t.Helper()
+
+	sp, err := pkg.Spread("Spreads/Spread_u210.xml")
+	if err != nil {
+		t.Fatalf("Spread failed: %v", err)
+	}
+	for i := range sp.InnerSpread.Pages {
+		sp.InnerSpread.Pages[i].AppliedMaster = masterID
+	}
+	return sp
+}
+
+// inheritedIDs returns the Self IDs of inherited items.
+func inheritedIDs(items []EffectivePageItem) []string {
+	var ids []string
+	for _, e := range items {
+		if e.Inherited() {
+			ids = append(ids, e.Item.GetSelf())
+		}
+	}
+	return ids
+}
+
+// TestMasterSpreads tests loading the typed master spreads of example.idml.
+func TestMasterSpreads(t *testing.T) {
+	pkg := loadExampleIDML(t)
+
+	masters, err := pkg.MasterSpreads()
+	if err != nil {
+		t.Fatalf("MasterSpreads failed: %v", err)
+	}
+	if len(masters) != 3 {
+		t.Errorf("got %d master spreads, want 3", len(masters))
+	}
+
+	ms, ok := masters[MasterSpreadPath("u1ca")]
+	if !ok {
+		t.Fatal("master spread u1ca not found")
+	}
+	if ms.InnerMasterSpread.Name != "A-pageNumbers" || len(ms.Pages()) != 2 {
+		t.Errorf("u1ca: name %q, %d pages", ms.InnerMasterSpread.Name, len(ms.Pages()))
+	}
+	if pkg.getCacheStats().MasterSpreadsCached != 3 {
+		t.Error("master spreads should be cached")
+	}
+}
+
+// TestEffectiveItemsForPage tests merging master items with the page's own items.
+func TestEffectiveItemsForPage(t *testing.T) {
+	pkg := loadExampleIDML(t)
+	applyMaster(t, pkg, "u1ca")
+
+	left, err := pkg.EffectiveItemsForPage("u217")
+	if err != nil {
+		t.Fatalf("EffectiveItemsForPage failed: %v", err)
+	}
+	if got := inheritedIDs(left); !equalIDs(got, []string{"u1ea"}) {
+		t.Errorf("left page inherits %v, want [u1ea]", got)
+	}
+	if left[0].MasterID != "u1ca" || left[0].SourceFilename != MasterSpreadPath("u1ca") {
+		t.Errorf("inherited item source = %q/%q", left[0].MasterID, left[0].SourceFilename)
+	}
+	if len(left) < 2 {
+		t.Errorf("left page should also have its own items, got %d", len(left))
+	}
+
+	right, err := pkg.EffectiveItemsForPage("u218")
+	if err != nil {
+		t.Fatalf("EffectiveItemsForPage failed: %v", err)
+	}
+	if got := inheritedIDs(right); !equalIDs(got, []string{"u200"}) {
+		t.Errorf("right page inherits %v, want [u200]", got)
+	}
+
+	// Own items of the two pages must not overlap
+	seen := make(map[string]bool)
+	for _, e := range append(left, right...) {
+		if !e.Inherited() {
+			if seen[e.Item.GetSelf()] {
+				t.Errorf("item %s assigned to both pages", e.Item.GetSelf())
+			}
+			seen[e.Item.GetSelf()] = true
+		}
+	}
+
+	// Master pages report their own items
+	master, err := pkg.EffectiveItemsForPage("u1d1")
+	if err != nil {
+		t.Fatalf("EffectiveItemsForPage on master page failed: %v", err)
+	}
+	if len(master) == 0 || master[0].Item.GetSelf() != "u1ea" || master[0].Inherited() {
+		t.Errorf("master page items = %+v", master)
+	}
+}
+
+// TestEffectiveItemsForPage_Overrides tests OverrideList and ShowMasterItems.
+func TestEffectiveItemsForPage_Overrides(t *testing.T) {
+	pkg := loadExampleIDML(t)
+	sp := applyMaster(t, pkg, "u1ca")
+
+	sp.InnerSpread.Pages[0].OverrideList = "u1ea"
+	items, err := pkg.EffectiveItemsForPage("u217")
+	if err != nil {
+		t.Fatalf("EffectiveItemsForPage failed: %v", err)
+	}
+	if got := inheritedIDs(items); len(got) != 0 {
+		t.Errorf("overridden item still inherited: %v", got)
+	}
+
+	sp.InnerSpread.Pages[0].OverrideList = ""
+	sp.InnerSpread.ShowMasterItems = "false"
+	items, err = pkg.EffectiveItemsForPage("u218")
+	if err != nil {
+		t.Fatalf("EffectiveItemsForPage failed: %v", err)
+	}
+	if got := inheritedIDs(items); len(got) != 0 {
+		t.Errorf("ShowMasterItems=false still inherits %v", got)
+	}
+}
+
+// TestEffectiveItemsForPage_NotFound tests unknown pages and masters.
+func TestEffectiveItemsForPage_NotFound(t *testing.T) {
+	pkg := loadExampleIDML(t)
+
+	if _, err := pkg.EffectiveItemsForPage("missing"); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown page, got %v", err)
+	}
+
+	applyMaster(t, pkg, "uMissing")
+	if _, err := pkg.EffectiveItemsForPage("u217"); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown master, got %v", err)
+	}
+}
@@ -0,0 +1,563 @@
+package idml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"sort"
+	"strings"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/resources"
+	"github.com/dimelords/idmllib/v2/pkg/spread"
+	"github.com/dimelords/idmllib/v2/pkg/story"
+)
+
+// Page item attributes set by each object style category, by the Enable*
+// flag of the category.
+var objectStyleCategoryAttrs = []struct {
+	flag  string
+	attrs []string
+}{
+	{"EnableFill", []string{"FillColor", "FillTint", "GradientFillAngle", "OverprintFill"}},
+	{"EnableStroke", []string{
+		"StrokeWeight", "StrokeType", "StrokeColor", "StrokeTint", "GapColor", "GapTint",
+		"GradientStrokeAngle", "OverprintStroke", "OverprintGap",
+	}},
+	{"EnableStrokeAndCornerOptions", []string{
+		"StrokeAlignment", "EndCap", "EndJoin", "MiterLimit",
+		"LeftLineEnd", "RightLineEnd", "LeftArrowHeadScale", "RightArrowHeadScale", "ArrowHeadAlignment",
+		"CornerOption", "CornerRadius",
+		"TopLeftCornerOption", "TopLeftCornerRadius", "TopRightCornerOption", "TopRightCornerRadius",
+		"BottomLeftCornerOption", "BottomLeftCornerRadius", "BottomRightCornerOption", "BottomRightCornerRadius",
+	}},
+	{"EnableTextWrapAndOthers", []string{"Nonprinting"}},
+}
+
+// ApplyObjectStyle applies an object style to the page item with the given
+// Self. The item may be on a spread or master spread, inside a group there
+// (including nested groups), or anchored in a story.
+//
+// The settings of every category the style enables (EnableFill, EnableStroke,
+// EnableStrokeAndCornerOptions, EnableTextWrapAndOthers,
+// EnableFrameFittingOptions, EnableAnchoredObjectOptions and the text frame
+// options) are replaced by the style's settings. Without clearOverrides the
+// settings listed in the item's OverriddenPageItemProps are kept, as when a
+// style is applied in InDesign; with clearOverrides they are replaced too
+// and OverriddenPageItemProps is cleared. Categories the style does not
+// enable are left alone. The
+// paragraph style and story options of a style are not applied to text.
+//
+// Returns common.ErrNotFound if the style or the item does not exist.
+//
+// Example:
+//
+//	err := pkg.ApplyObjectStyle("u1e3", "ObjectStyle/Caption Frame", true)
+func (p *Package) ApplyObjectStyle(itemID, styleID string, clearOverrides bool) error {
+	const op = "apply object style"
+
+	styles, err := p.Styles()
+	if err != nil {
+		return common.WrapErrorWithPath("idml", op, PathStyles, err)
+	}
+	resolved, err := styles.ResolveObjectStyle(styleID)
+	if err != nil {
+		return common.WrapError("idml", op, err)
+	}
+	apply := func(target *objectStyleTarget) error {
+		*target.appliedStyle = styleID
+		var keep map[string]bool
+		if !clearOverrides && target.overridden != nil {
+			keep = overriddenProps(*target.overridden)
+		}
+		if err := target.applySettings(styles, resolved, keep); err != nil {
+			return err
+		}
+		if clearOverrides && target.overridden != nil {
+			*target.overridden = ""
+		}
+		if target.save != nil {
+			return target.save()
+		}
+		return nil
+	}
+
+	spreads, err := p.Spreads()
+	if err != nil {
+		return common.WrapError("idml", op, err)
+	}
+	for _, filename := range sortedKeys(spreads) {
+		sp := spreads[filename]
+		inner := &sp.InnerSpread
+		target, err := findPageItemTarget(itemID, inner.TextFrames, inner.Rectangles, inner.Ovals, inner.Polygons, inner.GraphicLines, inner.Groups)
+		if err != nil {
+			return common.WrapErrorWithPath("idml", op, filename, err)
+		}
+		if target == nil {
+			continue
+		}
+		if err := apply(target); err != nil {
+			return common.WrapErrorWithPath("idml", op, filename, err)
+		}
+		return p.marshalAndUpdateSpread(filename, sp)
+	}
+
+	masters, err := p.MasterSpreads()
+	if err != nil {
+		return common.WrapError("idml", op, err)
+	}
+	for _, filename := range sortedKeys(masters) {
+		ms := masters[filename]
+		inner := &ms.InnerMasterSpread
+		target, err := findPageItemTarget(itemID, inner.TextFrames, inner.Rectangles, inner.Ovals, inner.Polygons, inner.GraphicLines, inner.Groups)
+		if err != nil {
+			return common.WrapErrorWithPath("idml", op, filename, err)
+		}
+		if target == nil {
+			continue
+		}
+		if err := apply(target); err != nil {
+			return common.WrapErrorWithPath("idml", op, filename, err)
+		}
+		data, err := spread.MarshalMasterSpread(ms)
+		if err != nil {
+			return common.WrapErrorWithPath("idml", op, filename, err)
+		}
+		p.setFileData(filename, data)
+		p.cacheMasterSpread(filename, ms)
+		return nil
+	}
+
+	stories, err := p.Stories()
+	if err != nil {
+		return common.WrapError("idml", op, err)
+	}
+	for _, filename := range sortedKeys(stories) {
+		st := stories[filename]
+		for _, obj := range st.AnchoredObjects() {
+			if obj.Self() != itemID {
+				continue
+			}
+			if err := apply(anchoredObjectTarget(obj)); err != nil {
+				return common.WrapErrorWithPath("idml", op, filename, err)
+			}
+			return p.marshalAndUpdateStory(filename, st)
+		}
+	}
+
+	return common.WrapErrorWithPath("idml", op, itemID, common.ErrNotFound)
+}
+
+// objectStyleTarget gives uniform access to the settings of the different
+// page item types for ApplyObjectStyle.
+type objectStyleTarget struct {
+	appliedStyle *string
+	overridden   *string            // OverriddenPageItemProps, nil for groups
+	fields       map[string]*string // attributes with struct fields
+	otherAttrs   *[]xml.Attr
+	elements     *[]common.RawXMLElement // settings elements without struct fields
+
+	textWrap     **spread.TextWrapPreference   // nil if kept in elements
+	frameFitting **spread.FrameFittingOption   // nil if kept in elements
+	anchor       **story.AnchoredObjectSetting // nil unless anchored in a story
+	textFrame    bool                          // takes TextFramePreference and BaselineFrameGridOption
+	graphicFrame bool                          // takes FrameFittingOption
+
+	save func() error // writes a grouped item back into its group, nil otherwise
+}
+
+// findPageItemTarget returns the target for the page item with the given
+// Self among the items of a spread or master spread and the items inside
+// their groups, or nil.
+func findPageItemTarget(itemID string, textFrames []spread.SpreadTextFrame, rects []spread.Rectangle, ovals []spread.Oval,
+	polygons []spread.Polygon, lines []spread.GraphicLine, groups []spread.Group) (*objectStyleTarget, error) {
+	for i := range textFrames {
+		if textFrames[i].Self == itemID {
+			return textFrameTarget(&textFrames[i]), nil
+		}
+	}
+	for i := range rects {
+		if rects[i].Self == itemID {
+			return rectangleTarget(&rects[i]), nil
+		}
+	}
+	for i := range ovals {
+		if ovals[i].Self == itemID {
+			return ovalTarget(&ovals[i]), nil
+		}
+	}
+	for i := range polygons {
+		if polygons[i].Self == itemID {
+			return polygonTarget(&polygons[i]), nil
+		}
+	}
+	for i := range lines {
+		if lines[i].Self == itemID {
+			return graphicLineTarget(&lines[i]), nil
+		}
+	}
+	for i := range groups {
+		if groups[i].Self == itemID {
+			return groupTarget(&groups[i]), nil
+		}
+	}
+
+	// Groups keep their page items as raw XML
+	var target *objectStyleTarget
+	err := visitGroupedItems(groups, func(elem *common.RawXMLElement, save func() error) (bool, error) {
+		if attrValue(elem.Attrs, "Self") != itemID {
+			return false, nil
+		}
+		var item interface{}
+		switch elem.XMLName.Local {
+		case "TextFrame":
+			tf := &spread.SpreadTextFrame{}
+			item, target = tf, textFrameTarget(tf)
+		case "Rectangle":
+			r := &spread.Rectangle{}
+			item, target = r, rectangleTarget(r)
+		case "Oval":
+			o := &spread.Oval{}
+			item, target = o, ovalTarget(o)
+		case "Polygon":
+			pg := &spread.Polygon{}
+			item, target = pg, polygonTarget(pg)
+		case "GraphicLine":
+			l := &spread.GraphicLine{}
+			item, target = l, graphicLineTarget(l)
+		case "Group":
+			g := &spread.Group{}
+			item, target = g, groupTarget(g)
+		}
+		var err error
+		target.save, err = decodeGroupedItem(elem, save, item)
+		return true, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return target, nil
+}
+
+// anchoredObjectTarget returns the target for a page item anchored in a story.
+func anchoredObjectTarget(obj *story.AnchoredObject) *objectStyleTarget {
+	var target *objectStyleTarget
+	var anchoring *story.Anchoring
+	switch {
+	case obj.TextFrame != nil:
+		target, anchoring = textFrameTarget(&obj.TextFrame.SpreadTextFrame), &obj.TextFrame.Anchoring
+	case obj.Rectangle != nil:
+		target, anchoring = rectangleTarget(&obj.Rectangle.Rectangle), &obj.Rectangle.Anchoring
+	case obj.Oval != nil:
+		target, anchoring = ovalTarget(&obj.Oval.Oval), &obj.Oval.Anchoring
+	case obj.Polygon != nil:
+		target, anchoring = polygonTarget(&obj.Polygon.Polygon), &obj.Polygon.Anchoring
+	case obj.GraphicLine != nil:
+		target, anchoring = graphicLineTarget(&obj.GraphicLine.GraphicLine), &obj.GraphicLine.Anchoring
+	case obj.Group != nil:
+		target, anchoring = groupTarget(&obj.Group.Group), &obj.Group.Anchoring
+	}
+	target.anchor = &anchoring.AnchoredObjectSetting
+	return target
+}
+
+func textFrameTarget(tf *spread.SpreadTextFrame) *objectStyleTarget {
+	return &objectStyleTarget{
+		appliedStyle: &tf.AppliedObjectStyle,
+		overridden:   &tf.OverriddenPageItemProps,
+		fields: map[string]*string{
+			"FillColor": &tf.FillColor, "FillTint": &tf.FillTint,
+			"StrokeWeight": &tf.StrokeWeight, "StrokeType": &tf.StrokeType,
+			"StrokeColor": &tf.StrokeColor, "StrokeTint": &tf.StrokeTint,
+			"GradientFillAngle": &tf.GradientFillAngle, "GradientStrokeAngle": &tf.GradientStrokeAngle,
+		},
+		otherAttrs: &tf.OtherAttrs,
+		elements:   &tf.OtherElements,
+		textFrame:  true,
+	}
+}
+
+func rectangleTarget(r *spread.Rectangle) *objectStyleTarget {
+	return &objectStyleTarget{
+		appliedStyle: &r.AppliedObjectStyle,
+		overridden:   &r.OverriddenPageItemProps,
+		fields: map[string]*string{
+			"FillColor": &r.FillColor, "FillTint": &r.FillTint,
+			"StrokeWeight": &r.StrokeWeight, "StrokeType": &r.StrokeType,
+			"StrokeColor": &r.StrokeColor, "StrokeTint": &r.StrokeTint,
+			"GradientFillAngle": &r.GradientFillAngle, "GradientStrokeAngle": &r.GradientStrokeAngle,
+		},
+		otherAttrs:   &r.OtherAttrs,
+		elements:     &r.OtherElements,
+		textWrap:     &r.TextWrapPreference,
+		frameFitting: &r.FrameFittingOption,
+		graphicFrame: true,
+	}
+}
+
+func ovalTarget(o *spread.Oval) *objectStyleTarget {
+	return &objectStyleTarget{
+		appliedStyle: &o.AppliedObjectStyle,
+		overridden:   &o.OverriddenPageItemProps,
+		fields: map[string]*string{
+			"FillColor": &o.FillColor, "FillTint": &o.FillTint,
+			"StrokeWeight": &o.StrokeWeight, "StrokeType": &o.StrokeType,
+			"StrokeColor": &o.StrokeColor, "StrokeTint": &o.StrokeTint,
+		},
+		otherAttrs:   &o.OtherAttrs,
+		elements:     &o.OtherElements,
+		textWrap:     &o.TextWrapPreference,
+		graphicFrame: true,
+	}
+}
+
+func polygonTarget(pg *spread.Polygon) *objectStyleTarget {
+	return &objectStyleTarget{
+		appliedStyle: &pg.AppliedObjectStyle,
+		overridden:   &pg.OverriddenPageItemProps,
+		fields: map[string]*string{
+			"FillColor": &pg.FillColor, "FillTint": &pg.FillTint,
+			"StrokeWeight": &pg.StrokeWeight, "StrokeType": &pg.StrokeType,
+			"StrokeColor": &pg.StrokeColor, "StrokeTint": &pg.StrokeTint,
+		},
+		otherAttrs:   &pg.OtherAttrs,
+		elements:     &pg.OtherElements,
+		textWrap:     &pg.TextWrapPreference,
+		graphicFrame: true,
+	}
+}
+
+func graphicLineTarget(l *spread.GraphicLine) *objectStyleTarget {
+	return &objectStyleTarget{
+		appliedStyle: &l.AppliedObjectStyle,
+		overridden:   &l.OverriddenPageItemProps,
+		fields: map[string]*string{
+			"FillColor": &l.FillColor, "FillTint": &l.FillTint,
+			"StrokeWeight": &l.StrokeWeight, "StrokeType": &l.StrokeType,
+			"StrokeColor": &l.StrokeColor, "StrokeTint": &l.StrokeTint,
+			"EndCap": &l.EndCap, "EndJoin": &l.EndJoin, "MiterLimit": &l.MiterLimit,
+			"LeftLineEnd": &l.LeftLineEnd, "RightLineEnd": &l.RightLineEnd,
+			"GradientFillAngle": &l.GradientFillAngle, "GradientStrokeAngle": &l.GradientStrokeAngle,
+		},
+		otherAttrs: &l.OtherAttrs,
+		elements:   &l.OtherElements,
+		textWrap:   &l.TextWrapPreference,
+	}
+}
+
+func groupTarget(g *spread.Group) *objectStyleTarget {
+	return &objectStyleTarget{
+		appliedStyle: &g.AppliedObjectStyle,
+		otherAttrs:   &g.OtherAttrs,
+		elements:     &g.OtherElements,
+	}
+}
+
+// overriddenProps returns the names listed in OverriddenPageItemProps.
+func overriddenProps(value string) map[string]bool {
+	names := strings.Fields(value)
+	if len(names) == 0 {
+		return nil
+	}
+	keep := make(map[string]bool, len(names))
+	for _, name := range names {
+		keep[name] = true
+	}
+	return keep
+}
+
+// applySettings replaces the settings of the categories enabled by an object
+// style with the style's settings. Attributes and settings elements named in
+// keep are left alone.
+func (t *objectStyleTarget) applySettings(styles *resources.StylesFile, resolved *resources.ResolvedStyle, keep map[string]bool) error {
+	enabled := func(flag string) bool {
+		return resolved.Attr(flag) == "true"
+	}
+	// element returns the first style in the BasedOn chain with the element
+	element := func(get func(*resources.ObjectStyle) bool) *resources.ObjectStyle {
+		for _, id := range resolved.Chain {
+			if style := styles.FindObjectStyle(id); style != nil && get(style) {
+				return style
+			}
+		}
+		return nil
+	}
+
+	for _, category := range objectStyleCategoryAttrs {
+		if !enabled(category.flag) {
+			continue
+		}
+		for _, name := range category.attrs {
+			if value := resolved.Attr(name); value != "" && !keep[name] {
+				t.setAttr(name, value)
+			}
+		}
+	}
+
+	if enabled("EnableTextWrapAndOthers") && !keep["TextWrapPreference"] {
+		if style := element(func(s *resources.ObjectStyle) bool { return s.TextWrapPreference != nil }); style != nil {
+			var err error
+			if t.textWrap != nil {
+				*t.textWrap = &spread.TextWrapPreference{}
+				err = copyElement("TextWrapPreference", style.TextWrapPreference, *t.textWrap)
+			} else {
+				err = t.setElement("TextWrapPreference", style.TextWrapPreference)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if t.graphicFrame && enabled("EnableFrameFittingOptions") && !keep["FrameFittingOption"] {
+		if style := element(func(s *resources.ObjectStyle) bool { return s.FrameFittingOption != nil }); style != nil {
+			var err error
+			if t.frameFitting != nil {
+				*t.frameFitting = &spread.FrameFittingOption{}
+				err = copyElement("FrameFittingOption", style.FrameFittingOption, *t.frameFitting)
+			} else {
+				err = t.setElement("FrameFittingOption", style.FrameFittingOption)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if t.anchor != nil && enabled("EnableAnchoredObjectOptions") && !keep["AnchoredObjectSetting"] {
+		if style := element(func(s *resources.ObjectStyle) bool { return s.AnchoredObjectSetting != nil }); style != nil {
+			*t.anchor = &story.AnchoredObjectSetting{}
+			if err := copyElement("AnchoredObjectSetting", style.AnchoredObjectSetting, *t.anchor); err != nil {
+				return err
+			}
+		}
+	}
+
+	if t.textFrame {
+		if style := element(func(s *resources.ObjectStyle) bool { return s.TextFramePreference != nil }); style != nil && !keep["TextFramePreference"] {
+			if err := t.mergeTextFramePreference(style.TextFramePreference, enabled, keep); err != nil {
+				return err
+			}
+		}
+		if enabled("EnableTextFrameBaselineOptions") && !keep["BaselineFrameGridOption"] {
+			if style := element(func(s *resources.ObjectStyle) bool { return s.BaselineFrameGridOption != nil }); style != nil {
+				if err := t.setElement("BaselineFrameGridOption", style.BaselineFrameGridOption); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// mergeTextFramePreference sets the TextFramePreference attributes of the
+// enabled text frame categories that are not in keep, and the insets with
+// the general options.
+func (t *objectStyleTarget) mergeTextFramePreference(pref *resources.TextFramePreference, enabled func(flag string) bool, keep map[string]bool) error {
+	var src common.RawXMLElement
+	if err := copyElement("TextFramePreference", pref, &src); err != nil {
+		return err
+	}
+	dst := t.element("TextFramePreference")
+	for _, attr := range src.Attrs {
+		if enabled(textFramePreferenceFlag(attr.Name.Local)) && !keep[attr.Name.Local] {
+			dst.Attrs = setAttr(dst.Attrs, attr.Name.Local, attr.Value)
+		}
+	}
+	if enabled("EnableTextFrameGeneralOptions") && !keep["InsetSpacing"] && len(bytes.TrimSpace(src.Content)) > 0 {
+		dst.Content = src.Content
+	}
+	return nil
+}
+
+// textFramePreferenceFlag returns the Enable* flag of the object style
+// category a TextFramePreference attribute belongs to.
+func textFramePreferenceFlag(name string) string {
+	switch {
+	case strings.HasPrefix(name, "Footnotes"):
+		return "EnableTextFrameFootnoteOptions"
+	case strings.HasPrefix(name, "ColumnRule"):
+		return "EnableTextFrameColumnRuleOptions"
+	case strings.Contains(name, "AutoSizing"):
+		return "EnableTextFrameAutoSizingOptions"
+	case name == "FirstBaselineOffset" || name == "MinimumFirstBaselineOffset":
+		return "EnableTextFrameBaselineOptions"
+	}
+	return "EnableTextFrameGeneralOptions"
+}
+
+// setAttr sets an attribute of the page item.
+func (t *objectStyleTarget) setAttr(name, value string) {
+	if field := t.fields[name]; field != nil {
+		*field = value
+		return
+	}
+	*t.otherAttrs = setAttr(*t.otherAttrs, name, value)
+}
+
+// element returns the settings element with the given name from the item's
+// raw elements, adding an empty one if it has none.
+func (t *objectStyleTarget) element(name string) *common.RawXMLElement {
+	for i := range *t.elements {
+		if (*t.elements)[i].XMLName.Local == name {
+			return &(*t.elements)[i]
+		}
+	}
+	*t.elements = append(*t.elements, common.RawXMLElement{XMLName: xml.Name{Local: name}})
+	return &(*t.elements)[len(*t.elements)-1]
+}
+
+// setElement replaces a settings element kept in the item's raw elements
+// with the style's.
+func (t *objectStyleTarget) setElement(name string, src interface{}) error {
+	var raw common.RawXMLElement
+	if err := copyElement(name, src, &raw); err != nil {
+		return err
+	}
+	*t.element(name) = raw
+	return nil
+}
+
+// copyElement copies a settings element between the types of the resources,
+// spread and story packages by way of its XML.
+func copyElement(name string, src, dst interface{}) error {
+	var buf bytes.Buffer
+	enc := xml.NewEncoder(&buf)
+	if err := enc.EncodeElement(src, xml.StartElement{Name: xml.Name{Local: name}}); err != nil {
+		return err
+	}
+	if err := enc.Flush(); err != nil {
+		return err
+	}
+	return xml.Unmarshal(buf.Bytes(), dst)
+}
+
+// attrValue returns the value of the attribute with the given name.
+func attrValue(attrs []xml.Attr, name string) string {
+	for _, a := range attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// setAttr sets the named attribute in attrs, appending it if missing.
+func setAttr(attrs []xml.Attr, name, value string) []xml.Attr {
+	for i := range attrs {
+		if attrs[i].Name.Local == name {
+			attrs[i].Value = value
+			return attrs
+		}
+	}
+	return append(attrs, xml.Attr{Name: xml.Name{Local: name}, Value: value})
+}
+
+// sortedKeys returns the keys of a map of package files in sorted order.
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,250 @@
+package idml
+
+import (
+	"encoding/xml"
+	"errors"
+	"testing"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/spread"
+	"github.com/dimelords/idmllib/v2/pkg/story"
+)
+
+// findRectangle returns the rectangle with the given Self on the spreads of
+// pkg.
+func findRectangle(t *testing.T, pkg *Package, self string) *spread.Rectangle {
+	t.Helper()
+	spreads, err := pkg.Spreads()
+	if err != nil {
+		t.Fatalf("Spreads failed: %v", err)
+	}
+	for _, sp := range spreads {
+		for i := range sp.InnerSpread.Rectangles {
+			if sp.InnerSpread.Rectangles[i].Self == self {
+				return &sp.InnerSpread.Rectangles[i]
+			}
+		}
+	}
+	t.Fatalf("rectangle %s not found", self)
+	return nil
+}
+
+// findSpreadTextFrame returns the text frame with the given Self on the
+// spreads of pkg.
+func findSpreadTextFrame(t *testing.T, pkg *Package, self string) *spread.SpreadTextFrame {
+	t.Helper()
+	spreads, err := pkg.Spreads()
+	if err != nil {
+		t.Fatalf("Spreads failed: %v", err)
+	}
+	for _, sp := range spreads {
+		for i := range sp.InnerSpread.TextFrames {
+			if sp.InnerSpread.TextFrames[i].Self == self {
+				return &sp.InnerSpread.TextFrames[i]
+			}
+		}
+	}
+	t.Fatalf("text frame %s not found", self)
+	return nil
+}
+
+// rawAttr returns an attribute of the first element with the given name.
+func rawAttr(elements []common.RawXMLElement, element, name string) string {
+	for _, e := range elements {
+		if e.XMLName.Local == element {
+			return attrValue(e.Attrs, name)
+		}
+	}
+	return ""
+}
+
+// TestApplyObjectStyle tests applying an object style to a rectangle with
+// and without clearing its overridden settings.
+func TestApplyObjectStyle(t *testing.T) {
+	pkg := loadExampleIDML(t)
+	styleID := "ObjectStyle/Naviga%3aStandard%3aimage-Bilde"
+
+	rect := findRectangle(t, pkg, "u264")
+	rect.AppliedObjectStyle = "ObjectStyle/$ID/[Normal Graphics Frame]"
+	rect.FillColor = "Color/Seksjonsfarge"
+	rect.OverriddenPageItemProps = "FillColor"
+	rect.OtherAttrs = append(rect.OtherAttrs, xml.Attr{Name: xml.Name{Local: "TopLeftCornerOption"}, Value: "RoundedCorner"})
+	rect.TextWrapPreference = &spread.TextWrapPreference{TextWrapMode: "None"}
+
+	if err := pkg.ApplyObjectStyle("u264", styleID, false); err != nil {
+		t.Fatalf("ApplyObjectStyle failed: %v", err)
+	}
+	rect = findRectangle(t, pkg, "u264")
+	if rect.AppliedObjectStyle != styleID || rect.FillColor != "Color/Seksjonsfarge" || rect.OverriddenPageItemProps != "FillColor" {
+		t.Errorf("overridden settings were not kept: %+v", rect)
+	}
+	// Settings that are not overridden follow the style
+	if rect.StrokeWeight != "0" {
+		t.Errorf("StrokeWeight = %q, want 0 from the style", rect.StrokeWeight)
+	}
+	if got := attrValue(rect.OtherAttrs, "TopLeftCornerOption"); got != "None" {
+		t.Errorf("TopLeftCornerOption = %q, want None from the style", got)
+	}
+	if w := rect.TextWrapPreference; w == nil || w.TextWrapMode != "BoundingBoxTextWrap" {
+		t.Errorf("TextWrapPreference = %+v, want the style's", w)
+	}
+
+	if err := pkg.ApplyObjectStyle("u264", styleID, true); err != nil {
+		t.Fatalf("ApplyObjectStyle(clearOverrides) failed: %v", err)
+	}
+	reread, err := Read(writeTestIDML(t, pkg, "objectstyle.idml"))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	rect = findRectangle(t, reread, "u264")
+	if rect.AppliedObjectStyle != styleID || rect.OverriddenPageItemProps != "" {
+		t.Errorf("AppliedObjectStyle = %q, OverriddenPageItemProps = %q", rect.AppliedObjectStyle, rect.OverriddenPageItemProps)
+	}
+	if rect.FillColor != "Swatch/None" || rect.StrokeWeight != "0" {
+		t.Errorf("FillColor = %q, StrokeWeight = %q", rect.FillColor, rect.StrokeWeight)
+	}
+	if got := attrValue(rect.OtherAttrs, "TopLeftCornerOption"); got != "None" {
+		t.Errorf("TopLeftCornerOption = %q, want None", got)
+	}
+	if w := rect.TextWrapPreference; w == nil || w.TextWrapMode != "BoundingBoxTextWrap" {
+		t.Errorf("TextWrapPreference = %+v", w)
+	}
+
+	if err := pkg.ApplyObjectStyle("missing", styleID, true); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("missing item error = %v, want ErrNotFound", err)
+	}
+	if err := pkg.ApplyObjectStyle("u264", "ObjectStyle/Missing", true); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("missing style error = %v, want ErrNotFound", err)
+	}
+}
+
+// TestApplyObjectStyle_TextFrame tests that only the text frame options of
+// enabled categories are replaced.
+func TestApplyObjectStyle_TextFrame(t *testing.T) {
+	pkg := loadExampleIDML(t)
+	styleID := "ObjectStyle/Naviga%3aStandard%3aheadline-TIT B"
+
+	if err := pkg.ApplyObjectStyle("u234", styleID, true); err != nil {
+		t.Fatalf("ApplyObjectStyle failed: %v", err)
+	}
+	frame := findSpreadTextFrame(t, pkg, "u234")
+	if frame.AppliedObjectStyle != styleID {
+		t.Errorf("AppliedObjectStyle = %q", frame.AppliedObjectStyle)
+	}
+	if got := rawAttr(frame.OtherElements, "TextFramePreference", "TextColumnCount"); got != "1" {
+		t.Errorf("TextColumnCount = %q, want 1 from the style", got)
+	}
+	// Footnote options are not enabled by the style
+	if got := rawAttr(frame.OtherElements, "TextFramePreference", "FootnotesMinimumSpacing"); got != "12" {
+		t.Errorf("FootnotesMinimumSpacing = %q, want the frame's 12", got)
+	}
+	if got := rawAttr(frame.OtherElements, "TextWrapPreference", "TextWrapMode"); got != "BoundingBoxTextWrap" {
+		t.Errorf("TextWrapMode = %q, want BoundingBoxTextWrap", got)
+	}
+}
+
+// TestApplyObjectStyle_Anchored tests applying a style with anchored object
+// options to a page item anchored in a story.
+func TestApplyObjectStyle_Anchored(t *testing.T) {
+	pkg := loadExampleIDML(t)
+	styleID := "ObjectStyle/Naviga%3aStandard%3afactBox"
+
+	stories, err := pkg.Stories()
+	if err != nil {
+		t.Fatalf("Stories failed: %v", err)
+	}
+	var filename string
+	for _, name := range sortedKeys(stories) {
+		psrs := stories[name].StoryElement.ParagraphStyleRanges
+		if len(psrs) > 0 && len(psrs[0].CharacterStyleRanges) > 0 {
+			filename = name
+			break
+		}
+	}
+	if filename == "" {
+		t.Skip("No story with text in example.idml")
+	}
+	csr := &stories[filename].StoryElement.ParagraphStyleRanges[0].CharacterStyleRanges[0]
+	rect := &story.AnchoredRectangle{}
+	rect.Self = "uanc1"
+	rect.AnchoredObjectSetting = &story.AnchoredObjectSetting{AnchoredPosition: "Anchored", AnchorXoffset: "10"}
+	csr.Children = append(csr.Children, story.CharacterChild{Anchored: &story.AnchoredObject{Rectangle: rect}})
+
+	if err := pkg.ApplyObjectStyle("uanc1", styleID, true); err != nil {
+		t.Fatalf("ApplyObjectStyle failed: %v", err)
+	}
+	reread, err := Read(writeTestIDML(t, pkg, "anchored.idml"))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	st, err := reread.Story(filename)
+	if err != nil {
+		t.Fatalf("Story failed: %v", err)
+	}
+	for _, obj := range st.AnchoredObjects() {
+		if obj.Self() != "uanc1" {
+			continue
+		}
+		if obj.Rectangle.AppliedObjectStyle != styleID {
+			t.Errorf("AppliedObjectStyle = %q", obj.Rectangle.AppliedObjectStyle)
+		}
+		if a := obj.Rectangle.AnchoredObjectSetting; a == nil || a.AnchoredPosition != "InlinePosition" || a.AnchorXoffset != "0" {
+			t.Errorf("AnchoredObjectSetting = %+v", a)
+		}
+		return
+	}
+	t.Error("anchored rectangle not found after roundtrip")
+}
+
+// TestApplyObjectStyle_Grouped tests applying an object style to a group
+// and to a page item inside a nested group.
+func TestApplyObjectStyle_Grouped(t *testing.T) {
+	pkg := loadExampleIDML(t)
+	styleID := "ObjectStyle/Naviga%3aStandard%3aimage-Bilde"
+	sp, err := pkg.Spread("Spreads/Spread_u210.xml")
+	if err != nil {
+		t.Fatalf("Spread failed: %v", err)
+	}
+
+	var nested common.RawXMLElement
+	if err := xml.Unmarshal([]byte(`<Group Self="ug2"><Rectangle Self="ug1" AppliedObjectStyle="ObjectStyle/$ID/[None]" FillColor="Color/Black" /></Group>`), &nested); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	group := spread.Group{OtherElements: []common.RawXMLElement{nested}}
+	group.Self = "ug0"
+	sp.InnerSpread.Groups = append(sp.InnerSpread.Groups, group)
+
+	if err := pkg.ApplyObjectStyle("ug1", styleID, true); err != nil {
+		t.Fatalf("ApplyObjectStyle(grouped) failed: %v", err)
+	}
+	if err := pkg.ApplyObjectStyle("ug0", styleID, false); err != nil {
+		t.Fatalf("ApplyObjectStyle(group) failed: %v", err)
+	}
+
+	reread, err := Read(writeTestIDML(t, pkg, "grouped_style.idml"))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	sp, err = reread.Spread("Spreads/Spread_u210.xml")
+	if err != nil {
+		t.Fatalf("Spread failed: %v", err)
+	}
+	groups := sp.InnerSpread.Groups
+	if len(groups) == 0 || groups[len(groups)-1].AppliedObjectStyle != styleID {
+		t.Fatalf("group AppliedObjectStyle not set")
+	}
+	var inner spread.Group
+	if err := copyElement("Group", &groups[len(groups)-1].OtherElements[0], &inner); err != nil {
+		t.Fatalf("decoding nested group failed: %v", err)
+	}
+	var rect spread.Rectangle
+	if err := copyElement("Rectangle", &inner.OtherElements[0], &rect); err != nil {
+		t.Fatalf("decoding grouped rectangle failed: %v", err)
+	}
+	if rect.Self != "ug1" || rect.AppliedObjectStyle != styleID {
+		t.Errorf("grouped rectangle %q AppliedObjectStyle = %q", rect.Self, rect.AppliedObjectStyle)
+	}
+	if rect.FillColor != "Swatch/None" || rect.StrokeWeight != "0" {
+		t.Errorf("grouped rectangle FillColor = %q, StrokeWeight = %q", rect.FillColor, rect.StrokeWeight)
+	}
+}
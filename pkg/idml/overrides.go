@@ -0,0 +1,331 @@
+package idml
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/resources"
+	"github.com/dimelords/idmllib/v2/pkg/story"
+)
+
+// LocalOverrideSet is a set of local character formatting that one or more
+// character style ranges apply on top of the same character style.
+type LocalOverrideSet struct {
+	// CharacterStyle is the character style applied by the ranges
+	CharacterStyle string
+
+	// Formatting maps the overridden attributes and Properties children,
+	// such as FontStyle or AppliedFont, to their values
+	Formatting map[string]string
+
+	// Count is the number of character style ranges using the set
+	Count int
+
+	// Stories lists the story files using the set (e.g., "Stories/Story_u1d8.xml")
+	Stories []string
+}
+
+// LocalOverrideOptions controls ConvertLocalOverrides.
+type LocalOverrideOptions struct {
+	// Group is the Self of the character style group new styles are created
+	// in, or "" for the root group
+	Group string
+
+	// NamePrefix names new styles, which are numbered ("Local Override 1",
+	// "Local Override 2", ...). Defaults to "Local Override".
+	NamePrefix string
+
+	// MinCount skips override sets used by fewer ranges. Zero converts all.
+	MinCount int
+}
+
+// LocalOverrideReport lists the character styles used by
+// ConvertLocalOverrides.
+type LocalOverrideReport struct {
+	Created []string // Selfs of the character styles created
+	Reused  []string // Selfs of existing character styles with matching formatting
+	Ranges  int      // number of character style ranges rewritten
+}
+
+// LocalOverrides returns the sets of local character formatting used in the
+// stories of the package, including their table cells, footnotes and notes:
+// ranges applying the same character style with identical overrides share a
+// set. Sets are ordered by Count, most used first.
+//
+// Local overrides bypass the style system. ConvertLocalOverrides replaces
+// them with character styles.
+//
+// Example:
+//
+//	sets, err := pkg.LocalOverrides()
+//	for _, set := range sets {
+//	    fmt.Println(set.Count, set.CharacterStyle, set.Formatting)
+//	}
+func (p *Package) LocalOverrides() ([]LocalOverrideSet, error) {
+	stories, err := p.Stories()
+	if err != nil {
+		return nil, common.WrapError("idml", "local overrides", err)
+	}
+	filenames := make([]string, 0, len(stories))
+	for filename := range stories {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+
+	var sets []*LocalOverrideSet
+	byKey := make(map[string]*LocalOverrideSet)
+	for _, filename := range filenames {
+		for _, csr := range characterRanges(stories[filename]) {
+			formatting := csr.LocalFormatting()
+			if formatting == nil {
+				continue
+			}
+			key := overrideKey(csr.AppliedCharacterStyle, formatting)
+			set := byKey[key]
+			if set == nil {
+				set = &LocalOverrideSet{CharacterStyle: csr.AppliedCharacterStyle, Formatting: formatting}
+				byKey[key] = set
+				sets = append(sets, set)
+			}
+			set.Count++
+			if n := len(set.Stories); n == 0 || set.Stories[n-1] != filename {
+				set.Stories = append(set.Stories, filename)
+			}
+		}
+	}
+
+	result := make([]LocalOverrideSet, len(sets))
+	for i, set := range sets {
+		result[i] = *set
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Count > result[j].Count
+	})
+	return result, nil
+}
+
+// ConvertLocalOverrides replaces local character formatting with character
+// styles. For each set returned by LocalOverrides, an existing character
+// style whose computed formatting equals the applied style with the
+// overrides is reused; otherwise a style based on the applied style and
+// setting the overrides is created. The ranges are then rewritten to apply
+// that style, with their overrides cleared.
+//
+// The text looks the same afterwards. Built-in styles are never reused.
+//
+// Returns common.ErrNotFound if opts.Group or an applied character style
+// does not exist.
+//
+// Example:
+//
+//	report, err := pkg.ConvertLocalOverrides(idml.LocalOverrideOptions{
+//	    Group:    "CharacterStyleGroup/Overrides",
+//	    MinCount: 2,
+//	})
+//	fmt.Println(report.Created, report.Ranges)
+func (p *Package) ConvertLocalOverrides(opts LocalOverrideOptions) (*LocalOverrideReport, error) {
+	const op = "convert local overrides"
+
+	sets, err := p.LocalOverrides()
+	if err != nil {
+		return nil, err
+	}
+	styles, err := p.Styles()
+	if err != nil {
+		return nil, common.WrapErrorWithPath("idml", op, PathStyles, err)
+	}
+	if findCharacterStyleGroup(styles.RootCharacterStyleGroup, opts.Group) == nil {
+		return nil, common.WrapErrorWithPath("idml", op, opts.Group, common.ErrNotFound)
+	}
+	prefix := opts.NamePrefix
+	if prefix == "" {
+		prefix = "Local Override"
+	}
+
+	// Computed formatting of the styles that may be reused
+	type candidate struct {
+		self       string
+		formatting map[string]string
+	}
+	var candidates []candidate
+	for _, listed := range listStyles(styles) {
+		if listed.prefix != characterStylePrefix || strings.Contains(listed.self, "/$ID/") {
+			continue
+		}
+		formatting, err := resolvedCharacterFormatting(styles, listed.self)
+		if err != nil {
+			return nil, common.WrapErrorWithPath("idml", op, listed.self, err)
+		}
+		candidates = append(candidates, candidate{listed.self, formatting})
+	}
+
+	report := &LocalOverrideReport{}
+	converted := make(map[string]string)
+	next := 1
+	for _, set := range sets {
+		if set.Count < opts.MinCount {
+			continue
+		}
+		want, err := resolvedCharacterFormatting(styles, set.CharacterStyle)
+		if err != nil {
+			return nil, common.WrapErrorWithPath("idml", op, set.CharacterStyle, err)
+		}
+		for name, value := range set.Formatting {
+			want[name] = value
+		}
+
+		styleID := ""
+		for _, c := range candidates {
+			if equalFormatting(c.formatting, want) {
+				styleID = c.self
+				break
+			}
+		}
+		if styleID != "" {
+			if !slices.Contains(report.Reused, styleID) && !slices.Contains(report.Created, styleID) {
+				report.Reused = append(report.Reused, styleID)
+			}
+		} else {
+			basedOn := set.CharacterStyle
+			if basedOn == characterStylePrefix+"$ID/[No character style]" {
+				basedOn = ""
+			}
+			for {
+				styleID, err = p.CreateCharacterStyle(opts.Group, prefix+" "+strconv.Itoa(next), basedOn, set.Formatting)
+				next++
+				if !errors.Is(err, common.ErrAlreadyExists) {
+					break
+				}
+			}
+			if err != nil {
+				return nil, common.WrapError("idml", op, err)
+			}
+			report.Created = append(report.Created, styleID)
+			candidates = append(candidates, candidate{styleID, want})
+		}
+		converted[overrideKey(set.CharacterStyle, set.Formatting)] = styleID
+	}
+	if len(converted) == 0 {
+		return report, nil
+	}
+
+	stories, err := p.Stories()
+	if err != nil {
+		return nil, common.WrapError("idml", op, err)
+	}
+	for filename, st := range stories {
+		changed := false
+		for _, csr := range characterRanges(st) {
+			formatting := csr.LocalFormatting()
+			if formatting == nil {
+				continue
+			}
+			styleID, ok := converted[overrideKey(csr.AppliedCharacterStyle, formatting)]
+			if !ok {
+				continue
+			}
+			if err := csr.ClearLocalFormatting(); err != nil {
+				return nil, common.WrapErrorWithPath("idml", op, filename, err)
+			}
+			csr.AppliedCharacterStyle = styleID
+			report.Ranges++
+			changed = true
+		}
+		if changed {
+			if err := p.marshalAndUpdateStory(filename, st); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return report, nil
+}
+
+// characterRanges returns pointers to the character style ranges of a
+// story, including those in table cells, nested tables, footnotes and notes.
+func characterRanges(st *story.Story) []*story.CharacterStyleRange {
+	ranges := appendCharacterRanges(nil, st.StoryElement.ParagraphStyleRanges)
+	ranges = appendTableCharacterRanges(ranges, st.Tables())
+	for _, footnote := range st.Footnotes() {
+		ranges = appendCharacterRanges(ranges, footnote.ParagraphStyleRanges)
+	}
+	for _, note := range st.Notes() {
+		ranges = appendCharacterRanges(ranges, note.ParagraphStyleRanges)
+	}
+	return ranges
+}
+
+// appendCharacterRanges appends pointers to the character style ranges of
+// the given paragraph ranges.
+func appendCharacterRanges(ranges []*story.CharacterStyleRange, psrs []story.ParagraphStyleRange) []*story.CharacterStyleRange {
+	for i := range psrs {
+		for j := range psrs[i].CharacterStyleRanges {
+			ranges = append(ranges, &psrs[i].CharacterStyleRanges[j])
+		}
+	}
+	return ranges
+}
+
+// appendTableCharacterRanges appends pointers to the character style ranges
+// of the cells of the given tables, descending into nested tables.
+func appendTableCharacterRanges(ranges []*story.CharacterStyleRange, tables []*story.Table) []*story.CharacterStyleRange {
+	for _, table := range tables {
+		for i := range table.Cells {
+			cell := &table.Cells[i]
+			ranges = appendCharacterRanges(ranges, cell.ParagraphStyleRanges)
+			ranges = appendTableCharacterRanges(ranges, cell.Tables())
+		}
+	}
+	return ranges
+}
+
+// overrideKey identifies a set of local formatting on a character style.
+func overrideKey(characterStyle string, formatting map[string]string) string {
+	names := make([]string, 0, len(formatting))
+	for name := range formatting {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b strings.Builder
+	b.WriteString(characterStyle)
+	for _, name := range names {
+		b.WriteString("\x00" + name + "=" + formatting[name])
+	}
+	return b.String()
+}
+
+// resolvedCharacterFormatting returns the computed formatting of a character
+// style as values by name. [No character style] and "" set nothing.
+func resolvedCharacterFormatting(styles *resources.StylesFile, styleID string) (map[string]string, error) {
+	formatting := make(map[string]string)
+	if styleID == "" || styleID == characterStylePrefix+"$ID/[No character style]" {
+		return formatting, nil
+	}
+	resolved, err := styles.ResolveCharacterStyle(styleID)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range resolved.Attributes {
+		formatting[name] = value
+	}
+	for name := range resolved.Properties {
+		formatting[name] = resolved.Property(name)
+	}
+	return formatting, nil
+}
+
+// equalFormatting reports whether two sets of formatting values are equal.
+func equalFormatting(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, value := range a {
+		if other, ok := b[name]; !ok || other != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,166 @@
+package idml
+
+import (
+	"encoding/xml"
+	"errors"
+	"testing"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/story"
+)
+
+// TestLocalOverrides tests grouping identical local formatting across
+// stories.
+func TestLocalOverrides(t *testing.T) {
+	pkg := loadExampleIDML(t)
+
+	sets, err := pkg.LocalOverrides()
+	if err != nil {
+		t.Fatalf("LocalOverrides failed: %v", err)
+	}
+	if len(sets) != 3 {
+		t.Fatalf("LocalOverrides() returned %d sets, want 3: %+v", len(sets), sets)
+	}
+	top := sets[0]
+	if top.CharacterStyle != "CharacterStyle/Naviga%3anoneStyle" || top.Count != 14 ||
+		len(top.Formatting) != 2 || top.Formatting["HorizontalScale"] != "101" || top.Formatting["Tracking"] != "4" {
+		t.Errorf("most used set = %+v", top)
+	}
+	if len(top.Stories) == 0 {
+		t.Error("most used set lists no stories")
+	}
+	for _, set := range sets[1:] {
+		if set.Count != 1 {
+			t.Errorf("set %+v, want Count 1", set)
+		}
+	}
+}
+
+// TestConvertLocalOverrides tests replacing local formatting with created
+// and reused character styles.
+func TestConvertLocalOverrides(t *testing.T) {
+	pkg := loadExampleIDML(t)
+	tight, err := pkg.CreateCharacterStyle("", "Tight", "CharacterStyle/Naviga%3anoneStyle", map[string]string{"Tracking": "3"})
+	if err != nil {
+		t.Fatalf("CreateCharacterStyle failed: %v", err)
+	}
+
+	report, err := pkg.ConvertLocalOverrides(LocalOverrideOptions{MinCount: 2})
+	if err != nil {
+		t.Fatalf("ConvertLocalOverrides failed: %v", err)
+	}
+	created := "CharacterStyle/Local Override 1"
+	if len(report.Created) != 1 || report.Created[0] != created || len(report.Reused) != 0 || report.Ranges != 14 {
+		t.Errorf("report = %+v", report)
+	}
+	if sets, _ := pkg.LocalOverrides(); len(sets) != 2 {
+		t.Errorf("%d override sets remain, want 2", len(sets))
+	}
+
+	report, err = pkg.ConvertLocalOverrides(LocalOverrideOptions{})
+	if err != nil {
+		t.Fatalf("ConvertLocalOverrides(all) failed: %v", err)
+	}
+	if len(report.Reused) != 1 || report.Reused[0] != tight || len(report.Created) != 1 || report.Ranges != 2 {
+		t.Errorf("report = %+v", report)
+	}
+
+	reread, err := Read(writeTestIDML(t, pkg, "overrides.idml"))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if sets, err := reread.LocalOverrides(); err != nil || len(sets) != 0 {
+		t.Errorf("LocalOverrides() after converting = %+v, %v", sets, err)
+	}
+	styles, err := reread.Styles()
+	if err != nil {
+		t.Fatalf("Styles failed: %v", err)
+	}
+	resolved, err := styles.ResolveCharacterStyle(created)
+	if err != nil {
+		t.Fatalf("ResolveCharacterStyle failed: %v", err)
+	}
+	if resolved.Attr("HorizontalScale") != "101" || resolved.Attr("Tracking") != "4" ||
+		len(resolved.Chain) < 2 || resolved.Chain[1] != "CharacterStyle/Naviga%3anoneStyle" {
+		t.Errorf("created style = %v %v", resolved.Chain, resolved.Attributes)
+	}
+	// The 14 ranges and the Self of the style
+	if _, count := countStyleRefs(t, reread, created); count != 15 {
+		t.Errorf("%s is referenced %d times, want 15", created, count)
+	}
+
+	if _, err := pkg.ConvertLocalOverrides(LocalOverrideOptions{Group: "CharacterStyleGroup/Missing"}); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("missing group error = %v, want ErrNotFound", err)
+	}
+}
+
+// TestLocalOverrides_TableCellsAndNotes tests that local formatting in table
+// cells and notes is found and converted.
+func TestLocalOverrides_TableCellsAndNotes(t *testing.T) {
+	pkg := loadExampleIDML(t)
+	st, err := pkg.Story(hyperlinkStory)
+	if err != nil {
+		t.Fatalf("Story failed: %v", err)
+	}
+	paragraph := func() []story.ParagraphStyleRange {
+		csr := story.NewCharacterStyleRange("", nil)
+		csr.Tracking = "77"
+		csr.AddContent("Wide")
+		return []story.ParagraphStyleRange{{
+			XMLName:               xml.Name{Local: "ParagraphStyleRange"},
+			AppliedParagraphStyle: "ParagraphStyle/$ID/NormalParagraphStyle",
+			CharacterStyleRanges:  []story.CharacterStyleRange{csr},
+		}}
+	}
+	table := &story.Table{
+		Self:         "utbl1",
+		BodyRowCount: "1",
+		ColumnCount:  "1",
+		Cells:        []story.Cell{{Self: "utbl1i0i0", Name: "0:0", ParagraphStyleRanges: paragraph()}},
+	}
+	note := &story.Note{XMLName: xml.Name{Local: "Note"}, Self: "u901", ParagraphStyleRanges: paragraph()}
+	csr := &st.StoryElement.ParagraphStyleRanges[0].CharacterStyleRanges[0]
+	csr.Children = append(csr.Children, story.CharacterChild{Table: table}, story.CharacterChild{Note: note})
+
+	sets, err := pkg.LocalOverrides()
+	if err != nil {
+		t.Fatalf("LocalOverrides failed: %v", err)
+	}
+	var found *LocalOverrideSet
+	for i := range sets {
+		if sets[i].Formatting["Tracking"] == "77" {
+			found = &sets[i]
+		}
+	}
+	if found == nil || found.Count != 2 || len(found.Formatting) != 1 {
+		t.Fatalf("set with Tracking 77 = %+v, want 2 ranges", found)
+	}
+
+	report, err := pkg.ConvertLocalOverrides(LocalOverrideOptions{MinCount: 2})
+	if err != nil {
+		t.Fatalf("ConvertLocalOverrides failed: %v", err)
+	}
+	reread, err := Read(writeTestIDML(t, pkg, "overrides_table.idml"))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	st, err = reread.Story(hyperlinkStory)
+	if err != nil {
+		t.Fatalf("Story failed: %v", err)
+	}
+	tables := st.Tables()
+	if len(tables) != 1 {
+		t.Fatalf("%d tables after roundtrip, want 1", len(tables))
+	}
+	cellRange := tables[0].Cells[0].ParagraphStyleRanges[0].CharacterStyleRanges[0]
+	if cellRange.Tracking != "" || !containsString(report.Created, cellRange.AppliedCharacterStyle) {
+		t.Errorf("cell range Tracking = %q, style = %q, created %v", cellRange.Tracking, cellRange.AppliedCharacterStyle, report.Created)
+	}
+	notes := st.Notes()
+	if len(notes) != 1 {
+		t.Fatalf("%d notes after roundtrip, want 1", len(notes))
+	}
+	if noteRange := notes[0].ParagraphStyleRanges[0].CharacterStyleRanges[0]; noteRange.AppliedCharacterStyle != cellRange.AppliedCharacterStyle {
+		t.Errorf("note range style = %q, want %q", noteRange.AppliedCharacterStyle, cellRange.AppliedCharacterStyle)
+	}
+}
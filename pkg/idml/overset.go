@@ -0,0 +1,582 @@
+package idml
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/fontmetrics"
+	"github.com/dimelords/idmllib/v2/pkg/resources"
+	"github.com/dimelords/idmllib/v2/pkg/spread"
+	"github.com/dimelords/idmllib/v2/pkg/story"
+)
+
+// Defaults used when a style does not define a value.
+const (
+	defaultPointSize        = 12.0
+	defaultAutoLeading      = 1.2 // InDesign's 120% auto leading
+	defaultAverageCharWidth = 0.5 // fraction of the point size
+)
+
+// OversetOptions controls text fitting estimates.
+type OversetOptions struct {
+	// Fonts provides glyph metrics for measuring text.
+	// If nil, or if a font is not in the collection, characters are measured
+	// with AverageCharWidth.
+	Fonts *fontmetrics.Collection
+
+	// AverageCharWidth is the fallback character width as a fraction of the
+	// point size. Defaults to 0.5.
+	AverageCharWidth float64
+}
+
+// FrameFit describes how much of a story an individual frame holds.
+type FrameFit struct {
+	// FrameID is the text frame Self ID.
+	FrameID string
+
+	// SpreadFilename is the spread file containing the frame.
+	SpreadFilename string
+
+	// Capacity holds the column layout from TextFramePreference (may be nil).
+	Capacity *spread.TextCapacityInfo
+
+	// Lines is the estimated number of lines set in the frame.
+	Lines int
+
+	// UsedHeight is the height taken by lines and paragraph spacing, summed over columns.
+	UsedHeight float64
+
+	// AvailableHeight is the column height times the column count.
+	AvailableHeight float64
+
+	// FillRatio is UsedHeight / AvailableHeight (0 for frames without space).
+	FillRatio float64
+
+	// Overset is true for the last frame of the thread when text remains.
+	Overset bool
+}
+
+// OversetReport is the result of EstimateOverset.
+type OversetReport struct {
+	// StoryID is the story Self ID.
+	StoryID string
+
+	// Frames lists the thread's frames in reading order.
+	Frames []FrameFit
+
+	// TotalLines is the estimated line count of the whole story.
+	TotalLines int
+
+	// OversetLines is the estimated number of lines that do not fit.
+	OversetLines int
+
+	// Overset is true if the story does not fit into its frames.
+	Overset bool
+
+	// MissingFonts lists "Family Style" names that were not found in
+	// OversetOptions.Fonts and were measured with the fallback width.
+	MissingFonts []string
+}
+
+// EstimateOverset estimates whether a story fits into its threaded frames.
+//
+// The estimate combines each frame's TextCapacity (columns, gutters and
+// insets) with paragraph and character formatting: PointSize, Leading,
+// Tracking, HorizontalScale, SpaceBefore, SpaceAfter and indents, resolved
+// through the BasedOn chain. Text is wrapped greedily at spaces and measured
+// with the optional font metrics.
+//
+// The result is an approximation: hyphenation, justification, kerning,
+// baseline grids, tables and anchored objects are not taken into account.
+//
+// Example:
+//
+//	fonts, _ := fontmetrics.LoadDir("/Library/Fonts")
+//	report, err := pkg.EstimateOverset("u222", idml.OversetOptions{Fonts: fonts})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for _, f := range report.Frames {
+//	    fmt.Printf("%s: %d lines, %.0f%% full\n", f.FrameID, f.Lines, f.FillRatio*100)
+//	}
+//	if report.Overset {
+//	    fmt.Printf("%d lines overset\n", report.OversetLines)
+//	}
+func (p *Package) EstimateOverset(storyID string, opts OversetOptions) (*OversetReport, error) {
+	storyID = normalizeStoryID(storyID)
+
+	chain, err := p.TextFrameChain(storyID)
+	if err != nil {
+		return nil, common.WrapError("idml", "estimate overset", err)
+	}
+
+	st, err := p.Story(StoryPath(storyID))
+	if err != nil {
+		return nil, common.WrapError("idml", "estimate overset", err)
+	}
+
+	// Styles are optional; without them every paragraph uses the defaults
+	var styles *resources.StylesFile
+	if p.hasFile(PathStyles) {
+		if styles, err = p.Styles(); err != nil {
+			return nil, common.WrapError("idml", "estimate overset", err)
+		}
+	}
+
+	m := newTextMeasurer(styles, opts)
+	paragraphs := m.paragraphs(st)
+
+	report := &OversetReport{StoryID: storyID}
+	flow := &textFlow{paragraphs: paragraphs}
+
+	for _, f := range chain {
+		fit := FrameFit{
+			FrameID:        f.Frame.Self,
+			SpreadFilename: f.SpreadFilename,
+			Capacity:       f.Frame.TextCapacity(),
+		}
+
+		columns, width, height := frameColumns(f.Frame, fit.Capacity)
+		fit.AvailableHeight = height * float64(columns)
+		for c := 0; c < columns && !flow.done(); c++ {
+			lines, used := flow.fill(width, height)
+			fit.Lines += lines
+			fit.UsedHeight += used
+		}
+		if fit.AvailableHeight > 0 {
+			fit.FillRatio = fit.UsedHeight / fit.AvailableHeight
+		}
+
+		report.TotalLines += fit.Lines
+		report.Frames = append(report.Frames, fit)
+	}
+
+	if !flow.done() {
+		report.Overset = true
+		report.Frames[len(report.Frames)-1].Overset = true
+
+		// Count the remaining lines at the last frame's column width
+		_, width, _ := frameColumns(chain[len(chain)-1].Frame, report.Frames[len(report.Frames)-1].Capacity)
+		for !flow.done() {
+			lines, _ := flow.fill(width, -1)
+			if lines == 0 {
+				break
+			}
+			report.OversetLines += lines
+		}
+		report.TotalLines += report.OversetLines
+	}
+
+	report.MissingFonts = m.missingFonts()
+	return report, nil
+}
+
+// frameColumns returns the column count, column width and column height of a frame.
+func frameColumns(tf *spread.SpreadTextFrame, capacity *spread.TextCapacityInfo) (int, float64, float64) {
+	bounds, err := tf.Bounds()
+	if err != nil {
+		return 0, 0, 0
+	}
+
+	if capacity == nil {
+		return 1, bounds.Width, bounds.Height
+	}
+
+	height := bounds.Height - capacity.InsetSpacing[0] - capacity.InsetSpacing[2]
+	if capacity.ColumnWidth <= 0 || height <= 0 {
+		return 0, 0, 0
+	}
+	return capacity.ColumnCount, capacity.ColumnWidth, height
+}
+
+// ============================================================================
+// Formatting
+// ============================================================================
+
+// textFormat is the resolved formatting that affects text measurement.
+type textFormat struct {
+	font            string
+	fontStyle       string
+	pointSize       float64
+	leading         float64 // 0 means auto leading
+	tracking        float64 // 1/1000 em
+	horizontalScale float64 // percent
+}
+
+// lineHeight returns the leading of a line set in this format.
+func (f textFormat) lineHeight() float64 {
+	if f.leading > 0 {
+		return f.leading
+	}
+	return f.pointSize * defaultAutoLeading
+}
+
+// paragraphFormat adds paragraph-level spacing to textFormat.
+type paragraphFormat struct {
+	textFormat
+	spaceBefore     float64
+	spaceAfter      float64
+	leftIndent      float64
+	rightIndent     float64
+	firstLineIndent float64
+}
+
+// textMeasurer resolves styles and measures text runs.
+type textMeasurer struct {
+	styles    *resources.StylesFile
+	fonts     *fontmetrics.Collection
+	charWidth float64
+	missing   map[string]bool
+}
+
+// newTextMeasurer creates a measurer with defaults applied to opts.
+func newTextMeasurer(styles *resources.StylesFile, opts OversetOptions) *textMeasurer {
+	charWidth := opts.AverageCharWidth
+	if charWidth <= 0 {
+		charWidth = defaultAverageCharWidth
+	}
+	return &textMeasurer{
+		styles:    styles,
+		fonts:     opts.Fonts,
+		charWidth: charWidth,
+		missing:   make(map[string]bool),
+	}
+}
+
+// paragraphFormat resolves a paragraph style, applying BasedOn parents first.
+func (m *textMeasurer) paragraphFormat(styleID string) paragraphFormat {
+	pf := paragraphFormat{textFormat: textFormat{pointSize: defaultPointSize, horizontalScale: 100}}
+	if m.styles == nil {
+		return pf
+	}
+
+	var chain []*resources.ParagraphStyle
+	seen := make(map[string]bool)
+	for id := styleID; id != "" && !seen[id]; {
+		seen[id] = true
+		ps := m.styles.FindParagraphStyle(id)
+		if ps == nil {
+			break
+		}
+		chain = append(chain, ps)
+		id = qualifyStyleID("ParagraphStyle/", ps.Properties.GetBasedOn())
+	}
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		ps := chain[i]
+		setFloat(&pf.pointSize, ps.PointSize)
+		setFloat(&pf.tracking, ps.Tracking)
+		setFloat(&pf.spaceBefore, ps.SpaceBefore)
+		setFloat(&pf.spaceAfter, ps.SpaceAfter)
+		setFloat(&pf.leftIndent, ps.LeftIndent)
+		setFloat(&pf.rightIndent, ps.RightIndent)
+		setFloat(&pf.firstLineIndent, ps.FirstLineIndent)
+		if ps.FontStyle != "" {
+			pf.fontStyle = ps.FontStyle
+		}
+		if font := ps.Properties.GetAppliedFont(); font != "" {
+			pf.font = font
+		}
+		setLeading(&pf.leading, ps.Properties.GetLeading())
+	}
+	return pf
+}
+
+// runFormat applies a character style range on top of its paragraph format.
+func (m *textMeasurer) runFormat(base textFormat, csr *story.CharacterStyleRange) textFormat {
+	tf := base
+
+	if m.styles != nil {
+		var chain []*resources.CharacterStyle
+		seen := make(map[string]bool)
+		for id := csr.AppliedCharacterStyle; id != "" && !seen[id]; {
+			seen[id] = true
+			cs := m.styles.FindCharacterStyle(id)
+			if cs == nil {
+				break
+			}
+			chain = append(chain, cs)
+			id = qualifyStyleID("CharacterStyle/", cs.Properties.GetBasedOn())
+		}
+		for i := len(chain) - 1; i >= 0; i-- {
+			cs := chain[i]
+			setFloat(&tf.pointSize, cs.PointSize)
+			if cs.FontStyle != "" {
+				tf.fontStyle = cs.FontStyle
+			}
+			if font := cs.GetAppliedFont(); font != "" {
+				tf.font = font
+			}
+			setLeading(&tf.leading, cs.Properties.GetLeading())
+		}
+	}
+
+	// Local overrides, including AppliedFont and Leading from the range's
+	// Properties children
+	for name, value := range csr.LocalFormatting() {
+		switch name {
+		case "Tracking":
+			setFloat(&tf.tracking, value)
+		case "HorizontalScale":
+			setFloat(&tf.horizontalScale, value)
+		case "PointSize":
+			setFloat(&tf.pointSize, value)
+		case "FontStyle":
+			tf.fontStyle = value
+		case "AppliedFont":
+			tf.font = value
+		case "Leading":
+			setLeading(&tf.leading, value)
+		}
+	}
+	return tf
+}
+
+// width measures text in the given format, in points.
+func (m *textMeasurer) width(text string, tf textFormat) float64 {
+	runes := utf8.RuneCountInString(text)
+
+	var w float64
+	if font := m.lookupFont(tf); font != nil {
+		w = font.TextWidth(text, tf.pointSize)
+	} else {
+		w = float64(runes) * tf.pointSize * m.charWidth
+	}
+
+	w += float64(runes) * tf.pointSize * tf.tracking / 1000
+	return w * tf.horizontalScale / 100
+}
+
+// lookupFont finds the font for a format and records missing fonts.
+func (m *textMeasurer) lookupFont(tf textFormat) *fontmetrics.Font {
+	if m.fonts == nil || tf.font == "" {
+		return nil
+	}
+	font := m.fonts.Lookup(tf.font, tf.fontStyle)
+	if font == nil {
+		m.missing[strings.TrimSpace(tf.font+" "+tf.fontStyle)] = true
+	}
+	return font
+}
+
+// missingFonts returns the sorted names of fonts that were not found.
+func (m *textMeasurer) missingFonts() []string {
+	names := make([]string, 0, len(m.missing))
+	for name := range m.missing {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// qualifyStyleID adds the style type prefix to BasedOn values such as
+// "$ID/[No paragraph style]", which are stored without it.
+func qualifyStyleID(prefix, id string) string {
+	if id == "" || strings.HasPrefix(id, prefix) {
+		return id
+	}
+	return prefix + id
+}
+
+// setFloat parses value into dst, leaving dst unchanged if value is empty or invalid.
+func setFloat(dst *float64, value string) {
+	if v, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+		*dst = v
+	}
+}
+
+// setLeading parses a Leading value; "Auto" resets to auto leading.
+func setLeading(dst *float64, value string) {
+	if strings.EqualFold(value, "Auto") {
+		*dst = 0
+		return
+	}
+	setFloat(dst, value)
+}
+
+// ============================================================================
+// Line breaking
+// ============================================================================
+
+// textUnit is a word plus its trailing spaces; lines break between units.
+type textUnit struct {
+	width      float64 // width of the word
+	spaceWidth float64 // width of the trailing spaces
+	leading    float64 // largest leading of the runs in the unit
+}
+
+// layoutParagraph is a paragraph broken into units.
+type layoutParagraph struct {
+	format paragraphFormat
+	units  []textUnit
+}
+
+// paragraphs splits a story into paragraphs of measured units.
+// Paragraphs end at Br elements and at paragraph style range boundaries.
+func (m *textMeasurer) paragraphs(st *story.Story) []layoutParagraph {
+	var result []layoutParagraph
+
+	for i := range st.StoryElement.ParagraphStyleRanges {
+		psr := &st.StoryElement.ParagraphStyleRanges[i]
+		pf := m.paragraphFormat(psr.AppliedParagraphStyle)
+
+		current := layoutParagraph{format: pf}
+		var unit *textUnit
+		inSpace := false
+
+		for j := range psr.CharacterStyleRanges {
+			csr := &psr.CharacterStyleRanges[j]
+			tf := m.runFormat(pf.textFormat, csr)
+
+			for _, child := range csr.FlatChildren() {
+				switch {
+				case child.Br != nil:
+					result = append(result, current)
+					current = layoutParagraph{format: pf}
+					unit, inSpace = nil, false
+				case child.Content != nil:
+					for _, piece := range splitSpaces(child.Content.Text) {
+						r, _ := utf8.DecodeRuneInString(piece)
+						isSpace := unicode.IsSpace(r)
+						if unit == nil || (inSpace && !isSpace) {
+							current.units = append(current.units, textUnit{})
+							unit = &current.units[len(current.units)-1]
+						}
+						if isSpace {
+							unit.spaceWidth += m.width(piece, tf)
+						} else {
+							unit.width += m.width(piece, tf)
+						}
+						unit.leading = max(unit.leading, tf.lineHeight())
+						inSpace = isSpace
+					}
+				}
+			}
+		}
+
+		// A trailing Br already closed the last paragraph of this range
+		if len(current.units) > 0 || len(psr.CharacterStyleRanges) == 0 || !endsWithBr(psr) {
+			result = append(result, current)
+		}
+	}
+
+	return result
+}
+
+// endsWithBr reports whether the last child of a paragraph range is a Br.
+func endsWithBr(psr *story.ParagraphStyleRange) bool {
+	for i := len(psr.CharacterStyleRanges) - 1; i >= 0; i-- {
+		children := psr.CharacterStyleRanges[i].FlatChildren()
+		for j := len(children) - 1; j >= 0; j-- {
+			if children[j].Br != nil {
+				return true
+			}
+			if children[j].Content != nil && children[j].Content.Text != "" {
+				return false
+			}
+		}
+	}
+	return false
+}
+
+// splitSpaces splits text into alternating runs of whitespace and non-whitespace.
+func splitSpaces(text string) []string {
+	var pieces []string
+	start := 0
+	prevSpace := false
+	for i, r := range text {
+		isSpace := unicode.IsSpace(r)
+		if i > start && isSpace != prevSpace {
+			pieces = append(pieces, text[start:i])
+			start = i
+		}
+		prevSpace = isSpace
+	}
+	if start < len(text) {
+		pieces = append(pieces, text[start:])
+	}
+	return pieces
+}
+
+// textFlow tracks the position of the next line to set.
+type textFlow struct {
+	paragraphs []layoutParagraph
+	para       int  // index of the current paragraph
+	unit       int  // index of the next unit in the current paragraph
+	started    bool // whether a line of the current paragraph has been set
+}
+
+// done reports whether all text has been set.
+func (f *textFlow) done() bool {
+	return f.para >= len(f.paragraphs)
+}
+
+// fill sets lines into a column of the given width and height and returns
+// the number of lines and the height used. A negative height is unlimited.
+func (f *textFlow) fill(width, height float64) (int, float64) {
+	lines := 0
+	y := 0.0
+
+	for !f.done() {
+		p := &f.paragraphs[f.para]
+
+		// Space before is not applied at the top of a column
+		spaceBefore := 0.0
+		if !f.started && y > 0 {
+			spaceBefore = p.format.spaceBefore
+		}
+
+		available := width - p.format.leftIndent - p.format.rightIndent
+		if !f.started {
+			available -= p.format.firstLineIndent
+		}
+		next, leading := f.breakLine(p, available)
+
+		if height >= 0 && y+spaceBefore+leading > height {
+			break
+		}
+
+		y += spaceBefore + leading
+		lines++
+		f.unit = next
+		f.started = true
+
+		if f.unit >= len(p.units) {
+			y += p.format.spaceAfter
+			f.para++
+			f.unit = 0
+			f.started = false
+		}
+	}
+
+	if height >= 0 {
+		y = min(y, height)
+	}
+	return lines, y
+}
+
+// breakLine returns the index after the last unit that fits on the next line
+// and the line's leading. At least one unit is always placed.
+func (f *textFlow) breakLine(p *layoutParagraph, available float64) (int, float64) {
+	leading := p.format.lineHeight()
+	if len(p.units) == 0 {
+		return 0, leading
+	}
+
+	leading = 0
+	lineWidth := 0.0
+	i := f.unit
+	for ; i < len(p.units); i++ {
+		u := p.units[i]
+		if i > f.unit && lineWidth+u.width > available {
+			break
+		}
+		lineWidth += u.width + u.spaceWidth
+		leading = max(leading, u.leading)
+	}
+	return i, leading
+}
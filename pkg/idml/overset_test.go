@@ -0,0 +1,173 @@
+package idml
+
+import (
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/fontmetrics"
+	"github.com/dimelords/idmllib/v2/pkg/story"
+)
+
+// setStoryText replaces the text of a story in the package, keeping the
+// formatting of its first run.
+func setStoryText(t *testing.T, pkg *Package, storyID, text string) {
+	t.Helper()
+
+	st, err := pkg.Story(StoryPath(storyID))
+	if err != nil {
+		t.Fatalf("Story failed: %v", err)
+	}
+	if n := st.ReplaceText(st.ExtractText(), text); n != 1 {
+		t.Fatalf("ReplaceText replaced %d occurrences, want 1", n)
+	}
+	if err := pkg.UpdateStory(StoryPath(storyID), st, ValidationOptions{}); err != nil {
+		t.Fatalf("UpdateStory failed: %v", err)
+	}
+}
+
+// TestEstimateOverset tests the estimate for the threaded story in example.idml.
+func TestEstimateOverset(t *testing.T) {
+	pkg := loadExampleIDML(t)
+
+	report, err := pkg.EstimateOverset("u222", OversetOptions{})
+	if err != nil {
+		t.Fatalf("EstimateOverset failed: %v", err)
+	}
+
+	if len(report.Frames) != 2 || report.Frames[0].FrameID != "u234" || report.Frames[1].FrameID != "u24a" {
+		t.Fatalf("Frames = %+v, want u234 then u24a", report.Frames)
+	}
+	if report.Overset || report.OversetLines != 0 {
+		t.Errorf("story should fit, got %d overset lines", report.OversetLines)
+	}
+
+	first := report.Frames[0]
+	if first.Capacity == nil || first.Capacity.ColumnCount != 5 {
+		t.Errorf("Capacity = %+v, want 5 columns", first.Capacity)
+	}
+	if first.Lines == 0 || first.FillRatio <= 0 || first.FillRatio > 1 {
+		t.Errorf("first frame: %d lines, fill %.2f", first.Lines, first.FillRatio)
+	}
+	if report.TotalLines != first.Lines+report.Frames[1].Lines {
+		t.Errorf("TotalLines = %d, want sum of frame lines", report.TotalLines)
+	}
+	if len(report.MissingFonts) != 0 {
+		t.Errorf("MissingFonts = %v without a font collection", report.MissingFonts)
+	}
+
+	if _, err := pkg.EstimateOverset("missing", OversetOptions{}); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown story, got %v", err)
+	}
+}
+
+// TestEstimateOverset_Overset tests that too much text is reported as overset.
+func TestEstimateOverset_Overset(t *testing.T) {
+	pkg := loadExampleIDML(t)
+
+	// u260 holds about five lines of 12pt text
+	setStoryText(t, pkg, "u24e", strings.Repeat("Lorem ipsum dolor sit amet. ", 400))
+
+	report, err := pkg.EstimateOverset("u24e", OversetOptions{})
+	if err != nil {
+		t.Fatalf("EstimateOverset failed: %v", err)
+	}
+
+	if !report.Overset || report.OversetLines == 0 {
+		t.Fatalf("expected overset, got %+v", report)
+	}
+	last := report.Frames[len(report.Frames)-1]
+	if !last.Overset {
+		t.Error("last frame should be marked as overset")
+	}
+	if last.FillRatio < 0.8 {
+		t.Errorf("overset frame fill ratio = %.2f, want nearly full", last.FillRatio)
+	}
+	if report.TotalLines != last.Lines+report.OversetLines {
+		t.Errorf("TotalLines = %d, want %d", report.TotalLines, last.Lines+report.OversetLines)
+	}
+}
+
+// TestEstimateOverset_FontMetrics tests that loaded font metrics change the estimate.
+func TestEstimateOverset_FontMetrics(t *testing.T) {
+	pkg := loadExampleIDML(t)
+	setStoryText(t, pkg, "u24e", strings.Repeat("Lorem ipsum dolor sit amet ", 20))
+
+	// The fallback width of half an em fits the text
+	report, err := pkg.EstimateOverset("u24e", OversetOptions{})
+	if err != nil {
+		t.Fatalf("EstimateOverset failed: %v", err)
+	}
+	if report.Overset {
+		t.Fatalf("text should fit with the default width, got %d lines", report.TotalLines)
+	}
+
+	// A very wide font pushes it into overset
+	fonts := fontmetrics.NewCollection()
+	fonts.Add(&fontmetrics.Font{Family: "Flama Semicondensed", Style: "Book", UnitsPerEm: 1000, AverageAdvance: 1500})
+
+	wide, err := pkg.EstimateOverset("u24e", OversetOptions{Fonts: fonts})
+	if err != nil {
+		t.Fatalf("EstimateOverset failed: %v", err)
+	}
+	if !wide.Overset || wide.TotalLines <= report.TotalLines {
+		t.Errorf("wide font: %d lines (overset %v), default: %d lines",
+			wide.TotalLines, wide.Overset, report.TotalLines)
+	}
+	if len(wide.MissingFonts) != 0 {
+		t.Errorf("MissingFonts = %v", wide.MissingFonts)
+	}
+
+	// Fonts that are not in the collection are reported
+	missing, err := pkg.EstimateOverset("u24e", OversetOptions{Fonts: fontmetrics.NewCollection()})
+	if err != nil {
+		t.Fatalf("EstimateOverset failed: %v", err)
+	}
+	if len(missing.MissingFonts) != 1 || !strings.HasPrefix(missing.MissingFonts[0], "Flama Semicondensed") {
+		t.Errorf("MissingFonts = %v", missing.MissingFonts)
+	}
+}
+
+// TestEstimateOverset_LocalProperties tests that local leading and fonts,
+// which IDML keeps as Properties children of the range, are measured.
+func TestEstimateOverset_LocalProperties(t *testing.T) {
+	pkg := loadExampleIDML(t)
+	setStoryText(t, pkg, "u24e", strings.Repeat("Lorem ipsum dolor sit amet ", 20))
+
+	before, err := pkg.EstimateOverset("u24e", OversetOptions{})
+	if err != nil {
+		t.Fatalf("EstimateOverset failed: %v", err)
+	}
+
+	st, err := pkg.Story(StoryPath("u24e"))
+	if err != nil {
+		t.Fatalf("Story failed: %v", err)
+	}
+	var props common.RawXMLElement
+	if err := xml.Unmarshal([]byte(`<Properties><Leading type="unit">40</Leading><AppliedFont type="string">Local Font</AppliedFont></Properties>`), &props); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	for i := range st.StoryElement.ParagraphStyleRanges {
+		psr := &st.StoryElement.ParagraphStyleRanges[i]
+		for j := range psr.CharacterStyleRanges {
+			csr := &psr.CharacterStyleRanges[j]
+			csr.Children = append([]story.CharacterChild{{Other: &props}}, csr.Children...)
+		}
+	}
+	if err := pkg.UpdateStory(StoryPath("u24e"), st, ValidationOptions{}); err != nil {
+		t.Fatalf("UpdateStory failed: %v", err)
+	}
+
+	after, err := pkg.EstimateOverset("u24e", OversetOptions{Fonts: fontmetrics.NewCollection()})
+	if err != nil {
+		t.Fatalf("EstimateOverset failed: %v", err)
+	}
+	if after.Frames[0].UsedHeight <= before.Frames[0].UsedHeight {
+		t.Errorf("UsedHeight with 40pt leading = %.1f, without = %.1f", after.Frames[0].UsedHeight, before.Frames[0].UsedHeight)
+	}
+	if len(after.MissingFonts) != 1 || !strings.HasPrefix(after.MissingFonts[0], "Local Font") {
+		t.Errorf("MissingFonts = %v, want the local font", after.MissingFonts)
+	}
+}
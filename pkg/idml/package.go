@@ -62,6 +62,10 @@ type Package struct {
 	// Map key is the spread filename (e.g., "Spreads/Spread_u210.xml").
 	spreads map[string]*spread.Spread
 
+	// masterSpreads caches parsed MasterSpread files from the MasterSpreads/ directory.
+	// Map key is the master spread filename (e.g., "MasterSpreads/MasterSpread_ub4.xml").
+	masterSpreads map[string]*spread.MasterSpread
+
 	// resources caches parsed Resource files from the Resources/ directory.
 	// Map key is the resource filename (e.g., "Resources/Graphic.xml").
 	// This is the generic preservation-based parser.
@@ -76,6 +80,12 @@ type Package struct {
 	// styles caches the typed Styles.xml file (if parsed)
 	styles *resources.StylesFile
 
+	// tags caches the typed XML/Tags.xml file (if parsed)
+	tags *resources.TagsFile
+
+	// backingStory caches the typed XML/BackingStory.xml file (if parsed)
+	backingStory *story.BackingStory
+
 	// metadata caches optional metadata files (META-INF/*, XML/*).
 	// Map key is the file path (e.g., "META-INF/container.xml").
 	metadata map[string]*MetadataFile
@@ -93,11 +103,12 @@ type Package struct {
 // New creates a new empty IDML package.
 func New() *Package {
 	return &Package{
-		files:     make(map[string]*fileEntry),
-		stories:   make(map[string]*story.Story),
-		spreads:   make(map[string]*spread.Spread),
-		resources: make(map[string]*ResourceFile),
-		metadata:  make(map[string]*MetadataFile),
+		files:         make(map[string]*fileEntry),
+		stories:       make(map[string]*story.Story),
+		spreads:       make(map[string]*spread.Spread),
+		masterSpreads: make(map[string]*spread.MasterSpread),
+		resources:     make(map[string]*ResourceFile),
+		metadata:      make(map[string]*MetadataFile),
 	}
 }
 
@@ -242,6 +253,51 @@ func (p *Package) Spreads() (map[string]*spread.Spread, error) {
 	return spreads, nil
 }
 
+// MasterSpread returns a parsed MasterSpread from the MasterSpreads/ directory.
+// The master spread is parsed on first access and cached.
+// Returns an error if the file doesn't exist or can't be parsed.
+func (p *Package) MasterSpread(filename string) (*spread.MasterSpread, error) {
+	// Return cached master spread if available
+	if ms, cached := p.getCachedMasterSpread(filename); cached {
+		return ms, nil
+	}
+
+	// Get master spread file
+	entry, err := p.getFileEntry(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the master spread
+	ms, err := spread.ParseMasterSpread(entry.data)
+	if err != nil {
+		return nil, common.WrapErrorWithPath("idml", "parse master spread", filename, err)
+	}
+
+	// Cache for future calls
+	p.cacheMasterSpread(filename, ms)
+	return ms, nil
+}
+
+// MasterSpreads returns all parsed MasterSpread files from the MasterSpreads/ directory.
+// Master spreads are parsed on first access and cached.
+func (p *Package) MasterSpreads() (map[string]*spread.MasterSpread, error) {
+	masters := make(map[string]*spread.MasterSpread)
+
+	// Find all master spread files
+	for filename := range p.files {
+		if IsMasterSpreadPath(filename) {
+			ms, err := p.MasterSpread(filename)
+			if err != nil {
+				return nil, err
+			}
+			masters[filename] = ms
+		}
+	}
+
+	return masters, nil
+}
+
 // Resource returns a parsed Resource file from the Resources/ directory.
 // The resource is parsed on first access and cached.
 // Returns an error if the resource file doesn't exist or can't be parsed.
@@ -416,6 +472,59 @@ func (p *Package) Styles() (*resources.StylesFile, error) {
 	return styles, nil
 }
 
+// Tags returns the typed XML/Tags.xml file with the XML tag definitions.
+// The file is parsed on first access and cached.
+// Returns ErrNotFound if the package has no Tags.xml.
+func (p *Package) Tags() (*resources.TagsFile, error) {
+	// Return cached if available
+	if tags, cached := p.getCachedTags(); cached {
+		return tags, nil
+	}
+
+	// Get Tags.xml file
+	entry, err := p.getFileEntry(PathTags)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the tags file
+	tags, err := resources.ParseTagsFile(entry.data)
+	if err != nil {
+		return nil, common.WrapErrorWithPath("idml", "parse tags", PathTags, err)
+	}
+
+	// Cache for future calls
+	p.cacheTags(tags)
+	return tags, nil
+}
+
+// BackingStory returns the typed XML/BackingStory.xml file, which holds the
+// root of the XML structure.
+// The file is parsed on first access and cached.
+// Returns ErrNotFound if the package has no BackingStory.xml.
+func (p *Package) BackingStory() (*story.BackingStory, error) {
+	// Return cached if available
+	if bs, cached := p.getCachedBackingStory(); cached {
+		return bs, nil
+	}
+
+	// Get BackingStory.xml file
+	entry, err := p.getFileEntry(PathBackingStory)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the backing story
+	bs, err := story.ParseBackingStory(entry.data)
+	if err != nil {
+		return nil, common.WrapErrorWithPath("idml", "parse backing story", PathBackingStory, err)
+	}
+
+	// Cache for future calls
+	p.cacheBackingStory(bs)
+	return bs, nil
+}
+
 // SetFonts updates the cached fonts file.
 // The file will be marshaled when Write() is called.
 func (p *Package) SetFonts(fonts *resources.FontsFile) {
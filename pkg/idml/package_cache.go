@@ -23,6 +23,7 @@ func (p *Package) clearCache() {
 
 	// Clear spread cache
 	p.spreads = make(map[string]*spread.Spread)
+	p.masterSpreads = make(map[string]*spread.MasterSpread)
 
 	// Clear resource cache
 	p.resources = make(map[string]*ResourceFile)
@@ -32,6 +33,10 @@ func (p *Package) clearCache() {
 	p.graphics = nil
 	p.styles = nil
 
+	// Clear typed XML structure cache
+	p.tags = nil
+	p.backingStory = nil
+
 	// Clear metadata cache
 	p.metadata = make(map[string]*MetadataFile)
 
@@ -62,6 +67,16 @@ func (p *Package) invalidateCache(path string) {
 		// Also clear generic resource cache for this file
 		delete(p.resources, path)
 
+	case PathTags:
+		p.tags = nil
+		// Also clear generic metadata cache for this file
+		delete(p.metadata, path)
+
+	case PathBackingStory:
+		p.backingStory = nil
+		// Also clear generic metadata cache for this file
+		delete(p.metadata, path)
+
 	default:
 		// Handle story files
 		if IsStoryPath(path) {
@@ -79,6 +94,12 @@ func (p *Package) invalidateCache(path string) {
 			return
 		}
 
+		// Handle master spread files
+		if IsMasterSpreadPath(path) {
+			delete(p.masterSpreads, path)
+			return
+		}
+
 		// Handle resource files
 		if IsResourcePath(path) {
 			delete(p.resources, path)
@@ -117,8 +138,11 @@ func (p *Package) invalidateResourceCache() {
 }
 
 // invalidateMetadataCache clears all cached metadata objects.
+// This includes the typed XML structure files.
 func (p *Package) invalidateMetadataCache() {
 	p.metadata = make(map[string]*MetadataFile)
+	p.tags = nil
+	p.backingStory = nil
 }
 
 // invalidateIndex clears the page item index.
@@ -142,6 +166,7 @@ func (p *Package) getCacheStats() CacheStats {
 
 	// Spread cache
 	stats.SpreadsCached = len(p.spreads)
+	stats.MasterSpreadsCached = len(p.masterSpreads)
 
 	// Resource cache
 	stats.ResourcesCached = len(p.resources)
@@ -171,16 +196,17 @@ func (p *Package) getCacheStats() CacheStats {
 
 // CacheStats provides information about cached objects in a Package.
 type CacheStats struct {
-	DocumentCached  bool // Whether document is cached
-	StoriesCached   int  // Number of cached stories
-	SpreadsCached   int  // Number of cached spreads
-	ResourcesCached int  // Number of cached generic resources
-	FontsCached     bool // Whether typed fonts are cached
-	GraphicsCached  bool // Whether typed graphics are cached
-	StylesCached    bool // Whether typed styles are cached
-	MetadataCached  int  // Number of cached metadata files
-	IndexCached     bool // Whether page item index is cached
-	IndexedItems    int  // Number of items in the index
+	DocumentCached      bool // Whether document is cached
+	StoriesCached       int  // Number of cached stories
+	SpreadsCached       int  // Number of cached spreads
+	MasterSpreadsCached int  // Number of cached master spreads
+	ResourcesCached     int  // Number of cached generic resources
+	FontsCached         bool // Whether typed fonts are cached
+	GraphicsCached      bool // Whether typed graphics are cached
+	StylesCached        bool // Whether typed styles are cached
+	MetadataCached      int  // Number of cached metadata files
+	IndexCached         bool // Whether page item index is cached
+	IndexedItems        int  // Number of items in the index
 }
 
 // ensureCacheInitialized ensures all cache maps are initialized.
@@ -192,6 +218,9 @@ func (p *Package) ensureCacheInitialized() {
 	if p.spreads == nil {
 		p.spreads = make(map[string]*spread.Spread)
 	}
+	if p.masterSpreads == nil {
+		p.masterSpreads = make(map[string]*spread.MasterSpread)
+	}
 	if p.resources == nil {
 		p.resources = make(map[string]*ResourceFile)
 	}
@@ -212,6 +241,12 @@ func (p *Package) cacheSpread(filename string, sp *spread.Spread) {
 	p.spreads[filename] = sp
 }
 
+// cacheMasterSpread stores a parsed master spread in the cache.
+func (p *Package) cacheMasterSpread(filename string, ms *spread.MasterSpread) {
+	p.ensureCacheInitialized()
+	p.masterSpreads[filename] = ms
+}
+
 // cacheResource stores a parsed resource in the cache.
 func (p *Package) cacheResource(filename string, resource *ResourceFile) {
 	p.ensureCacheInitialized()
@@ -245,6 +280,16 @@ func (p *Package) cacheStyles(styles *resources.StylesFile) {
 	p.styles = styles
 }
 
+// cacheTags stores parsed XML tags in the cache.
+func (p *Package) cacheTags(tags *resources.TagsFile) {
+	p.tags = tags
+}
+
+// cacheBackingStory stores a parsed backing story in the cache.
+func (p *Package) cacheBackingStory(bs *story.BackingStory) {
+	p.backingStory = bs
+}
+
 // getCachedStory retrieves a cached story if it exists.
 func (p *Package) getCachedStory(filename string) (*story.Story, bool) {
 	if p.stories == nil {
@@ -263,6 +308,15 @@ func (p *Package) getCachedSpread(filename string) (*spread.Spread, bool) {
 	return sp, exists
 }
 
+// getCachedMasterSpread retrieves a cached master spread if it exists.
+func (p *Package) getCachedMasterSpread(filename string) (*spread.MasterSpread, bool) {
+	if p.masterSpreads == nil {
+		return nil, false
+	}
+	ms, exists := p.masterSpreads[filename]
+	return ms, exists
+}
+
 // getCachedResource retrieves a cached resource if it exists.
 func (p *Package) getCachedResource(filename string) (*ResourceFile, bool) {
 	if p.resources == nil {
@@ -300,3 +354,13 @@ func (p *Package) getCachedGraphics() (*resources.GraphicFile, bool) {
 func (p *Package) getCachedStyles() (*resources.StylesFile, bool) {
 	return p.styles, p.styles != nil
 }
+
+// getCachedTags retrieves cached XML tags if they exist.
+func (p *Package) getCachedTags() (*resources.TagsFile, bool) {
+	return p.tags, p.tags != nil
+}
+
+// getCachedBackingStory retrieves the cached backing story if it exists.
+func (p *Package) getCachedBackingStory() (*story.BackingStory, bool) {
+	return p.backingStory, p.backingStory != nil
+}
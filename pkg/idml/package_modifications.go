@@ -32,8 +32,9 @@ func (p *Package) validateStoryDependencies(st *story.Story, opts ValidationOpti
 
 	if missing.HasMissing() {
 		if opts.AutoAddMissing {
-			// Add missing resources
-			if err := rm.AddMissingResources(opts); err != nil {
+			// Add the resources missing for this story, which is not in the
+			// package yet
+			if err := rm.addMissing(missing, opts); err != nil {
 				return common.WrapErrorWithPath("idml", "add missing resources", filename, err)
 			}
 		} else if opts.FailOnMissing {
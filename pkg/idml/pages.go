@@ -0,0 +1,776 @@
+package idml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/document"
+	"github.com/dimelords/idmllib/v2/pkg/spread"
+)
+
+// packagingNamespace is the idPkg namespace used by designmap resource references.
+const packagingNamespace = "http://ns.adobe.com/AdobeInDesign/idml/1.0/packaging"
+
+// ═══════════════════════════════════════════════════════════════════════════
+// Spread Operations
+// ═══════════════════════════════════════════════════════════════════════════
+
+// AddSpread adds a new spread to the package.
+//
+// This operation:
+//  1. Validates the spread file and its page IDs don't already exist
+//  2. Lays out its pages around the spine and sets PageCount and BindingLocation
+//  3. Marshals the spread, so a failure leaves the package unchanged
+//  4. Inserts its ResourceRef in designmap.xml after afterSpread
+//  5. Adds the spread file
+//  6. Updates the Section page ranges
+//
+// afterSpread is the filename of an existing spread; if empty the new spread
+// becomes the first spread of the document.
+//
+// Example:
+//
+//	sp := &spread.Spread{InnerSpread: spread.SpreadElement{
+//	    Self:  "u300",
+//	    Pages: []spread.Page{{Self: "u301", GeometricBounds: "0 0 842 595", AppliedMaster: "ub4"}},
+//	}}
+//	err := pkg.AddSpread(idml.SpreadPath("u300"), sp, "Spreads/Spread_u210.xml")
+func (p *Package) AddSpread(filename string, sp *spread.Spread, afterSpread string) error {
+	const op = "add spread"
+
+	// Step 1: Validate the spread and its pages are new
+	if p.hasFile(filename) {
+		return common.WrapErrorWithPath("idml", op, filename, common.ErrAlreadyExists)
+	}
+	for i := range sp.InnerSpread.Pages {
+		if err := p.validatePageDoesNotExist(op, sp.InnerSpread.Pages[i].Self); err != nil {
+			return err
+		}
+	}
+	oldPages, err := p.documentPageIDs()
+	if err != nil {
+		return common.WrapErrorWithPath("idml", op, filename, err)
+	}
+
+	// Step 2: Lay out the pages
+	if sp.InnerSpread.ItemTransform == "" {
+		sp.InnerSpread.ItemTransform = "1 0 0 1 0 0"
+	}
+	if err := sp.InnerSpread.LayoutPages(); err != nil {
+		return common.WrapErrorWithPath("idml", op, filename, err)
+	}
+
+	// Step 3: Marshal the spread before anything is changed
+	var doc *document.Document
+	if p.hasFile(PathDesignmap) {
+		if doc, err = p.Document(); err != nil {
+			return common.WrapErrorWithPath("idml", op, filename, err)
+		}
+		if sp.DOMVersion == "" {
+			sp.DOMVersion = doc.DOMVersion
+		}
+	}
+	data, err := spread.MarshalSpread(sp)
+	if err != nil {
+		return common.WrapErrorWithPath("idml", op, filename, err)
+	}
+
+	// Step 4: Register the spread in designmap.xml
+	if doc != nil {
+		if err := insertSpreadRef(doc, filename, afterSpread); err != nil {
+			return common.WrapErrorWithPath("idml", op, filename, err)
+		}
+	} else if afterSpread != "" && !p.hasFile(afterSpread) {
+		return common.WrapErrorWithPath("idml", op, afterSpread, common.ErrNotFound)
+	}
+
+	// Step 5: Add the spread file
+	p.setFileData(filename, data)
+	p.cacheSpread(filename, sp)
+	p.invalidateIndex()
+
+	// Step 6: Update sections
+	return p.syncSections(op, oldPages)
+}
+
+// RemoveSpread removes a spread and its page items from the package.
+//
+// This operation:
+//  1. Validates the spread exists
+//  2. Removes the spread file and its ResourceRef in designmap.xml
+//  3. Updates the Section page ranges, moving a section that started on a
+//     removed page to the next remaining page
+//  4. Optionally removes orphaned resources (fonts, styles, colors)
+//
+// Stories displayed only by frames on the spread are kept.
+//
+// Example:
+//
+//	result, err := pkg.RemoveSpread("Spreads/Spread_u210.xml", true)
+func (p *Package) RemoveSpread(filename string, cleanup bool) (*CleanupResult, error) {
+	const op = "remove spread"
+
+	// Step 1: Validate the spread exists
+	if !IsSpreadPath(filename) || !p.hasFile(filename) {
+		return nil, common.WrapErrorWithPath("idml", op, filename, common.ErrNotFound)
+	}
+	oldPages, err := p.documentPageIDs()
+	if err != nil {
+		return nil, common.WrapErrorWithPath("idml", op, filename, err)
+	}
+
+	// Step 2: Remove the file and its reference
+	if err := p.removeSpreadFromPackage(filename); err != nil {
+		return nil, common.WrapErrorWithPath("idml", op, filename, err)
+	}
+
+	// Step 3: Update sections
+	if err := p.syncSections(op, oldPages); err != nil {
+		return nil, err
+	}
+
+	// Step 4: Cleanup orphaned resources if requested
+	return p.performCleanupIfRequested(cleanup)
+}
+
+// removeSpreadFromPackage removes a spread file, its cache entry and its
+// designmap.xml ResourceRef.
+func (p *Package) removeSpreadFromPackage(filename string) error {
+	if p.hasFile(PathDesignmap) {
+		doc, err := p.Document()
+		if err != nil {
+			return err
+		}
+		refs := doc.Spreads[:0]
+		for _, ref := range doc.Spreads {
+			if ref.Src != filename {
+				refs = append(refs, ref)
+			}
+		}
+		doc.Spreads = refs
+	}
+
+	p.invalidateCache(filename)
+	p.removeFile(filename)
+	return nil
+}
+
+// insertSpreadRef inserts a Spread ResourceRef after the reference to afterSpread,
+// or first if afterSpread is empty.
+func insertSpreadRef(doc *document.Document, filename, afterSpread string) error {
+	index := 0
+	if afterSpread != "" {
+		index = -1
+		for i, ref := range doc.Spreads {
+			if ref.Src == afterSpread {
+				index = i + 1
+				break
+			}
+		}
+		if index < 0 {
+			return fmt.Errorf("spread %q: %w", afterSpread, common.ErrNotFound)
+		}
+	}
+
+	ref := document.ResourceRef{
+		XMLName: xml.Name{Space: packagingNamespace, Local: "Spread"},
+		Src:     filename,
+	}
+	doc.Spreads = append(doc.Spreads, document.ResourceRef{})
+	copy(doc.Spreads[index+1:], doc.Spreads[index:])
+	doc.Spreads[index] = ref
+	return nil
+}
+
+// ═══════════════════════════════════════════════════════════════════════════
+// Page Operations
+// ═══════════════════════════════════════════════════════════════════════════
+
+// AddPage inserts a page into a spread.
+//
+// This operation:
+//  1. Validates the page ID is new and afterPage is in the spread
+//  2. Copies GeometricBounds and AppliedMaster from a neighbouring page if unset
+//  3. Inserts the page and lays out the spread, moving the items of pages that
+//     shift so they stay on their page
+//  4. Updates the Section page ranges
+//
+// afterPage is the Self ID of a page in the spread; if empty the new page
+// becomes the first page of the spread. A page inserted left of the spine
+// becomes a left-hand page, otherwise it is added on the right.
+//
+// Example:
+//
+//	page := &spread.Page{Self: "u400"}
+//	err := pkg.AddPage("Spreads/Spread_u210.xml", page, "u218")
+func (p *Package) AddPage(spreadFilename string, page *spread.Page, afterPage string) error {
+	const op = "add page"
+
+	// Step 1: Validate
+	sp, err := p.loadSpreadForModification(spreadFilename, op)
+	if err != nil {
+		return err
+	}
+	if err := p.validatePageDoesNotExist(op, page.Self); err != nil {
+		return err
+	}
+	s := &sp.InnerSpread
+	index, err := pageInsertIndex(s, afterPage)
+	if err != nil {
+		return common.WrapErrorWithPath("idml", op, spreadFilename, err)
+	}
+	oldPages, err := p.documentPageIDs()
+	if err != nil {
+		return common.WrapErrorWithPath("idml", op, spreadFilename, err)
+	}
+
+	// Step 2: Inherit size and master from a neighbour
+	newPage := *page
+	if len(s.Pages) > 0 {
+		neighbour := s.Pages[max(index-1, 0)]
+		if newPage.GeometricBounds == "" {
+			newPage.GeometricBounds = neighbour.GeometricBounds
+		}
+		if newPage.AppliedMaster == "" {
+			newPage.AppliedMaster = neighbour.AppliedMaster
+		}
+	}
+
+	// Step 3: Insert and lay out
+	layout, err := capturePageLayout(s)
+	if err != nil {
+		return common.WrapErrorWithPath("idml", op, spreadFilename, err)
+	}
+	if index < s.BindingIndex() {
+		s.BindingLocation = strconv.Itoa(s.BindingIndex() + 1)
+	}
+	s.Pages = insertPage(s.Pages, index, newPage)
+	if err := layout.apply(s); err != nil {
+		return common.WrapErrorWithPath("idml", op, spreadFilename, err)
+	}
+	if err := p.marshalAndUpdateSpread(spreadFilename, sp); err != nil {
+		return err
+	}
+
+	// Step 4: Update sections
+	return p.syncSections(op, oldPages)
+}
+
+// RemovePage removes a page and the page items on it.
+//
+// This operation:
+//  1. Finds the page in the document spreads
+//  2. Removes the page and the items whose center lies on it
+//  3. Lays out the spread again, moving the items of pages that shift;
+//     a spread without pages left is removed entirely
+//  4. Updates the Section page ranges
+//  5. Optionally removes orphaned resources (fonts, styles, colors)
+//
+// Example:
+//
+//	result, err := pkg.RemovePage("u218", false)
+func (p *Package) RemovePage(pageID string, cleanup bool) (*CleanupResult, error) {
+	const op = "remove page"
+
+	// Step 1: Find the page
+	filename, sp, index, err := p.findDocumentPage(op, pageID)
+	if err != nil {
+		return nil, err
+	}
+	oldPages, err := p.documentPageIDs()
+	if err != nil {
+		return nil, common.WrapErrorWithPath("idml", op, filename, err)
+	}
+	s := &sp.InnerSpread
+
+	if len(s.Pages) == 1 {
+		// Step 3: Remove the empty spread
+		if err := p.removeSpreadFromPackage(filename); err != nil {
+			return nil, common.WrapErrorWithPath("idml", op, filename, err)
+		}
+	} else {
+		// Step 2: Remove the page and its items
+		layout, err := capturePageLayout(s)
+		if err != nil {
+			return nil, common.WrapErrorWithPath("idml", op, filename, err)
+		}
+		takePageItems(s, layout[pageID].items)
+		if index < s.BindingIndex() {
+			s.BindingLocation = strconv.Itoa(s.BindingIndex() - 1)
+		}
+		s.Pages = append(s.Pages[:index], s.Pages[index+1:]...)
+
+		// Step 3: Lay out the remaining pages
+		if err := layout.apply(s); err != nil {
+			return nil, common.WrapErrorWithPath("idml", op, filename, err)
+		}
+		if err := p.marshalAndUpdateSpread(filename, sp); err != nil {
+			return nil, err
+		}
+		p.invalidateIndex()
+	}
+
+	// Step 4: Update sections
+	if err := p.syncSections(op, oldPages); err != nil {
+		return nil, err
+	}
+
+	// Step 5: Cleanup orphaned resources if requested
+	return p.performCleanupIfRequested(cleanup)
+}
+
+// MovePage moves a page, together with the page items on it, to a new position.
+//
+// This operation:
+//  1. Finds the page and validates the target position
+//  2. Removes the page from its spread and inserts it into spreadFilename
+//     after afterPage (or first if afterPage is empty)
+//  3. Moves the items on the page along with it, and lays out both spreads;
+//     a source spread without pages left is removed
+//  4. Updates the Section page ranges (a section starting on the page moves with it)
+//
+// Example:
+//
+//	// Make u218 the first page of its spread
+//	err := pkg.MovePage("u218", "Spreads/Spread_u210.xml", "")
+func (p *Package) MovePage(pageID, spreadFilename, afterPage string) error {
+	const op = "move page"
+
+	if pageID == afterPage {
+		return common.Errorf("idml", op, spreadFilename, "cannot move page %q after itself", pageID)
+	}
+
+	// Step 1: Find the page and the target
+	srcFilename, src, srcIndex, err := p.findDocumentPage(op, pageID)
+	if err != nil {
+		return err
+	}
+	dst, err := p.loadSpreadForModification(spreadFilename, op)
+	if err != nil {
+		return err
+	}
+	if _, err := pageInsertIndex(&dst.InnerSpread, afterPage); err != nil {
+		return common.WrapErrorWithPath("idml", op, spreadFilename, err)
+	}
+	oldPages, err := p.documentPageIDs()
+	if err != nil {
+		return common.WrapErrorWithPath("idml", op, spreadFilename, err)
+	}
+
+	s, d := &src.InnerSpread, &dst.InnerSpread
+	srcLayout, err := capturePageLayout(s)
+	if err != nil {
+		return common.WrapErrorWithPath("idml", op, srcFilename, err)
+	}
+	dstLayout := srcLayout
+	if src != dst {
+		if dstLayout, err = capturePageLayout(d); err != nil {
+			return common.WrapErrorWithPath("idml", op, spreadFilename, err)
+		}
+		dstLayout[pageID] = srcLayout[pageID]
+	}
+
+	// Step 2: Take the page (and its items) out of the source spread.
+	// Reordering within a spread keeps the binding, so pages swap sides.
+	page := s.Pages[srcIndex]
+	binding := s.BindingIndex()
+	s.Pages = append(s.Pages[:srcIndex], s.Pages[srcIndex+1:]...)
+	if src != dst {
+		if srcIndex < binding {
+			s.BindingLocation = strconv.Itoa(binding - 1)
+		}
+		appendPageItems(d, takePageItems(s, srcLayout[pageID].items))
+	}
+
+	// Step 3: Insert it into the target spread and lay out
+	index, _ := pageInsertIndex(d, afterPage)
+	if src != dst && index < d.BindingIndex() {
+		d.BindingLocation = strconv.Itoa(d.BindingIndex() + 1)
+	}
+	d.Pages = insertPage(d.Pages, index, page)
+	if err := dstLayout.apply(d); err != nil {
+		return common.WrapErrorWithPath("idml", op, spreadFilename, err)
+	}
+	if err := p.marshalAndUpdateSpread(spreadFilename, dst); err != nil {
+		return err
+	}
+
+	if src != dst {
+		if len(s.Pages) == 0 {
+			if err := p.removeSpreadFromPackage(srcFilename); err != nil {
+				return common.WrapErrorWithPath("idml", op, srcFilename, err)
+			}
+		} else {
+			if err := srcLayout.apply(s); err != nil {
+				return common.WrapErrorWithPath("idml", op, srcFilename, err)
+			}
+			if err := p.marshalAndUpdateSpread(srcFilename, src); err != nil {
+				return err
+			}
+		}
+		p.invalidateIndex()
+	}
+
+	// Step 4: Update sections
+	return p.syncSections(op, oldPages)
+}
+
+// ResizePage changes the size of a page.
+//
+// The page keeps its top-left corner in its own coordinates; the spread is laid
+// out again and the items on every page that shifts move along with it.
+//
+// Example:
+//
+//	// A4 portrait
+//	err := pkg.ResizePage("u217", 595.276, 841.89)
+func (p *Package) ResizePage(pageID string, width, height float64) error {
+	const op = "resize page"
+
+	if width <= 0 || height <= 0 {
+		return common.Errorf("idml", op, "", "invalid page size %gx%g", width, height)
+	}
+
+	filename, sp, index, err := p.findDocumentPage(op, pageID)
+	if err != nil {
+		return err
+	}
+	s := &sp.InnerSpread
+
+	layout, err := capturePageLayout(s)
+	if err != nil {
+		return common.WrapErrorWithPath("idml", op, filename, err)
+	}
+
+	rect := layout[pageID].bounds
+	s.Pages[index].GeometricBounds = fmt.Sprintf("%s %s %s %s",
+		formatCoordinate(rect.Top), formatCoordinate(rect.Left),
+		formatCoordinate(rect.Top+height), formatCoordinate(rect.Left+width))
+
+	if err := layout.apply(s); err != nil {
+		return common.WrapErrorWithPath("idml", op, filename, err)
+	}
+	return p.marshalAndUpdateSpread(filename, sp)
+}
+
+// ═══════════════════════════════════════════════════════════════════════════
+// Helpers
+// ═══════════════════════════════════════════════════════════════════════════
+
+// pagePlacement records where a page was and which items were on it.
+type pagePlacement struct {
+	rect   spread.Rect // page rectangle in spread coordinates
+	bounds spread.Rect // page GeometricBounds in page coordinates
+	items  map[string]bool
+}
+
+// pageLayout maps page Self IDs to their placement before a change.
+type pageLayout map[string]pagePlacement
+
+// capturePageLayout records the position of every page of a spread and
+// assigns each page item to the page its center lies on.
+func capturePageLayout(s *spread.SpreadElement) (pageLayout, error) {
+	layout := make(pageLayout, len(s.Pages))
+	assigned := make(map[string]bool)
+	items := collectPageItems(s.TextFrames, s.Rectangles, s.Ovals, s.Polygons, s.GraphicLines, s.Groups)
+
+	for i := range s.Pages {
+		page := &s.Pages[i]
+		rect, err := page.SpreadRect()
+		if err != nil {
+			return nil, fmt.Errorf("page %q: %w", page.Self, err)
+		}
+		bounds, err := parseRect(page.GeometricBounds)
+		if err != nil {
+			return nil, fmt.Errorf("page %q: %w", page.Self, err)
+		}
+
+		placement := pagePlacement{rect: rect, bounds: bounds, items: make(map[string]bool)}
+		for _, item := range items {
+			id := item.GetSelf()
+			if center, ok := pageItemCenter(item); ok && !assigned[id] && rect.Contains(center) {
+				placement.items[id] = true
+				assigned[id] = true
+			}
+		}
+		layout[page.Self] = placement
+	}
+	return layout, nil
+}
+
+// apply lays out the spread's pages and translates the items of every page
+// whose top-left corner moved, so items keep their position on the page.
+func (l pageLayout) apply(s *spread.SpreadElement) error {
+	if err := s.LayoutPages(); err != nil {
+		return err
+	}
+
+	items := collectPageItems(s.TextFrames, s.Rectangles, s.Ovals, s.Polygons, s.GraphicLines, s.Groups)
+	for i := range s.Pages {
+		old, ok := l[s.Pages[i].Self]
+		if !ok || len(old.items) == 0 {
+			continue
+		}
+		rect, err := s.Pages[i].SpreadRect()
+		if err != nil {
+			return err
+		}
+		dx, dy := rect.Left-old.rect.Left, rect.Top-old.rect.Top
+		if dx == 0 && dy == 0 {
+			continue
+		}
+		for _, item := range items {
+			if !old.items[item.GetSelf()] {
+				continue
+			}
+			if t, ok := item.(interface{ Translate(dx, dy float64) error }); ok {
+				if err := t.Translate(dx, dy); err != nil {
+					return fmt.Errorf("item %q: %w", item.GetSelf(), err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// takePageItems removes the items with the given IDs from the spread and
+// returns them in a SpreadElement used as a container.
+func takePageItems(s *spread.SpreadElement, ids map[string]bool) *spread.SpreadElement {
+	taken := &spread.SpreadElement{}
+	s.TextFrames, taken.TextFrames = splitPageItems(s.TextFrames, func(v *spread.SpreadTextFrame) bool { return ids[v.Self] })
+	s.Rectangles, taken.Rectangles = splitPageItems(s.Rectangles, func(v *spread.Rectangle) bool { return ids[v.Self] })
+	s.Ovals, taken.Ovals = splitPageItems(s.Ovals, func(v *spread.Oval) bool { return ids[v.Self] })
+	s.Polygons, taken.Polygons = splitPageItems(s.Polygons, func(v *spread.Polygon) bool { return ids[v.Self] })
+	s.GraphicLines, taken.GraphicLines = splitPageItems(s.GraphicLines, func(v *spread.GraphicLine) bool { return ids[v.Self] })
+	s.Groups, taken.Groups = splitPageItems(s.Groups, func(v *spread.Group) bool { return ids[v.Self] })
+	return taken
+}
+
+// appendPageItems appends the items held in src to dst.
+func appendPageItems(dst, src *spread.SpreadElement) {
+	dst.TextFrames = append(dst.TextFrames, src.TextFrames...)
+	dst.Rectangles = append(dst.Rectangles, src.Rectangles...)
+	dst.Ovals = append(dst.Ovals, src.Ovals...)
+	dst.Polygons = append(dst.Polygons, src.Polygons...)
+	dst.GraphicLines = append(dst.GraphicLines, src.GraphicLines...)
+	dst.Groups = append(dst.Groups, src.Groups...)
+}
+
+// splitPageItems partitions items into the ones to keep and the ones taken.
+func splitPageItems[T any](items []T, take func(*T) bool) (kept, taken []T) {
+	for i := range items {
+		if take(&items[i]) {
+			taken = append(taken, items[i])
+		} else {
+			kept = append(kept, items[i])
+		}
+	}
+	return kept, taken
+}
+
+// insertPage inserts a page at index.
+func insertPage(pages []spread.Page, index int, page spread.Page) []spread.Page {
+	pages = append(pages, spread.Page{})
+	copy(pages[index+1:], pages[index:])
+	pages[index] = page
+	return pages
+}
+
+// pageInsertIndex returns the index a page inserted after afterPage gets.
+func pageInsertIndex(s *spread.SpreadElement, afterPage string) (int, error) {
+	if afterPage == "" {
+		return 0, nil
+	}
+	for i := range s.Pages {
+		if s.Pages[i].Self == afterPage {
+			return i + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("page %q: %w", afterPage, common.ErrNotFound)
+}
+
+// findDocumentPage finds a page in the document spreads.
+func (p *Package) findDocumentPage(op, pageID string) (string, *spread.Spread, int, error) {
+	filenames, err := p.orderedSpreadFilenames()
+	if err != nil {
+		return "", nil, 0, common.WrapError("idml", op, err)
+	}
+	for _, filename := range filenames {
+		sp, err := p.loadSpreadForModification(filename, op)
+		if err != nil {
+			return "", nil, 0, err
+		}
+		for i := range sp.InnerSpread.Pages {
+			if sp.InnerSpread.Pages[i].Self == pageID {
+				return filename, sp, i, nil
+			}
+		}
+	}
+	return "", nil, 0, common.WrapError("idml", op, fmt.Errorf("page %q: %w", pageID, common.ErrNotFound))
+}
+
+// validatePageDoesNotExist checks that no spread or master spread has a page with the ID.
+func (p *Package) validatePageDoesNotExist(op, pageID string) error {
+	if pageID == "" {
+		return common.Errorf("idml", op, "", "page has no Self ID")
+	}
+
+	_, masters, err := p.pageContainers()
+	if err != nil {
+		return common.WrapError("idml", op, err)
+	}
+	pages, err := p.documentPageIDs()
+	if err != nil {
+		return common.WrapError("idml", op, err)
+	}
+	for _, m := range masters {
+		for i := range m.pages {
+			pages = append(pages, m.pages[i].Self)
+		}
+	}
+
+	for _, id := range pages {
+		if id == pageID {
+			return common.WrapError("idml", op, fmt.Errorf("page %q: %w", pageID, common.ErrAlreadyExists))
+		}
+	}
+	return nil
+}
+
+// documentPageIDs returns the Self IDs of all document pages in reading order.
+func (p *Package) documentPageIDs() ([]string, error) {
+	filenames, err := p.orderedSpreadFilenames()
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, filename := range filenames {
+		sp, err := p.Spread(filename)
+		if err != nil {
+			return nil, err
+		}
+		for i := range sp.InnerSpread.Pages {
+			ids = append(ids, sp.InnerSpread.Pages[i].Self)
+		}
+	}
+	return ids, nil
+}
+
+// syncSections updates the designmap.xml Sections after pages were added,
+// removed or moved. oldPages is the page order before the change.
+//
+// A section whose start page was removed moves to the next remaining page
+// (or is dropped if there is none), the first section always starts on the
+// first page, and every Length is recomputed from the section starts.
+func (p *Package) syncSections(op string, oldPages []string) error {
+	if !p.hasFile(PathDesignmap) {
+		return nil
+	}
+	doc, err := p.Document()
+	if err != nil {
+		return common.WrapError("idml", op, err)
+	}
+	pages, err := p.documentPageIDs()
+	if err != nil {
+		return common.WrapError("idml", op, err)
+	}
+	if len(doc.Sections) == 0 || len(pages) == 0 {
+		return nil
+	}
+
+	position := make(map[string]int, len(pages))
+	for i, id := range pages {
+		position[id] = i
+	}
+
+	type sectionStart struct {
+		section  document.Section
+		index    int
+		replaced bool
+	}
+	var starts []sectionStart
+	for _, section := range doc.Sections {
+		index, ok := position[section.PageStart]
+		replaced := false
+		if !ok {
+			index, ok = nextRemainingPage(oldPages, section.PageStart, position)
+			replaced = true
+		}
+		if ok {
+			starts = append(starts, sectionStart{section: section, index: index, replaced: replaced})
+		}
+	}
+	if len(starts) == 0 {
+		// Every section start was removed; keep the first section for the remaining pages
+		starts = append(starts, sectionStart{section: doc.Sections[0]})
+	}
+
+	// Order by start page; on a shared start prefer the section that was there already
+	sort.SliceStable(starts, func(i, j int) bool {
+		if starts[i].index != starts[j].index {
+			return starts[i].index < starts[j].index
+		}
+		return !starts[i].replaced && starts[j].replaced
+	})
+	starts[0].index = 0
+
+	sections := make([]document.Section, 0, len(starts))
+	for i, s := range starts {
+		if i > 0 && s.index == starts[i-1].index {
+			continue
+		}
+		sections = append(sections, s.section)
+		sections[len(sections)-1].PageStart = pages[s.index]
+	}
+
+	// Recompute lengths from the section starts
+	for i := range sections {
+		end := len(pages)
+		if i+1 < len(sections) {
+			end = position[sections[i+1].PageStart]
+		}
+		length := strconv.Itoa(end - position[sections[i].PageStart])
+		sections[i].Length = length
+		if sections[i].AlternateLayoutLength != "" {
+			sections[i].AlternateLayoutLength = length
+		}
+	}
+
+	doc.Sections = sections
+	return nil
+}
+
+// nextRemainingPage finds the first page at or after removedID in oldPages
+// that still exists, returning its new position.
+func nextRemainingPage(oldPages []string, removedID string, position map[string]int) (int, bool) {
+	for i, id := range oldPages {
+		if id != removedID {
+			continue
+		}
+		for _, next := range oldPages[i+1:] {
+			if index, ok := position[next]; ok {
+				return index, true
+			}
+		}
+		break
+	}
+	return 0, false
+}
+
+// parseRect parses GeometricBounds ("y1 x1 y2 x2") into a Rect.
+func parseRect(geometricBounds string) (spread.Rect, error) {
+	var r spread.Rect
+	if _, err := fmt.Sscanf(geometricBounds, "%g %g %g %g", &r.Top, &r.Left, &r.Bottom, &r.Right); err != nil {
+		return spread.Rect{}, fmt.Errorf("invalid GeometricBounds %q: %w", geometricBounds, err)
+	}
+	return r, nil
+}
+
+// formatCoordinate formats a coordinate without exponent notation.
+func formatCoordinate(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
@@ -0,0 +1,317 @@
+package idml
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/spread"
+)
+
+const exampleSpread = "Spreads/Spread_u210.xml"
+
+// pageIDs returns the page IDs of a spread.
+func pageIDs(t *testing.T, pkg *Package, filename string) []string {
+	t.Helper()
+
+	sp, err := pkg.Spread(filename)
+	if err != nil {
+		t.Fatalf("Spread failed: %v", err)
+	}
+	var ids []string
+	for _, page := range sp.InnerSpread.Pages {
+		ids = append(ids, page.Self)
+	}
+	return ids
+}
+
+// pageLeft returns the left edge of a page in spread coordinates.
+func pageLeft(t *testing.T, pkg *Package, filename, pageID string) float64 {
+	t.Helper()
+
+	sp, err := pkg.Spread(filename)
+	if err != nil {
+		t.Fatalf("Spread failed: %v", err)
+	}
+	for i := range sp.InnerSpread.Pages {
+		if sp.InnerSpread.Pages[i].Self == pageID {
+			rect, err := sp.InnerSpread.Pages[i].SpreadRect()
+			if err != nil {
+				t.Fatalf("SpreadRect failed: %v", err)
+			}
+			return rect.Left
+		}
+	}
+	t.Fatalf("page %s not found in %s", pageID, filename)
+	return 0
+}
+
+// ownItemCenters returns the centers of the items placed on a page.
+func ownItemCenters(t *testing.T, pkg *Package, pageID string) map[string]spread.Position {
+	t.Helper()
+
+	items, err := pkg.EffectiveItemsForPage(pageID)
+	if err != nil {
+		t.Fatalf("EffectiveItemsForPage failed: %v", err)
+	}
+	centers := make(map[string]spread.Position)
+	for _, e := range items {
+		if center, ok := pageItemCenter(e.Item); ok && !e.Inherited() {
+			centers[e.Item.GetSelf()] = center
+		}
+	}
+	return centers
+}
+
+// assertSection checks the first section of the document.
+func assertSection(t *testing.T, pkg *Package, wantStart, wantLength string) {
+	t.Helper()
+
+	doc, err := pkg.Document()
+	if err != nil {
+		t.Fatalf("Document failed: %v", err)
+	}
+	if len(doc.Sections) == 0 {
+		t.Fatal("document has no sections")
+	}
+	s := doc.Sections[0]
+	if s.PageStart != wantStart || s.Length != wantLength {
+		t.Errorf("section = start %s, length %s; want %s, %s", s.PageStart, s.Length, wantStart, wantLength)
+	}
+}
+
+func nearlyEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+// TestAddPage tests inserting a page right of the spine.
+func TestAddPage(t *testing.T) {
+	pkg := loadExampleIDML(t)
+	before := ownItemCenters(t, pkg, "u218")
+	if len(before) == 0 {
+		t.Fatal("expected items on page u218")
+	}
+
+	if err := pkg.AddPage(exampleSpread, &spread.Page{Self: "uf01", Name: "new"}, "u217"); err != nil {
+		t.Fatalf("AddPage failed: %v", err)
+	}
+
+	if got := pageIDs(t, pkg, exampleSpread); !equalIDs(got, []string{"u217", "uf01", "u218"}) {
+		t.Fatalf("pages = %v", got)
+	}
+	sp, _ := pkg.Spread(exampleSpread)
+	if sp.InnerSpread.PageCount != "3" || sp.InnerSpread.BindingLocation != "1" {
+		t.Errorf("PageCount %s, BindingLocation %s", sp.InnerSpread.PageCount, sp.InnerSpread.BindingLocation)
+	}
+	added := sp.InnerSpread.Pages[1]
+	if added.GeometricBounds != sp.InnerSpread.Pages[0].GeometricBounds || added.AppliedMaster != "n" {
+		t.Errorf("new page did not inherit bounds/master: %q %q", added.GeometricBounds, added.AppliedMaster)
+	}
+
+	width := 793.7007874015749
+	if left := pageLeft(t, pkg, exampleSpread, "uf01"); !nearlyEqual(left, 0) {
+		t.Errorf("new page left = %v, want 0", left)
+	}
+	if left := pageLeft(t, pkg, exampleSpread, "u218"); !nearlyEqual(left, width) {
+		t.Errorf("u218 left = %v, want %v", left, width)
+	}
+
+	// Items follow u218 to its new position
+	after := ownItemCenters(t, pkg, "u218")
+	for id, c := range before {
+		if moved, ok := after[id]; !ok || !nearlyEqual(moved.X, c.X+width) || !nearlyEqual(moved.Y, c.Y) {
+			t.Errorf("item %s: before %+v, after %+v", id, c, moved)
+		}
+	}
+
+	assertSection(t, pkg, "u217", "3")
+}
+
+// TestAddPage_Errors tests invalid page insertions.
+func TestAddPage_Errors(t *testing.T) {
+	pkg := loadExampleIDML(t)
+
+	if err := pkg.AddPage(exampleSpread, &spread.Page{Self: "u217"}, ""); !errors.Is(err, common.ErrAlreadyExists) {
+		t.Errorf("duplicate page: got %v, want ErrAlreadyExists", err)
+	}
+	if err := pkg.AddPage(exampleSpread, &spread.Page{Self: "uf01"}, "missing"); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("unknown afterPage: got %v, want ErrNotFound", err)
+	}
+	if err := pkg.AddPage("Spreads/Spread_missing.xml", &spread.Page{Self: "uf01"}, ""); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("unknown spread: got %v, want ErrNotFound", err)
+	}
+	if err := pkg.ResizePage("u217", -1, 100); err == nil {
+		t.Error("ResizePage with negative width should fail")
+	}
+}
+
+// TestRemovePage tests removing a page with its items.
+func TestRemovePage(t *testing.T) {
+	pkg := loadExampleIDML(t)
+	removed := ownItemCenters(t, pkg, "u217")
+
+	if _, err := pkg.RemovePage("u217", false); err != nil {
+		t.Fatalf("RemovePage failed: %v", err)
+	}
+
+	sp, _ := pkg.Spread(exampleSpread)
+	if sp.InnerSpread.PageCount != "1" || sp.InnerSpread.BindingLocation != "0" {
+		t.Errorf("PageCount %s, BindingLocation %s", sp.InnerSpread.PageCount, sp.InnerSpread.BindingLocation)
+	}
+	for id := range removed {
+		if _, err := pkg.SelectPageItemByID(id); err == nil {
+			t.Errorf("item %s on removed page still exists", id)
+		}
+	}
+
+	// The section moves to the next page
+	assertSection(t, pkg, "u218", "1")
+
+	// Removing the last page removes the spread
+	if _, err := pkg.RemovePage("u218", false); err != nil {
+		t.Fatalf("RemovePage failed: %v", err)
+	}
+	if pkg.hasFile(exampleSpread) {
+		t.Error("empty spread should be removed")
+	}
+	doc, _ := pkg.Document()
+	if len(doc.Spreads) != 0 {
+		t.Errorf("designmap still references %d spreads", len(doc.Spreads))
+	}
+}
+
+// TestMovePage tests reordering pages within a spread.
+func TestMovePage(t *testing.T) {
+	pkg := loadExampleIDML(t)
+	before := ownItemCenters(t, pkg, "u218")
+
+	if err := pkg.MovePage("u218", exampleSpread, ""); err != nil {
+		t.Fatalf("MovePage failed: %v", err)
+	}
+
+	if got := pageIDs(t, pkg, exampleSpread); !equalIDs(got, []string{"u218", "u217"}) {
+		t.Fatalf("pages = %v", got)
+	}
+	width := 793.7007874015749
+	if left := pageLeft(t, pkg, exampleSpread, "u218"); !nearlyEqual(left, -width) {
+		t.Errorf("u218 left = %v, want %v", left, -width)
+	}
+
+	after := ownItemCenters(t, pkg, "u218")
+	for id, c := range before {
+		if moved, ok := after[id]; !ok || !nearlyEqual(moved.X, c.X-width) {
+			t.Errorf("item %s: before %+v, after %+v", id, c, moved)
+		}
+	}
+
+	assertSection(t, pkg, "u218", "2")
+
+	if err := pkg.MovePage("u218", exampleSpread, "u218"); err == nil {
+		t.Error("moving a page after itself should fail")
+	}
+}
+
+// TestAddSpread tests adding a spread and moving a page into it.
+func TestAddSpread(t *testing.T) {
+	pkg := loadExampleIDML(t)
+	filename := SpreadPath("uf00")
+
+	sp := &spread.Spread{InnerSpread: spread.SpreadElement{
+		Self:  "uf00",
+		Pages: []spread.Page{{Self: "uf01", GeometricBounds: "0 0 1133.8582677165355 793.7007874015749", AppliedMaster: "n"}},
+	}}
+	if err := pkg.AddSpread(filename, sp, exampleSpread); err != nil {
+		t.Fatalf("AddSpread failed: %v", err)
+	}
+	if err := pkg.AddSpread(filename, sp, exampleSpread); !errors.Is(err, common.ErrAlreadyExists) {
+		t.Errorf("duplicate spread: got %v, want ErrAlreadyExists", err)
+	}
+
+	// A failed add leaves neither the file nor a designmap reference behind
+	other := &spread.Spread{InnerSpread: spread.SpreadElement{Self: "uf10"}}
+	if err := pkg.AddSpread(SpreadPath("uf10"), other, "Spreads/Spread_missing.xml"); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("missing afterSpread: got %v, want ErrNotFound", err)
+	}
+	if pkg.hasFile(SpreadPath("uf10")) {
+		t.Error("spread file added despite the error")
+	}
+	doc, err := pkg.Document()
+	if err != nil {
+		t.Fatalf("Document failed: %v", err)
+	}
+	for _, ref := range doc.Spreads {
+		if ref.Src == SpreadPath("uf10") {
+			t.Error("designmap references the spread that failed to add")
+		}
+	}
+
+	if sp.InnerSpread.PageCount != "1" || sp.DOMVersion == "" {
+		t.Errorf("PageCount %q, DOMVersion %q", sp.InnerSpread.PageCount, sp.DOMVersion)
+	}
+	assertSection(t, pkg, "u217", "3")
+
+	// Move u218 with its items into the new spread
+	before := ownItemCenters(t, pkg, "u218")
+	if err := pkg.MovePage("u218", filename, "uf01"); err != nil {
+		t.Fatalf("MovePage failed: %v", err)
+	}
+	if got := pageIDs(t, pkg, filename); !equalIDs(got, []string{"uf01", "u218"}) {
+		t.Fatalf("pages = %v", got)
+	}
+	after := ownItemCenters(t, pkg, "u218")
+	if len(after) != len(before) {
+		t.Errorf("u218 has %d items after move, want %d", len(after), len(before))
+	}
+
+	// Round trip through a file
+	path := writeTestIDML(t, pkg, "add_spread.idml")
+	reread, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	order, err := reread.orderedSpreadFilenames()
+	if err != nil {
+		t.Fatalf("orderedSpreadFilenames failed: %v", err)
+	}
+	if !equalIDs(order, []string{exampleSpread, filename}) {
+		t.Errorf("spread order = %v", order)
+	}
+	if got := pageIDs(t, reread, filename); !equalIDs(got, []string{"uf01", "u218"}) {
+		t.Errorf("re-read pages = %v", got)
+	}
+	assertSection(t, reread, "u217", "3")
+
+	// Remove it again
+	if _, err := reread.RemoveSpread(filename, false); err != nil {
+		t.Fatalf("RemoveSpread failed: %v", err)
+	}
+	if _, err := reread.RemoveSpread(filename, false); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("second RemoveSpread: got %v, want ErrNotFound", err)
+	}
+	assertSection(t, reread, "u217", "1")
+}
+
+// TestResizePage tests resizing a left-hand page.
+func TestResizePage(t *testing.T) {
+	pkg := loadExampleIDML(t)
+	before := ownItemCenters(t, pkg, "u217")
+
+	if err := pkg.ResizePage("u217", 600, 800); err != nil {
+		t.Fatalf("ResizePage failed: %v", err)
+	}
+
+	sp, _ := pkg.Spread(exampleSpread)
+	page := sp.InnerSpread.Pages[0]
+	if page.GeometricBounds != "0 0 800 600" || page.ItemTransform != "1 0 0 1 -600 -400" {
+		t.Errorf("page = %q / %q", page.GeometricBounds, page.ItemTransform)
+	}
+
+	dx, dy := -600+793.7007874015749, -400+566.9291338582677
+	after := ownItemCenters(t, pkg, "u217")
+	for id, c := range before {
+		if moved, ok := after[id]; !ok || !nearlyEqual(moved.X, c.X+dx) || !nearlyEqual(moved.Y, c.Y+dy) {
+			t.Errorf("item %s: before %+v, after %+v", id, c, moved)
+		}
+	}
+}
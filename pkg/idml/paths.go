@@ -66,6 +66,13 @@ func IsSpreadPath(path string) bool {
 		len(path) > 4 && path[len(path)-4:] == ExtXML
 }
 
+// IsMasterSpreadPath checks if a path is a master spread file.
+func IsMasterSpreadPath(path string) bool {
+	return len(path) > len(PrefixMasterSpreads) &&
+		path[:len(PrefixMasterSpreads)] == PrefixMasterSpreads &&
+		len(path) > 4 && path[len(path)-4:] == ExtXML
+}
+
 // IsResourcePath checks if a path is in the Resources directory.
 func IsResourcePath(path string) bool {
 	return len(path) > len(PrefixResources) &&
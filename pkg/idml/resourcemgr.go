@@ -32,6 +32,8 @@ type dependencySet struct {
 	paragraphStyles map[string]bool
 	characterStyles map[string]bool
 	objectStyles    map[string]bool
+	tableStyles     map[string]bool
+	cellStyles      map[string]bool
 	colors          map[string]bool
 	swatches        map[string]bool
 	layers          map[string]bool
@@ -44,6 +46,8 @@ func newDependencySet() *dependencySet {
 		paragraphStyles: make(map[string]bool),
 		characterStyles: make(map[string]bool),
 		objectStyles:    make(map[string]bool),
+		tableStyles:     make(map[string]bool),
+		cellStyles:      make(map[string]bool),
 		colors:          make(map[string]bool),
 		swatches:        make(map[string]bool),
 		layers:          make(map[string]bool),
@@ -67,6 +71,14 @@ type CleanupOptions struct {
 	// DEFAULT: false - object styles often serve as templates
 	RemoveOrphanedObjectStyles bool
 
+	// RemoveOrphanedTableStyles removes table styles not used by any table
+	// DEFAULT: false - table styles often serve as templates
+	RemoveOrphanedTableStyles bool
+
+	// RemoveOrphanedCellStyles removes cell styles not used by any cell or table style
+	// DEFAULT: false - cell styles often serve as templates
+	RemoveOrphanedCellStyles bool
+
 	// RemoveOrphanedColors removes colors not used in any element
 	// DEFAULT: false - colors are part of the color library
 	RemoveOrphanedColors bool
@@ -92,6 +104,8 @@ func DefaultCleanupOptions() CleanupOptions {
 		RemoveOrphanedParagraphStyles: true,
 		RemoveOrphanedCharacterStyles: true,
 		RemoveOrphanedObjectStyles:    false, // Keep - often used as templates
+		RemoveOrphanedTableStyles:     false, // Keep - often used as templates
+		RemoveOrphanedCellStyles:      false, // Keep - often used as templates
 		RemoveOrphanedColors:          false, // Keep - part of color library
 		RemoveOrphanedSwatches:        false, // Keep - part of swatch library
 		RemoveOrphanedLayers:          false, // Keep - layers are structural
@@ -186,6 +200,12 @@ type OrphanedResources struct {
 	// ObjectStyles contains object style IDs that are defined but not used
 	ObjectStyles []string
 
+	// TableStyles contains table style IDs that are defined but not used
+	TableStyles []string
+
+	// CellStyles contains cell style IDs that are defined but not used
+	CellStyles []string
+
 	// Colors contains color IDs that are defined but not used
 	Colors []string
 
@@ -202,6 +222,8 @@ func (or *OrphanedResources) HasOrphans() bool {
 		len(or.ParagraphStyles) > 0 ||
 		len(or.CharacterStyles) > 0 ||
 		len(or.ObjectStyles) > 0 ||
+		len(or.TableStyles) > 0 ||
+		len(or.CellStyles) > 0 ||
 		len(or.Colors) > 0 ||
 		len(or.Swatches) > 0 ||
 		len(or.Layers) > 0
@@ -213,6 +235,8 @@ func (or *OrphanedResources) Count() int {
 		len(or.ParagraphStyles) +
 		len(or.CharacterStyles) +
 		len(or.ObjectStyles) +
+		len(or.TableStyles) +
+		len(or.CellStyles) +
 		len(or.Colors) +
 		len(or.Swatches) +
 		len(or.Layers)
@@ -234,6 +258,12 @@ type CleanupResult struct {
 	// RemovedObjectStyles lists object style IDs that were removed
 	RemovedObjectStyles []string
 
+	// RemovedTableStyles lists table style IDs that were removed
+	RemovedTableStyles []string
+
+	// RemovedCellStyles lists cell style IDs that were removed
+	RemovedCellStyles []string
+
 	// RemovedColors lists color IDs that were removed
 	RemovedColors []string
 
@@ -250,6 +280,8 @@ func (cr *CleanupResult) Count() int {
 		len(cr.RemovedParagraphStyles) +
 		len(cr.RemovedCharacterStyles) +
 		len(cr.RemovedObjectStyles) +
+		len(cr.RemovedTableStyles) +
+		len(cr.RemovedCellStyles) +
 		len(cr.RemovedColors) +
 		len(cr.RemovedSwatches) +
 		len(cr.RemovedLayers)
@@ -26,6 +26,12 @@ func (rm *ResourceManager) AddMissingResources(opts ValidationOptions) error {
 		return common.WrapError("idml", "add missing resources", fmt.Errorf("failed to find missing resources: %w", err))
 	}
 
+	return rm.addMissing(missing, opts)
+}
+
+// addMissing creates default versions of the given missing resources
+// according to the ValidationOptions.
+func (rm *ResourceManager) addMissing(missing *MissingResources, opts ValidationOptions) error {
 	if !missing.HasMissing() {
 		return nil // Nothing to do
 	}
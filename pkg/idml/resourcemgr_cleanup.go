@@ -12,6 +12,13 @@ import (
 )
 
 func (rm *ResourceManager) FindOrphans() (*OrphanedResources, error) {
+	return rm.findOrphans(false)
+}
+
+// findOrphans identifies the orphaned resources. If keepTableStyles is true,
+// unused table styles are treated as kept by the cleanup, so the cell styles
+// they refer to are not reported.
+func (rm *ResourceManager) findOrphans(keepTableStyles bool) (*OrphanedResources, error) {
 	result := &OrphanedResources{}
 
 	// Step 1: Analyze the entire document to find what's actually used
@@ -35,17 +42,22 @@ func (rm *ResourceManager) FindOrphans() (*OrphanedResources, error) {
 		return nil, common.WrapError("idml", "find orphaned object styles", err)
 	}
 
-	// Step 5: Find orphaned colors
+	// Step 5: Find orphaned table and cell styles
+	if err := rm.findOrphanedTableStyles(deps, keepTableStyles, result); err != nil {
+		return nil, common.WrapError("idml", "find orphaned table styles", err)
+	}
+
+	// Step 6: Find orphaned colors
 	if err := rm.findOrphanedColors(deps, result); err != nil {
 		return nil, common.WrapError("idml", "find orphaned colors", err)
 	}
 
-	// Step 6: Find orphaned swatches
+	// Step 7: Find orphaned swatches
 	if err := rm.findOrphanedSwatches(deps, result); err != nil {
 		return nil, common.WrapError("idml", "find orphaned swatches", err)
 	}
 
-	// Step 7: Find orphaned layers
+	// Step 8: Find orphaned layers
 	if err := rm.findOrphanedLayers(deps, result); err != nil {
 		return nil, common.WrapError("idml", "find orphaned layers", err)
 	}
@@ -82,6 +94,11 @@ func (rm *ResourceManager) analyzeDependencies() (*dependencySet, error) {
 		}
 	}
 
+	// Add the styles that used table and cell styles refer to
+	if err := rm.resolveTableStyleDependencies(deps); err != nil {
+		return nil, common.WrapError("idml", "analyze dependencies", fmt.Errorf("failed to resolve table styles: %w", err))
+	}
+
 	// Extract colors from used paragraph styles
 	if err := rm.extractColorsFromParagraphStyles(deps); err != nil {
 		return nil, common.WrapError("idml", "analyze dependencies", fmt.Errorf("failed to extract paragraph style colors: %w", err))
@@ -97,8 +114,35 @@ func (rm *ResourceManager) analyzeDependencies() (*dependencySet, error) {
 
 // analyzeStory analyzes a story and tracks all style dependencies.
 func (rm *ResourceManager) analyzeStory(st *story.Story, deps *dependencySet) error {
+	rm.analyzeRanges(st.StoryElement.ParagraphStyleRanges, deps)
+	rm.analyzeTables(st.Tables(), deps)
+
+	// Footnote and note text have their own paragraph and character style ranges
+	for _, footnote := range st.Footnotes() {
+		rm.analyzeRanges(footnote.ParagraphStyleRanges, deps)
+	}
+	for _, note := range st.Notes() {
+		rm.analyzeRanges(note.ParagraphStyleRanges, deps)
+	}
+
+	// Analyze page items anchored in the text. The stories of anchored text
+	// frames are analyzed on their own, since all stories are visited.
+	for _, obj := range st.AnchoredObjects() {
+		if style := obj.AppliedObjectStyle(); style != "" {
+			deps.objectStyles[style] = true
+		}
+		for _, color := range obj.Colors() {
+			deps.colors[color] = true
+		}
+	}
+
+	return nil
+}
+
+// analyzeRanges tracks the paragraph and character styles of the given ranges.
+func (rm *ResourceManager) analyzeRanges(ranges []story.ParagraphStyleRange, deps *dependencySet) {
 	// Analyze each paragraph style range
-	for _, psr := range st.StoryElement.ParagraphStyleRanges {
+	for _, psr := range ranges {
 		// Track the paragraph style
 		if psr.AppliedParagraphStyle != "" {
 			deps.paragraphStyles[psr.AppliedParagraphStyle] = true
@@ -118,10 +162,86 @@ func (rm *ResourceManager) analyzeStory(st *story.Story, deps *dependencySet) er
 			// after all styles are collected. See extractColorsFromCharacterStyles().
 		}
 	}
+}
+
+// analyzeTables tracks the table and cell styles of the given tables and the
+// styles of the text in their cells, descending into nested tables.
+func (rm *ResourceManager) analyzeTables(tables []*story.Table, deps *dependencySet) {
+	for _, table := range tables {
+		if table.AppliedTableStyle != "" {
+			deps.tableStyles[table.AppliedTableStyle] = true
+		}
+		for i := range table.Cells {
+			cell := &table.Cells[i]
+			if cell.AppliedCellStyle != "" {
+				deps.cellStyles[cell.AppliedCellStyle] = true
+			}
+			rm.analyzeRanges(cell.ParagraphStyleRanges, deps)
+			rm.analyzeTables(cell.Tables(), deps)
+		}
+	}
+}
+
+// resolveTableStyleDependencies adds the styles that the used table and cell
+// styles depend on: the styles they are based on, the region cell styles of
+// table styles and the paragraph styles of cell styles.
+func (rm *ResourceManager) resolveTableStyleDependencies(deps *dependencySet) error {
+	if len(deps.tableStyles) == 0 && len(deps.cellStyles) == 0 {
+		return nil
+	}
+	styles, err := rm.pkg.Styles()
+	if err != nil {
+		if errors.Is(err, common.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get styles: %w", err)
+	}
 
+	for id := range deps.tableStyles {
+		for current := id; current != ""; {
+			deps.tableStyles[current] = true
+			style := styles.FindTableStyle(current)
+			if style == nil {
+				break
+			}
+			for _, cellStyle := range style.RegionCellStyles() {
+				deps.cellStyles[cellStyle] = true
+			}
+			current = basedOnStyle("TableStyle/", style.Properties, deps.tableStyles)
+		}
+	}
+	for id := range deps.cellStyles {
+		for current := id; current != ""; {
+			deps.cellStyles[current] = true
+			style := styles.FindCellStyle(current)
+			if style == nil {
+				break
+			}
+			if style.AppliedParagraphStyle != "" {
+				deps.paragraphStyles[style.AppliedParagraphStyle] = true
+			}
+			current = basedOnStyle("CellStyle/", style.Properties, deps.cellStyles)
+		}
+	}
 	return nil
 }
 
+// basedOnStyle returns the style a style is based on, or "" if it has none
+// or the parent is already in seen.
+func basedOnStyle(prefix string, props *common.Properties, seen map[string]bool) string {
+	parent := props.GetBasedOn()
+	if parent == "" {
+		return ""
+	}
+	if !strings.HasPrefix(parent, prefix) {
+		parent = prefix + parent
+	}
+	if seen[parent] {
+		return ""
+	}
+	return parent
+}
+
 // analyzeSpread analyzes a spread and tracks all object dependencies.
 func (rm *ResourceManager) analyzeSpread(sp *spread.Spread, deps *dependencySet) error {
 	// Analyze text frames
@@ -308,6 +428,76 @@ func (rm *ResourceManager) findOrphanedObjectStyles(deps *dependencySet, result
 	return nil
 }
 
+// findOrphanedTableStyles identifies table and cell styles that are defined
+// but not used. Built-in styles such as "TableStyle/$ID/[Basic Table]" and
+// "CellStyle/$ID/[None]" are never reported. If keepTableStyles is true, the
+// region cell styles of all table styles count as used.
+func (rm *ResourceManager) findOrphanedTableStyles(deps *dependencySet, keepTableStyles bool, result *OrphanedResources) error {
+	// Get all defined styles
+	styles, err := rm.pkg.Styles()
+	if err != nil {
+		// If there's no Styles.xml file, that's okay - no table styles to clean up
+		if errors.Is(err, common.ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get styles: %w", err)
+	}
+
+	// Table styles that are kept still refer to their region cell styles
+	if keepTableStyles {
+		var regions func(group *resources.TableStyleGroup)
+		regions = func(group *resources.TableStyleGroup) {
+			for _, ts := range group.TableStyles {
+				for _, cellStyle := range ts.RegionCellStyles() {
+					deps.cellStyles[cellStyle] = true
+				}
+			}
+			for i := range group.NestedGroups {
+				regions(&group.NestedGroups[i])
+			}
+		}
+		if styles.RootTableStyleGroup != nil {
+			regions(styles.RootTableStyleGroup)
+		}
+		// Add the cell styles those are based on
+		if err := rm.resolveTableStyleDependencies(deps); err != nil {
+			return err
+		}
+	}
+
+	var tables func(group *resources.TableStyleGroup)
+	tables = func(group *resources.TableStyleGroup) {
+		for _, ts := range group.TableStyles {
+			if !deps.tableStyles[ts.Self] && !strings.Contains(ts.Self, "/$ID/") {
+				result.TableStyles = append(result.TableStyles, ts.Self)
+			}
+		}
+		for i := range group.NestedGroups {
+			tables(&group.NestedGroups[i])
+		}
+	}
+	if styles.RootTableStyleGroup != nil {
+		tables(styles.RootTableStyleGroup)
+	}
+
+	var cells func(group *resources.CellStyleGroup)
+	cells = func(group *resources.CellStyleGroup) {
+		for _, cs := range group.CellStyles {
+			if !deps.cellStyles[cs.Self] && !strings.Contains(cs.Self, "/$ID/") {
+				result.CellStyles = append(result.CellStyles, cs.Self)
+			}
+		}
+		for i := range group.NestedGroups {
+			cells(&group.NestedGroups[i])
+		}
+	}
+	if styles.RootCellStyleGroup != nil {
+		cells(styles.RootCellStyleGroup)
+	}
+
+	return nil
+}
+
 // findOrphanedColors identifies colors that are defined but not used.
 func (rm *ResourceManager) findOrphanedColors(deps *dependencySet, result *OrphanedResources) error {
 	// Get all defined colors
@@ -366,8 +556,10 @@ func (rm *ResourceManager) findOrphanedLayers(deps *dependencySet, result *Orpha
 }
 
 // CleanupOrphans removes orphaned resources according to the options.
-// This method first identifies orphaned resources using FindOrphans(),
-// then removes them according to the CleanupOptions configuration.
+// This method first identifies orphaned resources like FindOrphans(),
+// then removes them according to the CleanupOptions configuration. Unless
+// RemoveOrphanedTableStyles is set, the cell styles referred to by unused
+// table styles are kept along with those table styles.
 //
 // If DryRun is true, no resources are actually removed - the method just
 // returns what would be removed.
@@ -375,8 +567,9 @@ func (rm *ResourceManager) findOrphanedLayers(deps *dependencySet, result *Orpha
 // Returns a CleanupResult containing details about what was removed (or
 // would be removed in DryRun mode).
 func (rm *ResourceManager) CleanupOrphans(opts CleanupOptions) (*CleanupResult, error) {
-	// Step 1: Find all orphaned resources
-	orphans, err := rm.FindOrphans()
+	// Step 1: Find all orphaned resources, keeping the cell styles of table
+	// styles that are not removed
+	orphans, err := rm.findOrphans(!opts.RemoveOrphanedTableStyles)
 	if err != nil {
 		return nil, common.WrapError("idml", "cleanup orphans", fmt.Errorf("failed to find orphans: %w", err))
 	}
@@ -395,6 +588,12 @@ func (rm *ResourceManager) CleanupOrphans(opts CleanupOptions) (*CleanupResult,
 		if opts.RemoveOrphanedObjectStyles {
 			result.RemovedObjectStyles = orphans.ObjectStyles
 		}
+		if opts.RemoveOrphanedTableStyles {
+			result.RemovedTableStyles = orphans.TableStyles
+		}
+		if opts.RemoveOrphanedCellStyles {
+			result.RemovedCellStyles = orphans.CellStyles
+		}
 		if opts.RemoveOrphanedColors {
 			result.RemovedColors = orphans.Colors
 		}
@@ -428,21 +627,28 @@ func (rm *ResourceManager) CleanupOrphans(opts CleanupOptions) (*CleanupResult,
 		}
 	}
 
-	// Step 6: Remove orphaned colors if requested
+	// Step 6: Remove orphaned table and cell styles if requested
+	if opts.RemoveOrphanedTableStyles || opts.RemoveOrphanedCellStyles {
+		if err := rm.removeOrphanedTableStyles(orphans, opts, result); err != nil {
+			return result, common.WrapError("idml", "remove orphaned table styles", err)
+		}
+	}
+
+	// Step 7: Remove orphaned colors if requested
 	if opts.RemoveOrphanedColors {
 		if err := rm.removeOrphanedColors(orphans.Colors, result); err != nil {
 			return result, common.WrapError("idml", "remove orphaned colors", err)
 		}
 	}
 
-	// Step 7: Remove orphaned swatches if requested
+	// Step 8: Remove orphaned swatches if requested
 	if opts.RemoveOrphanedSwatches {
 		if err := rm.removeOrphanedSwatches(orphans.Swatches, result); err != nil {
 			return result, common.WrapError("idml", "remove orphaned swatches", err)
 		}
 	}
 
-	// Step 8: Remove orphaned layers if requested
+	// Step 9: Remove orphaned layers if requested
 	if opts.RemoveOrphanedLayers {
 		if err := rm.removeOrphanedLayers(orphans.Layers, result); err != nil {
 			return result, common.WrapError("idml", "remove orphaned layers", err)
@@ -622,6 +828,88 @@ func (rm *ResourceManager) removeOrphanedObjectStyles(styleIDs []string, result
 	return nil
 }
 
+// removeOrphanedTableStyles removes the specified table and cell styles from
+// the Styles.xml file, including styles in nested groups.
+func (rm *ResourceManager) removeOrphanedTableStyles(orphans *OrphanedResources, opts CleanupOptions, result *CleanupResult) error {
+	if len(orphans.TableStyles) == 0 && len(orphans.CellStyles) == 0 {
+		return nil // Nothing to do
+	}
+
+	// Get the styles file
+	styles, err := rm.pkg.Styles()
+	if err != nil {
+		return fmt.Errorf("failed to get styles: %w", err)
+	}
+
+	// Remove orphaned table styles if requested
+	if opts.RemoveOrphanedTableStyles && styles.RootTableStyleGroup != nil {
+		stylesToRemove := make(map[string]bool)
+		for _, id := range orphans.TableStyles {
+			stylesToRemove[id] = true
+		}
+
+		var filter func(group *resources.TableStyleGroup)
+		filter = func(group *resources.TableStyleGroup) {
+			filtered := make([]resources.TableStyle, 0, len(group.TableStyles))
+			for _, ts := range group.TableStyles {
+				if !stylesToRemove[ts.Self] {
+					filtered = append(filtered, ts)
+				} else {
+					result.RemovedTableStyles = append(result.RemovedTableStyles, ts.Self)
+				}
+			}
+			group.TableStyles = filtered
+			for i := range group.NestedGroups {
+				filter(&group.NestedGroups[i])
+			}
+		}
+		filter(styles.RootTableStyleGroup)
+	}
+
+	// Remove orphaned cell styles if requested
+	if opts.RemoveOrphanedCellStyles && styles.RootCellStyleGroup != nil {
+		stylesToRemove := make(map[string]bool)
+		for _, id := range orphans.CellStyles {
+			stylesToRemove[id] = true
+		}
+
+		var filter func(group *resources.CellStyleGroup)
+		filter = func(group *resources.CellStyleGroup) {
+			filtered := make([]resources.CellStyle, 0, len(group.CellStyles))
+			for _, cs := range group.CellStyles {
+				if !stylesToRemove[cs.Self] {
+					filtered = append(filtered, cs)
+				} else {
+					result.RemovedCellStyles = append(result.RemovedCellStyles, cs.Self)
+				}
+			}
+			group.CellStyles = filtered
+			for i := range group.NestedGroups {
+				filter(&group.NestedGroups[i])
+			}
+		}
+		filter(styles.RootCellStyleGroup)
+	}
+
+	// Update the cached styles
+	rm.pkg.SetStyles(styles)
+
+	// Marshal and update the file entry
+	data, err := resources.MarshalStylesFile(styles)
+	if err != nil {
+		return fmt.Errorf("failed to marshal styles: %w", err)
+	}
+
+	// Update the file entry
+	if entry, exists := rm.pkg.files[PathStyles]; exists {
+		entry.data = data
+	} else {
+		rm.pkg.files[PathStyles] = &fileEntry{data: data}
+	}
+
+	return nil
+}
+
 // removeOrphanedColors removes the specified colors from the Graphic.xml file.
 func (rm *ResourceManager) removeOrphanedColors(colorIDs []string, result *CleanupResult) error {
 	if len(colorIDs) == 0 {
@@ -1,7 +1,12 @@
 package idml
 
 import (
+	"encoding/xml"
 	"testing"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/resources"
+	"github.com/dimelords/idmllib/v2/pkg/story"
 )
 
 // TestOrphanedResourcesHelpers_HelperMethods tests the helper methods on OrphanedResources.
@@ -183,6 +188,9 @@ func TestDefaultCleanupOptions_SensibleDefaults(t *testing.T) {
 	if opts.RemoveOrphanedObjectStyles {
 		t.Error("RemoveOrphanedObjectStyles should be false by default")
 	}
+	if opts.RemoveOrphanedTableStyles || opts.RemoveOrphanedCellStyles {
+		t.Error("RemoveOrphanedTableStyles and RemoveOrphanedCellStyles should be false by default")
+	}
 	if opts.RemoveOrphanedColors {
 		t.Error("RemoveOrphanedColors should be false by default")
 	}
@@ -268,6 +276,200 @@ func TestFindOrphans_RealDocument(t *testing.T) {
 	t.Logf("Total orphans: %d", orphans.Count())
 }
 
+// TestFindOrphans_AnchoredObjects tests that object styles used only by
+// page items anchored in stories are not reported as orphans.
+func TestFindOrphans_AnchoredObjects(t *testing.T) {
+	pkg, err := Read("../../testdata/example.idml")
+	if err != nil {
+		t.Fatalf("Failed to read example.idml: %v", err)
+	}
+
+	rm := NewResourceManager(pkg)
+	orphans, err := rm.FindOrphans()
+	if err != nil {
+		t.Fatalf("FindOrphans() error = %v", err)
+	}
+	if len(orphans.ObjectStyles) == 0 {
+		t.Skip("No orphaned object styles in example.idml")
+	}
+	objectStyle := orphans.ObjectStyles[0]
+
+	// Anchor a rectangle with the unused object style in the first story with text
+	stories, err := pkg.Stories()
+	if err != nil {
+		t.Fatalf("Stories() error = %v", err)
+	}
+	var csr *story.CharacterStyleRange
+	for _, st := range stories {
+		if len(st.StoryElement.ParagraphStyleRanges) > 0 && len(st.StoryElement.ParagraphStyleRanges[0].CharacterStyleRanges) > 0 {
+			csr = &st.StoryElement.ParagraphStyleRanges[0].CharacterStyleRanges[0]
+			break
+		}
+	}
+	if csr == nil {
+		t.Skip("No story with text in example.idml")
+	}
+	rect := &story.AnchoredRectangle{}
+	rect.Self = "uanc1"
+	rect.AppliedObjectStyle = objectStyle
+	csr.Children = append(csr.Children, story.CharacterChild{Anchored: &story.AnchoredObject{Rectangle: rect}})
+
+	orphans, err = rm.FindOrphans()
+	if err != nil {
+		t.Fatalf("FindOrphans() error = %v", err)
+	}
+	for _, os := range orphans.ObjectStyles {
+		if os == objectStyle {
+			t.Errorf("Object style %s used by an anchored rectangle reported as orphan", objectStyle)
+		}
+	}
+}
+
+// TestFindOrphans_TableStyles tests that table and cell styles are tracked
+// through tables in stories, region cell styles and the paragraph styles of
+// cell styles, and that unused ones are removed on request.
+func TestFindOrphans_TableStyles(t *testing.T) {
+	pkg, err := Read("../../testdata/example.idml")
+	if err != nil {
+		t.Fatalf("Failed to read example.idml: %v", err)
+	}
+	rm := NewResourceManager(pkg)
+	orphans, err := rm.FindOrphans()
+	if err != nil {
+		t.Fatalf("FindOrphans() error = %v", err)
+	}
+	if len(orphans.TableStyles) != 0 || len(orphans.CellStyles) != 0 {
+		t.Errorf("built-in table and cell styles reported as orphans: %v %v", orphans.TableStyles, orphans.CellStyles)
+	}
+	if len(orphans.ParagraphStyles) == 0 {
+		t.Skip("No orphaned paragraph styles in example.idml")
+	}
+	cellParagraphStyle := orphans.ParagraphStyles[0]
+
+	// Add custom styles: a used table style whose header uses a cell style,
+	// a cell style applied to a cell, and one unused style of each kind
+	styles, err := pkg.Styles()
+	if err != nil {
+		t.Fatalf("Styles() error = %v", err)
+	}
+	styles.RootTableStyleGroup.TableStyles = append(styles.RootTableStyleGroup.TableStyles,
+		resources.TableStyle{Self: "TableStyle/Data", Name: "Data", HeaderRegionCellStyle: "CellStyle/Header"},
+		resources.TableStyle{Self: "TableStyle/Unused", Name: "Unused"},
+	)
+	styles.RootCellStyleGroup.CellStyles = append(styles.RootCellStyleGroup.CellStyles,
+		resources.CellStyle{Self: "CellStyle/Header", Name: "Header", AppliedParagraphStyle: cellParagraphStyle},
+		resources.CellStyle{Self: "CellStyle/Body", Name: "Body"},
+		resources.CellStyle{Self: "CellStyle/Unused", Name: "Unused"},
+	)
+
+	// Add a table using them to the first story with text
+	stories, err := pkg.Stories()
+	if err != nil {
+		t.Fatalf("Stories() error = %v", err)
+	}
+	var csr *story.CharacterStyleRange
+	for _, st := range stories {
+		if len(st.StoryElement.ParagraphStyleRanges) > 0 && len(st.StoryElement.ParagraphStyleRanges[0].CharacterStyleRanges) > 0 {
+			csr = &st.StoryElement.ParagraphStyleRanges[0].CharacterStyleRanges[0]
+			break
+		}
+	}
+	if csr == nil {
+		t.Skip("No story with text in example.idml")
+	}
+	table := &story.Table{
+		Self:              "utbl1",
+		BodyRowCount:      "1",
+		ColumnCount:       "1",
+		AppliedTableStyle: "TableStyle/Data",
+		Cells:             []story.Cell{{Self: "utbl1i0i0", Name: "0:0", AppliedCellStyle: "CellStyle/Body"}},
+	}
+	csr.Children = append(csr.Children, story.CharacterChild{Table: table})
+
+	orphans, err = rm.FindOrphans()
+	if err != nil {
+		t.Fatalf("FindOrphans() error = %v", err)
+	}
+	if len(orphans.TableStyles) != 1 || orphans.TableStyles[0] != "TableStyle/Unused" {
+		t.Errorf("orphaned table styles = %v, want [TableStyle/Unused]", orphans.TableStyles)
+	}
+	if len(orphans.CellStyles) != 1 || orphans.CellStyles[0] != "CellStyle/Unused" {
+		t.Errorf("orphaned cell styles = %v, want [CellStyle/Unused]", orphans.CellStyles)
+	}
+	for _, id := range orphans.ParagraphStyles {
+		if id == cellParagraphStyle {
+			t.Errorf("paragraph style %s applied by a used cell style reported as orphan", id)
+		}
+	}
+
+	opts := CleanupOptions{RemoveOrphanedTableStyles: true, RemoveOrphanedCellStyles: true}
+	result, err := rm.CleanupOrphans(opts)
+	if err != nil {
+		t.Fatalf("CleanupOrphans() error = %v", err)
+	}
+	if result.Count() != 2 {
+		t.Errorf("CleanupOrphans() removed %d resources, want 2", result.Count())
+	}
+	styles, _ = pkg.Styles()
+	if styles.FindTableStyle("TableStyle/Unused") != nil || styles.FindCellStyle("CellStyle/Unused") != nil {
+		t.Error("orphaned table or cell style was not removed")
+	}
+	if styles.FindTableStyle("TableStyle/Data") == nil || styles.FindCellStyle("CellStyle/Header") == nil {
+		t.Error("used table or cell style was removed")
+	}
+}
+
+// TestCleanupOrphans_KeepTableStyles tests that removing orphaned cell
+// styles while keeping table styles keeps the cell styles of unused table
+// styles.
+func TestCleanupOrphans_KeepTableStyles(t *testing.T) {
+	pkg, err := Read("../../testdata/example.idml")
+	if err != nil {
+		t.Fatalf("Failed to read example.idml: %v", err)
+	}
+	styles, err := pkg.Styles()
+	if err != nil {
+		t.Fatalf("Styles() error = %v", err)
+	}
+	styles.RootTableStyleGroup.TableStyles = append(styles.RootTableStyleGroup.TableStyles,
+		resources.TableStyle{Self: "TableStyle/Template", Name: "Template", BodyRegionCellStyle: "CellStyle/Body"},
+	)
+	styles.RootCellStyleGroup.CellStyles = append(styles.RootCellStyleGroup.CellStyles,
+		resources.CellStyle{Self: "CellStyle/Base", Name: "Base"},
+		resources.CellStyle{Self: "CellStyle/Body", Name: "Body", Properties: &common.Properties{
+			OtherElements: []common.RawXMLElement{{XMLName: xml.Name{Local: "BasedOn"}, Content: []byte("CellStyle/Base")}},
+		}},
+		resources.CellStyle{Self: "CellStyle/Unused", Name: "Unused"},
+	)
+	rm := NewResourceManager(pkg)
+
+	// Removing both, the cell style goes with the table style
+	opts := CleanupOptions{DryRun: true, RemoveOrphanedTableStyles: true, RemoveOrphanedCellStyles: true}
+	result, err := rm.CleanupOrphans(opts)
+	if err != nil {
+		t.Fatalf("CleanupOrphans() error = %v", err)
+	}
+	if len(result.RemovedCellStyles) != 3 {
+		t.Errorf("removed cell styles = %v, want Base, Body and Unused", result.RemovedCellStyles)
+	}
+
+	opts = CleanupOptions{RemoveOrphanedCellStyles: true}
+	result, err = rm.CleanupOrphans(opts)
+	if err != nil {
+		t.Fatalf("CleanupOrphans() error = %v", err)
+	}
+	if len(result.RemovedCellStyles) != 1 || result.RemovedCellStyles[0] != "CellStyle/Unused" {
+		t.Errorf("removed cell styles = %v, want [CellStyle/Unused]", result.RemovedCellStyles)
+	}
+	styles, _ = pkg.Styles()
+	if styles.FindTableStyle("TableStyle/Template") == nil {
+		t.Error("table style was removed")
+	}
+	if styles.FindCellStyle("CellStyle/Body") == nil || styles.FindCellStyle("CellStyle/Base") == nil {
+		t.Error("cell style of a kept table style was removed")
+	}
+}
+
 // TestCleanupOrphans_DryRun tests that DryRun mode doesn't actually remove anything.
 func TestCleanupOrphans_DryRun(t *testing.T) {
 	// Load the example.idml test file
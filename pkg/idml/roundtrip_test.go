@@ -561,3 +561,47 @@ func TestRoundtripWithParsing_FunctionalValidation(t *testing.T) {
 		})
 	}
 }
+
+// TestRoundtrip_Footnotes verifies that a footnote added to a story survives
+// writing and that endnote and footnote options are kept intact.
+func TestRoundtrip_Footnotes(t *testing.T) {
+	pkg := loadExampleIDML(t)
+
+	st, err := pkg.Story("Stories/Story_u2ee.xml")
+	if err != nil {
+		t.Fatalf("Story failed: %v", err)
+	}
+	text := st.ExtractText()
+	if _, err := st.AddFootnote(len(text), "Source: annual report.", ""); err != nil {
+		t.Fatalf("AddFootnote failed: %v", err)
+	}
+
+	reread, err := Read(writeTestIDML(t, pkg, "footnotes.idml"))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	st, err = reread.Story("Stories/Story_u2ee.xml")
+	if err != nil {
+		t.Fatalf("Story failed after roundtrip: %v", err)
+	}
+	footnotes := st.Footnotes()
+	if len(footnotes) != 1 || footnotes[0].Text() != "Source: annual report." {
+		t.Fatalf("Footnotes() = %v after roundtrip", footnotes)
+	}
+	if got := st.ExtractText(); got != text {
+		t.Errorf("ExtractText() = %q, want %q", got, text)
+	}
+
+	for filename, want := range map[string]string{
+		"designmap.xml":             "<EndnoteOption ",
+		"Resources/Preferences.xml": "<FootnoteOption ",
+	} {
+		data, err := reread.getFileData(filename)
+		if err != nil {
+			t.Fatalf("getFileData(%s) failed: %v", filename, err)
+		}
+		if !strings.Contains(string(data), want) {
+			t.Errorf("%s lost %s", filename, want)
+		}
+	}
+}
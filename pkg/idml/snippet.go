@@ -0,0 +1,571 @@
+package idml
+
+import (
+	"encoding/xml"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/document"
+	"github.com/dimelords/idmllib/v2/pkg/resources"
+	"github.com/dimelords/idmllib/v2/pkg/spread"
+	"github.com/dimelords/idmllib/v2/pkg/story"
+)
+
+// Snippet is an InDesign snippet that can be placed into a package.
+//
+// It is implemented by *idms.Package. The interface lets PlaceSnippet accept
+// snippets without pkg/idml importing pkg/idms, which already imports pkg/idml.
+type Snippet interface {
+	// SnippetDocument returns the snippet Document with its inline styles,
+	// colors, swatches, layers, spreads and stories.
+	SnippetDocument() *document.Document
+}
+
+// selfAttrPattern matches Self attributes in marshaled XML.
+var selfAttrPattern = regexp.MustCompile(`\sSelf="([^"]*)"`)
+
+// attrValuePattern matches any attribute value in marshaled XML.
+var attrValuePattern = regexp.MustCompile(`(\s[\w:]+=")([^"]*)"`)
+
+// idReferenceAttrs are the attributes of stories and page items that hold a
+// Self ID or a space-separated list of them.
+var idReferenceAttrs = []string{
+	"Self",
+	"ParentStory",
+	"NextTextFrame",
+	"PreviousTextFrame",
+	"ItemLayer",
+	"XMLContent",
+	"SourcePageItem",
+	"StoryList",
+	"OverrideList",
+}
+
+// idReferencePattern matches the values of idReferenceAttrs in marshaled XML.
+var idReferencePattern = regexp.MustCompile(`(\s(?:` + strings.Join(idReferenceAttrs, "|") + `)=")([^"]*)"`)
+
+// snippetContent holds the stories and page items of a snippet while their
+// IDs are renumbered.
+type snippetContent struct {
+	XMLName xml.Name               `xml:"SnippetContent"`
+	Stories []story.StoryElement   `xml:"Story"`
+	Items   []spread.SpreadElement `xml:"Spread"`
+}
+
+// PlaceSnippet places the contents of an IDMS snippet on a spread.
+//
+// This operation:
+//  1. Merges the snippet's paragraph, character and object styles into Styles.xml
+//  2. Merges its colors, swatches and stroke styles into Graphic.xml
+//  3. Merges its layers into designmap.xml, reusing layers with the same name
+//  4. Renumbers Self IDs of its stories and page items that are already used
+//     in the package, updating all references to them
+//  5. Adds its stories under Stories/ and registers them in designmap.xml
+//  6. Appends its page items to the spread, moved by offsetX, offsetY points
+//
+// Styles, colors and swatches that already exist in the package keep their
+// definition; the snippet's version is ignored. The snippet's pages are not
+// placed, only the page items on them.
+//
+// Example:
+//
+//	snip, err := idms.Read("teaser.idms")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	err = pkg.PlaceSnippet(snip, "Spreads/Spread_u210.xml", 0, 120)
+func (p *Package) PlaceSnippet(snip Snippet, spreadFile string, offsetX, offsetY float64) error {
+	const op = "place snippet"
+
+	var src *document.Document
+	if snip != nil {
+		src = snip.SnippetDocument()
+	}
+	if src == nil {
+		return common.Errorf("idml", op, spreadFile, "snippet has no document")
+	}
+
+	sp, err := p.loadSpreadForModification(spreadFile, op)
+	if err != nil {
+		return err
+	}
+	doc, err := p.Document()
+	if err != nil {
+		return common.WrapErrorWithPath("idml", op, spreadFile, err)
+	}
+
+	// Step 1: Merge styles
+	if src.RootParagraphStyleGroup != nil || src.RootCharacterStyleGroup != nil || src.RootObjectStyleGroup != nil {
+		styles, err := p.Styles()
+		if err != nil {
+			return common.WrapErrorWithPath("idml", op, PathStyles, err)
+		}
+		mergeSnippetStyles(styles, src)
+		p.SetStyles(styles)
+	}
+
+	// Step 2: Merge colors, swatches and stroke styles
+	if len(src.Colors) > 0 || len(src.Swatches) > 0 || len(src.StrokeStyles) > 0 {
+		graphics, err := p.Graphics()
+		if err != nil {
+			return common.WrapErrorWithPath("idml", op, PathGraphic, err)
+		}
+		mergeSnippetGraphics(graphics, src)
+		p.SetGraphics(graphics)
+	}
+
+	// Step 3: Collect the IDs in use and merge layers
+	used, err := p.usedSelfIDs()
+	if err != nil {
+		return common.WrapErrorWithPath("idml", op, spreadFile, err)
+	}
+	content := snippetContent{Stories: src.InlineStories}
+	for i := range src.InlineSpreads {
+		content.Items = append(content.Items, pageItemsOf(&src.InlineSpreads[i]))
+	}
+	data, err := xml.Marshal(content)
+	if err != nil {
+		return common.WrapErrorWithPath("idml", op, spreadFile, err)
+	}
+	var itemIDs []string
+	for _, m := range selfAttrPattern.FindAllSubmatch(data, -1) {
+		if len(m[1]) > 0 {
+			itemIDs = append(itemIDs, string(m[1]))
+		}
+	}
+	snippetIDs := make(map[string]bool)
+	for _, id := range itemIDs {
+		snippetIDs[id] = true
+	}
+	for _, layer := range src.Layers {
+		snippetIDs[layer.Self] = true
+	}
+
+	ids := newIDAllocator(used, snippetIDs)
+	renames := mergeSnippetLayers(doc, src.Layers, used, ids)
+
+	// Step 4: Renumber colliding IDs, in document order so the result is stable
+	for _, id := range itemIDs {
+		if _, renamed := renames[id]; used[id] && !renamed {
+			renames[id] = ids.next()
+		}
+	}
+	// Decoding the renamed XML also gives copies, leaving the snippet unchanged
+	content = snippetContent{}
+	if err := xml.Unmarshal(renameIDReferences(data, renames), &content); err != nil {
+		return common.WrapErrorWithPath("idml", op, spreadFile, err)
+	}
+
+	// Step 5: Add the stories
+	for _, elem := range content.Stories {
+		filename := StoryPath(elem.Self)
+		if err := p.validateStoryDoesNotExist(filename); err != nil {
+			return err
+		}
+		st := &story.Story{DOMVersion: doc.DOMVersion, StoryElement: elem}
+		if err := p.marshalAndUpdateStory(filename, st); err != nil {
+			return err
+		}
+		doc.Stories = append(doc.Stories, document.ResourceRef{
+			XMLName: xml.Name{Space: packagingNamespace, Local: "Story"},
+			Src:     filename,
+		})
+		doc.StoryList = strings.TrimSpace(doc.StoryList + " " + elem.Self)
+	}
+
+	// Step 6: Place the page items
+	for i := range content.Items {
+		if err := placePageItems(&sp.InnerSpread, &content.Items[i], offsetX, offsetY); err != nil {
+			return common.WrapErrorWithPath("idml", op, spreadFile, err)
+		}
+	}
+	if err := p.marshalAndUpdateSpread(spreadFile, sp); err != nil {
+		return err
+	}
+	p.invalidateIndex()
+
+	return nil
+}
+
+// pageItemsOf returns a copy of a spread element holding only its page items.
+func pageItemsOf(s *spread.SpreadElement) spread.SpreadElement {
+	return spread.SpreadElement{
+		TextFrames:   s.TextFrames,
+		Rectangles:   s.Rectangles,
+		Ovals:        s.Ovals,
+		Polygons:     s.Polygons,
+		GraphicLines: s.GraphicLines,
+		Groups:       s.Groups,
+	}
+}
+
+// placePageItems appends the page items of items to dst, moved by dx, dy.
+func placePageItems(dst, items *spread.SpreadElement, dx, dy float64) error {
+	var bases []*spread.PageItemBase
+	for i := range items.TextFrames {
+		bases = append(bases, &items.TextFrames[i].PageItemBase)
+	}
+	for i := range items.Rectangles {
+		bases = append(bases, &items.Rectangles[i].PageItemBase)
+	}
+	for i := range items.Ovals {
+		bases = append(bases, &items.Ovals[i].PageItemBase)
+	}
+	for i := range items.Polygons {
+		bases = append(bases, &items.Polygons[i].PageItemBase)
+	}
+	for i := range items.GraphicLines {
+		bases = append(bases, &items.GraphicLines[i].PageItemBase)
+	}
+	for i := range items.Groups {
+		bases = append(bases, &items.Groups[i].PageItemBase)
+	}
+	for _, base := range bases {
+		if err := base.Translate(dx, dy); err != nil {
+			return err
+		}
+	}
+
+	dst.TextFrames = append(dst.TextFrames, items.TextFrames...)
+	dst.Rectangles = append(dst.Rectangles, items.Rectangles...)
+	dst.Ovals = append(dst.Ovals, items.Ovals...)
+	dst.Polygons = append(dst.Polygons, items.Polygons...)
+	dst.GraphicLines = append(dst.GraphicLines, items.GraphicLines...)
+	dst.Groups = append(dst.Groups, items.Groups...)
+	return nil
+}
+
+// usedSelfIDs returns every Self ID in the package, including those of
+// cached stories, spreads, the backing story and the document that have not
+// been written back yet.
+func (p *Package) usedSelfIDs() (map[string]bool, error) {
+	used := make(map[string]bool)
+	for filename, entry := range p.files {
+		if strings.HasSuffix(filename, ".xml") {
+			collectSelfIDs(entry.data, used)
+		}
+	}
+
+	for filename, st := range p.stories {
+		data, err := story.MarshalStory(st)
+		if err != nil {
+			return nil, common.WrapErrorWithPath("idml", "marshal story", filename, err)
+		}
+		collectSelfIDs(data, used)
+	}
+	for filename, sp := range p.spreads {
+		data, err := spread.MarshalSpread(sp)
+		if err != nil {
+			return nil, common.WrapErrorWithPath("idml", "marshal spread", filename, err)
+		}
+		collectSelfIDs(data, used)
+	}
+	if p.backingStory != nil {
+		data, err := story.MarshalBackingStory(p.backingStory)
+		if err != nil {
+			return nil, common.WrapErrorWithPath("idml", "marshal backing story", PathBackingStory, err)
+		}
+		collectSelfIDs(data, used)
+	}
+	if p.document != nil {
+		data, err := document.MarshalDocument(p.document)
+		if err != nil {
+			return nil, common.WrapErrorWithPath("idml", "marshal document", PathDesignmap, err)
+		}
+		collectSelfIDs(data, used)
+	}
+	return used, nil
+}
+
+// collectSelfIDs adds the non-empty Self attribute values in data to ids.
+func collectSelfIDs(data []byte, ids map[string]bool) {
+	for _, m := range selfAttrPattern.FindAllSubmatch(data, -1) {
+		if len(m[1]) > 0 {
+			ids[string(m[1])] = true
+		}
+	}
+}
+
+// renameIDReferences replaces the IDs in idReferenceAttrs values that are
+// keys of renames, including IDs inside lists.
+func renameIDReferences(data []byte, renames map[string]string) []byte {
+	return idReferencePattern.ReplaceAllFunc(data, func(attr []byte) []byte {
+		m := idReferencePattern.FindSubmatch(attr)
+		ids := strings.Split(string(m[2]), " ")
+		renamed := false
+		for i, id := range ids {
+			if newID, ok := renames[id]; ok {
+				ids[i], renamed = newID, true
+			}
+		}
+		if !renamed {
+			return attr
+		}
+		return []byte(string(m[1]) + strings.Join(ids, " ") + `"`)
+	})
+}
+
+// idAllocator hands out InDesign-style IDs ("u" followed by hex digits)
+// that are not in use.
+type idAllocator struct {
+	used map[string]bool
+	n    uint64
+}
+
+// newIDAllocator returns an allocator that avoids all IDs in the given sets
+// and starts after the highest "u" ID among them.
+func newIDAllocator(sets ...map[string]bool) *idAllocator {
+	a := &idAllocator{used: make(map[string]bool)}
+	for _, set := range sets {
+		for id := range set {
+			a.used[id] = true
+			if strings.HasPrefix(id, "u") {
+				if n, err := strconv.ParseUint(id[1:], 16, 64); err == nil && n >= a.n {
+					a.n = n + 1
+				}
+			}
+		}
+	}
+	return a
+}
+
+// next returns a new unused ID.
+func (a *idAllocator) next() string {
+	for {
+		id := "u" + strconv.FormatUint(a.n, 16)
+		a.n++
+		if !a.used[id] {
+			a.used[id] = true
+			return id
+		}
+	}
+}
+
+// mergeSnippetLayers adds snippet layers to the document. A layer whose name
+// matches an existing layer is mapped onto it; a new layer whose Self is
+// already used gets a new ID. Returns the renamed layer IDs.
+func mergeSnippetLayers(doc *document.Document, layers []document.Layer, used map[string]bool, ids *idAllocator) map[string]string {
+	renames := make(map[string]string)
+	for _, layer := range layers {
+		if self := layerByName(doc, layer.Name); self != "" {
+			renames[layer.Self] = self
+			continue
+		}
+		if used[layer.Self] {
+			renames[layer.Self] = ids.next()
+			layer.Self = renames[layer.Self]
+		}
+		doc.Layers = append(doc.Layers, layer)
+	}
+	return renames
+}
+
+// layerByName returns the Self ID of the document layer with the given name, or "".
+func layerByName(doc *document.Document, name string) string {
+	for _, l := range doc.Layers {
+		if l.Name == name {
+			return l.Self
+		}
+	}
+	return ""
+}
+
+// styleMerger decides whether a style of a merged group is copied: added
+// if the target has no style with its ID, or replacing the target's style
+// if it has.
+type styleMerger func(styleID string, exists bool) bool
+
+// addMissingStyles is a styleMerger that only adds styles the target lacks.
+func addMissingStyles(_ string, exists bool) bool {
+	return !exists
+}
+
+// mergeSnippetStyles adds the snippet's styles that are missing from styles.
+func mergeSnippetStyles(styles *resources.StylesFile, src *document.Document) {
+	mergeStyles(styles, &resources.StylesFile{
+		RootParagraphStyleGroup: src.RootParagraphStyleGroup,
+		RootCharacterStyleGroup: src.RootCharacterStyleGroup,
+		RootCellStyleGroup:      src.RootCellStyleGroup,
+		RootTableStyleGroup:     src.RootTableStyleGroup,
+		RootObjectStyleGroup:    src.RootObjectStyleGroup,
+	}, addMissingStyles)
+}
+
+// mergeStyles copies the paragraph, character, object, cell and table styles
+// of src that merge selects into styles, keeping their group hierarchy.
+func mergeStyles(styles, src *resources.StylesFile, merge styleMerger) {
+	if src.RootParagraphStyleGroup != nil {
+		if styles.RootParagraphStyleGroup == nil {
+			styles.RootParagraphStyleGroup = &resources.ParagraphStyleGroup{
+				XMLName: xml.Name{Local: "RootParagraphStyleGroup"},
+				Self:    src.RootParagraphStyleGroup.Self,
+			}
+		}
+		mergeStyleGroup(styles, styles.RootParagraphStyleGroup, src.RootParagraphStyleGroup, paragraphStyleGroups, merge)
+	}
+	if src.RootCharacterStyleGroup != nil {
+		if styles.RootCharacterStyleGroup == nil {
+			styles.RootCharacterStyleGroup = &resources.CharacterStyleGroup{
+				XMLName: xml.Name{Local: "RootCharacterStyleGroup"},
+				Self:    src.RootCharacterStyleGroup.Self,
+			}
+		}
+		mergeStyleGroup(styles, styles.RootCharacterStyleGroup, src.RootCharacterStyleGroup, characterStyleGroups, merge)
+	}
+	if src.RootObjectStyleGroup != nil {
+		if styles.RootObjectStyleGroup == nil {
+			styles.RootObjectStyleGroup = &resources.ObjectStyleGroup{
+				XMLName: xml.Name{Local: "RootObjectStyleGroup"},
+				Self:    src.RootObjectStyleGroup.Self,
+			}
+		}
+		mergeStyleGroup(styles, styles.RootObjectStyleGroup, src.RootObjectStyleGroup, objectStyleGroups, merge)
+	}
+	if src.RootCellStyleGroup != nil {
+		if styles.RootCellStyleGroup == nil {
+			styles.RootCellStyleGroup = &resources.CellStyleGroup{
+				XMLName: xml.Name{Local: "RootCellStyleGroup"},
+				Self:    src.RootCellStyleGroup.Self,
+			}
+		}
+		mergeStyleGroup(styles, styles.RootCellStyleGroup, src.RootCellStyleGroup, cellStyleGroups, merge)
+	}
+	if src.RootTableStyleGroup != nil {
+		if styles.RootTableStyleGroup == nil {
+			styles.RootTableStyleGroup = &resources.TableStyleGroup{
+				XMLName: xml.Name{Local: "RootTableStyleGroup"},
+				Self:    src.RootTableStyleGroup.Self,
+			}
+		}
+		mergeStyleGroup(styles, styles.RootTableStyleGroup, src.RootTableStyleGroup, tableStyleGroups, merge)
+	}
+}
+
+// styleGroupAccessor gives mergeStyleGroup access to the fields of a style
+// group type G holding styles of type S.
+type styleGroupAccessor[G, S any] struct {
+	styles    func(*G) *[]S
+	groups    func(*G) *[]G
+	self      func(*G) string
+	empty     func(*G) G // a group with the Self and Name of g and no content
+	styleSelf func(*S) string
+	find      func(*resources.StylesFile, string) *S
+}
+
+var (
+	paragraphStyleGroups = styleGroupAccessor[resources.ParagraphStyleGroup, resources.ParagraphStyle]{
+		styles: func(g *resources.ParagraphStyleGroup) *[]resources.ParagraphStyle { return &g.ParagraphStyles },
+		groups: func(g *resources.ParagraphStyleGroup) *[]resources.ParagraphStyleGroup { return &g.NestedGroups },
+		self:   func(g *resources.ParagraphStyleGroup) string { return g.Self },
+		empty: func(g *resources.ParagraphStyleGroup) resources.ParagraphStyleGroup {
+			return resources.ParagraphStyleGroup{XMLName: g.XMLName, Self: g.Self, Name: g.Name}
+		},
+		styleSelf: func(s *resources.ParagraphStyle) string { return s.Self },
+		find:      (*resources.StylesFile).FindParagraphStyle,
+	}
+	characterStyleGroups = styleGroupAccessor[resources.CharacterStyleGroup, resources.CharacterStyle]{
+		styles: func(g *resources.CharacterStyleGroup) *[]resources.CharacterStyle { return &g.CharacterStyles },
+		groups: func(g *resources.CharacterStyleGroup) *[]resources.CharacterStyleGroup { return &g.NestedGroups },
+		self:   func(g *resources.CharacterStyleGroup) string { return g.Self },
+		empty: func(g *resources.CharacterStyleGroup) resources.CharacterStyleGroup {
+			return resources.CharacterStyleGroup{XMLName: g.XMLName, Self: g.Self, Name: g.Name}
+		},
+		styleSelf: func(s *resources.CharacterStyle) string { return s.Self },
+		find:      (*resources.StylesFile).FindCharacterStyle,
+	}
+	objectStyleGroups = styleGroupAccessor[resources.ObjectStyleGroup, resources.ObjectStyle]{
+		styles: func(g *resources.ObjectStyleGroup) *[]resources.ObjectStyle { return &g.ObjectStyles },
+		groups: func(g *resources.ObjectStyleGroup) *[]resources.ObjectStyleGroup { return &g.NestedGroups },
+		self:   func(g *resources.ObjectStyleGroup) string { return g.Self },
+		empty: func(g *resources.ObjectStyleGroup) resources.ObjectStyleGroup {
+			return resources.ObjectStyleGroup{XMLName: g.XMLName, Self: g.Self, Name: g.Name}
+		},
+		styleSelf: func(s *resources.ObjectStyle) string { return s.Self },
+		find:      (*resources.StylesFile).FindObjectStyle,
+	}
+	cellStyleGroups = styleGroupAccessor[resources.CellStyleGroup, resources.CellStyle]{
+		styles: func(g *resources.CellStyleGroup) *[]resources.CellStyle { return &g.CellStyles },
+		groups: func(g *resources.CellStyleGroup) *[]resources.CellStyleGroup { return &g.NestedGroups },
+		self:   func(g *resources.CellStyleGroup) string { return g.Self },
+		empty: func(g *resources.CellStyleGroup) resources.CellStyleGroup {
+			return resources.CellStyleGroup{XMLName: g.XMLName, Self: g.Self, Name: g.Name}
+		},
+		styleSelf: func(s *resources.CellStyle) string { return s.Self },
+		find:      (*resources.StylesFile).FindCellStyle,
+	}
+	tableStyleGroups = styleGroupAccessor[resources.TableStyleGroup, resources.TableStyle]{
+		styles: func(g *resources.TableStyleGroup) *[]resources.TableStyle { return &g.TableStyles },
+		groups: func(g *resources.TableStyleGroup) *[]resources.TableStyleGroup { return &g.NestedGroups },
+		self:   func(g *resources.TableStyleGroup) string { return g.Self },
+		empty: func(g *resources.TableStyleGroup) resources.TableStyleGroup {
+			return resources.TableStyleGroup{XMLName: g.XMLName, Self: g.Self, Name: g.Name}
+		},
+		styleSelf: func(s *resources.TableStyle) string { return s.Self },
+		find:      (*resources.StylesFile).FindTableStyle,
+	}
+)
+
+// mergeStyleGroup recursively copies the styles of src that merge selects
+// to dst, creating nested groups as needed. Styles that exist elsewhere in
+// styles are replaced where they are.
+func mergeStyleGroup[G, S any](styles *resources.StylesFile, dst, src *G, acc styleGroupAccessor[G, S], merge styleMerger) {
+	for _, style := range *acc.styles(src) {
+		existing := acc.find(styles, acc.styleSelf(&style))
+		switch {
+		case !merge(acc.styleSelf(&style), existing != nil):
+		case existing != nil:
+			*existing = style
+		default:
+			*acc.styles(dst) = append(*acc.styles(dst), style)
+		}
+	}
+	dstGroups := acc.groups(dst)
+	for i := range *acc.groups(src) {
+		nested := &(*acc.groups(src))[i]
+		j := -1
+		for k := range *dstGroups {
+			if acc.self(&(*dstGroups)[k]) == acc.self(nested) {
+				j = k
+				break
+			}
+		}
+		if j < 0 {
+			*dstGroups = append(*dstGroups, acc.empty(nested))
+			j = len(*dstGroups) - 1
+		}
+		mergeStyleGroup(styles, &(*dstGroups)[j], nested, acc, merge)
+	}
+}
+
+// mergeSnippetGraphics adds the snippet's colors, swatches and stroke styles
+// that are missing from graphics.
+func mergeSnippetGraphics(graphics *resources.GraphicFile, src *document.Document) {
+	existing := make(map[string]bool)
+	for _, c := range graphics.Colors {
+		existing[c.Self] = true
+	}
+	for _, s := range graphics.Swatches {
+		existing[s.Self] = true
+	}
+	for _, s := range graphics.StrokeStyles {
+		existing[s.Self] = true
+	}
+
+	for _, c := range src.Colors {
+		if !existing[c.Self] {
+			graphics.Colors = append(graphics.Colors, c)
+		}
+	}
+	for _, s := range src.Swatches {
+		if !existing[s.Self] {
+			graphics.Swatches = append(graphics.Swatches, s)
+		}
+	}
+	for _, s := range src.StrokeStyles {
+		if !existing[s.Self] {
+			graphics.StrokeStyles = append(graphics.StrokeStyles, s)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package idml
+
+import (
+	"encoding/xml"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/dimelords/idmllib/v2/internal/testutil"
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/document"
+	"github.com/dimelords/idmllib/v2/pkg/resources"
+	"github.com/dimelords/idmllib/v2/pkg/spread"
+)
+
+// docSnippet is a Snippet backed by a plain Document.
+type docSnippet struct {
+	doc *document.Document
+}
+
+func (s docSnippet) SnippetDocument() *document.Document {
+	return s.doc
+}
+
+// loadSnippet parses an IDMS test file into a Snippet.
+func loadSnippet(t *testing.T, filename string) docSnippet {
+	t.Helper()
+
+	data, err := os.ReadFile(testutil.TestDataPath(t, filename))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	doc, err := document.ParseDocument(data)
+	if err != nil {
+		t.Fatalf("ParseDocument failed: %v", err)
+	}
+	return docSnippet{doc: doc}
+}
+
+// findTextFrame returns the text frame with the given ID in a spread, or nil.
+func findTextFrame(sp *spread.Spread, id string) *spread.SpreadTextFrame {
+	for i := range sp.InnerSpread.TextFrames {
+		if sp.InnerSpread.TextFrames[i].Self == id {
+			return &sp.InnerSpread.TextFrames[i]
+		}
+	}
+	return nil
+}
+
+// TestPlaceSnippet_RenumbersCollidingIDs places a snippet exported from
+// example.idml back into it, so its frame, rectangle and story IDs collide.
+func TestPlaceSnippet_RenumbersCollidingIDs(t *testing.T) {
+	pkg := loadExampleIDML(t)
+	snip := loadSnippet(t, "Snippet_31F27A387.idms")
+
+	sp, err := pkg.Spread(exampleSpread)
+	if err != nil {
+		t.Fatalf("Spread failed: %v", err)
+	}
+	frames, rects := len(sp.InnerSpread.TextFrames), len(sp.InnerSpread.Rectangles)
+	original := findTextFrame(sp, "u300").ItemTransform
+
+	if err := pkg.PlaceSnippet(snip, exampleSpread, 10, 20); err != nil {
+		t.Fatalf("PlaceSnippet failed: %v", err)
+	}
+
+	if len(sp.InnerSpread.TextFrames) != frames+1 || len(sp.InnerSpread.Rectangles) != rects+1 {
+		t.Fatalf("got %d frames and %d rectangles, want %d and %d",
+			len(sp.InnerSpread.TextFrames), len(sp.InnerSpread.Rectangles), frames+1, rects+1)
+	}
+	if got := findTextFrame(sp, "u300").ItemTransform; got != original {
+		t.Errorf("existing frame moved: ItemTransform = %q, want %q", got, original)
+	}
+
+	placed := sp.InnerSpread.TextFrames[frames]
+	if placed.Self == "u300" || placed.ParentStory == "u2ee" {
+		t.Fatalf("placed frame kept colliding IDs: Self %q, ParentStory %q", placed.Self, placed.ParentStory)
+	}
+	if placed.ItemLayer != "uba" {
+		t.Errorf("ItemLayer = %q, want existing layer uba", placed.ItemLayer)
+	}
+	if sp.InnerSpread.Rectangles[rects].Self == "u264" {
+		t.Error("placed rectangle kept colliding ID u264")
+	}
+
+	snipFrame := snip.doc.InlineSpreads[0].TextFrames[0]
+	if err := snipFrame.Translate(10, 20); err != nil {
+		t.Fatalf("Translate failed: %v", err)
+	}
+	if placed.ItemTransform != snipFrame.ItemTransform {
+		t.Errorf("ItemTransform = %q, want %q", placed.ItemTransform, snipFrame.ItemTransform)
+	}
+
+	// The snippet's story is added under its new ID and registered
+	st, err := pkg.Story(StoryPath(placed.ParentStory))
+	if err != nil {
+		t.Fatalf("Story(%s) failed: %v", placed.ParentStory, err)
+	}
+	if st.StoryElement.Self != placed.ParentStory || st.ExtractText() == "" {
+		t.Errorf("placed story = %q with text %q", st.StoryElement.Self, st.ExtractText())
+	}
+	doc, err := pkg.Document()
+	if err != nil {
+		t.Fatalf("Document failed: %v", err)
+	}
+	if !strings.HasSuffix(doc.StoryList, " "+placed.ParentStory) {
+		t.Errorf("StoryList = %q, want it to end with %s", doc.StoryList, placed.ParentStory)
+	}
+	if last := doc.Stories[len(doc.Stories)-1]; last.Src != StoryPath(placed.ParentStory) {
+		t.Errorf("last story ref = %q", last.Src)
+	}
+
+	// All Self IDs stay unique after a roundtrip
+	reread, err := Read(writeTestIDML(t, pkg, "placed.idml"))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	seen := make(map[string]string)
+	for _, filename := range reread.Files() {
+		if !strings.HasSuffix(filename, ".xml") || strings.HasPrefix(filename, "Resources/") {
+			continue
+		}
+		data, err := reread.getFileData(filename)
+		if err != nil {
+			t.Fatalf("getFileData failed: %v", err)
+		}
+		for _, m := range selfAttrPattern.FindAllSubmatch(data, -1) {
+			id := string(m[1])
+			if prev, dup := seen[id]; dup && id != "" {
+				t.Errorf("Self %q in %s is also used in %s", id, filename, prev)
+			}
+			seen[id] = filename
+		}
+	}
+}
+
+// TestPlaceSnippet_MergesResources tests merging of styles, colors and layers.
+func TestPlaceSnippet_MergesResources(t *testing.T) {
+	pkg := loadExampleIDML(t)
+
+	rect := spread.Rectangle{
+		PageItemBase:       spread.PageItemBase{Self: "uf00", ItemLayer: "uba", ItemTransform: "1 0 0 1 0 0"},
+		AppliedObjectStyle: "ObjectStyle/Snippet Box",
+	}
+	doc := &document.Document{
+		Colors: []resources.Color{
+			{Self: "Color/Black", Name: "Black", Model: "Process", Space: "CMYK", ColorValue: "0 0 0 50"},
+			{Self: "Color/Snippet Red", Name: "Snippet Red", Model: "Process", Space: "CMYK", ColorValue: "0 100 100 0"},
+		},
+		Swatches: []resources.Swatch{{Self: "Swatch/None", Name: "None"}},
+		RootParagraphStyleGroup: &resources.ParagraphStyleGroup{
+			XMLName: xml.Name{Local: "RootParagraphStyleGroup"},
+			Self:    "u7f",
+			NestedGroups: []resources.ParagraphStyleGroup{{
+				XMLName:         xml.Name{Local: "ParagraphStyleGroup"},
+				Self:            "ParagraphStyleGroup/Snippet",
+				Name:            "Snippet",
+				ParagraphStyles: []resources.ParagraphStyle{{Self: "ParagraphStyle/Snippet%3aBody", Name: "Snippet:Body"}},
+			}},
+		},
+		RootObjectStyleGroup: &resources.ObjectStyleGroup{
+			XMLName:      xml.Name{Local: "RootObjectStyleGroup"},
+			Self:         "u88",
+			ObjectStyles: []resources.ObjectStyle{{Self: "ObjectStyle/Snippet Box", Name: "Snippet Box"}},
+		},
+		// Same ID as the Editorial layer, but a different layer
+		Layers:        []document.Layer{{Self: "uba", Name: "Snippet Layer"}},
+		InlineSpreads: []spread.SpreadElement{{Self: "u10", Rectangles: []spread.Rectangle{rect}}},
+	}
+
+	if err := pkg.PlaceSnippet(docSnippet{doc: doc}, exampleSpread, 0, 0); err != nil {
+		t.Fatalf("PlaceSnippet failed: %v", err)
+	}
+
+	graphics, err := pkg.Graphics()
+	if err != nil {
+		t.Fatalf("Graphics failed: %v", err)
+	}
+	var black, red int
+	for _, c := range graphics.Colors {
+		switch c.Self {
+		case "Color/Black":
+			black++
+			if c.ColorValue != "0 0 0 100" {
+				t.Errorf("existing Color/Black was replaced: %q", c.ColorValue)
+			}
+		case "Color/Snippet Red":
+			red++
+		}
+	}
+	if black != 1 || red != 1 {
+		t.Errorf("got %d Color/Black and %d Color/Snippet Red, want 1 each", black, red)
+	}
+
+	styles, err := pkg.Styles()
+	if err != nil {
+		t.Fatalf("Styles failed: %v", err)
+	}
+	if styles.FindParagraphStyle("ParagraphStyle/Snippet%3aBody") == nil {
+		t.Error("paragraph style from nested snippet group was not merged")
+	}
+	if styles.FindObjectStyle("ObjectStyle/Snippet Box") == nil {
+		t.Error("object style was not merged")
+	}
+
+	target, err := pkg.Document()
+	if err != nil {
+		t.Fatalf("Document failed: %v", err)
+	}
+	layer := target.Layers[len(target.Layers)-1]
+	if layer.Name != "Snippet Layer" || layer.Self == "uba" {
+		t.Fatalf("last layer = %+v, want Snippet Layer with a new ID", layer)
+	}
+
+	sp, err := pkg.Spread(exampleSpread)
+	if err != nil {
+		t.Fatalf("Spread failed: %v", err)
+	}
+	placed := sp.InnerSpread.Rectangles[len(sp.InnerSpread.Rectangles)-1]
+	if placed.Self != "uf00" || placed.ItemLayer != layer.Self {
+		t.Errorf("placed rectangle = %s on layer %s, want uf00 on %s", placed.Self, placed.ItemLayer, layer.Self)
+	}
+	if doc.InlineSpreads[0].Rectangles[0].ItemLayer != "uba" {
+		t.Error("PlaceSnippet modified the snippet")
+	}
+}
+
+// TestPlaceSnippet_Errors tests invalid snippets and unknown spreads.
+func TestPlaceSnippet_Errors(t *testing.T) {
+	pkg := loadExampleIDML(t)
+
+	if err := pkg.PlaceSnippet(nil, exampleSpread, 0, 0); err == nil {
+		t.Error("expected error for nil snippet")
+	}
+	if err := pkg.PlaceSnippet(docSnippet{}, exampleSpread, 0, 0); err == nil {
+		t.Error("expected error for snippet without document")
+	}
+
+	snip := loadSnippet(t, "Snippet_31F27A2D0.idms")
+	err := pkg.PlaceSnippet(snip, "Spreads/Spread_missing.xml", 0, 0)
+	if !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("error = %v, want ErrNotFound", err)
+	}
+}
+
+// TestRenameIDReferences tests that colliding IDs are renamed inside list
+// values and only in ID reference attributes.
+func TestRenameIDReferences(t *testing.T) {
+	renames := map[string]string{"u2": "u10", "u3": "u11"}
+	data := `<Root Self="u2" StoryList="u1 u2 u3" Name="u2"><TextFrame Self="u4" ParentStory="u3" PreviousTextFrame="n" Label="u3" /></Root>`
+	want := `<Root Self="u10" StoryList="u1 u10 u11" Name="u2"><TextFrame Self="u4" ParentStory="u11" PreviousTextFrame="n" Label="u3" /></Root>`
+
+	if got := string(renameIDReferences([]byte(data), renames)); got != want {
+		t.Errorf("renameIDReferences =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,95 @@
+package idml
+
+import (
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/spread"
+	"github.com/dimelords/idmllib/v2/pkg/story"
+)
+
+// ExtractStoryText returns the text of a story with its special characters
+// rendered, like story.Story.ExtractTextWithOptions with
+// opts.SpecialCharacters set.
+//
+// Unless opts sets them, the auto page number and section marker are taken
+// from the page of the first frame showing the story: the page name (e.g.,
+// "7", or "A" on a master page) and the Marker of the section the page
+// belongs to. A story shown in several frames gets the page of its first
+// frame throughout. storyID may be a story Self ID or a story path.
+//
+// Example:
+//
+//	text, err := pkg.ExtractStoryText("u1d8", story.TextOptions{PlainSpaces: true})
+func (p *Package) ExtractStoryText(storyID string, opts story.TextOptions) (string, error) {
+	const op = "extract story text"
+
+	id := normalizeStoryID(storyID)
+	st, err := p.Story(StoryPath(id))
+	if err != nil {
+		return "", common.WrapError("idml", op, err)
+	}
+
+	opts.SpecialCharacters = true
+	if opts.PageNumber == "" || opts.SectionMarker == "" {
+		pageNumber, marker, err := p.storyPageNumber(id)
+		if err != nil {
+			return "", common.WrapError("idml", op, err)
+		}
+		if opts.PageNumber == "" {
+			opts.PageNumber = pageNumber
+		}
+		if opts.SectionMarker == "" {
+			opts.SectionMarker = marker
+		}
+	}
+	return st.ExtractTextWithOptions(opts), nil
+}
+
+// storyPageNumber returns the page name and section marker of the page
+// holding the first frame of the story, searching document spreads before
+// master spreads. Both are empty if no frame on a page shows the story.
+func (p *Package) storyPageNumber(storyID string) (pageNumber, marker string, err error) {
+	containers, _, err := p.pageContainers()
+	if err != nil {
+		return "", "", err
+	}
+	doc, err := p.Document()
+	if err != nil {
+		return "", "", err
+	}
+
+	// The section of a page is the last section starting at or before it
+	markers := make(map[string]string)
+	for _, section := range doc.Sections {
+		markers[section.PageStart] = section.Marker
+	}
+	var current string
+	for _, c := range containers {
+		for i := range c.pages {
+			page := &c.pages[i]
+			if m, ok := markers[page.Self]; ok && c.masterID == "" {
+				current = m
+			}
+			if c.masterID == "" {
+				markers[page.Self] = current
+			}
+
+			rect, err := page.SpreadRect()
+			if err != nil {
+				continue
+			}
+			for _, item := range c.items {
+				frame, ok := item.(*spread.SpreadTextFrame)
+				if !ok || frame.ParentStory != storyID {
+					continue
+				}
+				if center, ok := pageItemCenter(frame); ok && rect.Contains(center) {
+					if c.masterID != "" {
+						return page.Name, "", nil
+					}
+					return page.Name, markers[page.Self], nil
+				}
+			}
+		}
+	}
+	return "", "", nil
+}
@@ -0,0 +1,59 @@
+package idml
+
+import (
+	"testing"
+
+	"github.com/dimelords/idmllib/v2/pkg/story"
+)
+
+// TestExtractStoryText tests resolving the auto page number and section
+// marker against the page of the story's frame.
+func TestExtractStoryText(t *testing.T) {
+	pkg := loadExampleIDML(t)
+
+	st, err := pkg.Story(hyperlinkStory)
+	if err != nil {
+		t.Fatalf("Story failed: %v", err)
+	}
+	// Each insertion at offset 0 goes before the zero-width markers already there
+	if err := st.InsertSpecialCharacter(0, story.SpecialAutoPageNumber); err != nil {
+		t.Fatalf("InsertSpecialCharacter failed: %v", err)
+	}
+	if err := st.InsertSpecialCharacter(0, story.SpecialSectionMarker); err != nil {
+		t.Fatalf("InsertSpecialCharacter failed: %v", err)
+	}
+	if err := st.InsertText(0, "Sports "); err != nil {
+		t.Fatalf("InsertText failed: %v", err)
+	}
+
+	doc, err := pkg.Document()
+	if err != nil {
+		t.Fatalf("Document failed: %v", err)
+	}
+	doc.Sections[0].Marker = "Weekend"
+
+	reread, err := Read(writeTestIDML(t, pkg, "folio.idml"))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+
+	got, err := reread.ExtractStoryText("u2ee", story.TextOptions{})
+	if err != nil {
+		t.Fatalf("ExtractStoryText failed: %v", err)
+	}
+	if want := "A22WeekendSports ANNA ipsum"; len(got) < len(want) || got[:len(want)] != want {
+		t.Errorf("ExtractStoryText() = %q, want prefix %q", got, want)
+	}
+
+	got, err = reread.ExtractStoryText(hyperlinkStory, story.TextOptions{PageNumber: "7"})
+	if err != nil {
+		t.Fatalf("ExtractStoryText failed: %v", err)
+	}
+	if want := "7WeekendSports ANNA"; got[:len(want)] != want {
+		t.Errorf("ExtractStoryText() with PageNumber = %q", got)
+	}
+
+	if _, err := reread.ExtractStoryText("u999", story.TextOptions{}); err == nil {
+		t.Error("expected error for a missing story")
+	}
+}
@@ -33,7 +33,7 @@ func ParseStylesForHierarchy(data []byte) ([]StyleInfo, error) {
 
 			// Check if this is a style element
 			switch t.Name.Local {
-			case "ParagraphStyle", "CharacterStyle", "ObjectStyle":
+			case "ParagraphStyle", "CharacterStyle", "ObjectStyle", "CellStyle", "TableStyle":
 				foundStyle = true
 			}
 
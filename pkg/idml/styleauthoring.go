@@ -0,0 +1,881 @@
+package idml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/document"
+	"github.com/dimelords/idmllib/v2/pkg/resources"
+	"github.com/dimelords/idmllib/v2/pkg/spread"
+	"github.com/dimelords/idmllib/v2/pkg/story"
+)
+
+// Style ID prefixes of the style kinds that can be edited.
+const (
+	paragraphStylePrefix = "ParagraphStyle/"
+	characterStylePrefix = "CharacterStyle/"
+	objectStylePrefix    = "ObjectStyle/"
+)
+
+// objectValuePattern matches object references stored as element content,
+// such as <BasedOn type="object">ParagraphStyle/Body</BasedOn>.
+var objectValuePattern = regexp.MustCompile(`<([\w:]+) type="object">([^<]*)</[\w:]+>`)
+
+// styleProperties are the style settings that InDesign stores as Properties
+// children rather than attributes, with their type for CreateParagraphStyle
+// and CreateCharacterStyle.
+var styleProperties = map[string]string{
+	"AppliedFont": "string",
+	"Leading":     "unit",
+}
+
+// CreateParagraphStyle adds a paragraph style to Styles.xml and returns its
+// Self.
+//
+// group is the Self of the style group to add the style to (e.g.,
+// "ParagraphStyleGroup/$ID/Headlines"), or "" for the root group. The Self
+// of the style follows InDesign's naming: the group path and name joined by
+// ":", which is escaped as "%3a" ("ParagraphStyle/Headlines%3aLarge").
+//
+// basedOn is the Self of the parent style, or "" for [No paragraph style].
+// attrs sets formatting attributes such as PointSize or Justification;
+// AppliedFont and Leading are written as Properties children, as InDesign
+// does.
+//
+// Returns common.ErrNotFound if the group or parent style does not exist and
+// common.ErrAlreadyExists if a style with the same Self exists.
+//
+// Example:
+//
+//	id, err := pkg.CreateParagraphStyle("", "Body Large", "ParagraphStyle/Body", map[string]string{
+//	    "PointSize":   "12",
+//	    "AppliedFont": "Minion Pro",
+//	})
+func (p *Package) CreateParagraphStyle(group, name, basedOn string, attrs map[string]string) (string, error) {
+	const op = "create paragraph style"
+
+	styles, err := p.Styles()
+	if err != nil {
+		return "", common.WrapErrorWithPath("idml", op, PathStyles, err)
+	}
+	dst := findParagraphStyleGroup(styles.RootParagraphStyleGroup, group)
+	if dst == nil {
+		return "", common.WrapErrorWithPath("idml", op, group, common.ErrNotFound)
+	}
+	if basedOn == "" {
+		basedOn = paragraphStylePrefix + "$ID/[No paragraph style]"
+	} else if styles.FindParagraphStyle(basedOn) == nil {
+		return "", common.WrapErrorWithPath("idml", op, basedOn, common.ErrNotFound)
+	}
+
+	id, err := newStyleID(op, paragraphStylePrefix, dst.Name, name)
+	if err != nil {
+		return "", err
+	}
+	if styles.FindParagraphStyle(id) != nil {
+		return "", common.WrapErrorWithPath("idml", op, id, common.ErrAlreadyExists)
+	}
+
+	var style resources.ParagraphStyle
+	if err := decodeNewStyle("ParagraphStyle", paragraphStylePrefix, id, name, basedOn, attrs, &style); err != nil {
+		return "", common.WrapErrorWithPath("idml", op, id, err)
+	}
+	dst.ParagraphStyles = append(dst.ParagraphStyles, style)
+	p.SetStyles(styles)
+	return id, nil
+}
+
+// CreateCharacterStyle adds a character style to Styles.xml and returns its
+// Self, as CreateParagraphStyle. basedOn "" means [No character style].
+func (p *Package) CreateCharacterStyle(group, name, basedOn string, attrs map[string]string) (string, error) {
+	const op = "create character style"
+
+	styles, err := p.Styles()
+	if err != nil {
+		return "", common.WrapErrorWithPath("idml", op, PathStyles, err)
+	}
+	dst := findCharacterStyleGroup(styles.RootCharacterStyleGroup, group)
+	if dst == nil {
+		return "", common.WrapErrorWithPath("idml", op, group, common.ErrNotFound)
+	}
+	if basedOn == "" {
+		basedOn = characterStylePrefix + "$ID/[No character style]"
+	} else if styles.FindCharacterStyle(basedOn) == nil {
+		return "", common.WrapErrorWithPath("idml", op, basedOn, common.ErrNotFound)
+	}
+
+	id, err := newStyleID(op, characterStylePrefix, dst.Name, name)
+	if err != nil {
+		return "", err
+	}
+	if styles.FindCharacterStyle(id) != nil {
+		return "", common.WrapErrorWithPath("idml", op, id, common.ErrAlreadyExists)
+	}
+
+	var style resources.CharacterStyle
+	if err := decodeNewStyle("CharacterStyle", characterStylePrefix, id, name, basedOn, attrs, &style); err != nil {
+		return "", common.WrapErrorWithPath("idml", op, id, err)
+	}
+	dst.CharacterStyles = append(dst.CharacterStyles, style)
+	p.SetStyles(styles)
+	return id, nil
+}
+
+// RenameStyle renames a paragraph, character or object style and returns its
+// new Self. The style stays in its group; references to it are rewritten as
+// in DeleteStyle.
+//
+// Returns common.ErrNotFound if the style does not exist and
+// common.ErrAlreadyExists if the new Self is taken. Built-in styles such as
+// "ParagraphStyle/$ID/NormalParagraphStyle" cannot be renamed.
+func (p *Package) RenameStyle(styleID, newName string) (string, error) {
+	const op = "rename style"
+
+	styles, err := p.editableStyles(op, styleID)
+	if err != nil {
+		return "", err
+	}
+
+	var newID string
+	switch stylePrefix(styleID) {
+	case paragraphStylePrefix:
+		group, i := paragraphStyleParent(styles.RootParagraphStyleGroup, styleID)
+		if group == nil {
+			return "", common.WrapErrorWithPath("idml", op, styleID, common.ErrNotFound)
+		}
+		if newID, err = newStyleID(op, paragraphStylePrefix, group.Name, newName); err != nil {
+			return "", err
+		}
+		if newID != styleID && styles.FindParagraphStyle(newID) != nil {
+			return "", common.WrapErrorWithPath("idml", op, newID, common.ErrAlreadyExists)
+		}
+		group.ParagraphStyles[i].Self, group.ParagraphStyles[i].Name = newID, newName
+	case characterStylePrefix:
+		group, i := characterStyleParent(styles.RootCharacterStyleGroup, styleID)
+		if group == nil {
+			return "", common.WrapErrorWithPath("idml", op, styleID, common.ErrNotFound)
+		}
+		if newID, err = newStyleID(op, characterStylePrefix, group.Name, newName); err != nil {
+			return "", err
+		}
+		if newID != styleID && styles.FindCharacterStyle(newID) != nil {
+			return "", common.WrapErrorWithPath("idml", op, newID, common.ErrAlreadyExists)
+		}
+		group.CharacterStyles[i].Self, group.CharacterStyles[i].Name = newID, newName
+	case objectStylePrefix:
+		group, i := objectStyleParent(styles.RootObjectStyleGroup, styleID)
+		if group == nil {
+			return "", common.WrapErrorWithPath("idml", op, styleID, common.ErrNotFound)
+		}
+		if newID, err = newStyleID(op, objectStylePrefix, group.Name, newName); err != nil {
+			return "", err
+		}
+		if newID != styleID && styles.FindObjectStyle(newID) != nil {
+			return "", common.WrapErrorWithPath("idml", op, newID, common.ErrAlreadyExists)
+		}
+		group.ObjectStyles[i].Self, group.ObjectStyles[i].Name = newID, newName
+	}
+
+	p.SetStyles(styles)
+	if newID == styleID {
+		return newID, nil
+	}
+	return newID, p.renameStyleReferences(op, map[string]string{styleID: newID})
+}
+
+// MoveStyleToGroup moves a paragraph, character or object style to another
+// group and returns its new Self, which reflects the group path. group is
+// the Self of the target group, or "" for the root group. References to the
+// style are rewritten as in DeleteStyle.
+//
+// Returns common.ErrNotFound if the style or group does not exist and
+// common.ErrAlreadyExists if the group already has a style with that name.
+//
+// Example:
+//
+//	id, err := pkg.MoveStyleToGroup("ParagraphStyle/Body", "ParagraphStyleGroup/$ID/Text")
+//	// id == "ParagraphStyle/Text%3aBody"
+func (p *Package) MoveStyleToGroup(styleID, group string) (string, error) {
+	const op = "move style to group"
+
+	styles, err := p.editableStyles(op, styleID)
+	if err != nil {
+		return "", err
+	}
+
+	var newID string
+	switch stylePrefix(styleID) {
+	case paragraphStylePrefix:
+		from, i := paragraphStyleParent(styles.RootParagraphStyleGroup, styleID)
+		to := findParagraphStyleGroup(styles.RootParagraphStyleGroup, group)
+		if from == nil || to == nil {
+			return "", common.WrapErrorWithPath("idml", op, styleID+" -> "+group, common.ErrNotFound)
+		}
+		style := from.ParagraphStyles[i]
+		style.Name = styleBaseName(style.Name)
+		if newID, err = newStyleID(op, paragraphStylePrefix, to.Name, style.Name); err != nil {
+			return "", err
+		}
+		if from == to {
+			return styleID, nil
+		}
+		if styles.FindParagraphStyle(newID) != nil {
+			return "", common.WrapErrorWithPath("idml", op, newID, common.ErrAlreadyExists)
+		}
+		style.Self = newID
+		from.ParagraphStyles = append(from.ParagraphStyles[:i], from.ParagraphStyles[i+1:]...)
+		to.ParagraphStyles = append(to.ParagraphStyles, style)
+	case characterStylePrefix:
+		from, i := characterStyleParent(styles.RootCharacterStyleGroup, styleID)
+		to := findCharacterStyleGroup(styles.RootCharacterStyleGroup, group)
+		if from == nil || to == nil {
+			return "", common.WrapErrorWithPath("idml", op, styleID+" -> "+group, common.ErrNotFound)
+		}
+		style := from.CharacterStyles[i]
+		style.Name = styleBaseName(style.Name)
+		if newID, err = newStyleID(op, characterStylePrefix, to.Name, style.Name); err != nil {
+			return "", err
+		}
+		if from == to {
+			return styleID, nil
+		}
+		if styles.FindCharacterStyle(newID) != nil {
+			return "", common.WrapErrorWithPath("idml", op, newID, common.ErrAlreadyExists)
+		}
+		style.Self = newID
+		from.CharacterStyles = append(from.CharacterStyles[:i], from.CharacterStyles[i+1:]...)
+		to.CharacterStyles = append(to.CharacterStyles, style)
+	case objectStylePrefix:
+		from, i := objectStyleParent(styles.RootObjectStyleGroup, styleID)
+		to := findObjectStyleGroup(styles.RootObjectStyleGroup, group)
+		if from == nil || to == nil {
+			return "", common.WrapErrorWithPath("idml", op, styleID+" -> "+group, common.ErrNotFound)
+		}
+		style := from.ObjectStyles[i]
+		style.Name = styleBaseName(style.Name)
+		if newID, err = newStyleID(op, objectStylePrefix, to.Name, style.Name); err != nil {
+			return "", err
+		}
+		if from == to {
+			return styleID, nil
+		}
+		if styles.FindObjectStyle(newID) != nil {
+			return "", common.WrapErrorWithPath("idml", op, newID, common.ErrAlreadyExists)
+		}
+		style.Self = newID
+		from.ObjectStyles = append(from.ObjectStyles[:i], from.ObjectStyles[i+1:]...)
+		to.ObjectStyles = append(to.ObjectStyles, style)
+	}
+
+	p.SetStyles(styles)
+	if newID == styleID {
+		return newID, nil
+	}
+	return newID, p.renameStyleReferences(op, map[string]string{styleID: newID})
+}
+
+// DeleteStyle removes a paragraph, character or object style from
+// Styles.xml and replaces every reference to it with replacementID.
+//
+// Styles based on the deleted style, including the replacement, are based
+// on the deleted style's parent afterwards. Other references are rewritten
+// in Styles.xml (NextStyle, the applied
+// styles of object styles, nested and bullet character styles, ...), in
+// stories (AppliedParagraphStyle, AppliedCharacterStyle and anchored
+// objects), in spreads and master spreads (AppliedObjectStyle) and in
+// designmap.xml and Preferences.xml.
+//
+// replacementID must be a style of the same kind; "" means
+// [No paragraph style], [No character style] or [None]. Built-in styles
+// cannot be deleted.
+//
+// Returns common.ErrNotFound if the style or the replacement does not exist.
+//
+// Example:
+//
+//	err := pkg.DeleteStyle("CharacterStyle/Old Emphasis", "CharacterStyle/Emphasis")
+func (p *Package) DeleteStyle(styleID, replacementID string) error {
+	const op = "delete style"
+
+	styles, err := p.editableStyles(op, styleID)
+	if err != nil {
+		return err
+	}
+	prefix := stylePrefix(styleID)
+	if replacementID == "" {
+		replacementID = defaultReplacementStyle(prefix)
+	}
+	if stylePrefix(replacementID) != prefix || replacementID == styleID {
+		return common.Errorf("idml", op, styleID, "invalid replacement style %q", replacementID)
+	}
+
+	// Styles based on the deleted style, the replacement among them, are
+	// based on its parent instead, so no style ends up based on itself
+	props := stylePropertiesBySelf(styles, prefix)
+	deleted, ok := props[styleID]
+	if !ok {
+		return common.WrapErrorWithPath("idml", op, styleID, common.ErrNotFound)
+	}
+	parent := styleBasedOn(prefix, *deleted)
+	if parent == "" {
+		parent = defaultReplacementStyle(prefix)
+	}
+	var children []string
+	for self, childProps := range props {
+		if self != styleID && styleBasedOn(prefix, *childProps) == styleID {
+			if parent == self {
+				return common.Errorf("idml", op, styleID, "style %q would be based on itself", self)
+			}
+			children = append(children, self)
+		}
+	}
+
+	switch prefix {
+	case paragraphStylePrefix:
+		group, i := paragraphStyleParent(styles.RootParagraphStyleGroup, styleID)
+		if group == nil {
+			return common.WrapErrorWithPath("idml", op, styleID, common.ErrNotFound)
+		}
+		if styles.FindParagraphStyle(replacementID) == nil {
+			return common.WrapErrorWithPath("idml", op, replacementID, common.ErrNotFound)
+		}
+		group.ParagraphStyles = append(group.ParagraphStyles[:i], group.ParagraphStyles[i+1:]...)
+	case characterStylePrefix:
+		group, i := characterStyleParent(styles.RootCharacterStyleGroup, styleID)
+		if group == nil {
+			return common.WrapErrorWithPath("idml", op, styleID, common.ErrNotFound)
+		}
+		if styles.FindCharacterStyle(replacementID) == nil {
+			return common.WrapErrorWithPath("idml", op, replacementID, common.ErrNotFound)
+		}
+		group.CharacterStyles = append(group.CharacterStyles[:i], group.CharacterStyles[i+1:]...)
+	case objectStylePrefix:
+		group, i := objectStyleParent(styles.RootObjectStyleGroup, styleID)
+		if group == nil {
+			return common.WrapErrorWithPath("idml", op, styleID, common.ErrNotFound)
+		}
+		if styles.FindObjectStyle(replacementID) == nil {
+			return common.WrapErrorWithPath("idml", op, replacementID, common.ErrNotFound)
+		}
+		group.ObjectStyles = append(group.ObjectStyles[:i], group.ObjectStyles[i+1:]...)
+	}
+
+	for _, self := range children {
+		setStyleBasedOn(props[self], prefix, parent)
+	}
+
+	p.SetStyles(styles)
+	return p.renameStyleReferences(op, map[string]string{styleID: replacementID})
+}
+
+// editableStyles returns the styles of the package if styleID is a
+// paragraph, character or object style that is not built in.
+func (p *Package) editableStyles(op, styleID string) (*resources.StylesFile, error) {
+	prefix := stylePrefix(styleID)
+	if prefix == "" {
+		return nil, common.Errorf("idml", op, styleID, "not a paragraph, character or object style")
+	}
+	if strings.HasPrefix(styleID, prefix+"$ID/") {
+		return nil, common.Errorf("idml", op, styleID, "built-in styles cannot be changed")
+	}
+	styles, err := p.Styles()
+	if err != nil {
+		return nil, common.WrapErrorWithPath("idml", op, PathStyles, err)
+	}
+	return styles, nil
+}
+
+// renameStyleReferences replaces references to the style IDs in renames
+// throughout Styles.xml, Preferences.xml, designmap.xml, stories, spreads
+// and master spreads.
+//
+// Files are marshaled, references are replaced in attribute values and in
+// object-typed elements, and files that changed are parsed back in place,
+// so structs already returned by the package stay current.
+func (p *Package) renameStyleReferences(op string, renames map[string]string) error {
+	styles, err := p.Styles()
+	if err != nil {
+		return common.WrapErrorWithPath("idml", op, PathStyles, err)
+	}
+	data, err := resources.MarshalStylesFile(styles)
+	if err != nil {
+		return common.WrapErrorWithPath("idml", op, PathStyles, err)
+	}
+	if renamed, changed := renameStyleRefs(data, renames); changed {
+		parsed, err := resources.ParseStylesFile(renamed)
+		if err != nil {
+			return common.WrapErrorWithPath("idml", op, PathStyles, err)
+		}
+		*styles = *parsed
+		p.SetStyles(styles)
+	}
+
+	stories, err := p.Stories()
+	if err != nil {
+		return common.WrapError("idml", op, err)
+	}
+	for filename, st := range stories {
+		data, err := story.MarshalStory(st)
+		if err != nil {
+			return common.WrapErrorWithPath("idml", op, filename, err)
+		}
+		renamed, changed := renameStyleRefs(data, renames)
+		if !changed {
+			continue
+		}
+		parsed, err := story.ParseStory(renamed)
+		if err != nil {
+			return common.WrapErrorWithPath("idml", op, filename, err)
+		}
+		*st = *parsed
+		if err := p.marshalAndUpdateStory(filename, st); err != nil {
+			return err
+		}
+	}
+
+	spreads, err := p.Spreads()
+	if err != nil {
+		return common.WrapError("idml", op, err)
+	}
+	for filename, sp := range spreads {
+		data, err := spread.MarshalSpread(sp)
+		if err != nil {
+			return common.WrapErrorWithPath("idml", op, filename, err)
+		}
+		renamed, changed := renameStyleRefs(data, renames)
+		if !changed {
+			continue
+		}
+		parsed, err := spread.ParseSpread(renamed)
+		if err != nil {
+			return common.WrapErrorWithPath("idml", op, filename, err)
+		}
+		*sp = *parsed
+		if err := p.marshalAndUpdateSpread(filename, sp); err != nil {
+			return err
+		}
+	}
+
+	masters, err := p.MasterSpreads()
+	if err != nil {
+		return common.WrapError("idml", op, err)
+	}
+	for filename, ms := range masters {
+		data, err := spread.MarshalMasterSpread(ms)
+		if err != nil {
+			return common.WrapErrorWithPath("idml", op, filename, err)
+		}
+		renamed, changed := renameStyleRefs(data, renames)
+		if !changed {
+			continue
+		}
+		parsed, err := spread.ParseMasterSpread(renamed)
+		if err != nil {
+			return common.WrapErrorWithPath("idml", op, filename, err)
+		}
+		*ms = *parsed
+		p.setFileData(filename, renamed)
+	}
+
+	if err := p.renameDesignmapStyleRefs(op, renames); err != nil {
+		return err
+	}
+	return p.renamePreferencesStyleRefs(op, renames)
+}
+
+// renamePreferencesStyleRefs replaces style references in Preferences.xml,
+// such as the paragraph style of TextDefault.
+func (p *Package) renamePreferencesStyleRefs(op string, renames map[string]string) error {
+	resource, cached := p.resources[PathPreferences]
+	if !cached {
+		entry, err := p.getFileEntry(PathPreferences)
+		if err != nil {
+			// Packages without Preferences.xml have nothing to rewrite
+			return nil
+		}
+		if renamed, changed := renameStyleRefs(entry.data, renames); changed {
+			p.setFileData(PathPreferences, renamed)
+		}
+		return nil
+	}
+
+	data, err := MarshalResourceFile(resource)
+	if err != nil {
+		return common.WrapErrorWithPath("idml", op, PathPreferences, err)
+	}
+	renamed, changed := renameStyleRefs(data, renames)
+	if !changed {
+		return nil
+	}
+	parsed, err := ParseResourceFile(renamed)
+	if err != nil {
+		return common.WrapErrorWithPath("idml", op, PathPreferences, err)
+	}
+	*resource = *parsed
+	return nil
+}
+
+// renameDesignmapStyleRefs replaces style references in designmap.xml, in
+// the parsed document if there is one and in the file data otherwise.
+func (p *Package) renameDesignmapStyleRefs(op string, renames map[string]string) error {
+	if p.documentMetadata == nil {
+		entry, err := p.getFileEntry(PathDesignmap)
+		if err != nil {
+			// Packages without designmap.xml have nothing to rewrite
+			return nil
+		}
+		if renamed, changed := renameStyleRefs(entry.data, renames); changed {
+			p.setFileData(PathDesignmap, renamed)
+		}
+		return nil
+	}
+
+	data, err := document.MarshalDocumentWithMetadata(p.documentMetadata)
+	if err != nil {
+		return common.WrapErrorWithPath("idml", op, PathDesignmap, err)
+	}
+	renamed, changed := renameStyleRefs(data, renames)
+	if !changed {
+		return nil
+	}
+	parsed, err := document.ParseDocumentWithMetadata(renamed)
+	if err != nil {
+		return common.WrapErrorWithPath("idml", op, PathDesignmap, err)
+	}
+	*p.document = *parsed.Document
+	parsed.Document = p.document
+	*p.documentMetadata = *parsed
+	return nil
+}
+
+// renameStyleRefs replaces attribute values and object-typed element
+// contents that equal a key of renames, reporting whether anything changed.
+// A BasedOn reference to a [No ... style] root is written as InDesign
+// writes it, as a string without the type prefix.
+func renameStyleRefs(data []byte, renames map[string]string) ([]byte, bool) {
+	escaped := make(map[string]string, len(renames))
+	for from, to := range renames {
+		escaped[escapeXML(from)] = escapeXML(to)
+	}
+
+	changed := false
+	data = attrValuePattern.ReplaceAllFunc(data, func(attr []byte) []byte {
+		m := attrValuePattern.FindSubmatch(attr)
+		if to, ok := escaped[string(m[2])]; ok {
+			changed = true
+			return []byte(string(m[1]) + to + `"`)
+		}
+		return attr
+	})
+	data = objectValuePattern.ReplaceAllFunc(data, func(elem []byte) []byte {
+		m := objectValuePattern.FindSubmatch(elem)
+		to, ok := escaped[string(m[2])]
+		if !ok {
+			return elem
+		}
+		changed = true
+		name := string(m[1])
+		typ, value := "object", to
+		if name == "BasedOn" {
+			typ, value = basedOnElement(stylePrefix(to), to)
+		}
+		return []byte("<" + name + ` type="` + typ + `">` + value + "</" + name + ">")
+	})
+	return data, changed
+}
+
+// escapeXML returns s escaped as encoding/xml writes text and attributes.
+func escapeXML(s string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// decodeNewStyle builds a style element with the given Self, Name, BasedOn
+// and formatting and decodes it into v, so attributes with a struct field
+// land in that field and others in OtherAttrs.
+func decodeNewStyle(elemName, prefix, self, name, basedOn string, attrs map[string]string, v interface{}) error {
+	start := xml.StartElement{
+		Name: xml.Name{Local: elemName},
+		Attr: []xml.Attr{{Name: xml.Name{Local: "Self"}, Value: self}, {Name: xml.Name{Local: "Name"}, Value: name}},
+	}
+	basedOnType, basedOnValue := basedOnElement(prefix, basedOn)
+	props := []xml.StartElement{{Name: xml.Name{Local: "BasedOn"}, Attr: []xml.Attr{{Name: xml.Name{Local: "type"}, Value: basedOnType}}}}
+	values := []string{basedOnValue}
+
+	keys := make([]string, 0, len(attrs))
+	for key := range attrs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value := attrs[key]
+		switch {
+		case key == "Self" || key == "Name":
+			return common.Errorf("idml", "create style", self, "attribute %s cannot be set", key)
+		case styleProperties[key] != "":
+			typ := styleProperties[key]
+			if key == "Leading" && value == "Auto" {
+				typ = "enumeration"
+			}
+			props = append(props, xml.StartElement{Name: xml.Name{Local: key}, Attr: []xml.Attr{{Name: xml.Name{Local: "type"}, Value: typ}}})
+			values = append(values, value)
+		default:
+			start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: key}, Value: value})
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := xml.NewEncoder(&buf)
+	tokens := []xml.Token{start, xml.StartElement{Name: xml.Name{Local: "Properties"}}}
+	for i, prop := range props {
+		tokens = append(tokens, prop, xml.CharData(values[i]), prop.End())
+	}
+	tokens = append(tokens, xml.EndElement{Name: xml.Name{Local: "Properties"}}, start.End())
+	for _, tok := range tokens {
+		if err := enc.EncodeToken(tok); err != nil {
+			return common.WrapError("idml", "create style", common.ErrInvalidFormat)
+		}
+	}
+	if err := enc.Flush(); err != nil {
+		return err
+	}
+	if err := xml.Unmarshal(buf.Bytes(), v); err != nil {
+		return common.WrapError("idml", "create style", common.ErrInvalidFormat)
+	}
+	return nil
+}
+
+// basedOnElement returns the type and value of a BasedOn element referring
+// to a style. InDesign refers to the [No ... style] roots by name.
+func basedOnElement(prefix, styleID string) (typ, value string) {
+	if strings.HasPrefix(styleID, prefix+"$ID/[No ") {
+		return "string", strings.TrimPrefix(styleID, prefix)
+	}
+	return "object", styleID
+}
+
+// newStyleID returns the Self of a style named name in a group, e.g.
+// "ParagraphStyle/Naviga%3aStandard%3aBody" for "Body" in the group named
+// "$ID/Naviga:Standard".
+func newStyleID(op, prefix, groupName, name string) (string, error) {
+	if name == "" {
+		return "", common.Errorf("idml", op, prefix, "style name is empty")
+	}
+	if path := strings.TrimPrefix(groupName, "$ID/"); path != "" {
+		name = path + ":" + name
+	}
+	return prefix + strings.ReplaceAll(name, ":", "%3a"), nil
+}
+
+// styleBaseName returns a style name without a group path, which some
+// documents include in the Name ("Naviga:Standard:body" -> "body").
+func styleBaseName(name string) string {
+	return name[strings.LastIndex(name, ":")+1:]
+}
+
+// stylePrefix returns the ID prefix of a paragraph, character or object
+// style ID, or "" for other IDs.
+func stylePrefix(styleID string) string {
+	for _, prefix := range []string{paragraphStylePrefix, characterStylePrefix, objectStylePrefix} {
+		if strings.HasPrefix(styleID, prefix) {
+			return prefix
+		}
+	}
+	return ""
+}
+
+// defaultReplacementStyle returns the style that replaces a deleted style
+// of the kind with the given prefix when no replacement is given.
+func defaultReplacementStyle(prefix string) string {
+	switch prefix {
+	case paragraphStylePrefix:
+		return paragraphStylePrefix + "$ID/[No paragraph style]"
+	case characterStylePrefix:
+		return characterStylePrefix + "$ID/[No character style]"
+	default:
+		return objectStylePrefix + "$ID/[None]"
+	}
+}
+
+// stylePropertiesBySelf returns pointers to the Properties of the styles of
+// the kind with the given prefix, by Self.
+func stylePropertiesBySelf(styles *resources.StylesFile, prefix string) map[string]**common.Properties {
+	props := make(map[string]**common.Properties)
+	switch prefix {
+	case paragraphStylePrefix:
+		var walk func(group *resources.ParagraphStyleGroup)
+		walk = func(group *resources.ParagraphStyleGroup) {
+			for i := range group.ParagraphStyles {
+				props[group.ParagraphStyles[i].Self] = &group.ParagraphStyles[i].Properties
+			}
+			for i := range group.NestedGroups {
+				walk(&group.NestedGroups[i])
+			}
+		}
+		if styles.RootParagraphStyleGroup != nil {
+			walk(styles.RootParagraphStyleGroup)
+		}
+	case characterStylePrefix:
+		var walk func(group *resources.CharacterStyleGroup)
+		walk = func(group *resources.CharacterStyleGroup) {
+			for i := range group.CharacterStyles {
+				props[group.CharacterStyles[i].Self] = &group.CharacterStyles[i].Properties
+			}
+			for i := range group.NestedGroups {
+				walk(&group.NestedGroups[i])
+			}
+		}
+		if styles.RootCharacterStyleGroup != nil {
+			walk(styles.RootCharacterStyleGroup)
+		}
+	case objectStylePrefix:
+		var walk func(group *resources.ObjectStyleGroup)
+		walk = func(group *resources.ObjectStyleGroup) {
+			for i := range group.ObjectStyles {
+				props[group.ObjectStyles[i].Self] = &group.ObjectStyles[i].Properties
+			}
+			for i := range group.NestedGroups {
+				walk(&group.NestedGroups[i])
+			}
+		}
+		if styles.RootObjectStyleGroup != nil {
+			walk(styles.RootObjectStyleGroup)
+		}
+	}
+	return props
+}
+
+// styleBasedOn returns the Self of the style a style is based on, or "" if
+// it has no BasedOn.
+func styleBasedOn(prefix string, props *common.Properties) string {
+	parent := props.GetBasedOn()
+	if parent != "" && !strings.HasPrefix(parent, prefix) {
+		parent = prefix + parent
+	}
+	return parent
+}
+
+// setStyleBasedOn makes a style based on the style parentID, replacing the
+// BasedOn element of its Properties.
+func setStyleBasedOn(props **common.Properties, prefix, parentID string) {
+	typ, value := basedOnElement(prefix, parentID)
+	elem := common.RawXMLElement{
+		XMLName: xml.Name{Local: "BasedOn"},
+		Attrs:   []xml.Attr{{Name: xml.Name{Local: "type"}, Value: typ}},
+		Content: []byte(escapeXML(value)),
+	}
+	if *props == nil {
+		*props = &common.Properties{}
+	}
+	for i := range (*props).OtherElements {
+		if (*props).OtherElements[i].XMLName.Local == "BasedOn" {
+			(*props).OtherElements[i] = elem
+			return
+		}
+	}
+	(*props).OtherElements = append([]common.RawXMLElement{elem}, (*props).OtherElements...)
+}
+
+// findParagraphStyleGroup returns the group with the given Self in root or
+// its nested groups, or root itself for "".
+func findParagraphStyleGroup(root *resources.ParagraphStyleGroup, groupID string) *resources.ParagraphStyleGroup {
+	if root == nil || groupID == "" || root.Self == groupID {
+		return root
+	}
+	for i := range root.NestedGroups {
+		if group := findParagraphStyleGroup(&root.NestedGroups[i], groupID); group != nil {
+			return group
+		}
+	}
+	return nil
+}
+
+// paragraphStyleParent returns the group holding a paragraph style and the
+// index of the style in it, or nil if there is none.
+func paragraphStyleParent(group *resources.ParagraphStyleGroup, styleID string) (*resources.ParagraphStyleGroup, int) {
+	if group == nil {
+		return nil, -1
+	}
+	for i := range group.ParagraphStyles {
+		if group.ParagraphStyles[i].Self == styleID {
+			return group, i
+		}
+	}
+	for i := range group.NestedGroups {
+		if parent, j := paragraphStyleParent(&group.NestedGroups[i], styleID); parent != nil {
+			return parent, j
+		}
+	}
+	return nil, -1
+}
+
+// findCharacterStyleGroup returns the group with the given Self in root or
+// its nested groups, or root itself for "".
+func findCharacterStyleGroup(root *resources.CharacterStyleGroup, groupID string) *resources.CharacterStyleGroup {
+	if root == nil || groupID == "" || root.Self == groupID {
+		return root
+	}
+	for i := range root.NestedGroups {
+		if group := findCharacterStyleGroup(&root.NestedGroups[i], groupID); group != nil {
+			return group
+		}
+	}
+	return nil
+}
+
+// characterStyleParent returns the group holding a character style and the
+// index of the style in it, or nil if there is none.
+func characterStyleParent(group *resources.CharacterStyleGroup, styleID string) (*resources.CharacterStyleGroup, int) {
+	if group == nil {
+		return nil, -1
+	}
+	for i := range group.CharacterStyles {
+		if group.CharacterStyles[i].Self == styleID {
+			return group, i
+		}
+	}
+	for i := range group.NestedGroups {
+		if parent, j := characterStyleParent(&group.NestedGroups[i], styleID); parent != nil {
+			return parent, j
+		}
+	}
+	return nil, -1
+}
+
+// findObjectStyleGroup returns the group with the given Self in root or its
+// nested groups, or root itself for "".
+func findObjectStyleGroup(root *resources.ObjectStyleGroup, groupID string) *resources.ObjectStyleGroup {
+	if root == nil || groupID == "" || root.Self == groupID {
+		return root
+	}
+	for i := range root.NestedGroups {
+		if group := findObjectStyleGroup(&root.NestedGroups[i], groupID); group != nil {
+			return group
+		}
+	}
+	return nil
+}
+
+// objectStyleParent returns the group holding an object style and the index
+// of the style in it, or nil if there is none.
+func objectStyleParent(group *resources.ObjectStyleGroup, styleID string) (*resources.ObjectStyleGroup, int) {
+	if group == nil {
+		return nil, -1
+	}
+	for i := range group.ObjectStyles {
+		if group.ObjectStyles[i].Self == styleID {
+			return group, i
+		}
+	}
+	for i := range group.NestedGroups {
+		if parent, j := objectStyleParent(&group.NestedGroups[i], styleID); parent != nil {
+			return parent, j
+		}
+	}
+	return nil, -1
+}
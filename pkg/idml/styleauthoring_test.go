@@ -0,0 +1,272 @@
+package idml
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+)
+
+// countStyleRefs writes pkg, reads it back and counts the occurrences of a
+// style ID in its XML files.
+func countStyleRefs(t *testing.T, pkg *Package, styleID string) (*Package, int) {
+	t.Helper()
+	reread, err := Read(writeTestIDML(t, pkg, "styles.idml"))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	ref := escapeXML(styleID)
+	count := 0
+	for filename, entry := range reread.files {
+		if strings.HasSuffix(filename, ".xml") {
+			count += strings.Count(string(entry.data), `"`+ref+`"`) + strings.Count(string(entry.data), ">"+ref+"<")
+		}
+	}
+	return reread, count
+}
+
+// TestCreateParagraphStyle tests creating styles in groups with inherited
+// and local formatting.
+func TestCreateParagraphStyle(t *testing.T) {
+	pkg := loadExampleIDML(t)
+
+	id, err := pkg.CreateParagraphStyle("ParagraphStyleGroup/$ID/Naviga%3aStandard", "Body Large", "ParagraphStyle/TEK tekst u innrykk", map[string]string{
+		"PointSize":   "12",
+		"Hyphenation": "false",
+		"AppliedFont": "Minion Pro",
+		"Leading":     "Auto",
+	})
+	if err != nil {
+		t.Fatalf("CreateParagraphStyle failed: %v", err)
+	}
+	if want := "ParagraphStyle/Naviga%3aStandard%3aBody Large"; id != want {
+		t.Errorf("CreateParagraphStyle() = %q, want %q", id, want)
+	}
+
+	reread, _ := countStyleRefs(t, pkg, id)
+	styles, err := reread.Styles()
+	if err != nil {
+		t.Fatalf("Styles failed: %v", err)
+	}
+	style := styles.FindParagraphStyle(id)
+	if style == nil {
+		t.Fatal("created style not found after roundtrip")
+	}
+	if style.Name != "Body Large" || style.PointSize != "12" {
+		t.Errorf("style = %+v", style)
+	}
+	resolved, err := styles.ResolveParagraphStyle(id)
+	if err != nil {
+		t.Fatalf("ResolveParagraphStyle failed: %v", err)
+	}
+	if resolved.PointSize() != 12 || resolved.AppliedFont() != "Minion Pro" || resolved.Leading() != "Auto" ||
+		resolved.Justification() != "LeftJustified" || resolved.Attr("Hyphenation") != "false" {
+		t.Errorf("resolved formatting = %v %v", resolved.Attributes, resolved.Properties)
+	}
+
+	root, err := pkg.CreateParagraphStyle("", "Caption", "", nil)
+	if err != nil {
+		t.Fatalf("CreateParagraphStyle(root) failed: %v", err)
+	}
+	if root != "ParagraphStyle/Caption" {
+		t.Errorf("CreateParagraphStyle(root) = %q", root)
+	}
+	if got := styles.FindParagraphStyle(id).Properties.GetBasedOn(); got != "ParagraphStyle/TEK tekst u innrykk" {
+		t.Errorf("BasedOn = %q", got)
+	}
+
+	if _, err := pkg.CreateParagraphStyle("", "Caption", "", nil); !errors.Is(err, common.ErrAlreadyExists) {
+		t.Errorf("duplicate style error = %v, want ErrAlreadyExists", err)
+	}
+	if _, err := pkg.CreateParagraphStyle("ParagraphStyleGroup/Missing", "X", "", nil); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("missing group error = %v, want ErrNotFound", err)
+	}
+	if _, err := pkg.CreateParagraphStyle("", "X", "ParagraphStyle/Missing", nil); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("missing parent error = %v, want ErrNotFound", err)
+	}
+
+	charID, err := pkg.CreateCharacterStyle("CharacterStyleGroup/$ID/Naviga", "Kicker", "", map[string]string{"FontStyle": "Bold", "Capitalization": "AllCaps"})
+	if err != nil {
+		t.Fatalf("CreateCharacterStyle failed: %v", err)
+	}
+	styles, _ = pkg.Styles()
+	char := styles.FindCharacterStyle(charID)
+	if charID != "CharacterStyle/Naviga%3aKicker" || char == nil || char.FontStyle != "Bold" {
+		t.Errorf("CreateCharacterStyle() = %q, %+v", charID, char)
+	}
+}
+
+// TestRenameStyle tests renaming a style used in stories, as BasedOn and as
+// NextStyle.
+func TestRenameStyle(t *testing.T) {
+	pkg := loadExampleIDML(t)
+	oldID := "ParagraphStyle/Naviga%3aStandard%3abody-TEK tekst m innrykk"
+	_, before := countStyleRefs(t, pkg, oldID)
+	if before == 0 {
+		t.Fatal("test style is not referenced")
+	}
+
+	newID, err := pkg.RenameStyle(oldID, "Body Indented")
+	if err != nil {
+		t.Fatalf("RenameStyle failed: %v", err)
+	}
+	if want := "ParagraphStyle/Naviga%3aStandard%3aBody Indented"; newID != want {
+		t.Errorf("RenameStyle() = %q, want %q", newID, want)
+	}
+
+	reread, remaining := countStyleRefs(t, pkg, oldID)
+	if remaining != 0 {
+		t.Errorf("%d references to %s remain", remaining, oldID)
+	}
+	if _, after := countStyleRefs(t, pkg, newID); after != before {
+		t.Errorf("references to %s = %d, want %d", newID, after, before)
+	}
+	styles, err := reread.Styles()
+	if err != nil {
+		t.Fatalf("Styles failed: %v", err)
+	}
+	style := styles.FindParagraphStyle(newID)
+	if style == nil || style.Name != "Body Indented" || style.NextStyle != newID {
+		t.Errorf("renamed style = %+v", style)
+	}
+
+	// Renaming a parent style rewrites BasedOn of its children
+	if _, err := pkg.RenameStyle("ParagraphStyle/TEK tekst u innrykk", "Body"); err != nil {
+		t.Fatalf("RenameStyle(parent) failed: %v", err)
+	}
+	styles, _ = pkg.Styles()
+	if got := styles.FindParagraphStyle("ParagraphStyle/TEK tekst m innrykk").Properties.GetBasedOn(); got != "ParagraphStyle/Body" {
+		t.Errorf("BasedOn of child = %q, want ParagraphStyle/Body", got)
+	}
+
+	if _, err := pkg.RenameStyle("ParagraphStyle/Body", "TEK tekst m innrykk"); !errors.Is(err, common.ErrAlreadyExists) {
+		t.Errorf("rename to existing error = %v, want ErrAlreadyExists", err)
+	}
+	if _, err := pkg.RenameStyle("ParagraphStyle/$ID/NormalParagraphStyle", "Basic"); err == nil {
+		t.Error("expected error renaming a built-in style")
+	}
+	if _, err := pkg.RenameStyle("ParagraphStyle/Missing", "X"); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("rename missing error = %v, want ErrNotFound", err)
+	}
+}
+
+// TestMoveStyleToGroup tests moving a style between groups.
+func TestMoveStyleToGroup(t *testing.T) {
+	pkg := loadExampleIDML(t)
+	oldID := "ParagraphStyle/Naviga%3aStandard%3abody-TEK tekst u innrykk"
+
+	newID, err := pkg.MoveStyleToGroup(oldID, "")
+	if err != nil {
+		t.Fatalf("MoveStyleToGroup failed: %v", err)
+	}
+	if newID != "ParagraphStyle/body-TEK tekst u innrykk" {
+		t.Errorf("MoveStyleToGroup() = %q", newID)
+	}
+	styles, _ := pkg.Styles()
+	if group, _ := paragraphStyleParent(styles.RootParagraphStyleGroup, newID); group != styles.RootParagraphStyleGroup {
+		t.Error("style is not in the root group")
+	}
+	if _, remaining := countStyleRefs(t, pkg, oldID); remaining != 0 {
+		t.Errorf("%d references to %s remain", remaining, oldID)
+	}
+
+	back, err := pkg.MoveStyleToGroup(newID, "ParagraphStyleGroup/$ID/Naviga%3aStandard")
+	if err != nil {
+		t.Fatalf("MoveStyleToGroup(back) failed: %v", err)
+	}
+	if back != oldID {
+		t.Errorf("MoveStyleToGroup(back) = %q, want %q", back, oldID)
+	}
+	if _, err := pkg.MoveStyleToGroup(oldID, "ParagraphStyleGroup/Missing"); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("move to missing group error = %v, want ErrNotFound", err)
+	}
+}
+
+// TestDeleteStyle tests deleting character and object styles with their
+// references replaced.
+func TestDeleteStyle(t *testing.T) {
+	pkg := loadExampleIDML(t)
+
+	charID := "CharacterStyle/Naviga%3anoneStyle"
+	if err := pkg.DeleteStyle(charID, ""); err != nil {
+		t.Fatalf("DeleteStyle(character) failed: %v", err)
+	}
+	reread, remaining := countStyleRefs(t, pkg, charID)
+	if remaining != 0 {
+		t.Errorf("%d references to %s remain", remaining, charID)
+	}
+	styles, _ := reread.Styles()
+	if styles.FindCharacterStyle(charID) != nil {
+		t.Error("deleted character style still exists")
+	}
+
+	objID := "ObjectStyle/Naviga%3aStandard%3aimage-Bilde"
+	replacement := "ObjectStyle/$ID/[Normal Graphics Frame]"
+	_, replacedBefore := countStyleRefs(t, pkg, replacement)
+	_, refs := countStyleRefs(t, pkg, objID)
+	if err := pkg.DeleteStyle(objID, replacement); err != nil {
+		t.Fatalf("DeleteStyle(object) failed: %v", err)
+	}
+	if _, remaining := countStyleRefs(t, pkg, objID); remaining != 0 {
+		t.Errorf("%d references to %s remain", remaining, objID)
+	}
+	if _, after := countStyleRefs(t, pkg, replacement); after != replacedBefore+refs-1 {
+		t.Errorf("references to replacement = %d, want %d", after, replacedBefore+refs-1)
+	}
+
+	// Deleting a parent makes children based on its own parent
+	if err := pkg.DeleteStyle("ParagraphStyle/TEK tekst u innrykk", ""); err != nil {
+		t.Fatalf("DeleteStyle(paragraph) failed: %v", err)
+	}
+	styles, _ = pkg.Styles()
+	if got := styles.FindParagraphStyle("ParagraphStyle/TEK tekst m innrykk").Properties.GetBasedOn(); got != "$ID/[No paragraph style]" {
+		t.Errorf("BasedOn of child = %q, want $ID/[No paragraph style]", got)
+	}
+
+	// Deleting the parent of the replacement does not base it on itself
+	a, err := pkg.CreateParagraphStyle("", "A", "ParagraphStyle/NOT tittel", nil)
+	if err != nil {
+		t.Fatalf("CreateParagraphStyle(A) failed: %v", err)
+	}
+	b, err := pkg.CreateParagraphStyle("", "B", a, nil)
+	if err != nil {
+		t.Fatalf("CreateParagraphStyle(B) failed: %v", err)
+	}
+	if err := pkg.DeleteStyle(a, b); err != nil {
+		t.Fatalf("DeleteStyle(A, B) failed: %v", err)
+	}
+	styles, _ = pkg.Styles()
+	if got := styles.FindParagraphStyle(b).Properties.GetBasedOn(); got != "ParagraphStyle/NOT tittel" {
+		t.Errorf("BasedOn of replacement = %q, want ParagraphStyle/NOT tittel", got)
+	}
+	if _, err := styles.ResolveParagraphStyle(b); err != nil {
+		t.Errorf("ResolveParagraphStyle(B) failed: %v", err)
+	}
+
+	// A rewrite that would base a style on itself is refused
+	c, err := pkg.CreateParagraphStyle("", "C", b, nil)
+	if err != nil {
+		t.Fatalf("CreateParagraphStyle(C) failed: %v", err)
+	}
+	setStyleBasedOn(&styles.FindParagraphStyle(b).Properties, paragraphStylePrefix, c)
+	if err := pkg.DeleteStyle(b, ""); err == nil {
+		t.Error("expected error deleting a style in a BasedOn cycle")
+	}
+	if styles.FindParagraphStyle(b) == nil {
+		t.Error("style was deleted despite the error")
+	}
+
+	if err := pkg.DeleteStyle("ParagraphStyle/$ID/NormalParagraphStyle", ""); err == nil {
+		t.Error("expected error deleting a built-in style")
+	}
+	if err := pkg.DeleteStyle("ParagraphStyle/NOT tittel", "CharacterStyle/$ID/[No character style]"); err == nil {
+		t.Error("expected error for a replacement of another kind")
+	}
+	if err := pkg.DeleteStyle("ParagraphStyle/NOT tittel", "ParagraphStyle/Missing"); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("missing replacement error = %v, want ErrNotFound", err)
+	}
+	if err := pkg.DeleteStyle("Color/Black", ""); err == nil {
+		t.Error("expected error deleting a non-style")
+	}
+}
@@ -0,0 +1,505 @@
+package idml
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/resources"
+)
+
+// StyleConflictPolicy decides what ImportStylesFrom does with a style whose
+// Self already exists in the package.
+type StyleConflictPolicy int
+
+const (
+	// StyleConflictSkip keeps the existing style.
+	StyleConflictSkip StyleConflictPolicy = iota
+
+	// StyleConflictOverwrite replaces the existing style with the imported one.
+	StyleConflictOverwrite
+
+	// StyleConflictRename adds the imported style under a new name, such as
+	// "Body copy". Built-in styles are never renamed; they are skipped.
+	StyleConflictRename
+)
+
+// StyleImportOptions configures ImportStylesFrom.
+type StyleImportOptions struct {
+	// OnConflict decides what happens to imported styles that already exist.
+	// With StyleConflictOverwrite, colors, gradients, swatches and stroke
+	// styles that already exist are replaced too; otherwise they are kept.
+	OnConflict StyleConflictPolicy
+}
+
+// StyleImportReport describes what ImportStylesFrom changed.
+type StyleImportReport struct {
+	// Added lists the IDs of styles that were added, including renamed ones
+	Added []string
+
+	// Overwritten lists the IDs of existing styles that were replaced
+	Overwritten []string
+
+	// Skipped lists the IDs of styles that were kept as they were
+	Skipped []string
+
+	// Renamed maps the IDs of conflicting source styles to the IDs they were
+	// added under
+	Renamed map[string]string
+
+	// Colors lists the IDs of colors and gradients that were added or replaced
+	Colors []string
+
+	// Swatches lists the IDs of swatches that were added or replaced
+	Swatches []string
+
+	// StrokeStyles lists the IDs of stroke styles that were added or replaced
+	StrokeStyles []string
+
+	// Fonts lists the names of font families that were added
+	Fonts []string
+}
+
+// Count returns the number of resources added or replaced.
+func (r *StyleImportReport) Count() int {
+	return len(r.Added) +
+		len(r.Overwritten) +
+		len(r.Colors) +
+		len(r.Swatches) +
+		len(r.StrokeStyles) +
+		len(r.Fonts)
+}
+
+// ImportStylesFrom copies the paragraph, character, object, table and cell
+// styles of src into the package, keeping their group hierarchy.
+//
+// The colors, gradients, swatches and stroke styles the copied styles refer
+// to are copied from src's Graphic.xml, and the font families of their
+// AppliedFont from src's Fonts.xml, if the package does not have them.
+//
+// Styles with a Self that already exists are handled by opts.OnConflict.
+// References between imported styles (BasedOn, NextStyle, ...) follow
+// renamed styles; an imported style based on a skipped style is based on the
+// package's version.
+//
+// Example:
+//
+//	template, err := idml.Read("house-template.idml")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	report, err := pkg.ImportStylesFrom(template, idml.StyleImportOptions{
+//	    OnConflict: idml.StyleConflictOverwrite,
+//	})
+//	fmt.Printf("%d added, %d updated\n", len(report.Added), len(report.Overwritten))
+func (p *Package) ImportStylesFrom(src *Package, opts StyleImportOptions) (*StyleImportReport, error) {
+	const op = "import styles"
+
+	if src == nil {
+		return nil, common.Errorf("idml", op, "", "source package is nil")
+	}
+	srcStyles, err := src.Styles()
+	if err != nil {
+		return nil, common.WrapErrorWithPath("idml", op, PathStyles, err)
+	}
+	styles, err := p.Styles()
+	if err != nil {
+		return nil, common.WrapErrorWithPath("idml", op, PathStyles, err)
+	}
+
+	// Work on a copy of the source styles, which renaming rewrites
+	data, err := resources.MarshalStylesFile(srcStyles)
+	if err != nil {
+		return nil, common.WrapErrorWithPath("idml", op, PathStyles, err)
+	}
+	imported, err := resources.ParseStylesFile(data)
+	if err != nil {
+		return nil, common.WrapErrorWithPath("idml", op, PathStyles, err)
+	}
+
+	report := &StyleImportReport{Renamed: make(map[string]string)}
+	if opts.OnConflict == StyleConflictRename {
+		renames, names := planStyleRenames(styles, imported)
+		if len(renames) > 0 {
+			renamed, _ := renameStyleRefs(data, renames)
+			if imported, err = resources.ParseStylesFile(renamed); err != nil {
+				return nil, common.WrapErrorWithPath("idml", op, PathStyles, err)
+			}
+			for id, name := range names {
+				setStyleName(imported, id, name)
+			}
+			report.Renamed = renames
+		}
+	}
+
+	mergeStyles(styles, imported, func(styleID string, exists bool) bool {
+		switch {
+		case !exists:
+			report.Added = append(report.Added, styleID)
+		case opts.OnConflict == StyleConflictOverwrite:
+			report.Overwritten = append(report.Overwritten, styleID)
+		default:
+			report.Skipped = append(report.Skipped, styleID)
+			return false
+		}
+		return true
+	})
+	p.SetStyles(styles)
+
+	// Collect what the copied styles refer to
+	var refs []string
+	families := make(map[string]bool)
+	for _, list := range [][]string{report.Added, report.Overwritten} {
+		for _, id := range list {
+			style, props := findAnyStyle(styles, id)
+			data, err := xml.Marshal(style)
+			if err != nil {
+				return nil, common.WrapErrorWithPath("idml", op, id, err)
+			}
+			refs = append(refs, graphicRefs(data)...)
+			if font := props.GetAppliedFont(); font != "" {
+				families[font] = true
+			}
+		}
+	}
+
+	if err := p.importGraphics(src, refs, opts.OnConflict == StyleConflictOverwrite, report); err != nil {
+		return nil, common.WrapErrorWithPath("idml", op, PathGraphic, err)
+	}
+	if err := p.importFontFamilies(src, families, report); err != nil {
+		return nil, common.WrapErrorWithPath("idml", op, PathFonts, err)
+	}
+	return report, nil
+}
+
+// planStyleRenames returns new IDs and names for the styles of imported
+// that conflict with styles, except built-in styles.
+func planStyleRenames(styles, imported *resources.StylesFile) (renames, names map[string]string) {
+	renames = make(map[string]string)
+	names = make(map[string]string)
+	taken := func(id string) bool {
+		existing, _ := findAnyStyle(styles, id)
+		other, _ := findAnyStyle(imported, id)
+		return existing != nil || other != nil
+	}
+
+	for _, s := range listStyles(imported) {
+		if strings.HasPrefix(s.self, s.prefix+"$ID/") {
+			continue
+		}
+		if existing, _ := findAnyStyle(styles, s.self); existing == nil {
+			continue
+		}
+		base := styleBaseName(s.name) + " copy"
+		name := base
+		for n := 2; ; n++ {
+			id, err := newStyleID("import styles", s.prefix, s.group, name)
+			if err == nil && !taken(id) {
+				renames[s.self] = id
+				names[id] = name
+				break
+			}
+			name = base + " " + strconv.Itoa(n)
+		}
+	}
+	return renames, names
+}
+
+// listedStyle is a style with the Name of the group holding it.
+type listedStyle struct {
+	prefix, self, name, group string
+}
+
+// listStyles returns the paragraph, character, object, cell and table
+// styles of a styles file.
+func listStyles(styles *resources.StylesFile) []listedStyle {
+	var list []listedStyle
+	var paragraphs func(g *resources.ParagraphStyleGroup)
+	paragraphs = func(g *resources.ParagraphStyleGroup) {
+		for _, s := range g.ParagraphStyles {
+			list = append(list, listedStyle{paragraphStylePrefix, s.Self, s.Name, g.Name})
+		}
+		for i := range g.NestedGroups {
+			paragraphs(&g.NestedGroups[i])
+		}
+	}
+	var characters func(g *resources.CharacterStyleGroup)
+	characters = func(g *resources.CharacterStyleGroup) {
+		for _, s := range g.CharacterStyles {
+			list = append(list, listedStyle{characterStylePrefix, s.Self, s.Name, g.Name})
+		}
+		for i := range g.NestedGroups {
+			characters(&g.NestedGroups[i])
+		}
+	}
+	var objects func(g *resources.ObjectStyleGroup)
+	objects = func(g *resources.ObjectStyleGroup) {
+		for _, s := range g.ObjectStyles {
+			list = append(list, listedStyle{objectStylePrefix, s.Self, s.Name, g.Name})
+		}
+		for i := range g.NestedGroups {
+			objects(&g.NestedGroups[i])
+		}
+	}
+	var cells func(g *resources.CellStyleGroup)
+	cells = func(g *resources.CellStyleGroup) {
+		for _, s := range g.CellStyles {
+			list = append(list, listedStyle{"CellStyle/", s.Self, s.Name, g.Name})
+		}
+		for i := range g.NestedGroups {
+			cells(&g.NestedGroups[i])
+		}
+	}
+	var tables func(g *resources.TableStyleGroup)
+	tables = func(g *resources.TableStyleGroup) {
+		for _, s := range g.TableStyles {
+			list = append(list, listedStyle{"TableStyle/", s.Self, s.Name, g.Name})
+		}
+		for i := range g.NestedGroups {
+			tables(&g.NestedGroups[i])
+		}
+	}
+
+	if styles.RootParagraphStyleGroup != nil {
+		paragraphs(styles.RootParagraphStyleGroup)
+	}
+	if styles.RootCharacterStyleGroup != nil {
+		characters(styles.RootCharacterStyleGroup)
+	}
+	if styles.RootObjectStyleGroup != nil {
+		objects(styles.RootObjectStyleGroup)
+	}
+	if styles.RootCellStyleGroup != nil {
+		cells(styles.RootCellStyleGroup)
+	}
+	if styles.RootTableStyleGroup != nil {
+		tables(styles.RootTableStyleGroup)
+	}
+	return list
+}
+
+// findAnyStyle returns the style of any kind with the given Self and its
+// Properties, or nil if there is none.
+func findAnyStyle(styles *resources.StylesFile, styleID string) (interface{}, *common.Properties) {
+	switch {
+	case strings.HasPrefix(styleID, paragraphStylePrefix):
+		if s := styles.FindParagraphStyle(styleID); s != nil {
+			return s, s.Properties
+		}
+	case strings.HasPrefix(styleID, characterStylePrefix):
+		if s := styles.FindCharacterStyle(styleID); s != nil {
+			return s, s.Properties
+		}
+	case strings.HasPrefix(styleID, objectStylePrefix):
+		if s := styles.FindObjectStyle(styleID); s != nil {
+			return s, s.Properties
+		}
+	case strings.HasPrefix(styleID, "CellStyle/"):
+		if s := styles.FindCellStyle(styleID); s != nil {
+			return s, s.Properties
+		}
+	case strings.HasPrefix(styleID, "TableStyle/"):
+		if s := styles.FindTableStyle(styleID); s != nil {
+			return s, s.Properties
+		}
+	}
+	return nil, nil
+}
+
+// setStyleName sets the Name of the style with the given Self.
+func setStyleName(styles *resources.StylesFile, styleID, name string) {
+	switch s, _ := findAnyStyle(styles, styleID); s := s.(type) {
+	case *resources.ParagraphStyle:
+		s.Name = name
+	case *resources.CharacterStyle:
+		s.Name = name
+	case *resources.ObjectStyle:
+		s.Name = name
+	case *resources.CellStyle:
+		s.Name = name
+	case *resources.TableStyle:
+		s.Name = name
+	}
+}
+
+// graphicRefPrefixes are the ID prefixes of the Graphic.xml resources that
+// styles refer to.
+var graphicRefPrefixes = []string{"Color/", "Gradient/", "Swatch/", "StrokeStyle/"}
+
+// graphicRefs returns the colors, gradients, swatches and stroke styles
+// referenced in the attribute values and object-typed elements of data.
+func graphicRefs(data []byte) []string {
+	var refs []string
+	add := func(value string) {
+		for _, prefix := range graphicRefPrefixes {
+			if strings.HasPrefix(value, prefix) {
+				refs = append(refs, value)
+				return
+			}
+		}
+	}
+	for _, m := range attrValuePattern.FindAllSubmatch(data, -1) {
+		add(string(m[2]))
+	}
+	for _, m := range objectValuePattern.FindAllSubmatch(data, -1) {
+		add(string(m[2]))
+	}
+	return refs
+}
+
+// importGraphics copies the colors, gradients, swatches and stroke styles in
+// refs from src, and those they refer to, such as the stop colors of
+// gradients. Existing ones are replaced only if overwrite is set.
+func (p *Package) importGraphics(src *Package, refs []string, overwrite bool, report *StyleImportReport) error {
+	if len(refs) == 0 {
+		return nil
+	}
+	srcGraphics, err := src.Graphics()
+	if errors.Is(err, common.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	graphics, err := p.Graphics()
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	changed := false
+	for len(refs) > 0 {
+		ref := refs[0]
+		refs = refs[1:]
+		if seen[ref] {
+			continue
+		}
+		seen[ref] = true
+
+		var item interface{}
+		var list *[]string
+		switch {
+		case strings.HasPrefix(ref, "Color/"):
+			item, list = copyGraphic(&graphics.Colors, srcGraphics.Colors, func(c *resources.Color) string { return c.Self }, ref, overwrite), &report.Colors
+		case strings.HasPrefix(ref, "Gradient/"):
+			item, list = copyGraphic(&graphics.Gradients, srcGraphics.Gradients, func(g *resources.Gradient) string { return g.Self }, ref, overwrite), &report.Colors
+		case strings.HasPrefix(ref, "Swatch/"):
+			item, list = copyGraphic(&graphics.Swatches, srcGraphics.Swatches, func(s *resources.Swatch) string { return s.Self }, ref, overwrite), &report.Swatches
+		default:
+			item, list = copyGraphic(&graphics.StrokeStyles, srcGraphics.StrokeStyles, func(s *resources.StrokeStyle) string { return s.Self }, ref, overwrite), &report.StrokeStyles
+		}
+		if item == nil {
+			continue
+		}
+		changed = true
+		*list = append(*list, ref)
+		data, err := xml.Marshal(item)
+		if err != nil {
+			return err
+		}
+		refs = append(refs, graphicRefs(data)...)
+	}
+
+	if changed {
+		p.SetGraphics(graphics)
+	}
+	return nil
+}
+
+// copyGraphic copies the item with the given Self from src to dst, replacing
+// an existing item only if overwrite is set. It returns the copied item, or
+// nil if nothing was copied.
+func copyGraphic[T any](dst *[]T, src []T, self func(*T) string, ref string, overwrite bool) interface{} {
+	from := -1
+	for i := range src {
+		if self(&src[i]) == ref {
+			from = i
+			break
+		}
+	}
+	if from < 0 {
+		return nil
+	}
+	for i := range *dst {
+		if self(&(*dst)[i]) == ref {
+			if !overwrite {
+				return nil
+			}
+			(*dst)[i] = src[from]
+			return &(*dst)[i]
+		}
+	}
+	*dst = append(*dst, src[from])
+	return &(*dst)[len(*dst)-1]
+}
+
+// importFontFamilies copies the named font families from src that the
+// package does not have. Families whose Self is taken get a new one.
+func (p *Package) importFontFamilies(src *Package, families map[string]bool, report *StyleImportReport) error {
+	if len(families) == 0 {
+		return nil
+	}
+	srcFonts, err := src.Fonts()
+	if errors.Is(err, common.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	fonts, err := p.Fonts()
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	used := make(map[string]bool)
+	have := make(map[string]bool)
+	next := uint64(0)
+	for _, family := range fonts.FontFamilies {
+		used[family.Self] = true
+		have[family.Name] = true
+		if n, err := strconv.ParseUint(strings.TrimPrefix(family.Self, "di"), 16, 64); err == nil && n >= next {
+			next = n + 1
+		}
+	}
+
+	changed := false
+	for _, name := range names {
+		if have[name] {
+			continue
+		}
+		for _, family := range srcFonts.FontFamilies {
+			if family.Name != name {
+				continue
+			}
+			family.Fonts = append([]resources.Font(nil), family.Fonts...)
+			if used[family.Self] {
+				self := fmt.Sprintf("di%x", next)
+				next++
+				for i := range family.Fonts {
+					family.Fonts[i].Self = self + strings.TrimPrefix(family.Fonts[i].Self, family.Self)
+				}
+				family.Self = self
+			}
+			used[family.Self] = true
+			fonts.FontFamilies = append(fonts.FontFamilies, family)
+			report.Fonts = append(report.Fonts, name)
+			changed = true
+			break
+		}
+	}
+
+	if changed {
+		p.SetFonts(fonts)
+	}
+	return nil
+}
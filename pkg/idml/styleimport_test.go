@@ -0,0 +1,160 @@
+package idml
+
+import (
+	"testing"
+)
+
+// containsString reports whether list contains s.
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// loadStyleImportTarget returns plain.idml with a paragraph style that
+// conflicts with one in example.idml.
+func loadStyleImportTarget(t *testing.T) *Package {
+	t.Helper()
+	pkg := loadPlainIDML(t)
+	if _, err := pkg.CreateParagraphStyle("", "TEK tekst u innrykk", "", map[string]string{"PointSize": "20"}); err != nil {
+		t.Fatalf("CreateParagraphStyle failed: %v", err)
+	}
+	return pkg
+}
+
+// TestImportStylesFrom tests importing styles with their groups, colors and
+// fonts, keeping existing styles.
+func TestImportStylesFrom(t *testing.T) {
+	src := loadExampleIDML(t)
+	pkg := loadStyleImportTarget(t)
+
+	report, err := pkg.ImportStylesFrom(src, StyleImportOptions{})
+	if err != nil {
+		t.Fatalf("ImportStylesFrom failed: %v", err)
+	}
+	if !containsString(report.Skipped, "ParagraphStyle/TEK tekst u innrykk") {
+		t.Error("conflicting style was not skipped")
+	}
+	if !containsString(report.Skipped, "ParagraphStyle/$ID/NormalParagraphStyle") {
+		t.Error("built-in style was not skipped")
+	}
+	for _, id := range []string{
+		"ParagraphStyle/TEK tekst m innrykk",
+		"ParagraphStyle/Naviga%3aStandard%3aheadline-TIT B",
+		"CharacterStyle/BIL fotokreditering",
+		"ObjectStyle/Naviga%3aStandard%3aimage-Bilde",
+	} {
+		if !containsString(report.Added, id) {
+			t.Errorf("%s was not added", id)
+		}
+	}
+	if !containsString(report.Colors, "Color/Seksjonsfarge") {
+		t.Errorf("Colors = %v, want Color/Seksjonsfarge", report.Colors)
+	}
+	if !containsString(report.Fonts, "Publico Text") {
+		t.Errorf("Fonts = %v, want Publico Text", report.Fonts)
+	}
+	if report.Count() == 0 || len(report.Renamed) != 0 {
+		t.Errorf("report = %+v", report)
+	}
+
+	reread, err := Read(writeTestIDML(t, pkg, "imported.idml"))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	styles, err := reread.Styles()
+	if err != nil {
+		t.Fatalf("Styles failed: %v", err)
+	}
+	if style := styles.FindParagraphStyle("ParagraphStyle/TEK tekst u innrykk"); style == nil || style.PointSize != "20" {
+		t.Errorf("existing style was changed: %+v", style)
+	}
+	group, _ := paragraphStyleParent(styles.RootParagraphStyleGroup, "ParagraphStyle/Naviga%3aStandard%3aheadline-TIT B")
+	if group == nil || group.Self != "ParagraphStyleGroup/$ID/Naviga%3aStandard" {
+		t.Errorf("imported style is not in its group: %+v", group)
+	}
+	graphics, err := reread.Graphics()
+	if err != nil {
+		t.Fatalf("Graphics failed: %v", err)
+	}
+	found := false
+	for _, c := range graphics.Colors {
+		found = found || c.Self == "Color/Seksjonsfarge"
+	}
+	if !found {
+		t.Error("imported color missing after roundtrip")
+	}
+	fonts, err := reread.Fonts()
+	if err != nil {
+		t.Fatalf("Fonts failed: %v", err)
+	}
+	selfs := make(map[string]bool)
+	for _, family := range fonts.FontFamilies {
+		if selfs[family.Self] {
+			t.Errorf("duplicate font family Self %s", family.Self)
+		}
+		selfs[family.Self] = true
+	}
+
+	// Importing again changes nothing
+	again, err := pkg.ImportStylesFrom(src, StyleImportOptions{})
+	if err != nil {
+		t.Fatalf("ImportStylesFrom(again) failed: %v", err)
+	}
+	if again.Count() != 0 {
+		t.Errorf("second import changed %d resources", again.Count())
+	}
+}
+
+// TestImportStylesFromOverwrite tests replacing existing styles.
+func TestImportStylesFromOverwrite(t *testing.T) {
+	pkg := loadStyleImportTarget(t)
+
+	report, err := pkg.ImportStylesFrom(loadExampleIDML(t), StyleImportOptions{OnConflict: StyleConflictOverwrite})
+	if err != nil {
+		t.Fatalf("ImportStylesFrom failed: %v", err)
+	}
+	if !containsString(report.Overwritten, "ParagraphStyle/TEK tekst u innrykk") || len(report.Skipped) != 0 {
+		t.Errorf("Overwritten = %v, Skipped = %v", report.Overwritten, report.Skipped)
+	}
+	styles, _ := pkg.Styles()
+	if style := styles.FindParagraphStyle("ParagraphStyle/TEK tekst u innrykk"); style == nil || style.PointSize != "9.3" {
+		t.Errorf("style was not overwritten: %+v", style)
+	}
+}
+
+// TestImportStylesFromRename tests adding conflicting styles under new names
+// with references between imported styles following them.
+func TestImportStylesFromRename(t *testing.T) {
+	pkg := loadStyleImportTarget(t)
+
+	report, err := pkg.ImportStylesFrom(loadExampleIDML(t), StyleImportOptions{OnConflict: StyleConflictRename})
+	if err != nil {
+		t.Fatalf("ImportStylesFrom failed: %v", err)
+	}
+	renamed := "ParagraphStyle/TEK tekst u innrykk copy"
+	if got := report.Renamed["ParagraphStyle/TEK tekst u innrykk"]; got != renamed {
+		t.Fatalf("Renamed = %v", report.Renamed)
+	}
+	if !containsString(report.Added, renamed) || !containsString(report.Skipped, "ParagraphStyle/$ID/NormalParagraphStyle") {
+		t.Errorf("Added = %v, Skipped = %v", report.Added, report.Skipped)
+	}
+
+	styles, _ := pkg.Styles()
+	if style := styles.FindParagraphStyle(renamed); style == nil || style.Name != "TEK tekst u innrykk copy" || style.PointSize != "9.3" {
+		t.Errorf("renamed style = %+v", style)
+	}
+	if style := styles.FindParagraphStyle("ParagraphStyle/TEK tekst u innrykk"); style == nil || style.PointSize != "20" {
+		t.Errorf("existing style was changed: %+v", style)
+	}
+	if got := styles.FindParagraphStyle("ParagraphStyle/TEK tekst m innrykk").Properties.GetBasedOn(); got != renamed {
+		t.Errorf("BasedOn of imported child = %q, want %q", got, renamed)
+	}
+
+	if _, err := pkg.ImportStylesFrom(nil, StyleImportOptions{}); err == nil {
+		t.Error("expected error for a nil source")
+	}
+}
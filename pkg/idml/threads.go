@@ -0,0 +1,395 @@
+package idml
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/spread"
+)
+
+// noTextFrame is the value InDesign writes for an empty PreviousTextFrame,
+// NextTextFrame or ParentStory reference.
+const noTextFrame = "n"
+
+// ThreadedTextFrame pairs a text frame with the spread file that contains it.
+type ThreadedTextFrame struct {
+	// SpreadFilename is the spread file holding the frame (e.g., "Spreads/Spread_u210.xml").
+	SpreadFilename string
+
+	// Frame points at the cached frame, so changes are visible to later reads.
+	// Frames inside groups are decoded from the group's XML; changes to them
+	// are written back when the spread is saved.
+	Frame *spread.SpreadTextFrame
+
+	// save writes a grouped frame back into its group, nil for other frames
+	save func() error
+}
+
+// TextFrameChain returns the frames of a story's thread in reading order.
+//
+// The chain starts at the frame without a PreviousTextFrame and follows
+// NextTextFrame across all spreads. storyID may be a story Self ID ("u1d8")
+// or a story path ("Stories/Story_u1d8.xml").
+//
+// Returns common.ErrNotFound if no frame displays the story, and an error
+// if the thread is broken (several starts, dangling references, cycles or
+// frames that cannot be reached from the start). Frames inside groups,
+// including nested groups, are part of the thread.
+//
+// Example:
+//
+//	chain, err := pkg.TextFrameChain("u222")
+//	for _, f := range chain {
+//	    fmt.Println(f.SpreadFilename, f.Frame.Self)
+//	}
+func (p *Package) TextFrameChain(storyID string) ([]ThreadedTextFrame, error) {
+	frames, err := p.threadedTextFrames()
+	if err != nil {
+		return nil, common.WrapError("idml", "text frame chain", err)
+	}
+
+	return resolveTextFrameChain(frames, normalizeStoryID(storyID))
+}
+
+// FramesForStory returns all frames whose ParentStory is the given story, in
+// document order (designmap spread order, then order within each spread).
+//
+// Unlike TextFrameChain it does not require a valid thread, which makes it
+// useful for diagnosing broken documents. Returns an empty slice if no frame
+// displays the story. Frames inside groups follow the frames placed directly
+// on their spread.
+func (p *Package) FramesForStory(storyID string) ([]ThreadedTextFrame, error) {
+	frames, err := p.threadedTextFrames()
+	if err != nil {
+		return nil, common.WrapError("idml", "frames for story", err)
+	}
+
+	storyID = normalizeStoryID(storyID)
+	result := []ThreadedTextFrame{}
+	for _, f := range frames {
+		if f.Frame.ParentStory == storyID {
+			result = append(result, f)
+		}
+	}
+	return result, nil
+}
+
+// LinkTextFrames threads nextID after prevID.
+//
+// This operation:
+//  1. Validates that both frames exist, prevID ends its thread and nextID starts one
+//  2. Rejects links that would close a cycle
+//  3. Moves nextID and any frames following it into prevID's story
+//  4. Updates the affected spread files
+//
+// The story previously shown by nextID is left in the package; remove it with
+// RemoveStory if it is no longer needed. Either frame may be inside a group.
+//
+// Example:
+//
+//	err := pkg.LinkTextFrames("u24a", "u260")
+func (p *Package) LinkTextFrames(prevID, nextID string) error {
+	const op = "link text frames"
+
+	frames, err := p.threadedTextFrames()
+	if err != nil {
+		return common.WrapError("idml", op, err)
+	}
+	byID := indexThreadedTextFrames(frames)
+
+	prev, ok := byID[prevID]
+	if !ok {
+		return common.WrapError("idml", op, fmt.Errorf("text frame %q: %w", prevID, common.ErrNotFound))
+	}
+	next, ok := byID[nextID]
+	if !ok {
+		return common.WrapError("idml", op, fmt.Errorf("text frame %q: %w", nextID, common.ErrNotFound))
+	}
+	if prevID == nextID {
+		return common.Errorf("idml", op, "", "cannot link text frame %q to itself", prevID)
+	}
+	if !isNoTextFrame(prev.Frame.NextTextFrame) {
+		return common.Errorf("idml", op, "", "text frame %q is already followed by %q", prevID, prev.Frame.NextTextFrame)
+	}
+	if !isNoTextFrame(next.Frame.PreviousTextFrame) {
+		return common.Errorf("idml", op, "", "text frame %q is already preceded by %q", nextID, next.Frame.PreviousTextFrame)
+	}
+
+	// Both threads must be intact before they are joined
+	storyID := prev.Frame.ParentStory
+	if isNoTextFrame(storyID) {
+		return common.Errorf("idml", op, "", "text frame %q has no parent story", prevID)
+	}
+	chain, err := resolveTextFrameChain(frames, storyID)
+	if err != nil {
+		return common.WrapError("idml", op, err)
+	}
+	tail := []ThreadedTextFrame{next}
+	if !isNoTextFrame(next.Frame.ParentStory) {
+		if tail, err = resolveTextFrameChain(frames, next.Frame.ParentStory); err != nil {
+			return common.WrapError("idml", op, err)
+		}
+	}
+	for _, f := range chain {
+		if f.Frame.Self == nextID {
+			return common.Errorf("idml", op, "", "linking %q to %q would create a cycle", prevID, nextID)
+		}
+	}
+
+	prev.Frame.NextTextFrame = nextID
+	next.Frame.PreviousTextFrame = prevID
+	for _, f := range tail {
+		f.Frame.ParentStory = storyID
+	}
+
+	return p.saveThreadedSpreads(op, append([]ThreadedTextFrame{prev}, tail...))
+}
+
+// UnlinkTextFrame removes a frame from its thread.
+//
+// The frames before and after it are linked to each other, so the remaining
+// thread stays intact. The detached frame shows storyID afterwards, which
+// must be an existing story that no other frame displays (see AddStory).
+// The frame may be inside a group.
+//
+// Example:
+//
+//	_ = pkg.AddStory(idml.StoryPath("u900"), emptyStory, idml.ValidationOptions{})
+//	err := pkg.UnlinkTextFrame("u24a", "u900")
+func (p *Package) UnlinkTextFrame(frameID, storyID string) error {
+	const op = "unlink text frame"
+
+	frames, err := p.threadedTextFrames()
+	if err != nil {
+		return common.WrapError("idml", op, err)
+	}
+	byID := indexThreadedTextFrames(frames)
+
+	target, ok := byID[frameID]
+	if !ok {
+		return common.WrapError("idml", op, fmt.Errorf("text frame %q: %w", frameID, common.ErrNotFound))
+	}
+
+	storyID = normalizeStoryID(storyID)
+	if !p.hasFile(StoryPath(storyID)) {
+		return common.WrapErrorWithPath("idml", op, StoryPath(storyID), common.ErrNotFound)
+	}
+	for _, f := range frames {
+		if f.Frame.ParentStory == storyID {
+			return common.Errorf("idml", op, StoryPath(storyID), "story is already displayed by text frame %q", f.Frame.Self)
+		}
+	}
+
+	// The thread must be intact so the neighbours can be reconnected safely
+	if _, err := resolveTextFrameChain(frames, target.Frame.ParentStory); err != nil {
+		return common.WrapError("idml", op, err)
+	}
+
+	changed := []ThreadedTextFrame{target}
+	prevID, nextID := target.Frame.PreviousTextFrame, target.Frame.NextTextFrame
+	if prev, ok := byID[prevID]; ok {
+		prev.Frame.NextTextFrame = orNoTextFrame(nextID)
+		changed = append(changed, prev)
+	}
+	if next, ok := byID[nextID]; ok {
+		next.Frame.PreviousTextFrame = orNoTextFrame(prevID)
+		changed = append(changed, next)
+	}
+
+	target.Frame.PreviousTextFrame = noTextFrame
+	target.Frame.NextTextFrame = noTextFrame
+	target.Frame.ParentStory = storyID
+
+	return p.saveThreadedSpreads(op, changed)
+}
+
+// ============================================================================
+// Helpers
+// ============================================================================
+
+// threadedTextFrames returns all text frames in document order: for each
+// spread, the frames placed directly on it, then the frames inside its
+// groups.
+func (p *Package) threadedTextFrames() ([]ThreadedTextFrame, error) {
+	filenames, err := p.orderedSpreadFilenames()
+	if err != nil {
+		return nil, err
+	}
+
+	var frames []ThreadedTextFrame
+	for _, filename := range filenames {
+		sp, err := p.Spread(filename)
+		if err != nil {
+			return nil, err
+		}
+		for i := range sp.InnerSpread.TextFrames {
+			frames = append(frames, ThreadedTextFrame{
+				SpreadFilename: filename,
+				Frame:          &sp.InnerSpread.TextFrames[i],
+			})
+		}
+
+		// Groups keep their page items as raw XML
+		err = visitGroupedItems(sp.InnerSpread.Groups, func(elem *common.RawXMLElement, save func() error) (bool, error) {
+			if elem.XMLName.Local != "TextFrame" {
+				return false, nil
+			}
+			frame := &spread.SpreadTextFrame{}
+			saveFrame, err := decodeGroupedItem(elem, save, frame)
+			if err != nil {
+				return false, err
+			}
+			frames = append(frames, ThreadedTextFrame{SpreadFilename: filename, Frame: frame, save: saveFrame})
+			return false, nil
+		})
+		if err != nil {
+			return nil, common.WrapErrorWithPath("idml", "read grouped text frames", filename, err)
+		}
+	}
+	return frames, nil
+}
+
+// orderedSpreadFilenames returns spread files in designmap order.
+// Spread files not referenced by the designmap follow in name order.
+func (p *Package) orderedSpreadFilenames() ([]string, error) {
+	var ordered []string
+	seen := make(map[string]bool)
+
+	if p.hasFile(PathDesignmap) {
+		doc, err := p.Document()
+		if err != nil {
+			return nil, err
+		}
+		for _, ref := range doc.Spreads {
+			if p.hasFile(ref.Src) && !seen[ref.Src] {
+				ordered = append(ordered, ref.Src)
+				seen[ref.Src] = true
+			}
+		}
+	}
+
+	var rest []string
+	for filename := range p.files {
+		if IsSpreadPath(filename) && !seen[filename] {
+			rest = append(rest, filename)
+		}
+	}
+	sort.Strings(rest)
+
+	return append(ordered, rest...), nil
+}
+
+// saveThreadedSpreads re-marshals every spread that holds one of the frames,
+// after writing grouped frames back into their groups.
+func (p *Package) saveThreadedSpreads(op string, frames []ThreadedTextFrame) error {
+	for _, f := range frames {
+		if f.save == nil {
+			continue
+		}
+		if err := f.save(); err != nil {
+			return common.WrapErrorWithPath("idml", op, f.SpreadFilename, err)
+		}
+	}
+
+	saved := make(map[string]bool)
+	for _, f := range frames {
+		if saved[f.SpreadFilename] {
+			continue
+		}
+		sp, err := p.loadSpreadForModification(f.SpreadFilename, op)
+		if err != nil {
+			return err
+		}
+		if err := p.marshalAndUpdateSpread(f.SpreadFilename, sp); err != nil {
+			return err
+		}
+		saved[f.SpreadFilename] = true
+	}
+	return nil
+}
+
+// resolveTextFrameChain orders the frames of storyID along their links and
+// verifies that they form a single, acyclic thread.
+func resolveTextFrameChain(frames []ThreadedTextFrame, storyID string) ([]ThreadedTextFrame, error) {
+	const op = "text frame chain"
+	path := StoryPath(storyID)
+
+	byID := indexThreadedTextFrames(frames)
+	var members, heads []ThreadedTextFrame
+	for _, f := range frames {
+		if f.Frame.ParentStory != storyID {
+			continue
+		}
+		members = append(members, f)
+		if isNoTextFrame(f.Frame.PreviousTextFrame) {
+			heads = append(heads, f)
+		}
+	}
+
+	if len(members) == 0 {
+		return nil, common.WrapErrorWithPath("idml", op, path, fmt.Errorf("no text frame displays story: %w", common.ErrNotFound))
+	}
+	if len(heads) != 1 {
+		return nil, common.Errorf("idml", op, path, "thread has %d starting frames, want 1", len(heads))
+	}
+
+	chain := []ThreadedTextFrame{heads[0]}
+	visited := map[string]bool{heads[0].Frame.Self: true}
+	for current := heads[0]; !isNoTextFrame(current.Frame.NextTextFrame); {
+		nextID := current.Frame.NextTextFrame
+		next, ok := byID[nextID]
+		switch {
+		case !ok:
+			return nil, common.Errorf("idml", op, path, "text frame %q links to missing frame %q", current.Frame.Self, nextID)
+		case visited[nextID]:
+			return nil, common.Errorf("idml", op, path, "thread contains a cycle at text frame %q", nextID)
+		case next.Frame.ParentStory != storyID:
+			return nil, common.Errorf("idml", op, path, "text frame %q belongs to story %q", nextID, next.Frame.ParentStory)
+		case next.Frame.PreviousTextFrame != current.Frame.Self:
+			return nil, common.Errorf("idml", op, path, "text frame %q links back to %q instead of %q",
+				nextID, next.Frame.PreviousTextFrame, current.Frame.Self)
+		}
+		chain = append(chain, next)
+		visited[nextID] = true
+		current = next
+	}
+
+	if len(chain) != len(members) {
+		return nil, common.Errorf("idml", op, path, "%d of %d text frames are not reachable from the thread start",
+			len(members)-len(chain), len(members))
+	}
+	return chain, nil
+}
+
+// indexThreadedTextFrames maps frame Self IDs to their frames.
+func indexThreadedTextFrames(frames []ThreadedTextFrame) map[string]ThreadedTextFrame {
+	byID := make(map[string]ThreadedTextFrame, len(frames))
+	for _, f := range frames {
+		byID[f.Frame.Self] = f
+	}
+	return byID
+}
+
+// normalizeStoryID accepts a story Self ID or a story path and returns the Self ID.
+// Example: "Stories/Story_u1d8.xml" returns "u1d8"
+func normalizeStoryID(storyID string) string {
+	if IsStoryPath(storyID) {
+		storyID = strings.TrimSuffix(strings.TrimPrefix(storyID, PrefixStories+"Story_"), ExtXML)
+	}
+	return storyID
+}
+
+// isNoTextFrame reports whether a frame or story reference is empty.
+func isNoTextFrame(id string) bool {
+	return id == "" || id == noTextFrame
+}
+
+// orNoTextFrame returns id, or the empty reference marker if id is empty.
+func orNoTextFrame(id string) string {
+	if isNoTextFrame(id) {
+		return noTextFrame
+	}
+	return id
+}
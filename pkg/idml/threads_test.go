@@ -0,0 +1,251 @@
+package idml
+
+import (
+	"encoding/xml"
+	"errors"
+	"testing"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/spread"
+)
+
+// chainIDs returns the frame Self IDs of a chain.
+func chainIDs(chain []ThreadedTextFrame) []string {
+	ids := make([]string, len(chain))
+	for i, f := range chain {
+		ids[i] = f.Frame.Self
+	}
+	return ids
+}
+
+// equalIDs compares two ID slices.
+func equalIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// TestTextFrameChain tests resolving the two-frame thread in example.idml.
+func TestTextFrameChain(t *testing.T) {
+	pkg := loadExampleIDML(t)
+
+	for _, id := range []string{"u222", StoryPath("u222")} {
+		chain, err := pkg.TextFrameChain(id)
+		if err != nil {
+			t.Fatalf("TextFrameChain(%q) failed: %v", id, err)
+		}
+		if got, want := chainIDs(chain), []string{"u234", "u24a"}; !equalIDs(got, want) {
+			t.Errorf("TextFrameChain(%q) = %v, want %v", id, got, want)
+		}
+		if chain[0].SpreadFilename != "Spreads/Spread_u210.xml" {
+			t.Errorf("SpreadFilename = %q", chain[0].SpreadFilename)
+		}
+	}
+
+	if _, err := pkg.TextFrameChain("missing"); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown story, got %v", err)
+	}
+}
+
+// TestTextFrameChain_Broken tests that dangling references and cycles are reported.
+func TestTextFrameChain_Broken(t *testing.T) {
+	pkg := loadExampleIDML(t)
+	chain, err := pkg.TextFrameChain("u222")
+	if err != nil {
+		t.Fatalf("TextFrameChain failed: %v", err)
+	}
+
+	// Dangling reference
+	chain[1].Frame.NextTextFrame = "u999"
+	if _, err := pkg.TextFrameChain("u222"); err == nil {
+		t.Error("expected error for dangling NextTextFrame")
+	}
+
+	// Cycle back to the first frame
+	chain[1].Frame.NextTextFrame = "u234"
+	if _, err := pkg.TextFrameChain("u222"); err == nil {
+		t.Error("expected error for cyclic thread")
+	}
+
+	// FramesForStory still lists the frames of a broken thread
+	frames, err := pkg.FramesForStory("u222")
+	if err != nil {
+		t.Fatalf("FramesForStory failed: %v", err)
+	}
+	if len(frames) != 2 {
+		t.Errorf("FramesForStory returned %d frames, want 2", len(frames))
+	}
+}
+
+// TestLinkTextFrames tests threading a frame onto an existing chain.
+func TestLinkTextFrames(t *testing.T) {
+	pkg := loadExampleIDML(t)
+
+	if err := pkg.LinkTextFrames("u24a", "u260"); err != nil {
+		t.Fatalf("LinkTextFrames failed: %v", err)
+	}
+
+	// The change must survive a write/read cycle
+	reloaded, err := Read(writeTestIDML(t, pkg, "linked.idml"))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	chain, err := reloaded.TextFrameChain("u222")
+	if err != nil {
+		t.Fatalf("TextFrameChain failed: %v", err)
+	}
+	if got, want := chainIDs(chain), []string{"u234", "u24a", "u260"}; !equalIDs(got, want) {
+		t.Errorf("chain = %v, want %v", got, want)
+	}
+
+	frames, err := reloaded.FramesForStory("u24e")
+	if err != nil {
+		t.Fatalf("FramesForStory failed: %v", err)
+	}
+	if len(frames) != 0 {
+		t.Errorf("story u24e still has %d frames", len(frames))
+	}
+}
+
+// TestLinkTextFrames_Invalid tests that invalid links are rejected without changes.
+func TestLinkTextFrames_Invalid(t *testing.T) {
+	pkg := loadExampleIDML(t)
+
+	tests := []struct {
+		name         string
+		prev, next   string
+		wantNotFound bool
+	}{
+		{name: "cycle", prev: "u24a", next: "u234"},
+		{name: "self", prev: "u260", next: "u260"},
+		{name: "prev already linked", prev: "u234", next: "u260"},
+		{name: "next already linked", prev: "u260", next: "u24a"},
+		{name: "missing frame", prev: "u260", next: "u999", wantNotFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := pkg.LinkTextFrames(tt.prev, tt.next)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if tt.wantNotFound && !errors.Is(err, common.ErrNotFound) {
+				t.Errorf("expected ErrNotFound, got %v", err)
+			}
+		})
+	}
+
+	chain, err := pkg.TextFrameChain("u222")
+	if err != nil {
+		t.Fatalf("TextFrameChain failed: %v", err)
+	}
+	if got, want := chainIDs(chain), []string{"u234", "u24a"}; !equalIDs(got, want) {
+		t.Errorf("chain changed to %v", got)
+	}
+}
+
+// TestUnlinkTextFrame tests detaching a frame and reconnecting its neighbours.
+func TestUnlinkTextFrame(t *testing.T) {
+	pkg := loadExampleIDML(t)
+
+	if err := pkg.LinkTextFrames("u24a", "u260"); err != nil {
+		t.Fatalf("LinkTextFrames failed: %v", err)
+	}
+
+	// The detached frame needs a story of its own that no frame displays
+	if err := pkg.UnlinkTextFrame("u24a", "u222"); err == nil {
+		t.Error("expected error when reusing a displayed story")
+	}
+	if err := pkg.UnlinkTextFrame("u24a", "u999"); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for missing story, got %v", err)
+	}
+
+	// u24e lost its only frame when u260 was linked
+	if err := pkg.UnlinkTextFrame("u24a", "u24e"); err != nil {
+		t.Fatalf("UnlinkTextFrame failed: %v", err)
+	}
+
+	chain, err := pkg.TextFrameChain("u222")
+	if err != nil {
+		t.Fatalf("TextFrameChain failed: %v", err)
+	}
+	if got, want := chainIDs(chain), []string{"u234", "u260"}; !equalIDs(got, want) {
+		t.Errorf("chain = %v, want %v", got, want)
+	}
+
+	detached, err := pkg.TextFrameChain("u24e")
+	if err != nil {
+		t.Fatalf("TextFrameChain failed: %v", err)
+	}
+	if got := chainIDs(detached); !equalIDs(got, []string{"u24a"}) {
+		t.Errorf("detached chain = %v", got)
+	}
+}
+
+// TestTextFrameChain_GroupedFrame tests that frames inside nested groups
+// are part of threads and can be linked and unlinked.
+func TestTextFrameChain_GroupedFrame(t *testing.T) {
+	pkg := loadExampleIDML(t)
+	sp, err := pkg.Spread("Spreads/Spread_u210.xml")
+	if err != nil {
+		t.Fatalf("Spread failed: %v", err)
+	}
+
+	var nested common.RawXMLElement
+	if err := xml.Unmarshal([]byte(`<Group Self="ug2"><TextFrame Self="ug1" ParentStory="u222" PreviousTextFrame="u24a" NextTextFrame="n" /></Group>`), &nested); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	group := spread.Group{OtherElements: []common.RawXMLElement{nested}}
+	group.Self = "ug0"
+	sp.InnerSpread.Groups = append(sp.InnerSpread.Groups, group)
+
+	findSpreadTextFrame(t, pkg, "u24a").NextTextFrame = "ug1"
+
+	chain, err := pkg.TextFrameChain("u222")
+	if err != nil {
+		t.Fatalf("TextFrameChain through a group failed: %v", err)
+	}
+	if got, want := chainIDs(chain), []string{"u234", "u24a", "ug1"}; !equalIDs(got, want) {
+		t.Errorf("chain = %v, want %v", got, want)
+	}
+	frames, err := pkg.FramesForStory("u222")
+	if err != nil {
+		t.Fatalf("FramesForStory failed: %v", err)
+	}
+	if got, want := chainIDs(frames), []string{"u234", "u24a", "ug1"}; !equalIDs(got, want) {
+		t.Errorf("FramesForStory = %v, want %v", got, want)
+	}
+
+	// u24e loses its only frame when u260 is linked after the grouped frame
+	if err := pkg.LinkTextFrames("ug1", "u260"); err != nil {
+		t.Fatalf("LinkTextFrames(grouped) failed: %v", err)
+	}
+	if err := pkg.UnlinkTextFrame("ug1", "u24e"); err != nil {
+		t.Fatalf("UnlinkTextFrame(grouped) failed: %v", err)
+	}
+
+	reread, err := Read(writeTestIDML(t, pkg, "grouped_thread.idml"))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	chain, err = reread.TextFrameChain("u222")
+	if err != nil {
+		t.Fatalf("TextFrameChain after roundtrip failed: %v", err)
+	}
+	if got, want := chainIDs(chain), []string{"u234", "u24a", "u260"}; !equalIDs(got, want) {
+		t.Errorf("chain after unlink = %v, want %v", got, want)
+	}
+	detached, err := reread.TextFrameChain("u24e")
+	if err != nil {
+		t.Fatalf("TextFrameChain(u24e) failed: %v", err)
+	}
+	if got := chainIDs(detached); !equalIDs(got, []string{"ug1"}) {
+		t.Errorf("detached chain = %v, want [ug1]", got)
+	}
+}
@@ -2,6 +2,8 @@ package idml
 
 import (
 	"archive/zip"
+	"bytes"
+	"io"
 	"os"
 	"regexp"
 	"strings"
@@ -49,6 +51,15 @@ func (p *Package) marshalCachedObjects() error {
 		p.setFileData(filename, xmlData)
 	}
 
+	// If master spreads were parsed, marshal them back to XML
+	for filename, ms := range p.masterSpreads {
+		xmlData, err := spread.MarshalMasterSpread(ms)
+		if err != nil {
+			return common.WrapErrorWithPath("idml", "marshal master spread", filename, err)
+		}
+		p.setFileData(filename, xmlData)
+	}
+
 	// If resources were parsed, marshal them back to XML
 	for filename, resource := range p.resources {
 		xmlData, err := MarshalResourceFile(resource)
@@ -94,6 +105,25 @@ func (p *Package) marshalCachedObjects() error {
 		p.setFileData(filename, data)
 	}
 
+	// If typed XML tags were parsed, marshal them back (after the generic
+	// metadata files so the typed version wins)
+	if p.tags != nil {
+		xmlData, err := resources.MarshalTagsFile(p.tags)
+		if err != nil {
+			return common.WrapErrorWithPath("idml", "marshal tags", PathTags, err)
+		}
+		p.setFileData(PathTags, xmlData)
+	}
+
+	// If the backing story was parsed, marshal it back
+	if p.backingStory != nil {
+		xmlData, err := story.MarshalBackingStory(p.backingStory)
+		if err != nil {
+			return common.WrapErrorWithPath("idml", "marshal backing story", PathBackingStory, err)
+		}
+		p.setFileData(PathBackingStory, xmlData)
+	}
+
 	return nil
 }
 
@@ -190,8 +220,11 @@ func writeZipFiles(w *zip.Writer, pkg *Package) error {
 			}
 		}
 
-		// Use the original FileHeader to preserve compression and metadata
-		fileWriter, err := w.CreateHeader(entry.header)
+		// Use a copy of the original FileHeader to preserve compression and metadata.
+		// zip.Writer mutates the header it is given, so copying keeps repeated
+		// writes of the same package identical.
+		header := *entry.header
+		fileWriter, err := w.CreateHeader(&header)
 		if err != nil {
 			return common.WrapErrorWithPath("idml", "write", name, err)
 		}
@@ -215,31 +248,92 @@ func writeZipFiles(w *zip.Writer, pkg *Package) error {
 // This is required by the IDML specification. InDesign will reject files
 // that don't follow this requirement.
 func Write(pkg *Package, path string) error {
-	// Step 1: Marshal all cached objects back to XML
-	if err := pkg.marshalCachedObjects(); err != nil {
-		return err
-	}
-
-	// Step 2: Create the output file
 	// #nosec G304 - This is a library function; file path is intentionally provided by caller
 	f, err := os.Create(path)
 	if err != nil {
 		return common.WrapErrorWithPath("idml", "write", path, err)
 	}
-	defer f.Close()
-
-	// Step 3: Create ZIP writer and write all files
-	w := zip.NewWriter(f)
-	defer w.Close()
 
-	if err := writeZipFiles(w, pkg); err != nil {
+	if _, err := pkg.WriteTo(f); err != nil {
+		_ = f.Close()
 		return err
 	}
 
-	// Step 4: Close the ZIP writer (important!)
-	if err := w.Close(); err != nil {
+	if err := f.Close(); err != nil {
 		return common.WrapErrorWithPath("idml", "write", path, err)
 	}
 
 	return nil
 }
+
+// WriteTo writes the IDML package as a ZIP archive to w.
+// It implements io.WriterTo and returns the number of bytes written.
+//
+// The output is identical to Write: cached objects are marshaled first,
+// mimetype is written first and uncompressed, and all other files follow
+// in their original order.
+//
+// Example:
+//
+//	func handler(w http.ResponseWriter, r *http.Request) {
+//	    w.Header().Set("Content-Type", "application/vnd.adobe.indesign-idml-package")
+//	    if _, err := pkg.WriteTo(w); err != nil {
+//	        log.Println(err)
+//	    }
+//	}
+func (p *Package) WriteTo(w io.Writer) (int64, error) {
+	if w == nil {
+		return 0, common.Errorf("idml", "write to writer", "<stream>", "writer is nil")
+	}
+
+	// Step 1: Marshal all cached objects back to XML
+	if err := p.marshalCachedObjects(); err != nil {
+		return 0, err
+	}
+
+	// Step 2: Create ZIP writer and write all files
+	cw := &countingWriter{w: w}
+	zw := zip.NewWriter(cw)
+
+	if err := writeZipFiles(zw, p); err != nil {
+		return cw.n, err
+	}
+
+	// Step 3: Close the ZIP writer to flush the central directory (important!)
+	if err := zw.Close(); err != nil {
+		return cw.n, common.WrapErrorWithPath("idml", "write to writer", "<stream>", err)
+	}
+
+	return cw.n, nil
+}
+
+// MarshalBytes returns the IDML package as an in-memory ZIP archive.
+// This is the counterpart of ReadBytes and produces the same bytes as Write.
+//
+// Example:
+//
+//	data, err := pkg.MarshalBytes()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	_, err = s3Client.PutObject(ctx, &s3.PutObjectInput{Body: bytes.NewReader(data)})
+func (p *Package) MarshalBytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := p.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// countingWriter wraps an io.Writer and counts the bytes written through it.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+// Write implements io.Writer.
+func (c *countingWriter) Write(b []byte) (int, error) {
+	n, err := c.w.Write(b)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,103 @@
+package idml
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+)
+
+// TestWriteTo_MatchesWrite verifies that WriteTo and MarshalBytes produce the same archive as Write.
+func TestWriteTo_MatchesWrite(t *testing.T) {
+	pkg := loadPlainIDML(t)
+
+	outputPath := writeTestIDML(t, pkg, "write_to.idml")
+	fileData, err := os.ReadFile(outputPath)
+	if err != nil {
+		t.Fatalf("failed to read written file: %v", err)
+	}
+
+	var buf bytes.Buffer
+	n, err := pkg.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo failed: %v", err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("WriteTo returned %d bytes, buffer has %d", n, buf.Len())
+	}
+
+	memData, err := pkg.MarshalBytes()
+	if err != nil {
+		t.Fatalf("MarshalBytes failed: %v", err)
+	}
+
+	if !bytes.Equal(fileData, buf.Bytes()) {
+		t.Error("WriteTo output differs from Write output")
+	}
+	if !bytes.Equal(fileData, memData) {
+		t.Error("MarshalBytes output differs from Write output")
+	}
+}
+
+// TestMarshalBytes_ZipOrdering verifies mimetype is first and stored, and file order is preserved.
+func TestMarshalBytes_ZipOrdering(t *testing.T) {
+	pkg := loadExampleIDML(t)
+
+	data, err := pkg.MarshalBytes()
+	if err != nil {
+		t.Fatalf("MarshalBytes failed: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("output is not a valid ZIP: %v", err)
+	}
+
+	if len(zr.File) == 0 || zr.File[0].Name != PathMimetype {
+		t.Fatalf("first entry must be %s", PathMimetype)
+	}
+	if zr.File[0].Method != zip.Store {
+		t.Errorf("mimetype must be stored uncompressed, got method %d", zr.File[0].Method)
+	}
+
+	var expected []string
+	for _, name := range pkg.fileOrder {
+		if name != PathMimetype {
+			expected = append(expected, name)
+		}
+	}
+	for i, name := range expected {
+		if got := zr.File[i+1].Name; got != name {
+			t.Errorf("entry %d = %s, want %s", i+1, got, name)
+		}
+	}
+
+	// The in-memory archive must be readable again
+	reread, err := ReadBytes(data)
+	if err != nil {
+		t.Fatalf("ReadBytes of marshaled data failed: %v", err)
+	}
+	if reread.FileCount() != pkg.FileCount() {
+		t.Errorf("file count = %d, want %d", reread.FileCount(), pkg.FileCount())
+	}
+}
+
+// failingWriter returns an error on every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+// TestWriteTo_Errors verifies error handling for nil and failing writers.
+func TestWriteTo_Errors(t *testing.T) {
+	pkg := loadPlainIDML(t)
+
+	if _, err := pkg.WriteTo(nil); err == nil {
+		t.Error("WriteTo(nil) should return error")
+	}
+	if _, err := pkg.WriteTo(failingWriter{}); err == nil {
+		t.Error("WriteTo with failing writer should return error")
+	}
+}
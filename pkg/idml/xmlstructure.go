@@ -0,0 +1,413 @@
+package idml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/story"
+)
+
+// XMLNode is an element of the document's XML structure (InDesign's
+// structure view) together with the content it is placed on.
+//
+// Elements tagging text in a story carry the story filename and the range of
+// the tagged text. An element placed on a story (XMLContent is the story's
+// Self) covers the whole story, and its children are the elements tagging
+// text inside that story. Elements placed on page items such as graphic
+// frames, and the root element, have no story.
+type XMLNode struct {
+	Self string
+	Tag  string // Tag name (e.g., "Title")
+
+	// Element is the underlying XMLElement; changes to it are saved on Write
+	Element *story.XMLElement
+
+	// Tagged text (only when StoryFile is set)
+	StoryFile string          // Story holding the text (e.g., "Stories/Story_u1d8.xml")
+	Range     story.TextRange // Position of the text in the story's flattened text
+	Text      string
+
+	// Child elements in document order
+	Children []*XMLNode
+}
+
+// XMLImportResult reports what ImportXML changed.
+type XMLImportResult struct {
+	// Updated lists the Self IDs of elements whose text or attributes changed
+	Updated []string
+
+	// Unmatched lists the paths (e.g., "/Root/Story[2]/Note") of elements in
+	// the imported XML that have no counterpart in the document's structure
+	Unmatched []string
+}
+
+// XMLStructure returns the root of the document's XML structure, built from
+// XML/BackingStory.xml and the XMLElements in the stories.
+//
+// Returns ErrNotFound if the package has no backing story or the backing
+// story has no root element.
+//
+// Example:
+//
+//	root, err := pkg.XMLStructure()
+//	for _, child := range root.Children {
+//	    fmt.Println(child.Tag, child.StoryFile, child.Text)
+//	}
+func (p *Package) XMLStructure() (*XMLNode, error) {
+	bs, err := p.BackingStory()
+	if err != nil {
+		return nil, common.WrapError("idml", "XML structure", err)
+	}
+
+	tagged := groupByParent(bs.XMLElements())
+	if len(tagged[nil]) == 0 {
+		return nil, common.WrapErrorWithPath("idml", "XML structure", PathBackingStory, common.ErrNotFound)
+	}
+
+	b := xmlTreeBuilder{p: p, visited: make(map[string]bool)}
+	return b.build(tagged[nil][0], tagged, "")
+}
+
+// xmlTreeBuilder builds XMLNodes, following elements placed on stories.
+type xmlTreeBuilder struct {
+	p       *Package
+	visited map[string]bool // story files already expanded (guards against cycles)
+}
+
+// build returns the node for t and its descendants. storyFile is the story
+// holding t, or "" for the backing story.
+func (b *xmlTreeBuilder) build(t story.TaggedRange, tagged map[*story.XMLElement][]story.TaggedRange, storyFile string) (*XMLNode, error) {
+	node := &XMLNode{Self: t.Element.Self, Tag: t.Element.TagName(), Element: t.Element}
+	if storyFile != "" {
+		node.StoryFile, node.Range, node.Text = storyFile, t.Range, t.Text
+	}
+
+	for _, child := range tagged[t.Element] {
+		childNode, err := b.build(child, tagged, storyFile)
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, childNode)
+	}
+
+	if t.Element.XMLContent == "" {
+		return node, nil
+	}
+	filename := StoryPath(t.Element.XMLContent)
+	if b.visited[filename] {
+		return node, nil
+	}
+	st, err := b.p.Story(filename)
+	if errors.Is(err, common.ErrNotFound) {
+		// Placed on a page item rather than a story
+		return node, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	b.visited[filename] = true
+
+	text := st.ExtractText()
+	node.StoryFile, node.Range, node.Text = filename, story.TextRange{Start: 0, End: len(text)}, text
+	inner := groupByParent(st.XMLElements())
+	for _, child := range inner[nil] {
+		childNode, err := b.build(child, inner, filename)
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, childNode)
+	}
+	return node, nil
+}
+
+// groupByParent groups tagged ranges by their enclosing element; top-level
+// ranges are under the nil key. Document order is kept.
+func groupByParent(tagged []story.TaggedRange) map[*story.XMLElement][]story.TaggedRange {
+	groups := make(map[*story.XMLElement][]story.TaggedRange)
+	for _, t := range tagged {
+		groups[t.Parent] = append(groups[t.Parent], t)
+	}
+	return groups
+}
+
+// ExportXML writes the tagged content of the document as a plain XML
+// document: one element per XMLElement, named after its tag, with its XML
+// attributes and the text it tags. Text in a story that is not inside a child
+// element is kept as mixed content; line breaks become newlines.
+func (p *Package) ExportXML(w io.Writer) error {
+	root, err := p.XMLStructure()
+	if err != nil {
+		return common.WrapError("idml", "export XML", err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	writeXMLNode(&buf, root)
+	buf.WriteByte('\n')
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return common.WrapError("idml", "export XML", err)
+	}
+	return nil
+}
+
+// xmlTextEscaper escapes character data but keeps newlines readable.
+var xmlTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+
+// writeXMLNode writes node and its children as plain XML.
+func writeXMLNode(buf *bytes.Buffer, node *XMLNode) {
+	buf.WriteString("<" + node.Tag)
+	for _, attr := range node.Element.Attributes {
+		buf.WriteString(" " + attr.Name + `="`)
+		xml.EscapeText(buf, []byte(attr.Value)) // nolint:errcheck
+		buf.WriteString(`"`)
+	}
+	buf.WriteString(">")
+
+	pos := node.Range.Start
+	for _, child := range node.Children {
+		if node.StoryFile != "" && child.StoryFile == node.StoryFile {
+			buf.WriteString(xmlTextEscaper.Replace(node.Text[pos-node.Range.Start : child.Range.Start-node.Range.Start]))
+			pos = child.Range.End
+		}
+		writeXMLNode(buf, child)
+	}
+	if node.StoryFile != "" {
+		buf.WriteString(xmlTextEscaper.Replace(node.Text[pos-node.Range.Start:]))
+	}
+
+	buf.WriteString("</" + node.Tag + ">")
+}
+
+// ImportXML applies a plain XML document, such as one written by ExportXML,
+// to the document's XML structure.
+//
+// Elements are matched by tag name and position among their siblings: the
+// n-th <Title> child of an element goes to the n-th Title child in the
+// structure. The text of a matched element without child elements in the
+// structure replaces the text it tags; for an element placed on a story this
+// replaces the story text shown in the story's frames. XML attributes are set
+// on the matched elements. The structure itself is not changed: elements
+// without a counterpart are reported in XMLImportResult.Unmatched.
+//
+// The root element must have the same tag as the structure's root.
+func (p *Package) ImportXML(r io.Reader) (*XMLImportResult, error) {
+	const op = "import XML"
+	in, err := parsePlainXML(r)
+	if err != nil {
+		return nil, common.WrapError("idml", op, err)
+	}
+
+	root, err := p.XMLStructure()
+	if err != nil {
+		return nil, common.WrapError("idml", op, err)
+	}
+	if in.name != root.Tag {
+		return nil, common.Errorf("idml", op, "", "root element <%s> does not match structure root <%s>", in.name, root.Tag)
+	}
+
+	result := &XMLImportResult{}
+	if err := p.importXMLNode(root, in, "/"+in.name, result); err != nil {
+		return nil, common.WrapError("idml", op, err)
+	}
+	return result, nil
+}
+
+// importXMLNode applies el to node and matches their children.
+func (p *Package) importXMLNode(node *XMLNode, el *plainElement, path string, result *XMLImportResult) error {
+	updated := false
+	for _, attr := range el.attrs {
+		if value, ok := node.Element.Attribute(attr.Name.Local); !ok || value != attr.Value {
+			node.Element.SetAttribute(attr.Name.Local, attr.Value)
+			updated = true
+		}
+	}
+
+	if len(node.Children) == 0 && node.StoryFile != "" {
+		if text := el.text(); text != node.Text {
+			st, err := p.Story(node.StoryFile)
+			if err != nil {
+				return err
+			}
+			if node.Element.XMLContent != "" && StoryPath(node.Element.XMLContent) == node.StoryFile {
+				st.SetText(text)
+			} else if err := st.SetXMLElementText(node.Self, text); err != nil {
+				return err
+			}
+			updated = true
+		}
+	}
+	if updated {
+		result.Updated = append(result.Updated, node.Self)
+	}
+
+	// Match children by tag and position
+	used := make(map[*XMLNode]bool)
+	seen := make(map[string]int)
+	for _, child := range el.children {
+		seen[child.name]++
+		childPath := path + "/" + child.name
+		if seen[child.name] > 1 {
+			childPath += "[" + strconv.Itoa(seen[child.name]) + "]"
+		}
+
+		var match *XMLNode
+		for _, candidate := range node.Children {
+			if candidate.Tag == child.name && !used[candidate] {
+				match = candidate
+				break
+			}
+		}
+		if match == nil {
+			result.Unmatched = append(result.Unmatched, childPath)
+			continue
+		}
+		used[match] = true
+		if err := p.importXMLNode(match, child, childPath, result); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TagText tags the text in [start, end) of a story with the given tag name,
+// adding the tag to Tags.xml if needed. If the story is not yet part of the
+// XML structure, an element with the "Story" tag is placed on it under the
+// root element.
+//
+// The range must lie within a single character style range; see
+// story.Story.TagText. Returns ErrNotFound if the package has no backing story.
+func (p *Package) TagText(storyFile string, start, end int, tag string) (*story.XMLElement, error) {
+	const op = "tag text"
+	if !isXMLName(tag) {
+		return nil, common.Errorf("idml", op, storyFile, "%q is not a valid tag name", tag)
+	}
+
+	st, err := p.Story(storyFile)
+	if err != nil {
+		return nil, common.WrapErrorWithPath("idml", op, storyFile, err)
+	}
+	tags, err := p.Tags()
+	if err != nil {
+		return nil, common.WrapErrorWithPath("idml", op, PathTags, err)
+	}
+	root, err := p.XMLStructure()
+	if err != nil {
+		return nil, common.WrapError("idml", op, err)
+	}
+
+	used, err := p.usedSelfIDs()
+	if err != nil {
+		return nil, common.WrapError("idml", op, err)
+	}
+	ids := newIDAllocator(used)
+
+	element, err := st.TagText(start, end, ids.next(), "XMLTag/"+tag)
+	if err != nil {
+		return nil, err
+	}
+	tags.AddTag(tag)
+
+	if !placesStory(root, st.StoryElement.Self) {
+		tags.AddTag("Story")
+		root.Element.Children = append(root.Element.Children, story.CharacterChild{XMLElement: &story.XMLElement{
+			XMLName:    xml.Name{Local: "XMLElement"},
+			Self:       ids.next(),
+			MarkupTag:  "XMLTag/Story",
+			XMLContent: st.StoryElement.Self,
+		}})
+	}
+	return element, nil
+}
+
+// placesStory reports whether an element in the tree is placed on the story.
+func placesStory(node *XMLNode, storySelf string) bool {
+	if node.Element.XMLContent == storySelf {
+		return true
+	}
+	for _, child := range node.Children {
+		if placesStory(child, storySelf) {
+			return true
+		}
+	}
+	return false
+}
+
+// isXMLName reports whether name can be used as an XML element name.
+func isXMLName(name string) bool {
+	if name == "" || strings.HasPrefix(strings.ToLower(name), "xml") {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_' || unicode.IsLetter(r):
+		case i > 0 && (r == '-' || r == '.' || unicode.IsDigit(r)):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// plainElement is an element of a plain XML document read by ImportXML.
+type plainElement struct {
+	name     string
+	attrs    []xml.Attr
+	children []*plainElement
+	content  []string // character data and the text of child elements, in order
+}
+
+// text returns all character data inside the element.
+func (e *plainElement) text() string {
+	return strings.Join(e.content, "")
+}
+
+// parsePlainXML reads a plain XML document into a tree of plainElements.
+func parsePlainXML(r io.Reader) (*plainElement, error) {
+	d := xml.NewDecoder(r)
+	var stack []*plainElement
+	var root *plainElement
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			el := &plainElement{name: t.Name.Local, attrs: t.Attr}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, el)
+			} else if root == nil {
+				root = el
+			}
+			stack = append(stack, el)
+		case xml.EndElement:
+			el := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.content = append(parent.content, el.text())
+			}
+		case xml.CharData:
+			if len(stack) > 0 {
+				el := stack[len(stack)-1]
+				el.content = append(el.content, string(t))
+			}
+		}
+	}
+	if root == nil {
+		return nil, common.ErrInvalidFormat
+	}
+	return root, nil
+}
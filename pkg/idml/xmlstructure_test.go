@@ -0,0 +1,215 @@
+package idml
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/story"
+)
+
+// TestXMLStructure_Example tests reading the untagged structure of the example document.
+func TestXMLStructure_Example(t *testing.T) {
+	pkg := loadExampleIDML(t)
+
+	tags, err := pkg.Tags()
+	if err != nil {
+		t.Fatalf("Tags failed: %v", err)
+	}
+	if len(tags.Tags) != 1 || tags.Tag("Root") == nil || tags.Tag("Root").Properties.TagColor == nil {
+		t.Errorf("Tags() = %+v, want the colored Root tag", tags.Tags)
+	}
+
+	root, err := pkg.XMLStructure()
+	if err != nil {
+		t.Fatalf("XMLStructure failed: %v", err)
+	}
+	if root.Self != "di3" || root.Tag != "Root" || root.StoryFile != "" || len(root.Children) != 0 {
+		t.Errorf("root = %+v", root)
+	}
+}
+
+// TestTagText_ExportImport tests tagging story text, exporting the structure
+// as plain XML and importing changed XML.
+func TestTagText_ExportImport(t *testing.T) {
+	pkg := loadExampleIDML(t)
+
+	st, err := pkg.Story(hyperlinkStory)
+	if err != nil {
+		t.Fatalf("Story failed: %v", err)
+	}
+	r := st.FindText("dolor sit amet")[0]
+	element, err := pkg.TagText(hyperlinkStory, r.Start, r.End, "Phrase")
+	if err != nil {
+		t.Fatalf("TagText failed: %v", err)
+	}
+	if element.MarkupTag != "XMLTag/Phrase" || element.Self == "" {
+		t.Errorf("new element = %+v", element)
+	}
+	element.SetAttribute("lang", "la")
+	if _, err := pkg.TagText(hyperlinkStory, 0, 4, "not a name"); err == nil {
+		t.Error("expected error for an invalid tag name")
+	}
+
+	reread, err := Read(writeTestIDML(t, pkg, "tagged.idml"))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	tags, err := reread.Tags()
+	if err != nil {
+		t.Fatalf("Tags failed: %v", err)
+	}
+	if tags.Tag("Phrase") == nil || tags.Tag("Story") == nil {
+		t.Errorf("Tags() = %+v, want Phrase and Story tags added", tags.Tags)
+	}
+
+	root, err := reread.XMLStructure()
+	if err != nil {
+		t.Fatalf("XMLStructure failed: %v", err)
+	}
+	if len(root.Children) != 1 {
+		t.Fatalf("root has %d children, want the placed story", len(root.Children))
+	}
+	placed := root.Children[0]
+	if placed.Tag != "Story" || placed.StoryFile != hyperlinkStory || placed.Element.XMLContent != "u2ee" || len(placed.Children) != 1 {
+		t.Fatalf("placed story node = %+v", placed)
+	}
+	if phrase := placed.Children[0]; phrase.Text != "dolor sit amet" || phrase.Range != r || phrase.StoryFile != hyperlinkStory {
+		t.Errorf("phrase node = %+v", phrase)
+	}
+
+	var out bytes.Buffer
+	if err := reread.ExportXML(&out); err != nil {
+		t.Fatalf("ExportXML failed: %v", err)
+	}
+	want := `<Root><Story>ANNA ipsum <Phrase lang="la">dolor sit amet</Phrase>, consectetur adipiscing elit.</Story></Root>`
+	if !strings.Contains(out.String(), want) {
+		t.Errorf("ExportXML() =\n%s\nwant it to contain\n%s", out.String(), want)
+	}
+
+	changed := strings.Replace(out.String(), "dolor sit amet", "lorem &amp; more", 1)
+	changed = strings.Replace(changed, `lang="la"`, `lang="en"`, 1)
+	changed = strings.Replace(changed, "</Story>", "<Note>extra</Note></Story>", 1)
+	result, err := reread.ImportXML(strings.NewReader(changed))
+	if err != nil {
+		t.Fatalf("ImportXML failed: %v", err)
+	}
+	if len(result.Updated) != 1 || result.Updated[0] != element.Self {
+		t.Errorf("Updated = %v, want [%s]", result.Updated, element.Self)
+	}
+	if len(result.Unmatched) != 1 || result.Unmatched[0] != "/Root/Story/Note" {
+		t.Errorf("Unmatched = %v", result.Unmatched)
+	}
+	st, _ = reread.Story(hyperlinkStory)
+	if got := st.ExtractText(); got != "ANNA ipsum lorem & more, consectetur adipiscing elit." {
+		t.Errorf("story text after import = %q", got)
+	}
+	if found, _ := st.FindXMLElement(element.Self); found.Text != "lorem & more" {
+		t.Errorf("tagged text after import = %q", found.Text)
+	}
+	if lang, _ := st.XMLElements()[0].Element.Attribute("lang"); lang != "en" {
+		t.Errorf("lang attribute after import = %q", lang)
+	}
+
+	if _, err := reread.ImportXML(strings.NewReader("<Other/>")); err == nil {
+		t.Error("expected error for a mismatched root element")
+	}
+}
+
+// TestImportXML_PlacedStory tests replacing the text of a story placed in the
+// structure without tagged children.
+func TestImportXML_PlacedStory(t *testing.T) {
+	pkg := loadExampleIDML(t)
+
+	// Tagging and untagging leaves the story placed but without child elements
+	element, err := pkg.TagText(hyperlinkStory, 0, 4, "Name")
+	if err != nil {
+		t.Fatalf("TagText failed: %v", err)
+	}
+	st, _ := pkg.Story(hyperlinkStory)
+	if err := st.UntagXMLElement(element.Self); err != nil {
+		t.Fatalf("UntagXMLElement failed: %v", err)
+	}
+
+	result, err := pkg.ImportXML(strings.NewReader("<Root><Story>New text\nsecond line</Story></Root>"))
+	if err != nil {
+		t.Fatalf("ImportXML failed: %v", err)
+	}
+	if len(result.Updated) != 1 {
+		t.Errorf("Updated = %v, want the placed story element", result.Updated)
+	}
+	if got := st.ExtractText(); got != "New text\nsecond line" {
+		t.Errorf("story text after import = %q", got)
+	}
+}
+
+// TestXMLStructure_ParagraphElement tests an element enclosing a whole
+// character style range as a child of ParagraphStyleRange.
+func TestXMLStructure_ParagraphElement(t *testing.T) {
+	pkg := loadExampleIDML(t)
+
+	// Tagging places the story in the structure; the paragraph tag replaces the text tag
+	element, err := pkg.TagText(hyperlinkStory, 0, 4, "Name")
+	if err != nil {
+		t.Fatalf("TagText failed: %v", err)
+	}
+	st, _ := pkg.Story(hyperlinkStory)
+	if err := st.UntagXMLElement(element.Self); err != nil {
+		t.Fatalf("UntagXMLElement failed: %v", err)
+	}
+	tags, _ := pkg.Tags()
+	tags.AddTag("Para")
+	text := st.ExtractText()
+	csr := &st.StoryElement.ParagraphStyleRanges[0].CharacterStyleRanges[0]
+	csr.XMLElements = []*story.XMLElement{{Self: "di90", MarkupTag: "XMLTag/Para"}}
+
+	reread, err := Read(writeTestIDML(t, pkg, "paragraph_tag.idml"))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	root, err := reread.XMLStructure()
+	if err != nil {
+		t.Fatalf("XMLStructure failed: %v", err)
+	}
+	placed := root.Children[0]
+	if len(placed.Children) != 1 {
+		t.Fatalf("placed story has %d children, want the paragraph element", len(placed.Children))
+	}
+	if para := placed.Children[0]; para.Self != "di90" || para.Tag != "Para" || para.StoryFile != hyperlinkStory || para.Text != text {
+		t.Errorf("paragraph node = %+v", para)
+	}
+
+	var out bytes.Buffer
+	if err := reread.ExportXML(&out); err != nil {
+		t.Fatalf("ExportXML failed: %v", err)
+	}
+	if want := "<Root><Story><Para>" + text + "</Para></Story></Root>"; !strings.Contains(out.String(), want) {
+		t.Errorf("ExportXML() =\n%s\nwant it to contain\n%s", out.String(), want)
+	}
+
+	changed := strings.Replace(out.String(), text, "New paragraph", 1)
+	result, err := reread.ImportXML(strings.NewReader(changed))
+	if err != nil {
+		t.Fatalf("ImportXML failed: %v", err)
+	}
+	if len(result.Updated) != 1 || result.Updated[0] != "di90" {
+		t.Errorf("Updated = %v, want [di90]", result.Updated)
+	}
+	st, _ = reread.Story(hyperlinkStory)
+	if found, err := st.FindXMLElement("di90"); err != nil || found.Text != "New paragraph" {
+		t.Errorf("paragraph element after import = %+v, %v", found, err)
+	}
+}
+
+// TestXMLStructure_Missing tests the error for packages without a backing story.
+func TestXMLStructure_Missing(t *testing.T) {
+	pkg := New()
+	if _, err := pkg.XMLStructure(); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("XMLStructure() error = %v, want ErrNotFound", err)
+	}
+	if _, err := pkg.Tags(); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("Tags() error = %v, want ErrNotFound", err)
+	}
+}
@@ -156,7 +156,7 @@ func (e *Exporter) extractReferencedStories() (map[string]*story.Story, error) {
 }
 
 // extractReferencedStyles extracts all referenced style definitions.
-// This includes ParagraphStyles, CharacterStyles, and ObjectStyles with their inheritance chains.
+// This includes ParagraphStyles, CharacterStyles, ObjectStyles, TableStyles and CellStyles with their inheritance chains.
 // Returns the complete Styles resource file with only referenced styles.
 func (e *Exporter) extractReferencedStyles() (*resources.StylesFile, error) {
 	// Get the full styles file from source package
@@ -191,6 +191,23 @@ func (e *Exporter) extractReferencedStyles() (*resources.StylesFile, error) {
 	// Extract object styles with their nested group structure
 	e.extractObjectStylesWithGroups(srcStyles.RootObjectStyleGroup, extracted.RootObjectStyleGroup)
 
+	// Extract table and cell styles with their nested group structure
+	// (only if tables are part of the selection)
+	if len(e.deps.CellStyles) > 0 && srcStyles.RootCellStyleGroup != nil {
+		extracted.RootCellStyleGroup = &resources.CellStyleGroup{
+			Self:    srcStyles.RootCellStyleGroup.Self,
+			XMLName: xml.Name{Local: "RootCellStyleGroup"},
+		}
+		e.extractCellStylesWithGroups(srcStyles.RootCellStyleGroup, extracted.RootCellStyleGroup)
+	}
+	if len(e.deps.TableStyles) > 0 && srcStyles.RootTableStyleGroup != nil {
+		extracted.RootTableStyleGroup = &resources.TableStyleGroup{
+			Self:    srcStyles.RootTableStyleGroup.Self,
+			XMLName: xml.Name{Local: "RootTableStyleGroup"},
+		}
+		e.extractTableStylesWithGroups(srcStyles.RootTableStyleGroup, extracted.RootTableStyleGroup)
+	}
+
 	return extracted, nil
 }
 
@@ -437,6 +454,116 @@ func (e *Exporter) groupContainsObjectStyle(group *resources.ObjectStyleGroup) b
 	return false
 }
 
+// extractCellStylesWithGroups extracts cell styles while preserving nested group structure.
+// Only extracts groups that contain referenced styles.
+func (e *Exporter) extractCellStylesWithGroups(srcGroup, dstGroup *resources.CellStyleGroup) {
+	if srcGroup == nil || dstGroup == nil {
+		return
+	}
+
+	// Extract styles at this level
+	for i := range srcGroup.CellStyles {
+		style := &srcGroup.CellStyles[i]
+		if e.deps.CellStyles[style.Self] {
+			dstGroup.CellStyles = append(dstGroup.CellStyles, *style)
+		}
+	}
+
+	// Recursively extract nested groups that contain referenced styles
+	for i := range srcGroup.NestedGroups {
+		srcNestedGroup := &srcGroup.NestedGroups[i]
+		if e.groupContainsCellStyle(srcNestedGroup) {
+			// Create a copy of the nested group (without styles/nested groups initially)
+			dstNestedGroup := resources.CellStyleGroup{
+				XMLName: srcNestedGroup.XMLName,
+				Self:    srcNestedGroup.Self,
+				Name:    srcNestedGroup.Name,
+			}
+			// Recursively extract styles from this group
+			e.extractCellStylesWithGroups(srcNestedGroup, &dstNestedGroup)
+			dstGroup.NestedGroups = append(dstGroup.NestedGroups, dstNestedGroup)
+		}
+	}
+}
+
+// groupContainsCellStyle checks if a group or any of its nested groups contains a referenced cell style.
+func (e *Exporter) groupContainsCellStyle(group *resources.CellStyleGroup) bool {
+	if group == nil {
+		return false
+	}
+
+	// Check styles at this level
+	for i := range group.CellStyles {
+		if e.deps.CellStyles[group.CellStyles[i].Self] {
+			return true
+		}
+	}
+
+	// Check nested groups
+	for i := range group.NestedGroups {
+		if e.groupContainsCellStyle(&group.NestedGroups[i]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// extractTableStylesWithGroups extracts table styles while preserving nested group structure.
+// Only extracts groups that contain referenced styles.
+func (e *Exporter) extractTableStylesWithGroups(srcGroup, dstGroup *resources.TableStyleGroup) {
+	if srcGroup == nil || dstGroup == nil {
+		return
+	}
+
+	// Extract styles at this level
+	for i := range srcGroup.TableStyles {
+		style := &srcGroup.TableStyles[i]
+		if e.deps.TableStyles[style.Self] {
+			dstGroup.TableStyles = append(dstGroup.TableStyles, *style)
+		}
+	}
+
+	// Recursively extract nested groups that contain referenced styles
+	for i := range srcGroup.NestedGroups {
+		srcNestedGroup := &srcGroup.NestedGroups[i]
+		if e.groupContainsTableStyle(srcNestedGroup) {
+			// Create a copy of the nested group (without styles/nested groups initially)
+			dstNestedGroup := resources.TableStyleGroup{
+				XMLName: srcNestedGroup.XMLName,
+				Self:    srcNestedGroup.Self,
+				Name:    srcNestedGroup.Name,
+			}
+			// Recursively extract styles from this group
+			e.extractTableStylesWithGroups(srcNestedGroup, &dstNestedGroup)
+			dstGroup.NestedGroups = append(dstGroup.NestedGroups, dstNestedGroup)
+		}
+	}
+}
+
+// groupContainsTableStyle checks if a group or any of its nested groups contains a referenced table style.
+func (e *Exporter) groupContainsTableStyle(group *resources.TableStyleGroup) bool {
+	if group == nil {
+		return false
+	}
+
+	// Check styles at this level
+	for i := range group.TableStyles {
+		if e.deps.TableStyles[group.TableStyles[i].Self] {
+			return true
+		}
+	}
+
+	// Check nested groups
+	for i := range group.NestedGroups {
+		if e.groupContainsTableStyle(&group.NestedGroups[i]) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // extractReferencedColors extracts referenced color definitions from Graphics resource.
 // Returns a GraphicFile with only referenced colors.
 func (e *Exporter) extractReferencedColors() (*resources.GraphicFile, error) {
@@ -503,7 +630,8 @@ func (e *Exporter) extractAllResources() (*ExtractedResources, error) {
 	resources.Stories = stories
 
 	// Extract styles (only if we have style dependencies)
-	if len(e.deps.ParagraphStyles) > 0 || len(e.deps.CharacterStyles) > 0 || len(e.deps.ObjectStyles) > 0 {
+	if len(e.deps.ParagraphStyles) > 0 || len(e.deps.CharacterStyles) > 0 || len(e.deps.ObjectStyles) > 0 ||
+		len(e.deps.TableStyles) > 0 || len(e.deps.CellStyles) > 0 {
 		styles, err := e.extractReferencedStyles()
 		if err != nil {
 			return nil, fmt.Errorf("failed to extract styles: %w", err)
@@ -45,6 +45,8 @@ func (e *Exporter) buildMinimalPackage(sel *idml.Selection, resources *Extracted
 	if resources.Styles != nil {
 		doc.RootCharacterStyleGroup = resources.Styles.RootCharacterStyleGroup
 		doc.RootParagraphStyleGroup = resources.Styles.RootParagraphStyleGroup
+		doc.RootCellStyleGroup = resources.Styles.RootCellStyleGroup
+		doc.RootTableStyleGroup = resources.Styles.RootTableStyleGroup
 		doc.RootObjectStyleGroup = resources.Styles.RootObjectStyleGroup
 	} else {
 		// Add minimal default styles
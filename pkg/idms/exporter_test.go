@@ -6,6 +6,7 @@ import (
 	"github.com/dimelords/idmllib/v2/pkg/idml"
 	"github.com/dimelords/idmllib/v2/pkg/idms"
 	"github.com/dimelords/idmllib/v2/pkg/spread"
+	"github.com/dimelords/idmllib/v2/pkg/story"
 )
 
 func TestNewExporter(t *testing.T) {
@@ -800,6 +801,69 @@ func TestExportRectangle_WithObjectStyle(t *testing.T) {
 	t.Logf("   Object styles: %d", len(deps.ObjectStyles))
 }
 
+// TestExportTextFrame_WithTable tests that the table and cell styles of a
+// table in an exported story are included.
+func TestExportTextFrame_WithTable(t *testing.T) {
+	pkg, err := idml.Read("../../testdata/example.idml")
+	if err != nil {
+		t.Fatalf("Failed to open test IDML: %v", err)
+	}
+
+	spreads, err := pkg.Spreads()
+	if err != nil {
+		t.Fatalf("Failed to get spreads: %v", err)
+	}
+
+	// Find a text frame whose story has text
+	var testFrame *spread.SpreadTextFrame
+	var csr *story.CharacterStyleRange
+	for _, sp := range spreads {
+		for i := range sp.InnerSpread.TextFrames {
+			tf := &sp.InnerSpread.TextFrames[i]
+			st, err := pkg.Story("Stories/Story_" + tf.ParentStory + ".xml")
+			if err != nil || len(st.StoryElement.ParagraphStyleRanges) == 0 || len(st.StoryElement.ParagraphStyleRanges[0].CharacterStyleRanges) == 0 {
+				continue
+			}
+			testFrame = tf
+			csr = &st.StoryElement.ParagraphStyleRanges[0].CharacterStyleRanges[0]
+			break
+		}
+		if testFrame != nil {
+			break
+		}
+	}
+	if testFrame == nil {
+		t.Skip("No text frame with text found")
+	}
+
+	// Add a table to the story
+	csr.Children = append(csr.Children, story.CharacterChild{Table: &story.Table{
+		Self:              "utbl1",
+		BodyRowCount:      "1",
+		ColumnCount:       "1",
+		AppliedTableStyle: "TableStyle/$ID/[Basic Table]",
+		Cells:             []story.Cell{{Self: "utbl1i0i0", Name: "0:0", AppliedCellStyle: "CellStyle/$ID/[None]"}},
+	}})
+
+	sel := idml.NewSelection()
+	sel.AddTextFrame(testFrame)
+
+	exporter := idms.NewExporter(pkg)
+	result, err := exporter.ExportSelection(sel)
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	tables := result.Document.RootTableStyleGroup
+	if tables == nil || len(tables.TableStyles) != 1 || tables.TableStyles[0].Self != "TableStyle/$ID/[Basic Table]" {
+		t.Errorf("RootTableStyleGroup = %+v, want [Basic Table]", tables)
+	}
+	cells := result.Document.RootCellStyleGroup
+	if cells == nil || len(cells.CellStyles) != 1 || cells.CellStyles[0].Self != "CellStyle/$ID/[None]" {
+		t.Errorf("RootCellStyleGroup = %+v, want [None]", cells)
+	}
+}
+
 // ============================================================================
 // Phase 5.3: Mixed Selection Export Tests
 // ============================================================================
@@ -1077,3 +1141,80 @@ func TestExportMixed_CompleteDocument(t *testing.T) {
 	t.Logf("   Rectangles: %d", len(exportedSpread.Rectangles))
 	t.Logf("   Total elements: %d", exportedTotal)
 }
+
+// TestExportTextFrame_WithAnchoredTextFrame tests that stories of text frames
+// anchored in the exported story are included.
+func TestExportTextFrame_WithAnchoredTextFrame(t *testing.T) {
+	pkg, err := idml.Read("../../testdata/example.idml")
+	if err != nil {
+		t.Fatalf("Failed to open test IDML: %v", err)
+	}
+
+	spreads, err := pkg.Spreads()
+	if err != nil {
+		t.Fatalf("Failed to get spreads: %v", err)
+	}
+
+	var testFrame *spread.SpreadTextFrame
+	var host *story.Story
+	for _, sp := range spreads {
+		for i := range sp.InnerSpread.TextFrames {
+			tf := &sp.InnerSpread.TextFrames[i]
+			st, err := pkg.Story(idml.StoryPath(tf.ParentStory))
+			if err == nil && len(st.StoryElement.ParagraphStyleRanges) > 0 &&
+				len(st.StoryElement.ParagraphStyleRanges[0].CharacterStyleRanges) > 0 {
+				testFrame, host = tf, st
+				break
+			}
+		}
+		if testFrame != nil {
+			break
+		}
+	}
+	if testFrame == nil {
+		t.Skip("No text frame with story found")
+	}
+
+	// Pick another story to show in the anchored frame
+	stories, err := pkg.Stories()
+	if err != nil {
+		t.Fatalf("Failed to get stories: %v", err)
+	}
+	var anchoredStory string
+	for _, st := range stories {
+		if st.StoryElement.Self != host.StoryElement.Self {
+			anchoredStory = st.StoryElement.Self
+			break
+		}
+	}
+	if anchoredStory == "" {
+		t.Skip("Need a second story")
+	}
+
+	frame := &story.AnchoredTextFrame{}
+	frame.Self = "uanc1"
+	frame.ParentStory = anchoredStory
+	frame.AppliedObjectStyle = "ObjectStyle/$ID/[Normal Text Frame]"
+	csr := &host.StoryElement.ParagraphStyleRanges[0].CharacterStyleRanges[0]
+	csr.Children = append(csr.Children, story.CharacterChild{Anchored: &story.AnchoredObject{TextFrame: frame}})
+
+	sel := idml.NewSelection()
+	sel.AddTextFrame(testFrame)
+
+	exporter := idms.NewExporter(pkg)
+	result, err := exporter.ExportSelection(sel)
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	found := make(map[string]bool)
+	for _, st := range result.Document.InlineStories {
+		found[st.Self] = true
+	}
+	if !found[host.StoryElement.Self] || !found[anchoredStory] {
+		t.Errorf("Expected stories %s and %s inline, got %v", host.StoryElement.Self, anchoredStory, found)
+	}
+	if !exporter.Dependencies().ObjectStyles["ObjectStyle/$ID/[Normal Text Frame]"] {
+		t.Error("Expected anchored frame object style in dependencies")
+	}
+}
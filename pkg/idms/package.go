@@ -19,6 +19,7 @@ import (
 	"fmt"
 
 	"github.com/dimelords/idmllib/v2/pkg/document"
+	"github.com/dimelords/idmllib/v2/pkg/idml"
 	"github.com/dimelords/idmllib/v2/pkg/spread"
 	"github.com/dimelords/idmllib/v2/pkg/story"
 	"github.com/dimelords/idmllib/v2/pkg/xmp"
@@ -158,6 +159,14 @@ func (p *Package) Stories() []story.StoryElement {
 	return p.Document.InlineStories
 }
 
+// SnippetDocument returns the snippet Document, so the package can be placed
+// into an IDML package with idml.Package.PlaceSnippet.
+func (p *Package) SnippetDocument() *document.Document {
+	return p.Document
+}
+
+var _ idml.Snippet = (*Package)(nil)
+
 // XMP returns an XMP accessor for the package metadata.
 // This allows reading and modifying XMP metadata in a type-safe way.
 // Returns an XMP Metadata instance that can be used to update timestamps,
@@ -0,0 +1,41 @@
+package idms
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestWriteTo_MatchesWrite verifies that WriteTo produces the same output as Write.
+func TestWriteTo_MatchesWrite(t *testing.T) {
+	pkg, err := Read("../../testdata/Snippet_31F27A2D0.idms")
+	if err != nil {
+		t.Fatalf("Read() error: %v", err)
+	}
+
+	outPath := filepath.Join(t.TempDir(), "write_to.idms")
+	if err := Write(pkg, outPath); err != nil {
+		t.Fatalf("Write() error: %v", err)
+	}
+	fileData, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("failed to read written file: %v", err)
+	}
+
+	var buf bytes.Buffer
+	n, err := pkg.WriteTo(&buf)
+	if err != nil {
+		t.Fatalf("WriteTo() error: %v", err)
+	}
+	if n != int64(len(fileData)) {
+		t.Errorf("WriteTo() wrote %d bytes, want %d", n, len(fileData))
+	}
+	if !bytes.Equal(fileData, buf.Bytes()) {
+		t.Error("WriteTo() output differs from Write() output")
+	}
+
+	if _, err := pkg.WriteTo(nil); err == nil {
+		t.Error("WriteTo(nil) should return error")
+	}
+}
@@ -95,7 +95,9 @@
 //   - Object styles can be based on other object styles
 //
 // The pkg/analysis package provides tools for resolving these hierarchies
-// when exporting IDMS snippets.
+// when exporting IDMS snippets. ResolveParagraphStyle and
+// ResolveCharacterStyle compute the formatting of a style, merging the
+// attributes and Properties of the styles it is based on.
 //
 // # Font Status
 //
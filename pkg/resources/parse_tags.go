@@ -0,0 +1,81 @@
+package resources
+
+import (
+	"encoding/xml"
+
+	"github.com/dimelords/idmllib/v2/internal/xmlutil"
+	"github.com/dimelords/idmllib/v2/pkg/common"
+)
+
+// ParseTagsFile parses a Tags.xml file into a TagsFile struct.
+func ParseTagsFile(data []byte) (*TagsFile, error) {
+	if len(data) == 0 {
+		return nil, common.Errorf("resources", "parse tags", "", "input data is empty")
+	}
+
+	var tags TagsFile
+	if err := xml.Unmarshal(data, &tags); err != nil {
+		return nil, common.WrapError("resources", "parse tags", err)
+	}
+	return &tags, nil
+}
+
+// MarshalTagsFile marshals a TagsFile struct back to XML with proper formatting.
+func MarshalTagsFile(tags *TagsFile) ([]byte, error) {
+	return xmlutil.MarshalIndentWithHeader(tags, "", "\t")
+}
+
+// UnmarshalXML implements custom XML unmarshaling for TagsFile.
+func (t *TagsFile) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	// Verify we're parsing an idPkg:Tags element
+	if start.Name.Local != "Tags" {
+		return common.WrapError("resources", "unmarshal tags", common.ErrInvalidFormat)
+	}
+
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "DOMVersion" {
+			t.DOMVersion = attr.Value
+			break
+		}
+	}
+
+	type tagsContent struct {
+		Tags          []XMLTag               `xml:"XMLTag,omitempty"`
+		OtherElements []common.RawXMLElement `xml:",any"`
+	}
+
+	var content tagsContent
+	if err := d.DecodeElement(&content, &start); err != nil {
+		return common.WrapError("resources", "unmarshal tags content", err)
+	}
+
+	t.Tags = content.Tags
+	t.OtherElements = content.OtherElements
+	return nil
+}
+
+// MarshalXML implements custom XML marshaling for TagsFile.
+func (t *TagsFile) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	wrapper := xml.StartElement{
+		Name: xml.Name{Local: "idPkg:Tags"},
+		Attr: []xml.Attr{
+			{Name: xml.Name{Local: "xmlns:idPkg"}, Value: "http://ns.adobe.com/AdobeInDesign/idml/1.0/packaging"},
+			{Name: xml.Name{Local: "DOMVersion"}, Value: t.DOMVersion},
+		},
+	}
+
+	if err := e.EncodeToken(wrapper); err != nil {
+		return err
+	}
+	for _, tag := range t.Tags {
+		if err := e.EncodeElement(&tag, xml.StartElement{Name: xml.Name{Local: "XMLTag"}}); err != nil {
+			return err
+		}
+	}
+	for _, elem := range t.OtherElements {
+		if err := e.EncodeElement(&elem, xml.StartElement{Name: elem.XMLName}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(wrapper.End())
+}
@@ -267,3 +267,41 @@ func TestMarshalStylesFile_BasicStructure(t *testing.T) {
 		t.Error("RootCharacterStyleGroup is nil after roundtrip")
 	}
 }
+
+// TestTagsFile_Roundtrip tests parsing, extending and marshaling Tags.xml.
+func TestTagsFile_Roundtrip(t *testing.T) {
+	const tagsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<idPkg:Tags xmlns:idPkg="http://ns.adobe.com/AdobeInDesign/idml/1.0/packaging" DOMVersion="20.4">
+	<XMLTag Self="XMLTag/Root" Name="Root">
+		<Properties>
+			<TagColor type="enumeration">LightBlue</TagColor>
+		</Properties>
+	</XMLTag>
+</idPkg:Tags>`
+
+	tags, err := ParseTagsFile([]byte(tagsXML))
+	if err != nil {
+		t.Fatalf("ParseTagsFile failed: %v", err)
+	}
+	if tags.DOMVersion != "20.4" || len(tags.Tags) != 1 || tags.Tag("Root") == nil {
+		t.Fatalf("parsed tags = %+v", tags)
+	}
+	if tags.AddTag("Root") != tags.Tag("Root") || tags.AddTag("Title").Self != "XMLTag/Title" || len(tags.Tags) != 2 {
+		t.Errorf("AddTag() gave tags %+v", tags.Tags)
+	}
+
+	data, err := MarshalTagsFile(tags)
+	if err != nil {
+		t.Fatalf("MarshalTagsFile failed: %v", err)
+	}
+	out := string(data)
+	for _, want := range []string{`<idPkg:Tags xmlns:idPkg=`, `<TagColor type="enumeration">LightBlue</TagColor>`, `<XMLTag Self="XMLTag/Title" Name="Title" />`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %s:\n%s", want, out)
+		}
+	}
+
+	if _, err := ParseTagsFile(nil); err == nil {
+		t.Error("expected error for empty data")
+	}
+}
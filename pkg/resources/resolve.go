@@ -0,0 +1,247 @@
+package resources
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strconv"
+	"strings"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+)
+
+// Formatting is a set of text formatting values: attributes such as
+// PointSize or Justification and Properties children such as AppliedFont or
+// Leading, by name.
+type Formatting struct {
+	// Attributes maps attribute names to values
+	Attributes map[string]string
+
+	// Properties maps the names of Properties children to the elements
+	Properties map[string]common.RawXMLElement
+}
+
+// ResolvedStyle is the computed formatting of a paragraph or character
+// style: the formatting of the style and the styles it is based on, where a
+// style overrides the styles it is based on.
+type ResolvedStyle struct {
+	// Self is the ID of the resolved style
+	Self string
+
+	// Chain lists the styles that were merged, from Self to the root of the
+	// BasedOn chain (e.g., "ParagraphStyle/$ID/[No paragraph style]")
+	Chain []string
+
+	Formatting
+}
+
+// styleIdentityAttrs are style attributes that identify or describe a style
+// rather than format text; they are not part of the computed formatting.
+var styleIdentityAttrs = map[string]bool{
+	"Self": true, "Name": true, "Imported": true, "NextStyle": true, "SplitDocument": true,
+	"EmitCss": true, "StyleUniqueId": true, "IncludeClass": true,
+	"ExtendedKeyboardShortcut": true, "KeyboardShortcut": true,
+}
+
+// styleIdentityProperties are Properties children that are not formatting.
+var styleIdentityProperties = map[string]bool{
+	"BasedOn": true, "PreviewColor": true,
+}
+
+// NewFormatting returns an empty Formatting.
+func NewFormatting() Formatting {
+	return Formatting{
+		Attributes: make(map[string]string),
+		Properties: make(map[string]common.RawXMLElement),
+	}
+}
+
+// Merge sets the given attributes and Properties children, overriding values
+// already set. props may be nil.
+func (f *Formatting) Merge(attrs []xml.Attr, props *common.Properties) {
+	for _, attr := range attrs {
+		f.Attributes[attr.Name.Local] = attr.Value
+	}
+	if props == nil {
+		return
+	}
+	for _, elem := range props.OtherElements {
+		f.Properties[elem.XMLName.Local] = elem
+	}
+}
+
+// Attr returns the value of an attribute, or "" if it is not set.
+func (f *Formatting) Attr(name string) string {
+	return f.Attributes[name]
+}
+
+// Property returns the text of a Properties child, such as "Publico Text"
+// for AppliedFont, or "" if it is not set. Structured properties such as
+// TabList return their inner XML.
+func (f *Formatting) Property(name string) string {
+	elem, ok := f.Properties[name]
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(string(elem.Content))
+}
+
+// AppliedFont returns the font family, such as "Minion Pro".
+func (f *Formatting) AppliedFont() string {
+	return f.Property("AppliedFont")
+}
+
+// FontStyle returns the font style, such as "Bold Italic".
+func (f *Formatting) FontStyle() string {
+	return f.Attr("FontStyle")
+}
+
+// PointSize returns the font size in points, or 0 if it is not set or invalid.
+func (f *Formatting) PointSize() float64 {
+	size, _ := strconv.ParseFloat(f.Attr("PointSize"), 64)
+	return size
+}
+
+// Leading returns the leading in points or "Auto", or "" if it is not set.
+func (f *Formatting) Leading() string {
+	if leading := f.Property("Leading"); leading != "" {
+		return leading
+	}
+	return f.Attr("Leading")
+}
+
+// FillColor returns the text color reference, such as "Color/Black".
+func (f *Formatting) FillColor() string {
+	return f.Attr("FillColor")
+}
+
+// Justification returns the paragraph alignment, such as "LeftAlign".
+func (f *Formatting) Justification() string {
+	return f.Attr("Justification")
+}
+
+// ResolveParagraphStyle returns the computed formatting of a paragraph style,
+// following its BasedOn chain.
+//
+// Returns common.ErrNotFound if the style does not exist. A BasedOn
+// reference to a style that does not exist ends the chain.
+//
+// Example:
+//
+//	resolved, err := styles.ResolveParagraphStyle("ParagraphStyle/Body")
+//	fmt.Println(resolved.AppliedFont(), resolved.PointSize(), resolved.Leading())
+func (sf *StylesFile) ResolveParagraphStyle(styleID string) (*ResolvedStyle, error) {
+	return resolveStyle("resolve paragraph style", "ParagraphStyle/", styleID, func(id string) (interface{}, *common.Properties) {
+		style := sf.FindParagraphStyle(id)
+		if style == nil {
+			return nil, nil
+		}
+		return style, style.Properties
+	})
+}
+
+// ResolveCharacterStyle returns the computed formatting of a character style,
+// following its BasedOn chain, as ResolveParagraphStyle.
+func (sf *StylesFile) ResolveCharacterStyle(styleID string) (*ResolvedStyle, error) {
+	return resolveStyle("resolve character style", "CharacterStyle/", styleID, func(id string) (interface{}, *common.Properties) {
+		style := sf.FindCharacterStyle(id)
+		if style == nil {
+			return nil, nil
+		}
+		return style, style.Properties
+	})
+}
+
+// ResolveObjectStyle returns the computed attributes of an object style,
+// such as FillColor, StrokeWeight or EnableFill, following its BasedOn
+// chain. Settings elements such as TextWrapPreference are not merged; they
+// belong to the first style in Chain that has them.
+//
+// Example:
+//
+//	resolved, err := styles.ResolveObjectStyle("ObjectStyle/Caption Frame")
+//	if resolved.Attr("EnableFill") == "true" {
+//	    fmt.Println(resolved.FillColor())
+//	}
+func (sf *StylesFile) ResolveObjectStyle(styleID string) (*ResolvedStyle, error) {
+	return resolveStyle("resolve object style", "ObjectStyle/", styleID, func(id string) (interface{}, *common.Properties) {
+		style := sf.FindObjectStyle(id)
+		if style == nil {
+			return nil, nil
+		}
+		return style, style.Properties
+	})
+}
+
+// resolveStyle follows the BasedOn chain of a style and merges the
+// formatting of its styles, root first. find returns a style and its
+// Properties, or nil if the style does not exist.
+func resolveStyle(op, prefix, styleID string, find func(id string) (interface{}, *common.Properties)) (*ResolvedStyle, error) {
+	type link struct {
+		attrs []xml.Attr
+		props *common.Properties
+	}
+
+	resolved := &ResolvedStyle{Self: styleID, Formatting: NewFormatting()}
+	var chain []link
+	seen := make(map[string]bool)
+	for id := styleID; id != ""; {
+		if seen[id] {
+			return nil, common.Errorf("resources", op, styleID, "BasedOn cycle at %s", id)
+		}
+		seen[id] = true
+
+		style, props := find(id)
+		if style == nil {
+			if id == styleID {
+				return nil, common.WrapErrorWithPath("resources", op, styleID, common.ErrNotFound)
+			}
+			break
+		}
+		attrs, err := styleAttrs(style)
+		if err != nil {
+			return nil, common.WrapErrorWithPath("resources", op, styleID, err)
+		}
+		resolved.Chain = append(resolved.Chain, id)
+		chain = append(chain, link{attrs, props})
+		id = basedOnRef(prefix, props.GetBasedOn())
+	}
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		resolved.Merge(chain[i].attrs, chain[i].props)
+	}
+	for name := range styleIdentityAttrs {
+		delete(resolved.Attributes, name)
+	}
+	for name := range styleIdentityProperties {
+		delete(resolved.Properties, name)
+	}
+	return resolved, nil
+}
+
+// basedOnRef returns the style ID a BasedOn value refers to. InDesign writes
+// the root styles without the type prefix (e.g., "$ID/[No paragraph style]").
+func basedOnRef(prefix, basedOn string) string {
+	if basedOn == "" || strings.HasPrefix(basedOn, prefix) {
+		return basedOn
+	}
+	return prefix + basedOn
+}
+
+// styleAttrs returns the XML attributes of a style struct, both the modeled
+// fields and OtherAttrs, as they are written.
+func styleAttrs(style interface{}) ([]xml.Attr, error) {
+	data, err := xml.Marshal(style)
+	if err != nil {
+		return nil, err
+	}
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Attr, nil
+		}
+	}
+}
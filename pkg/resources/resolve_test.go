@@ -0,0 +1,194 @@
+package resources
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+)
+
+const resolveStylesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<idPkg:Styles xmlns:idPkg="http://ns.adobe.com/AdobeInDesign/idml/1.0/packaging" DOMVersion="20.4">
+	<RootCharacterStyleGroup Self="u78">
+		<CharacterStyle Self="CharacterStyle/$ID/[No character style]" Name="$ID/[No character style]"/>
+		<CharacterStyle Self="CharacterStyle/Emphasis" Name="Emphasis" FontStyle="Italic">
+			<Properties>
+				<BasedOn type="string">$ID/[No character style]</BasedOn>
+			</Properties>
+		</CharacterStyle>
+		<CharacterStyleGroup Self="CharacterStyleGroup/Web" Name="Web">
+			<CharacterStyle Self="CharacterStyle/Web%3aLink" Name="Web:Link" FillColor="Color/Blue" Underline="true">
+				<Properties>
+					<BasedOn type="object">CharacterStyle/Emphasis</BasedOn>
+				</Properties>
+			</CharacterStyle>
+		</CharacterStyleGroup>
+		<CharacterStyle Self="CharacterStyle/Loop A" Name="Loop A">
+			<Properties>
+				<BasedOn type="object">CharacterStyle/Loop B</BasedOn>
+			</Properties>
+		</CharacterStyle>
+		<CharacterStyle Self="CharacterStyle/Loop B" Name="Loop B">
+			<Properties>
+				<BasedOn type="object">CharacterStyle/Loop A</BasedOn>
+			</Properties>
+		</CharacterStyle>
+	</RootCharacterStyleGroup>
+	<RootParagraphStyleGroup Self="u77">
+		<ParagraphStyle Self="ParagraphStyle/$ID/[No paragraph style]" Name="$ID/[No paragraph style]" FontStyle="Regular" PointSize="12" FillColor="Color/Black" Justification="LeftAlign" Hyphenation="true">
+			<Properties>
+				<Leading type="enumeration">Auto</Leading>
+				<AppliedFont type="string">Minion Pro</AppliedFont>
+			</Properties>
+		</ParagraphStyle>
+		<ParagraphStyle Self="ParagraphStyle/Body" Name="Body" NextStyle="ParagraphStyle/Body" PointSize="9.5" Justification="LeftJustified" StyleUniqueId="1234">
+			<Properties>
+				<BasedOn type="string">$ID/[No paragraph style]</BasedOn>
+				<PreviewColor type="enumeration">Nothing</PreviewColor>
+				<Leading type="unit">11.4</Leading>
+				<AppliedFont type="string">Publico Text</AppliedFont>
+			</Properties>
+		</ParagraphStyle>
+		<ParagraphStyle Self="ParagraphStyle/Body Indented" Name="Body Indented" FirstLineIndent="8" Hyphenation="false">
+			<Properties>
+				<BasedOn type="object">ParagraphStyle/Body</BasedOn>
+			</Properties>
+		</ParagraphStyle>
+		<ParagraphStyle Self="ParagraphStyle/Orphan" Name="Orphan" PointSize="20">
+			<Properties>
+				<BasedOn type="object">ParagraphStyle/Deleted</BasedOn>
+			</Properties>
+		</ParagraphStyle>
+	</RootParagraphStyleGroup>
+	<RootObjectStyleGroup Self="u8a">
+		<ObjectStyle Self="ObjectStyle/$ID/[None]" Name="$ID/[None]" EnableFill="true" EnableStroke="true" FillColor="Swatch/None" StrokeWeight="0"/>
+		<ObjectStyle Self="ObjectStyle/Boxed" Name="Boxed" FillColor="Color/Paper" StrokeWeight="1" EnableTextWrapAndOthers="false">
+			<Properties>
+				<BasedOn type="string">$ID/[None]</BasedOn>
+			</Properties>
+		</ObjectStyle>
+	</RootObjectStyleGroup>
+</idPkg:Styles>`
+
+// TestResolveParagraphStyle tests computing a paragraph style from its
+// BasedOn chain.
+func TestResolveParagraphStyle(t *testing.T) {
+	styles, err := ParseStylesFile([]byte(resolveStylesXML))
+	if err != nil {
+		t.Fatalf("ParseStylesFile failed: %v", err)
+	}
+
+	resolved, err := styles.ResolveParagraphStyle("ParagraphStyle/Body Indented")
+	if err != nil {
+		t.Fatalf("ResolveParagraphStyle failed: %v", err)
+	}
+	wantChain := "ParagraphStyle/Body Indented > ParagraphStyle/Body > ParagraphStyle/$ID/[No paragraph style]"
+	if got := strings.Join(resolved.Chain, " > "); got != wantChain {
+		t.Errorf("Chain = %q, want %q", got, wantChain)
+	}
+
+	tests := []struct {
+		name, got, want string
+	}{
+		{"AppliedFont", resolved.AppliedFont(), "Publico Text"},
+		{"FontStyle", resolved.FontStyle(), "Regular"},
+		{"Leading", resolved.Leading(), "11.4"},
+		{"FillColor", resolved.FillColor(), "Color/Black"},
+		{"Justification", resolved.Justification(), "LeftJustified"},
+		{"FirstLineIndent", resolved.Attr("FirstLineIndent"), "8"},
+		{"Hyphenation", resolved.Attr("Hyphenation"), "false"},
+		{"Name", resolved.Attr("Name"), ""},
+		{"NextStyle", resolved.Attr("NextStyle"), ""},
+		{"BasedOn", resolved.Property("BasedOn"), ""},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+	if got := resolved.PointSize(); got != 9.5 {
+		t.Errorf("PointSize() = %v, want 9.5", got)
+	}
+
+	// A missing parent ends the chain
+	orphan, err := styles.ResolveParagraphStyle("ParagraphStyle/Orphan")
+	if err != nil {
+		t.Fatalf("ResolveParagraphStyle(Orphan) failed: %v", err)
+	}
+	if len(orphan.Chain) != 1 || orphan.PointSize() != 20 || orphan.AppliedFont() != "" {
+		t.Errorf("resolved orphan = %+v", orphan)
+	}
+
+	if _, err := styles.ResolveParagraphStyle("ParagraphStyle/Missing"); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("ResolveParagraphStyle(Missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+// TestResolveCharacterStyle tests computing a character style in a group
+// and BasedOn cycles.
+func TestResolveCharacterStyle(t *testing.T) {
+	styles, err := ParseStylesFile([]byte(resolveStylesXML))
+	if err != nil {
+		t.Fatalf("ParseStylesFile failed: %v", err)
+	}
+
+	resolved, err := styles.ResolveCharacterStyle("CharacterStyle/Web%3aLink")
+	if err != nil {
+		t.Fatalf("ResolveCharacterStyle failed: %v", err)
+	}
+	if len(resolved.Chain) != 3 {
+		t.Errorf("Chain = %v, want 3 styles", resolved.Chain)
+	}
+	if resolved.FontStyle() != "Italic" || resolved.FillColor() != "Color/Blue" || resolved.Attr("Underline") != "true" {
+		t.Errorf("resolved attributes = %v", resolved.Attributes)
+	}
+	if resolved.PointSize() != 0 {
+		t.Errorf("PointSize() = %v, want 0 (not set)", resolved.PointSize())
+	}
+
+	if _, err := styles.ResolveCharacterStyle("CharacterStyle/Loop A"); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("ResolveCharacterStyle(Loop A) error = %v, want a cycle error", err)
+	}
+}
+
+// TestResolveObjectStyle tests computing an object style from its BasedOn
+// chain.
+func TestResolveObjectStyle(t *testing.T) {
+	styles, err := ParseStylesFile([]byte(resolveStylesXML))
+	if err != nil {
+		t.Fatalf("ParseStylesFile failed: %v", err)
+	}
+
+	resolved, err := styles.ResolveObjectStyle("ObjectStyle/Boxed")
+	if err != nil {
+		t.Fatalf("ResolveObjectStyle failed: %v", err)
+	}
+	if len(resolved.Chain) != 2 {
+		t.Errorf("Chain = %v, want 2 styles", resolved.Chain)
+	}
+	if resolved.Attr("EnableFill") != "true" || resolved.Attr("FillColor") != "Color/Paper" ||
+		resolved.Attr("StrokeWeight") != "1" || resolved.Attr("EnableTextWrapAndOthers") != "false" {
+		t.Errorf("resolved attributes = %v", resolved.Attributes)
+	}
+
+	if _, err := styles.ResolveObjectStyle("ObjectStyle/Missing"); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("ResolveObjectStyle(Missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+// TestStyleOtherAttrsRoundtrip tests that style attributes without a field
+// survive writing.
+func TestStyleOtherAttrsRoundtrip(t *testing.T) {
+	styles, err := ParseStylesFile([]byte(resolveStylesXML))
+	if err != nil {
+		t.Fatalf("ParseStylesFile failed: %v", err)
+	}
+	data, err := MarshalStylesFile(styles)
+	if err != nil {
+		t.Fatalf("MarshalStylesFile failed: %v", err)
+	}
+	if !strings.Contains(string(data), `Hyphenation="false"`) {
+		t.Error("Hyphenation attribute was dropped")
+	}
+}
@@ -265,3 +265,154 @@ func TestStylesFileRoundtrip(t *testing.T) {
 		t.Logf("Parsed %d character styles", count1)
 	}
 }
+
+// TestTableAndCellStylesRoundtrip tests that table and cell style settings,
+// including ones without typed fields, survive a roundtrip and that styles
+// in nested groups are found.
+func TestTableAndCellStylesRoundtrip(t *testing.T) {
+	data := []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<idPkg:Styles xmlns:idPkg="http://ns.adobe.com/AdobeInDesign/idml/1.0/packaging" DOMVersion="20.4">
+	<RootCellStyleGroup Self="u7f">
+		<CellStyle Self="CellStyle/$ID/[None]" Name="$ID/[None]" AppliedParagraphStyle="ParagraphStyle/$ID/[No paragraph style]" />
+		<CellStyleGroup Self="CellStyleGroup/Body" Name="Body">
+			<CellStyle Self="CellStyle/Body%3aShaded" Name="Body:Shaded" AppliedParagraphStyle="ParagraphStyle/Cell" FillColor="Color/Paper" FillTint="20" TopEdgeStrokeWeight="0.5" DiagonalLineInFront="true">
+				<Properties>
+					<BasedOn type="object">CellStyle/$ID/[None]</BasedOn>
+				</Properties>
+			</CellStyle>
+		</CellStyleGroup>
+	</RootCellStyleGroup>
+	<RootTableStyleGroup Self="u81">
+		<TableStyle Self="TableStyle/$ID/[Basic Table]" Name="$ID/[Basic Table]" />
+		<TableStyleGroup Self="TableStyleGroup/Data" Name="Data">
+			<TableStyle Self="TableStyle/Data%3aStriped" Name="Data:Striped" TopBorderStrokeType="StrokeStyle/$ID/Solid" TopBorderStrokeWeight="1" StartRowFillCount="1" StartRowFillColor="Color/Paper" HeaderRegionCellStyle="CellStyle/Body%3aShaded" BodyRegionCellStyle="n" FooterRegionCellStyle="CellStyle/$ID/[None]" HeaderRegionSameAsBodyRegion="false" />
+		</TableStyleGroup>
+	</RootTableStyleGroup>
+</idPkg:Styles>`)
+
+	styles, err := ParseStylesFile(data)
+	if err != nil {
+		t.Fatalf("ParseStylesFile() error = %v", err)
+	}
+	marshaled, err := MarshalStylesFile(styles)
+	if err != nil {
+		t.Fatalf("MarshalStylesFile() error = %v", err)
+	}
+	styles, err = ParseStylesFile(marshaled)
+	if err != nil {
+		t.Fatalf("ParseStylesFile() after roundtrip error = %v", err)
+	}
+
+	cell := styles.FindCellStyle("CellStyle/Body%3aShaded")
+	if cell == nil {
+		t.Fatal("FindCellStyle() did not find a style in a nested group")
+	}
+	if cell.FillColor != "Color/Paper" || cell.FillTint != "20" || cell.TopEdgeStrokeWeight != "0.5" || cell.AppliedParagraphStyle != "ParagraphStyle/Cell" {
+		t.Errorf("cell style = %+v", cell)
+	}
+	if got := cell.Properties.GetBasedOn(); got != "CellStyle/$ID/[None]" {
+		t.Errorf("cell style BasedOn = %q", got)
+	}
+	if len(cell.OtherAttrs) != 1 || cell.OtherAttrs[0].Name.Local != "DiagonalLineInFront" {
+		t.Errorf("cell style OtherAttrs = %v", cell.OtherAttrs)
+	}
+
+	table := styles.FindTableStyle("TableStyle/Data%3aStriped")
+	if table == nil {
+		t.Fatal("FindTableStyle() did not find a style in a nested group")
+	}
+	if table.TopBorderStrokeType != "StrokeStyle/$ID/Solid" || table.StartRowFillCount != "1" || table.StartRowFillColor != "Color/Paper" {
+		t.Errorf("table style = %+v", table)
+	}
+	if len(table.OtherAttrs) != 1 || table.OtherAttrs[0].Value != "false" {
+		t.Errorf("table style OtherAttrs = %v", table.OtherAttrs)
+	}
+	want := []string{"CellStyle/Body%3aShaded", "CellStyle/$ID/[None]"}
+	if got := table.RegionCellStyles(); len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("RegionCellStyles() = %v, want %v", got, want)
+	}
+
+	if styles.FindTableStyle("TableStyle/Missing") != nil || styles.FindCellStyle("CellStyle/Missing") != nil {
+		t.Error("Find of a missing style returned a style")
+	}
+}
+
+// TestObjectStyleRoundtrip tests that object style settings survive writing
+// as typed fields, in the order InDesign writes them.
+func TestObjectStyleRoundtrip(t *testing.T) {
+	data := []byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<idPkg:Styles xmlns:idPkg="http://ns.adobe.com/AdobeInDesign/idml/1.0/packaging" DOMVersion="20.4">
+	<RootObjectStyleGroup Self="u8a">
+		<ObjectStyleGroup Self="ObjectStyleGroup/Frames" Name="Frames">
+			<ObjectStyle Self="ObjectStyle/Frames%3aPhoto" Name="Frames:Photo" EnableFill="true" EnableStroke="true" EnableStrokeAndCornerOptions="true" EnableTextWrapAndOthers="true" EnableAnchoredObjectOptions="true" EnableFrameFittingOptions="true" FillColor="Color/Paper" FillTint="50" StrokeWeight="0.5" StrokeType="StrokeStyle/$ID/Dashed" StrokeColor="Color/Black" CornerOption="RoundedCorner" CornerRadius="6" TopLeftCornerOption="RoundedCorner" TopLeftCornerRadius="6" CustomSetting="kept">
+				<Properties>
+					<BasedOn type="string">ObjectStyle/$ID/[Normal Graphics Frame]</BasedOn>
+				</Properties>
+				<TransformAttributeOption TransformAttrLeftReference="PageEdgeReference" />
+				<AnchoredObjectSetting AnchoredPosition="Anchored" AnchorPoint="TopLeftAnchor" AnchorXoffset="4" AnchorSpaceAbove="0" />
+				<TextWrapPreference Inverse="false" TextWrapSide="BothSides" TextWrapMode="BoundingBoxTextWrap">
+					<Properties>
+						<TextWrapOffset Top="4" Left="0" Bottom="0" Right="0" />
+					</Properties>
+					<ContourOption ContourType="SameAsClipping" />
+				</TextWrapPreference>
+				<StoryPreference OpticalMarginAlignment="false" />
+				<FrameFittingOption AutoFit="true" FittingOnEmptyFrame="FillProportionally" FittingAlignment="CenterAnchor" />
+			</ObjectStyle>
+		</ObjectStyleGroup>
+	</RootObjectStyleGroup>
+</idPkg:Styles>`)
+
+	styles, err := ParseStylesFile(data)
+	if err != nil {
+		t.Fatalf("ParseStylesFile() error = %v", err)
+	}
+	marshaled, err := MarshalStylesFile(styles)
+	if err != nil {
+		t.Fatalf("MarshalStylesFile() error = %v", err)
+	}
+	styles, err = ParseStylesFile(marshaled)
+	if err != nil {
+		t.Fatalf("ParseStylesFile() after roundtrip error = %v", err)
+	}
+
+	style := styles.FindObjectStyle("ObjectStyle/Frames%3aPhoto")
+	if style == nil {
+		t.Fatal("FindObjectStyle() did not find a style in a nested group")
+	}
+	if style.EnableFill != "true" || style.EnableFrameFittingOptions != "true" || style.EnableParagraphStyle != "" {
+		t.Errorf("enable flags = %+v", style)
+	}
+	if style.FillColor != "Color/Paper" || style.FillTint != "50" || style.StrokeWeight != "0.5" ||
+		style.StrokeType != "StrokeStyle/$ID/Dashed" || style.StrokeColor != "Color/Black" {
+		t.Errorf("fill and stroke = %+v", style)
+	}
+	if style.CornerOption != "RoundedCorner" || style.CornerRadius != "6" || style.TopLeftCornerRadius != "6" {
+		t.Errorf("corners = %+v", style)
+	}
+	if len(style.OtherAttrs) != 1 || style.OtherAttrs[0].Name.Local != "CustomSetting" {
+		t.Errorf("OtherAttrs = %v", style.OtherAttrs)
+	}
+	if a := style.AnchoredObjectSetting; a == nil || a.AnchoredPosition != "Anchored" || a.AnchorXoffset != "4" {
+		t.Errorf("AnchoredObjectSetting = %+v", a)
+	}
+	if w := style.TextWrapPreference; w == nil || w.TextWrapMode != "BoundingBoxTextWrap" || w.Properties == nil || len(w.OtherElements) != 1 {
+		t.Errorf("TextWrapPreference = %+v", w)
+	}
+	if f := style.FrameFittingOption; f == nil || f.AutoFit != "true" || f.FittingOnEmptyFrame != "FillProportionally" {
+		t.Errorf("FrameFittingOption = %+v", f)
+	}
+	if style.StoryPreference == nil || len(style.OtherElements) != 0 {
+		t.Errorf("StoryPreference = %+v, OtherElements = %v", style.StoryPreference, style.OtherElements)
+	}
+
+	order := []string{"<Properties>", "<TransformAttributeOption", "<AnchoredObjectSetting", "<TextWrapPreference", "<StoryPreference", "<FrameFittingOption"}
+	last := -1
+	for _, name := range order {
+		i := bytes.Index(marshaled, []byte(name))
+		if i <= last {
+			t.Errorf("%s is out of order in %s", name, marshaled)
+		}
+		last = i
+	}
+}
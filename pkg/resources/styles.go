@@ -71,6 +71,9 @@ type CharacterStyle struct {
 	Underline   string `xml:"Underline,attr,omitempty"`   // "true" or "false"
 	StrikeThru  string `xml:"StrikeThru,attr,omitempty"`  // "true" or "false"
 
+	// Additional formatting attributes (catch-all)
+	OtherAttrs []xml.Attr `xml:",any,attr"`
+
 	// Properties contain additional style settings
 	Properties *common.Properties `xml:"Properties,omitempty"`
 
@@ -144,40 +147,94 @@ type ParagraphStyle struct {
 	MinimumGlyphScaling string `xml:"MinimumGlyphScaling,attr,omitempty"` // Percentage (97 = 97%)
 	MaximumGlyphScaling string `xml:"MaximumGlyphScaling,attr,omitempty"` // Percentage (103 = 103%)
 
+	// Additional formatting attributes (catch-all for the 100+ attributes)
+	OtherAttrs []xml.Attr `xml:",any,attr"`
+
 	// Properties contain additional style settings (AppliedFont, Leading, TabList, etc.)
 	Properties *common.Properties `xml:"Properties,omitempty"`
 
-	// Catch-all for the many other attributes (100+ attributes total)
+	// Catch-all for other child elements
 	OtherElements []common.RawXMLElement `xml:",any"`
 }
 
 // CellStyleGroup represents a group of table cell styles.
+// Supports nested groups for hierarchical organization.
+// XMLName will be "RootCellStyleGroup" for root or "CellStyleGroup" for nested.
 type CellStyleGroup struct {
-	XMLName       xml.Name               `xml:"RootCellStyleGroup"`
+	XMLName       xml.Name               // Will be set during unmarshal
 	Self          string                 `xml:"Self,attr"`
+	Name          string                 `xml:"Name,attr,omitempty"`
 	CellStyles    []CellStyle            `xml:"CellStyle,omitempty"`
+	NestedGroups  []CellStyleGroup       `xml:"CellStyleGroup,omitempty"` // Nested groups
 	OtherElements []common.RawXMLElement `xml:",any"`
 }
 
 // CellStyle represents a table cell style definition.
+// Cell styles set the insets, fill, edge strokes and text alignment of
+// table cells, and the paragraph style of their text.
 type CellStyle struct {
-	Self                  string                 `xml:"Self,attr"`
-	Name                  string                 `xml:"Name,attr"`
-	AppliedParagraphStyle string                 `xml:"AppliedParagraphStyle,attr,omitempty"` // Reference to paragraph style
-	Properties            *common.Properties     `xml:"Properties,omitempty"`
-	OtherElements         []common.RawXMLElement `xml:",any"`
+	Self                     string `xml:"Self,attr"`
+	Name                     string `xml:"Name,attr"`
+	AppliedParagraphStyle    string `xml:"AppliedParagraphStyle,attr,omitempty"` // Reference to paragraph style
+	ExtendedKeyboardShortcut string `xml:"ExtendedKeyboardShortcut,attr,omitempty"`
+	KeyboardShortcut         string `xml:"KeyboardShortcut,attr,omitempty"`
+
+	// Text insets
+	TextTopInset    string `xml:"TextTopInset,attr,omitempty"`
+	TextLeftInset   string `xml:"TextLeftInset,attr,omitempty"`
+	TextBottomInset string `xml:"TextBottomInset,attr,omitempty"`
+	TextRightInset  string `xml:"TextRightInset,attr,omitempty"`
+
+	// Fill
+	FillColor string `xml:"FillColor,attr,omitempty"` // Color or swatch reference
+	FillTint  string `xml:"FillTint,attr,omitempty"`  // Percentage, -1 for the swatch tint
+
+	// Edge strokes (Top, Left, Bottom, Right)
+	TopEdgeStrokeWeight    string `xml:"TopEdgeStrokeWeight,attr,omitempty"`
+	TopEdgeStrokeType      string `xml:"TopEdgeStrokeType,attr,omitempty"` // Stroke style reference
+	TopEdgeStrokeColor     string `xml:"TopEdgeStrokeColor,attr,omitempty"`
+	TopEdgeStrokeTint      string `xml:"TopEdgeStrokeTint,attr,omitempty"`
+	LeftEdgeStrokeWeight   string `xml:"LeftEdgeStrokeWeight,attr,omitempty"`
+	LeftEdgeStrokeType     string `xml:"LeftEdgeStrokeType,attr,omitempty"`
+	LeftEdgeStrokeColor    string `xml:"LeftEdgeStrokeColor,attr,omitempty"`
+	LeftEdgeStrokeTint     string `xml:"LeftEdgeStrokeTint,attr,omitempty"`
+	BottomEdgeStrokeWeight string `xml:"BottomEdgeStrokeWeight,attr,omitempty"`
+	BottomEdgeStrokeType   string `xml:"BottomEdgeStrokeType,attr,omitempty"`
+	BottomEdgeStrokeColor  string `xml:"BottomEdgeStrokeColor,attr,omitempty"`
+	BottomEdgeStrokeTint   string `xml:"BottomEdgeStrokeTint,attr,omitempty"`
+	RightEdgeStrokeWeight  string `xml:"RightEdgeStrokeWeight,attr,omitempty"`
+	RightEdgeStrokeType    string `xml:"RightEdgeStrokeType,attr,omitempty"`
+	RightEdgeStrokeColor   string `xml:"RightEdgeStrokeColor,attr,omitempty"`
+	RightEdgeStrokeTint    string `xml:"RightEdgeStrokeTint,attr,omitempty"`
+
+	// Text layout
+	VerticalJustification string `xml:"VerticalJustification,attr,omitempty"` // "TopAlign", "CenterAlign", etc.
+	RotationAngle         string `xml:"RotationAngle,attr,omitempty"`
+	ClipContentToTextCell string `xml:"ClipContentToTextCell,attr,omitempty"` // "true" or "false"
+
+	// Additional formatting attributes (catch-all)
+	OtherAttrs []xml.Attr `xml:",any,attr"`
+
+	// Properties contain BasedOn and other style settings
+	Properties    *common.Properties     `xml:"Properties,omitempty"`
+	OtherElements []common.RawXMLElement `xml:",any"`
 }
 
 // TableStyleGroup represents a group of table styles.
+// Supports nested groups for hierarchical organization.
+// XMLName will be "RootTableStyleGroup" for root or "TableStyleGroup" for nested.
 type TableStyleGroup struct {
-	XMLName       xml.Name               `xml:"RootTableStyleGroup"`
+	XMLName       xml.Name               // Will be set during unmarshal
 	Self          string                 `xml:"Self,attr"`
+	Name          string                 `xml:"Name,attr,omitempty"`
 	TableStyles   []TableStyle           `xml:"TableStyle,omitempty"`
+	NestedGroups  []TableStyleGroup      `xml:"TableStyleGroup,omitempty"` // Nested groups
 	OtherElements []common.RawXMLElement `xml:",any"`
 }
 
 // TableStyle represents a table style definition.
-// Contains comprehensive table border, fill, and stroke settings.
+// Contains the table border, alternating stroke and fill patterns and the
+// cell styles applied to the header, footer, body and outer column regions.
 type TableStyle struct {
 	Self                     string `xml:"Self,attr"`
 	Name                     string `xml:"Name,attr"`
@@ -187,18 +244,91 @@ type TableStyle struct {
 	// Table spacing
 	SpaceBefore string `xml:"SpaceBefore,attr,omitempty"`
 	SpaceAfter  string `xml:"SpaceAfter,attr,omitempty"`
+	StrokeOrder string `xml:"StrokeOrder,attr,omitempty"` // "BestJoins", "RowOnTop", etc.
 
-	// Border properties (Top, Left, Bottom, Right)
-	TopBorderStrokeWeight  string `xml:"TopBorderStrokeWeight,attr,omitempty"`
-	TopBorderStrokeColor   string `xml:"TopBorderStrokeColor,attr,omitempty"`
-	LeftBorderStrokeWeight string `xml:"LeftBorderStrokeWeight,attr,omitempty"`
-	LeftBorderStrokeColor  string `xml:"LeftBorderStrokeColor,attr,omitempty"`
-	// ... many more border attributes
+	// Cell insets
+	TextTopInset    string `xml:"TextTopInset,attr,omitempty"`
+	TextLeftInset   string `xml:"TextLeftInset,attr,omitempty"`
+	TextBottomInset string `xml:"TextBottomInset,attr,omitempty"`
+	TextRightInset  string `xml:"TextRightInset,attr,omitempty"`
 
+	// Border properties (Top, Left, Bottom, Right)
+	TopBorderStrokeWeight    string `xml:"TopBorderStrokeWeight,attr,omitempty"`
+	TopBorderStrokeType      string `xml:"TopBorderStrokeType,attr,omitempty"` // Stroke style reference
+	TopBorderStrokeColor     string `xml:"TopBorderStrokeColor,attr,omitempty"`
+	TopBorderStrokeTint      string `xml:"TopBorderStrokeTint,attr,omitempty"`
+	LeftBorderStrokeWeight   string `xml:"LeftBorderStrokeWeight,attr,omitempty"`
+	LeftBorderStrokeType     string `xml:"LeftBorderStrokeType,attr,omitempty"`
+	LeftBorderStrokeColor    string `xml:"LeftBorderStrokeColor,attr,omitempty"`
+	LeftBorderStrokeTint     string `xml:"LeftBorderStrokeTint,attr,omitempty"`
+	BottomBorderStrokeWeight string `xml:"BottomBorderStrokeWeight,attr,omitempty"`
+	BottomBorderStrokeType   string `xml:"BottomBorderStrokeType,attr,omitempty"`
+	BottomBorderStrokeColor  string `xml:"BottomBorderStrokeColor,attr,omitempty"`
+	BottomBorderStrokeTint   string `xml:"BottomBorderStrokeTint,attr,omitempty"`
+	RightBorderStrokeWeight  string `xml:"RightBorderStrokeWeight,attr,omitempty"`
+	RightBorderStrokeType    string `xml:"RightBorderStrokeType,attr,omitempty"`
+	RightBorderStrokeColor   string `xml:"RightBorderStrokeColor,attr,omitempty"`
+	RightBorderStrokeTint    string `xml:"RightBorderStrokeTint,attr,omitempty"`
+
+	// Alternating row strokes
+	StartRowStrokeCount  string `xml:"StartRowStrokeCount,attr,omitempty"`
+	StartRowStrokeWeight string `xml:"StartRowStrokeWeight,attr,omitempty"`
+	StartRowStrokeType   string `xml:"StartRowStrokeType,attr,omitempty"`
+	StartRowStrokeColor  string `xml:"StartRowStrokeColor,attr,omitempty"`
+	StartRowStrokeTint   string `xml:"StartRowStrokeTint,attr,omitempty"`
+	EndRowStrokeCount    string `xml:"EndRowStrokeCount,attr,omitempty"`
+	EndRowStrokeWeight   string `xml:"EndRowStrokeWeight,attr,omitempty"`
+	EndRowStrokeType     string `xml:"EndRowStrokeType,attr,omitempty"`
+	EndRowStrokeColor    string `xml:"EndRowStrokeColor,attr,omitempty"`
+	EndRowStrokeTint     string `xml:"EndRowStrokeTint,attr,omitempty"`
+
+	// Alternating row and column fills
+	StartRowFillCount    string `xml:"StartRowFillCount,attr,omitempty"`
+	StartRowFillColor    string `xml:"StartRowFillColor,attr,omitempty"`
+	StartRowFillTint     string `xml:"StartRowFillTint,attr,omitempty"`
+	EndRowFillCount      string `xml:"EndRowFillCount,attr,omitempty"`
+	EndRowFillColor      string `xml:"EndRowFillColor,attr,omitempty"`
+	EndRowFillTint       string `xml:"EndRowFillTint,attr,omitempty"`
+	StartColumnFillCount string `xml:"StartColumnFillCount,attr,omitempty"`
+	StartColumnFillColor string `xml:"StartColumnFillColor,attr,omitempty"`
+	StartColumnFillTint  string `xml:"StartColumnFillTint,attr,omitempty"`
+	EndColumnFillCount   string `xml:"EndColumnFillCount,attr,omitempty"`
+	EndColumnFillColor   string `xml:"EndColumnFillColor,attr,omitempty"`
+	EndColumnFillTint    string `xml:"EndColumnFillTint,attr,omitempty"`
+
+	// Region cell styles. "n" means no cell style is applied to the region.
+	HeaderRegionCellStyle      string `xml:"HeaderRegionCellStyle,attr,omitempty"`
+	FooterRegionCellStyle      string `xml:"FooterRegionCellStyle,attr,omitempty"`
+	BodyRegionCellStyle        string `xml:"BodyRegionCellStyle,attr,omitempty"`
+	LeftColumnRegionCellStyle  string `xml:"LeftColumnRegionCellStyle,attr,omitempty"`
+	RightColumnRegionCellStyle string `xml:"RightColumnRegionCellStyle,attr,omitempty"`
+
+	// Additional formatting attributes (catch-all)
+	OtherAttrs []xml.Attr `xml:",any,attr"`
+
+	// Properties contain BasedOn and other style settings
 	Properties    *common.Properties     `xml:"Properties,omitempty"`
 	OtherElements []common.RawXMLElement `xml:",any"`
 }
 
+// RegionCellStyles returns the cell styles the table style applies to its
+// regions, skipping regions without a cell style.
+func (ts *TableStyle) RegionCellStyles() []string {
+	var styles []string
+	for _, id := range []string{
+		ts.HeaderRegionCellStyle,
+		ts.FooterRegionCellStyle,
+		ts.BodyRegionCellStyle,
+		ts.LeftColumnRegionCellStyle,
+		ts.RightColumnRegionCellStyle,
+	} {
+		if id != "" && id != "n" {
+			styles = append(styles, id)
+		}
+	}
+	return styles
+}
+
 // ObjectStyleGroup represents a group of object styles.
 // Supports nested groups for hierarchical organization.
 // XMLName will be "RootObjectStyleGroup" for root or "ObjectStyleGroup" for nested.
@@ -213,41 +343,106 @@ type ObjectStyleGroup struct {
 
 // ObjectStyle represents an object style definition.
 // Applies to frames, text boxes, graphics, and other page objects.
+//
+// The Enable* flags ("true" or "false") select the categories of settings
+// the style applies; settings of disabled categories are left to the page
+// item.
 type ObjectStyle struct {
 	Self                     string `xml:"Self,attr"`
 	Name                     string `xml:"Name,attr"`
 	ExtendedKeyboardShortcut string `xml:"ExtendedKeyboardShortcut,attr,omitempty"`
 	KeyboardShortcut         string `xml:"KeyboardShortcut,attr,omitempty"`
 	AppliedParagraphStyle    string `xml:"AppliedParagraphStyle,attr,omitempty"`
+	ApplyNextParagraphStyle  string `xml:"ApplyNextParagraphStyle,attr,omitempty"`
 	EmitCss                  string `xml:"EmitCss,attr,omitempty"`
 	IncludeClass             string `xml:"IncludeClass,attr,omitempty"`
 
-	// Stroke and fill properties
-	FillColor    string `xml:"FillColor,attr,omitempty"`
-	FillTint     string `xml:"FillTint,attr,omitempty"`
-	StrokeColor  string `xml:"StrokeColor,attr,omitempty"`
-	StrokeTint   string `xml:"StrokeTint,attr,omitempty"`
-	StrokeWeight string `xml:"StrokeWeight,attr,omitempty"`
+	// Categories applied by the style
+	EnableFill                         string `xml:"EnableFill,attr,omitempty"`
+	EnableStroke                       string `xml:"EnableStroke,attr,omitempty"`
+	EnableStrokeAndCornerOptions       string `xml:"EnableStrokeAndCornerOptions,attr,omitempty"`
+	EnableParagraphStyle               string `xml:"EnableParagraphStyle,attr,omitempty"`
+	EnableTextFrameGeneralOptions      string `xml:"EnableTextFrameGeneralOptions,attr,omitempty"`
+	EnableTextFrameBaselineOptions     string `xml:"EnableTextFrameBaselineOptions,attr,omitempty"`
+	EnableTextFrameAutoSizingOptions   string `xml:"EnableTextFrameAutoSizingOptions,attr,omitempty"`
+	EnableTextFrameColumnRuleOptions   string `xml:"EnableTextFrameColumnRuleOptions,attr,omitempty"`
+	EnableTextFrameFootnoteOptions     string `xml:"EnableTextFrameFootnoteOptions,attr,omitempty"`
+	EnableStoryOptions                 string `xml:"EnableStoryOptions,attr,omitempty"`
+	EnableTextWrapAndOthers            string `xml:"EnableTextWrapAndOthers,attr,omitempty"`
+	EnableAnchoredObjectOptions        string `xml:"EnableAnchoredObjectOptions,attr,omitempty"`
+	EnableFrameFittingOptions          string `xml:"EnableFrameFittingOptions,attr,omitempty"`
+	EnableTransformAttributes          string `xml:"EnableTransformAttributes,attr,omitempty"`
+	EnableExportTagging                string `xml:"EnableExportTagging,attr,omitempty"`
+	EnableObjectExportAltTextOptions   string `xml:"EnableObjectExportAltTextOptions,attr,omitempty"`
+	EnableObjectExportTaggedPdfOptions string `xml:"EnableObjectExportTaggedPdfOptions,attr,omitempty"`
+	EnableObjectExportEpubOptions      string `xml:"EnableObjectExportEpubOptions,attr,omitempty"`
+
+	// Fill properties
+	FillColor         string `xml:"FillColor,attr,omitempty"` // Color or swatch reference
+	FillTint          string `xml:"FillTint,attr,omitempty"`  // -1 for the swatch's own tint
+	GradientFillAngle string `xml:"GradientFillAngle,attr,omitempty"`
+	OverprintFill     string `xml:"OverprintFill,attr,omitempty"`
+
+	// Stroke properties
+	StrokeWeight        string `xml:"StrokeWeight,attr,omitempty"`
+	StrokeType          string `xml:"StrokeType,attr,omitempty"` // Stroke style reference
+	StrokeColor         string `xml:"StrokeColor,attr,omitempty"`
+	StrokeTint          string `xml:"StrokeTint,attr,omitempty"`
+	StrokeAlignment     string `xml:"StrokeAlignment,attr,omitempty"` // "CenterAlignment", "InsideAlignment", "OutsideAlignment"
+	GapColor            string `xml:"GapColor,attr,omitempty"`
+	GapTint             string `xml:"GapTint,attr,omitempty"`
+	MiterLimit          string `xml:"MiterLimit,attr,omitempty"`
+	EndCap              string `xml:"EndCap,attr,omitempty"`  // "ButtEndCap", "RoundEndCap", "ProjectingEndCap"
+	EndJoin             string `xml:"EndJoin,attr,omitempty"` // "MiterEndJoin", "RoundEndJoin", "BevelEndJoin"
+	LeftLineEnd         string `xml:"LeftLineEnd,attr,omitempty"`
+	RightLineEnd        string `xml:"RightLineEnd,attr,omitempty"`
+	LeftArrowHeadScale  string `xml:"LeftArrowHeadScale,attr,omitempty"`
+	RightArrowHeadScale string `xml:"RightArrowHeadScale,attr,omitempty"`
+	ArrowHeadAlignment  string `xml:"ArrowHeadAlignment,attr,omitempty"`
+	GradientStrokeAngle string `xml:"GradientStrokeAngle,attr,omitempty"`
+	OverprintStroke     string `xml:"OverprintStroke,attr,omitempty"`
+	OverprintGap        string `xml:"OverprintGap,attr,omitempty"`
 
 	// Corner properties
-	TopLeftCornerOption string `xml:"TopLeftCornerOption,attr,omitempty"`
-	TopLeftCornerRadius string `xml:"TopLeftCornerRadius,attr,omitempty"`
-	CornerRadius        string `xml:"CornerRadius,attr,omitempty"`
+	CornerOption            string `xml:"CornerOption,attr,omitempty"` // "None", "RoundedCorner", "BevelCorner", etc.
+	CornerRadius            string `xml:"CornerRadius,attr,omitempty"`
+	TopLeftCornerOption     string `xml:"TopLeftCornerOption,attr,omitempty"`
+	TopLeftCornerRadius     string `xml:"TopLeftCornerRadius,attr,omitempty"`
+	TopRightCornerOption    string `xml:"TopRightCornerOption,attr,omitempty"`
+	TopRightCornerRadius    string `xml:"TopRightCornerRadius,attr,omitempty"`
+	BottomLeftCornerOption  string `xml:"BottomLeftCornerOption,attr,omitempty"`
+	BottomLeftCornerRadius  string `xml:"BottomLeftCornerRadius,attr,omitempty"`
+	BottomRightCornerOption string `xml:"BottomRightCornerOption,attr,omitempty"`
+	BottomRightCornerRadius string `xml:"BottomRightCornerRadius,attr,omitempty"`
+
+	// Other settings
+	Nonprinting      string `xml:"Nonprinting,attr,omitempty"`
+	AppliedNamedGrid string `xml:"AppliedNamedGrid,attr,omitempty"`
+
+	// Additional attributes (catch-all)
+	OtherAttrs []xml.Attr `xml:",any,attr"`
 
 	// Child elements
+	Properties               *common.Properties        `xml:"Properties,omitempty"`
 	TransformAttributeOption *TransformAttributeOption `xml:"TransformAttributeOption,omitempty"`
 	ObjectExportOption       *ObjectExportOption       `xml:"ObjectExportOption,omitempty"`
 	TextFramePreference      *TextFramePreference      `xml:"TextFramePreference,omitempty"`
+	BaselineFrameGridOption  *common.RawXMLElement     `xml:"BaselineFrameGridOption,omitempty"`
+	AnchoredObjectSetting    *AnchoredObjectSetting    `xml:"AnchoredObjectSetting,omitempty"`
+	TextWrapPreference       *TextWrapPreference       `xml:"TextWrapPreference,omitempty"`
+	StoryPreference          *common.RawXMLElement     `xml:"StoryPreference,omitempty"`
+	FrameFittingOption       *FrameFittingOption       `xml:"FrameFittingOption,omitempty"`
 
-	Properties    *common.Properties     `xml:"Properties,omitempty"`
+	// Effects category settings and other elements
 	OtherElements []common.RawXMLElement `xml:",any"`
 }
 
 // TransformAttributeOption defines transform reference points for objects.
 type TransformAttributeOption struct {
-	TransformAttrLeftReference  string `xml:"TransformAttrLeftReference,attr,omitempty"`
-	TransformAttrTopReference   string `xml:"TransformAttrTopReference,attr,omitempty"`
-	TransformAttrRefAnchorPoint string `xml:"TransformAttrRefAnchorPoint,attr,omitempty"`
+	TransformAttrLeftReference  string     `xml:"TransformAttrLeftReference,attr,omitempty"`
+	TransformAttrTopReference   string     `xml:"TransformAttrTopReference,attr,omitempty"`
+	TransformAttrRefAnchorPoint string     `xml:"TransformAttrRefAnchorPoint,attr,omitempty"`
+	OtherAttrs                  []xml.Attr `xml:",any,attr"`
 }
 
 // ObjectExportOption defines object export settings.
@@ -259,6 +454,7 @@ type ObjectExportOption struct {
 	ApplyTagType          string                 `xml:"ApplyTagType,attr,omitempty"`
 	ImageConversionType   string                 `xml:"ImageConversionType,attr,omitempty"`
 	ImageExportResolution string                 `xml:"ImageExportResolution,attr,omitempty"`
+	OtherAttrs            []xml.Attr             `xml:",any,attr"`
 	Properties            *common.Properties     `xml:"Properties,omitempty"`
 	OtherElements         []common.RawXMLElement `xml:",any"`
 }
@@ -267,12 +463,59 @@ type ObjectExportOption struct {
 type TextFramePreference struct {
 	TextColumnCount       string                 `xml:"TextColumnCount,attr,omitempty"`
 	TextColumnGutter      string                 `xml:"TextColumnGutter,attr,omitempty"`
+	TextColumnFixedWidth  string                 `xml:"TextColumnFixedWidth,attr,omitempty"`
+	UseFixedColumnWidth   string                 `xml:"UseFixedColumnWidth,attr,omitempty"`
 	FirstBaselineOffset   string                 `xml:"FirstBaselineOffset,attr,omitempty"`
 	VerticalJustification string                 `xml:"VerticalJustification,attr,omitempty"`
+	VerticalThreshold     string                 `xml:"VerticalThreshold,attr,omitempty"`
+	IgnoreWrap            string                 `xml:"IgnoreWrap,attr,omitempty"`
 	AutoSizingType        string                 `xml:"AutoSizingType,attr,omitempty"`
+	OtherAttrs            []xml.Attr             `xml:",any,attr"`
 	OtherElements         []common.RawXMLElement `xml:",any"`
 }
 
+// AnchoredObjectSetting defines how page items with the style are
+// positioned when they are anchored in text.
+type AnchoredObjectSetting struct {
+	AnchoredPosition         string     `xml:"AnchoredPosition,attr,omitempty"` // "InlinePosition", "AboveLine", "Anchored"
+	SpineRelative            string     `xml:"SpineRelative,attr,omitempty"`
+	LockPosition             string     `xml:"LockPosition,attr,omitempty"`
+	PinPosition              string     `xml:"PinPosition,attr,omitempty"`
+	AnchorPoint              string     `xml:"AnchorPoint,attr,omitempty"`
+	HorizontalAlignment      string     `xml:"HorizontalAlignment,attr,omitempty"`
+	HorizontalReferencePoint string     `xml:"HorizontalReferencePoint,attr,omitempty"`
+	VerticalAlignment        string     `xml:"VerticalAlignment,attr,omitempty"`
+	VerticalReferencePoint   string     `xml:"VerticalReferencePoint,attr,omitempty"`
+	AnchorXoffset            string     `xml:"AnchorXoffset,attr,omitempty"`
+	AnchorYoffset            string     `xml:"AnchorYoffset,attr,omitempty"`
+	AnchorSpaceAbove         string     `xml:"AnchorSpaceAbove,attr,omitempty"`
+	OtherAttrs               []xml.Attr `xml:",any,attr"`
+}
+
+// TextWrapPreference defines how text wraps around page items with the
+// style. The wrap offsets are kept in Properties (TextWrapOffset).
+type TextWrapPreference struct {
+	Inverse               string                 `xml:"Inverse,attr,omitempty"`
+	ApplyToMasterPageOnly string                 `xml:"ApplyToMasterPageOnly,attr,omitempty"`
+	TextWrapSide          string                 `xml:"TextWrapSide,attr,omitempty"` // "BothSides", "LeftSide", "RightSide", etc.
+	TextWrapMode          string                 `xml:"TextWrapMode,attr,omitempty"` // "None", "BoundingBoxTextWrap", "JumpObjectTextWrap", etc.
+	OtherAttrs            []xml.Attr             `xml:",any,attr"`
+	Properties            *common.Properties     `xml:"Properties,omitempty"`
+	OtherElements         []common.RawXMLElement `xml:",any"` // ContourOption
+}
+
+// FrameFittingOption defines how content is fitted into frames with the style.
+type FrameFittingOption struct {
+	AutoFit             string     `xml:"AutoFit,attr,omitempty"`
+	LeftCrop            string     `xml:"LeftCrop,attr,omitempty"`
+	TopCrop             string     `xml:"TopCrop,attr,omitempty"`
+	RightCrop           string     `xml:"RightCrop,attr,omitempty"`
+	BottomCrop          string     `xml:"BottomCrop,attr,omitempty"`
+	FittingOnEmptyFrame string     `xml:"FittingOnEmptyFrame,attr,omitempty"` // "None", "FitContentProportionally", etc.
+	FittingAlignment    string     `xml:"FittingAlignment,attr,omitempty"`
+	OtherAttrs          []xml.Attr `xml:",any,attr"`
+}
+
 // TOCStyle represents a table of contents style definition.
 type TOCStyle struct {
 	Self                 string                 `xml:"Self,attr"`
@@ -317,3 +560,131 @@ func (sf *StylesFile) findParagraphStyleInGroup(group *ParagraphStyleGroup, styl
 
 	return nil
 }
+
+// FindCharacterStyle finds a character style by its Self ID.
+// It searches through the character style group hierarchy, including nested groups.
+func (sf *StylesFile) FindCharacterStyle(styleID string) *CharacterStyle {
+	if sf.RootCharacterStyleGroup == nil {
+		return nil
+	}
+	return sf.findCharacterStyleInGroup(sf.RootCharacterStyleGroup, styleID)
+}
+
+// findCharacterStyleInGroup recursively searches for a character style in a group.
+func (sf *StylesFile) findCharacterStyleInGroup(group *CharacterStyleGroup, styleID string) *CharacterStyle {
+	if group == nil {
+		return nil
+	}
+
+	// Search in direct styles
+	for i := range group.CharacterStyles {
+		if group.CharacterStyles[i].Self == styleID {
+			return &group.CharacterStyles[i]
+		}
+	}
+
+	// Search in nested groups
+	for i := range group.NestedGroups {
+		if style := sf.findCharacterStyleInGroup(&group.NestedGroups[i], styleID); style != nil {
+			return style
+		}
+	}
+
+	return nil
+}
+
+// FindObjectStyle finds an object style by its Self ID.
+// It searches through the object style group hierarchy, including nested groups.
+func (sf *StylesFile) FindObjectStyle(styleID string) *ObjectStyle {
+	if sf.RootObjectStyleGroup == nil {
+		return nil
+	}
+	return sf.findObjectStyleInGroup(sf.RootObjectStyleGroup, styleID)
+}
+
+// findObjectStyleInGroup recursively searches for an object style in a group.
+func (sf *StylesFile) findObjectStyleInGroup(group *ObjectStyleGroup, styleID string) *ObjectStyle {
+	if group == nil {
+		return nil
+	}
+
+	// Search in direct styles
+	for i := range group.ObjectStyles {
+		if group.ObjectStyles[i].Self == styleID {
+			return &group.ObjectStyles[i]
+		}
+	}
+
+	// Search in nested groups
+	for i := range group.NestedGroups {
+		if style := sf.findObjectStyleInGroup(&group.NestedGroups[i], styleID); style != nil {
+			return style
+		}
+	}
+
+	return nil
+}
+
+// FindCellStyle finds a cell style by its Self ID.
+// It searches through the cell style group hierarchy, including nested groups.
+func (sf *StylesFile) FindCellStyle(styleID string) *CellStyle {
+	if sf.RootCellStyleGroup == nil {
+		return nil
+	}
+	return sf.findCellStyleInGroup(sf.RootCellStyleGroup, styleID)
+}
+
+// findCellStyleInGroup recursively searches for a cell style in a group.
+func (sf *StylesFile) findCellStyleInGroup(group *CellStyleGroup, styleID string) *CellStyle {
+	if group == nil {
+		return nil
+	}
+
+	// Search in direct styles
+	for i := range group.CellStyles {
+		if group.CellStyles[i].Self == styleID {
+			return &group.CellStyles[i]
+		}
+	}
+
+	// Search in nested groups
+	for i := range group.NestedGroups {
+		if style := sf.findCellStyleInGroup(&group.NestedGroups[i], styleID); style != nil {
+			return style
+		}
+	}
+
+	return nil
+}
+
+// FindTableStyle finds a table style by its Self ID.
+// It searches through the table style group hierarchy, including nested groups.
+func (sf *StylesFile) FindTableStyle(styleID string) *TableStyle {
+	if sf.RootTableStyleGroup == nil {
+		return nil
+	}
+	return sf.findTableStyleInGroup(sf.RootTableStyleGroup, styleID)
+}
+
+// findTableStyleInGroup recursively searches for a table style in a group.
+func (sf *StylesFile) findTableStyleInGroup(group *TableStyleGroup, styleID string) *TableStyle {
+	if group == nil {
+		return nil
+	}
+
+	// Search in direct styles
+	for i := range group.TableStyles {
+		if group.TableStyles[i].Self == styleID {
+			return &group.TableStyles[i]
+		}
+	}
+
+	// Search in nested groups
+	for i := range group.NestedGroups {
+		if style := sf.findTableStyleInGroup(&group.NestedGroups[i], styleID); style != nil {
+			return style
+		}
+	}
+
+	return nil
+}
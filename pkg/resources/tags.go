@@ -0,0 +1,65 @@
+package resources
+
+import (
+	"encoding/xml"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+)
+
+// TagsFile represents the XML/Tags.xml file containing the XML tag definitions
+// used by the document's XML structure.
+//
+// The root element is <idPkg:Tags> with the idPkg namespace.
+type TagsFile struct {
+	// XMLName is not set directly - we handle it manually in MarshalXML/UnmarshalXML
+	XMLName xml.Name `xml:"-"`
+
+	// DOMVersion is the InDesign DOM version (e.g., "20.4")
+	DOMVersion string `xml:"DOMVersion,attr"`
+
+	// Tags are the tag definitions in document order
+	Tags []XMLTag `xml:"XMLTag,omitempty"`
+
+	// Catch-all for other elements we haven't explicitly modeled
+	OtherElements []common.RawXMLElement `xml:",any"`
+}
+
+// XMLTag defines a tag that XML elements can be marked up with.
+type XMLTag struct {
+	Self string `xml:"Self,attr"` // "XMLTag/" followed by the name (e.g., "XMLTag/Title")
+	Name string `xml:"Name,attr"` // Tag name (e.g., "Title")
+
+	// Catch-all for other attributes
+	OtherAttrs []xml.Attr `xml:",any,attr"`
+
+	// Properties hold the tag color shown in InDesign's structure view
+	Properties *XMLTagProperties `xml:"Properties,omitempty"`
+}
+
+// XMLTagProperties is the Properties element of an XMLTag.
+type XMLTagProperties struct {
+	TagColor *common.RawXMLElement `xml:"TagColor,omitempty"` // An enumeration (e.g., "LightBlue") or a list of RGB values
+
+	// Catch-all for other Properties children
+	OtherElements []common.RawXMLElement `xml:",any"`
+}
+
+// Tag returns the tag with the given name, or nil if it is not defined.
+func (t *TagsFile) Tag(name string) *XMLTag {
+	for i := range t.Tags {
+		if t.Tags[i].Name == name {
+			return &t.Tags[i]
+		}
+	}
+	return nil
+}
+
+// AddTag returns the tag with the given name, defining it first if needed.
+// New tags get no color; InDesign assigns one when the document is opened.
+func (t *TagsFile) AddTag(name string) *XMLTag {
+	if tag := t.Tag(name); tag != nil {
+		return tag
+	}
+	t.Tags = append(t.Tags, XMLTag{Self: "XMLTag/" + name, Name: name})
+	return &t.Tags[len(t.Tags)-1]
+}
@@ -113,6 +113,16 @@ func (p *Polygon) Transform() (Transform, error) {
 	return parseItemTransform(p.ItemTransform)
 }
 
+// Position returns the x and y position of the group from its ItemTransform.
+func (g *Group) Position() (Position, error) {
+	return parseItemTransformPosition(g.ItemTransform)
+}
+
+// Transform returns the full transformation matrix of the group.
+func (g *Group) Transform() (Transform, error) {
+	return parseItemTransform(g.ItemTransform)
+}
+
 // parseGeometricBounds parses the GeometricBounds string format.
 // Format: "y1 x1 y2 x2" (top-left and bottom-right coordinates in points)
 func parseGeometricBounds(bounds string) (Bounds, error) {
@@ -301,3 +311,153 @@ func parseAnchorPoint(anchor string) (float64, float64) {
 
 	return x, y
 }
+
+// Rect is an axis-aligned rectangle in spread coordinates (points).
+// Unlike Bounds, it keeps the position of the rectangle on the spread.
+type Rect struct {
+	Top    float64
+	Left   float64
+	Bottom float64
+	Right  float64
+}
+
+// Center returns the center point of the rectangle.
+func (r Rect) Center() Position {
+	return Position{X: (r.Left + r.Right) / 2, Y: (r.Top + r.Bottom) / 2}
+}
+
+// Contains reports whether pt lies within the rectangle (edges included).
+func (r Rect) Contains(pt Position) bool {
+	return pt.X >= r.Left && pt.X <= r.Right && pt.Y >= r.Top && pt.Y <= r.Bottom
+}
+
+// String formats the matrix as an ItemTransform value ("a b c d x y").
+func (t Transform) String() string {
+	values := []float64{t.A, t.B, t.C, t.D, t.X, t.Y}
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Translate moves the item by dx, dy points in its parent coordinate space
+// by adjusting the translation of its ItemTransform.
+// An empty ItemTransform is treated as the identity matrix.
+func (p *PageItemBase) Translate(dx, dy float64) error {
+	t := Transform{A: 1, D: 1}
+	if p.ItemTransform != "" {
+		var err error
+		if t, err = parseItemTransform(p.ItemTransform); err != nil {
+			return err
+		}
+	}
+	t.X += dx
+	t.Y += dy
+	p.ItemTransform = t.String()
+	return nil
+}
+
+// Apply maps a point from inner coordinates to the parent coordinate space.
+func (t Transform) Apply(x, y float64) Position {
+	return Position{
+		X: t.A*x + t.C*y + t.X,
+		Y: t.B*x + t.D*y + t.Y,
+	}
+}
+
+// Bounds returns the width and height of the page from its GeometricBounds.
+func (p *Page) Bounds() (Bounds, error) {
+	return parseGeometricBounds(p.GeometricBounds)
+}
+
+// SpreadRect returns the page rectangle in spread coordinates,
+// i.e. its GeometricBounds mapped through its ItemTransform.
+func (p *Page) SpreadRect() (Rect, error) {
+	return spreadRect(p.GeometricBounds, nil, p.ItemTransform)
+}
+
+// SpreadRect returns the bounding box of the text frame in spread coordinates.
+// The frame's GeometricBounds (or PathGeometry anchors) are mapped through its
+// ItemTransform, so rotated frames yield the box around the rotated shape.
+func (tf *SpreadTextFrame) SpreadRect() (Rect, error) {
+	return spreadRect(tf.GeometricBounds, tf.Properties, tf.ItemTransform)
+}
+
+// SpreadRect returns the bounding box of the rectangle in spread coordinates.
+func (r *Rectangle) SpreadRect() (Rect, error) {
+	return spreadRect(r.GeometricBounds, r.Properties, r.ItemTransform)
+}
+
+// SpreadRect returns the bounding box of the oval in spread coordinates.
+func (o *Oval) SpreadRect() (Rect, error) {
+	return spreadRect(o.GeometricBounds, o.Properties, o.ItemTransform)
+}
+
+// SpreadRect returns the bounding box of the polygon in spread coordinates.
+func (p *Polygon) SpreadRect() (Rect, error) {
+	return spreadRect(p.GeometricBounds, p.Properties, p.ItemTransform)
+}
+
+// SpreadRect returns the bounding box of the graphic line in spread coordinates.
+func (l *GraphicLine) SpreadRect() (Rect, error) {
+	return spreadRect(l.GeometricBounds, l.Properties, l.ItemTransform)
+}
+
+// spreadRect maps the corners of an item's inner geometry through its
+// ItemTransform and returns the enclosing rectangle.
+// GeometricBounds is used when present, otherwise the PathGeometry anchors.
+// An empty ItemTransform is treated as the identity matrix.
+func spreadRect(geometricBounds string, props *common.Properties, itemTransform string) (Rect, error) {
+	points, err := innerCorners(geometricBounds, props)
+	if err != nil {
+		return Rect{}, err
+	}
+
+	t := Transform{A: 1, D: 1}
+	if itemTransform != "" {
+		if t, err = parseItemTransform(itemTransform); err != nil {
+			return Rect{}, err
+		}
+	}
+
+	first := t.Apply(points[0].X, points[0].Y)
+	r := Rect{Top: first.Y, Left: first.X, Bottom: first.Y, Right: first.X}
+	for _, pt := range points[1:] {
+		p := t.Apply(pt.X, pt.Y)
+		r.Left = min(r.Left, p.X)
+		r.Right = max(r.Right, p.X)
+		r.Top = min(r.Top, p.Y)
+		r.Bottom = max(r.Bottom, p.Y)
+	}
+	return r, nil
+}
+
+// innerCorners returns the outline points of an item in its inner coordinates.
+func innerCorners(geometricBounds string, props *common.Properties) ([]Position, error) {
+	if parts := strings.Fields(geometricBounds); len(parts) == 4 {
+		var v [4]float64
+		for i, part := range parts {
+			f, err := strconv.ParseFloat(part, 64)
+			if err != nil {
+				return nil, common.WrapError("spread", "parse geometric bounds", err)
+			}
+			v[i] = f
+		}
+		// "y1 x1 y2 x2"
+		return []Position{{X: v[1], Y: v[0]}, {X: v[3], Y: v[0]}, {X: v[3], Y: v[2]}, {X: v[1], Y: v[2]}}, nil
+	}
+
+	if props == nil || props.PathGeometry == nil || props.PathGeometry.GeometryPathType == nil ||
+		props.PathGeometry.GeometryPathType.PathPointArray == nil ||
+		len(props.PathGeometry.GeometryPathType.PathPointArray.PathPoints) == 0 {
+		return nil, common.Errorf("spread", "get spread rect", "", "neither GeometricBounds nor PathGeometry is available")
+	}
+
+	var points []Position
+	for _, pp := range props.PathGeometry.GeometryPathType.PathPointArray.PathPoints {
+		x, y := parseAnchorPoint(pp.Anchor)
+		points = append(points, Position{X: x, Y: y})
+	}
+	return points, nil
+}
@@ -281,3 +281,54 @@ func TestPolygon_Bounds(t *testing.T) {
 		t.Errorf("Height = %v, want 100", bounds.Height)
 	}
 }
+
+func TestSpreadTextFrame_SpreadRect(t *testing.T) {
+	tests := []struct {
+		name string
+		tf   spread.SpreadTextFrame
+		want spread.Rect
+	}{
+		{
+			name: "translated",
+			tf: spread.SpreadTextFrame{PageItemBase: spread.PageItemBase{
+				GeometricBounds: "0 0 100 200",
+				ItemTransform:   "1 0 0 1 -50 10",
+			}},
+			want: spread.Rect{Top: 10, Left: -50, Bottom: 110, Right: 150},
+		},
+		{
+			name: "rotated 90 degrees",
+			tf: spread.SpreadTextFrame{PageItemBase: spread.PageItemBase{
+				GeometricBounds: "0 0 100 200",
+				ItemTransform:   "0 1 -1 0 0 0",
+			}},
+			want: spread.Rect{Top: 0, Left: -100, Bottom: 200, Right: 0},
+		},
+		{
+			name: "no transform",
+			tf: spread.SpreadTextFrame{PageItemBase: spread.PageItemBase{
+				GeometricBounds: "5 10 15 20",
+			}},
+			want: spread.Rect{Top: 5, Left: 10, Bottom: 15, Right: 20},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.tf.SpreadRect()
+			if err != nil {
+				t.Fatalf("SpreadRect() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("SpreadRect() = %+v, want %+v", got, tt.want)
+			}
+			if !got.Contains(got.Center()) {
+				t.Errorf("rect does not contain its center %+v", got.Center())
+			}
+		})
+	}
+
+	if _, err := (&spread.SpreadTextFrame{}).SpreadRect(); err == nil {
+		t.Error("expected error without bounds or path geometry")
+	}
+}
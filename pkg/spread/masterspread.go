@@ -0,0 +1,161 @@
+package spread
+
+import (
+	"encoding/xml"
+
+	"github.com/dimelords/idmllib/v2/internal/xmlutil"
+	"github.com/dimelords/idmllib/v2/pkg/common"
+)
+
+// MasterSpread represents a master spread (MasterSpreads/*.xml) in an IDML document.
+// Master spreads hold the pages and page items that document pages inherit
+// through Page.AppliedMaster.
+//
+// The root element is <idPkg:MasterSpread> with the idPkg namespace. Like Spread,
+// it uses an outer wrapper and an inner element holding the actual content.
+type MasterSpread struct {
+	// XMLName is not set directly - we handle it manually in MarshalXML/UnmarshalXML
+	XMLName xml.Name `xml:"-"`
+
+	// DOMVersion is the InDesign DOM version (e.g., "20.4")
+	DOMVersion string `xml:"DOMVersion,attr"`
+
+	// InnerMasterSpread is the actual <MasterSpread> element
+	InnerMasterSpread MasterSpreadElement `xml:"-"`
+}
+
+// MasterSpreadElement represents the <MasterSpread> element with all attributes and children.
+type MasterSpreadElement struct {
+	XMLName xml.Name `xml:"MasterSpread"`
+
+	// Core attributes
+	Self                    string `xml:"Self,attr"`
+	Name                    string `xml:"Name,attr,omitempty"`       // Full name (e.g., "A-Master")
+	NamePrefix              string `xml:"NamePrefix,attr,omitempty"` // Prefix shown on pages (e.g., "A")
+	BaseName                string `xml:"BaseName,attr,omitempty"`   // Name without prefix (e.g., "Master")
+	ShowMasterItems         string `xml:"ShowMasterItems,attr,omitempty"`
+	PageCount               string `xml:"PageCount,attr,omitempty"`
+	OverriddenPageItemProps string `xml:"OverriddenPageItemProps,attr,omitempty"`
+	PrimaryTextFrame        string `xml:"PrimaryTextFrame,attr,omitempty"`
+	ItemTransform           string `xml:"ItemTransform,attr,omitempty"`
+
+	// Catch-all for attributes we haven't explicitly modeled
+	OtherAttrs []xml.Attr `xml:",any,attr"`
+
+	// Child elements
+	Properties   *common.Properties `xml:"Properties,omitempty"`
+	Pages        []Page             `xml:"Page,omitempty"`
+	TextFrames   []SpreadTextFrame  `xml:"TextFrame,omitempty"`
+	Rectangles   []Rectangle        `xml:"Rectangle,omitempty"`
+	Ovals        []Oval             `xml:"Oval,omitempty"`
+	Polygons     []Polygon          `xml:"Polygon,omitempty"`
+	GraphicLines []GraphicLine      `xml:"GraphicLine,omitempty"`
+	Groups       []Group            `xml:"Group,omitempty"`
+
+	// Catch-all for other elements we haven't explicitly modeled
+	OtherElements []common.RawXMLElement `xml:",any"`
+}
+
+// Pages returns all pages in this master spread.
+func (m *MasterSpread) Pages() []Page {
+	return m.InnerMasterSpread.Pages
+}
+
+// TextFrames returns all text frames in this master spread.
+func (m *MasterSpread) TextFrames() []SpreadTextFrame {
+	return m.InnerMasterSpread.TextFrames
+}
+
+// Rectangles returns all rectangles in this master spread.
+func (m *MasterSpread) Rectangles() []Rectangle {
+	return m.InnerMasterSpread.Rectangles
+}
+
+// UnmarshalXML implements custom XML unmarshaling for MasterSpread.
+func (m *MasterSpread) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	if d == nil {
+		return common.Errorf("spread", "unmarshal master spread", "", "decoder is nil")
+	}
+
+	// Verify we're parsing an idPkg:MasterSpread element
+	if start.Name.Local != "MasterSpread" {
+		return common.WrapError("spread", "parse master spread", common.ErrInvalidFormat)
+	}
+
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "DOMVersion" {
+			m.DOMVersion = attr.Value
+			break
+		}
+	}
+
+	// Read tokens until we find the inner <MasterSpread> element or hit the end
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "MasterSpread" {
+				if err := d.DecodeElement(&m.InnerMasterSpread, &t); err != nil {
+					return err
+				}
+			}
+
+		case xml.EndElement:
+			if t.Name.Local == "MasterSpread" && t.Name.Space == start.Name.Space {
+				return nil
+			}
+		}
+	}
+}
+
+// MarshalXML implements custom XML marshaling for MasterSpread.
+func (m *MasterSpread) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	wrapper := xml.StartElement{
+		Name: xml.Name{Local: "idPkg:MasterSpread"},
+		Attr: []xml.Attr{
+			{Name: xml.Name{Local: "xmlns:idPkg"}, Value: "http://ns.adobe.com/AdobeInDesign/idml/1.0/packaging"},
+			{Name: xml.Name{Local: "DOMVersion"}, Value: m.DOMVersion},
+		},
+	}
+
+	if err := e.EncodeToken(wrapper); err != nil {
+		return common.WrapError("spread", "marshal master spread", err)
+	}
+
+	innerStart := xml.StartElement{Name: xml.Name{Local: "MasterSpread"}}
+	if err := e.EncodeElement(&m.InnerMasterSpread, innerStart); err != nil {
+		return common.WrapError("spread", "marshal master spread", err)
+	}
+
+	if err := e.EncodeToken(wrapper.End()); err != nil {
+		return common.WrapError("spread", "marshal master spread", err)
+	}
+
+	return nil
+}
+
+// ParseMasterSpread parses master spread XML data into a MasterSpread struct.
+func ParseMasterSpread(data []byte) (*MasterSpread, error) {
+	if len(data) == 0 {
+		return nil, common.Errorf("spread", "parse master spread", "", "input data is empty")
+	}
+
+	var ms MasterSpread
+	if err := xml.Unmarshal(data, &ms); err != nil {
+		return nil, common.WrapError("spread", "parse master spread", err)
+	}
+	return &ms, nil
+}
+
+// MarshalMasterSpread marshals a MasterSpread struct back to XML with proper formatting.
+func MarshalMasterSpread(ms *MasterSpread) ([]byte, error) {
+	data, err := xmlutil.MarshalIndentWithHeader(ms, "", "\t")
+	if err != nil {
+		return nil, common.WrapError("spread", "marshal master spread", err)
+	}
+	return data, nil
+}
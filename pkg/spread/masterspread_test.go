@@ -0,0 +1,61 @@
+package spread
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParseMasterSpread tests parsing and round-tripping a master spread.
+func TestParseMasterSpread(t *testing.T) {
+	masterXML := `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<idPkg:MasterSpread xmlns:idPkg="http://ns.adobe.com/AdobeInDesign/idml/1.0/packaging" DOMVersion="20.4">
+	<MasterSpread Self="ub4" ItemTransform="1 0 0 1 0 0" Name="A-Master" NamePrefix="A" BaseName="Master" ShowMasterItems="true" PageCount="1" OverriddenPageItemProps="" PrimaryTextFrame="n" PageColor="UseMasterColor">
+		<Page Self="ub9" Name="A" AppliedMaster="n" GeometricBounds="0 0 841.88976377 595.27559055" ItemTransform="1 0 0 1 -297.637795275 -420.944881885" MasterPageTransform="1 0 0 1 0 0">
+			<MarginPreference ColumnCount="1" Top="36" Bottom="36" Left="36" Right="36" />
+		</Page>
+		<TextFrame Self="uc0" ParentStory="uc2" PreviousTextFrame="n" NextTextFrame="n" ItemTransform="1 0 0 1 0 0" />
+	</MasterSpread>
+</idPkg:MasterSpread>`
+
+	ms, err := ParseMasterSpread([]byte(masterXML))
+	if err != nil {
+		t.Fatalf("ParseMasterSpread failed: %v", err)
+	}
+
+	if ms.DOMVersion != "20.4" {
+		t.Errorf("DOMVersion = %q, want 20.4", ms.DOMVersion)
+	}
+	m := ms.InnerMasterSpread
+	if m.Self != "ub4" || m.NamePrefix != "A" || m.BaseName != "Master" {
+		t.Errorf("attributes = %q/%q/%q", m.Self, m.NamePrefix, m.BaseName)
+	}
+	if len(ms.Pages()) != 1 || ms.Pages()[0].Self != "ub9" {
+		t.Fatalf("Pages = %+v", ms.Pages())
+	}
+	if len(ms.TextFrames()) != 1 || ms.TextFrames()[0].Self != "uc0" {
+		t.Errorf("TextFrames = %+v", ms.TextFrames())
+	}
+
+	data, err := MarshalMasterSpread(ms)
+	if err != nil {
+		t.Fatalf("MarshalMasterSpread failed: %v", err)
+	}
+	out := string(data)
+	for _, want := range []string{"<idPkg:MasterSpread", `NamePrefix="A"`, `PageColor="UseMasterColor"`, `Self="uc0"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("marshaled output missing %s", want)
+		}
+	}
+
+	again, err := ParseMasterSpread(data)
+	if err != nil {
+		t.Fatalf("re-parse failed: %v", err)
+	}
+	if again.InnerMasterSpread.Self != "ub4" || len(again.Pages()) != 1 {
+		t.Errorf("round trip lost content: %+v", again.InnerMasterSpread)
+	}
+
+	if _, err := ParseMasterSpread(nil); err == nil {
+		t.Error("expected error for empty data")
+	}
+}
@@ -36,6 +36,16 @@ type Rectangle struct {
 	Locked              string `xml:"Locked,attr,omitempty"`              // "true" or "false"
 	LocalDisplaySetting string `xml:"LocalDisplaySetting,attr,omitempty"` // "Default", etc.
 
+	// Stroke properties
+	StrokeWeight string `xml:"StrokeWeight,attr,omitempty"` // Border width in points
+	StrokeType   string `xml:"StrokeType,attr,omitempty"`   // Stroke style reference
+	StrokeColor  string `xml:"StrokeColor,attr,omitempty"`  // Color swatch reference
+	StrokeTint   string `xml:"StrokeTint,attr,omitempty"`   // Tint percentage
+
+	// Fill properties
+	FillColor string `xml:"FillColor,attr,omitempty"`
+	FillTint  string `xml:"FillTint,attr,omitempty"`
+
 	// Style and transform
 	AppliedObjectStyle string `xml:"AppliedObjectStyle,attr,omitempty"`
 
@@ -44,6 +54,9 @@ type Rectangle struct {
 	TargetInterfaceChangeCount      string `xml:"TargetInterfaceChangeCount,attr,omitempty"`
 	LastUpdatedInterfaceChangeCount string `xml:"LastUpdatedInterfaceChangeCount,attr,omitempty"`
 
+	// Additional attributes (corner options, stroke details, etc.)
+	OtherAttrs []xml.Attr `xml:",any,attr"`
+
 	// Child elements
 	Properties         *common.Properties  `xml:"Properties,omitempty"`
 	FrameFittingOption *FrameFittingOption `xml:"FrameFittingOption,omitempty"`
@@ -0,0 +1,67 @@
+package spread
+
+import (
+	"strconv"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+)
+
+// BindingIndex returns the index of the first page to the right of the spine.
+// Pages before it are left-hand pages, the rest are right-hand pages.
+//
+// BindingLocation is used when present; otherwise half of the pages are
+// assumed to be on the left. The result is clamped to [0, len(Pages)].
+func (s *SpreadElement) BindingIndex() int {
+	n := len(s.Pages)
+	binding, err := strconv.Atoi(s.BindingLocation)
+	if err != nil {
+		binding = n / 2
+	}
+	return max(0, min(binding, n))
+}
+
+// LayoutPages positions the pages of the spread around the spine and updates
+// PageCount and BindingLocation to match the Pages slice.
+//
+// Pages left of the spine are placed right to left ending at x=0, pages right
+// of it left to right starting at x=0. Each page is centered vertically on the
+// spread origin, which is how InDesign lays out spreads. Page items are not
+// moved; callers that change page positions are responsible for that.
+//
+// Returns an error if a page has missing or malformed GeometricBounds.
+func (s *SpreadElement) LayoutPages() error {
+	binding := s.BindingIndex()
+
+	sizes := make([]Bounds, len(s.Pages))
+	for i := range s.Pages {
+		b, err := s.Pages[i].Bounds()
+		if err != nil {
+			return common.WrapError("spread", "layout pages", err)
+		}
+		sizes[i] = b
+	}
+
+	// Left-hand pages, from the spine outwards
+	x := 0.0
+	for i := binding - 1; i >= 0; i-- {
+		x -= sizes[i].Width
+		s.Pages[i].ItemTransform = pageTransform(x, sizes[i].Height)
+	}
+
+	// Right-hand pages, from the spine outwards
+	x = 0
+	for i := binding; i < len(s.Pages); i++ {
+		s.Pages[i].ItemTransform = pageTransform(x, sizes[i].Height)
+		x += sizes[i].Width
+	}
+
+	s.PageCount = strconv.Itoa(len(s.Pages))
+	s.BindingLocation = strconv.Itoa(binding)
+	return nil
+}
+
+// pageTransform returns the ItemTransform for a page with its left edge at x,
+// centered vertically on the spread origin.
+func pageTransform(x, height float64) string {
+	return Transform{A: 1, D: 1, X: x, Y: -height / 2}.String()
+}
@@ -0,0 +1,45 @@
+package spread
+
+import "testing"
+
+// TestLayoutPages tests placing pages around the spine.
+func TestLayoutPages(t *testing.T) {
+	s := SpreadElement{
+		BindingLocation: "1",
+		Pages: []Page{
+			{Self: "a", GeometricBounds: "0 0 800 600"},
+			{Self: "b", GeometricBounds: "0 0 800 600"},
+			{Self: "c", GeometricBounds: "0 0 700 500"},
+		},
+	}
+
+	if err := s.LayoutPages(); err != nil {
+		t.Fatalf("LayoutPages failed: %v", err)
+	}
+
+	want := []string{"1 0 0 1 -600 -400", "1 0 0 1 0 -400", "1 0 0 1 600 -350"}
+	for i, page := range s.Pages {
+		if page.ItemTransform != want[i] {
+			t.Errorf("page %s ItemTransform = %q, want %q", page.Self, page.ItemTransform, want[i])
+		}
+	}
+	if s.PageCount != "3" || s.BindingLocation != "1" {
+		t.Errorf("PageCount %q, BindingLocation %q", s.PageCount, s.BindingLocation)
+	}
+
+	// Missing BindingLocation puts half of the pages on the left; out of range is clamped
+	for _, tt := range []struct {
+		binding string
+		want    int
+	}{{"", 1}, {"9", 3}, {"-2", 0}} {
+		s.BindingLocation = tt.binding
+		if got := s.BindingIndex(); got != tt.want {
+			t.Errorf("BindingIndex(%q) = %d, want %d", tt.binding, got, tt.want)
+		}
+	}
+
+	s.Pages = append(s.Pages, Page{Self: "d"})
+	if err := s.LayoutPages(); err == nil {
+		t.Error("expected error for page without GeometricBounds")
+	}
+}
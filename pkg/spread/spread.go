@@ -255,6 +255,16 @@ type SpreadTextFrame struct {
 	Locked              string `xml:"Locked,attr,omitempty"`
 	LocalDisplaySetting string `xml:"LocalDisplaySetting,attr,omitempty"`
 
+	// Stroke properties
+	StrokeWeight string `xml:"StrokeWeight,attr,omitempty"` // Border width in points
+	StrokeType   string `xml:"StrokeType,attr,omitempty"`   // Stroke style reference
+	StrokeColor  string `xml:"StrokeColor,attr,omitempty"`  // Color swatch reference
+	StrokeTint   string `xml:"StrokeTint,attr,omitempty"`   // Tint percentage
+
+	// Fill properties
+	FillColor string `xml:"FillColor,attr,omitempty"`
+	FillTint  string `xml:"FillTint,attr,omitempty"`
+
 	// Style and transform
 	AppliedObjectStyle string `xml:"AppliedObjectStyle,attr,omitempty"`
 
@@ -263,6 +273,9 @@ type SpreadTextFrame struct {
 	TargetInterfaceChangeCount      string `xml:"TargetInterfaceChangeCount,attr,omitempty"`
 	LastUpdatedInterfaceChangeCount string `xml:"LastUpdatedInterfaceChangeCount,attr,omitempty"`
 
+	// Additional attributes (corner options, stroke details, etc.)
+	OtherAttrs []xml.Attr `xml:",any,attr"`
+
 	// Child elements
 	Properties *common.Properties `xml:"Properties,omitempty"`
 
@@ -299,6 +312,9 @@ type Oval struct {
 	OverriddenPageItemProps string `xml:"OverriddenPageItemProps,attr,omitempty"`
 	LocalDisplaySetting     string `xml:"LocalDisplaySetting,attr,omitempty"`
 
+	// Additional attributes (corner options, stroke details, etc.)
+	OtherAttrs []xml.Attr `xml:",any,attr"`
+
 	// Child elements
 	Properties         *common.Properties  `xml:"Properties,omitempty"`
 	TextWrapPreference *TextWrapPreference `xml:"TextWrapPreference,omitempty"`
@@ -337,6 +353,9 @@ type Polygon struct {
 	OverriddenPageItemProps string `xml:"OverriddenPageItemProps,attr,omitempty"`
 	LocalDisplaySetting     string `xml:"LocalDisplaySetting,attr,omitempty"`
 
+	// Additional attributes (corner options, stroke details, etc.)
+	OtherAttrs []xml.Attr `xml:",any,attr"`
+
 	// Child elements
 	Properties         *common.Properties  `xml:"Properties,omitempty"`
 	TextWrapPreference *TextWrapPreference `xml:"TextWrapPreference,omitempty"`
@@ -405,6 +424,9 @@ type GraphicLine struct {
 	TargetInterfaceChangeCount      string `xml:"TargetInterfaceChangeCount,attr,omitempty"`
 	LastUpdatedInterfaceChangeCount string `xml:"LastUpdatedInterfaceChangeCount,attr,omitempty"`
 
+	// Additional attributes (stroke details, etc.)
+	OtherAttrs []xml.Attr `xml:",any,attr"`
+
 	// Child elements
 	PathGeometry       *common.PathGeometry `xml:"PathGeometry,omitempty"`
 	Properties         *common.Properties   `xml:"Properties,omitempty"`
@@ -419,5 +441,6 @@ type GraphicLine struct {
 type Group struct {
 	PageItemBase
 	AppliedObjectStyle string                 `xml:"AppliedObjectStyle,attr,omitempty"`
+	OtherAttrs         []xml.Attr             `xml:",any,attr"`
 	OtherElements      []common.RawXMLElement `xml:",any"`
 }
@@ -0,0 +1,392 @@
+package story
+
+import (
+	"bytes"
+	"encoding/xml"
+	"slices"
+
+	"github.com/dimelords/idmllib/v2/pkg/spread"
+)
+
+// AnchoredObjectSetting describes how an anchored page item is positioned
+// relative to its anchor character in the text.
+type AnchoredObjectSetting struct {
+	XMLName xml.Name `xml:"AnchoredObjectSetting"`
+
+	AnchoredPosition string `xml:"AnchoredPosition,attr,omitempty"` // "InlinePosition", "AboveLine", "Anchored"
+	SpineRelative    string `xml:"SpineRelative,attr,omitempty"`    // "true"/"false"
+	LockPosition     string `xml:"LockPosition,attr,omitempty"`     // "true"/"false"
+	PinPosition      string `xml:"PinPosition,attr,omitempty"`      // "true"/"false"
+	AnchorPoint      string `xml:"AnchorPoint,attr,omitempty"`      // "TopLeftAnchor", "CenterAnchor", etc.
+
+	// Horizontal placement (custom anchored objects)
+	HorizontalAlignment      string `xml:"HorizontalAlignment,attr,omitempty"`      // "LeftAlign", "CenterAlign", etc.
+	HorizontalReferencePoint string `xml:"HorizontalReferencePoint,attr,omitempty"` // "TextFrame", "PageMargins", etc.
+	AnchorXoffset            string `xml:"AnchorXoffset,attr,omitempty"`
+
+	// Vertical placement
+	VerticalAlignment      string `xml:"VerticalAlignment,attr,omitempty"`      // "TopAlign", "CenterAlign", etc.
+	VerticalReferencePoint string `xml:"VerticalReferencePoint,attr,omitempty"` // "LineBaseline", "Capheight", etc.
+	AnchorYoffset          string `xml:"AnchorYoffset,attr,omitempty"`
+	AnchorSpaceAbove       string `xml:"AnchorSpaceAbove,attr,omitempty"` // Space above for "AboveLine" objects
+
+	// Additional attributes (catch-all)
+	OtherAttrs []xml.Attr `xml:",any,attr"`
+}
+
+// Anchoring holds the parts that a page item only has when it is anchored in
+// a story. It is embedded in the Anchored* types.
+type Anchoring struct {
+	AnchoredObjectSetting *AnchoredObjectSetting `xml:"AnchoredObjectSetting,omitempty"`
+
+	// settingPosition is the 1-based position of AnchoredObjectSetting among
+	// the child elements of the parsed item, so it is written back in place.
+	// Zero writes it after the other children.
+	settingPosition int
+}
+
+// attr returns the value of the named attribute in attrs.
+func attr(attrs []xml.Attr, name string) string {
+	for _, a := range attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// AnchoredRectangle is a rectangle (often holding an image) anchored in a story.
+type AnchoredRectangle struct {
+	spread.Rectangle
+	Anchoring
+}
+
+// AnchoredTextFrame is a text frame anchored in a story. Its text lives in
+// the story referenced by ParentStory.
+type AnchoredTextFrame struct {
+	spread.SpreadTextFrame
+	Anchoring
+}
+
+// AnchoredOval is an oval anchored in a story.
+type AnchoredOval struct {
+	spread.Oval
+	Anchoring
+}
+
+// AnchoredPolygon is a polygon anchored in a story.
+type AnchoredPolygon struct {
+	spread.Polygon
+	Anchoring
+}
+
+// AnchoredGraphicLine is a graphic line anchored in a story.
+type AnchoredGraphicLine struct {
+	spread.GraphicLine
+	Anchoring
+}
+
+// AnchoredGroup is a group of page items anchored in a story.
+// The grouped items themselves are kept as raw XML, like spread.Group.
+type AnchoredGroup struct {
+	spread.Group
+	Anchoring
+}
+
+// AnchoredObject is a page item placed inside a CharacterStyleRange, either
+// inline or as a custom anchored object. Exactly one of the fields is set.
+type AnchoredObject struct {
+	Rectangle   *AnchoredRectangle
+	TextFrame   *AnchoredTextFrame
+	Oval        *AnchoredOval
+	Polygon     *AnchoredPolygon
+	GraphicLine *AnchoredGraphicLine
+	Group       *AnchoredGroup
+}
+
+// ElementName returns the XML element name of the anchored item ("Rectangle", "TextFrame", ...).
+func (a *AnchoredObject) ElementName() string {
+	switch {
+	case a.Rectangle != nil:
+		return "Rectangle"
+	case a.TextFrame != nil:
+		return "TextFrame"
+	case a.Oval != nil:
+		return "Oval"
+	case a.Polygon != nil:
+		return "Polygon"
+	case a.GraphicLine != nil:
+		return "GraphicLine"
+	case a.Group != nil:
+		return "Group"
+	}
+	return ""
+}
+
+// item returns the anchored item as a value for marshaling, together with its
+// base and anchoring parts.
+func (a *AnchoredObject) item() (any, *spread.PageItemBase, *Anchoring) {
+	switch {
+	case a.Rectangle != nil:
+		return a.Rectangle, &a.Rectangle.PageItemBase, &a.Rectangle.Anchoring
+	case a.TextFrame != nil:
+		return a.TextFrame, &a.TextFrame.PageItemBase, &a.TextFrame.Anchoring
+	case a.Oval != nil:
+		return a.Oval, &a.Oval.PageItemBase, &a.Oval.Anchoring
+	case a.Polygon != nil:
+		return a.Polygon, &a.Polygon.PageItemBase, &a.Polygon.Anchoring
+	case a.GraphicLine != nil:
+		return a.GraphicLine, &a.GraphicLine.PageItemBase, &a.GraphicLine.Anchoring
+	case a.Group != nil:
+		return a.Group, &a.Group.PageItemBase, &a.Group.Anchoring
+	}
+	return nil, nil, nil
+}
+
+// Self returns the ID of the anchored item.
+func (a *AnchoredObject) Self() string {
+	if _, base, _ := a.item(); base != nil {
+		return base.Self
+	}
+	return ""
+}
+
+// Setting returns the AnchoredObjectSetting of the item, or nil if it has none
+// (InDesign omits it for inline objects with default settings).
+func (a *AnchoredObject) Setting() *AnchoredObjectSetting {
+	if _, _, anchoring := a.item(); anchoring != nil {
+		return anchoring.AnchoredObjectSetting
+	}
+	return nil
+}
+
+// AppliedObjectStyle returns the object style applied to the anchored item.
+func (a *AnchoredObject) AppliedObjectStyle() string {
+	switch {
+	case a.Rectangle != nil:
+		return a.Rectangle.AppliedObjectStyle
+	case a.TextFrame != nil:
+		return a.TextFrame.AppliedObjectStyle
+	case a.Oval != nil:
+		return a.Oval.AppliedObjectStyle
+	case a.Polygon != nil:
+		return a.Polygon.AppliedObjectStyle
+	case a.GraphicLine != nil:
+		return a.GraphicLine.AppliedObjectStyle
+	case a.Group != nil:
+		return a.Group.AppliedObjectStyle
+	}
+	return ""
+}
+
+// Colors returns the fill and stroke color references set directly on the
+// anchored item. Empty values and "Swatch/None" are skipped.
+func (a *AnchoredObject) Colors() []string {
+	var fill, stroke string
+	switch {
+	case a.Oval != nil:
+		fill, stroke = a.Oval.FillColor, a.Oval.StrokeColor
+	case a.Polygon != nil:
+		fill, stroke = a.Polygon.FillColor, a.Polygon.StrokeColor
+	case a.GraphicLine != nil:
+		fill, stroke = a.GraphicLine.FillColor, a.GraphicLine.StrokeColor
+	case a.Rectangle != nil:
+		fill, stroke = a.Rectangle.FillColor, a.Rectangle.StrokeColor
+	case a.TextFrame != nil:
+		fill, stroke = a.TextFrame.FillColor, a.TextFrame.StrokeColor
+	case a.Group != nil:
+		// Groups keep their colors in OtherAttrs
+		fill, stroke = attr(a.Group.OtherAttrs, "FillColor"), attr(a.Group.OtherAttrs, "StrokeColor")
+	}
+
+	var colors []string
+	for _, c := range []string{fill, stroke} {
+		if c != "" && c != "Swatch/None" {
+			colors = append(colors, c)
+		}
+	}
+	return colors
+}
+
+// Image returns the image placed in the anchored item, or nil.
+func (a *AnchoredObject) Image() *spread.Image {
+	switch {
+	case a.Rectangle != nil:
+		return a.Rectangle.Image
+	case a.Oval != nil:
+		return a.Oval.Image
+	case a.Polygon != nil:
+		return a.Polygon.Image
+	}
+	return nil
+}
+
+// ParentStory returns the story ID of an anchored text frame, or "" for other items.
+func (a *AnchoredObject) ParentStory() string {
+	if a.TextFrame != nil {
+		return a.TextFrame.ParentStory
+	}
+	return ""
+}
+
+// AnchoredObjects returns pointers to all anchored page items in the story,
+// in document order, including those inside table cells.
+func (s *Story) AnchoredObjects() []*AnchoredObject {
+	return anchoredObjectsIn(s.StoryElement.ParagraphStyleRanges, nil)
+}
+
+// anchoredObjectsIn appends the anchored objects found in ranges to objects.
+func anchoredObjectsIn(ranges []ParagraphStyleRange, objects []*AnchoredObject) []*AnchoredObject {
+	for i := range ranges {
+		psr := &ranges[i]
+		for j := range psr.CharacterStyleRanges {
+			for _, child := range psr.CharacterStyleRanges[j].FlatChildren() {
+				switch {
+				case child.Anchored != nil:
+					objects = append(objects, child.Anchored)
+				case child.Table != nil:
+					for k := range child.Table.Cells {
+						objects = anchoredObjectsIn(child.Table.Cells[k].ParagraphStyleRanges, objects)
+					}
+				}
+			}
+		}
+	}
+	return objects
+}
+
+// decodeAnchoredObject decodes a page item element inside a CharacterStyleRange.
+func decodeAnchoredObject(d *xml.Decoder, start *xml.StartElement) (*AnchoredObject, error) {
+	var obj AnchoredObject
+	var target any
+	switch start.Name.Local {
+	case "Rectangle":
+		obj.Rectangle = &AnchoredRectangle{}
+		target = obj.Rectangle
+	case "TextFrame":
+		obj.TextFrame = &AnchoredTextFrame{}
+		target = obj.TextFrame
+	case "Oval":
+		obj.Oval = &AnchoredOval{}
+		target = obj.Oval
+	case "Polygon":
+		obj.Polygon = &AnchoredPolygon{}
+		target = obj.Polygon
+	case "GraphicLine":
+		obj.GraphicLine = &AnchoredGraphicLine{}
+		target = obj.GraphicLine
+	case "Group":
+		obj.Group = &AnchoredGroup{}
+		target = obj.Group
+	}
+
+	// Copy the element while noting where AnchoredObjectSetting is, which
+	// the embedded Anchoring would otherwise move after the other children
+	var buf bytes.Buffer
+	enc := xml.NewEncoder(&buf)
+	if err := enc.EncodeToken(*start); err != nil {
+		return nil, err
+	}
+	position, children := 0, 0
+	for depth := 0; depth >= 0; {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if depth == 0 {
+				children++
+				if t.Name.Local == "AnchoredObjectSetting" {
+					position = children
+				}
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+		}
+		if err := enc.EncodeToken(tok); err != nil {
+			return nil, err
+		}
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+	if err := xml.Unmarshal(buf.Bytes(), target); err != nil {
+		return nil, err
+	}
+	if _, _, anchoring := obj.item(); position < children {
+		anchoring.settingPosition = position
+	}
+	return &obj, nil
+}
+
+// encodeAnchoredObject encodes an anchored object as its page item element,
+// with its AnchoredObjectSetting at the position it was parsed from.
+func encodeAnchoredObject(e *xml.Encoder, obj *AnchoredObject) error {
+	item, _, anchoring := obj.item()
+	if item == nil {
+		return nil
+	}
+	start := xml.StartElement{Name: xml.Name{Local: obj.ElementName()}}
+	if anchoring.AnchoredObjectSetting == nil || anchoring.settingPosition == 0 {
+		return e.EncodeElement(item, start)
+	}
+
+	// Encode the item, then split its children into token groups and move
+	// AnchoredObjectSetting, written last, back to its position
+	var buf bytes.Buffer
+	if err := xml.NewEncoder(&buf).EncodeElement(item, start); err != nil {
+		return err
+	}
+	d := xml.NewDecoder(&buf)
+	var root xml.StartElement
+	var children [][]xml.Token
+	for depth := -1; ; {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		tok = xml.CopyToken(tok)
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 0 {
+				root = t
+				continue
+			}
+			if depth == 1 {
+				children = append(children, nil)
+			}
+		case xml.EndElement:
+			depth--
+		case xml.CharData:
+			// The encoder indents the children itself
+			if len(bytes.TrimSpace(t)) == 0 {
+				continue
+			}
+		}
+		if depth < 0 {
+			break
+		}
+		if n := len(children); n > 0 {
+			children[n-1] = append(children[n-1], tok)
+		}
+	}
+	if n := len(children); n > 0 {
+		setting := children[n-1]
+		children = slices.Insert(children[:n-1], min(anchoring.settingPosition, n)-1, setting)
+	}
+
+	if err := e.EncodeToken(root); err != nil {
+		return err
+	}
+	for _, child := range children {
+		for _, tok := range child {
+			if err := e.EncodeToken(tok); err != nil {
+				return err
+			}
+		}
+	}
+	return e.EncodeToken(root.End())
+}
@@ -0,0 +1,184 @@
+package story
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// anchoredStoryXML is a story with an inline image rectangle and a custom
+// anchored text frame, separated by text.
+const anchoredStoryXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<idPkg:Story xmlns:idPkg="http://ns.adobe.com/AdobeInDesign/idml/1.0/packaging" DOMVersion="20.4">
+	<Story Self="u200" AppliedTOCStyle="n" TrackChanges="false">
+		<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/$ID/NormalParagraphStyle">
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+				<Content>Logo: </Content>
+				<Rectangle Self="u210" ContentType="GraphicType" StrokeWeight="0.5" FillColor="Swatch/None" StrokeColor="Color/Red" AppliedObjectStyle="ObjectStyle/Inline Image" ItemTransform="1 0 0 1 20 -10" GeometricBounds="-10 -20 10 20">
+					<Properties>
+						<PathGeometry>
+							<GeometryPathType PathOpen="false">
+								<PathPointArray>
+									<PathPointType Anchor="-20 -10" LeftDirection="-20 -10" RightDirection="-20 -10" />
+								</PathPointArray>
+							</GeometryPathType>
+						</PathGeometry>
+					</Properties>
+					<AnchoredObjectSetting AnchoredPosition="InlinePosition" AnchorYoffset="-2" />
+					<Image Self="u211" Space="$ID/#Links_RGB" AppliedObjectStyle="ObjectStyle/$ID/[None]" ItemTransform="1 0 0 1 0 0">
+						<Link Self="u212" LinkResourceURI="file:/images/logo.png" StoredState="Normal" />
+					</Image>
+				</Rectangle>
+				<Content> and a sidebar</Content>
+				<TextFrame Self="u220" ParentStory="u230" PreviousTextFrame="n" NextTextFrame="n" ContentType="TextType" AppliedObjectStyle="ObjectStyle/Sidebar" FillColor="Color/Paper">
+					<AnchoredObjectSetting AnchoredPosition="Anchored" AnchorPoint="TopLeftAnchor" HorizontalReferencePoint="PageMargins" AnchorXoffset="12" VerticalReferencePoint="LineBaseline" PinPosition="true" />
+					<TextFramePreference TextColumnCount="1" />
+				</TextFrame>
+				<Br/>
+			</CharacterStyleRange>
+		</ParagraphStyleRange>
+	</Story>
+</idPkg:Story>`
+
+// TestParseStory_AnchoredObjects tests that anchored page items are parsed into typed structures.
+func TestParseStory_AnchoredObjects(t *testing.T) {
+	st, err := ParseStory([]byte(anchoredStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+
+	objects := st.AnchoredObjects()
+	if len(objects) != 2 {
+		t.Fatalf("AnchoredObjects() returned %d objects, want 2", len(objects))
+	}
+
+	rect := objects[0]
+	if rect.Rectangle == nil || rect.ElementName() != "Rectangle" || rect.Self() != "u210" {
+		t.Fatalf("first object = %s %s, want Rectangle u210", rect.ElementName(), rect.Self())
+	}
+	if rect.AppliedObjectStyle() != "ObjectStyle/Inline Image" {
+		t.Errorf("AppliedObjectStyle() = %q", rect.AppliedObjectStyle())
+	}
+	if s := rect.Setting(); s == nil || s.AnchoredPosition != "InlinePosition" || s.AnchorYoffset != "-2" {
+		t.Errorf("Setting() = %+v", s)
+	}
+	if img := rect.Image(); img == nil || img.Link == nil || img.Link.LinkResourceURI != "file:/images/logo.png" {
+		t.Errorf("Image() = %+v", img)
+	}
+	if diff := cmp.Diff([]string{"Color/Red"}, rect.Colors()); diff != "" {
+		t.Errorf("Colors() mismatch (-want +got):\n%s", diff)
+	}
+
+	frame := objects[1]
+	if frame.TextFrame == nil || frame.ParentStory() != "u230" {
+		t.Fatalf("second object = %s, ParentStory %q", frame.ElementName(), frame.ParentStory())
+	}
+	if s := frame.Setting(); s == nil || s.AnchoredPosition != "Anchored" || s.PinPosition != "true" {
+		t.Errorf("Setting() = %+v", s)
+	}
+	if diff := cmp.Diff([]string{"Color/Paper"}, frame.Colors()); diff != "" {
+		t.Errorf("Colors() mismatch (-want +got):\n%s", diff)
+	}
+
+	if got := st.ExtractText(); got != "Logo:  and a sidebar\n" {
+		t.Errorf("ExtractText() = %q", got)
+	}
+}
+
+// TestAnchoredObjectsRoundtrip tests that anchored items marshal to stable XML in place.
+func TestAnchoredObjectsRoundtrip(t *testing.T) {
+	st, err := ParseStory([]byte(anchoredStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+
+	first, err := MarshalStory(st)
+	if err != nil {
+		t.Fatalf("MarshalStory failed: %v", err)
+	}
+	reparsed, err := ParseStory(first)
+	if err != nil {
+		t.Fatalf("ParseStory (second) failed: %v", err)
+	}
+	second, err := MarshalStory(reparsed)
+	if err != nil {
+		t.Fatalf("MarshalStory (second) failed: %v", err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Errorf("roundtrip output differs:\nfirst:\n%s\nsecond:\n%s", first, second)
+	}
+
+	out := string(first)
+	for _, want := range []string{
+		`StrokeColor="Color/Red"`,
+		`StrokeWeight="0.5"`,
+		`<AnchoredObjectSetting AnchoredPosition="InlinePosition" AnchorYoffset="-2"></AnchoredObjectSetting>`,
+		`LinkResourceURI="file:/images/logo.png"`,
+		`<PathPointType Anchor="-20 -10"`,
+		`<TextFramePreference TextColumnCount="1">`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %s", want)
+		}
+	}
+	if strings.Index(out, "Logo:") > strings.Index(out, "<Rectangle") ||
+		strings.Index(out, "</Rectangle>") > strings.Index(out, "and a sidebar") ||
+		strings.Index(out, "and a sidebar") > strings.Index(out, "<TextFrame") {
+		t.Error("anchored items are not kept between surrounding content")
+	}
+}
+
+// TestAnchoredObjects_InTableCell tests that objects anchored in table cells are found.
+func TestAnchoredObjects_InTableCell(t *testing.T) {
+	st, table := parseTableStory(t)
+
+	csr := &table.Cells[0].ParagraphStyleRanges[0].CharacterStyleRanges[0]
+	oval := &AnchoredOval{}
+	oval.Self = "u300"
+	csr.Children = append(csr.Children, CharacterChild{Anchored: &AnchoredObject{Oval: oval}})
+
+	objects := st.AnchoredObjects()
+	if len(objects) != 1 || objects[0].Self() != "u300" {
+		t.Errorf("AnchoredObjects() = %v, want the oval u300", objects)
+	}
+}
+
+// TestAnchoredObjectSetting_KeepsPosition tests that AnchoredObjectSetting is
+// written where it was parsed, before the children that followed it.
+func TestAnchoredObjectSetting_KeepsPosition(t *testing.T) {
+	st, err := ParseStory([]byte(anchoredStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+	data, err := MarshalStory(st)
+	if err != nil {
+		t.Fatalf("MarshalStory failed: %v", err)
+	}
+	out := string(data)
+
+	rect := out[strings.Index(out, "<Rectangle"):strings.Index(out, "</Rectangle>")]
+	order := []string{"<Properties>", "<PathGeometry>", "<AnchoredObjectSetting", "<Image", "<Link"}
+	for i := 1; i < len(order); i++ {
+		if strings.Index(rect, order[i-1]) > strings.Index(rect, order[i]) {
+			t.Errorf("%s is written after %s:\n%s", order[i-1], order[i], rect)
+		}
+	}
+	frame := out[strings.Index(out, "<TextFrame"):strings.Index(out, "</TextFrame>")]
+	if strings.Index(frame, "<AnchoredObjectSetting") > strings.Index(frame, "<TextFramePreference") {
+		t.Errorf("AnchoredObjectSetting is written after TextFramePreference:\n%s", frame)
+	}
+
+	reparsed, err := ParseStory(data)
+	if err != nil {
+		t.Fatalf("ParseStory (second) failed: %v", err)
+	}
+	objects := reparsed.AnchoredObjects()
+	if got := objects[0].Setting(); got == nil || got.AnchorYoffset != "-2" {
+		t.Errorf("Setting() after roundtrip = %+v", got)
+	}
+	if img := objects[0].Image(); img == nil || img.Self != "u211" {
+		t.Errorf("Image() after roundtrip = %+v", img)
+	}
+}
@@ -0,0 +1,96 @@
+package story
+
+import (
+	"encoding/xml"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+)
+
+// BackingStory represents the XML/BackingStory.xml file. It holds the XML
+// elements that are not placed in a story, including the root element of the
+// XML structure.
+//
+// The backing story has the shape of a story with an <XmlStory> element in
+// place of <Story>, so it embeds Story and shares its text and XML methods.
+type BackingStory struct {
+	Story
+}
+
+// ParseBackingStory parses a BackingStory.xml file.
+func ParseBackingStory(data []byte) (*BackingStory, error) {
+	if len(data) == 0 {
+		return nil, common.Errorf("story", "parse backing story", "", "input data is empty")
+	}
+
+	var bs BackingStory
+	if err := xml.Unmarshal(data, &bs); err != nil {
+		return nil, common.WrapError("story", "parse backing story", err)
+	}
+	return &bs, nil
+}
+
+// MarshalBackingStory marshals a BackingStory back to XML with the XML declaration.
+func MarshalBackingStory(bs *BackingStory) ([]byte, error) {
+	data, err := xml.MarshalIndent(bs, "", "\t")
+	if err != nil {
+		return nil, common.WrapError("story", "marshal backing story", err)
+	}
+
+	result := []byte(xml.Header)
+	result = append(result, data...)
+	result = append(result, '\n')
+	return result, nil
+}
+
+// UnmarshalXML implements custom unmarshaling for BackingStory to read the
+// XmlStory element into StoryElement.
+func (b *BackingStory) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	b.XMLName = start.Name
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "DOMVersion" {
+			b.DOMVersion = attr.Value
+		}
+	}
+
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			if t.Name.Local != "XmlStory" {
+				if err := d.Skip(); err != nil {
+					return err
+				}
+				continue
+			}
+			// StoryElement expects a <Story> element
+			t.Name.Local = "Story"
+			if err := d.DecodeElement(&b.StoryElement, &t); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// MarshalXML implements custom marshaling for BackingStory to write the
+// idPkg:BackingStory and XmlStory elements.
+func (b BackingStory) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: "idPkg:BackingStory"}
+	start.Attr = []xml.Attr{
+		{Name: xml.Name{Local: "xmlns:idPkg"}, Value: "http://ns.adobe.com/AdobeInDesign/idml/1.0/packaging"},
+		{Name: xml.Name{Local: "DOMVersion"}, Value: b.DOMVersion},
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := e.EncodeElement(b.StoryElement, xml.StartElement{Name: xml.Name{Local: "XmlStory"}}); err != nil {
+		return err
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
@@ -0,0 +1,270 @@
+package story
+
+import (
+	"encoding/xml"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+)
+
+// Change types of tracked changes.
+const (
+	ChangeInserted = "InsertedText"
+	ChangeDeleted  = "DeletedText"
+	ChangeMoved    = "MovedText" // text at the place it was moved to
+)
+
+// changeDateLayout is the format of Change and Note dates.
+const changeDateLayout = "2006-01-02T15:04:05"
+
+// Change is a tracked change. It wraps the inserted, deleted or moved text
+// inside a CharacterStyleRange; the text is part of the story text until the
+// change is accepted or rejected.
+type Change struct {
+	XMLName xml.Name
+
+	// Identity
+	Self       string
+	ChangeType string // ChangeInserted, ChangeDeleted or ChangeMoved
+
+	// Author and time of the change
+	UserName string // Name of a DocumentUser in designmap.xml
+	Date     string // e.g., "2024-05-12T10:00:00"
+
+	// Additional attributes (catch-all)
+	OtherAttrs []xml.Attr
+
+	// Changed text: Content, Br and the other character children in order
+	Children []CharacterChild
+}
+
+// Time returns the parsed Date of the change.
+func (c *Change) Time() (time.Time, error) {
+	return time.Parse(changeDateLayout, c.Date)
+}
+
+// Note is an InCopy note anchored in a CharacterStyleRange. Like a footnote,
+// its text lives in its own paragraph style ranges and is not part of the
+// story text.
+type Note struct {
+	XMLName xml.Name `xml:"Note"`
+
+	// Identity
+	Self string `xml:"Self,attr,omitempty"`
+
+	// Author and time of the note
+	UserName            string `xml:"UserName,attr,omitempty"`
+	AppliedDocumentUser string `xml:"AppliedDocumentUser,attr,omitempty"` // Reference to a DocumentUser
+	CreationDate        string `xml:"CreationDate,attr,omitempty"`
+	ModificationDate    string `xml:"ModificationDate,attr,omitempty"`
+
+	// Additional attributes (catch-all)
+	OtherAttrs []xml.Attr `xml:",any,attr"`
+
+	// Note text
+	ParagraphStyleRanges []ParagraphStyleRange `xml:"ParagraphStyleRange"`
+
+	// Catch-all for unknown elements
+	OtherElements []common.RawXMLElement `xml:",any"`
+}
+
+// Text returns the note text without trailing line breaks.
+func (n *Note) Text() string {
+	var buf strings.Builder
+	writeRangesText(&buf, n.ParagraphStyleRanges)
+	return strings.TrimRight(buf.String(), "\n")
+}
+
+// TrackedChange describes a tracked change in a story.
+type TrackedChange struct {
+	Change *Change
+	Range  TextRange // position of the changed text in the flattened story text
+	Text   string
+}
+
+// Changes returns the tracked changes in the story, in document order.
+func (s *Story) Changes() []TrackedChange {
+	text := s.ExtractText()
+	var changes []TrackedChange
+	s.walkChildren(func(v childVisit) {
+		change := (*v.parent)[v.index].Change
+		if change == nil {
+			return
+		}
+		end := v.pos + childrenTextLen(change.Children)
+		changes = append(changes, TrackedChange{
+			Change: change,
+			Range:  TextRange{Start: v.pos, End: end},
+			Text:   text[v.pos:end],
+		})
+	})
+	return changes
+}
+
+// AcceptChange accepts the tracked change with the given Self ID: inserted
+// and moved text stays in the story, deleted text is removed.
+//
+// Returns common.ErrNotFound if the story has no such change.
+func (s *Story) AcceptChange(self string) error {
+	if s.resolveChanges(func(c *Change) bool { return c.Self == self }, true) == 0 {
+		return common.WrapErrorWithPath("story", "accept change", self, common.ErrNotFound)
+	}
+	return nil
+}
+
+// RejectChange rejects the tracked change with the given Self ID: inserted
+// and moved text is removed, deleted text stays in the story.
+//
+// Returns common.ErrNotFound if the story has no such change.
+func (s *Story) RejectChange(self string) error {
+	if s.resolveChanges(func(c *Change) bool { return c.Self == self }, false) == 0 {
+		return common.WrapErrorWithPath("story", "reject change", self, common.ErrNotFound)
+	}
+	return nil
+}
+
+// AcceptAllChanges accepts all tracked changes in the story and returns how
+// many there were.
+func (s *Story) AcceptAllChanges() int {
+	return s.resolveChanges(func(*Change) bool { return true }, true)
+}
+
+// RejectAllChanges rejects all tracked changes in the story and returns how
+// many there were.
+func (s *Story) RejectAllChanges() int {
+	return s.resolveChanges(func(*Change) bool { return true }, false)
+}
+
+// resolveChanges accepts or rejects the changes that match, one at a time,
+// and returns how many it resolved. The text of a resolved change is either
+// unwrapped in place or removed with the element.
+func (s *Story) resolveChanges(match func(*Change) bool, accept bool) int {
+	resolved := 0
+	for {
+		var (
+			parent   *[]CharacterChild
+			index    int
+			psr, csr int
+		)
+		s.walkChildren(func(v childVisit) {
+			if c := (*v.parent)[v.index].Change; parent == nil && c != nil && match(c) {
+				parent, index, psr, csr = v.parent, v.index, v.psr, v.csr
+			}
+		})
+		if parent == nil {
+			return resolved
+		}
+
+		change := (*parent)[index].Change
+		var keep []CharacterChild
+		if accept == (change.ChangeType != ChangeDeleted) {
+			keep = change.Children
+		}
+		*parent = slices.Replace(*parent, index, index+1, keep...)
+		mergeAdjacentContent(parent)
+		s.removeEmptiedRanges(map[[2]int]bool{{psr, csr}: true}, nil)
+		resolved++
+	}
+}
+
+// Notes returns pointers to all notes in the story, in document order.
+func (s *Story) Notes() []*Note {
+	var notes []*Note
+	s.walkChildren(func(v childVisit) {
+		if note := (*v.parent)[v.index].Note; note != nil {
+			notes = append(notes, note)
+		}
+	})
+	return notes
+}
+
+// RemoveNote removes the note with the given Self ID.
+//
+// Returns common.ErrNotFound if the story has no such note.
+func (s *Story) RemoveNote(self string) error {
+	if s.removeNotes(func(n *Note) bool { return n.Self == self }) == 0 {
+		return common.WrapErrorWithPath("story", "remove note", self, common.ErrNotFound)
+	}
+	return nil
+}
+
+// RemoveAllNotes removes all notes from the story, as done before publishing,
+// and returns how many there were.
+func (s *Story) RemoveAllNotes() int {
+	return s.removeNotes(func(*Note) bool { return true })
+}
+
+// removeNotes removes the notes that match and returns how many it removed.
+func (s *Story) removeNotes(match func(*Note) bool) int {
+	removed := 0
+	emptied := make(map[[2]int]bool)
+	var remove func(children *[]CharacterChild)
+	remove = func(children *[]CharacterChild) {
+		kept := (*children)[:0]
+		for _, child := range *children {
+			if child.Note != nil && match(child.Note) {
+				removed++
+				continue
+			}
+			if nested := child.nested(); nested != nil {
+				remove(nested)
+			}
+			kept = append(kept, child)
+		}
+		*children = kept
+		mergeAdjacentContent(children)
+	}
+	for i := range s.StoryElement.ParagraphStyleRanges {
+		psr := &s.StoryElement.ParagraphStyleRanges[i]
+		for j := range psr.CharacterStyleRanges {
+			before := removed
+			remove(&psr.CharacterStyleRanges[j].Children)
+			if removed > before {
+				emptied[[2]int{i, j}] = true
+			}
+		}
+	}
+	s.removeEmptiedRanges(emptied, nil)
+	return removed
+}
+
+// UnmarshalXML implements custom unmarshaling for Change to preserve child order.
+func (c *Change) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	c.XMLName = start.Name
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "Self":
+			c.Self = attr.Value
+		case "ChangeType":
+			c.ChangeType = attr.Value
+		case "UserName":
+			c.UserName = attr.Value
+		case "Date":
+			c.Date = attr.Value
+		default:
+			c.OtherAttrs = append(c.OtherAttrs, attr)
+		}
+	}
+
+	children, err := decodeCharacterChildren(d)
+	if err != nil {
+		return err
+	}
+	c.Children = children
+	return nil
+}
+
+// MarshalXML implements custom marshaling for Change to preserve child order.
+func (c Change) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: "Change"}
+	start.Attr = appendAttrs(start.Attr,
+		"Self", c.Self,
+		"ChangeType", c.ChangeType,
+		"UserName", c.UserName,
+		"Date", c.Date,
+	)
+	start.Attr = append(start.Attr, c.OtherAttrs...)
+	return encodeContainer(e, start, c.Children)
+}
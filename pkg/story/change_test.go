@@ -0,0 +1,171 @@
+package story
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+)
+
+// trackedStoryXML is a story with an insertion, a deletion spanning a line
+// break, a change inside a hyperlink source and a note.
+const trackedStoryXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<idPkg:Story xmlns:idPkg="http://ns.adobe.com/AdobeInDesign/idml/1.0/packaging" DOMVersion="20.4">
+	<Story Self="u800" AppliedTOCStyle="n" TrackChanges="true">
+		<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/Body">
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+				<Content>The </Content>
+				<Change Self="u810" ChangeType="InsertedText" UserName="Anna" Date="2024-05-12T10:00:00">
+					<Content>quick </Content>
+				</Change>
+				<Content>fox</Content>
+				<Change Self="u811" ChangeType="DeletedText" UserName="Bo" Date="2024-05-13T09:30:00">
+					<Content> jumps</Content>
+					<Br/>
+				</Change>
+				<Note Self="u820" UserName="Anna" AppliedDocumentUser="dDocumentUser1" CreationDate="2024-05-12T10:05:00" ModificationDate="2024-05-12T10:05:00">
+					<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/$ID/NormalParagraphStyle">
+						<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+							<Content>Check the animal.</Content>
+						</CharacterStyleRange>
+					</ParagraphStyleRange>
+				</Note>
+				<Content>.</Content>
+				<HyperlinkTextSource Self="u830" Name="Link" Hidden="false">
+					<Content>Read</Content>
+					<Change Self="u812" ChangeType="InsertedText" UserName="Bo" Date="2024-05-13T09:31:00">
+						<Content> more</Content>
+					</Change>
+				</HyperlinkTextSource>
+			</CharacterStyleRange>
+		</ParagraphStyleRange>
+	</Story>
+</idPkg:Story>`
+
+// TestParseStory_Changes tests that tracked changes and notes are parsed.
+func TestParseStory_Changes(t *testing.T) {
+	st, err := ParseStory([]byte(trackedStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+
+	if got, want := st.ExtractText(), "The quick fox jumps\n.Read more"; got != want {
+		t.Errorf("ExtractText() = %q, want %q", got, want)
+	}
+
+	changes := st.Changes()
+	if len(changes) != 3 {
+		t.Fatalf("Changes() returned %d changes, want 3", len(changes))
+	}
+	inserted := changes[0]
+	if inserted.Change.ChangeType != ChangeInserted || inserted.Change.UserName != "Anna" || inserted.Text != "quick " || inserted.Range.Start != 4 {
+		t.Errorf("changes[0] = %+v", inserted)
+	}
+	if date, err := inserted.Change.Time(); err != nil || date.Day() != 12 || date.Hour() != 10 {
+		t.Errorf("Time() = %v, %v", date, err)
+	}
+	if changes[1].Text != " jumps\n" || changes[2].Text != " more" {
+		t.Errorf("changes = %+v", changes)
+	}
+
+	notes := st.Notes()
+	if len(notes) != 1 || notes[0].Text() != "Check the animal." || notes[0].AppliedDocumentUser != "dDocumentUser1" {
+		t.Errorf("Notes() = %+v", notes)
+	}
+
+	first, err := MarshalStory(st)
+	if err != nil {
+		t.Fatalf("MarshalStory failed: %v", err)
+	}
+	reparsed, err := ParseStory(first)
+	if err != nil {
+		t.Fatalf("ParseStory (second) failed: %v", err)
+	}
+	second, err := MarshalStory(reparsed)
+	if err != nil {
+		t.Fatalf("MarshalStory (second) failed: %v", err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Errorf("roundtrip output differs:\nfirst:\n%s\nsecond:\n%s", first, second)
+	}
+	if !strings.Contains(string(first), `<Change Self="u811" ChangeType="DeletedText" UserName="Bo" Date="2024-05-13T09:30:00">`) {
+		t.Errorf("output missing the deleted text change:\n%s", first)
+	}
+}
+
+// TestAcceptRejectChanges tests resolving tracked changes.
+func TestAcceptRejectChanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		resolve func(st *Story) int
+		want    string
+	}{
+		{"accept all", (*Story).AcceptAllChanges, "The quick fox.Read more"},
+		{"reject all", (*Story).RejectAllChanges, "The fox jumps\n.Read"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, err := ParseStory([]byte(trackedStoryXML))
+			if err != nil {
+				t.Fatalf("ParseStory failed: %v", err)
+			}
+			if n := tt.resolve(st); n != 3 {
+				t.Errorf("resolved %d changes, want 3", n)
+			}
+			if got := st.ExtractText(); got != tt.want {
+				t.Errorf("ExtractText() = %q, want %q", got, tt.want)
+			}
+			if len(st.Changes()) != 0 {
+				t.Errorf("Changes() = %+v, want none", st.Changes())
+			}
+			if sources := st.LinkSources(); len(sources) != 1 {
+				t.Errorf("LinkSources() = %+v, want the hyperlink source kept", sources)
+			}
+		})
+	}
+
+	st, err := ParseStory([]byte(trackedStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+	if err := st.AcceptChange("u811"); err != nil {
+		t.Fatalf("AcceptChange failed: %v", err)
+	}
+	if err := st.RejectChange("u810"); err != nil {
+		t.Fatalf("RejectChange failed: %v", err)
+	}
+	if got := st.ExtractText(); got != "The fox.Read more" {
+		t.Errorf("ExtractText() = %q", got)
+	}
+	children := st.StoryElement.ParagraphStyleRanges[0].CharacterStyleRanges[0].Children
+	if children[0].Content == nil || children[0].Content.Text != "The fox" {
+		t.Errorf("first child = %+v, want the remaining text merged", children[0])
+	}
+	if err := st.AcceptChange("u810"); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("AcceptChange(u810) twice error = %v, want ErrNotFound", err)
+	}
+}
+
+// TestRemoveNotes tests removing notes.
+func TestRemoveNotes(t *testing.T) {
+	st, err := ParseStory([]byte(trackedStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+	text := st.ExtractText()
+
+	if err := st.RemoveNote("u899"); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("RemoveNote(u899) error = %v, want ErrNotFound", err)
+	}
+	if n := st.RemoveAllNotes(); n != 1 {
+		t.Errorf("RemoveAllNotes() = %d, want 1", n)
+	}
+	if len(st.Notes()) != 0 {
+		t.Errorf("Notes() = %+v, want none", st.Notes())
+	}
+	if got := st.ExtractText(); got != text {
+		t.Errorf("ExtractText() = %q, want %q", got, text)
+	}
+}
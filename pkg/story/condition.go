@@ -0,0 +1,174 @@
+package story
+
+import (
+	"encoding/xml"
+	"slices"
+	"strings"
+)
+
+// ConditionalRange is a run of story text with the same applied conditions.
+type ConditionalRange struct {
+	Range      TextRange // position in the flattened story text
+	Conditions []string  // Selfs of the applied conditions (e.g., "Condition/Norway")
+	Text       string
+}
+
+// AppliedConditions returns the Selfs of the conditions applied to the range,
+// or nil if the text is unconditional.
+func (c *CharacterStyleRange) AppliedConditions() []string {
+	for _, attr := range c.OtherAttrs {
+		if attr.Name.Local == "AppliedConditions" {
+			return strings.Fields(attr.Value)
+		}
+	}
+	return nil
+}
+
+// SetAppliedConditions replaces the conditions applied to the range.
+// An empty list makes the text unconditional.
+func (c *CharacterStyleRange) SetAppliedConditions(conditions []string) {
+	c.OtherAttrs = slices.DeleteFunc(c.OtherAttrs, func(attr xml.Attr) bool {
+		return attr.Name.Local == "AppliedConditions"
+	})
+	if len(conditions) > 0 {
+		c.OtherAttrs = append(c.OtherAttrs, xml.Attr{
+			Name:  xml.Name{Local: "AppliedConditions"},
+			Value: strings.Join(conditions, " "),
+		})
+	}
+}
+
+// conditionsVisible reports whether text with the given conditions is shown:
+// unconditional text always is, conditional text when at least one of its
+// conditions is visible.
+func conditionsVisible(conditions []string, visible map[string]bool) bool {
+	if len(conditions) == 0 {
+		return true
+	}
+	for _, id := range conditions {
+		if visible[id] {
+			return true
+		}
+	}
+	return false
+}
+
+// ConditionalRanges returns the runs of conditional text in the story, in
+// document order. Neighboring character style ranges with the same conditions
+// form a single run.
+func (s *Story) ConditionalRanges() []ConditionalRange {
+	text := s.ExtractText()
+	var ranges []ConditionalRange
+	pos := 0
+	for _, psr := range s.StoryElement.ParagraphStyleRanges {
+		for j := range psr.CharacterStyleRanges {
+			csr := &psr.CharacterStyleRanges[j]
+			end := pos + childrenTextLen(csr.Children)
+			conditions := csr.AppliedConditions()
+			if len(conditions) > 0 && end > pos {
+				if n := len(ranges); n > 0 && ranges[n-1].Range.End == pos && slices.Equal(ranges[n-1].Conditions, conditions) {
+					ranges[n-1].Range.End = end
+					ranges[n-1].Text = text[ranges[n-1].Range.Start:end]
+				} else {
+					ranges = append(ranges, ConditionalRange{Range: TextRange{Start: pos, End: end}, Conditions: conditions, Text: text[pos:end]})
+				}
+			}
+			pos = end
+		}
+	}
+	return ranges
+}
+
+// RangesWithCondition returns the ranges of story text that carry the
+// condition with the given Self, in document order.
+func (s *Story) RangesWithCondition(conditionID string) []TextRange {
+	var ranges []TextRange
+	for _, r := range s.ConditionalRanges() {
+		if slices.Contains(r.Conditions, conditionID) {
+			ranges = append(ranges, r.Range)
+		}
+	}
+	return ranges
+}
+
+// ExtractTextWithConditions returns the story text as shown when the
+// conditions in visible are shown and all others are hidden. Keys are
+// condition Selfs; see document.Document.VisibleConditions and
+// document.ConditionSet.Conditions.
+//
+// Offsets into the returned text do not match the editing offsets, which
+// always refer to the full text returned by ExtractText.
+func (s *Story) ExtractTextWithConditions(visible map[string]bool) string {
+	if visible == nil {
+		visible = map[string]bool{}
+	}
+	return s.ExtractTextWithOptions(TextOptions{VisibleConditions: visible})
+}
+
+// FlattenConditions removes the text of character style ranges whose
+// conditions are all hidden and makes the remaining text unconditional, as
+// needed to produce one variant of a conditional document. Footnotes and
+// table cells are flattened too. Paragraphs left empty are removed; a story
+// whose text is hidden entirely keeps its first paragraph, without text.
+//
+// It returns the number of character style ranges removed.
+func (s *Story) FlattenConditions(visible map[string]bool) int {
+	var removed int
+	ranges := s.StoryElement.ParagraphStyleRanges
+	if len(ranges) == 0 {
+		return 0
+	}
+	first := ranges[0]
+	first.CharacterStyleRanges = nil
+
+	ranges = s.flattenRanges(ranges, visible, &removed)
+	if len(ranges) == 0 {
+		ranges = append(ranges, first)
+	}
+	s.StoryElement.ParagraphStyleRanges = ranges
+	return removed
+}
+
+// flattenRanges flattens the conditions of ranges and of the footnotes and
+// tables inside them.
+func (s *Story) flattenRanges(ranges []ParagraphStyleRange, visible map[string]bool, removed *int) []ParagraphStyleRange {
+	kept := ranges[:0]
+	for _, psr := range ranges {
+		csrs := psr.CharacterStyleRanges[:0]
+		for _, csr := range psr.CharacterStyleRanges {
+			if !conditionsVisible(csr.AppliedConditions(), visible) {
+				*removed++
+				continue
+			}
+			csr.SetAppliedConditions(nil)
+			s.flattenChildren(csr.Children, visible, removed)
+			csrs = append(csrs, csr)
+		}
+		hadRanges := len(psr.CharacterStyleRanges) > 0
+		psr.CharacterStyleRanges = csrs
+		if hadRanges && len(csrs) == 0 && len(psr.OtherElements) == 0 {
+			continue
+		}
+		s.mergeAdjacentRanges(&psr)
+		kept = append(kept, psr)
+	}
+	return kept
+}
+
+// flattenChildren flattens the conditions inside footnotes and table cells
+// among children, including those in text containers.
+func (s *Story) flattenChildren(children []CharacterChild, visible map[string]bool, removed *int) {
+	for _, child := range children {
+		switch {
+		case child.Footnote != nil:
+			child.Footnote.ParagraphStyleRanges = s.flattenRanges(child.Footnote.ParagraphStyleRanges, visible, removed)
+		case child.Table != nil:
+			for i := range child.Table.Cells {
+				cell := &child.Table.Cells[i]
+				cell.ParagraphStyleRanges = s.flattenRanges(cell.ParagraphStyleRanges, visible, removed)
+			}
+		case child.nested() != nil:
+			s.flattenChildren(*child.nested(), visible, removed)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package story
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// conditionalStoryXML is a story with text for two regions, a paragraph that
+// is entirely Swedish and a conditional footnote.
+const conditionalStoryXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<idPkg:Story xmlns:idPkg="http://ns.adobe.com/AdobeInDesign/idml/1.0/packaging" DOMVersion="20.4">
+	<Story Self="u700" AppliedTOCStyle="n" TrackChanges="false">
+		<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/Body">
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+				<Content>Call </Content>
+			</CharacterStyleRange>
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]" AppliedConditions="Condition/Norway">
+				<Content>22 00 00 00</Content>
+			</CharacterStyleRange>
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]" AppliedConditions="Condition/Sweden">
+				<Content>08 00 00 00</Content>
+			</CharacterStyleRange>
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+				<Content> today.</Content>
+				<Footnote Self="u710">
+					<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/Footnote">
+						<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+							<Content>Weekdays</Content>
+						</CharacterStyleRange>
+						<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]" AppliedConditions="Condition/Norway">
+							<Content> (not Sundays)</Content>
+						</CharacterStyleRange>
+					</ParagraphStyleRange>
+				</Footnote>
+				<Br/>
+			</CharacterStyleRange>
+		</ParagraphStyleRange>
+		<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/Body">
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]" AppliedConditions="Condition/Sweden Condition/Denmark">
+				<Content>Only in Sweden and Denmark.</Content>
+			</CharacterStyleRange>
+		</ParagraphStyleRange>
+	</Story>
+</idPkg:Story>`
+
+// TestConditionalRanges tests finding the text ranges that carry conditions.
+func TestConditionalRanges(t *testing.T) {
+	st, err := ParseStory([]byte(conditionalStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+
+	ranges := st.ConditionalRanges()
+	if len(ranges) != 3 {
+		t.Fatalf("ConditionalRanges() returned %d ranges, want 3: %+v", len(ranges), ranges)
+	}
+	if ranges[0].Text != "22 00 00 00" || !slices.Equal(ranges[0].Conditions, []string{"Condition/Norway"}) {
+		t.Errorf("ranges[0] = %+v", ranges[0])
+	}
+	if ranges[2].Text != "Only in Sweden and Denmark." || len(ranges[2].Conditions) != 2 {
+		t.Errorf("ranges[2] = %+v", ranges[2])
+	}
+
+	sweden := st.RangesWithCondition("Condition/Sweden")
+	if len(sweden) != 2 || sweden[0] != ranges[1].Range || sweden[1] != ranges[2].Range {
+		t.Errorf("RangesWithCondition(Sweden) = %v", sweden)
+	}
+
+	csr := &st.StoryElement.ParagraphStyleRanges[0].CharacterStyleRanges[0]
+	csr.SetAppliedConditions([]string{"Condition/Norway"})
+	if got := st.RangesWithCondition("Condition/Norway"); len(got) != 1 || got[0].Start != 0 {
+		t.Errorf("after SetAppliedConditions, Norway ranges = %v, want one merged range", got)
+	}
+	csr.SetAppliedConditions(nil)
+	if got := csr.AppliedConditions(); got != nil {
+		t.Errorf("AppliedConditions() = %v after clearing", got)
+	}
+}
+
+// TestExtractTextWithConditions tests extracting the text of a variant.
+func TestExtractTextWithConditions(t *testing.T) {
+	st, err := ParseStory([]byte(conditionalStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		visible map[string]bool
+		want    string
+	}{
+		{"norway", map[string]bool{"Condition/Norway": true, "Condition/Sweden": false}, "Call 22 00 00 00 today.\n"},
+		{"denmark", map[string]bool{"Condition/Denmark": true}, "Call  today.\nOnly in Sweden and Denmark."},
+		{"none visible", nil, "Call  today.\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := st.ExtractTextWithConditions(tt.visible); got != tt.want {
+				t.Errorf("ExtractTextWithConditions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	got := st.ExtractTextWithOptions(TextOptions{IncludeFootnotes: true, VisibleConditions: map[string]bool{"Condition/Sweden": true}})
+	if !strings.Contains(got, "[Weekdays]") {
+		t.Errorf("footnote text = %q, want the Norwegian part hidden", got)
+	}
+}
+
+// TestFlattenConditions tests removing hidden conditional text.
+func TestFlattenConditions(t *testing.T) {
+	st, err := ParseStory([]byte(conditionalStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+
+	removed := st.FlattenConditions(map[string]bool{"Condition/Norway": true})
+	if removed != 2 {
+		t.Errorf("FlattenConditions() removed %d ranges, want 2", removed)
+	}
+	if got := st.ExtractText(); got != "Call 22 00 00 00 today.\n" {
+		t.Errorf("ExtractText() = %q", got)
+	}
+	if len(st.StoryElement.ParagraphStyleRanges) != 1 {
+		t.Errorf("got %d paragraph ranges, want the Swedish paragraph removed", len(st.StoryElement.ParagraphStyleRanges))
+	}
+	if got := st.StoryElement.ParagraphStyleRanges[0].CharacterStyleRanges; len(got) != 1 {
+		t.Errorf("got %d character ranges, want the unconditional ranges merged", len(got))
+	}
+	if got := st.ConditionalRanges(); len(got) != 0 {
+		t.Errorf("ConditionalRanges() = %+v after flattening", got)
+	}
+	notes := st.ExtractTextWithOptions(TextOptions{IncludeFootnotes: true})
+	if !strings.Contains(notes, "[Weekdays (not Sundays)]") {
+		t.Errorf("footnote text = %q", notes)
+	}
+
+	data, err := MarshalStory(st)
+	if err != nil {
+		t.Fatalf("MarshalStory failed: %v", err)
+	}
+	if strings.Contains(string(data), "AppliedConditions") {
+		t.Errorf("output still has AppliedConditions:\n%s", data)
+	}
+}
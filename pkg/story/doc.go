@@ -26,6 +26,19 @@
 //   - CharacterStyleRange: Groups text by character style with custom marshal/unmarshal
 //   - Content: Actual text content
 //   - Br: Line break element
+//   - Table: Table anchored in a CharacterStyleRange, with Row, Column and Cell children
+//   - AnchoredObject: Rectangle, TextFrame, Oval, Polygon, GraphicLine or Group anchored
+//     in a CharacterStyleRange, with its AnchoredObjectSetting
+//   - Footnote, Endnote: Footnote text and endnote references anchored in a CharacterStyleRange
+//   - HyperlinkTextSource, CrossReferenceSource: Text containers marking the source of a
+//     hyperlink or cross-reference; their text is part of the story text
+//   - TextDestination: Hyperlink text or paragraph destination anchored in the text
+//   - XMLElement: Element of the XML structure; inside a CharacterStyleRange it is a
+//     text container tagging its text, with XMLAttribute children. As a child of Story
+//     or ParagraphStyleRange it encloses whole ranges, which list it in XMLElements
+//   - BackingStory: The XML/BackingStory.xml file holding the root of the XML structure
+//   - Change: Tracked change; a text container for inserted, deleted or moved text
+//   - Note: InCopy note anchored in a CharacterStyleRange, with its own paragraph ranges
 //
 // # Usage
 //
@@ -59,12 +72,90 @@
 //	}
 //	os.WriteFile("output.xml", data, 0644)
 //
+// # Editing Text
+//
+// FindText, ReplaceText, InsertText and DeleteText operate on byte offsets into
+// the flattened text returned by ExtractText. Character style ranges are split
+// and merged as needed so that edited text keeps the paragraph and character
+// style of the surrounding run:
+//
+//	n := story.ReplaceText("Lorem", "Ipsum")
+//	story.ReplaceTextRegexp(regexp.MustCompile(`(\d+) kr`), "NOK $1")
+//	err := story.InsertText(0, "Breaking: ")
+//
+// Footnote references do not add to the flattened text. AddFootnote and
+// RemoveFootnote use the same offsets, and ExtractTextWithOptions can render
+// footnotes inline:
+//
+//	fn, err := story.AddFootnote(offset, "Source: annual report.", "ParagraphStyle/Footnote")
+//	text := story.ExtractTextWithOptions(story.TextOptions{IncludeFootnotes: true})
+//
+// # Special Characters
+//
+// InDesign writes markers such as the auto page number as <?ACE n?>
+// processing instructions, usually inside a Content element. They are kept as
+// Instruction children with InContent set and take no room in the flattened
+// text; SpecialCharacters lists them together with special Unicode spaces and
+// hyphens, and TextOptions can render them:
+//
+//	text := story.ExtractTextWithOptions(story.TextOptions{SpecialCharacters: true, PageNumber: "12"})
+//
+// # Conditional Text
+//
+// The AppliedConditions attribute of a CharacterStyleRange lists the
+// conditions of its text. ConditionalRanges reports them as text ranges,
+// ExtractTextWithConditions renders the text of one variant and
+// FlattenConditions removes hidden text from the story:
+//
+//	visible := map[string]bool{"Condition/Norway": true}
+//	text := story.ExtractTextWithConditions(visible)
+//	removed := story.FlattenConditions(visible)
+//
+// # Markdown and HTML Export
+//
+// ExportMarkdown and ExportHTML write the story for the web. MarkupOptions
+// maps paragraph and character styles to tags and hyperlink sources to URLs;
+// paragraphs end at <Br/> elements and footnotes are listed after the text:
+//
+//	html := story.ExportHTML(story.MarkupOptions{
+//	    ParagraphTags: map[string]string{"ParagraphStyle/Headline": "h1"},
+//	    CharacterTags: map[string]string{"CharacterStyle/Bold": "strong"},
+//	})
+//
+// # Markdown and HTML Import
+//
+// NewStoryFromMarkdown and NewStoryFromHTML build a story the other way
+// round. MarkupImportOptions maps tags to paragraph and character styles;
+// paragraphs are separated by <Br/> elements and footnotes take the
+// "footnote" style:
+//
+//	st := story.NewStoryFromMarkdown(md, story.MarkupImportOptions{
+//	    ParagraphStyles: map[string]string{"h1": "ParagraphStyle/Headline"},
+//	    CharacterStyles: map[string]string{"strong": "CharacterStyle/Bold"},
+//	})
+//
+// # Effective Formatting
+//
+// EffectiveFormattingAt returns the formatting of a character, layering the
+// resolved paragraph style, the attributes and Properties of the
+// ParagraphStyleRange, the resolved character style and the attributes and
+// Properties of the CharacterStyleRange:
+//
+//	f, err := st.EffectiveFormattingAt(offset, styles)
+//	fmt.Println(f.AppliedFont(), f.PointSize())
+//
 // # Custom Marshaling
 //
 // CharacterStyleRange uses custom UnmarshalXML/MarshalXML to preserve the exact
-// order of Content and Br elements, which is critical for InDesign compatibility.
+// order of Content, Br, Table, Footnote, hyperlink source, XMLElement, Change,
+// Note, anchored page item elements and processing instructions, which is
+// critical for InDesign compatibility.
 // The Children field stores mixed content in order.
 //
+// StoryElement and ParagraphStyleRange use custom UnmarshalXML/MarshalXML to
+// read the ranges inside XMLElements into their range lists and to write them
+// back inside those elements.
+//
 // # Backward Compatibility
 //
 // Helper methods GetContent(), SetContent(), and AddContent() provide backward
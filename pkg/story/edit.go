@@ -0,0 +1,472 @@
+package story
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+)
+
+// TextRange identifies a range in the flattened story text returned by ExtractText.
+// Start and End are byte offsets; End is exclusive.
+type TextRange struct {
+	Start int
+	End   int
+}
+
+// Len returns the length of the range in bytes.
+func (r TextRange) Len() int {
+	return r.End - r.Start
+}
+
+// textSegment maps a Content or Br child to its position in the flattened text.
+type textSegment struct {
+	psr, csr   int
+	parent     *[]CharacterChild // slice holding the child: the range's children or a container's
+	child      int
+	start, end int
+	isBr       bool
+}
+
+// childVisit describes a child visited by walkChildren.
+type childVisit struct {
+	psr, csr int
+	parent   *[]CharacterChild // slice holding the child
+	index    int
+	pos      int // offset of the child in the flattened text
+}
+
+// walkChildren calls fn for every child of the story's character style ranges
+// in document order, descending into text containers such as hyperlink sources
+// after visiting the container itself.
+func (s *Story) walkChildren(fn func(v childVisit)) {
+	pos := 0
+	for i := range s.StoryElement.ParagraphStyleRanges {
+		psr := &s.StoryElement.ParagraphStyleRanges[i]
+		for j := range psr.CharacterStyleRanges {
+			pos = walkRangeChildren(i, j, &psr.CharacterStyleRanges[j].Children, pos, fn)
+		}
+	}
+}
+
+// walkRangeChildren calls fn for the children of character style range csr
+// of paragraph style range psr, the first of which is at offset pos, and
+// returns the offset after them.
+func walkRangeChildren(psr, csr int, parent *[]CharacterChild, pos int, fn func(v childVisit)) int {
+	for k := range *parent {
+		child := &(*parent)[k]
+		fn(childVisit{psr: psr, csr: csr, parent: parent, index: k, pos: pos})
+		switch {
+		case child.Content != nil:
+			pos += len(child.Content.Text)
+		case child.Br != nil:
+			pos++
+		case child.nested() != nil:
+			pos = walkRangeChildren(psr, csr, child.nested(), pos, fn)
+		}
+	}
+	return pos
+}
+
+// segments returns the text segments of the story in document order.
+// Only Content and Br children contribute to the flattened text.
+func (s *Story) segments() []textSegment {
+	var segs []textSegment
+	s.walkChildren(func(v childVisit) {
+		child := (*v.parent)[v.index]
+		seg := textSegment{psr: v.psr, csr: v.csr, parent: v.parent, child: v.index, start: v.pos}
+		switch {
+		case child.Content != nil:
+			seg.end = v.pos + len(child.Content.Text)
+		case child.Br != nil:
+			seg.end, seg.isBr = v.pos+1, true
+		default:
+			return
+		}
+		segs = append(segs, seg)
+	})
+	return segs
+}
+
+// FindText returns the ranges of all non-overlapping occurrences of substr
+// in the flattened story text.
+func (s *Story) FindText(substr string) []TextRange {
+	if substr == "" {
+		return nil
+	}
+
+	text := s.ExtractText()
+	var ranges []TextRange
+	for offset := 0; ; {
+		idx := strings.Index(text[offset:], substr)
+		if idx < 0 {
+			break
+		}
+		start := offset + idx
+		ranges = append(ranges, TextRange{Start: start, End: start + len(substr)})
+		offset = start + len(substr)
+	}
+	return ranges
+}
+
+// FindTextRegexp returns the ranges of all matches of re in the flattened story text.
+func (s *Story) FindTextRegexp(re *regexp.Regexp) []TextRange {
+	if re == nil {
+		return nil
+	}
+
+	var ranges []TextRange
+	for _, m := range re.FindAllStringIndex(s.ExtractText(), -1) {
+		ranges = append(ranges, TextRange{Start: m[0], End: m[1]})
+	}
+	return ranges
+}
+
+// ReplaceText replaces all occurrences of old with replacement and returns the
+// number of replacements made.
+//
+// Replacement text takes the paragraph and character style of the run in which
+// each match starts. Newlines in replacement become <Br/> elements.
+//
+// Example:
+//
+//	st, _ := pkg.Story("Stories/Story_u1d8.xml")
+//	n := st.ReplaceText("Lorem", "Ipsum")
+//	fmt.Printf("Replaced %d occurrences\n", n)
+func (s *Story) ReplaceText(old, replacement string) int {
+	matches := s.FindText(old)
+	for i := len(matches) - 1; i >= 0; i-- {
+		s.replaceRange(matches[i].Start, matches[i].End, replacement)
+	}
+	return len(matches)
+}
+
+// ReplaceTextRegexp replaces all matches of re and returns the number of
+// replacements made. Inside replacement, $1 or ${name} are expanded as in
+// regexp.Regexp.Expand. Styles are preserved as in ReplaceText.
+func (s *Story) ReplaceTextRegexp(re *regexp.Regexp, replacement string) int {
+	if re == nil {
+		return 0
+	}
+
+	text := s.ExtractText()
+	matches := re.FindAllStringSubmatchIndex(text, -1)
+	for i := len(matches) - 1; i >= 0; i-- {
+		m := matches[i]
+		expanded := re.ExpandString(nil, replacement, text, m)
+		s.replaceRange(m[0], m[1], string(expanded))
+	}
+	return len(matches)
+}
+
+// InsertText inserts text at the given byte offset in the flattened story text.
+//
+// The inserted text takes the style of the preceding character, like typing in
+// InDesign. At the start of a paragraph it takes the style of the following
+// character instead. Newlines in text become <Br/> elements.
+func (s *Story) InsertText(offset int, text string) error {
+	if err := s.validateRange("insert text", offset, offset); err != nil {
+		return err
+	}
+	s.replaceRange(offset, offset, text)
+	return nil
+}
+
+// DeleteText removes the text in the range [start, end) of the flattened story text.
+// Character style ranges and paragraphs left empty by the deletion are removed.
+func (s *Story) DeleteText(start, end int) error {
+	if err := s.validateRange("delete text", start, end); err != nil {
+		return err
+	}
+	s.replaceRange(start, end, "")
+	return nil
+}
+
+// validateRange checks that [start, end) is a valid range on rune boundaries.
+func (s *Story) validateRange(op string, start, end int) error {
+	text := s.ExtractText()
+	if start < 0 || end < start || end > len(text) {
+		return common.Errorf("story", op, s.StoryElement.Self, "range [%d, %d) out of bounds (text length %d)", start, end, len(text))
+	}
+	for _, offset := range []int{start, end} {
+		if offset < len(text) && !utf8.RuneStart(text[offset]) {
+			return common.Errorf("story", op, s.StoryElement.Self, "offset %d is not on a character boundary", offset)
+		}
+	}
+	return nil
+}
+
+// replaceRange replaces the flattened text in [start, end) with text.
+// Offsets must be valid; callers are responsible for validation.
+func (s *Story) replaceRange(start, end int, text string) {
+	// Step 1: Make sure child boundaries exist at both ends of the range
+	s.splitContentAt(end)
+	s.splitContentAt(start)
+
+	// Step 2: Find where the new text goes
+	psrIdx, csrIdx, parent, childIdx := s.insertionPoint(start, end)
+
+	// Step 3: Remove children fully inside the range (back to front keeps indices valid)
+	segs := s.segments()
+	emptied := make(map[[2]int]bool)
+	for i := len(segs) - 1; i >= 0; i-- {
+		seg := segs[i]
+		if seg.start < start || seg.end > end || seg.start == seg.end {
+			continue
+		}
+		*seg.parent = slices.Delete(*seg.parent, seg.child, seg.child+1)
+		csr := &s.StoryElement.ParagraphStyleRanges[seg.psr].CharacterStyleRanges[seg.csr]
+		if csr.onlyProperties() {
+			emptied[[2]int{seg.psr, seg.csr}] = true
+		}
+	}
+
+	// Step 4: Insert the new text and rejoin content split in step 1
+	if text != "" {
+		*parent = slices.Insert(*parent, childIdx, textChildren(text)...)
+		delete(emptied, [2]int{psrIdx, csrIdx})
+	}
+	mergeAdjacentContent(parent)
+
+	// Step 5: Clean up ranges emptied by the deletion and merge identical neighbors
+	touched := map[int]bool{psrIdx: true}
+	for key := range emptied {
+		touched[key[0]] = true
+	}
+	for _, i := range s.removeEmptiedRanges(emptied, touched) {
+		s.mergeAdjacentRanges(&s.StoryElement.ParagraphStyleRanges[i])
+	}
+}
+
+// splitContentAt splits the Content child containing offset so that a child boundary
+// exists at offset. Does nothing if offset is already on a boundary.
+func (s *Story) splitContentAt(offset int) {
+	for _, seg := range s.segments() {
+		if seg.isBr || offset <= seg.start || offset >= seg.end {
+			continue
+		}
+		text := (*seg.parent)[seg.child].Content.Text
+		cut := offset - seg.start
+		(*seg.parent)[seg.child] = newContentChild(text[:cut])
+		*seg.parent = slices.Insert(*seg.parent, seg.child+1, newContentChild(text[cut:]))
+		return
+	}
+}
+
+// insertionPoint returns the character style range, the children slice and the
+// index in it where text replacing [start, end) is inserted. The slice is the
+// range's own children or those of a text container inside it. Content
+// boundaries must already exist at start.
+func (s *Story) insertionPoint(start, end int) (psrIdx, csrIdx int, parent *[]CharacterChild, childIdx int) {
+	segs := s.segments()
+
+	// For insertions, prefer the style of the preceding character unless it ends a paragraph
+	if start == end {
+		for _, seg := range segs {
+			if seg.end == start && !seg.isBr && seg.start < seg.end {
+				return seg.psr, seg.csr, seg.parent, seg.child + 1
+			}
+		}
+	}
+
+	// Otherwise use the run of the first character at start
+	for _, seg := range segs {
+		if seg.start == start && seg.start < seg.end {
+			return seg.psr, seg.csr, seg.parent, seg.child
+		}
+	}
+
+	// Insertion at the end of the story: append after the last text child
+	if len(segs) > 0 {
+		last := segs[len(segs)-1]
+		return last.psr, last.csr, last.parent, last.child + 1
+	}
+
+	// No text at all: use (or create) the first character style range
+	if len(s.StoryElement.ParagraphStyleRanges) == 0 {
+		s.StoryElement.ParagraphStyleRanges = append(s.StoryElement.ParagraphStyleRanges, ParagraphStyleRange{
+			XMLName:               xml.Name{Local: "ParagraphStyleRange"},
+			AppliedParagraphStyle: "ParagraphStyle/$ID/NormalParagraphStyle",
+		})
+	}
+	psr := &s.StoryElement.ParagraphStyleRanges[0]
+	if len(psr.CharacterStyleRanges) == 0 {
+		psr.CharacterStyleRanges = append(psr.CharacterStyleRanges, NewCharacterStyleRange("", nil))
+	}
+	return 0, 0, &psr.CharacterStyleRanges[0].Children, 0
+}
+
+// removeEmptiedRanges removes character style ranges that became empty during an
+// edit, and paragraph style ranges left without any character style range.
+// It returns the new indices of the touched paragraph style ranges that remain.
+func (s *Story) removeEmptiedRanges(emptied map[[2]int]bool, touched map[int]bool) []int {
+	var remaining []int
+	psrs := s.StoryElement.ParagraphStyleRanges[:0]
+	for i, psr := range s.StoryElement.ParagraphStyleRanges {
+		csrs := psr.CharacterStyleRanges[:0]
+		removed := false
+		for j, csr := range psr.CharacterStyleRanges {
+			if emptied[[2]int{i, j}] && csr.onlyProperties() {
+				removed = true
+				continue
+			}
+			csrs = append(csrs, csr)
+		}
+		psr.CharacterStyleRanges = csrs
+		if removed && len(csrs) == 0 && len(psr.OtherElements) == 0 {
+			continue
+		}
+		if touched[i] {
+			remaining = append(remaining, len(psrs))
+		}
+		psrs = append(psrs, psr)
+	}
+	s.StoryElement.ParagraphStyleRanges = psrs
+	return remaining
+}
+
+// mergeAdjacentRanges merges neighboring character style ranges with identical formatting.
+func (s *Story) mergeAdjacentRanges(psr *ParagraphStyleRange) {
+	merged := psr.CharacterStyleRanges[:0]
+	for _, csr := range psr.CharacterStyleRanges {
+		if n := len(merged); n > 0 && sameCharacterFormatting(&merged[n-1], &csr) {
+			// The Properties of both ranges are equal; keep only the first
+			for _, child := range csr.Children {
+				if !isPropertiesChild(child) {
+					merged[n-1].Children = append(merged[n-1].Children, child)
+				}
+			}
+			mergeAdjacentContent(&merged[n-1].Children)
+			continue
+		}
+		merged = append(merged, csr)
+	}
+	psr.CharacterStyleRanges = merged
+}
+
+// sameCharacterFormatting reports whether two character style ranges have
+// identical attributes and Properties children, such as AppliedFont and
+// Leading, and lie inside the same XMLElements.
+func sameCharacterFormatting(a, b *CharacterStyleRange) bool {
+	if a.AppliedCharacterStyle != b.AppliedCharacterStyle ||
+		a.HorizontalScale != b.HorizontalScale ||
+		a.Tracking != b.Tracking ||
+		len(a.OtherAttrs) != len(b.OtherAttrs) ||
+		!slices.Equal(a.XMLElements, b.XMLElements) {
+		return false
+	}
+
+	attrs := make(map[xml.Name]string, len(a.OtherAttrs))
+	for _, attr := range a.OtherAttrs {
+		attrs[attr.Name] = attr.Value
+	}
+	for _, attr := range b.OtherAttrs {
+		if v, ok := attrs[attr.Name]; !ok || v != attr.Value {
+			return false
+		}
+	}
+
+	aProps, bProps := a.propertiesElements(), b.propertiesElements()
+	if len(aProps) != len(bProps) {
+		return false
+	}
+	for i := range aProps {
+		aKey, err := canonicalElement(&aProps[i])
+		if err != nil {
+			return false
+		}
+		bKey, err := canonicalElement(&bProps[i])
+		if err != nil || aKey != bKey {
+			return false
+		}
+	}
+	return true
+}
+
+// canonicalElement returns the markup of an element without the whitespace
+// between its children, so that elements indented differently compare equal.
+func canonicalElement(elem *common.RawXMLElement) (string, error) {
+	data, err := xml.Marshal(elem)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return b.String(), nil
+		}
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			b.WriteString("<" + t.Name.Local)
+			for _, attr := range t.Attr {
+				b.WriteString(" " + attr.Name.Local + "=" + strconv.Quote(attr.Value))
+			}
+			b.WriteString(">")
+		case xml.EndElement:
+			b.WriteString("</" + t.Name.Local + ">")
+		case xml.CharData:
+			b.WriteString(strings.TrimSpace(string(t)))
+		}
+	}
+}
+
+// onlyProperties reports whether the range holds no children other than
+// Properties, and so no text or anchored content.
+func (c *CharacterStyleRange) onlyProperties() bool {
+	for _, child := range c.Children {
+		if !isPropertiesChild(child) {
+			return false
+		}
+	}
+	return true
+}
+
+// isPropertiesChild reports whether a child is the Properties element of a
+// character style range.
+func isPropertiesChild(child CharacterChild) bool {
+	return child.Other != nil && child.Other.XMLName.Local == "Properties"
+}
+
+// mergeAdjacentContent joins consecutive Content children of a character style
+// range or text container.
+func mergeAdjacentContent(children *[]CharacterChild) {
+	merged := (*children)[:0]
+	for _, child := range *children {
+		if n := len(merged); n > 0 && child.Content != nil && merged[n-1].Content != nil {
+			merged[n-1] = newContentChild(merged[n-1].Content.Text + child.Content.Text)
+			continue
+		}
+		merged = append(merged, child)
+	}
+	*children = merged
+}
+
+// textChildren converts text into Content children, turning newlines into Br elements.
+func textChildren(text string) []CharacterChild {
+	var children []CharacterChild
+	for i, line := range strings.Split(text, "\n") {
+		if i > 0 {
+			children = append(children, CharacterChild{Br: &Br{XMLName: xml.Name{Local: "Br"}}})
+		}
+		if line != "" {
+			children = append(children, newContentChild(line))
+		}
+	}
+	return children
+}
+
+// newContentChild creates a CharacterChild holding a Content element.
+func newContentChild(text string) CharacterChild {
+	return CharacterChild{Content: &Content{XMLName: xml.Name{Local: "Content"}, Text: text}}
+}
@@ -0,0 +1,354 @@
+package story
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/dimelords/idmllib/v2/internal/testutil"
+	"github.com/google/go-cmp/cmp"
+)
+
+// editStoryXML has two paragraphs; the first mixes a plain and a bold run.
+const editStoryXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<idPkg:Story xmlns:idPkg="http://ns.adobe.com/AdobeInDesign/idml/1.0/packaging" DOMVersion="20.4">
+	<Story Self="u200">
+		<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/Body">
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+				<Content>Hello </Content>
+			</CharacterStyleRange>
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/Bold" PointSize="12">
+				<Content>brave</Content>
+			</CharacterStyleRange>
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+				<Content> world</Content>
+				<Br/>
+			</CharacterStyleRange>
+		</ParagraphStyleRange>
+		<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/Caption">
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+				<Content>Second world</Content>
+			</CharacterStyleRange>
+		</ParagraphStyleRange>
+	</Story>
+</idPkg:Story>`
+
+// parseEditStory parses editStoryXML.
+func parseEditStory(t *testing.T) *Story {
+	t.Helper()
+	st, err := ParseStory([]byte(editStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+	return st
+}
+
+// runStyles returns "style:text" for each character style range in the story.
+func runStyles(st *Story) []string {
+	var runs []string
+	for _, psr := range st.StoryElement.ParagraphStyleRanges {
+		for _, csr := range psr.CharacterStyleRanges {
+			text := ""
+			for _, child := range csr.Children {
+				if child.Content != nil {
+					text += child.Content.Text
+				} else if child.Br != nil {
+					text += "\n"
+				}
+			}
+			runs = append(runs, psr.AppliedParagraphStyle+"|"+csr.AppliedCharacterStyle+":"+text)
+		}
+	}
+	return runs
+}
+
+// TestStory_FindText tests literal and regexp search on flattened text.
+func TestStory_FindText(t *testing.T) {
+	st := parseEditStory(t)
+
+	got := st.FindText("world")
+	want := []TextRange{{Start: 12, End: 17}, {Start: 25, End: 30}}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("FindText mismatch (-want +got):\n%s", diff)
+	}
+
+	// Matches spanning runs are found as well
+	if got := st.FindText("o brave w"); len(got) != 1 || got[0].Start != 4 {
+		t.Errorf("FindText across runs = %v", got)
+	}
+
+	if got := st.FindText(""); got != nil {
+		t.Errorf("FindText(\"\") = %v, want nil", got)
+	}
+
+	got = st.FindTextRegexp(regexp.MustCompile(`w\w+`))
+	if len(got) != 2 || got[0].Len() != 5 {
+		t.Errorf("FindTextRegexp = %v", got)
+	}
+}
+
+// TestStory_ReplaceText_WithinRun tests replacement inside a single run keeps its style.
+func TestStory_ReplaceText_WithinRun(t *testing.T) {
+	st := parseEditStory(t)
+
+	if n := st.ReplaceText("brave", "bold new"); n != 1 {
+		t.Fatalf("ReplaceText returned %d, want 1", n)
+	}
+
+	want := []string{
+		"ParagraphStyle/Body|CharacterStyle/$ID/[No character style]:Hello ",
+		"ParagraphStyle/Body|CharacterStyle/Bold:bold new",
+		"ParagraphStyle/Body|CharacterStyle/$ID/[No character style]: world\n",
+		"ParagraphStyle/Caption|CharacterStyle/$ID/[No character style]:Second world",
+	}
+	if diff := cmp.Diff(want, runStyles(st)); diff != "" {
+		t.Errorf("runs mismatch (-want +got):\n%s", diff)
+	}
+
+	// Local overrides of the run must be kept
+	bold := st.StoryElement.ParagraphStyleRanges[0].CharacterStyleRanges[1]
+	if len(bold.OtherAttrs) != 1 || bold.OtherAttrs[0].Value != "12" {
+		t.Errorf("OtherAttrs = %v, want PointSize=12", bold.OtherAttrs)
+	}
+}
+
+// TestStory_ReplaceText_AcrossRuns tests that matches spanning runs take the first run's style.
+func TestStory_ReplaceText_AcrossRuns(t *testing.T) {
+	st := parseEditStory(t)
+
+	if n := st.ReplaceText("Hello brave", "Hi"); n != 1 {
+		t.Fatalf("ReplaceText returned %d, want 1", n)
+	}
+
+	if got := st.ExtractText(); got != "Hi world\nSecond world" {
+		t.Errorf("ExtractText() = %q", got)
+	}
+
+	// The bold run is now empty and removed; the remaining plain runs are merged
+	want := []string{
+		"ParagraphStyle/Body|CharacterStyle/$ID/[No character style]:Hi world\n",
+		"ParagraphStyle/Caption|CharacterStyle/$ID/[No character style]:Second world",
+	}
+	if diff := cmp.Diff(want, runStyles(st)); diff != "" {
+		t.Errorf("runs mismatch (-want +got):\n%s", diff)
+	}
+	if n := len(st.StoryElement.ParagraphStyleRanges[0].CharacterStyleRanges[0].Children); n != 2 {
+		t.Errorf("merged run has %d children, want Content + Br", n)
+	}
+}
+
+// TestStory_ReplaceText_All tests replacing every occurrence in different paragraphs.
+func TestStory_ReplaceText_All(t *testing.T) {
+	st := parseEditStory(t)
+
+	if n := st.ReplaceText("world", "planet"); n != 2 {
+		t.Fatalf("ReplaceText returned %d, want 2", n)
+	}
+	if got := st.ExtractText(); got != "Hello brave planet\nSecond planet" {
+		t.Errorf("ExtractText() = %q", got)
+	}
+	if n := st.ReplaceText("missing", "x"); n != 0 {
+		t.Errorf("ReplaceText of missing text returned %d", n)
+	}
+}
+
+// TestStory_ReplaceTextRegexp tests regexp replacement with group expansion.
+func TestStory_ReplaceTextRegexp(t *testing.T) {
+	st := parseEditStory(t)
+
+	n := st.ReplaceTextRegexp(regexp.MustCompile(`(\w+) world`), "${1} earth")
+	if n != 2 {
+		t.Fatalf("ReplaceTextRegexp returned %d, want 2", n)
+	}
+	if got := st.ExtractText(); got != "Hello brave earth\nSecond earth" {
+		t.Errorf("ExtractText() = %q", got)
+	}
+
+	// "brave earth" started in the bold run
+	if got := runStyles(st)[1]; got != "ParagraphStyle/Body|CharacterStyle/Bold:brave earth" {
+		t.Errorf("bold run = %q", got)
+	}
+}
+
+// TestStory_InsertText tests insertion style inheritance and line breaks.
+func TestStory_InsertText(t *testing.T) {
+	st := parseEditStory(t)
+
+	// After "brave" the preceding character is bold
+	if err := st.InsertText(11, "st"); err != nil {
+		t.Fatalf("InsertText failed: %v", err)
+	}
+	// At the start of the second paragraph the following character's style applies
+	if err := st.InsertText(20, "A\n"); err != nil {
+		t.Fatalf("InsertText failed: %v", err)
+	}
+	// At the very end of the story
+	if err := st.InsertText(len(st.ExtractText()), "!"); err != nil {
+		t.Fatalf("InsertText failed: %v", err)
+	}
+
+	if got := st.ExtractText(); got != "Hello bravest world\nA\nSecond world!" {
+		t.Errorf("ExtractText() = %q", got)
+	}
+
+	want := []string{
+		"ParagraphStyle/Body|CharacterStyle/$ID/[No character style]:Hello ",
+		"ParagraphStyle/Body|CharacterStyle/Bold:bravest",
+		"ParagraphStyle/Body|CharacterStyle/$ID/[No character style]: world\n",
+		"ParagraphStyle/Caption|CharacterStyle/$ID/[No character style]:A\nSecond world!",
+	}
+	if diff := cmp.Diff(want, runStyles(st)); diff != "" {
+		t.Errorf("runs mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := st.InsertText(-1, "x"); err == nil {
+		t.Error("InsertText with negative offset should fail")
+	}
+	if err := st.InsertText(1000, "x"); err == nil {
+		t.Error("InsertText past the end should fail")
+	}
+}
+
+// TestStory_InsertText_Empty tests inserting into a story without content.
+func TestStory_InsertText_Empty(t *testing.T) {
+	st := &Story{}
+
+	if err := st.InsertText(0, "New"); err != nil {
+		t.Fatalf("InsertText failed: %v", err)
+	}
+	if got := st.ExtractText(); got != "New" {
+		t.Errorf("ExtractText() = %q, want New", got)
+	}
+}
+
+// TestStory_DeleteText tests deletion of a whole paragraph and invalid ranges.
+func TestStory_DeleteText(t *testing.T) {
+	st := parseEditStory(t)
+
+	// Delete the complete first paragraph including its Br
+	if err := st.DeleteText(0, 18); err != nil {
+		t.Fatalf("DeleteText failed: %v", err)
+	}
+	if got := st.ExtractText(); got != "Second world" {
+		t.Errorf("ExtractText() = %q", got)
+	}
+	if n := len(st.StoryElement.ParagraphStyleRanges); n != 1 {
+		t.Errorf("paragraph ranges = %d, want 1", n)
+	}
+
+	if err := st.DeleteText(5, 2); err == nil {
+		t.Error("DeleteText with inverted range should fail")
+	}
+}
+
+// TestStory_ReplaceText_Roundtrip tests that edited stories marshal and parse again.
+func TestStory_ReplaceText_Roundtrip(t *testing.T) {
+	st, err := ParseStory(testutil.ReadTestData(t, "story_u1d8.xml"))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+
+	before := st.ExtractText()
+	matches := st.FindText("Lorem")
+	if len(matches) == 0 {
+		t.Skip("test story has no occurrences of Lorem")
+	}
+
+	st.ReplaceText("Lorem", "Lørem")
+
+	data, err := MarshalStory(st)
+	if err != nil {
+		t.Fatalf("MarshalStory failed: %v", err)
+	}
+	reparsed, err := ParseStory(data)
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+
+	want := regexp.MustCompile("Lorem").ReplaceAllString(before, "Lørem")
+	if got := reparsed.ExtractText(); got != want {
+		t.Errorf("text after roundtrip differs:\nwant %q\ngot  %q", want, got)
+	}
+}
+
+// fontStoryXML has runs of the same character style with different local
+// fonts in Properties.
+const fontStoryXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<idPkg:Story xmlns:idPkg="http://ns.adobe.com/AdobeInDesign/idml/1.0/packaging" DOMVersion="20.4">
+	<Story Self="u201">
+		<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/Body">
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+				<Properties>
+					<AppliedFont type="string">Minion Pro</AppliedFont>
+				</Properties>
+				<Content>Hello </Content>
+			</CharacterStyleRange>
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/Bold">
+				<Content>brave</Content>
+			</CharacterStyleRange>
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+				<Properties>
+					<AppliedFont type="string">Myriad Pro</AppliedFont>
+				</Properties>
+				<Content> world</Content>
+			</CharacterStyleRange>
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/Bold">
+				<Content>!</Content>
+			</CharacterStyleRange>
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+				<Properties>
+					<AppliedFont type="string">Myriad Pro</AppliedFont>
+				</Properties>
+				<Content> again</Content>
+			</CharacterStyleRange>
+		</ParagraphStyleRange>
+	</Story>
+</idPkg:Story>`
+
+// TestStory_DeleteText_KeepsDifferentFonts tests that neighboring runs are
+// only merged when their Properties, such as AppliedFont, are equal.
+func TestStory_DeleteText_KeepsDifferentFonts(t *testing.T) {
+	st, err := ParseStory([]byte(fontStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+
+	// Removing "brave" makes the Minion Pro and Myriad Pro runs neighbors
+	if n := st.ReplaceText("brave", ""); n != 1 {
+		t.Fatalf("ReplaceText returned %d, want 1", n)
+	}
+	// Removing "!" makes the two Myriad Pro runs neighbors
+	if n := st.ReplaceText("!", ""); n != 1 {
+		t.Fatalf("ReplaceText returned %d, want 1", n)
+	}
+
+	csrs := st.StoryElement.ParagraphStyleRanges[0].CharacterStyleRanges
+	if len(csrs) != 2 {
+		t.Fatalf("runs = %v, want Minion Pro and Myriad Pro runs", runStyles(st))
+	}
+	for i, want := range []struct{ font, text string }{{"Minion Pro", "Hello "}, {"Myriad Pro", " world again"}} {
+		csr := &csrs[i]
+		if got := csr.LocalFormatting()["AppliedFont"]; got != want.font {
+			t.Errorf("run %d AppliedFont = %q, want %q", i, got, want.font)
+		}
+		if n := len(csr.propertiesElements()); n != 1 {
+			t.Errorf("run %d has %d Properties elements, want 1", i, n)
+		}
+		text := ""
+		for _, child := range csr.Children {
+			if child.Content != nil {
+				text += child.Content.Text
+			}
+		}
+		if text != want.text {
+			t.Errorf("run %d text = %q, want %q", i, text, want.text)
+		}
+	}
+
+	// A run left with only its Properties is removed
+	if err := st.DeleteText(0, len("Hello ")); err != nil {
+		t.Fatalf("DeleteText failed: %v", err)
+	}
+	if csrs := st.StoryElement.ParagraphStyleRanges[0].CharacterStyleRanges; len(csrs) != 1 {
+		t.Errorf("runs = %v, want the Myriad Pro run only", runStyles(st))
+	}
+}
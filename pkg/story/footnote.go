@@ -0,0 +1,175 @@
+package story
+
+import (
+	"encoding/xml"
+	"slices"
+	"strings"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+)
+
+// footnoteMarkerTarget and footnoteMarkerInst form the <?ACE 4?> processing
+// instruction InDesign writes where a footnote's number appears in its text.
+const (
+	footnoteMarkerTarget = "ACE"
+	footnoteMarkerInst   = "4"
+)
+
+// Footnote is a footnote anchored in a CharacterStyleRange. The reference
+// number appears in the story text at the position of the element; the
+// footnote text lives in its own paragraph style ranges and starts with the
+// <?ACE 4?> footnote number marker.
+//
+// Numbering, numbering style and the separator after the number come from the
+// document's footnote options, which are left untouched.
+type Footnote struct {
+	XMLName xml.Name `xml:"Footnote"`
+
+	// Identity (InDesign does not always write one)
+	Self string `xml:"Self,attr,omitempty"`
+
+	// Additional attributes (catch-all)
+	OtherAttrs []xml.Attr `xml:",any,attr"`
+
+	// Footnote text
+	ParagraphStyleRanges []ParagraphStyleRange `xml:"ParagraphStyleRange"`
+
+	// Catch-all for unknown elements
+	OtherElements []common.RawXMLElement `xml:",any"`
+}
+
+// Text returns the footnote text without the number marker and trailing line breaks.
+func (f *Footnote) Text() string {
+	var buf strings.Builder
+	writeRangesText(&buf, f.ParagraphStyleRanges)
+	return strings.TrimRight(buf.String(), "\n")
+}
+
+// Endnote is an endnote reference anchored in a CharacterStyleRange. The
+// endnote text lives in the document's endnote story (the story with
+// IsEndnoteStory="true"), and the endnote options in designmap.xml
+// (EndnoteOption) are left untouched.
+type Endnote struct {
+	XMLName xml.Name `xml:"Endnote"`
+
+	// Identity
+	Self string `xml:"Self,attr,omitempty"`
+
+	// Additional attributes (catch-all)
+	OtherAttrs []xml.Attr `xml:",any,attr"`
+
+	// Catch-all for child elements
+	OtherElements []common.RawXMLElement `xml:",any"`
+}
+
+// isFootnoteMarker reports whether pi is the <?ACE 4?> footnote number marker.
+func isFootnoteMarker(pi *xml.ProcInst) bool {
+	return pi.Target == footnoteMarkerTarget && strings.TrimSpace(string(pi.Inst)) == footnoteMarkerInst
+}
+
+// newFootnoteMarker returns the footnote number marker that starts the text
+// of a footnote.
+func newFootnoteMarker() CharacterChild {
+	return CharacterChild{Instruction: &xml.ProcInst{
+		Target: footnoteMarkerTarget,
+		Inst:   []byte(footnoteMarkerInst),
+	}}
+}
+
+// Footnotes returns pointers to all footnotes in the story, in document order.
+func (s *Story) Footnotes() []*Footnote {
+	var footnotes []*Footnote
+	for _, ref := range s.footnoteRefs() {
+		footnotes = append(footnotes, ref.footnote)
+	}
+	return footnotes
+}
+
+// Endnotes returns pointers to all endnote references in the story, in document order.
+func (s *Story) Endnotes() []*Endnote {
+	var endnotes []*Endnote
+	for i := range s.StoryElement.ParagraphStyleRanges {
+		psr := &s.StoryElement.ParagraphStyleRanges[i]
+		for j := range psr.CharacterStyleRanges {
+			for _, child := range psr.CharacterStyleRanges[j].FlatChildren() {
+				if child.Endnote != nil {
+					endnotes = append(endnotes, child.Endnote)
+				}
+			}
+		}
+	}
+	return endnotes
+}
+
+// footnoteRef locates a footnote in the story.
+type footnoteRef struct {
+	parent   *[]CharacterChild // slice holding the footnote
+	child    int
+	offset   int // position of the reference in the flattened text
+	footnote *Footnote
+}
+
+// footnoteRefs returns the footnotes of the story with their positions.
+func (s *Story) footnoteRefs() []footnoteRef {
+	var refs []footnoteRef
+	s.walkChildren(func(v childVisit) {
+		if fn := (*v.parent)[v.index].Footnote; fn != nil {
+			refs = append(refs, footnoteRef{parent: v.parent, child: v.index, offset: v.pos, footnote: fn})
+		}
+	})
+	return refs
+}
+
+// AddFootnote inserts a footnote reference at the given byte offset in the
+// flattened story text and returns the new footnote.
+//
+// The footnote holds a single paragraph in paragraphStyle (the normal
+// paragraph style if empty) starting with the footnote number marker.
+// Newlines in text become <Br/> elements. The reference takes the character
+// style of the preceding character, like text inserted with InsertText.
+//
+// Example:
+//
+//	offset := st.FindText("claim")[0].End
+//	fn, err := st.AddFootnote(offset, "Source: annual report 2024.", "ParagraphStyle/Footnote")
+func (s *Story) AddFootnote(offset int, text, paragraphStyle string) (*Footnote, error) {
+	if err := s.validateRange("add footnote", offset, offset); err != nil {
+		return nil, err
+	}
+	if paragraphStyle == "" {
+		paragraphStyle = "ParagraphStyle/$ID/NormalParagraphStyle"
+	}
+
+	csr := NewCharacterStyleRange("", nil)
+	csr.Children = append([]CharacterChild{newFootnoteMarker()}, textChildren(text)...)
+	footnote := &Footnote{
+		XMLName: xml.Name{Local: "Footnote"},
+		ParagraphStyleRanges: []ParagraphStyleRange{{
+			XMLName:               xml.Name{Local: "ParagraphStyleRange"},
+			AppliedParagraphStyle: paragraphStyle,
+			CharacterStyleRanges:  []CharacterStyleRange{csr},
+		}},
+	}
+
+	s.splitContentAt(offset)
+	_, _, parent, childIdx := s.insertionPoint(offset, offset)
+	*parent = slices.Insert(*parent, childIdx, CharacterChild{Footnote: footnote})
+	return footnote, nil
+}
+
+// RemoveFootnote removes the footnote referenced at the given byte offset in
+// the flattened story text. If several footnotes are referenced at the same
+// offset, the first one is removed.
+//
+// Returns common.ErrNotFound if no footnote is referenced at offset.
+func (s *Story) RemoveFootnote(offset int) error {
+	for _, ref := range s.footnoteRefs() {
+		if ref.offset != offset {
+			continue
+		}
+		*ref.parent = slices.Delete(*ref.parent, ref.child, ref.child+1)
+		mergeAdjacentContent(ref.parent)
+		return nil
+	}
+	return common.WrapErrorWithPath("story", "remove footnote", s.StoryElement.Self, common.ErrNotFound)
+}
@@ -0,0 +1,176 @@
+package story
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+)
+
+// footnoteStoryXML is a story with one footnote and one endnote reference.
+const footnoteStoryXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<idPkg:Story xmlns:idPkg="http://ns.adobe.com/AdobeInDesign/idml/1.0/packaging" DOMVersion="20.4">
+	<Story Self="u400" AppliedTOCStyle="n" TrackChanges="false">
+		<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/$ID/NormalParagraphStyle">
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+				<Content>Sales grew</Content>
+				<Footnote>
+					<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/Footnote">
+						<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+							<?ACE 4?>
+							<Content>Annual report 2024.</Content>
+						</CharacterStyleRange>
+					</ParagraphStyleRange>
+				</Footnote>
+				<Content> last year</Content>
+				<Endnote Self="u410" EndnoteTextRange="u411" />
+				<Content>.</Content>
+				<Br/>
+			</CharacterStyleRange>
+		</ParagraphStyleRange>
+	</Story>
+</idPkg:Story>`
+
+// TestParseStory_Footnotes tests that footnotes and endnote references are parsed into typed structures.
+func TestParseStory_Footnotes(t *testing.T) {
+	st, err := ParseStory([]byte(footnoteStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+
+	footnotes := st.Footnotes()
+	if len(footnotes) != 1 {
+		t.Fatalf("Footnotes() returned %d footnotes, want 1", len(footnotes))
+	}
+	if got := footnotes[0].Text(); got != "Annual report 2024." {
+		t.Errorf("Footnote.Text() = %q", got)
+	}
+	if got := footnotes[0].ParagraphStyleRanges[0].AppliedParagraphStyle; got != "ParagraphStyle/Footnote" {
+		t.Errorf("footnote paragraph style = %q", got)
+	}
+
+	endnotes := st.Endnotes()
+	if len(endnotes) != 1 || endnotes[0].Self != "u410" {
+		t.Fatalf("Endnotes() = %v, want u410", endnotes)
+	}
+	if len(endnotes[0].OtherAttrs) != 1 || endnotes[0].OtherAttrs[0].Value != "u411" {
+		t.Errorf("endnote attributes = %v", endnotes[0].OtherAttrs)
+	}
+
+	if got := st.ExtractText(); got != "Sales grew last year.\n" {
+		t.Errorf("ExtractText() = %q", got)
+	}
+	got := st.ExtractTextWithOptions(TextOptions{IncludeFootnotes: true})
+	if want := "Sales grew[1 Annual report 2024.] last year.\n"; got != want {
+		t.Errorf("ExtractTextWithOptions() = %q, want %q", got, want)
+	}
+}
+
+// TestFootnotesRoundtrip tests that footnotes, endnotes and the number marker survive marshaling.
+func TestFootnotesRoundtrip(t *testing.T) {
+	st, err := ParseStory([]byte(footnoteStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+
+	first, err := MarshalStory(st)
+	if err != nil {
+		t.Fatalf("MarshalStory failed: %v", err)
+	}
+	reparsed, err := ParseStory(first)
+	if err != nil {
+		t.Fatalf("ParseStory (second) failed: %v", err)
+	}
+	second, err := MarshalStory(reparsed)
+	if err != nil {
+		t.Fatalf("MarshalStory (second) failed: %v", err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Errorf("roundtrip output differs:\nfirst:\n%s\nsecond:\n%s", first, second)
+	}
+
+	out := string(first)
+	for _, want := range []string{
+		`<?ACE 4?>`,
+		`<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/Footnote">`,
+		`<Endnote Self="u410" EndnoteTextRange="u411"></Endnote>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %s", want)
+		}
+	}
+	if strings.Index(out, "Sales grew") > strings.Index(out, "<Footnote>") ||
+		strings.Index(out, "</Footnote>") > strings.Index(out, " last year") {
+		t.Error("footnote is not kept between surrounding content")
+	}
+}
+
+// TestAddFootnote tests inserting a footnote reference inside a run.
+func TestAddFootnote(t *testing.T) {
+	st, err := ParseStory([]byte(footnoteStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+
+	offset := st.FindText("last year")[0].End
+	fn, err := st.AddFootnote(offset, "Restated.", "")
+	if err != nil {
+		t.Fatalf("AddFootnote failed: %v", err)
+	}
+	if fn.Text() != "Restated." || fn.ParagraphStyleRanges[0].AppliedParagraphStyle != "ParagraphStyle/$ID/NormalParagraphStyle" {
+		t.Errorf("new footnote = %q in %q", fn.Text(), fn.ParagraphStyleRanges[0].AppliedParagraphStyle)
+	}
+
+	footnotes := st.Footnotes()
+	if len(footnotes) != 2 || footnotes[1] != fn {
+		t.Fatalf("Footnotes() = %v, want the new footnote second", footnotes)
+	}
+	if got := st.ExtractText(); got != "Sales grew last year.\n" {
+		t.Errorf("ExtractText() = %q, text must be unchanged", got)
+	}
+	got := st.ExtractTextWithOptions(TextOptions{IncludeFootnotes: true})
+	if want := "Sales grew[1 Annual report 2024.] last year[2 Restated.].\n"; got != want {
+		t.Errorf("ExtractTextWithOptions() = %q, want %q", got, want)
+	}
+
+	data, err := MarshalStory(st)
+	if err != nil {
+		t.Fatalf("MarshalStory failed: %v", err)
+	}
+	if n := strings.Count(string(data), "<?ACE 4?>"); n != 2 {
+		t.Errorf("marshaled story has %d footnote markers, want 2", n)
+	}
+
+	if _, err := st.AddFootnote(1000, "x", ""); err == nil {
+		t.Error("expected error for offset out of bounds")
+	}
+}
+
+// TestRemoveFootnote tests removing a footnote by the offset of its reference.
+func TestRemoveFootnote(t *testing.T) {
+	st, err := ParseStory([]byte(footnoteStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+
+	if err := st.RemoveFootnote(0); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("RemoveFootnote(0) error = %v, want ErrNotFound", err)
+	}
+	if err := st.RemoveFootnote(len("Sales grew")); err != nil {
+		t.Fatalf("RemoveFootnote failed: %v", err)
+	}
+	if n := len(st.Footnotes()); n != 0 {
+		t.Errorf("Footnotes() returned %d footnotes after removal", n)
+	}
+
+	// The content around the removed reference is joined again
+	csr := st.StoryElement.ParagraphStyleRanges[0].CharacterStyleRanges[0]
+	if csr.Children[0].Content == nil || csr.Children[0].Content.Text != "Sales grew last year" {
+		t.Errorf("first child = %+v, want merged content", csr.Children[0])
+	}
+	if len(st.Endnotes()) != 1 {
+		t.Error("endnote reference was removed")
+	}
+}
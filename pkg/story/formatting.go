@@ -0,0 +1,242 @@
+package story
+
+import (
+	"bytes"
+	"encoding/xml"
+	"slices"
+	"strings"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/resources"
+)
+
+// noCharacterStyle is the character style of text without a character style.
+const noCharacterStyle = "CharacterStyle/$ID/[No character style]"
+
+// EffectiveFormatting is the formatting in effect for a character of a
+// story: the paragraph style, the local paragraph formatting, the character
+// style and the local character formatting, each overriding the ones before.
+type EffectiveFormatting struct {
+	// ParagraphStyle and CharacterStyle are the applied style references
+	ParagraphStyle string
+	CharacterStyle string
+
+	resources.Formatting
+}
+
+// EffectiveFormattingAt returns the formatting of the character at the given
+// byte offset in the flattened story text, with the styles resolved through
+// their BasedOn chains in styles.
+//
+// Formatting from the paragraph style is overridden by attributes and
+// Properties on the ParagraphStyleRange, then by the character style (except
+// [No character style], which sets nothing), then by attributes and
+// Properties on the CharacterStyleRange.
+//
+// Returns common.ErrNotFound if an applied style does not exist.
+//
+// Example:
+//
+//	offset := st.FindText("Budget")[0].Start
+//	f, err := st.EffectiveFormattingAt(offset, styles)
+//	fmt.Println(f.AppliedFont(), f.FontStyle(), f.PointSize())
+func (s *Story) EffectiveFormattingAt(offset int, styles *resources.StylesFile) (*EffectiveFormatting, error) {
+	const op = "effective formatting"
+	if styles == nil {
+		return nil, common.Errorf("story", op, s.StoryElement.Self, "styles is nil")
+	}
+	var seg *textSegment
+	for _, candidate := range s.segments() {
+		if candidate.start <= offset && offset < candidate.end {
+			seg = &candidate
+			break
+		}
+	}
+	if seg == nil {
+		return nil, common.Errorf("story", op, s.StoryElement.Self, "offset %d is out of bounds (text length %d)", offset, len(s.ExtractText()))
+	}
+
+	psr := &s.StoryElement.ParagraphStyleRanges[seg.psr]
+	csr := &psr.CharacterStyleRanges[seg.csr]
+	f := &EffectiveFormatting{
+		ParagraphStyle: psr.AppliedParagraphStyle,
+		CharacterStyle: csr.AppliedCharacterStyle,
+	}
+
+	paragraph, err := styles.ResolveParagraphStyle(psr.AppliedParagraphStyle)
+	if err != nil {
+		return nil, common.WrapErrorWithPath("story", op, s.StoryElement.Self, err)
+	}
+	f.Formatting = paragraph.Formatting
+	props, err := rawProperties(psr.OtherElements)
+	if err != nil {
+		return nil, common.WrapErrorWithPath("story", op, s.StoryElement.Self, err)
+	}
+	f.Merge(psr.OtherAttrs, props)
+
+	if csr.AppliedCharacterStyle != "" && csr.AppliedCharacterStyle != noCharacterStyle {
+		character, err := styles.ResolveCharacterStyle(csr.AppliedCharacterStyle)
+		if err != nil {
+			return nil, common.WrapErrorWithPath("story", op, s.StoryElement.Self, err)
+		}
+		for name, value := range character.Attributes {
+			f.Attributes[name] = value
+		}
+		for name, elem := range character.Properties {
+			f.Properties[name] = elem
+		}
+	}
+
+	props, err = rawProperties(csr.propertiesElements())
+	if err != nil {
+		return nil, common.WrapErrorWithPath("story", op, s.StoryElement.Self, err)
+	}
+	f.Merge(csr.localAttrs(), props)
+	return f, nil
+}
+
+// rangeIdentityAttrs are character style range attributes that are not
+// formatting.
+var rangeIdentityAttrs = map[string]bool{
+	"AppliedConditions": true,
+}
+
+// localProperties are the Properties children of a character style range
+// that hold local formatting.
+var localProperties = map[string]bool{
+	"AppliedFont": true,
+	"Leading":     true,
+}
+
+// LocalFormatting returns the formatting set on the range itself rather
+// than through its character style: formatting attributes such as FontStyle,
+// PointSize or FillColor and the AppliedFont and Leading Properties
+// children, by name. Returns nil if the range has no local formatting.
+//
+// Example:
+//
+//	for name, value := range csr.LocalFormatting() {
+//	    fmt.Printf("%s=%s\n", name, value)
+//	}
+func (c *CharacterStyleRange) LocalFormatting() map[string]string {
+	var local map[string]string
+	set := func(name, value string) {
+		if local == nil {
+			local = make(map[string]string)
+		}
+		local[name] = value
+	}
+	for _, attr := range c.localAttrs() {
+		if attr.Name.Space == "" && !rangeIdentityAttrs[attr.Name.Local] {
+			set(attr.Name.Local, attr.Value)
+		}
+	}
+	for _, elem := range c.localPropertyElements() {
+		set(elem.XMLName.Local, strings.TrimSpace(string(elem.Content)))
+	}
+	return local
+}
+
+// ClearLocalFormatting removes the local formatting returned by
+// LocalFormatting, so the range is formatted by its character style alone.
+// Conditions and other Properties children are kept.
+func (c *CharacterStyleRange) ClearLocalFormatting() error {
+	c.HorizontalScale = ""
+	c.Tracking = ""
+	c.OtherAttrs = slices.DeleteFunc(c.OtherAttrs, func(attr xml.Attr) bool {
+		return attr.Name.Space == "" && !rangeIdentityAttrs[attr.Name.Local]
+	})
+
+	for i := 0; i < len(c.Children); i++ {
+		other := c.Children[i].Other
+		if other == nil || other.XMLName.Local != "Properties" {
+			continue
+		}
+		props, err := rawProperties([]common.RawXMLElement{*other})
+		if err != nil {
+			return common.WrapErrorWithPath("story", "clear local formatting", c.AppliedCharacterStyle, err)
+		}
+		props.OtherElements = slices.DeleteFunc(props.OtherElements, isLocalProperty)
+		if props.Label == nil && props.PathGeometry == nil && len(props.OtherElements) == 0 {
+			c.Children = slices.Delete(c.Children, i, i+1)
+			i--
+			continue
+		}
+		data, err := xml.Marshal(props)
+		if err != nil {
+			return common.WrapErrorWithPath("story", "clear local formatting", c.AppliedCharacterStyle, err)
+		}
+		var elem common.RawXMLElement
+		if err := xml.Unmarshal(data, &elem); err != nil {
+			return common.WrapErrorWithPath("story", "clear local formatting", c.AppliedCharacterStyle, err)
+		}
+		c.Children[i].Other = &elem
+	}
+	return nil
+}
+
+// localPropertyElements returns the Properties children of the range that
+// hold local formatting with a plain value.
+func (c *CharacterStyleRange) localPropertyElements() []common.RawXMLElement {
+	props, err := rawProperties(c.propertiesElements())
+	if err != nil || props == nil {
+		return nil
+	}
+	var elems []common.RawXMLElement
+	for _, elem := range props.OtherElements {
+		if isLocalProperty(elem) {
+			elems = append(elems, elem)
+		}
+	}
+	return elems
+}
+
+// isLocalProperty reports whether a Properties child is local formatting
+// with a plain value, such as <AppliedFont type="string">Minion Pro</AppliedFont>.
+func isLocalProperty(elem common.RawXMLElement) bool {
+	return localProperties[elem.XMLName.Local] && !bytes.ContainsRune(elem.Content, '<')
+}
+
+// localAttrs returns the local formatting attributes of the range.
+func (c *CharacterStyleRange) localAttrs() []xml.Attr {
+	attrs := make([]xml.Attr, 0, len(c.OtherAttrs)+2)
+	if c.HorizontalScale != "" {
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "HorizontalScale"}, Value: c.HorizontalScale})
+	}
+	if c.Tracking != "" {
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "Tracking"}, Value: c.Tracking})
+	}
+	return append(attrs, c.OtherAttrs...)
+}
+
+// propertiesElements returns the Properties children of the range, which
+// are kept as unknown elements.
+func (c *CharacterStyleRange) propertiesElements() []common.RawXMLElement {
+	var elems []common.RawXMLElement
+	for _, child := range c.Children {
+		if child.Other != nil && child.Other.XMLName.Local == "Properties" {
+			elems = append(elems, *child.Other)
+		}
+	}
+	return elems
+}
+
+// rawProperties decodes the first Properties element of elems, or returns
+// nil if there is none.
+func rawProperties(elems []common.RawXMLElement) (*common.Properties, error) {
+	for i := range elems {
+		if elems[i].XMLName.Local != "Properties" {
+			continue
+		}
+		data, err := xml.Marshal(&elems[i])
+		if err != nil {
+			return nil, err
+		}
+		var props common.Properties
+		if err := xml.Unmarshal(data, &props); err != nil {
+			return nil, err
+		}
+		return &props, nil
+	}
+	return nil, nil
+}
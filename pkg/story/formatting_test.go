@@ -0,0 +1,132 @@
+package story
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+	"github.com/dimelords/idmllib/v2/pkg/resources"
+)
+
+const formattingStylesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<idPkg:Styles xmlns:idPkg="http://ns.adobe.com/AdobeInDesign/idml/1.0/packaging" DOMVersion="20.4">
+	<RootCharacterStyleGroup Self="u78">
+		<CharacterStyle Self="CharacterStyle/$ID/[No character style]" Name="$ID/[No character style]"/>
+		<CharacterStyle Self="CharacterStyle/Bold" Name="Bold" FontStyle="Bold">
+			<Properties>
+				<BasedOn type="string">$ID/[No character style]</BasedOn>
+			</Properties>
+		</CharacterStyle>
+	</RootCharacterStyleGroup>
+	<RootParagraphStyleGroup Self="u77">
+		<ParagraphStyle Self="ParagraphStyle/$ID/[No paragraph style]" Name="$ID/[No paragraph style]" FontStyle="Regular" PointSize="12" Justification="LeftAlign">
+			<Properties>
+				<AppliedFont type="string">Minion Pro</AppliedFont>
+			</Properties>
+		</ParagraphStyle>
+		<ParagraphStyle Self="ParagraphStyle/Body" Name="Body" PointSize="9.5">
+			<Properties>
+				<BasedOn type="string">$ID/[No paragraph style]</BasedOn>
+				<Leading type="unit">11.4</Leading>
+			</Properties>
+		</ParagraphStyle>
+	</RootParagraphStyleGroup>
+</idPkg:Styles>`
+
+const formattingStoryXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<idPkg:Story xmlns:idPkg="http://ns.adobe.com/AdobeInDesign/idml/1.0/packaging" DOMVersion="20.4">
+	<Story Self="u100">
+		<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/Body" Justification="CenterAlign">
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+				<Content>Plain </Content>
+			</CharacterStyleRange>
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/Bold" PointSize="14" Tracking="20">
+				<Properties>
+					<AppliedFont type="string">Myriad Pro</AppliedFont>
+				</Properties>
+				<Content>Loud</Content>
+			</CharacterStyleRange>
+		</ParagraphStyleRange>
+	</Story>
+</idPkg:Story>`
+
+// TestEffectiveFormattingAt tests layering paragraph and character styles
+// with local overrides.
+func TestEffectiveFormattingAt(t *testing.T) {
+	styles, err := resources.ParseStylesFile([]byte(formattingStylesXML))
+	if err != nil {
+		t.Fatalf("ParseStylesFile failed: %v", err)
+	}
+	st, err := ParseStory([]byte(formattingStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+
+	plain, err := st.EffectiveFormattingAt(0, styles)
+	if err != nil {
+		t.Fatalf("EffectiveFormattingAt(0) failed: %v", err)
+	}
+	if plain.ParagraphStyle != "ParagraphStyle/Body" || plain.AppliedFont() != "Minion Pro" ||
+		plain.FontStyle() != "Regular" || plain.PointSize() != 9.5 || plain.Leading() != "11.4" {
+		t.Errorf("plain formatting = %+v", plain)
+	}
+	if got := plain.Justification(); got != "CenterAlign" {
+		t.Errorf("Justification() = %q, want the local CenterAlign", got)
+	}
+
+	loud, err := st.EffectiveFormattingAt(st.FindText("Loud")[0].Start, styles)
+	if err != nil {
+		t.Fatalf("EffectiveFormattingAt(Loud) failed: %v", err)
+	}
+	if loud.CharacterStyle != "CharacterStyle/Bold" || loud.FontStyle() != "Bold" || loud.PointSize() != 14 ||
+		loud.AppliedFont() != "Myriad Pro" || loud.Attr("Tracking") != "20" || loud.Leading() != "11.4" {
+		t.Errorf("loud formatting = %+v", loud)
+	}
+
+	if _, err := st.EffectiveFormattingAt(len(st.ExtractText()), styles); err == nil {
+		t.Error("expected error for an offset at the end of the story")
+	}
+	if _, err := st.EffectiveFormattingAt(0, nil); err == nil {
+		t.Error("expected error for nil styles")
+	}
+	st.StoryElement.ParagraphStyleRanges[0].AppliedParagraphStyle = "ParagraphStyle/Missing"
+	if _, err := st.EffectiveFormattingAt(0, styles); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("EffectiveFormattingAt with a missing style error = %v, want ErrNotFound", err)
+	}
+}
+
+// TestLocalFormatting tests reading and clearing the local formatting of a
+// character style range.
+func TestLocalFormatting(t *testing.T) {
+	st, err := ParseStory([]byte(formattingStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+	ranges := st.StoryElement.ParagraphStyleRanges[0].CharacterStyleRanges
+	if got := ranges[0].LocalFormatting(); got != nil {
+		t.Errorf("LocalFormatting() of plain range = %v, want nil", got)
+	}
+
+	loud := &ranges[1]
+	loud.SetAppliedConditions([]string{"Condition/Web"})
+	local := loud.LocalFormatting()
+	if len(local) != 3 || local["PointSize"] != "14" || local["Tracking"] != "20" || local["AppliedFont"] != "Myriad Pro" {
+		t.Errorf("LocalFormatting() = %v", local)
+	}
+
+	if err := loud.ClearLocalFormatting(); err != nil {
+		t.Fatalf("ClearLocalFormatting failed: %v", err)
+	}
+	if got := loud.LocalFormatting(); got != nil {
+		t.Errorf("LocalFormatting() after clearing = %v, want nil", got)
+	}
+	if got := loud.AppliedConditions(); len(got) != 1 || got[0] != "Condition/Web" {
+		t.Errorf("AppliedConditions() = %v, want the condition kept", got)
+	}
+	if len(loud.propertiesElements()) != 0 {
+		t.Error("empty Properties element was kept")
+	}
+	if got := loud.Children[len(loud.Children)-1]; got.Content == nil || got.Content.Text != "Loud" {
+		t.Errorf("content = %+v", got)
+	}
+}
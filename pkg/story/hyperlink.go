@@ -0,0 +1,326 @@
+package story
+
+import (
+	"encoding/xml"
+	"slices"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+)
+
+// HyperlinkTextSource is the text a hyperlink starts from. It wraps the
+// linked text inside a CharacterStyleRange; the Hyperlink element in
+// designmap.xml refers to it by Self.
+type HyperlinkTextSource struct {
+	XMLName xml.Name
+
+	// Identity
+	Self string
+	Name string
+
+	// Source settings
+	Hidden                string // "true"/"false"
+	AppliedCharacterStyle string // Usually "n" (none)
+
+	// Additional attributes (catch-all)
+	OtherAttrs []xml.Attr
+
+	// Linked text: Content, Br and the other character children in order
+	Children []CharacterChild
+}
+
+// CrossReferenceSource is the text a cross-reference starts from. Its content
+// is generated by InDesign from the applied CrossReferenceFormat.
+type CrossReferenceSource struct {
+	XMLName xml.Name
+
+	// Identity
+	Self string
+	Name string
+
+	// Source settings
+	Hidden                string // "true"/"false"
+	AppliedFormat         string // Reference to a CrossReferenceFormat in designmap.xml
+	AppliedCharacterStyle string // Usually "n" (none)
+
+	// Additional attributes (catch-all)
+	OtherAttrs []xml.Attr
+
+	// Generated text: Content, Br and the other character children in order
+	Children []CharacterChild
+}
+
+// TextDestination is a HyperlinkTextDestination or ParagraphDestination: a
+// zero-width anchor in the text that hyperlinks and cross-references can target.
+type TextDestination struct {
+	XMLName xml.Name
+
+	// Identity
+	Self string `xml:"Self,attr"`
+	Name string `xml:"Name,attr,omitempty"`
+
+	// Destination settings
+	Hidden               string `xml:"Hidden,attr,omitempty"`               // "true"/"false"
+	DestinationUniqueKey string `xml:"DestinationUniqueKey,attr,omitempty"` // Numeric key unique per document
+
+	// Additional attributes (catch-all)
+	OtherAttrs []xml.Attr `xml:",any,attr"`
+
+	// Catch-all for child elements
+	OtherElements []common.RawXMLElement `xml:",any"`
+}
+
+// LinkSource describes a hyperlink or cross-reference source in a story.
+type LinkSource struct {
+	Self           string
+	Name           string
+	CrossReference bool      // true for a CrossReferenceSource
+	AppliedFormat  string    // cross-reference format (cross-references only)
+	Range          TextRange // position of the source text in the flattened story text
+	Text           string
+}
+
+// LinkSources returns the hyperlink and cross-reference sources in the story,
+// in document order.
+func (s *Story) LinkSources() []LinkSource {
+	text := s.ExtractText()
+	var sources []LinkSource
+	s.walkChildren(func(v childVisit) {
+		child := (*v.parent)[v.index]
+		var src LinkSource
+		switch {
+		case child.HyperlinkSource != nil:
+			src = LinkSource{Self: child.HyperlinkSource.Self, Name: child.HyperlinkSource.Name}
+		case child.CrossReferenceSource != nil:
+			src = LinkSource{
+				Self:           child.CrossReferenceSource.Self,
+				Name:           child.CrossReferenceSource.Name,
+				CrossReference: true,
+				AppliedFormat:  child.CrossReferenceSource.AppliedFormat,
+			}
+		default:
+			return
+		}
+		end := v.pos + childrenTextLen(*child.nested())
+		src.Range = TextRange{Start: v.pos, End: end}
+		src.Text = text[v.pos:end]
+		sources = append(sources, src)
+	})
+	return sources
+}
+
+// TextDestinations returns the hyperlink text and paragraph destinations in
+// the story, in document order.
+func (s *Story) TextDestinations() []*TextDestination {
+	var dests []*TextDestination
+	s.walkChildren(func(v childVisit) {
+		if dest := (*v.parent)[v.index].Destination; dest != nil {
+			dests = append(dests, dest)
+		}
+	})
+	return dests
+}
+
+// AddHyperlinkSource wraps the text in [start, end) of the flattened story
+// text in a new HyperlinkTextSource with the given Self ID and name.
+//
+// The range must lie within a single character style range and must not
+// overlap an existing hyperlink or cross-reference source. It may lie inside
+// an XMLElement or enclose whole XMLElements. The source is not
+// linked anywhere until a Hyperlink in designmap.xml refers to it; use
+// idml.Package.AddURLHyperlink to do both.
+func (s *Story) AddHyperlinkSource(start, end int, self, name string) (*HyperlinkTextSource, error) {
+	const op = "add hyperlink source"
+	if err := s.validateRange(op, start, end); err != nil {
+		return nil, err
+	}
+	if start == end {
+		return nil, common.Errorf("story", op, s.StoryElement.Self, "hyperlink source range is empty")
+	}
+	if self == "" {
+		return nil, common.Errorf("story", op, s.StoryElement.Self, "hyperlink source needs a Self ID")
+	}
+
+	parent, first, last, err := s.wrappableChildren(op, start, end)
+	if err != nil {
+		return nil, err
+	}
+	if s.inLinkSource(parent) || containsChild((*parent)[first:last+1], isLinkSource) {
+		return nil, common.Errorf("story", op, s.StoryElement.Self, "range [%d, %d) overlaps another link source", start, end)
+	}
+
+	source := &HyperlinkTextSource{
+		XMLName:               xml.Name{Local: "HyperlinkTextSource"},
+		Self:                  self,
+		Name:                  name,
+		Hidden:                "false",
+		AppliedCharacterStyle: "n",
+		Children:              slices.Clone((*parent)[first : last+1]),
+	}
+	*parent = slices.Replace(*parent, first, last+1, CharacterChild{HyperlinkSource: source})
+	return source, nil
+}
+
+// RemoveLinkSource removes the hyperlink or cross-reference source with the
+// given Self ID. The text of a hyperlink source stays in the story; the
+// generated text of a cross-reference source is removed with it.
+//
+// Returns common.ErrNotFound if the story has no such source.
+func (s *Story) RemoveLinkSource(self string) error {
+	var (
+		parent *[]CharacterChild
+		index  int
+	)
+	s.walkChildren(func(v childVisit) {
+		child := (*v.parent)[v.index]
+		if parent == nil && ((child.HyperlinkSource != nil && child.HyperlinkSource.Self == self) ||
+			(child.CrossReferenceSource != nil && child.CrossReferenceSource.Self == self)) {
+			parent, index = v.parent, v.index
+		}
+	})
+	if parent == nil {
+		return common.WrapErrorWithPath("story", "remove link source", self, common.ErrNotFound)
+	}
+
+	child := (*parent)[index]
+	var keep []CharacterChild
+	if child.HyperlinkSource != nil {
+		keep = child.HyperlinkSource.Children
+	}
+	*parent = slices.Replace(*parent, index, index+1, keep...)
+	mergeAdjacentContent(parent)
+	return nil
+}
+
+// inLinkSource reports whether children is the children slice of a link
+// source or of a container inside one.
+func (s *Story) inLinkSource(children *[]CharacterChild) bool {
+	inLink := make(map[*[]CharacterChild]bool)
+	s.walkChildren(func(v childVisit) {
+		child := &(*v.parent)[v.index]
+		if nested := child.nested(); nested != nil {
+			inLink[nested] = inLink[v.parent] || isLinkSource(child)
+		}
+	})
+	return inLink[children]
+}
+
+// isLinkSource reports whether the child is a hyperlink or cross-reference source.
+func isLinkSource(child *CharacterChild) bool {
+	return child.HyperlinkSource != nil || child.CrossReferenceSource != nil
+}
+
+// childrenTextLen returns the length of the flattened text of children.
+func childrenTextLen(children []CharacterChild) int {
+	n := 0
+	for _, child := range flattenChildren(children, nil) {
+		switch {
+		case child.Content != nil:
+			n += len(child.Content.Text)
+		case child.Br != nil:
+			n++
+		}
+	}
+	return n
+}
+
+// UnmarshalXML implements custom unmarshaling for HyperlinkTextSource to preserve child order.
+func (h *HyperlinkTextSource) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	h.XMLName = start.Name
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "Self":
+			h.Self = attr.Value
+		case "Name":
+			h.Name = attr.Value
+		case "Hidden":
+			h.Hidden = attr.Value
+		case "AppliedCharacterStyle":
+			h.AppliedCharacterStyle = attr.Value
+		default:
+			h.OtherAttrs = append(h.OtherAttrs, attr)
+		}
+	}
+
+	children, err := decodeCharacterChildren(d)
+	if err != nil {
+		return err
+	}
+	h.Children = children
+	return nil
+}
+
+// MarshalXML implements custom marshaling for HyperlinkTextSource to preserve child order.
+func (h HyperlinkTextSource) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: "HyperlinkTextSource"}
+	start.Attr = appendAttrs(start.Attr,
+		"Self", h.Self,
+		"Name", h.Name,
+		"Hidden", h.Hidden,
+		"AppliedCharacterStyle", h.AppliedCharacterStyle,
+	)
+	start.Attr = append(start.Attr, h.OtherAttrs...)
+	return encodeContainer(e, start, h.Children)
+}
+
+// UnmarshalXML implements custom unmarshaling for CrossReferenceSource to preserve child order.
+func (c *CrossReferenceSource) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	c.XMLName = start.Name
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "Self":
+			c.Self = attr.Value
+		case "Name":
+			c.Name = attr.Value
+		case "Hidden":
+			c.Hidden = attr.Value
+		case "AppliedFormat":
+			c.AppliedFormat = attr.Value
+		case "AppliedCharacterStyle":
+			c.AppliedCharacterStyle = attr.Value
+		default:
+			c.OtherAttrs = append(c.OtherAttrs, attr)
+		}
+	}
+
+	children, err := decodeCharacterChildren(d)
+	if err != nil {
+		return err
+	}
+	c.Children = children
+	return nil
+}
+
+// MarshalXML implements custom marshaling for CrossReferenceSource to preserve child order.
+func (c CrossReferenceSource) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start.Name = xml.Name{Local: "CrossReferenceSource"}
+	start.Attr = appendAttrs(start.Attr,
+		"Self", c.Self,
+		"Name", c.Name,
+		"Hidden", c.Hidden,
+		"AppliedFormat", c.AppliedFormat,
+		"AppliedCharacterStyle", c.AppliedCharacterStyle,
+	)
+	start.Attr = append(start.Attr, c.OtherAttrs...)
+	return encodeContainer(e, start, c.Children)
+}
+
+// appendAttrs appends the non-empty values of the given name/value pairs as attributes.
+func appendAttrs(attrs []xml.Attr, pairs ...string) []xml.Attr {
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] != "" {
+			attrs = append(attrs, xml.Attr{Name: xml.Name{Local: pairs[i]}, Value: pairs[i+1]})
+		}
+	}
+	return attrs
+}
+
+// encodeContainer writes a text container element with its children.
+func encodeContainer(e *xml.Encoder, start xml.StartElement, children []CharacterChild) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := encodeCharacterChildren(e, children); err != nil {
+		return err
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
@@ -0,0 +1,214 @@
+package story
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+)
+
+// hyperlinkStoryXML is a story with a hyperlink source, a cross-reference
+// source and a text destination.
+const hyperlinkStoryXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<idPkg:Story xmlns:idPkg="http://ns.adobe.com/AdobeInDesign/idml/1.0/packaging" DOMVersion="20.4">
+	<Story Self="u500" AppliedTOCStyle="n" TrackChanges="false">
+		<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/$ID/NormalParagraphStyle">
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+				<Content>Visit </Content>
+				<HyperlinkTextSource Self="u510" Name="Home page" Hidden="false" AppliedCharacterStyle="n">
+					<Content>our site</Content>
+				</HyperlinkTextSource>
+				<Content> or see </Content>
+				<CrossReferenceSource Self="u520" Name="Ref" Hidden="false" AppliedFormat="u377" AppliedCharacterStyle="n">
+					<Content>"Results"</Content>
+				</CrossReferenceSource>
+				<Content>.</Content>
+				<Br/>
+			</CharacterStyleRange>
+		</ParagraphStyleRange>
+		<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/Heading">
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+				<HyperlinkTextDestination Self="HyperlinkTextDestination/Results" Name="Results" Hidden="false" DestinationUniqueKey="3"/>
+				<Content>Results</Content>
+			</CharacterStyleRange>
+		</ParagraphStyleRange>
+	</Story>
+</idPkg:Story>`
+
+// TestParseStory_LinkSources tests that link sources are parsed as text
+// containers whose text is part of the story text.
+func TestParseStory_LinkSources(t *testing.T) {
+	st, err := ParseStory([]byte(hyperlinkStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+
+	if got, want := st.ExtractText(), "Visit our site or see \"Results\".\nResults"; got != want {
+		t.Errorf("ExtractText() = %q, want %q", got, want)
+	}
+
+	sources := st.LinkSources()
+	if len(sources) != 2 {
+		t.Fatalf("LinkSources() returned %d sources, want 2", len(sources))
+	}
+	if src := sources[0]; src.Self != "u510" || src.CrossReference || src.Text != "our site" || src.Range != (TextRange{Start: 6, End: 14}) {
+		t.Errorf("first source = %+v", src)
+	}
+	if src := sources[1]; src.Self != "u520" || !src.CrossReference || src.AppliedFormat != "u377" || src.Text != `"Results"` {
+		t.Errorf("second source = %+v", src)
+	}
+
+	dests := st.TextDestinations()
+	if len(dests) != 1 || dests[0].Self != "HyperlinkTextDestination/Results" || dests[0].XMLName.Local != "HyperlinkTextDestination" {
+		t.Fatalf("TextDestinations() = %v", dests)
+	}
+
+	csr := st.StoryElement.ParagraphStyleRanges[0].CharacterStyleRanges[0]
+	if contents := csr.GetContent(); len(contents) != 5 || contents[1].Text != "our site" {
+		t.Errorf("GetContent() = %v, want the linked text included", contents)
+	}
+}
+
+// TestLinkSourcesRoundtrip tests that link sources and destinations survive marshaling.
+func TestLinkSourcesRoundtrip(t *testing.T) {
+	st, err := ParseStory([]byte(hyperlinkStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+
+	first, err := MarshalStory(st)
+	if err != nil {
+		t.Fatalf("MarshalStory failed: %v", err)
+	}
+	reparsed, err := ParseStory(first)
+	if err != nil {
+		t.Fatalf("ParseStory (second) failed: %v", err)
+	}
+	second, err := MarshalStory(reparsed)
+	if err != nil {
+		t.Fatalf("MarshalStory (second) failed: %v", err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Errorf("roundtrip output differs:\nfirst:\n%s\nsecond:\n%s", first, second)
+	}
+
+	out := string(first)
+	for _, want := range []string{
+		`<HyperlinkTextSource Self="u510" Name="Home page" Hidden="false" AppliedCharacterStyle="n">`,
+		`<CrossReferenceSource Self="u520" Name="Ref" Hidden="false" AppliedFormat="u377" AppliedCharacterStyle="n">`,
+		`<HyperlinkTextDestination Self="HyperlinkTextDestination/Results" Name="Results" Hidden="false" DestinationUniqueKey="3"></HyperlinkTextDestination>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %s", want)
+		}
+	}
+}
+
+// TestEditText_InsideLinkSource tests that text edits reach text inside link sources.
+func TestEditText_InsideLinkSource(t *testing.T) {
+	st, err := ParseStory([]byte(hyperlinkStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+
+	if n := st.ReplaceText("site", "website"); n != 1 {
+		t.Fatalf("ReplaceText replaced %d occurrences, want 1", n)
+	}
+	src := st.LinkSources()[0]
+	if src.Text != "our website" {
+		t.Errorf("link text = %q, want %q", src.Text, "our website")
+	}
+
+	// Text inserted right after the link continues it, like typing in InDesign
+	if err := st.InsertText(src.Range.End, "!"); err != nil {
+		t.Fatalf("InsertText failed: %v", err)
+	}
+	if got := st.LinkSources()[0].Text; got != "our website!" {
+		t.Errorf("link text after insert = %q", got)
+	}
+
+	if err := st.DeleteText(0, len("Visit our ")); err != nil {
+		t.Fatalf("DeleteText failed: %v", err)
+	}
+	if got := st.ExtractText(); !strings.HasPrefix(got, "website! or see") {
+		t.Errorf("ExtractText() = %q", got)
+	}
+	if got := st.LinkSources()[0]; got.Text != "website!" || got.Range.Start != 0 {
+		t.Errorf("link source after delete = %+v", got)
+	}
+}
+
+// TestAddHyperlinkSource tests wrapping a text range in a hyperlink source.
+func TestAddHyperlinkSource(t *testing.T) {
+	st, err := ParseStory([]byte(hyperlinkStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+	text := st.ExtractText()
+
+	r := st.FindText("see")[0]
+	src, err := st.AddHyperlinkSource(r.Start, r.End, "u530", "See")
+	if err != nil {
+		t.Fatalf("AddHyperlinkSource failed: %v", err)
+	}
+	if src.Self != "u530" || len(src.Children) != 1 || src.Children[0].Content.Text != "see" {
+		t.Errorf("new source = %+v", src)
+	}
+	if got := st.ExtractText(); got != text {
+		t.Errorf("ExtractText() = %q, text must be unchanged", got)
+	}
+	sources := st.LinkSources()
+	if len(sources) != 3 || sources[1].Self != "u530" || sources[1].Range != r {
+		t.Errorf("LinkSources() = %+v", sources)
+	}
+
+	tests := []struct {
+		name       string
+		start, end int
+	}{
+		{"empty range", 0, 0},
+		{"overlaps existing source", 8, 10},
+		{"spans paragraphs", r.Start, len(text)},
+		{"out of bounds", 0, len(text) + 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := st.AddHyperlinkSource(tt.start, tt.end, "u540", "x"); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+// TestRemoveLinkSource tests that removing a hyperlink source keeps its text
+// and removing a cross-reference source drops its generated text.
+func TestRemoveLinkSource(t *testing.T) {
+	st, err := ParseStory([]byte(hyperlinkStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+
+	if err := st.RemoveLinkSource("u999"); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("RemoveLinkSource(u999) error = %v, want ErrNotFound", err)
+	}
+
+	if err := st.RemoveLinkSource("u510"); err != nil {
+		t.Fatalf("RemoveLinkSource(u510) failed: %v", err)
+	}
+	csr := st.StoryElement.ParagraphStyleRanges[0].CharacterStyleRanges[0]
+	if csr.Children[0].Content == nil || csr.Children[0].Content.Text != "Visit our site or see " {
+		t.Errorf("first child = %+v, want merged content", csr.Children[0])
+	}
+
+	if err := st.RemoveLinkSource("u520"); err != nil {
+		t.Fatalf("RemoveLinkSource(u520) failed: %v", err)
+	}
+	if got := st.ExtractText(); got != "Visit our site or see .\nResults" {
+		t.Errorf("ExtractText() = %q", got)
+	}
+	if n := len(st.LinkSources()); n != 0 {
+		t.Errorf("LinkSources() returned %d sources after removal", n)
+	}
+}
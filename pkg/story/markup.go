@@ -0,0 +1,384 @@
+package story
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+)
+
+// MarkupOptions maps InDesign styles to Markdown and HTML for ExportMarkdown
+// and ExportHTML.
+//
+// Style keys are the AppliedParagraphStyle or AppliedCharacterStyle
+// reference (e.g., "ParagraphStyle/Headline") or the style name after the
+// last "/" with "%3a" decoded as ":" (e.g., "Headline" or "Body:Intro").
+type MarkupOptions struct {
+	// ParagraphTags maps paragraph styles to "h1" ... "h6", "p", "blockquote",
+	// "ul" or "ol" (an item of a bulleted or numbered list). Other tags are
+	// written as HTML elements. Unmapped paragraphs are "p".
+	ParagraphTags map[string]string
+
+	// CharacterTags maps character styles to "strong", "em", "code" or "s".
+	// Other tags, such as "sup" or "sub", are written as HTML elements, also
+	// in Markdown. Text in unmapped character styles is written as is.
+	CharacterTags map[string]string
+
+	// Links maps hyperlink source Selfs to URLs. Text inside a hyperlink
+	// source with a URL becomes a link; see idml.Package.ExportStoryMarkdown
+	// for resolving the URLs of a package.
+	Links map[string]string
+
+	// VisibleConditions, if non-nil, leaves out text whose conditions are all
+	// hidden, as in TextOptions.
+	VisibleConditions map[string]bool
+}
+
+// markupSpan is a run of paragraph text with one character tag and link, or
+// a footnote reference.
+type markupSpan struct {
+	text     string
+	tag      string
+	link     string
+	footnote int // footnote number, for references
+}
+
+// markupParagraph is a paragraph with its paragraph tag.
+type markupParagraph struct {
+	tag   string
+	spans []markupSpan
+}
+
+// markupCollector splits paragraph ranges into paragraphs and spans.
+type markupCollector struct {
+	opts      MarkupOptions
+	paras     []markupParagraph
+	open      bool
+	footnotes [][]markupParagraph // footnote paragraphs, by number - 1
+}
+
+// ExportMarkdown returns the story as Markdown. Paragraphs end at <Br/>
+// elements, forced line breaks become hard line breaks and footnotes become
+// Markdown footnotes ("[^1]") listed after the text.
+//
+// Tables, anchored objects and notes are left out; tracked changes are
+// exported as ExtractText shows them, so accept or reject them first.
+//
+// Example:
+//
+//	md := st.ExportMarkdown(story.MarkupOptions{
+//	    ParagraphTags: map[string]string{"ParagraphStyle/Headline": "h1"},
+//	    CharacterTags: map[string]string{"CharacterStyle/Bold": "strong"},
+//	})
+func (s *Story) ExportMarkdown(opts MarkupOptions) string {
+	c := s.collectMarkup(opts)
+	var buf strings.Builder
+	prevTag := ""
+	for i, para := range c.paras {
+		if i > 0 {
+			// Items of one list are not separated by blank lines
+			if para.tag == prevTag && isListTag(para.tag) {
+				buf.WriteString("\n")
+			} else {
+				buf.WriteString("\n\n")
+			}
+		}
+		prevTag = para.tag
+		text := escapeMarkdownStart(markdownInline(para.spans))
+		switch para.tag {
+		case "h1", "h2", "h3", "h4", "h5", "h6":
+			buf.WriteString(strings.Repeat("#", int(para.tag[1]-'0')) + " " + text)
+		case "blockquote":
+			buf.WriteString("> " + text)
+		case "ul":
+			buf.WriteString("- " + text)
+		case "ol":
+			buf.WriteString("1. " + text)
+		case "p":
+			buf.WriteString(text)
+		default:
+			fmt.Fprintf(&buf, "<%s>%s</%s>", para.tag, text, para.tag)
+		}
+	}
+	if len(c.footnotes) > 0 {
+		buf.WriteString("\n")
+	}
+	for i, note := range c.footnotes {
+		texts := make([]string, 0, len(note))
+		for _, para := range note {
+			texts = append(texts, markdownInline(para.spans))
+		}
+		fmt.Fprintf(&buf, "\n[^%d]: %s", i+1, strings.Join(texts, " "))
+	}
+	if buf.Len() > 0 {
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
+
+// ExportHTML returns the story as semantic HTML: one element per paragraph,
+// consecutive list items wrapped in <ul> or <ol>, forced line breaks as <br>
+// and footnotes as numbered references to a <section class="footnotes">
+// after the text. See ExportMarkdown for what is left out.
+//
+// Example:
+//
+//	html := st.ExportHTML(story.MarkupOptions{
+//	    ParagraphTags: map[string]string{"Headline": "h1", "Quote": "blockquote"},
+//	    CharacterTags: map[string]string{"Italic": "em"},
+//	})
+func (s *Story) ExportHTML(opts MarkupOptions) string {
+	c := s.collectMarkup(opts)
+	var buf strings.Builder
+	list := ""
+	for _, para := range c.paras {
+		if list != "" && para.tag != list {
+			fmt.Fprintf(&buf, "</%s>\n", list)
+			list = ""
+		}
+		text := htmlInline(para.spans)
+		if isListTag(para.tag) {
+			if list == "" {
+				fmt.Fprintf(&buf, "<%s>\n", para.tag)
+				list = para.tag
+			}
+			fmt.Fprintf(&buf, "<li>%s</li>\n", text)
+			continue
+		}
+		fmt.Fprintf(&buf, "<%s>%s</%s>\n", para.tag, text, para.tag)
+	}
+	if list != "" {
+		fmt.Fprintf(&buf, "</%s>\n", list)
+	}
+	if len(c.footnotes) > 0 {
+		buf.WriteString("<section class=\"footnotes\">\n<ol>\n")
+		for i, note := range c.footnotes {
+			texts := make([]string, 0, len(note))
+			for _, para := range note {
+				texts = append(texts, htmlInline(para.spans))
+			}
+			fmt.Fprintf(&buf, "<li id=\"fn%d\">%s</li>\n", i+1, strings.Join(texts, " "))
+		}
+		buf.WriteString("</ol>\n</section>\n")
+	}
+	return buf.String()
+}
+
+// collectMarkup splits the story into paragraphs, dropping paragraphs
+// without text.
+func (s *Story) collectMarkup(opts MarkupOptions) *markupCollector {
+	c := &markupCollector{opts: opts}
+	c.collectRanges(s.StoryElement.ParagraphStyleRanges)
+	c.paras = nonEmptyParagraphs(c.paras)
+	return c
+}
+
+// collectRanges collects the paragraphs of the given ranges.
+func (c *markupCollector) collectRanges(ranges []ParagraphStyleRange) {
+	for _, psr := range ranges {
+		ptag := styleTag(c.opts.ParagraphTags, psr.AppliedParagraphStyle)
+		if ptag == "" {
+			ptag = "p"
+		}
+		for _, csr := range psr.CharacterStyleRanges {
+			if c.opts.VisibleConditions != nil && !conditionsVisible(csr.AppliedConditions(), c.opts.VisibleConditions) {
+				continue
+			}
+			c.collectChildren(csr.Children, ptag, styleTag(c.opts.CharacterTags, csr.AppliedCharacterStyle), "")
+		}
+	}
+	c.open = false
+}
+
+// collectChildren adds the text of children to the current paragraph,
+// starting a new paragraph after each <Br/>.
+func (c *markupCollector) collectChildren(children []CharacterChild, ptag, ctag, link string) {
+	for _, child := range children {
+		switch {
+		case child.Content != nil:
+			c.add(ptag, markupSpan{text: child.Content.Text, tag: ctag, link: link})
+		case child.Br != nil:
+			if !c.open {
+				c.paras = append(c.paras, markupParagraph{tag: ptag})
+			}
+			c.open = false
+		case child.Footnote != nil:
+			note := &markupCollector{opts: c.opts}
+			note.collectRanges(child.Footnote.ParagraphStyleRanges)
+			c.footnotes = append(c.footnotes, nonEmptyParagraphs(note.paras))
+			c.add(ptag, markupSpan{footnote: len(c.footnotes)})
+		case child.HyperlinkSource != nil:
+			inner := link
+			if url := c.opts.Links[child.HyperlinkSource.Self]; url != "" {
+				inner = url
+			}
+			c.collectChildren(child.HyperlinkSource.Children, ptag, ctag, inner)
+		case child.nested() != nil:
+			c.collectChildren(*child.nested(), ptag, ctag, link)
+		}
+	}
+}
+
+// add appends a span to the current paragraph, starting one if needed.
+func (c *markupCollector) add(ptag string, span markupSpan) {
+	if !c.open {
+		c.paras = append(c.paras, markupParagraph{tag: ptag})
+		c.open = true
+	}
+	para := &c.paras[len(c.paras)-1]
+	if n := len(para.spans); n > 0 && span.footnote == 0 {
+		last := &para.spans[n-1]
+		if last.footnote == 0 && last.tag == span.tag && last.link == span.link {
+			last.text += span.text
+			return
+		}
+	}
+	para.spans = append(para.spans, span)
+}
+
+// nonEmptyParagraphs returns the paragraphs with text or footnote references.
+func nonEmptyParagraphs(paras []markupParagraph) []markupParagraph {
+	kept := paras[:0]
+	for _, para := range paras {
+		for _, span := range para.spans {
+			if span.footnote > 0 || strings.TrimSpace(span.text) != "" {
+				kept = append(kept, para)
+				break
+			}
+		}
+	}
+	return kept
+}
+
+// styleTag returns the tag mapped to a style reference or to its name.
+func styleTag(tags map[string]string, ref string) string {
+	if tag, ok := tags[ref]; ok {
+		return tag
+	}
+	name := ref[strings.LastIndex(ref, "/")+1:]
+	if tag, ok := tags[name]; ok {
+		return tag
+	}
+	return tags[strings.ReplaceAll(name, "%3a", ":")]
+}
+
+// isListTag reports whether a paragraph tag is a list item.
+func isListTag(tag string) bool {
+	return tag == "ul" || tag == "ol"
+}
+
+// markdownTags are the Markdown delimiters of character tags.
+var markdownTags = map[string]string{
+	"strong": "**",
+	"b":      "**",
+	"em":     "*",
+	"i":      "*",
+	"code":   "`",
+	"s":      "~~",
+	"del":    "~~",
+}
+
+// markdownInline renders the spans of a paragraph as Markdown.
+func markdownInline(spans []markupSpan) string {
+	return renderInline(spans, func(span markupSpan) string {
+		if span.footnote > 0 {
+			return fmt.Sprintf("[^%d]", span.footnote)
+		}
+		text := span.text
+		if span.tag != "code" {
+			text = escapeMarkdown(text)
+		}
+		text = strings.ReplaceAll(text, "\u2028", "\\\n")
+		if span.tag == "" {
+			return text
+		}
+		return wrapTrimmed(text, func(inner string) string {
+			if delim, ok := markdownTags[span.tag]; ok {
+				return delim + inner + delim
+			}
+			return "<" + span.tag + ">" + inner + "</" + span.tag + ">"
+		})
+	}, func(text, url string) string {
+		return "[" + text + "](" + strings.ReplaceAll(url, ")", "%29") + ")"
+	})
+}
+
+// htmlInline renders the spans of a paragraph as HTML.
+func htmlInline(spans []markupSpan) string {
+	return renderInline(spans, func(span markupSpan) string {
+		if span.footnote > 0 {
+			return fmt.Sprintf("<sup><a href=\"#fn%d\" id=\"fnref%d\">%d</a></sup>", span.footnote, span.footnote, span.footnote)
+		}
+		text := strings.ReplaceAll(html.EscapeString(span.text), "\u2028", "<br>")
+		if span.tag == "" {
+			return text
+		}
+		return wrapTrimmed(text, func(inner string) string {
+			return "<" + span.tag + ">" + inner + "</" + span.tag + ">"
+		})
+	}, func(text, url string) string {
+		return "<a href=\"" + html.EscapeString(url) + "\">" + text + "</a>"
+	})
+}
+
+// renderInline renders spans with span, wrapping runs of spans with the same
+// link with link, and trims the paragraph.
+func renderInline(spans []markupSpan, span func(markupSpan) string, link func(text, url string) string) string {
+	var buf strings.Builder
+	for i := 0; i < len(spans); {
+		if spans[i].link == "" || spans[i].footnote > 0 {
+			buf.WriteString(span(spans[i]))
+			i++
+			continue
+		}
+		var inner strings.Builder
+		j := i
+		for ; j < len(spans) && spans[j].link == spans[i].link && spans[j].footnote == 0; j++ {
+			inner.WriteString(span(spans[j]))
+		}
+		buf.WriteString(wrapTrimmed(inner.String(), func(text string) string {
+			return link(text, spans[i].link)
+		}))
+		i = j
+	}
+	return strings.TrimSpace(buf.String())
+}
+
+// wrapTrimmed wraps text with wrap, keeping leading and trailing white space
+// outside, where Markdown needs it.
+func wrapTrimmed(text string, wrap func(string) string) string {
+	inner := strings.TrimSpace(text)
+	if inner == "" {
+		return text
+	}
+	start := strings.Index(text, inner)
+	return text[:start] + wrap(inner) + text[start+len(inner):]
+}
+
+// escapeMarkdown escapes characters with a meaning in Markdown text.
+func escapeMarkdown(text string) string {
+	var buf strings.Builder
+	for _, r := range text {
+		if strings.ContainsRune("\\`*_[]<>", r) {
+			buf.WriteRune('\\')
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
+
+// escapeMarkdownStart escapes a paragraph start that Markdown would read as
+// a heading, list item or block quote.
+func escapeMarkdownStart(text string) string {
+	if text == "" {
+		return text
+	}
+	if strings.ContainsRune("#-+>", rune(text[0])) {
+		return "\\" + text
+	}
+	digits := strings.IndexFunc(text, func(r rune) bool { return !unicode.IsDigit(r) })
+	if digits > 0 && (text[digits] == '.' || text[digits] == ')') {
+		return text[:digits] + "\\" + text[digits:]
+	}
+	return text
+}
@@ -0,0 +1,143 @@
+package story
+
+import (
+	"testing"
+)
+
+const markupStoryXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<idPkg:Story xmlns:idPkg="http://ns.adobe.com/AdobeInDesign/idml/1.0/packaging" DOMVersion="20.4">
+	<Story Self="u100">
+		<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/Headline">
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+				<Content>Budget &amp; taxes</Content>
+				<Br/>
+			</CharacterStyleRange>
+		</ParagraphStyleRange>
+		<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/Body%3aText">
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+				<Content>The council met </Content>
+			</CharacterStyleRange>
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/Bold">
+				<Content>on Monday </Content>
+			</CharacterStyleRange>
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+				<Content>to vote</Content>
+				<Footnote>
+					<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/Footnote">
+						<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+							<Content><?ACE 4?>	Minutes, page 4.</Content>
+						</CharacterStyleRange>
+					</ParagraphStyleRange>
+				</Footnote>
+				<Content>. See </Content>
+				<HyperlinkTextSource Self="u200" Name="Source" Hidden="false" AppliedCharacterStyle="n">
+					<Content>the agenda</Content>
+				</HyperlinkTextSource>
+				<Content> for *all* items.</Content>
+				<Br/>
+				<Br/>
+			</CharacterStyleRange>
+		</ParagraphStyleRange>
+		<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/Bullet">
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+				<Content>Roads</Content>
+				<Br/>
+				<Content>Schools&#x2028;and libraries</Content>
+				<Br/>
+			</CharacterStyleRange>
+		</ParagraphStyleRange>
+		<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/Body%3aText">
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/Superscript">
+				<Content>1. Closing</Content>
+			</CharacterStyleRange>
+		</ParagraphStyleRange>
+	</Story>
+</idPkg:Story>`
+
+// markupTestOptions maps the styles of markupStoryXML.
+func markupTestOptions() MarkupOptions {
+	return MarkupOptions{
+		ParagraphTags: map[string]string{
+			"ParagraphStyle/Headline": "h1",
+			"Bullet":                  "ul",
+			"Body:Text":               "p",
+		},
+		CharacterTags: map[string]string{
+			"CharacterStyle/Bold": "strong",
+			"Superscript":         "sup",
+		},
+		Links: map[string]string{"u200": "https://example.com/agenda"},
+	}
+}
+
+// TestExportMarkdown tests exporting paragraphs, styles, footnotes, links
+// and breaks as Markdown.
+func TestExportMarkdown(t *testing.T) {
+	st, err := ParseStory([]byte(markupStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+
+	want := "# Budget & taxes\n" +
+		"\n" +
+		"The council met **on Monday** to vote[^1]. See [the agenda](https://example.com/agenda) for \\*all\\* items.\n" +
+		"\n" +
+		"- Roads\n" +
+		"- Schools\\\nand libraries\n" +
+		"\n" +
+		"<sup>1. Closing</sup>\n" +
+		"\n" +
+		"[^1]: Minutes, page 4.\n"
+	if got := st.ExportMarkdown(markupTestOptions()); got != want {
+		t.Errorf("ExportMarkdown() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// TestExportHTML tests exporting paragraphs, styles, footnotes, links and
+// breaks as HTML.
+func TestExportHTML(t *testing.T) {
+	st, err := ParseStory([]byte(markupStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+
+	want := "<h1>Budget &amp; taxes</h1>\n" +
+		"<p>The council met <strong>on Monday</strong> to vote<sup><a href=\"#fn1\" id=\"fnref1\">1</a></sup>. See <a href=\"https://example.com/agenda\">the agenda</a> for *all* items.</p>\n" +
+		"<ul>\n" +
+		"<li>Roads</li>\n" +
+		"<li>Schools<br>and libraries</li>\n" +
+		"</ul>\n" +
+		"<p><sup>1. Closing</sup></p>\n" +
+		"<section class=\"footnotes\">\n<ol>\n<li id=\"fn1\">Minutes, page 4.</li>\n</ol>\n</section>\n"
+	if got := st.ExportHTML(markupTestOptions()); got != want {
+		t.Errorf("ExportHTML() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// TestExportMarkupDefaults tests exporting without style mappings and links.
+func TestExportMarkupDefaults(t *testing.T) {
+	st, err := ParseStory([]byte(markupStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+
+	want := "Budget & taxes\n" +
+		"\n" +
+		"The council met on Monday to vote[^1]. See the agenda for \\*all\\* items.\n" +
+		"\n" +
+		"Roads\n" +
+		"\n" +
+		"Schools\\\nand libraries\n" +
+		"\n" +
+		"1\\. Closing\n" +
+		"\n" +
+		"[^1]: Minutes, page 4.\n"
+	if got := st.ExportMarkdown(MarkupOptions{}); got != want {
+		t.Errorf("ExportMarkdown() =\n%s\nwant\n%s", got, want)
+	}
+
+	empty := &Story{}
+	if got := empty.ExportHTML(MarkupOptions{}); got != "" {
+		t.Errorf("ExportHTML() of an empty story = %q", got)
+	}
+}
@@ -0,0 +1,713 @@
+package story
+
+import (
+	"encoding/xml"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+)
+
+// MarkupImportOptions maps Markdown and HTML to InDesign styles for
+// NewStoryFromMarkdown and NewStoryFromHTML, the reverse of MarkupOptions.
+//
+// Style values are AppliedParagraphStyle or AppliedCharacterStyle references
+// (e.g., "ParagraphStyle/Headline"); see idml.Package.ImportStoryMarkdown for
+// mapping to style names of a package.
+type MarkupImportOptions struct {
+	// ParagraphStyles maps "h1" ... "h6", "p", "blockquote", "ul", "ol" (an
+	// item of a bulleted or numbered list) and "footnote" (footnote text) to
+	// paragraph styles. Unmapped tags take the style of "p", which defaults
+	// to the normal paragraph style.
+	ParagraphStyles map[string]string
+
+	// CharacterStyles maps "strong", "em", "code", "s" and other HTML tags,
+	// such as "sup" or "sub", to character styles. Text in unmapped tags has
+	// no character style; in nested tags the innermost mapped tag wins.
+	CharacterStyles map[string]string
+}
+
+const (
+	// normalParagraphStyle is the paragraph style of unmapped paragraphs.
+	normalParagraphStyle = "ParagraphStyle/$ID/NormalParagraphStyle"
+
+	// forcedLineBreak is the character InDesign stores for a forced line break.
+	forcedLineBreak = "\u2028"
+)
+
+// markupImporter builds paragraphs and spans from Markdown or HTML.
+type markupImporter struct {
+	opts      MarkupImportOptions
+	paras     []*markupParagraph
+	cur       *markupParagraph // paragraph receiving text, nil between paragraphs
+	note      *markupParagraph // footnote receiving text between paragraphs
+	block     string           // paragraph tag for text outside a paragraph
+	tags      []string         // open character tags, innermost last
+	labels    map[string]int   // footnote labels to numbers
+	footnotes map[int]*markupParagraph
+}
+
+// NewStoryFromMarkdown builds a story from Markdown. Paragraphs are
+// separated by blank lines, headings, list items and block quotes; hard line
+// breaks become forced line breaks and footnotes ("[^1]") become footnotes
+// in the "footnote" paragraph style.
+//
+// Link text is kept without the link, as hyperlinks are document resources;
+// code blocks, tables and images are not supported. The returned story has
+// no Self: set it, or use idml.Package.ImportStoryMarkdown to replace a
+// story of a package.
+//
+// Example:
+//
+//	st := story.NewStoryFromMarkdown("# Budget\n\nThe **council** met.", story.MarkupImportOptions{
+//	    ParagraphStyles: map[string]string{"h1": "ParagraphStyle/Headline"},
+//	    CharacterStyles: map[string]string{"strong": "CharacterStyle/Bold"},
+//	})
+func NewStoryFromMarkdown(src string, opts MarkupImportOptions) *Story {
+	imp := newMarkupImporter(opts)
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+
+	// Footnote definitions are parsed after the text, which numbers them
+	var body []string
+	type definition struct{ label, text string }
+	var defs []definition
+	for _, line := range lines {
+		if m := markdownFootnoteDef.FindStringSubmatch(line); m != nil {
+			defs = append(defs, definition{m[1], m[2]})
+			continue
+		}
+		if len(defs) > 0 && len(body) > 0 && body[len(body)-1] == "" && strings.HasPrefix(line, "    ") {
+			// Indented continuation of the last footnote definition
+			defs[len(defs)-1].text += " " + strings.TrimSpace(line)
+			continue
+		}
+		body = append(body, line)
+	}
+
+	var block []string
+	tag := ""
+	flush := func() {
+		if len(block) > 0 {
+			imp.startParagraph(tag)
+			imp.markdownInline(joinMarkdownLines(block))
+			imp.endParagraph()
+		}
+		block = nil
+	}
+	for _, line := range body {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			flush()
+		case markdownHeading.MatchString(trimmed):
+			flush()
+			m := markdownHeading.FindStringSubmatch(trimmed)
+			tag, block = "h"+string(rune('0'+len(m[1]))), []string{m[2]}
+			flush()
+		case markdownThematicBreak.MatchString(trimmed):
+			flush()
+		case markdownBullet.MatchString(trimmed):
+			flush()
+			tag, block = "ul", []string{markdownBullet.ReplaceAllString(trimmed, "")}
+		case markdownNumbered.MatchString(trimmed):
+			flush()
+			tag, block = "ol", []string{markdownNumbered.ReplaceAllString(trimmed, "")}
+		case strings.HasPrefix(trimmed, ">"):
+			text := strings.TrimPrefix(strings.TrimPrefix(trimmed, ">"), " ")
+			if tag != "blockquote" || strings.TrimSpace(text) == "" {
+				flush()
+			}
+			tag = "blockquote"
+			if strings.TrimSpace(text) != "" {
+				block = append(block, text)
+			}
+		default:
+			if len(block) == 0 {
+				tag = "p"
+			}
+			block = append(block, line)
+		}
+	}
+	flush()
+
+	for _, def := range defs {
+		imp.cur = imp.footnote(def.label)
+		imp.markdownInline(strings.TrimSpace(def.text))
+		imp.endParagraph()
+	}
+	return imp.story()
+}
+
+// NewStoryFromHTML builds a story from a limited subset of HTML: <p>, <h1>
+// to <h6>, <blockquote> and list items of <ul> and <ol> become paragraphs,
+// <br> a forced line break and inline elements such as <strong> or <sup>
+// character style ranges. Links to "#fn1" in a <sup>, with the footnote text
+// in the list items of a <section class="footnotes">, become footnotes, as
+// written by ExportHTML.
+//
+// Whitespace is collapsed as in a browser; <head>, <script> and <style> are
+// skipped. See NewStoryFromMarkdown for what is not supported.
+//
+// Returns an error wrapping common.ErrInvalidFormat if src cannot be parsed.
+func NewStoryFromHTML(src string, opts MarkupImportOptions) (*Story, error) {
+	imp := newMarkupImporter(opts)
+	d := xml.NewDecoder(strings.NewReader("<html>" + src + "</html>"))
+	d.Strict = false
+	d.AutoClose = xml.HTMLAutoClose
+	d.Entity = xml.HTMLEntity
+
+	type block struct {
+		name, tag string
+		note      *markupParagraph
+		footnotes bool
+	}
+	var blocks []block
+	skip := 0
+	footnotes := false
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, common.Errorf("story", "import HTML", "", "%w: %v", common.ErrInvalidFormat, err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			switch {
+			case skip > 0 || htmlSkipped[name]:
+				skip++
+			case name == "br":
+				imp.lineBreak()
+			case name == "a" && isFootnoteLink(t):
+				imp.reference(strings.TrimPrefix(htmlAttr(t, "href"), "#"))
+				skip++
+			case htmlBlocks[name]:
+				imp.endParagraph()
+				blocks = append(blocks, block{name, imp.block, imp.note, footnotes})
+				switch {
+				case name == "section" && strings.Contains(htmlAttr(t, "class"), "footnotes"):
+					footnotes = true
+				case footnotes && name == "li":
+					imp.note = imp.footnote(htmlAttr(t, "id"))
+				case name == "blockquote" || name == "ul" || name == "ol" || isHeading(name):
+					imp.block = name
+				}
+			default:
+				imp.tags = append(imp.tags, htmlTag(name))
+			}
+
+		case xml.EndElement:
+			name := strings.ToLower(t.Name.Local)
+			switch {
+			case skip > 0:
+				skip--
+			case htmlBlocks[name]:
+				imp.endParagraph()
+				// Close the innermost open block of this name and those inside it
+				for i := len(blocks) - 1; i >= 0; i-- {
+					if blocks[i].name == name {
+						imp.block, imp.note, footnotes = blocks[i].tag, blocks[i].note, blocks[i].footnotes
+						blocks = blocks[:i]
+						break
+					}
+				}
+			case name != "br":
+				imp.closeTag(htmlTag(name))
+			}
+
+		case xml.CharData:
+			if skip == 0 {
+				imp.htmlText(string(t))
+			}
+		}
+	}
+	imp.endParagraph()
+	return imp.story(), nil
+}
+
+var (
+	markdownHeading       = regexp.MustCompile(`^(#{1,6})\s+(.*?)(?:\s+#+)?$`)
+	markdownBullet        = regexp.MustCompile(`^[-*+]\s+`)
+	markdownNumbered      = regexp.MustCompile(`^\d+[.)]\s+`)
+	markdownThematicBreak = regexp.MustCompile(`^(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	markdownFootnoteDef   = regexp.MustCompile(`^\[\^([^\]]+)\]:\s*(.*)$`)
+	markdownFootnoteRef   = regexp.MustCompile(`^\[\^([^\]]+)\]`)
+	markdownInlineHTML    = regexp.MustCompile(`^<(/?)([a-zA-Z][a-zA-Z0-9]*)[^<>]*?(/?)>`)
+	htmlWhitespace        = regexp.MustCompile(`\s+`)
+)
+
+// htmlBlocks are the HTML elements that end the current paragraph.
+var htmlBlocks = map[string]bool{
+	"html": true, "body": true, "main": true, "article": true, "section": true,
+	"header": true, "footer": true, "aside": true, "nav": true, "div": true,
+	"p": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"blockquote": true, "ul": true, "ol": true, "li": true, "pre": true,
+	"table": true, "tr": true, "td": true, "th": true, "hr": true, "figure": true,
+}
+
+// htmlSkipped are the HTML elements whose content is not text.
+var htmlSkipped = map[string]bool{
+	"head": true, "script": true, "style": true, "template": true, "title": true,
+}
+
+// htmlTag returns the character tag for an HTML element, mapping the
+// presentational elements to their semantic counterparts.
+func htmlTag(name string) string {
+	switch name {
+	case "b":
+		return "strong"
+	case "i":
+		return "em"
+	case "del", "strike":
+		return "s"
+	}
+	return name
+}
+
+// isHeading reports whether name is "h1" ... "h6".
+func isHeading(name string) bool {
+	return len(name) == 2 && name[0] == 'h' && name[1] >= '1' && name[1] <= '6'
+}
+
+// isFootnoteLink reports whether a is a footnote reference as written by
+// ExportHTML.
+func isFootnoteLink(a xml.StartElement) bool {
+	href := htmlAttr(a, "href")
+	return strings.HasPrefix(href, "#fn") && !strings.HasPrefix(href, "#fnref")
+}
+
+// htmlAttr returns the value of an attribute of an HTML element.
+func htmlAttr(e xml.StartElement, name string) string {
+	for _, attr := range e.Attr {
+		if strings.EqualFold(attr.Name.Local, name) {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// joinMarkdownLines joins the lines of a Markdown paragraph: lines ending in
+// a backslash or two spaces end with a hard line break, others are joined
+// with a space.
+func joinMarkdownLines(lines []string) string {
+	var buf strings.Builder
+	for i, line := range lines {
+		line = strings.TrimLeftFunc(line, unicode.IsSpace)
+		if i == len(lines)-1 {
+			buf.WriteString(strings.TrimRightFunc(line, unicode.IsSpace))
+			break
+		}
+		switch {
+		case strings.HasSuffix(line, "\\") && !strings.HasSuffix(line, "\\\\"):
+			buf.WriteString(strings.TrimSuffix(line, "\\") + forcedLineBreak)
+		case strings.HasSuffix(line, "  "):
+			buf.WriteString(strings.TrimRightFunc(line, unicode.IsSpace) + forcedLineBreak)
+		default:
+			buf.WriteString(strings.TrimRightFunc(line, unicode.IsSpace) + " ")
+		}
+	}
+	return buf.String()
+}
+
+func newMarkupImporter(opts MarkupImportOptions) *markupImporter {
+	return &markupImporter{
+		opts:      opts,
+		block:     "p",
+		labels:    make(map[string]int),
+		footnotes: make(map[int]*markupParagraph),
+	}
+}
+
+// startParagraph starts a paragraph with the given tag.
+func (imp *markupImporter) startParagraph(tag string) {
+	imp.cur = &markupParagraph{tag: tag}
+	imp.paras = append(imp.paras, imp.cur)
+}
+
+// endParagraph ends the current paragraph, trimming trailing spaces.
+func (imp *markupImporter) endParagraph() {
+	if imp.cur != nil {
+		spans := imp.cur.spans
+		for len(spans) > 0 && spans[len(spans)-1].footnote == 0 {
+			last := &spans[len(spans)-1]
+			last.text = strings.TrimRight(last.text, " ")
+			if last.text != "" {
+				break
+			}
+			spans = spans[:len(spans)-1]
+		}
+		imp.cur.spans = spans
+	}
+	imp.cur = nil
+}
+
+// footnote returns the text paragraph of the footnote with the given label.
+func (imp *markupImporter) footnote(label string) *markupParagraph {
+	n := imp.number(label)
+	if imp.footnotes[n] == nil {
+		imp.footnotes[n] = &markupParagraph{tag: "footnote"}
+	}
+	return imp.footnotes[n]
+}
+
+// number returns the number of a footnote label, numbering new labels.
+func (imp *markupImporter) number(label string) int {
+	if n, ok := imp.labels[label]; ok {
+		return n
+	}
+	imp.labels[label] = len(imp.labels) + 1
+	return len(imp.labels)
+}
+
+// text adds text in the innermost open character tag, starting a paragraph
+// in the current block if none is open.
+func (imp *markupImporter) text(text string) {
+	if text == "" {
+		return
+	}
+	imp.open()
+	tag := ""
+	for i := len(imp.tags) - 1; i >= 0; i-- {
+		if imp.opts.CharacterStyles[imp.tags[i]] != "" {
+			tag = imp.tags[i]
+			break
+		}
+	}
+	spans := imp.cur.spans
+	if n := len(spans); n > 0 && spans[n-1].footnote == 0 && spans[n-1].tag == tag {
+		spans[n-1].text += text
+		return
+	}
+	imp.cur.spans = append(spans, markupSpan{text: text, tag: tag})
+}
+
+// open makes sure a paragraph receives text: the current footnote or a new
+// paragraph in the current block.
+func (imp *markupImporter) open() {
+	switch {
+	case imp.cur != nil:
+	case imp.note != nil:
+		imp.cur = imp.note
+	default:
+		imp.startParagraph(imp.block)
+	}
+}
+
+// lineBreak adds a forced line break.
+func (imp *markupImporter) lineBreak() {
+	imp.text(forcedLineBreak)
+}
+
+// reference adds a reference to the footnote with the given label, except in
+// footnote text.
+func (imp *markupImporter) reference(label string) {
+	imp.open()
+	if imp.cur.tag == "footnote" {
+		return
+	}
+	imp.cur.spans = append(imp.cur.spans, markupSpan{footnote: imp.number(label)})
+}
+
+// closeTag closes the innermost open character tag of the given name.
+func (imp *markupImporter) closeTag(tag string) {
+	for i := len(imp.tags) - 1; i >= 0; i-- {
+		if imp.tags[i] == tag {
+			imp.tags = append(imp.tags[:i], imp.tags[i+1:]...)
+			return
+		}
+	}
+}
+
+// isOpen reports whether a character tag is open.
+func (imp *markupImporter) isOpen(tag string) bool {
+	for _, open := range imp.tags {
+		if open == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// htmlText adds HTML text with whitespace collapsed.
+func (imp *markupImporter) htmlText(text string) {
+	text = htmlWhitespace.ReplaceAllString(text, " ")
+	if imp.cur == nil && imp.note == nil || imp.endsWithSpace() {
+		text = strings.TrimLeft(text, " ")
+	}
+	imp.text(text)
+}
+
+// endsWithSpace reports whether the current paragraph is empty or ends with
+// a space or line break.
+func (imp *markupImporter) endsWithSpace() bool {
+	para := imp.cur
+	if para == nil {
+		para = imp.note
+	}
+	if para == nil {
+		return true
+	}
+	spans := para.spans
+	if len(spans) == 0 {
+		return true
+	}
+	last := spans[len(spans)-1].text
+	return last != "" && (strings.HasSuffix(last, " ") || strings.HasSuffix(last, forcedLineBreak))
+}
+
+// markdownInline adds the inline content of a Markdown paragraph: emphasis,
+// code spans, strikethrough, inline HTML elements, links, footnote
+// references and backslash escapes. Tags left open end with the paragraph.
+func (imp *markupImporter) markdownInline(s string) {
+	imp.markdownSpans(s)
+	imp.tags = nil
+}
+
+// markdownSpans adds Markdown inline content, such as the text of a link.
+func (imp *markupImporter) markdownSpans(s string) {
+	var text strings.Builder
+	flush := func() {
+		imp.text(html.UnescapeString(text.String()))
+		text.Reset()
+	}
+
+	for i := 0; i < len(s); {
+		rest := s[i:]
+		switch {
+		case rest[0] == '\\' && len(rest) > 1 && isASCIIPunct(rest[1]):
+			text.WriteByte(rest[1])
+			i += 2
+
+		case markdownFootnoteRef.MatchString(rest):
+			m := markdownFootnoteRef.FindStringSubmatch(rest)
+			flush()
+			imp.reference(m[1])
+			i += len(m[0])
+
+		case rest[0] == '[':
+			label, n := markdownLink(rest)
+			if n == 0 {
+				text.WriteByte('[')
+				i++
+				break
+			}
+			flush()
+			imp.markdownSpans(label)
+			i += n
+
+		case rest[0] == '`':
+			end := strings.IndexByte(rest[1:], '`')
+			if end < 0 {
+				text.WriteByte('`')
+				i++
+				break
+			}
+			flush()
+			imp.tags = append(imp.tags, "code")
+			imp.text(rest[1 : end+1])
+			imp.closeTag("code")
+			i += end + 2
+
+		case rest[0] == '<' && markdownInlineHTML.MatchString(rest):
+			m := markdownInlineHTML.FindStringSubmatch(rest)
+			flush()
+			name := htmlTag(strings.ToLower(m[2]))
+			switch {
+			case name == "br":
+				imp.lineBreak()
+			case m[1] == "/":
+				imp.closeTag(name)
+			case m[3] == "":
+				imp.tags = append(imp.tags, name)
+			}
+			i += len(m[0])
+
+		default:
+			delim, tag := markdownDelimiter(rest)
+			if delim == "" || !imp.isDelimiter(s, i, delim, tag) {
+				text.WriteByte(rest[0])
+				i++
+				break
+			}
+			flush()
+			if imp.isOpen(tag) {
+				imp.closeTag(tag)
+			} else {
+				imp.tags = append(imp.tags, tag)
+			}
+			i += len(delim)
+		}
+	}
+	flush()
+}
+
+// markdownDelimiter returns the emphasis or strikethrough delimiter at the
+// start of s and its character tag.
+func markdownDelimiter(s string) (delim, tag string) {
+	for _, d := range []struct{ delim, tag string }{
+		{"**", "strong"}, {"__", "strong"}, {"~~", "s"}, {"*", "em"}, {"_", "em"},
+	} {
+		if strings.HasPrefix(s, d.delim) {
+			return d.delim, d.tag
+		}
+	}
+	return "", ""
+}
+
+// isDelimiter reports whether delim at s[i:] opens or closes tag: an opening
+// delimiter is followed by text and closed later in s, a closing one follows
+// text. Underscores inside words are text.
+func (imp *markupImporter) isDelimiter(s string, i int, delim, tag string) bool {
+	before, after := rune(' '), rune(' ')
+	if i > 0 {
+		before = lastRune(s[:i])
+	}
+	if j := i + len(delim); j < len(s) {
+		after = []rune(s[j:])[0]
+	}
+	if imp.isOpen(tag) {
+		if delim[0] == '_' && (unicode.IsLetter(after) || unicode.IsDigit(after)) {
+			return false
+		}
+		return !unicode.IsSpace(before)
+	}
+	if delim[0] == '_' && (unicode.IsLetter(before) || unicode.IsDigit(before)) {
+		return false
+	}
+	return !unicode.IsSpace(after) && strings.Contains(s[i+len(delim):], delim)
+}
+
+// markdownLink returns the text of a Markdown link "[text](url)" at the
+// start of s and its length, or 0 if s does not start with a link.
+func markdownLink(s string) (string, int) {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth > 0 {
+				continue
+			}
+			if !strings.HasPrefix(s[i+1:], "(") {
+				return "", 0
+			}
+			end := strings.IndexByte(s[i+1:], ')')
+			if end < 0 {
+				return "", 0
+			}
+			return s[1:i], i + 1 + end + 1
+		}
+	}
+	return "", 0
+}
+
+// isASCIIPunct reports whether c is an ASCII punctuation character, which
+// Markdown allows to be escaped with a backslash.
+func isASCIIPunct(c byte) bool {
+	return c < 0x80 && unicode.IsPunct(rune(c)) || strings.IndexByte("$+<=>^`|~", c) >= 0
+}
+
+// lastRune returns the last rune of s.
+func lastRune(s string) rune {
+	r := []rune(s)
+	return r[len(r)-1]
+}
+
+// story builds the story from the collected paragraphs. Consecutive
+// paragraphs in the same style share a paragraph style range.
+func (imp *markupImporter) story() *Story {
+	st := &Story{StoryElement: StoryElement{XMLName: xml.Name{Local: "Story"}}}
+	ranges := &st.StoryElement.ParagraphStyleRanges
+	for i, para := range imp.paras {
+		style := imp.paragraphStyle(para.tag)
+		if n := len(*ranges); n == 0 || (*ranges)[n-1].AppliedParagraphStyle != style {
+			*ranges = append(*ranges, ParagraphStyleRange{
+				XMLName:               xml.Name{Local: "ParagraphStyleRange"},
+				AppliedParagraphStyle: style,
+			})
+		}
+		psr := &(*ranges)[len(*ranges)-1]
+		imp.addSpans(psr, para.spans)
+		if i < len(imp.paras)-1 {
+			csr := &psr.CharacterStyleRanges[len(psr.CharacterStyleRanges)-1]
+			csr.Children = append(csr.Children, CharacterChild{Br: &Br{XMLName: xml.Name{Local: "Br"}}})
+		}
+	}
+	return st
+}
+
+// addSpans adds the spans of a paragraph to psr, starting a character style
+// range when the style changes. psr always ends up with a range.
+func (imp *markupImporter) addSpans(psr *ParagraphStyleRange, spans []markupSpan) {
+	for _, span := range spans {
+		style := imp.characterStyle(span.tag)
+		if span.footnote > 0 && len(psr.CharacterStyleRanges) > 0 {
+			// The reference takes the style of the preceding character
+			style = psr.CharacterStyleRanges[len(psr.CharacterStyleRanges)-1].AppliedCharacterStyle
+		}
+		if n := len(psr.CharacterStyleRanges); n == 0 || psr.CharacterStyleRanges[n-1].AppliedCharacterStyle != style {
+			psr.CharacterStyleRanges = append(psr.CharacterStyleRanges, NewCharacterStyleRange(style, nil))
+		}
+		csr := &psr.CharacterStyleRanges[len(psr.CharacterStyleRanges)-1]
+		if span.footnote > 0 {
+			csr.Children = append(csr.Children, CharacterChild{Footnote: imp.buildFootnote(span.footnote)})
+			continue
+		}
+		if n := len(csr.Children); n > 0 && csr.Children[n-1].Content != nil {
+			csr.Children[n-1].Content.Text += span.text
+			continue
+		}
+		csr.Children = append(csr.Children, newContentChild(span.text))
+	}
+	if len(psr.CharacterStyleRanges) == 0 {
+		psr.CharacterStyleRanges = append(psr.CharacterStyleRanges, NewCharacterStyleRange("", nil))
+	}
+}
+
+// buildFootnote builds footnote n, starting with the footnote number marker.
+func (imp *markupImporter) buildFootnote(n int) *Footnote {
+	psr := ParagraphStyleRange{
+		XMLName:               xml.Name{Local: "ParagraphStyleRange"},
+		AppliedParagraphStyle: imp.paragraphStyle("footnote"),
+	}
+	if para := imp.footnotes[n]; para != nil {
+		imp.addSpans(&psr, para.spans)
+	} else {
+		imp.addSpans(&psr, nil)
+	}
+	first := &psr.CharacterStyleRanges[0]
+	first.Children = append([]CharacterChild{newFootnoteMarker()}, first.Children...)
+	return &Footnote{
+		XMLName:              xml.Name{Local: "Footnote"},
+		ParagraphStyleRanges: []ParagraphStyleRange{psr},
+	}
+}
+
+// paragraphStyle returns the paragraph style of a paragraph tag.
+func (imp *markupImporter) paragraphStyle(tag string) string {
+	if style := imp.opts.ParagraphStyles[tag]; style != "" {
+		return style
+	}
+	if style := imp.opts.ParagraphStyles["p"]; style != "" {
+		return style
+	}
+	return normalParagraphStyle
+}
+
+// characterStyle returns the character style of a character tag.
+func (imp *markupImporter) characterStyle(tag string) string {
+	if style := imp.opts.CharacterStyles[tag]; style != "" {
+		return style
+	}
+	return "CharacterStyle/$ID/[No character style]"
+}
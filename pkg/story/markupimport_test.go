@@ -0,0 +1,184 @@
+package story
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+)
+
+// markupImportTestOptions maps tags to the styles of markupStoryXML.
+func markupImportTestOptions() MarkupImportOptions {
+	return MarkupImportOptions{
+		ParagraphStyles: map[string]string{
+			"h1":       "ParagraphStyle/Headline",
+			"p":        "ParagraphStyle/Body%3aText",
+			"ul":       "ParagraphStyle/Bullet",
+			"footnote": "ParagraphStyle/Footnote",
+		},
+		CharacterStyles: map[string]string{
+			"strong": "CharacterStyle/Bold",
+			"sup":    "CharacterStyle/Superscript",
+		},
+	}
+}
+
+// TestMarkupImportRoundtrip tests that exported Markdown and HTML import back
+// into the same paragraphs, styles and footnotes.
+func TestMarkupImportRoundtrip(t *testing.T) {
+	st, err := ParseStory([]byte(markupStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+	// Hyperlinks are not imported
+	exportOpts := markupTestOptions()
+	exportOpts.Links = nil
+	md := st.ExportMarkdown(exportOpts)
+	htm := st.ExportHTML(exportOpts)
+
+	fromHTML, err := NewStoryFromHTML(htm, markupImportTestOptions())
+	if err != nil {
+		t.Fatalf("NewStoryFromHTML failed: %v", err)
+	}
+	for name, imported := range map[string]*Story{
+		"markdown": NewStoryFromMarkdown(md, markupImportTestOptions()),
+		"html":     fromHTML,
+	} {
+		t.Run(name, func(t *testing.T) {
+			if got := imported.ExportMarkdown(exportOpts); got != md {
+				t.Errorf("re-exported Markdown =\n%s\nwant\n%s", got, md)
+			}
+			if got := imported.ExportHTML(exportOpts); got != htm {
+				t.Errorf("re-exported HTML =\n%s\nwant\n%s", got, htm)
+			}
+
+			ranges := imported.StoryElement.ParagraphStyleRanges
+			if len(ranges) != 4 || ranges[2].AppliedParagraphStyle != "ParagraphStyle/Bullet" {
+				t.Fatalf("got %d paragraph ranges, want 4 with the list third", len(ranges))
+			}
+			footnotes := imported.Footnotes()
+			if len(footnotes) != 1 || footnotes[0].Text() != "Minutes, page 4." {
+				t.Fatalf("Footnotes() = %+v, want one footnote", footnotes)
+			}
+			if got := footnotes[0].ParagraphStyleRanges[0].AppliedParagraphStyle; got != "ParagraphStyle/Footnote" {
+				t.Errorf("footnote paragraph style = %q", got)
+			}
+			if err := imported.InsertText(0, "x"); err != nil {
+				t.Errorf("InsertText on the imported story failed: %v", err)
+			}
+		})
+	}
+}
+
+// TestNewStoryFromMarkdown tests Markdown blocks and inline syntax.
+func TestNewStoryFromMarkdown(t *testing.T) {
+	src := "## Roads &amp; rails ##\n" +
+		"\n" +
+		"A *short* note_with_underscores about `a*b`, **bold _and italic_** and\n" +
+		"a [link](https://example.com) \\*not emphasis\\* ~~gone~~ H<sub>2</sub>O.  \n" +
+		"Next line<br>and another.\n" +
+		"\n" +
+		"> Quoted\n" +
+		"> text\n" +
+		"\n" +
+		"1. First\n" +
+		"2) Second * star\n" +
+		"\n" +
+		"---\n"
+	opts := MarkupImportOptions{
+		ParagraphStyles: map[string]string{"h2": "ParagraphStyle/Subhead", "ol": "ParagraphStyle/Numbered", "blockquote": "ParagraphStyle/Quote"},
+		CharacterStyles: map[string]string{"em": "CharacterStyle/Italic", "strong": "CharacterStyle/Bold", "code": "CharacterStyle/Code", "s": "CharacterStyle/Strike", "sub": "CharacterStyle/Sub"},
+	}
+	st := NewStoryFromMarkdown(src, opts)
+
+	want := "Roads & rails\n" +
+		"A short note_with_underscores about a*b, bold and italic and a link *not emphasis* gone H2O. Next line and another.\n" +
+		"Quoted text\n" +
+		"First\n" +
+		"Second * star"
+	if got := st.ExtractText(); got != want {
+		t.Errorf("ExtractText() = %q, want %q", got, want)
+	}
+
+	var styles []string
+	for _, psr := range st.StoryElement.ParagraphStyleRanges {
+		styles = append(styles, psr.AppliedParagraphStyle)
+	}
+	wantStyles := []string{"ParagraphStyle/Subhead", normalParagraphStyle, "ParagraphStyle/Quote", "ParagraphStyle/Numbered"}
+	if len(styles) != len(wantStyles) {
+		t.Fatalf("paragraph styles = %v, want %v", styles, wantStyles)
+	}
+	for i := range styles {
+		if styles[i] != wantStyles[i] {
+			t.Errorf("paragraph style %d = %q, want %q", i, styles[i], wantStyles[i])
+		}
+	}
+
+	runs := map[string]string{}
+	for _, csr := range st.StoryElement.ParagraphStyleRanges[1].CharacterStyleRanges {
+		for _, c := range csr.GetContent() {
+			runs[c.Text] = csr.AppliedCharacterStyle
+		}
+	}
+	for text, style := range map[string]string{
+		"short":      "CharacterStyle/Italic",
+		"a*b":        "CharacterStyle/Code",
+		"bold ":      "CharacterStyle/Bold",
+		"and italic": "CharacterStyle/Italic",
+		"gone":       "CharacterStyle/Strike",
+		"2":          "CharacterStyle/Sub",
+	} {
+		if runs[text] != style {
+			t.Errorf("style of %q = %q, want %q (runs %v)", text, runs[text], style, runs)
+		}
+	}
+}
+
+// TestNewStoryFromHTML tests HTML blocks, whitespace, entities and skipped
+// elements.
+func TestNewStoryFromHTML(t *testing.T) {
+	src := "<!DOCTYPE html><html><head><title>Ignored</title><style>p { x: y }</style></head><body>\n" +
+		"<h2>  Roads &amp;\n  rails&nbsp;</h2>\n" +
+		"<div><p>Some <b>bold</b> and <i>italic</i><br/>text.</p></div>\n" +
+		"<blockquote><p>Quoted</p><p>twice</p></blockquote>\n" +
+		"<ol><li>One</li><li><p>Two</p></li></ol>\n" +
+		"Loose <span>text</span>\n" +
+		"<script>alert(1)</script>\n" +
+		"</body></html>"
+	opts := MarkupImportOptions{
+		ParagraphStyles: map[string]string{"h2": "ParagraphStyle/Subhead", "ol": "ParagraphStyle/Numbered", "blockquote": "ParagraphStyle/Quote"},
+		CharacterStyles: map[string]string{"strong": "CharacterStyle/Bold", "em": "CharacterStyle/Italic"},
+	}
+	st, err := NewStoryFromHTML(src, opts)
+	if err != nil {
+		t.Fatalf("NewStoryFromHTML failed: %v", err)
+	}
+
+	want := "Roads & rails\u00a0\n" +
+		"Some bold and italic text.\n" +
+		"Quoted\n" +
+		"twice\n" +
+		"One\n" +
+		"Two\n" +
+		"Loose text"
+	if got := st.ExtractText(); got != want {
+		t.Errorf("ExtractText() = %q, want %q", got, want)
+	}
+	ranges := st.StoryElement.ParagraphStyleRanges
+	if len(ranges) != 5 {
+		t.Fatalf("got %d paragraph ranges, want 5", len(ranges))
+	}
+	if ranges[2].AppliedParagraphStyle != "ParagraphStyle/Quote" || ranges[3].AppliedParagraphStyle != "ParagraphStyle/Numbered" {
+		t.Errorf("paragraph styles = %q, %q", ranges[2].AppliedParagraphStyle, ranges[3].AppliedParagraphStyle)
+	}
+	if got := ranges[1].CharacterStyleRanges[1].AppliedCharacterStyle; got != "CharacterStyle/Bold" {
+		t.Errorf("style of <b> = %q, want CharacterStyle/Bold", got)
+	}
+
+	if _, err := NewStoryFromHTML("<p>a <b>b</i></p>", opts); !errors.Is(err, common.ErrInvalidFormat) {
+		t.Errorf("NewStoryFromHTML with mismatched tags error = %v, want ErrInvalidFormat", err)
+	}
+	if empty, err := NewStoryFromHTML(" ", opts); err != nil || len(empty.StoryElement.ParagraphStyleRanges) != 0 {
+		t.Errorf("NewStoryFromHTML of whitespace = %+v, %v", empty, err)
+	}
+}
@@ -115,6 +115,206 @@ func (s Story) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	return nil
 }
 
+// UnmarshalXML implements custom unmarshaling for StoryElement to read the
+// paragraph style ranges inside XMLElements.
+func (s *StoryElement) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	s.XMLName = start.Name
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "Self":
+			s.Self = attr.Value
+		case "UserText":
+			s.UserText = attr.Value
+		case "IsEndnoteStory":
+			s.IsEndnoteStory = attr.Value
+		case "AppliedTOCStyle":
+			s.AppliedTOCStyle = attr.Value
+		case "TrackChanges":
+			s.TrackChanges = attr.Value
+		case "StoryTitle":
+			s.StoryTitle = attr.Value
+		case "AppliedNamedGrid":
+			s.AppliedNamedGrid = attr.Value
+		default:
+			s.OtherAttrs = append(s.OtherAttrs, attr)
+		}
+	}
+
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			var err error
+			switch t.Name.Local {
+			case "StoryPreference":
+				s.StoryPreference = &StoryPreference{}
+				err = d.DecodeElement(s.StoryPreference, &t)
+			case "InCopyExportOption":
+				s.InCopyExportOption = &InCopyExportOption{}
+				err = d.DecodeElement(s.InCopyExportOption, &t)
+			case "ParagraphStyleRange":
+				var psr ParagraphStyleRange
+				if err = d.DecodeElement(&psr, &t); err == nil {
+					s.ParagraphStyleRanges = append(s.ParagraphStyleRanges, psr)
+				}
+			case "XMLElement":
+				var raw *common.RawXMLElement
+				raw, err = decodeEnclosingElement(d, &t, "ParagraphStyleRange", func(elem *common.RawXMLElement, enclosing []*XMLElement) error {
+					var psr ParagraphStyleRange
+					if err := decodeRaw(elem, &psr); err != nil {
+						return err
+					}
+					psr.XMLElements = enclosing
+					s.ParagraphStyleRanges = append(s.ParagraphStyleRanges, psr)
+					return nil
+				})
+				if raw != nil {
+					s.OtherElements = append(s.OtherElements, *raw)
+				}
+			default:
+				var raw common.RawXMLElement
+				if err = d.DecodeElement(&raw, &t); err == nil {
+					s.OtherElements = append(s.OtherElements, raw)
+				}
+			}
+			if err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// MarshalXML implements custom marshaling for StoryElement to write paragraph
+// style ranges inside the XMLElements enclosing them.
+func (s StoryElement) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	// Encoded on its own, the element would be named after the type
+	if start.Name.Local == "" || start.Name.Local == "StoryElement" {
+		start.Name = xml.Name{Local: "Story"}
+	}
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "Self"}, Value: s.Self})
+	start.Attr = appendAttrs(start.Attr,
+		"UserText", s.UserText,
+		"IsEndnoteStory", s.IsEndnoteStory,
+		"AppliedTOCStyle", s.AppliedTOCStyle,
+		"TrackChanges", s.TrackChanges,
+		"StoryTitle", s.StoryTitle,
+		"AppliedNamedGrid", s.AppliedNamedGrid,
+	)
+	start.Attr = append(start.Attr, s.OtherAttrs...)
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if s.StoryPreference != nil {
+		if err := e.Encode(s.StoryPreference); err != nil {
+			return err
+		}
+	}
+	if s.InCopyExportOption != nil {
+		if err := e.Encode(s.InCopyExportOption); err != nil {
+			return err
+		}
+	}
+	if err := encodeEnclosedRanges(e, s.ParagraphStyleRanges, func(psr *ParagraphStyleRange) []*XMLElement {
+		return psr.XMLElements
+	}); err != nil {
+		return err
+	}
+	for i := range s.OtherElements {
+		if err := e.Encode(&s.OtherElements[i]); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
+
+// UnmarshalXML implements custom unmarshaling for ParagraphStyleRange to read
+// the character style ranges inside XMLElements.
+func (p *ParagraphStyleRange) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	p.XMLName = start.Name
+	for _, attr := range start.Attr {
+		if attr.Name.Local == "AppliedParagraphStyle" {
+			p.AppliedParagraphStyle = attr.Value
+			continue
+		}
+		p.OtherAttrs = append(p.OtherAttrs, attr)
+	}
+
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			var err error
+			switch t.Name.Local {
+			case "CharacterStyleRange":
+				var csr CharacterStyleRange
+				if err = d.DecodeElement(&csr, &t); err == nil {
+					p.CharacterStyleRanges = append(p.CharacterStyleRanges, csr)
+				}
+			case "XMLElement":
+				var raw *common.RawXMLElement
+				raw, err = decodeEnclosingElement(d, &t, "CharacterStyleRange", func(elem *common.RawXMLElement, enclosing []*XMLElement) error {
+					var csr CharacterStyleRange
+					if err := decodeRaw(elem, &csr); err != nil {
+						return err
+					}
+					csr.XMLElements = enclosing
+					p.CharacterStyleRanges = append(p.CharacterStyleRanges, csr)
+					return nil
+				})
+				if raw != nil {
+					p.OtherElements = append(p.OtherElements, *raw)
+				}
+			default:
+				var raw common.RawXMLElement
+				if err = d.DecodeElement(&raw, &t); err == nil {
+					p.OtherElements = append(p.OtherElements, raw)
+				}
+			}
+			if err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// MarshalXML implements custom marshaling for ParagraphStyleRange to write
+// character style ranges inside the XMLElements enclosing them.
+func (p ParagraphStyleRange) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if start.Name.Local == "" {
+		start.Name = xml.Name{Local: "ParagraphStyleRange"}
+	}
+	start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: "AppliedParagraphStyle"}, Value: p.AppliedParagraphStyle})
+	start.Attr = append(start.Attr, p.OtherAttrs...)
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	if err := encodeEnclosedRanges(e, p.CharacterStyleRanges, func(csr *CharacterStyleRange) []*XMLElement {
+		return csr.XMLElements
+	}); err != nil {
+		return err
+	}
+	for i := range p.OtherElements {
+		if err := e.Encode(&p.OtherElements[i]); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
+
 // UnmarshalXML implements custom unmarshaling for CharacterStyleRange to preserve element order.
 func (c *CharacterStyleRange) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	// Add nil check for decoder
@@ -141,45 +341,143 @@ func (c *CharacterStyleRange) UnmarshalXML(d *xml.Decoder, start xml.StartElemen
 	}
 
 	// Parse child elements in order
+	children, err := decodeCharacterChildren(d)
+	if err != nil {
+		return err
+	}
+	c.Children = children
+	return nil
+}
+
+// decodeCharacterChildren decodes the mixed content of a CharacterStyleRange or
+// a text container inside one, up to and including the closing tag.
+// Processing instructions between the elements are kept as Instruction children.
+func decodeCharacterChildren(d *xml.Decoder) ([]CharacterChild, error) {
+	var children []CharacterChild
 	for {
 		token, err := d.Token()
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		switch t := token.(type) {
 		case xml.StartElement:
-			switch t.Name.Local {
-			case "Content":
-				var content Content
-				if err := d.DecodeElement(&content, &t); err != nil {
-					return err
+			if t.Name.Local == "Content" {
+				content, err := decodeContent(d, &t)
+				if err != nil {
+					return nil, err
 				}
-				c.Children = append(c.Children, CharacterChild{Content: &content})
+				children = append(children, content...)
+				continue
+			}
+			child, err := decodeCharacterChild(d, &t)
+			if err != nil {
+				return nil, err
+			}
+			children = append(children, child)
 
-			case "Br":
-				// Br is self-closing, just consume the element
-				if err := d.Skip(); err != nil {
-					return err
-				}
-				c.Children = append(c.Children, CharacterChild{Br: &Br{XMLName: t.Name}})
+		case xml.ProcInst:
+			// Processing instructions mark special characters such as footnote numbers
+			pi := t.Copy()
+			children = append(children, CharacterChild{Instruction: &pi})
 
-			default:
-				// Unknown element - store as RawXMLElement
-				var raw common.RawXMLElement
-				raw.XMLName = t.Name
-				raw.Attrs = t.Attr
+		case xml.EndElement:
+			return children, nil
+		}
+	}
+}
 
-				// Read inner content
-				if err := d.DecodeElement(&raw, &t); err != nil {
-					return err
-				}
-				c.Children = append(c.Children, CharacterChild{Other: &raw})
-			}
+// decodeCharacterChild decodes a single child element of a CharacterStyleRange
+// other than Content, which is decoded by decodeContent.
+func decodeCharacterChild(d *xml.Decoder, t *xml.StartElement) (CharacterChild, error) {
+	switch t.Name.Local {
+	case "Br":
+		// Br is self-closing, just consume the element
+		if err := d.Skip(); err != nil {
+			return CharacterChild{}, err
+		}
+		return CharacterChild{Br: &Br{XMLName: t.Name}}, nil
 
-		case xml.EndElement:
-			return nil
+	case "Table":
+		var table Table
+		if err := d.DecodeElement(&table, t); err != nil {
+			return CharacterChild{}, err
+		}
+		return CharacterChild{Table: &table}, nil
+
+	case "Rectangle", "TextFrame", "Oval", "Polygon", "GraphicLine", "Group":
+		obj, err := decodeAnchoredObject(d, t)
+		if err != nil {
+			return CharacterChild{}, err
+		}
+		return CharacterChild{Anchored: obj}, nil
+
+	case "Footnote":
+		var footnote Footnote
+		if err := d.DecodeElement(&footnote, t); err != nil {
+			return CharacterChild{}, err
+		}
+		return CharacterChild{Footnote: &footnote}, nil
+
+	case "Endnote":
+		var endnote Endnote
+		if err := d.DecodeElement(&endnote, t); err != nil {
+			return CharacterChild{}, err
+		}
+		return CharacterChild{Endnote: &endnote}, nil
+
+	case "HyperlinkTextSource":
+		var source HyperlinkTextSource
+		if err := d.DecodeElement(&source, t); err != nil {
+			return CharacterChild{}, err
+		}
+		return CharacterChild{HyperlinkSource: &source}, nil
+
+	case "CrossReferenceSource":
+		var source CrossReferenceSource
+		if err := d.DecodeElement(&source, t); err != nil {
+			return CharacterChild{}, err
+		}
+		return CharacterChild{CrossReferenceSource: &source}, nil
+
+	case "XMLElement":
+		var element XMLElement
+		if err := d.DecodeElement(&element, t); err != nil {
+			return CharacterChild{}, err
+		}
+		return CharacterChild{XMLElement: &element}, nil
+
+	case "Change":
+		var change Change
+		if err := d.DecodeElement(&change, t); err != nil {
+			return CharacterChild{}, err
+		}
+		return CharacterChild{Change: &change}, nil
+
+	case "Note":
+		var note Note
+		if err := d.DecodeElement(&note, t); err != nil {
+			return CharacterChild{}, err
+		}
+		return CharacterChild{Note: &note}, nil
+
+	case "HyperlinkTextDestination", "ParagraphDestination":
+		var dest TextDestination
+		if err := d.DecodeElement(&dest, t); err != nil {
+			return CharacterChild{}, err
+		}
+		return CharacterChild{Destination: &dest}, nil
+
+	default:
+		// Unknown element - store as RawXMLElement
+		// (DecodeElement fills in the name and attributes)
+		var raw common.RawXMLElement
+
+		// Read inner content
+		if err := d.DecodeElement(&raw, t); err != nil {
+			return CharacterChild{}, err
 		}
+		return CharacterChild{Other: &raw}, nil
 	}
 }
 
@@ -217,25 +515,8 @@ func (c CharacterStyleRange) MarshalXML(e *xml.Encoder, start xml.StartElement)
 	}
 
 	// Write children in order
-	for _, child := range c.Children {
-		if child.Content != nil {
-			if err := e.Encode(child.Content); err != nil {
-				return err
-			}
-		} else if child.Br != nil {
-			// Encode Br as self-closing tag
-			brStart := xml.StartElement{Name: xml.Name{Local: "Br"}}
-			if err := e.EncodeToken(brStart); err != nil {
-				return err
-			}
-			if err := e.EncodeToken(xml.EndElement{Name: brStart.Name}); err != nil {
-				return err
-			}
-		} else if child.Other != nil {
-			if err := e.Encode(child.Other); err != nil {
-				return err
-			}
-		}
+	if err := encodeCharacterChildren(e, c.Children); err != nil {
+		return err
 	}
 
 	// Write closing tag
@@ -245,3 +526,52 @@ func (c CharacterStyleRange) MarshalXML(e *xml.Encoder, start xml.StartElement)
 
 	return nil
 }
+
+// encodeCharacterChildren writes the mixed content of a CharacterStyleRange or
+// a text container inside one, in order.
+func encodeCharacterChildren(e *xml.Encoder, children []CharacterChild) error {
+	for i := 0; i < len(children); i++ {
+		child := children[i]
+		var err error
+		switch {
+		case child.Content != nil, child.Instruction != nil && child.InContent:
+			var n int
+			n, err = encodeContentRun(e, children[i:])
+			i += n - 1
+		case child.Br != nil:
+			// Encode Br as self-closing tag
+			brStart := xml.StartElement{Name: xml.Name{Local: "Br"}}
+			if err = e.EncodeToken(brStart); err == nil {
+				err = e.EncodeToken(xml.EndElement{Name: brStart.Name})
+			}
+		case child.Table != nil:
+			err = e.Encode(child.Table)
+		case child.Anchored != nil:
+			err = encodeAnchoredObject(e, child.Anchored)
+		case child.Footnote != nil:
+			err = e.Encode(child.Footnote)
+		case child.Endnote != nil:
+			err = e.Encode(child.Endnote)
+		case child.HyperlinkSource != nil:
+			err = e.Encode(child.HyperlinkSource)
+		case child.CrossReferenceSource != nil:
+			err = e.Encode(child.CrossReferenceSource)
+		case child.XMLElement != nil:
+			err = e.Encode(child.XMLElement)
+		case child.Change != nil:
+			err = e.Encode(child.Change)
+		case child.Note != nil:
+			err = e.Encode(child.Note)
+		case child.Destination != nil:
+			err = e.Encode(child.Destination)
+		case child.Instruction != nil:
+			err = e.EncodeToken(*child.Instruction)
+		case child.Other != nil:
+			err = e.Encode(child.Other)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -218,6 +218,33 @@ func TestCharacterStyleRange_WithUnknownElements(t *testing.T) {
 	}
 }
 
+// TestCharacterStyleRange_UnknownElementAttributes tests that attributes of
+// unknown elements are parsed and written back once.
+func TestCharacterStyleRange_UnknownElementAttributes(t *testing.T) {
+	xmlData := `<CharacterStyleRange AppliedCharacterStyle="test">
+		<UnknownElement Kind="x">Unknown content</UnknownElement>
+	</CharacterStyleRange>`
+
+	var csr CharacterStyleRange
+	if err := unmarshalXML(xmlData, &csr); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if len(csr.Children) != 1 || csr.Children[0].Other == nil {
+		t.Fatalf("Children = %+v, want the unknown element", csr.Children)
+	}
+	if attrs := csr.Children[0].Other.Attrs; len(attrs) != 1 || attrs[0].Value != "x" {
+		t.Errorf("Unknown element attributes = %v, want Kind=\"x\" once", attrs)
+	}
+
+	data, err := xml.Marshal(&csr)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	if n := strings.Count(string(data), `Kind="x"`); n != 1 {
+		t.Errorf("Kind attribute written %d times:\n%s", n, data)
+	}
+}
+
 // TestCharacterStyleRange_Marshal tests marshaling CharacterStyleRange.
 func TestCharacterStyleRange_Marshal(t *testing.T) {
 	csr := CharacterStyleRange{
@@ -0,0 +1,306 @@
+package story
+
+import (
+	"encoding/xml"
+	"slices"
+	"strings"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+)
+
+// SpecialCharacter is a special character in story text. InDesign writes
+// markers such as the auto page number as <?ACE n?> processing instructions,
+// usually inside a Content element; special spaces, hyphens and breaks are
+// Unicode characters in the Content text.
+type SpecialCharacter int
+
+// Special characters written as <?ACE n?> processing instructions.
+const (
+	SpecialNone               SpecialCharacter = iota
+	SpecialEndNestedStyle                      // <?ACE 3?>
+	SpecialFootnoteNumber                      // <?ACE 4?>
+	SpecialIndentToHere                        // <?ACE 7?>
+	SpecialRightIndentTab                      // <?ACE 8?>
+	SpecialAutoPageNumber                      // <?ACE 18?>
+	SpecialSectionMarker                       // <?ACE 19?>
+	SpecialUnknownInstruction                  // any other <?ACE n?>
+)
+
+// Special characters written as Unicode characters in Content.
+const (
+	SpecialTab                    SpecialCharacter = iota + 100 // U+0009
+	SpecialForcedLineBreak                                      // U+2028
+	SpecialNonbreakingSpace                                     // U+00A0
+	SpecialFlushSpace                                           // U+2001
+	SpecialEnSpace                                              // U+2002
+	SpecialEmSpace                                              // U+2003
+	SpecialThirdSpace                                           // U+2004
+	SpecialQuarterSpace                                         // U+2005
+	SpecialSixthSpace                                           // U+2006
+	SpecialFigureSpace                                          // U+2007
+	SpecialPunctuationSpace                                     // U+2008
+	SpecialThinSpace                                            // U+2009
+	SpecialHairSpace                                            // U+200A
+	SpecialDiscretionaryLineBreak                               // U+200B
+	SpecialZeroWidthNonJoiner                                   // U+200C
+	SpecialZeroWidthJoiner                                      // U+200D
+	SpecialNonbreakingHyphen                                    // U+2011
+	SpecialDiscretionaryHyphen                                  // U+00AD
+)
+
+// specialInfo describes how a special character is written and named.
+type specialInfo struct {
+	name string
+	ace  string // ACE code for processing instruction characters
+	r    rune   // Unicode character for Content characters
+}
+
+var specialCharacters = map[SpecialCharacter]specialInfo{
+	SpecialEndNestedStyle:         {name: "EndNestedStyle", ace: "3"},
+	SpecialFootnoteNumber:         {name: "FootnoteNumber", ace: footnoteMarkerInst},
+	SpecialIndentToHere:           {name: "IndentToHere", ace: "7"},
+	SpecialRightIndentTab:         {name: "RightIndentTab", ace: "8"},
+	SpecialAutoPageNumber:         {name: "AutoPageNumber", ace: "18"},
+	SpecialSectionMarker:          {name: "SectionMarker", ace: "19"},
+	SpecialUnknownInstruction:     {name: "UnknownInstruction"},
+	SpecialTab:                    {name: "Tab", r: '\t'},
+	SpecialForcedLineBreak:        {name: "ForcedLineBreak", r: '\u2028'},
+	SpecialNonbreakingSpace:       {name: "NonbreakingSpace", r: '\u00A0'},
+	SpecialFlushSpace:             {name: "FlushSpace", r: '\u2001'},
+	SpecialEnSpace:                {name: "EnSpace", r: '\u2002'},
+	SpecialEmSpace:                {name: "EmSpace", r: '\u2003'},
+	SpecialThirdSpace:             {name: "ThirdSpace", r: '\u2004'},
+	SpecialQuarterSpace:           {name: "QuarterSpace", r: '\u2005'},
+	SpecialSixthSpace:             {name: "SixthSpace", r: '\u2006'},
+	SpecialFigureSpace:            {name: "FigureSpace", r: '\u2007'},
+	SpecialPunctuationSpace:       {name: "PunctuationSpace", r: '\u2008'},
+	SpecialThinSpace:              {name: "ThinSpace", r: '\u2009'},
+	SpecialHairSpace:              {name: "HairSpace", r: '\u200A'},
+	SpecialDiscretionaryLineBreak: {name: "DiscretionaryLineBreak", r: '\u200B'},
+	SpecialZeroWidthNonJoiner:     {name: "ZeroWidthNonJoiner", r: '\u200C'},
+	SpecialZeroWidthJoiner:        {name: "ZeroWidthJoiner", r: '\u200D'},
+	SpecialNonbreakingHyphen:      {name: "NonbreakingHyphen", r: '\u2011'},
+	SpecialDiscretionaryHyphen:    {name: "DiscretionaryHyphen", r: '\u00AD'},
+}
+
+// String returns the name of the special character (e.g., "AutoPageNumber").
+func (c SpecialCharacter) String() string {
+	if info, ok := specialCharacters[c]; ok {
+		return info.name
+	}
+	return "None"
+}
+
+// Rune returns the Unicode character of a special character written in
+// Content, and false for processing instruction characters.
+func (c SpecialCharacter) Rune() (rune, bool) {
+	info := specialCharacters[c]
+	return info.r, info.r != 0
+}
+
+// Instruction returns the <?ACE n?> processing instruction of a special
+// character, or nil for characters written in Content and for
+// SpecialUnknownInstruction.
+func (c SpecialCharacter) Instruction() *xml.ProcInst {
+	info := specialCharacters[c]
+	if info.ace == "" {
+		return nil
+	}
+	return &xml.ProcInst{Target: footnoteMarkerTarget, Inst: []byte(info.ace)}
+}
+
+// SpecialCharacterOf returns the special character a processing instruction
+// stands for: SpecialUnknownInstruction for unknown ACE codes and SpecialNone
+// for other instructions.
+func SpecialCharacterOf(pi *xml.ProcInst) SpecialCharacter {
+	if pi == nil || pi.Target != footnoteMarkerTarget {
+		return SpecialNone
+	}
+	code := strings.TrimSpace(string(pi.Inst))
+	for c, info := range specialCharacters {
+		if info.ace != "" && info.ace == code {
+			return c
+		}
+	}
+	return SpecialUnknownInstruction
+}
+
+// SpecialCharacterForRune returns the special character of a Unicode
+// character, or SpecialNone for ordinary characters.
+func SpecialCharacterForRune(r rune) SpecialCharacter {
+	for c, info := range specialCharacters {
+		if info.r == r {
+			return c
+		}
+	}
+	return SpecialNone
+}
+
+// SpecialCharacterRef locates a special character in a story.
+type SpecialCharacterRef struct {
+	Character   SpecialCharacter
+	Offset      int           // position in the flattened story text
+	Instruction *xml.ProcInst // the processing instruction, for instruction characters
+}
+
+// SpecialCharacters returns the special characters in the story, in document
+// order. Instruction characters take no room in the flattened text; the
+// offset of a Content character is the offset of the character itself.
+func (s *Story) SpecialCharacters() []SpecialCharacterRef {
+	var refs []SpecialCharacterRef
+	s.walkChildren(func(v childVisit) {
+		child := (*v.parent)[v.index]
+		switch {
+		case child.Instruction != nil:
+			if c := SpecialCharacterOf(child.Instruction); c != SpecialNone {
+				refs = append(refs, SpecialCharacterRef{Character: c, Offset: v.pos, Instruction: child.Instruction})
+			}
+		case child.Content != nil:
+			for i, r := range child.Content.Text {
+				if c := SpecialCharacterForRune(r); c != SpecialNone {
+					refs = append(refs, SpecialCharacterRef{Character: c, Offset: v.pos + i})
+				}
+			}
+		}
+	})
+	return refs
+}
+
+// InsertSpecialCharacter inserts a special character at the given byte offset
+// in the flattened story text. Instruction characters are written inside a
+// Content element, as InDesign does; Content characters are inserted like
+// InsertText.
+//
+// Example:
+//
+//	err := st.InsertSpecialCharacter(len(st.ExtractText()), story.SpecialAutoPageNumber)
+func (s *Story) InsertSpecialCharacter(offset int, c SpecialCharacter) error {
+	const op = "insert special character"
+	if r, ok := c.Rune(); ok {
+		return s.InsertText(offset, string(r))
+	}
+	pi := c.Instruction()
+	if pi == nil {
+		return common.Errorf("story", op, s.StoryElement.Self, "unknown special character %d", int(c))
+	}
+	if err := s.validateRange(op, offset, offset); err != nil {
+		return err
+	}
+
+	s.splitContentAt(offset)
+	_, _, parent, childIdx := s.insertionPoint(offset, offset)
+	*parent = slices.Insert(*parent, childIdx, CharacterChild{Instruction: pi, InContent: true})
+	return nil
+}
+
+// renderInstruction returns the text of a special character given as a
+// processing instruction, as rendered with TextOptions.SpecialCharacters.
+func (w *textWriter) renderInstruction(pi *xml.ProcInst) string {
+	switch SpecialCharacterOf(pi) {
+	case SpecialRightIndentTab:
+		return "\t"
+	case SpecialAutoPageNumber:
+		if w.opts.PageNumber == "" {
+			return "#"
+		}
+		return w.opts.PageNumber
+	case SpecialSectionMarker:
+		return w.opts.SectionMarker
+	}
+	return ""
+}
+
+// plainSpaces maps the special characters of Content text to plain text for
+// TextOptions.PlainSpaces.
+func plainSpaces(r rune) rune {
+	switch c := SpecialCharacterForRune(r); {
+	case c == SpecialForcedLineBreak:
+		return '\n'
+	case c == SpecialNonbreakingHyphen:
+		return '-'
+	case c == SpecialDiscretionaryLineBreak, c == SpecialZeroWidthNonJoiner,
+		c == SpecialZeroWidthJoiner, c == SpecialDiscretionaryHyphen:
+		return -1
+	case c != SpecialNone && c != SpecialTab:
+		return ' '
+	}
+	return r
+}
+
+// decodeContent decodes a Content element. Processing instructions inside it,
+// such as <?ACE 18?>, become Instruction children between the Content
+// children holding the text before and after them.
+func decodeContent(d *xml.Decoder, start *xml.StartElement) ([]CharacterChild, error) {
+	var (
+		children []CharacterChild
+		text     strings.Builder
+		hasPI    bool
+	)
+	flush := func() {
+		if text.Len() > 0 || !hasPI {
+			children = append(children, CharacterChild{Content: &Content{XMLName: start.Name, Text: text.String()}})
+		}
+		text.Reset()
+	}
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.CharData:
+			text.Write(t)
+		case xml.ProcInst:
+			if text.Len() > 0 {
+				flush()
+			}
+			hasPI = true
+			pi := t.Copy()
+			children = append(children, CharacterChild{Instruction: &pi, InContent: true})
+		case xml.StartElement:
+			if err := d.Skip(); err != nil {
+				return nil, err
+			}
+		case xml.EndElement:
+			if text.Len() > 0 || !hasPI {
+				flush()
+			}
+			return children, nil
+		}
+	}
+}
+
+// encodeContentRun writes children[i] and, if instructions written inside
+// Content follow or precede it, the whole run of Content children and such
+// instructions as a single Content element. It returns the number of
+// children written.
+func encodeContentRun(e *xml.Encoder, children []CharacterChild) (int, error) {
+	n, hasPI := 0, false
+	for ; n < len(children); n++ {
+		if children[n].Instruction != nil && children[n].InContent {
+			hasPI = true
+		} else if children[n].Content == nil {
+			break
+		}
+	}
+	if !hasPI {
+		return 1, e.Encode(children[0].Content)
+	}
+
+	start := xml.StartElement{Name: xml.Name{Local: "Content"}}
+	if err := e.EncodeToken(start); err != nil {
+		return 0, err
+	}
+	for _, child := range children[:n] {
+		var err error
+		if child.Content != nil {
+			err = e.EncodeToken(xml.CharData(child.Content.Text))
+		} else {
+			err = e.EncodeToken(*child.Instruction)
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	return n, e.EncodeToken(xml.EndElement{Name: start.Name})
+}
@@ -0,0 +1,169 @@
+package story
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+// specialStoryXML is a story with special characters inside Content: an auto
+// page number, a section marker, indent to here, a right indent tab and
+// special spaces and hyphens.
+const specialStoryXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<idPkg:Story xmlns:idPkg="http://ns.adobe.com/AdobeInDesign/idml/1.0/packaging" DOMVersion="20.4">
+	<Story Self="u900" AppliedTOCStyle="n" TrackChanges="false">
+		<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/Folio">
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+				<Content>Page <?ACE 18?> – <?ACE 19?></Content>
+				<Br/>
+				<Content><?ACE 7?>Indented&#x9;text<?ACE 8?>right</Content>
+				<Br/>
+				<Content>co&#xAD;operate&#x2003;non&#x2011;stop&#x2028;end</Content>
+			</CharacterStyleRange>
+		</ParagraphStyleRange>
+	</Story>
+</idPkg:Story>`
+
+// TestParseStory_SpecialCharacters tests that processing instructions inside
+// Content are kept and located.
+func TestParseStory_SpecialCharacters(t *testing.T) {
+	st, err := ParseStory([]byte(specialStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+
+	want := "Page  – \nIndented\ttextright\nco\u00adoperate\u2003non\u2011stop\u2028end"
+	if got := st.ExtractText(); got != want {
+		t.Errorf("ExtractText() = %q, want %q", got, want)
+	}
+
+	var found []string
+	for _, ref := range st.SpecialCharacters() {
+		found = append(found, ref.Character.String())
+	}
+	wantFound := "AutoPageNumber SectionMarker IndentToHere Tab RightIndentTab DiscretionaryHyphen EmSpace NonbreakingHyphen ForcedLineBreak"
+	if got := strings.Join(found, " "); got != wantFound {
+		t.Errorf("SpecialCharacters() = %s, want %s", got, wantFound)
+	}
+	if ref := st.SpecialCharacters()[0]; ref.Offset != 5 || ref.Instruction == nil {
+		t.Errorf("page number ref = %+v", ref)
+	}
+
+	first, err := MarshalStory(st)
+	if err != nil {
+		t.Fatalf("MarshalStory failed: %v", err)
+	}
+	out := string(first)
+	for _, want := range []string{
+		"<Content>Page <?ACE 18?> – <?ACE 19?></Content>",
+		"<Content><?ACE 7?>Indented&#x9;text<?ACE 8?>right</Content>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	reparsed, err := ParseStory(first)
+	if err != nil {
+		t.Fatalf("ParseStory (second) failed: %v", err)
+	}
+	second, err := MarshalStory(reparsed)
+	if err != nil {
+		t.Fatalf("MarshalStory (second) failed: %v", err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Errorf("roundtrip output differs:\nfirst:\n%s\nsecond:\n%s", first, second)
+	}
+}
+
+// TestExtractText_SpecialCharacters tests rendering special characters.
+func TestExtractText_SpecialCharacters(t *testing.T) {
+	st, err := ParseStory([]byte(specialStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		opts TextOptions
+		want string
+	}{
+		{
+			name: "markers",
+			opts: TextOptions{SpecialCharacters: true, PageNumber: "12", SectionMarker: "Sports"},
+			want: "Page 12 – Sports\nIndented\ttext\tright\nco\u00adoperate\u2003non\u2011stop\u2028end",
+		},
+		{
+			name: "no page number",
+			opts: TextOptions{SpecialCharacters: true},
+			want: "Page # – \nIndented\ttext\tright\nco\u00adoperate\u2003non\u2011stop\u2028end",
+		},
+		{
+			name: "plain spaces",
+			opts: TextOptions{PlainSpaces: true},
+			want: "Page  – \nIndented\ttextright\ncooperate non-stop\nend",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := st.ExtractTextWithOptions(tt.opts); got != tt.want {
+				t.Errorf("ExtractTextWithOptions() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestInsertSpecialCharacter tests inserting special characters.
+func TestInsertSpecialCharacter(t *testing.T) {
+	st := &Story{StoryElement: StoryElement{
+		Self: "u901",
+		ParagraphStyleRanges: []ParagraphStyleRange{{
+			XMLName:              xml.Name{Local: "ParagraphStyleRange"},
+			CharacterStyleRanges: []CharacterStyleRange{NewCharacterStyleRange("", []Content{{Text: "Page of"}})},
+		}},
+	}}
+
+	if err := st.InsertSpecialCharacter(5, SpecialAutoPageNumber); err != nil {
+		t.Fatalf("InsertSpecialCharacter failed: %v", err)
+	}
+	if err := st.InsertSpecialCharacter(4, SpecialNonbreakingSpace); err != nil {
+		t.Fatalf("InsertSpecialCharacter failed: %v", err)
+	}
+	if err := st.InsertSpecialCharacter(0, SpecialNone); err == nil {
+		t.Error("expected error for SpecialNone")
+	}
+	if got := st.ExtractTextWithOptions(TextOptions{SpecialCharacters: true, PageNumber: "3"}); got != "Page\u00a0 3of\n" {
+		t.Errorf("ExtractTextWithOptions() = %q", got)
+	}
+
+	data, err := MarshalStory(st)
+	if err != nil {
+		t.Fatalf("MarshalStory failed: %v", err)
+	}
+	if !strings.Contains(string(data), "<Content>Page\u00a0 <?ACE 18?>of</Content>") {
+		t.Errorf("output missing the page number inside Content:\n%s", data)
+	}
+}
+
+// TestSpecialCharacterLookup tests mapping between special characters,
+// processing instructions and runes.
+func TestSpecialCharacterLookup(t *testing.T) {
+	if c := SpecialCharacterOf(&xml.ProcInst{Target: "ACE", Inst: []byte(" 18 ")}); c != SpecialAutoPageNumber {
+		t.Errorf("SpecialCharacterOf(ACE 18) = %v", c)
+	}
+	if c := SpecialCharacterOf(&xml.ProcInst{Target: "ACE", Inst: []byte("99")}); c != SpecialUnknownInstruction {
+		t.Errorf("SpecialCharacterOf(ACE 99) = %v", c)
+	}
+	if c := SpecialCharacterOf(&xml.ProcInst{Target: "aid", Inst: []byte("x")}); c != SpecialNone {
+		t.Errorf("SpecialCharacterOf(aid) = %v", c)
+	}
+	if pi := SpecialSectionMarker.Instruction(); pi == nil || string(pi.Inst) != "19" {
+		t.Errorf("SpecialSectionMarker.Instruction() = %v", pi)
+	}
+	if r, ok := SpecialEmSpace.Rune(); !ok || SpecialCharacterForRune(r) != SpecialEmSpace {
+		t.Errorf("SpecialEmSpace.Rune() = %q, %v", r, ok)
+	}
+	if _, ok := SpecialAutoPageNumber.Rune(); ok {
+		t.Error("SpecialAutoPageNumber.Rune() should report false")
+	}
+}
@@ -2,6 +2,7 @@ package story
 
 import (
 	"encoding/xml"
+	"strconv"
 	"strings"
 
 	"github.com/dimelords/idmllib/v2/pkg/common"
@@ -20,20 +21,132 @@ type Story struct {
 // ExtractText returns all text content from the story concatenated as a single string.
 // Line breaks (<Br> elements) are converted to newline characters.
 // This is a convenience method that navigates the story structure automatically.
+// Table content and footnotes are not included; use Tables() and Footnotes() to access them.
+//
+// The byte offsets used by FindText, InsertText, AddFootnote and the other
+// editing methods refer to this text.
 func (s *Story) ExtractText() string {
-	var buf strings.Builder
-	for _, psr := range s.StoryElement.ParagraphStyleRanges {
-		for _, csr := range psr.CharacterStyleRanges {
-			for _, child := range csr.Children {
-				if child.Content != nil {
-					buf.WriteString(child.Content.Text)
-				} else if child.Br != nil {
-					buf.WriteString("\n")
+	return s.ExtractTextWithOptions(TextOptions{})
+}
+
+// TextOptions controls what ExtractTextWithOptions includes.
+type TextOptions struct {
+	// IncludeFootnotes inserts the text of each footnote at its reference, in
+	// square brackets and starting with its number: "Text[1 Footnote text.]".
+	// Footnotes are numbered from 1 in story order.
+	IncludeFootnotes bool
+
+	// VisibleConditions hides conditional text whose conditions are all
+	// hidden. Keys are condition Selfs; conditions missing from the map are
+	// hidden. A nil map shows all text.
+	VisibleConditions map[string]bool
+
+	// SpecialCharacters renders special characters given as processing
+	// instructions: a right indent tab as "\t", the auto page number as
+	// PageNumber ("#" if empty) and the section marker as SectionMarker.
+	// Other markers, such as indent to here, render as nothing.
+	SpecialCharacters bool
+	PageNumber        string
+	SectionMarker     string
+
+	// PlainSpaces renders special spaces as ordinary spaces, forced line
+	// breaks as "\n" and nonbreaking hyphens as "-", and drops discretionary
+	// hyphens, discretionary line breaks and zero-width joiners.
+	PlainSpaces bool
+}
+
+// ExtractTextWithOptions returns the story text like ExtractText, with the
+// additional content selected by opts.
+func (s *Story) ExtractTextWithOptions(opts TextOptions) string {
+	w := textWriter{opts: opts}
+	w.writeRanges(s.StoryElement.ParagraphStyleRanges)
+	return w.buf.String()
+}
+
+// Tables returns pointers to all tables in the story, in document order.
+// Tables nested inside table cells are not included; see Cell.Tables.
+func (s *Story) Tables() []*Table {
+	return rangeTables(s.StoryElement.ParagraphStyleRanges)
+}
+
+// rangeTables returns pointers to the tables in the given paragraph ranges,
+// in document order.
+func rangeTables(ranges []ParagraphStyleRange) []*Table {
+	var tables []*Table
+	for i := range ranges {
+		psr := &ranges[i]
+		for j := range psr.CharacterStyleRanges {
+			for _, child := range psr.CharacterStyleRanges[j].FlatChildren() {
+				if child.Table != nil {
+					tables = append(tables, child.Table)
 				}
 			}
 		}
 	}
-	return buf.String()
+	return tables
+}
+
+// writeRangesText writes the text of the given paragraph ranges to buf.
+// Line breaks (<Br> elements) are written as newline characters.
+func writeRangesText(buf *strings.Builder, ranges []ParagraphStyleRange) {
+	w := textWriter{}
+	w.writeRanges(ranges)
+	buf.WriteString(w.buf.String())
+}
+
+// textWriter flattens paragraph ranges to text.
+type textWriter struct {
+	buf       strings.Builder
+	opts      TextOptions
+	footnotes int    // number of footnotes written so far
+	marker    string // text written for a footnote number marker
+}
+
+// writeRanges writes the text of the given paragraph ranges.
+func (w *textWriter) writeRanges(ranges []ParagraphStyleRange) {
+	for _, psr := range ranges {
+		for _, csr := range psr.CharacterStyleRanges {
+			if w.opts.VisibleConditions != nil && !conditionsVisible(csr.AppliedConditions(), w.opts.VisibleConditions) {
+				continue
+			}
+			w.writeChildren(csr.Children)
+		}
+	}
+}
+
+// writeChildren writes the text of the given children, including the content
+// of text containers.
+func (w *textWriter) writeChildren(children []CharacterChild) {
+	for _, child := range children {
+		switch {
+		case child.Content != nil && w.opts.PlainSpaces:
+			w.buf.WriteString(strings.Map(plainSpaces, child.Content.Text))
+		case child.Content != nil:
+			w.buf.WriteString(child.Content.Text)
+		case child.Br != nil:
+			w.buf.WriteString("\n")
+		case child.Instruction != nil && isFootnoteMarker(child.Instruction):
+			w.buf.WriteString(w.marker)
+		case child.Instruction != nil && w.opts.SpecialCharacters:
+			w.buf.WriteString(w.renderInstruction(child.Instruction))
+		case child.Footnote != nil && w.opts.IncludeFootnotes:
+			w.footnotes++
+			note := textWriter{
+				opts: TextOptions{
+					VisibleConditions: w.opts.VisibleConditions,
+					SpecialCharacters: w.opts.SpecialCharacters,
+					PageNumber:        w.opts.PageNumber,
+					SectionMarker:     w.opts.SectionMarker,
+					PlainSpaces:       w.opts.PlainSpaces,
+				},
+				marker: strconv.Itoa(w.footnotes) + " ",
+			}
+			note.writeRanges(child.Footnote.ParagraphStyleRanges)
+			w.buf.WriteString("[" + strings.TrimRight(note.buf.String(), "\n") + "]")
+		case child.nested() != nil:
+			w.writeChildren(*child.nested())
+		}
+	}
 }
 
 // StoryElement represents the main Story element containing all content.
@@ -51,13 +164,16 @@ type StoryElement struct {
 	StoryTitle       string `xml:"StoryTitle,attr,omitempty"`       // Story title
 	AppliedNamedGrid string `xml:"AppliedNamedGrid,attr,omitempty"` // Reference to named grid
 
+	// Additional attributes (catch-all)
+	OtherAttrs []xml.Attr `xml:"-"`
+
 	// Story preferences
 	StoryPreference *StoryPreference `xml:"StoryPreference,omitempty"`
 
 	// InCopy export options
 	InCopyExportOption *InCopyExportOption `xml:"InCopyExportOption,omitempty"`
 
-	// Content - paragraph style ranges
+	// Content - paragraph style ranges, including those inside XMLElements
 	ParagraphStyleRanges []ParagraphStyleRange `xml:"ParagraphStyleRange"`
 
 	// Catch-all for unknown elements
@@ -99,11 +215,18 @@ type ParagraphStyleRange struct {
 	// Applied paragraph style reference
 	AppliedParagraphStyle string `xml:"AppliedParagraphStyle,attr"`
 
-	// Character style ranges within this paragraph
+	// Local paragraph formatting attributes (catch-all)
+	OtherAttrs []xml.Attr `xml:",any,attr"`
+
+	// Character style ranges within this paragraph, including those inside
+	// XMLElements
 	CharacterStyleRanges []CharacterStyleRange `xml:"CharacterStyleRange"`
 
 	// Catch-all for unknown elements
 	OtherElements []common.RawXMLElement `xml:",any"`
+
+	// XMLElements enclosing the range in the story, outermost first
+	XMLElements []*XMLElement `xml:"-"`
 }
 
 // CharacterStyleRange represents a range of characters with the same character style.
@@ -122,16 +245,89 @@ type CharacterStyleRange struct {
 	// Additional formatting attributes (catch-all)
 	OtherAttrs []xml.Attr `xml:"-"` // Not used by encoding/xml, manually handled
 
-	// Mixed content: Content and Br elements in order
-	// This field stores both Content and Br elements in the order they appear
+	// Mixed content: Content, Br, Table, anchored page items, hyperlink sources
+	// and the other child elements in the order they appear
 	Children []CharacterChild `xml:"-"` // Manually marshaled to preserve order
+
+	// XMLElements enclosing the range in its paragraph style range, outermost first
+	XMLElements []*XMLElement `xml:"-"`
 }
 
-// CharacterChild represents either a Content element or a Br element
+// CharacterChild represents either a Content element, a Br element, a Table,
+// an anchored page item, a footnote, an endnote reference, a hyperlink or
+// cross-reference source, an XML element, a text destination or a processing
+// instruction.
+//
+// Hyperlink and cross-reference sources and XML elements are text containers:
+// their text is part of the story text and they hold children of their own.
 type CharacterChild struct {
-	Content *Content              // If non-nil, this is a Content element
-	Br      *Br                   // If non-nil, this is a Br element
-	Other   *common.RawXMLElement // If non-nil, this is an unknown element
+	Content              *Content              // If non-nil, this is a Content element
+	Br                   *Br                   // If non-nil, this is a Br element
+	Table                *Table                // If non-nil, this is a Table element
+	Anchored             *AnchoredObject       // If non-nil, this is an anchored page item
+	Footnote             *Footnote             // If non-nil, this is a Footnote element
+	Endnote              *Endnote              // If non-nil, this is an endnote reference
+	HyperlinkSource      *HyperlinkTextSource  // If non-nil, this is a hyperlink source
+	CrossReferenceSource *CrossReferenceSource // If non-nil, this is a cross-reference source
+	XMLElement           *XMLElement           // If non-nil, this is an element of the XML structure
+	Change               *Change               // If non-nil, this is a tracked change
+	Note                 *Note                 // If non-nil, this is an InCopy note
+	Destination          *TextDestination      // If non-nil, this is a hyperlink text or paragraph destination
+	Instruction          *xml.ProcInst         // If non-nil, this is a processing instruction such as <?ACE 4?>
+	Other                *common.RawXMLElement // If non-nil, this is an unknown element
+
+	// InContent marks an Instruction that is written inside a Content
+	// element, as InDesign writes special characters such as <?ACE 18?>
+	InContent bool
+}
+
+// nested returns the children of a text container such as a hyperlink source,
+// or nil if the child is not a container.
+func (c *CharacterChild) nested() *[]CharacterChild {
+	switch {
+	case c.HyperlinkSource != nil:
+		return &c.HyperlinkSource.Children
+	case c.CrossReferenceSource != nil:
+		return &c.CrossReferenceSource.Children
+	case c.XMLElement != nil:
+		return &c.XMLElement.Children
+	case c.Change != nil:
+		return &c.Change.Children
+	}
+	return nil
+}
+
+// FlatChildren returns the children of the range with the content of text
+// containers such as hyperlink sources expanded in place.
+func (c *CharacterStyleRange) FlatChildren() []CharacterChild {
+	return flattenChildren(c.Children, nil)
+}
+
+// flattenChildren appends children to flat, expanding text containers.
+func flattenChildren(children, flat []CharacterChild) []CharacterChild {
+	for _, child := range children {
+		if nested := child.nested(); nested != nil {
+			flat = flattenChildren(*nested, flat)
+			continue
+		}
+		flat = append(flat, child)
+	}
+	return flat
+}
+
+// containsChild reports whether any of children, at any depth inside text
+// containers, matches.
+func containsChild(children []CharacterChild, match func(*CharacterChild) bool) bool {
+	for i := range children {
+		child := &children[i]
+		if match(child) {
+			return true
+		}
+		if nested := child.nested(); nested != nil && containsChild(*nested, match) {
+			return true
+		}
+	}
+	return false
 }
 
 // Content represents actual text content.
@@ -174,7 +370,7 @@ func NewCharacterStyleRange(appliedStyle string, contents []Content) CharacterSt
 // This allows existing code to continue accessing content without knowing about the new Children structure.
 func (c *CharacterStyleRange) GetContent() []Content {
 	var contents []Content
-	for _, child := range c.Children {
+	for _, child := range c.FlatChildren() {
 		if child.Content != nil {
 			contents = append(contents, *child.Content)
 		}
@@ -0,0 +1,561 @@
+package story
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+)
+
+// DefaultCellStyle is the cell style InDesign applies when no cell style is set.
+const DefaultCellStyle = "CellStyle/$ID/[None]"
+
+// Table represents a table anchored inside a CharacterStyleRange.
+//
+// InDesign stores tables as a flat list of Row and Column definitions followed by
+// the Cell elements. Only the anchor cell of a merged area is present; its
+// RowSpan/ColumnSpan attributes describe how many grid positions it covers.
+// Cell names use the "column:row" format (e.g., "2:0").
+type Table struct {
+	XMLName xml.Name `xml:"Table"`
+
+	// Identity
+	Self string `xml:"Self,attr"`
+
+	// Table dimensions
+	HeaderRowCount string `xml:"HeaderRowCount,attr,omitempty"`
+	FooterRowCount string `xml:"FooterRowCount,attr,omitempty"`
+	BodyRowCount   string `xml:"BodyRowCount,attr,omitempty"`
+	ColumnCount    string `xml:"ColumnCount,attr,omitempty"`
+
+	// Applied table style reference
+	AppliedTableStyle string `xml:"AppliedTableStyle,attr,omitempty"`
+	TableDirection    string `xml:"TableDirection,attr,omitempty"` // "LeftToRightDirection", etc.
+
+	// Additional formatting attributes (catch-all)
+	OtherAttrs []xml.Attr `xml:",any,attr"`
+
+	// Child elements
+	Properties *common.Properties `xml:"Properties,omitempty"`
+	Rows       []Row              `xml:"Row"`
+	Columns    []Column           `xml:"Column"`
+	Cells      []Cell             `xml:"Cell"`
+
+	// Catch-all for unknown elements
+	OtherElements []common.RawXMLElement `xml:",any"`
+}
+
+// Row represents a table row definition.
+type Row struct {
+	XMLName xml.Name `xml:"Row"`
+
+	Self            string `xml:"Self,attr"`
+	Name            string `xml:"Name,attr"` // Zero-based row index
+	SingleRowHeight string `xml:"SingleRowHeight,attr,omitempty"`
+	MinimumHeight   string `xml:"MinimumHeight,attr,omitempty"`
+	AutoGrow        string `xml:"AutoGrow,attr,omitempty"` // "true"/"false"
+
+	// Additional formatting attributes (catch-all)
+	OtherAttrs []xml.Attr `xml:",any,attr"`
+
+	// Catch-all for unknown elements
+	OtherElements []common.RawXMLElement `xml:",any"`
+}
+
+// Column represents a table column definition.
+type Column struct {
+	XMLName xml.Name `xml:"Column"`
+
+	Self              string `xml:"Self,attr"`
+	Name              string `xml:"Name,attr"` // Zero-based column index
+	SingleColumnWidth string `xml:"SingleColumnWidth,attr,omitempty"`
+
+	// Additional formatting attributes (catch-all)
+	OtherAttrs []xml.Attr `xml:",any,attr"`
+
+	// Catch-all for unknown elements
+	OtherElements []common.RawXMLElement `xml:",any"`
+}
+
+// Cell represents a table cell. Cells contain their own paragraph style ranges.
+type Cell struct {
+	XMLName xml.Name `xml:"Cell"`
+
+	Self       string `xml:"Self,attr"`
+	Name       string `xml:"Name,attr"` // "column:row"
+	RowSpan    string `xml:"RowSpan,attr,omitempty"`
+	ColumnSpan string `xml:"ColumnSpan,attr,omitempty"`
+
+	// Applied cell style reference
+	AppliedCellStyle         string `xml:"AppliedCellStyle,attr,omitempty"`
+	AppliedCellStylePriority string `xml:"AppliedCellStylePriority,attr,omitempty"`
+
+	// Additional formatting attributes (catch-all)
+	OtherAttrs []xml.Attr `xml:",any,attr"`
+
+	// Child elements
+	Properties           *common.Properties    `xml:"Properties,omitempty"`
+	ParagraphStyleRanges []ParagraphStyleRange `xml:"ParagraphStyleRange"`
+
+	// Catch-all for unknown elements
+	OtherElements []common.RawXMLElement `xml:",any"`
+}
+
+// Position returns the zero-based column and row of the cell, parsed from its Name.
+func (c *Cell) Position() (column, row int, err error) {
+	parts := strings.Split(c.Name, ":")
+	if len(parts) != 2 {
+		return 0, 0, common.Errorf("story", "cell position", "", "invalid cell name %q (expected \"column:row\")", c.Name)
+	}
+	column, err = strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, common.WrapError("story", "cell position", err)
+	}
+	row, err = strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, common.WrapError("story", "cell position", err)
+	}
+	return column, row, nil
+}
+
+// Spans returns the number of rows and columns the cell covers (at least 1 each).
+func (c *Cell) Spans() (rowSpan, columnSpan int) {
+	return atoiDefault(c.RowSpan, 1), atoiDefault(c.ColumnSpan, 1)
+}
+
+// Text returns the text content of the cell. Paragraphs are separated the same
+// way as in Story.ExtractText, with trailing line breaks removed.
+func (c *Cell) Text() string {
+	var buf strings.Builder
+	writeRangesText(&buf, c.ParagraphStyleRanges)
+	return strings.TrimRight(buf.String(), "\n")
+}
+
+// Tables returns pointers to the tables nested in the cell, in document order.
+func (c *Cell) Tables() []*Table {
+	return rangeTables(c.ParagraphStyleRanges)
+}
+
+// SetText replaces the cell content with a single paragraph holding text.
+// The paragraph and character styles of the first existing range are kept.
+func (c *Cell) SetText(text string) {
+	paraStyle := "ParagraphStyle/$ID/NormalParagraphStyle"
+	charStyle := ""
+	if len(c.ParagraphStyleRanges) > 0 {
+		paraStyle = c.ParagraphStyleRanges[0].AppliedParagraphStyle
+		if len(c.ParagraphStyleRanges[0].CharacterStyleRanges) > 0 {
+			charStyle = c.ParagraphStyleRanges[0].CharacterStyleRanges[0].AppliedCharacterStyle
+		}
+	}
+
+	csr := NewCharacterStyleRange(charStyle, nil)
+	if text != "" {
+		csr.Children = []CharacterChild{{Content: &Content{XMLName: xml.Name{Local: "Content"}, Text: text}}}
+	}
+
+	c.ParagraphStyleRanges = []ParagraphStyleRange{{
+		XMLName:               xml.Name{Local: "ParagraphStyleRange"},
+		AppliedParagraphStyle: paraStyle,
+		CharacterStyleRanges:  []CharacterStyleRange{csr},
+	}}
+}
+
+// RowCount returns the total number of rows (header + body + footer).
+func (t *Table) RowCount() int {
+	count := atoiDefault(t.HeaderRowCount, 0) + atoiDefault(t.BodyRowCount, 0) + atoiDefault(t.FooterRowCount, 0)
+	if count == 0 {
+		return len(t.Rows)
+	}
+	return count
+}
+
+// hasSectionCounts reports whether the table sets any of HeaderRowCount,
+// BodyRowCount and FooterRowCount.
+func (t *Table) hasSectionCounts() bool {
+	return t.HeaderRowCount != "" || t.BodyRowCount != "" || t.FooterRowCount != ""
+}
+
+// ColumnTotal returns the number of columns in the table.
+func (t *Table) ColumnTotal() int {
+	if n := atoiDefault(t.ColumnCount, 0); n > 0 {
+		return n
+	}
+	return len(t.Columns)
+}
+
+// Cell returns the cell covering the given grid position.
+// For merged cells, the anchor cell is returned for every position it spans.
+// Returns nil if no cell covers the position.
+func (t *Table) Cell(row, column int) *Cell {
+	for i := range t.Cells {
+		col, r, err := t.Cells[i].Position()
+		if err != nil {
+			continue
+		}
+		rowSpan, colSpan := t.Cells[i].Spans()
+		if row >= r && row < r+rowSpan && column >= col && column < col+colSpan {
+			return &t.Cells[i]
+		}
+	}
+	return nil
+}
+
+// TextGrid returns the table content as a rows × columns grid of strings.
+// Merged cells report their text at the anchor position; covered positions are empty.
+func (t *Table) TextGrid() [][]string {
+	grid := make([][]string, t.RowCount())
+	for r := range grid {
+		grid[r] = make([]string, t.ColumnTotal())
+	}
+
+	for i := range t.Cells {
+		col, row, err := t.Cells[i].Position()
+		if err != nil || row >= len(grid) || col >= len(grid[row]) {
+			continue
+		}
+		grid[row][col] = t.Cells[i].Text()
+	}
+
+	return grid
+}
+
+// InsertRow inserts an empty row before the row at index. Use RowCount() to append.
+// Cells spanning across the insertion point are extended; new cells copy the
+// cell style of the adjacent row. The row is added to the header, body or footer
+// section that contains the insertion point.
+func (t *Table) InsertRow(index int) error {
+	rowCount := t.RowCount()
+	if index < 0 || index > rowCount {
+		return common.Errorf("story", "insert row", t.Self, "row index %d out of range [0, %d]", index, rowCount)
+	}
+
+	// Template row for height and other settings
+	var newRow Row
+	if len(t.Rows) > 0 {
+		src := t.Rows[clampIndex(index, len(t.Rows))]
+		newRow = src
+		newRow.OtherAttrs = append([]xml.Attr(nil), src.OtherAttrs...)
+		newRow.OtherElements = append([]common.RawXMLElement(nil), src.OtherElements...)
+	}
+	newRow.XMLName = xml.Name{Local: "Row"}
+	newRow.Self = t.uniqueSelf("Row")
+
+	// Shift or extend existing cells
+	covered := make(map[int]bool)
+	for i := range t.Cells {
+		col, row, err := t.Cells[i].Position()
+		if err != nil {
+			return common.WrapError("story", "insert row", err)
+		}
+		rowSpan, colSpan := t.Cells[i].Spans()
+		switch {
+		case row >= index:
+			t.Cells[i].Name = cellName(col, row+1)
+		case row+rowSpan > index:
+			t.Cells[i].RowSpan = strconv.Itoa(rowSpan + 1)
+			for c := col; c < col+colSpan; c++ {
+				covered[c] = true
+			}
+		}
+	}
+
+	// Create cells for every column not covered by an extended span
+	for col := 0; col < t.ColumnTotal(); col++ {
+		if covered[col] {
+			continue
+		}
+		style := DefaultCellStyle
+		if neighbor := t.neighborCell(index, col, rowCount); neighbor != nil {
+			style = neighbor.AppliedCellStyle
+		}
+		t.Cells = append(t.Cells, t.newCell(col, index, style))
+	}
+
+	pos := min(index, len(t.Rows))
+	t.Rows = append(t.Rows, Row{})
+	copy(t.Rows[pos+1:], t.Rows[pos:])
+	t.Rows[pos] = newRow
+	t.renumberRows()
+
+	// Update the section counts; without them the rows are counted
+	header := atoiDefault(t.HeaderRowCount, 0)
+	body := atoiDefault(t.BodyRowCount, 0)
+	switch {
+	case !t.hasSectionCounts():
+	case index < header:
+		t.HeaderRowCount = strconv.Itoa(header + 1)
+	case index <= header+body:
+		t.BodyRowCount = strconv.Itoa(body + 1)
+	default:
+		t.FooterRowCount = strconv.Itoa(atoiDefault(t.FooterRowCount, 0) + 1)
+	}
+
+	t.sortCells()
+	return nil
+}
+
+// DeleteRow removes the row at index.
+// Cells anchored in the row are removed unless they span further rows, in which
+// case they shrink; cells spanning across the row shrink as well.
+func (t *Table) DeleteRow(index int) error {
+	rowCount := t.RowCount()
+	if index < 0 || index >= rowCount {
+		return common.Errorf("story", "delete row", t.Self, "row index %d out of range [0, %d)", index, rowCount)
+	}
+
+	cells := t.Cells[:0]
+	for _, cell := range t.Cells {
+		col, row, err := cell.Position()
+		if err != nil {
+			return common.WrapError("story", "delete row", err)
+		}
+		rowSpan, _ := cell.Spans()
+		switch {
+		case row > index:
+			cell.Name = cellName(col, row-1)
+		case row == index && rowSpan == 1:
+			continue
+		case row+rowSpan > index:
+			cell.RowSpan = strconv.Itoa(rowSpan - 1)
+		}
+		cells = append(cells, cell)
+	}
+	t.Cells = cells
+
+	if index < len(t.Rows) {
+		t.Rows = append(t.Rows[:index], t.Rows[index+1:]...)
+	}
+	t.renumberRows()
+
+	// Only a section containing the row shrinks; without section counts the
+	// rows are counted
+	header := atoiDefault(t.HeaderRowCount, 0)
+	body := atoiDefault(t.BodyRowCount, 0)
+	footer := atoiDefault(t.FooterRowCount, 0)
+	switch {
+	case index < header:
+		t.HeaderRowCount = strconv.Itoa(max(header-1, 0))
+	case index < header+body:
+		t.BodyRowCount = strconv.Itoa(max(body-1, 0))
+	case index < header+body+footer:
+		t.FooterRowCount = strconv.Itoa(max(footer-1, 0))
+	}
+
+	t.sortCells()
+	return nil
+}
+
+// InsertColumn inserts an empty column before the column at index. Use ColumnTotal() to append.
+// Cells spanning across the insertion point are extended; new cells copy the
+// cell style of the adjacent column.
+func (t *Table) InsertColumn(index int) error {
+	columnCount := t.ColumnTotal()
+	if index < 0 || index > columnCount {
+		return common.Errorf("story", "insert column", t.Self, "column index %d out of range [0, %d]", index, columnCount)
+	}
+
+	var newColumn Column
+	if len(t.Columns) > 0 {
+		src := t.Columns[clampIndex(index, len(t.Columns))]
+		newColumn = src
+		newColumn.OtherAttrs = append([]xml.Attr(nil), src.OtherAttrs...)
+		newColumn.OtherElements = append([]common.RawXMLElement(nil), src.OtherElements...)
+	}
+	newColumn.XMLName = xml.Name{Local: "Column"}
+	newColumn.Self = t.uniqueSelf("Column")
+
+	covered := make(map[int]bool)
+	for i := range t.Cells {
+		col, row, err := t.Cells[i].Position()
+		if err != nil {
+			return common.WrapError("story", "insert column", err)
+		}
+		rowSpan, colSpan := t.Cells[i].Spans()
+		switch {
+		case col >= index:
+			t.Cells[i].Name = cellName(col+1, row)
+		case col+colSpan > index:
+			t.Cells[i].ColumnSpan = strconv.Itoa(colSpan + 1)
+			for r := row; r < row+rowSpan; r++ {
+				covered[r] = true
+			}
+		}
+	}
+
+	for row := 0; row < t.RowCount(); row++ {
+		if covered[row] {
+			continue
+		}
+		style := DefaultCellStyle
+		if neighbor := t.neighborColumnCell(row, index, columnCount); neighbor != nil {
+			style = neighbor.AppliedCellStyle
+		}
+		t.Cells = append(t.Cells, t.newCell(index, row, style))
+	}
+
+	pos := min(index, len(t.Columns))
+	t.Columns = append(t.Columns, Column{})
+	copy(t.Columns[pos+1:], t.Columns[pos:])
+	t.Columns[pos] = newColumn
+	t.renumberColumns()
+	t.ColumnCount = strconv.Itoa(columnCount + 1)
+
+	t.sortCells()
+	return nil
+}
+
+// DeleteColumn removes the column at index.
+// Cells anchored in the column are removed unless they span further columns, in
+// which case they shrink; cells spanning across the column shrink as well.
+func (t *Table) DeleteColumn(index int) error {
+	columnCount := t.ColumnTotal()
+	if index < 0 || index >= columnCount {
+		return common.Errorf("story", "delete column", t.Self, "column index %d out of range [0, %d)", index, columnCount)
+	}
+
+	cells := t.Cells[:0]
+	for _, cell := range t.Cells {
+		col, row, err := cell.Position()
+		if err != nil {
+			return common.WrapError("story", "delete column", err)
+		}
+		_, colSpan := cell.Spans()
+		switch {
+		case col > index:
+			cell.Name = cellName(col-1, row)
+		case col == index && colSpan == 1:
+			continue
+		case col+colSpan > index:
+			cell.ColumnSpan = strconv.Itoa(colSpan - 1)
+		}
+		cells = append(cells, cell)
+	}
+	t.Cells = cells
+
+	if index < len(t.Columns) {
+		t.Columns = append(t.Columns[:index], t.Columns[index+1:]...)
+	}
+	t.renumberColumns()
+	t.ColumnCount = strconv.Itoa(columnCount - 1)
+
+	t.sortCells()
+	return nil
+}
+
+// neighborCell returns the cell in the same column of the row adjacent to an insertion point.
+func (t *Table) neighborCell(index, column, rowCount int) *Cell {
+	if index < rowCount {
+		// The row currently at index has been shifted down by one
+		if cell := t.Cell(index+1, column); cell != nil {
+			return cell
+		}
+	}
+	if index > 0 {
+		return t.Cell(index-1, column)
+	}
+	return nil
+}
+
+// neighborColumnCell returns the cell in the same row of the column adjacent to an insertion point.
+func (t *Table) neighborColumnCell(row, index, columnCount int) *Cell {
+	if index < columnCount {
+		if cell := t.Cell(row, index+1); cell != nil {
+			return cell
+		}
+	}
+	if index > 0 {
+		return t.Cell(row, index-1)
+	}
+	return nil
+}
+
+// newCell creates an empty single-span cell at the given position.
+func (t *Table) newCell(column, row int, style string) Cell {
+	cell := Cell{
+		XMLName:                  xml.Name{Local: "Cell"},
+		Self:                     t.uniqueSelf("i"),
+		Name:                     cellName(column, row),
+		RowSpan:                  "1",
+		ColumnSpan:               "1",
+		AppliedCellStyle:         style,
+		AppliedCellStylePriority: "0",
+	}
+	cell.SetText("")
+	return cell
+}
+
+// renumberRows rewrites row names to match their position.
+func (t *Table) renumberRows() {
+	for i := range t.Rows {
+		t.Rows[i].Name = strconv.Itoa(i)
+	}
+}
+
+// renumberColumns rewrites column names to match their position.
+func (t *Table) renumberColumns() {
+	for i := range t.Columns {
+		t.Columns[i].Name = strconv.Itoa(i)
+	}
+}
+
+// sortCells orders cells by row, then column, which is the order InDesign writes them.
+func (t *Table) sortCells() {
+	key := func(c *Cell) (int, int) {
+		col, row, _ := c.Position()
+		return row, col
+	}
+	// Insertion sort keeps the original order of equal keys and is fast for nearly sorted input
+	for i := 1; i < len(t.Cells); i++ {
+		for j := i; j > 0; j-- {
+			r1, c1 := key(&t.Cells[j-1])
+			r2, c2 := key(&t.Cells[j])
+			if r1 < r2 || (r1 == r2 && c1 <= c2) {
+				break
+			}
+			t.Cells[j-1], t.Cells[j] = t.Cells[j], t.Cells[j-1]
+		}
+	}
+}
+
+// uniqueSelf generates a Self ID derived from the table ID that is not used by any row, column or cell.
+func (t *Table) uniqueSelf(kind string) string {
+	used := make(map[string]bool, len(t.Rows)+len(t.Columns)+len(t.Cells))
+	for _, r := range t.Rows {
+		used[r.Self] = true
+	}
+	for _, c := range t.Columns {
+		used[c.Self] = true
+	}
+	for _, c := range t.Cells {
+		used[c.Self] = true
+	}
+	for n := 0; ; n++ {
+		id := fmt.Sprintf("%s%s%d", t.Self, kind, n)
+		if !used[id] {
+			return id
+		}
+	}
+}
+
+// cellName formats a cell name in InDesign's "column:row" format.
+func cellName(column, row int) string {
+	return strconv.Itoa(column) + ":" + strconv.Itoa(row)
+}
+
+// clampIndex returns index limited to the valid range of a slice of length n.
+func clampIndex(index, n int) int {
+	if index >= n {
+		return n - 1
+	}
+	return index
+}
+
+// atoiDefault parses s as an integer, returning def if s is empty or invalid.
+func atoiDefault(s string, def int) int {
+	if n, err := strconv.Atoi(s); err == nil {
+		return n
+	}
+	return def
+}
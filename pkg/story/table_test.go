@@ -0,0 +1,341 @@
+package story
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// tableStoryXML is a story with a 3×3 table whose first cell spans two columns.
+const tableStoryXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<idPkg:Story xmlns:idPkg="http://ns.adobe.com/AdobeInDesign/idml/1.0/packaging" DOMVersion="20.4">
+	<Story Self="u100" AppliedTOCStyle="n" TrackChanges="false">
+		<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/$ID/NormalParagraphStyle">
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+				<Content>Before</Content>
+				<Br/>
+				<Table Self="u100i1" HeaderRowCount="1" FooterRowCount="0" BodyRowCount="2" ColumnCount="3" AppliedTableStyle="TableStyle/$ID/[Basic Table]" TableDirection="LeftToRightDirection" AppliedTableStylePriority="0">
+					<Row Self="u100i1Row0" Name="0" SingleRowHeight="20" MinimumHeight="3" />
+					<Row Self="u100i1Row1" Name="1" SingleRowHeight="20" MinimumHeight="3" />
+					<Row Self="u100i1Row2" Name="2" SingleRowHeight="20" MinimumHeight="3" />
+					<Column Self="u100i1Column0" Name="0" SingleColumnWidth="100" />
+					<Column Self="u100i1Column1" Name="1" SingleColumnWidth="100" />
+					<Column Self="u100i1Column2" Name="2" SingleColumnWidth="100" />
+					<Cell Self="u100i1i0i0" Name="0:0" RowSpan="1" ColumnSpan="2" AppliedCellStyle="CellStyle/Header" AppliedCellStylePriority="0" FillColor="Color/Black">
+						<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/$ID/NormalParagraphStyle">
+							<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+								<Content>Title</Content>
+							</CharacterStyleRange>
+						</ParagraphStyleRange>
+					</Cell>
+					<Cell Self="u100i1i2i0" Name="2:0" RowSpan="1" ColumnSpan="1" AppliedCellStyle="CellStyle/Header" AppliedCellStylePriority="0">
+						<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/$ID/NormalParagraphStyle">
+							<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+								<Content>Total</Content>
+							</CharacterStyleRange>
+						</ParagraphStyleRange>
+					</Cell>
+					<Cell Self="u100i1i0i1" Name="0:1" RowSpan="2" ColumnSpan="1" AppliedCellStyle="CellStyle/$ID/[None]" AppliedCellStylePriority="0">
+						<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/$ID/NormalParagraphStyle">
+							<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+								<Content>A</Content>
+							</CharacterStyleRange>
+						</ParagraphStyleRange>
+					</Cell>
+					<Cell Self="u100i1i1i1" Name="1:1" RowSpan="1" ColumnSpan="1" AppliedCellStyle="CellStyle/$ID/[None]" AppliedCellStylePriority="0">
+						<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/$ID/NormalParagraphStyle">
+							<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+								<Content>B1</Content>
+							</CharacterStyleRange>
+						</ParagraphStyleRange>
+					</Cell>
+					<Cell Self="u100i1i2i1" Name="2:1" RowSpan="1" ColumnSpan="1" AppliedCellStyle="CellStyle/$ID/[None]" AppliedCellStylePriority="0">
+						<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/$ID/NormalParagraphStyle">
+							<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+								<Content>C1</Content>
+							</CharacterStyleRange>
+						</ParagraphStyleRange>
+					</Cell>
+					<Cell Self="u100i1i1i2" Name="1:2" RowSpan="1" ColumnSpan="1" AppliedCellStyle="CellStyle/$ID/[None]" AppliedCellStylePriority="0">
+						<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/$ID/NormalParagraphStyle">
+							<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+								<Content>B2</Content>
+							</CharacterStyleRange>
+						</ParagraphStyleRange>
+					</Cell>
+					<Cell Self="u100i1i2i2" Name="2:2" RowSpan="1" ColumnSpan="1" AppliedCellStyle="CellStyle/$ID/[None]" AppliedCellStylePriority="0">
+						<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/$ID/NormalParagraphStyle">
+							<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+								<Content>C2</Content>
+							</CharacterStyleRange>
+						</ParagraphStyleRange>
+					</Cell>
+				</Table>
+				<Content>After</Content>
+			</CharacterStyleRange>
+		</ParagraphStyleRange>
+	</Story>
+</idPkg:Story>`
+
+// parseTableStory parses tableStoryXML and returns the story and its table.
+func parseTableStory(t *testing.T) (*Story, *Table) {
+	t.Helper()
+
+	st, err := ParseStory([]byte(tableStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+
+	tables := st.Tables()
+	if len(tables) != 1 {
+		t.Fatalf("Tables() returned %d tables, want 1", len(tables))
+	}
+	return st, tables[0]
+}
+
+// TestParseStory_Table tests that tables are parsed into typed structures.
+func TestParseStory_Table(t *testing.T) {
+	st, table := parseTableStory(t)
+
+	if table.Self != "u100i1" {
+		t.Errorf("Self = %q, want u100i1", table.Self)
+	}
+	if table.AppliedTableStyle != "TableStyle/$ID/[Basic Table]" {
+		t.Errorf("AppliedTableStyle = %q", table.AppliedTableStyle)
+	}
+	if table.RowCount() != 3 || table.ColumnTotal() != 3 {
+		t.Errorf("size = %dx%d, want 3x3", table.RowCount(), table.ColumnTotal())
+	}
+	if len(table.Rows) != 3 || len(table.Columns) != 3 || len(table.Cells) != 7 {
+		t.Errorf("got %d rows, %d columns, %d cells", len(table.Rows), len(table.Columns), len(table.Cells))
+	}
+
+	cell := table.Cell(0, 1)
+	if cell == nil || cell.Name != "0:0" {
+		t.Fatalf("Cell(0, 1) should return the merged anchor cell 0:0, got %+v", cell)
+	}
+	if cell.AppliedCellStyle != "CellStyle/Header" {
+		t.Errorf("AppliedCellStyle = %q, want CellStyle/Header", cell.AppliedCellStyle)
+	}
+	if rowSpan, colSpan := cell.Spans(); rowSpan != 1 || colSpan != 2 {
+		t.Errorf("Spans() = %d, %d, want 1, 2", rowSpan, colSpan)
+	}
+
+	// Table text is not part of the flattened story text
+	if got := st.ExtractText(); got != "Before\nAfter" {
+		t.Errorf("ExtractText() = %q, want %q", got, "Before\nAfter")
+	}
+}
+
+// TestTableRoundtrip tests that a story with a table marshals to stable XML.
+func TestTableRoundtrip(t *testing.T) {
+	st, _ := parseTableStory(t)
+
+	first, err := MarshalStory(st)
+	if err != nil {
+		t.Fatalf("MarshalStory failed: %v", err)
+	}
+
+	reparsed, err := ParseStory(first)
+	if err != nil {
+		t.Fatalf("ParseStory (second) failed: %v", err)
+	}
+	second, err := MarshalStory(reparsed)
+	if err != nil {
+		t.Fatalf("MarshalStory (second) failed: %v", err)
+	}
+
+	if !bytes.Equal(first, second) {
+		t.Errorf("roundtrip output differs:\nfirst:\n%s\nsecond:\n%s", first, second)
+	}
+
+	// Unknown attributes and element order must survive
+	out := string(first)
+	for _, want := range []string{`AppliedTableStylePriority="0"`, `FillColor="Color/Black"`, `<Content>Title</Content>`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %s", want)
+		}
+	}
+	if strings.Index(out, "Before") > strings.Index(out, "<Table") || strings.Index(out, "</Table>") > strings.Index(out, "After") {
+		t.Error("table is not kept between surrounding content")
+	}
+}
+
+// TestTable_TextGrid tests reading a table as a 2D text grid.
+func TestTable_TextGrid(t *testing.T) {
+	_, table := parseTableStory(t)
+
+	want := [][]string{
+		{"Title", "", "Total"},
+		{"A", "B1", "C1"},
+		{"", "B2", "C2"},
+	}
+	if diff := cmp.Diff(want, table.TextGrid()); diff != "" {
+		t.Errorf("TextGrid() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// TestTable_InsertRow tests inserting rows inside and after merged cells.
+func TestTable_InsertRow(t *testing.T) {
+	_, table := parseTableStory(t)
+
+	// Insert between the two rows covered by cell 0:1 (RowSpan=2)
+	if err := table.InsertRow(2); err != nil {
+		t.Fatalf("InsertRow failed: %v", err)
+	}
+
+	if table.RowCount() != 4 || table.BodyRowCount != "3" || table.HeaderRowCount != "1" {
+		t.Errorf("counts = header %s body %s, want 1 and 3", table.HeaderRowCount, table.BodyRowCount)
+	}
+	if len(table.Rows) != 4 || table.Rows[2].Name != "2" || table.Rows[3].Name != "3" {
+		t.Errorf("rows not renumbered: %+v", table.Rows)
+	}
+	if table.Rows[2].SingleRowHeight != "20" {
+		t.Errorf("new row should copy row settings, got height %q", table.Rows[2].SingleRowHeight)
+	}
+
+	if span, _ := table.Cell(1, 0).Spans(); span != 3 {
+		t.Errorf("spanning cell RowSpan = %d, want 3", span)
+	}
+
+	want := [][]string{
+		{"Title", "", "Total"},
+		{"A", "B1", "C1"},
+		{"", "", ""},
+		{"", "B2", "C2"},
+	}
+	if diff := cmp.Diff(want, table.TextGrid()); diff != "" {
+		t.Errorf("TextGrid() mismatch (-want +got):\n%s", diff)
+	}
+
+	// Insert into the header section
+	if err := table.InsertRow(0); err != nil {
+		t.Fatalf("InsertRow(0) failed: %v", err)
+	}
+	if table.HeaderRowCount != "2" {
+		t.Errorf("HeaderRowCount = %s, want 2", table.HeaderRowCount)
+	}
+	if style := table.Cell(0, 2).AppliedCellStyle; style != "CellStyle/Header" {
+		t.Errorf("new header cell style = %q, want CellStyle/Header", style)
+	}
+
+	// All Self IDs must be unique
+	seen := make(map[string]bool)
+	for _, c := range table.Cells {
+		if seen[c.Self] {
+			t.Errorf("duplicate cell Self %q", c.Self)
+		}
+		seen[c.Self] = true
+	}
+
+	if err := table.InsertRow(10); err == nil {
+		t.Error("InsertRow out of range should return error")
+	}
+}
+
+// TestTable_DeleteRow tests deleting rows that contain merged cell anchors.
+func TestTable_DeleteRow(t *testing.T) {
+	_, table := parseTableStory(t)
+
+	// Row 1 anchors cell 0:1 which spans into row 2
+	if err := table.DeleteRow(1); err != nil {
+		t.Fatalf("DeleteRow failed: %v", err)
+	}
+
+	want := [][]string{
+		{"Title", "", "Total"},
+		{"A", "B2", "C2"},
+	}
+	if diff := cmp.Diff(want, table.TextGrid()); diff != "" {
+		t.Errorf("TextGrid() mismatch (-want +got):\n%s", diff)
+	}
+	if table.BodyRowCount != "1" || len(table.Rows) != 2 {
+		t.Errorf("BodyRowCount = %s, rows = %d, want 1 and 2", table.BodyRowCount, len(table.Rows))
+	}
+	if span, _ := table.Cell(1, 0).Spans(); span != 1 {
+		t.Errorf("shrunk cell RowSpan = %d, want 1", span)
+	}
+}
+
+// TestTable_DeleteRowWithoutSectionCounts tests that a table without header,
+// body and footer row counts keeps them unset when rows change.
+func TestTable_DeleteRowWithoutSectionCounts(t *testing.T) {
+	st, err := ParseStory([]byte(strings.Replace(tableStoryXML, `HeaderRowCount="1" FooterRowCount="0" BodyRowCount="2" `, "", 1)))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+	table := st.Tables()[0]
+
+	if err := table.DeleteRow(2); err != nil {
+		t.Fatalf("DeleteRow failed: %v", err)
+	}
+	if table.HeaderRowCount != "" || table.BodyRowCount != "" || table.FooterRowCount != "" {
+		t.Errorf("counts = header %q body %q footer %q, want all unset", table.HeaderRowCount, table.BodyRowCount, table.FooterRowCount)
+	}
+	if table.RowCount() != 2 {
+		t.Errorf("RowCount() = %d, want 2", table.RowCount())
+	}
+
+	if err := table.InsertRow(2); err != nil {
+		t.Fatalf("InsertRow failed: %v", err)
+	}
+	if table.FooterRowCount != "" || table.RowCount() != 3 {
+		t.Errorf("FooterRowCount = %q, RowCount() = %d, want unset and 3", table.FooterRowCount, table.RowCount())
+	}
+}
+
+// TestTable_InsertDeleteColumn tests column insertion and deletion.
+func TestTable_InsertDeleteColumn(t *testing.T) {
+	st, table := parseTableStory(t)
+
+	// Insert inside the header cell spanning columns 0-1
+	if err := table.InsertColumn(1); err != nil {
+		t.Fatalf("InsertColumn failed: %v", err)
+	}
+
+	if table.ColumnCount != "4" || len(table.Columns) != 4 {
+		t.Errorf("ColumnCount = %s, columns = %d, want 4", table.ColumnCount, len(table.Columns))
+	}
+	if _, span := table.Cell(0, 0).Spans(); span != 3 {
+		t.Errorf("header ColumnSpan = %d, want 3", span)
+	}
+
+	want := [][]string{
+		{"Title", "", "", "Total"},
+		{"A", "", "B1", "C1"},
+		{"", "", "B2", "C2"},
+	}
+	if diff := cmp.Diff(want, table.TextGrid()); diff != "" {
+		t.Errorf("TextGrid() after insert mismatch (-want +got):\n%s", diff)
+	}
+
+	table.Cell(1, 1).SetText("New")
+
+	if err := table.DeleteColumn(0); err != nil {
+		t.Fatalf("DeleteColumn failed: %v", err)
+	}
+
+	want = [][]string{
+		{"Title", "", "Total"},
+		{"New", "B1", "C1"},
+		{"", "B2", "C2"},
+	}
+	if diff := cmp.Diff(want, table.TextGrid()); diff != "" {
+		t.Errorf("TextGrid() after delete mismatch (-want +got):\n%s", diff)
+	}
+
+	// The modified story must still marshal and parse
+	data, err := MarshalStory(st)
+	if err != nil {
+		t.Fatalf("MarshalStory failed: %v", err)
+	}
+	reparsed, err := ParseStory(data)
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+	if diff := cmp.Diff(want, reparsed.Tables()[0].TextGrid()); diff != "" {
+		t.Errorf("TextGrid() after roundtrip mismatch (-want +got):\n%s", diff)
+	}
+}
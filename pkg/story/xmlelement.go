@@ -0,0 +1,568 @@
+package story
+
+import (
+	"encoding/xml"
+	"slices"
+	"strings"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+)
+
+// XMLElement is an element of the document's XML structure. Inside a
+// CharacterStyleRange it is a text container: the tagged text is part of the
+// story text and the element holds children of its own.
+//
+// An element with XMLContent set is placed on a story or page item instead of
+// tagging text; it is usually found in the backing story (XML/BackingStory.xml).
+//
+// An element that is a child of Story or ParagraphStyleRange tags whole
+// paragraph or character style ranges. Those ranges are kept in the story's
+// ParagraphStyleRanges or the paragraph's CharacterStyleRanges like any other,
+// and list the element in their XMLElements field; the element's own Children
+// hold only its other content. Such an element that encloses no range is kept
+// as a raw element of its parent.
+type XMLElement struct {
+	XMLName xml.Name
+
+	// Identity
+	Self      string
+	MarkupTag string // Reference to an XMLTag in Tags.xml (e.g., "XMLTag/Title")
+
+	// Self of the story or page item the element is placed on (optional)
+	XMLContent string
+
+	// Additional attributes (catch-all)
+	OtherAttrs []xml.Attr
+
+	// XML attributes of the element, in order
+	Attributes []XMLAttribute
+
+	// Tagged content: Content, Br, nested XMLElements and the other character
+	// children in order
+	Children []CharacterChild
+}
+
+// XMLAttribute is an attribute of an XMLElement.
+type XMLAttribute struct {
+	XMLName xml.Name `xml:"XMLAttribute"`
+
+	Self  string `xml:"Self,attr"`  // Unique identifier (e.g., "di3i4XMLAttributenid")
+	Name  string `xml:"Name,attr"`  // Attribute name
+	Value string `xml:"Value,attr"` // Attribute value
+
+	// Catch-all for other attributes
+	OtherAttrs []xml.Attr `xml:",any,attr"`
+}
+
+// TagName returns the name of the element's tag, i.e. MarkupTag without the
+// "XMLTag/" prefix.
+func (x *XMLElement) TagName() string {
+	return strings.TrimPrefix(x.MarkupTag, "XMLTag/")
+}
+
+// Attribute returns the value of the XML attribute with the given name.
+func (x *XMLElement) Attribute(name string) (string, bool) {
+	for _, attr := range x.Attributes {
+		if attr.Name == name {
+			return attr.Value, true
+		}
+	}
+	return "", false
+}
+
+// SetAttribute sets the XML attribute with the given name, adding it if needed.
+func (x *XMLElement) SetAttribute(name, value string) {
+	for i := range x.Attributes {
+		if x.Attributes[i].Name == name {
+			x.Attributes[i].Value = value
+			return
+		}
+	}
+	x.Attributes = append(x.Attributes, XMLAttribute{
+		XMLName: xml.Name{Local: "XMLAttribute"},
+		Self:    x.Self + "XMLAttributen" + name,
+		Name:    name,
+		Value:   value,
+	})
+}
+
+// TaggedRange describes an XMLElement in a story and the text it tags.
+type TaggedRange struct {
+	Element *XMLElement
+	Parent  *XMLElement // enclosing XMLElement in the story, nil at the top level
+	Range   TextRange   // position of the tagged text in the flattened story text
+	Text    string
+}
+
+// XMLElements returns the XMLElements tagging text in the story, in document
+// order, including those enclosing whole paragraph or character style ranges.
+// Parents come before their children.
+func (s *Story) XMLElements() []TaggedRange {
+	text := s.ExtractText()
+	var (
+		tagged []TaggedRange
+		open   []int // indices in tagged of the elements enclosing the current range
+		pos    int
+	)
+	// enclose ends the open elements not in chain and starts the new ones at pos
+	enclose := func(chain []*XMLElement) {
+		k := 0
+		for k < len(open) && k < len(chain) && tagged[open[k]].Element == chain[k] {
+			k++
+		}
+		for _, i := range open[k:] {
+			tagged[i].Range.End = pos
+		}
+		open = open[:k]
+		for _, x := range chain[k:] {
+			var parent *XMLElement
+			if len(open) > 0 {
+				parent = tagged[open[len(open)-1]].Element
+			}
+			open = append(open, len(tagged))
+			tagged = append(tagged, TaggedRange{Element: x, Parent: parent, Range: TextRange{Start: pos}})
+		}
+	}
+
+	for i := range s.StoryElement.ParagraphStyleRanges {
+		psr := &s.StoryElement.ParagraphStyleRanges[i]
+		enclose(psr.XMLElements)
+		for j := range psr.CharacterStyleRanges {
+			csr := &psr.CharacterStyleRanges[j]
+			enclose(append(slices.Clip(psr.XMLElements), csr.XMLElements...))
+
+			enclosing := make(map[*[]CharacterChild]*XMLElement)
+			if len(open) > 0 {
+				enclosing[&csr.Children] = tagged[open[len(open)-1]].Element
+			}
+			pos = walkRangeChildren(i, j, &csr.Children, pos, func(v childVisit) {
+				child := &(*v.parent)[v.index]
+				nested := child.nested()
+				if nested == nil {
+					return
+				}
+				if child.XMLElement == nil {
+					enclosing[nested] = enclosing[v.parent]
+					return
+				}
+				enclosing[nested] = child.XMLElement
+				end := v.pos + childrenTextLen(*nested)
+				tagged = append(tagged, TaggedRange{
+					Element: child.XMLElement,
+					Parent:  enclosing[v.parent],
+					Range:   TextRange{Start: v.pos, End: end},
+				})
+			})
+		}
+	}
+	enclose(nil)
+
+	for i := range tagged {
+		tagged[i].Text = text[tagged[i].Range.Start:tagged[i].Range.End]
+	}
+	return tagged
+}
+
+// XMLElementsAt returns the XMLElements whose tagged text contains the byte
+// offset, outermost first.
+func (s *Story) XMLElementsAt(offset int) []*XMLElement {
+	var elements []*XMLElement
+	for _, t := range s.XMLElements() {
+		if t.Range.Start <= offset && offset < t.Range.End {
+			elements = append(elements, t.Element)
+		}
+	}
+	return elements
+}
+
+// FindXMLElement returns the XMLElement with the given Self ID and its tagged range.
+//
+// Returns common.ErrNotFound if the story has no such element.
+func (s *Story) FindXMLElement(self string) (TaggedRange, error) {
+	for _, t := range s.XMLElements() {
+		if t.Element.Self == self {
+			return t, nil
+		}
+	}
+	return TaggedRange{}, common.WrapErrorWithPath("story", "find XML element", self, common.ErrNotFound)
+}
+
+// TagText wraps the text in [start, end) of the flattened story text in a new
+// XMLElement with the given Self ID and markup tag (e.g., "XMLTag/Title").
+//
+// The range must lie within a single character style range. It may lie inside
+// another XMLElement, which makes the new element its child, and it may
+// enclose whole XMLElements or link sources.
+func (s *Story) TagText(start, end int, self, markupTag string) (*XMLElement, error) {
+	const op = "tag text"
+	if err := s.validateRange(op, start, end); err != nil {
+		return nil, err
+	}
+	if start == end {
+		return nil, common.Errorf("story", op, s.StoryElement.Self, "range to tag is empty")
+	}
+	if self == "" || markupTag == "" {
+		return nil, common.Errorf("story", op, s.StoryElement.Self, "XML element needs a Self ID and a markup tag")
+	}
+
+	parent, first, last, err := s.wrappableChildren(op, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	element := &XMLElement{
+		XMLName:   xml.Name{Local: "XMLElement"},
+		Self:      self,
+		MarkupTag: markupTag,
+		Children:  slices.Clone((*parent)[first : last+1]),
+	}
+	*parent = slices.Replace(*parent, first, last+1, CharacterChild{XMLElement: element})
+	return element, nil
+}
+
+// UntagXMLElement removes the XMLElement with the given Self ID from the story.
+// Its text and child elements stay in place.
+//
+// Returns common.ErrNotFound if the story has no such element.
+func (s *Story) UntagXMLElement(self string) error {
+	parent, index := s.findXMLElementChild(self)
+	if parent == nil {
+		if !s.removeEnclosingElement(self) {
+			return common.WrapErrorWithPath("story", "untag XML element", self, common.ErrNotFound)
+		}
+		return nil
+	}
+	children := (*parent)[index].XMLElement.Children
+	*parent = slices.Replace(*parent, index, index+1, children...)
+	mergeAdjacentContent(parent)
+	return nil
+}
+
+// SetXMLElementText replaces the text tagged by the XMLElement with the given
+// Self ID. Newlines in text become <Br/> elements. The element's Content and
+// Br children are replaced; other children, such as anchored objects and link
+// sources, are kept. For an element enclosing whole paragraph or character
+// style ranges, the text of those ranges is replaced and takes the formatting
+// of their first character.
+//
+// The element must not have child XMLElements. Returns common.ErrNotFound if
+// the story has no such element.
+func (s *Story) SetXMLElementText(self, text string) error {
+	const op = "set XML element text"
+	parent, index := s.findXMLElementChild(self)
+	if parent == nil {
+		return s.setEnclosingElementText(op, self, text)
+	}
+	element := (*parent)[index].XMLElement
+	if containsChild(element.Children, func(c *CharacterChild) bool { return c.XMLElement != nil }) {
+		return common.Errorf("story", op, self, "element has child XML elements")
+	}
+
+	kept := make([]CharacterChild, 0, len(element.Children))
+	at := -1
+	for _, child := range element.Children {
+		if child.Content != nil || child.Br != nil {
+			if at < 0 {
+				at = len(kept)
+			}
+			continue
+		}
+		kept = append(kept, child)
+	}
+	if at < 0 {
+		at = 0
+	}
+	element.Children = slices.Insert(kept, at, textChildren(text)...)
+	return nil
+}
+
+// SetText replaces the whole text of the story. The new text takes the
+// paragraph and character style of the first character; newlines become
+// <Br/> elements.
+func (s *Story) SetText(text string) {
+	s.replaceRange(0, len(s.ExtractText()), text)
+}
+
+// setEnclosingElementText replaces the text of the ranges enclosed by the
+// XMLElement with the given Self ID.
+func (s *Story) setEnclosingElementText(op, self, text string) error {
+	tagged := s.XMLElements()
+	i := slices.IndexFunc(tagged, func(t TaggedRange) bool { return t.Element.Self == self })
+	if i < 0 {
+		return common.WrapErrorWithPath("story", op, self, common.ErrNotFound)
+	}
+	if slices.ContainsFunc(tagged, func(t TaggedRange) bool { return t.Parent == tagged[i].Element }) {
+		return common.Errorf("story", op, self, "element has child XML elements")
+	}
+	if tagged[i].Range.Len() == 0 {
+		return common.Errorf("story", op, self, "element encloses no text")
+	}
+	s.replaceRange(tagged[i].Range.Start, tagged[i].Range.End, text)
+	return nil
+}
+
+// removeEnclosingElement removes the XMLElement with the given Self ID from
+// the elements enclosing the story's ranges and reports whether any range
+// listed it.
+func (s *Story) removeEnclosingElement(self string) bool {
+	found := false
+	remove := func(chain []*XMLElement) []*XMLElement {
+		i := slices.IndexFunc(chain, func(x *XMLElement) bool { return x.Self == self })
+		if i < 0 {
+			return chain
+		}
+		found = true
+		// Ranges share their chains, so build a new one
+		return append(slices.Clone(chain[:i]), chain[i+1:]...)
+	}
+	for i := range s.StoryElement.ParagraphStyleRanges {
+		psr := &s.StoryElement.ParagraphStyleRanges[i]
+		psr.XMLElements = remove(psr.XMLElements)
+		for j := range psr.CharacterStyleRanges {
+			psr.CharacterStyleRanges[j].XMLElements = remove(psr.CharacterStyleRanges[j].XMLElements)
+		}
+	}
+	return found
+}
+
+// findXMLElementChild returns the children slice holding the XMLElement with
+// the given Self ID and its index, or nil if there is none.
+func (s *Story) findXMLElementChild(self string) (*[]CharacterChild, int) {
+	var (
+		parent *[]CharacterChild
+		index  int
+	)
+	s.walkChildren(func(v childVisit) {
+		if x := (*v.parent)[v.index].XMLElement; parent == nil && x != nil && x.Self == self {
+			parent, index = v.parent, v.index
+		}
+	})
+	return parent, index
+}
+
+// wrappableChildren splits content at start and end and returns the children
+// slice and the index range [first, last] of its children that exactly cover
+// [start, end). Children of the outermost container are preferred, so a range
+// matching a whole link source or XMLElement selects the container itself.
+func (s *Story) wrappableChildren(op string, start, end int) (parent *[]CharacterChild, first, last int, err error) {
+	s.splitContentAt(end)
+	s.splitContentAt(start)
+
+	type boundary struct {
+		parent *[]CharacterChild
+		index  int
+	}
+	var starts, ends []boundary
+	s.walkChildren(func(v childVisit) {
+		child := &(*v.parent)[v.index]
+		var n int
+		switch {
+		case child.Content != nil:
+			n = len(child.Content.Text)
+		case child.Br != nil:
+			n = 1
+		case child.nested() != nil:
+			n = childrenTextLen(*child.nested())
+		}
+		if n == 0 {
+			return
+		}
+		if v.pos == start {
+			starts = append(starts, boundary{v.parent, v.index})
+		}
+		if v.pos+n == end {
+			ends = append(ends, boundary{v.parent, v.index})
+		}
+	})
+
+	for _, b := range starts {
+		for _, e := range ends {
+			if e.parent == b.parent && e.index >= b.index {
+				return b.parent, b.index, e.index, nil
+			}
+		}
+	}
+	return nil, 0, 0, common.Errorf("story", op, s.StoryElement.Self,
+		"range [%d, %d) does not cover whole elements of a single character style range", start, end)
+}
+
+// UnmarshalXML implements custom unmarshaling for XMLElement to preserve child order.
+func (x *XMLElement) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	x.XMLName = start.Name
+	for _, attr := range start.Attr {
+		switch attr.Name.Local {
+		case "Self":
+			x.Self = attr.Value
+		case "MarkupTag":
+			x.MarkupTag = attr.Value
+		case "XMLContent":
+			x.XMLContent = attr.Value
+		default:
+			x.OtherAttrs = append(x.OtherAttrs, attr)
+		}
+	}
+
+	children, err := decodeCharacterChildren(d)
+	if err != nil {
+		return err
+	}
+
+	// XMLAttribute children are kept apart from the tagged content
+	for _, child := range children {
+		if child.Other != nil && child.Other.XMLName.Local == "XMLAttribute" {
+			x.Attributes = append(x.Attributes, xmlAttributeFromRaw(child.Other))
+			continue
+		}
+		x.Children = append(x.Children, child)
+	}
+	return nil
+}
+
+// MarshalXML implements custom marshaling for XMLElement to preserve child order.
+func (x XMLElement) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = x.startElement(start)
+	if err := x.encodeStart(e, start); err != nil {
+		return err
+	}
+	return e.EncodeToken(xml.EndElement{Name: start.Name})
+}
+
+// startElement returns start named and attributed as the element.
+func (x *XMLElement) startElement(start xml.StartElement) xml.StartElement {
+	start.Name = xml.Name{Local: "XMLElement"}
+	start.Attr = appendAttrs(start.Attr,
+		"Self", x.Self,
+		"MarkupTag", x.MarkupTag,
+		"XMLContent", x.XMLContent,
+	)
+	start.Attr = append(start.Attr, x.OtherAttrs...)
+	return start
+}
+
+// encodeStart writes the opening tag, the XML attributes and the children of
+// the element, leaving it open.
+func (x *XMLElement) encodeStart(e *xml.Encoder, start xml.StartElement) error {
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, attr := range x.Attributes {
+		if err := e.Encode(attr); err != nil {
+			return err
+		}
+	}
+	return encodeCharacterChildren(e, x.Children)
+}
+
+// decodeEnclosingElement decodes an XMLElement that is a child of a Story or
+// ParagraphStyleRange. Each range named rangeName inside it, at any depth, is
+// passed to decodeRange with the XMLElements enclosing it, outermost first.
+// An element enclosing no range is returned as a raw element instead.
+func decodeEnclosingElement(d *xml.Decoder, t *xml.StartElement, rangeName string, decodeRange func(elem *common.RawXMLElement, enclosing []*XMLElement) error) (*common.RawXMLElement, error) {
+	var raw common.RawXMLElement
+	if err := d.DecodeElement(&raw, t); err != nil {
+		return nil, err
+	}
+	x := &XMLElement{}
+	if err := decodeRaw(&raw, x); err != nil {
+		return nil, err
+	}
+	if !enclosesRange(x, rangeName) {
+		return &raw, nil
+	}
+	return nil, unwrapRanges(x, rangeName, nil, decodeRange)
+}
+
+// enclosesRange reports whether a range named rangeName is among the children
+// of x or of its nested XMLElements. Ranges are decoded as raw children.
+func enclosesRange(x *XMLElement, rangeName string) bool {
+	return slices.ContainsFunc(x.Children, func(c CharacterChild) bool {
+		return c.Other != nil && c.Other.XMLName.Local == rangeName ||
+			c.XMLElement != nil && enclosesRange(c.XMLElement, rangeName)
+	})
+}
+
+// unwrapRanges passes the ranges inside x to decodeRange and removes them, and
+// the nested XMLElements enclosing them, from the children of x.
+func unwrapRanges(x *XMLElement, rangeName string, enclosing []*XMLElement, decodeRange func(elem *common.RawXMLElement, enclosing []*XMLElement) error) error {
+	enclosing = append(slices.Clip(enclosing), x)
+	children := x.Children
+	x.Children = nil
+	for _, child := range children {
+		var err error
+		switch {
+		case child.Other != nil && child.Other.XMLName.Local == rangeName:
+			err = decodeRange(child.Other, enclosing)
+		case child.XMLElement != nil && enclosesRange(child.XMLElement, rangeName):
+			err = unwrapRanges(child.XMLElement, rangeName, enclosing, decodeRange)
+		default:
+			x.Children = append(x.Children, child)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeEnclosedRanges writes ranges in order, each inside the XMLElements
+// returned by enclosing. Consecutive ranges sharing an element are written
+// inside a single copy of it.
+func encodeEnclosedRanges[R any](e *xml.Encoder, ranges []R, enclosing func(*R) []*XMLElement) error {
+	var open []*XMLElement
+	closeTo := func(k int) error {
+		for ; len(open) > k; open = open[:len(open)-1] {
+			if err := e.EncodeToken(xml.EndElement{Name: xml.Name{Local: "XMLElement"}}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for i := range ranges {
+		chain := enclosing(&ranges[i])
+		k := 0
+		for k < len(open) && k < len(chain) && open[k] == chain[k] {
+			k++
+		}
+		if err := closeTo(k); err != nil {
+			return err
+		}
+		for _, x := range chain[k:] {
+			if err := x.encodeStart(e, x.startElement(xml.StartElement{})); err != nil {
+				return err
+			}
+			open = append(open, x)
+		}
+		if err := e.Encode(&ranges[i]); err != nil {
+			return err
+		}
+	}
+	return closeTo(0)
+}
+
+// decodeRaw decodes a raw element into v.
+func decodeRaw(raw *common.RawXMLElement, v any) error {
+	data, err := xml.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	return xml.Unmarshal(data, v)
+}
+
+// xmlAttributeFromRaw converts a raw <XMLAttribute> element.
+func xmlAttributeFromRaw(raw *common.RawXMLElement) XMLAttribute {
+	attr := XMLAttribute{XMLName: xml.Name{Local: "XMLAttribute"}}
+	for _, a := range raw.Attrs {
+		switch a.Name.Local {
+		case "Self":
+			attr.Self = a.Value
+		case "Name":
+			attr.Name = a.Value
+		case "Value":
+			attr.Value = a.Value
+		default:
+			attr.OtherAttrs = append(attr.OtherAttrs, a)
+		}
+	}
+	return attr
+}
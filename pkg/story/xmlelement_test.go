@@ -0,0 +1,381 @@
+package story
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dimelords/idmllib/v2/pkg/common"
+)
+
+// taggedStoryXML is a story with nested XML elements, an XML attribute and a
+// hyperlink source inside a tagged element.
+const taggedStoryXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<idPkg:Story xmlns:idPkg="http://ns.adobe.com/AdobeInDesign/idml/1.0/packaging" DOMVersion="20.4">
+	<Story Self="u600" AppliedTOCStyle="n" TrackChanges="false">
+		<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/Heading">
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+				<XMLElement Self="di2i3" MarkupTag="XMLTag/Title">
+					<XMLAttribute Self="di2i3XMLAttributenid" Name="id" Value="t1"/>
+					<Content>Annual report</Content>
+				</XMLElement>
+				<Br/>
+			</CharacterStyleRange>
+		</ParagraphStyleRange>
+		<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/Body">
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+				<XMLElement Self="di2i4" MarkupTag="XMLTag/Body">
+					<Content>Sales grew in </Content>
+					<XMLElement Self="di2i4i5" MarkupTag="XMLTag/Region">
+						<Content>Norway</Content>
+					</XMLElement>
+					<Content> and </Content>
+					<HyperlinkTextSource Self="u610" Name="Link" Hidden="false">
+						<Content>Sweden</Content>
+					</HyperlinkTextSource>
+					<Content>.</Content>
+				</XMLElement>
+			</CharacterStyleRange>
+		</ParagraphStyleRange>
+	</Story>
+</idPkg:Story>`
+
+// TestParseStory_XMLElements tests that XML elements are parsed as text
+// containers and mapped to text ranges.
+func TestParseStory_XMLElements(t *testing.T) {
+	st, err := ParseStory([]byte(taggedStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+
+	if got, want := st.ExtractText(), "Annual report\nSales grew in Norway and Sweden."; got != want {
+		t.Errorf("ExtractText() = %q, want %q", got, want)
+	}
+
+	tagged := st.XMLElements()
+	if len(tagged) != 3 {
+		t.Fatalf("XMLElements() returned %d elements, want 3", len(tagged))
+	}
+	title, body, region := tagged[0], tagged[1], tagged[2]
+	if title.Element.TagName() != "Title" || title.Text != "Annual report" || title.Parent != nil {
+		t.Errorf("title = %+v", title)
+	}
+	if id, ok := title.Element.Attribute("id"); !ok || id != "t1" {
+		t.Errorf("title id attribute = %q, %v", id, ok)
+	}
+	if body.Text != "Sales grew in Norway and Sweden." || body.Range.Start != 14 {
+		t.Errorf("body = %+v", body)
+	}
+	if region.Parent != body.Element || region.Text != "Norway" {
+		t.Errorf("region = %+v", region)
+	}
+
+	if got := st.XMLElementsAt(region.Range.Start); len(got) != 2 || got[0] != body.Element || got[1] != region.Element {
+		t.Errorf("XMLElementsAt(%d) = %v, want body and region", region.Range.Start, got)
+	}
+	if got := st.XMLElementsAt(13); len(got) != 0 {
+		t.Errorf("XMLElementsAt(13) = %v, want none at the line break", got)
+	}
+
+	if _, err := st.FindXMLElement("di9"); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("FindXMLElement(di9) error = %v, want ErrNotFound", err)
+	}
+	if sources := st.LinkSources(); len(sources) != 1 || sources[0].Text != "Sweden" {
+		t.Errorf("LinkSources() = %+v", sources)
+	}
+}
+
+// TestXMLElementsRoundtrip tests that XML elements and attributes survive marshaling.
+func TestXMLElementsRoundtrip(t *testing.T) {
+	st, err := ParseStory([]byte(taggedStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+
+	first, err := MarshalStory(st)
+	if err != nil {
+		t.Fatalf("MarshalStory failed: %v", err)
+	}
+	reparsed, err := ParseStory(first)
+	if err != nil {
+		t.Fatalf("ParseStory (second) failed: %v", err)
+	}
+	second, err := MarshalStory(reparsed)
+	if err != nil {
+		t.Fatalf("MarshalStory (second) failed: %v", err)
+	}
+	if !bytes.Equal(first, second) {
+		t.Errorf("roundtrip output differs:\nfirst:\n%s\nsecond:\n%s", first, second)
+	}
+
+	out := string(first)
+	for _, want := range []string{
+		`<XMLElement Self="di2i3" MarkupTag="XMLTag/Title"><XMLAttribute Self="di2i3XMLAttributenid" Name="id" Value="t1"></XMLAttribute>`,
+		`<XMLElement Self="di2i4i5" MarkupTag="XMLTag/Region">`,
+	} {
+		if !strings.Contains(strings.Join(strings.Fields(out), ""), strings.Join(strings.Fields(want), "")) {
+			t.Errorf("output missing %s", want)
+		}
+	}
+}
+
+// TestTagText tests tagging and untagging text ranges.
+func TestTagText(t *testing.T) {
+	st, err := ParseStory([]byte(taggedStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+	text := st.ExtractText()
+
+	// A range inside an element becomes its child
+	r := st.FindText("grew")[0]
+	verb, err := st.TagText(r.Start, r.End, "u620", "XMLTag/Verb")
+	if err != nil {
+		t.Fatalf("TagText failed: %v", err)
+	}
+	found, err := st.FindXMLElement("u620")
+	if err != nil {
+		t.Fatalf("FindXMLElement failed: %v", err)
+	}
+	if found.Element != verb || found.Range != r || found.Parent == nil || found.Parent.Self != "di2i4" {
+		t.Errorf("tagged range = %+v", found)
+	}
+
+	// A range covering whole elements wraps them
+	r = st.FindText("Norway and Sweden")[0]
+	if _, err := st.TagText(r.Start, r.End, "u621", "XMLTag/Countries"); err != nil {
+		t.Fatalf("TagText around elements failed: %v", err)
+	}
+	if got, _ := st.FindXMLElement("di2i4i5"); got.Parent == nil || got.Parent.Self != "u621" {
+		t.Errorf("region parent = %+v", got.Parent)
+	}
+	if got := st.ExtractText(); got != text {
+		t.Errorf("ExtractText() = %q, text must be unchanged", got)
+	}
+
+	tests := []struct {
+		name       string
+		start, end int
+	}{
+		{"empty range", 3, 3},
+		{"partial element", r.Start + 2, r.End},
+		{"spans paragraphs", 0, len(text)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := st.TagText(tt.start, tt.end, "u630", "XMLTag/X"); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+
+	if err := st.UntagXMLElement("u621"); err != nil {
+		t.Fatalf("UntagXMLElement failed: %v", err)
+	}
+	if got, _ := st.FindXMLElement("di2i4i5"); got.Parent == nil || got.Parent.Self != "di2i4" {
+		t.Errorf("region parent after untag = %+v", got.Parent)
+	}
+	if err := st.UntagXMLElement("u621"); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("UntagXMLElement twice error = %v, want ErrNotFound", err)
+	}
+}
+
+// TestSetXMLElementText tests replacing the text of a tagged element.
+func TestSetXMLElementText(t *testing.T) {
+	st, err := ParseStory([]byte(taggedStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+
+	if err := st.SetXMLElementText("di2i3", "Quarterly\nreport"); err != nil {
+		t.Fatalf("SetXMLElementText failed: %v", err)
+	}
+	if got := st.ExtractText(); !strings.HasPrefix(got, "Quarterly\nreport\nSales") {
+		t.Errorf("ExtractText() = %q", got)
+	}
+	if id, _ := st.StoryElement.ParagraphStyleRanges[0].CharacterStyleRanges[0].Children[0].XMLElement.Attribute("id"); id != "t1" {
+		t.Errorf("attribute lost: id = %q", id)
+	}
+
+	if err := st.SetXMLElementText("di2i4", "x"); err == nil {
+		t.Error("expected error for an element with child elements")
+	}
+	if err := st.SetXMLElementText("di9", "x"); !errors.Is(err, common.ErrNotFound) {
+		t.Errorf("SetXMLElementText(di9) error = %v, want ErrNotFound", err)
+	}
+}
+
+// taggedParagraphsStoryXML is a story with an XML element enclosing two
+// paragraphs, one of which has a paragraph-level element enclosing a
+// character style range, followed by an untagged paragraph.
+const taggedParagraphsStoryXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<idPkg:Story xmlns:idPkg="http://ns.adobe.com/AdobeInDesign/idml/1.0/packaging" DOMVersion="20.4">
+	<Story Self="u700" AppliedTOCStyle="n" TrackChanges="false">
+		<XMLElement Self="di5" MarkupTag="XMLTag/Article">
+			<XMLAttribute Self="di5XMLAttributenid" Name="id" Value="a1"/>
+			<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/Heading">
+				<XMLElement Self="di5i6" MarkupTag="XMLTag/Title">
+					<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+						<Content>Annual report</Content>
+					</CharacterStyleRange>
+				</XMLElement>
+				<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+					<Br/>
+				</CharacterStyleRange>
+			</ParagraphStyleRange>
+			<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/Body">
+				<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+					<Content>Sales grew in </Content>
+					<XMLElement Self="di5i7" MarkupTag="XMLTag/Region">
+						<Content>Norway</Content>
+					</XMLElement>
+					<Content>.</Content>
+					<Br/>
+				</CharacterStyleRange>
+			</ParagraphStyleRange>
+		</XMLElement>
+		<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/Body">
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+				<Content>Untagged</Content>
+			</CharacterStyleRange>
+		</ParagraphStyleRange>
+		<XMLElement Self="di8" MarkupTag="XMLTag/Empty"/>
+	</Story>
+</idPkg:Story>`
+
+// TestXMLElements_EnclosingRanges tests XML elements that are children of
+// Story and ParagraphStyleRange: their text is part of the story and they
+// keep their place on a roundtrip.
+func TestXMLElements_EnclosingRanges(t *testing.T) {
+	st, err := ParseStory([]byte(taggedParagraphsStoryXML))
+	if err != nil {
+		t.Fatalf("ParseStory failed: %v", err)
+	}
+	if got, want := st.ExtractText(), "Annual report\nSales grew in Norway.\nUntagged"; got != want {
+		t.Errorf("ExtractText() = %q, want %q", got, want)
+	}
+
+	check := func(t *testing.T, st *Story) {
+		t.Helper()
+		tagged := st.XMLElements()
+		want := []struct {
+			self, parent string
+			start, end   int
+		}{
+			{"di5", "", 0, 36},
+			{"di5i6", "di5", 0, 13},
+			{"di5i7", "di5", 28, 34},
+		}
+		if len(tagged) != len(want) {
+			t.Fatalf("XMLElements() returned %d elements, want %d", len(tagged), len(want))
+		}
+		for i, w := range want {
+			got := tagged[i]
+			parent := ""
+			if got.Parent != nil {
+				parent = got.Parent.Self
+			}
+			if got.Element.Self != w.self || parent != w.parent || got.Range != (TextRange{Start: w.start, End: w.end}) {
+				t.Errorf("XMLElements()[%d] = %s in %q at %v, want %s in %q at [%d, %d)",
+					i, got.Element.Self, parent, got.Range, w.self, w.parent, w.start, w.end)
+			}
+		}
+		if id, _ := tagged[0].Element.Attribute("id"); id != "a1" {
+			t.Errorf("id attribute = %q", id)
+		}
+		if tagged[1].Text != "Annual report" {
+			t.Errorf("title text = %q", tagged[1].Text)
+		}
+	}
+	check(t, st)
+
+	data, err := MarshalStory(st)
+	if err != nil {
+		t.Fatalf("MarshalStory failed: %v", err)
+	}
+	out := strings.Join(strings.Fields(string(data)), "")
+	order := []string{
+		`<XMLElementSelf="di5"MarkupTag="XMLTag/Article"><XMLAttribute`,
+		`<ParagraphStyleRangeAppliedParagraphStyle="ParagraphStyle/Heading"><XMLElementSelf="di5i6"MarkupTag="XMLTag/Title"><CharacterStyleRange`,
+		`</CharacterStyleRange></XMLElement><CharacterStyleRange`,
+		`</ParagraphStyleRange></XMLElement><ParagraphStyleRange`,
+		`<XMLElementSelf="di8"MarkupTag="XMLTag/Empty"></XMLElement></Story>`,
+	}
+	at := 0
+	for _, want := range order {
+		i := strings.Index(out[at:], want)
+		if i < 0 {
+			t.Fatalf("output is missing %s after offset %d:\n%s", want, at, data)
+		}
+		at += i + len(want)
+	}
+
+	reparsed, err := ParseStory(data)
+	if err != nil {
+		t.Fatalf("ParseStory (second) failed: %v", err)
+	}
+	check(t, reparsed)
+
+	// Replacing the text of a paragraph-level element keeps it in place
+	if err := reparsed.SetXMLElementText("di5i6", "Quarterly report"); err != nil {
+		t.Fatalf("SetXMLElementText failed: %v", err)
+	}
+	if found, _ := reparsed.FindXMLElement("di5i6"); found.Text != "Quarterly report" {
+		t.Errorf("title text after SetXMLElementText = %q", found.Text)
+	}
+	if err := reparsed.SetXMLElementText("di5", "x"); err == nil {
+		t.Error("expected error for an element with child elements")
+	}
+
+	// Untagging the story-level element leaves its paragraphs in the story
+	if err := reparsed.UntagXMLElement("di5"); err != nil {
+		t.Fatalf("UntagXMLElement failed: %v", err)
+	}
+	tagged := reparsed.XMLElements()
+	if len(tagged) != 2 || tagged[0].Element.Self != "di5i6" || tagged[0].Parent != nil {
+		t.Errorf("XMLElements() after untag = %+v", tagged)
+	}
+	if got, want := reparsed.ExtractText(), "Quarterly report\nSales grew in Norway.\nUntagged"; got != want {
+		t.Errorf("ExtractText() after untag = %q, want %q", got, want)
+	}
+}
+
+// TestParseBackingStory tests reading and writing the backing story.
+func TestParseBackingStory(t *testing.T) {
+	const backingXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<idPkg:BackingStory xmlns:idPkg="http://ns.adobe.com/AdobeInDesign/idml/1.0/packaging" DOMVersion="20.4">
+	<XmlStory Self="u98" AppliedTOCStyle="n" TrackChanges="false">
+		<ParagraphStyleRange AppliedParagraphStyle="ParagraphStyle/$ID/NormalParagraphStyle">
+			<CharacterStyleRange AppliedCharacterStyle="CharacterStyle/$ID/[No character style]">
+				<XMLElement Self="di3" MarkupTag="XMLTag/Root">
+					<XMLElement Self="di3i4" MarkupTag="XMLTag/Article" XMLContent="u600"/>
+				</XMLElement>
+			</CharacterStyleRange>
+		</ParagraphStyleRange>
+	</XmlStory>
+</idPkg:BackingStory>`
+
+	bs, err := ParseBackingStory([]byte(backingXML))
+	if err != nil {
+		t.Fatalf("ParseBackingStory failed: %v", err)
+	}
+	tagged := bs.XMLElements()
+	if len(tagged) != 2 || bs.StoryElement.Self != "u98" || tagged[1].Element.XMLContent != "u600" || tagged[1].Parent != tagged[0].Element {
+		t.Fatalf("XMLElements() = %+v", tagged)
+	}
+
+	data, err := MarshalBackingStory(bs)
+	if err != nil {
+		t.Fatalf("MarshalBackingStory failed: %v", err)
+	}
+	out := string(data)
+	for _, want := range []string{`<idPkg:BackingStory xmlns:idPkg=`, `<XmlStory Self="u98"`, `XMLContent="u600"`, `</XmlStory>`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %s:\n%s", want, out)
+		}
+	}
+	if _, err := ParseBackingStory(data); err != nil {
+		t.Errorf("ParseBackingStory (second) failed: %v", err)
+	}
+}